@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -39,7 +40,9 @@ func DefaultCORSConfig() CORSConfig {
 			"X-Request-ID",
 			"X-Requested-With",
 			"X-Idempotency-Key",
+			"Idempotency-Key",
 			"X-Queue-Pass",
+			"X-Request-Nonce",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
@@ -56,46 +59,60 @@ func CORS() gin.HandlerFunc {
 	return CORSWithConfig(DefaultCORSConfig())
 }
 
-// CORSWithConfig middleware with custom configuration
+// CORSWithConfig middleware with custom configuration. AllowOrigins entries
+// are exact origins ("https://app.example.com"), "*" for any origin, or a
+// single-label wildcard ("https://*.example.com"). Preflight (OPTIONS)
+// requests are always answered here and never reach the proxy: 204 for
+// allowed origins, 403 for disallowed ones.
 func CORSWithConfig(config CORSConfig) gin.HandlerFunc {
+	allowOrigin := newOriginMatcher(config.AllowOrigins)
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(config.MaxAge)
+
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
+		preflight := c.Request.Method == http.MethodOptions
 
 		// When credentials are allowed, we must echo back the specific origin, not "*"
 		allowedOrigin := origin
 		if origin == "" {
 			allowedOrigin = "*"
-		} else if len(config.AllowOrigins) > 0 && config.AllowOrigins[0] != "*" {
-			allowedOrigin = ""
-			for _, o := range config.AllowOrigins {
-				if o == origin {
-					allowedOrigin = origin
-					break
-				}
+		} else {
+			// The response depends on the Origin header, so caches must key on it
+			c.Header("Vary", "Origin")
+			if !allowOrigin(origin) {
+				allowedOrigin = ""
 			}
 		}
 
 		if allowedOrigin == "" {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Served without CORS headers; the browser blocks the response
 			c.Next()
 			return
 		}
 
 		// Set CORS headers
 		c.Header("Access-Control-Allow-Origin", allowedOrigin)
-		c.Header("Access-Control-Allow-Methods", strings.Join(config.AllowMethods, ", "))
-		c.Header("Access-Control-Allow-Headers", strings.Join(config.AllowHeaders, ", "))
-		c.Header("Access-Control-Expose-Headers", strings.Join(config.ExposeHeaders, ", "))
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		c.Header("Access-Control-Expose-Headers", exposeHeaders)
 
 		if config.AllowCredentials && allowedOrigin != "*" {
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
 
 		if config.MaxAge > 0 {
-			c.Header("Access-Control-Max-Age", "86400")
+			c.Header("Access-Control-Max-Age", maxAge)
 		}
 
 		// Handle preflight request
-		if c.Request.Method == http.MethodOptions {
+		if preflight {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
@@ -103,3 +120,37 @@ func CORSWithConfig(config CORSConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// newOriginMatcher returns a func reporting whether origin is allowed by the
+// exact, "*" and "scheme://*.domain" entries in allowed
+func newOriginMatcher(allowed []string) func(origin string) bool {
+	exact := make(map[string]bool, len(allowed))
+	var suffixes [][2]string // {"scheme://", ".domain"}
+	for _, o := range allowed {
+		if o == "*" {
+			return func(string) bool { return true }
+		}
+		if scheme, rest, ok := strings.Cut(o, "://*."); ok {
+			suffixes = append(suffixes, [2]string{scheme + "://", "." + rest})
+			continue
+		}
+		exact[o] = true
+	}
+
+	return func(origin string) bool {
+		if exact[origin] {
+			return true
+		}
+		for _, s := range suffixes {
+			if !strings.HasPrefix(origin, s[0]) || !strings.HasSuffix(origin, s[1]) {
+				continue
+			}
+			// The wildcard covers exactly one non-empty DNS label
+			label := strings.TrimSuffix(strings.TrimPrefix(origin, s[0]), s[1])
+			if label != "" && !strings.ContainsAny(label, "./:") {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -104,3 +104,98 @@ func TestCORS_Preflight(t *testing.T) {
 		t.Errorf("Expected status %d for preflight, got %d", http.StatusNoContent, w.Code)
 	}
 }
+
+func TestCORS_AllowedOrigins(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowOrigins = []string{"https://app.example.com", "https://*.booking.test"}
+
+	r := gin.New()
+	r.Use(CORSWithConfig(config))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://tickets.booking.test", true},
+		{"https://evil.example.com", false},
+		{"http://tickets.booking.test", false}, // scheme must match
+		{"https://a.b.booking.test", false},    // wildcard covers one label
+		{"https://booking.test", false},        // wildcard needs a label
+		{"https://evil.com/https://app.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Origin", tt.origin)
+			r.ServeHTTP(w, req)
+
+			// Disallowed origins still reach the handler, just without CORS headers
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+			got := w.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Expected Access-Control-Allow-Origin %s, got %q", tt.origin, got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("Expected no Access-Control-Allow-Origin, got %q", got)
+			}
+			if tt.allowed && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("Expected Access-Control-Allow-Credentials for an allowed origin")
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Error("Expected Vary: Origin")
+			}
+		})
+	}
+}
+
+func TestCORS_PreflightNeverProxied(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowOrigins = []string{"https://app.example.com"}
+	config.MaxAge = 600
+
+	proxied := 0
+	r := gin.New()
+	r.Use(CORSWithConfig(config))
+	// Stands in for the catch-all proxy route
+	r.Any("/api/v1/*path", func(c *gin.Context) {
+		proxied++
+		c.Status(http.StatusOK)
+	})
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/bookings/reserve", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://app.example.com")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 for allowed preflight, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Expected Access-Control-Max-Age 600, got %q", got)
+	}
+
+	w = preflight("https://evil.example.com")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for disallowed preflight, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected no Access-Control-Allow-Origin for disallowed preflight")
+	}
+
+	if proxied != 0 {
+		t.Errorf("Expected no preflight to reach the proxy, got %d", proxied)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	pkgmiddleware "github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
@@ -44,6 +45,10 @@ type RateLimitConfig struct {
 	CleanupInterval time.Duration
 	// Entry TTL for local rate limiter
 	EntryTTL time.Duration
+	// TierResolver returns the limits for a tenant's pricing tier. It is
+	// consulted when a tenant_id is in the request context; a non-positive
+	// rps or burst means the tenant has no tier and the limits above apply.
+	TierResolver func(tenantID string) (rps, burst int)
 }
 
 // EndpointRateLimitConfig holds per-endpoint rate limiting configuration
@@ -74,6 +79,11 @@ type PerEndpointRateLimitConfig struct {
 	CleanupInterval time.Duration
 	// Entry TTL for local rate limiter
 	EntryTTL time.Duration
+	// TierResolver returns the limits for a tenant's pricing tier, which
+	// replace the endpoint limits for that tenant. A tenant_id is only in the
+	// context when an identifying middleware such as pkgmiddleware.Identify
+	// runs first.
+	TierResolver func(tenantID string) (rps, burst int)
 }
 
 // DefaultRateLimitConfig returns sensible defaults
@@ -196,10 +206,11 @@ type RedisRateLimiter struct {
 	script string
 }
 
-// NewRedisRateLimiter creates a new Redis rate limiter
-func NewRedisRateLimiter(config RateLimitConfig) *RedisRateLimiter {
-	// Lua script for atomic token bucket rate limiting
-	script := `
+// rateLimitScriptName registers rateLimitScript with the Redis client
+const rateLimitScriptName = "rate_limit"
+
+// rateLimitScript is the Lua script for atomic token bucket rate limiting
+const rateLimitScript = `
 local key = KEYS[1]
 local rate = tonumber(ARGV[1])
 local burst = tonumber(ARGV[2])
@@ -227,9 +238,18 @@ else
     return {0, tokens}
 end
 `
+
+// RedisScripts returns the Lua scripts the Redis rate limiter runs, by name,
+// for preloading at startup
+func RedisScripts() map[string]string {
+	return map[string]string{rateLimitScriptName: rateLimitScript}
+}
+
+// NewRedisRateLimiter creates a new Redis rate limiter
+func NewRedisRateLimiter(config RateLimitConfig) *RedisRateLimiter {
 	return &RedisRateLimiter{
 		config: config,
-		script: script,
+		script: rateLimitScript,
 	}
 }
 
@@ -243,7 +263,7 @@ func (rl *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, error)
 func (rl *RedisRateLimiter) AllowWithRemaining(ctx context.Context, key string, rps, burst int) (bool, float64, error) {
 	now := float64(time.Now().UnixNano()) / 1e9
 
-	result := rl.config.RedisClient.Eval(ctx, rl.script,
+	result := rl.config.RedisClient.EvalWithFallback(ctx, rateLimitScriptName, rl.script,
 		[]string{rl.config.KeyPrefix + key},
 		float64(rps),
 		float64(burst),
@@ -303,10 +323,36 @@ func (rl *RedisRateLimiter) AllowWithRemaining(ctx context.Context, key string,
 	return allowed == 1, remaining, nil
 }
 
-// RateLimiter creates a rate limiting middleware
+// tenantRateLimit resolves the tier limits for the request's tenant and the
+// bucket key, which is per user (or per IP when unauthenticated) within the
+// tenant. ok is false when there is no tenant or the tenant has no tier.
+func tenantRateLimit(c *gin.Context, resolver func(tenantID string) (rps, burst int)) (key string, rps, burst int, ok bool) {
+	if resolver == nil {
+		return "", 0, 0, false
+	}
+	tenantID := c.GetString(pkgmiddleware.ContextKeyTenantID)
+	if tenantID == "" {
+		return "", 0, 0, false
+	}
+	rps, burst = resolver(tenantID)
+	if rps <= 0 || burst <= 0 {
+		return "", 0, 0, false
+	}
+
+	subject := "ip:" + c.ClientIP()
+	if userID := c.GetString(pkgmiddleware.ContextKeyUserID); userID != "" {
+		subject = "user:" + userID
+	}
+	return "tenant:" + tenantID + ":" + subject, rps, burst, true
+}
+
+// RateLimiter creates a rate limiting middleware. Requests are limited per
+// client IP, or per tenant and user when config.TierResolver finds a tier for
+// the request's tenant; mount it after authentication for tiers to apply.
 func RateLimiter(config RateLimitConfig) gin.HandlerFunc {
 	var localLimiter *LocalRateLimiter
 	var redisLimiter *RedisRateLimiter
+	var tierLimiters sync.Map // map[string]*LocalRateLimiter keyed by "rps:burst"
 
 	if config.UseRedis && config.RedisClient != nil {
 		redisLimiter = NewRedisRateLimiter(config)
@@ -314,6 +360,25 @@ func RateLimiter(config RateLimitConfig) gin.HandlerFunc {
 		localLimiter = NewLocalRateLimiter(config)
 	}
 
+	// getTierLimiter returns or creates the local rate limiter for a tier's limits
+	getTierLimiter := func(rps, burst int) *LocalRateLimiter {
+		key := fmt.Sprintf("%d:%d", rps, burst)
+		if limiter, ok := tierLimiters.Load(key); ok {
+			return limiter.(*LocalRateLimiter)
+		}
+		limiter := NewLocalRateLimiter(RateLimitConfig{
+			RequestsPerSecond: rps,
+			BurstSize:         burst,
+			CleanupInterval:   config.CleanupInterval,
+			EntryTTL:          config.EntryTTL,
+		})
+		actual, loaded := tierLimiters.LoadOrStore(key, limiter)
+		if loaded {
+			limiter.Stop()
+		}
+		return actual.(*LocalRateLimiter)
+	}
+
 	return func(c *gin.Context) {
 		ctx, span := telemetry.StartSpan(c.Request.Context(), "middleware.rate_limiter")
 		defer span.End()
@@ -329,26 +394,40 @@ func RateLimiter(config RateLimitConfig) gin.HandlerFunc {
 
 		startTime := time.Now()
 
+		// Tenants with a tier get their own limits and buckets
+		key, rps, burst := clientIP, config.RequestsPerSecond, config.BurstSize
+		tierKey, tierRPS, tierBurst, tiered := tenantRateLimit(c, config.TierResolver)
+		if tiered {
+			key, rps, burst = tierKey, tierRPS, tierBurst
+			span.SetAttributes(
+				attribute.String("tenant_id", c.GetString(pkgmiddleware.ContextKeyTenantID)),
+				attribute.Int("rps", rps),
+				attribute.Int("burst", burst),
+			)
+		}
+
 		if redisLimiter != nil {
-			allowed, err = redisLimiter.Allow(ctx, clientIP)
+			allowed, _, err = redisLimiter.AllowWithRemaining(ctx, key, rps, burst)
 			if err != nil {
 				// Fallback to allowing on Redis errors (fail open)
 				allowed = true
 			}
+		} else if tiered {
+			allowed = getTierLimiter(rps, burst).Allow(key)
 		} else {
-			allowed = localLimiter.Allow(clientIP)
+			allowed = localLimiter.Allow(key)
 		}
 
 		span.SetAttributes(attribute.Bool("allowed", allowed))
 
 		// Calculate remaining tokens (approximation for headers)
-		remaining = config.BurstSize - 1
+		remaining = burst - 1
 		if !allowed {
 			remaining = 0
 		}
 
 		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", strconv.Itoa(config.RequestsPerSecond))
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rps))
 		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
 
@@ -383,47 +462,13 @@ func RateLimiterWithDefault() gin.HandlerFunc {
 	return RateLimiter(DefaultRateLimitConfig())
 }
 
-// GlobalRateLimiter implements global (non-per-IP) rate limiting for spike protection
-type GlobalRateLimiter struct {
-	maxConcurrent int64
-	currentCount  int64
-	mu            sync.Mutex
-}
+// GlobalRateLimiter implements global (non-per-IP) rate limiting for spike
+// protection. It is shared with the services through pkg/middleware.
+type GlobalRateLimiter = pkgmiddleware.GlobalRateLimiter
 
 // NewGlobalRateLimiter creates a new global rate limiter
 func NewGlobalRateLimiter(maxConcurrent int64) *GlobalRateLimiter {
-	return &GlobalRateLimiter{
-		maxConcurrent: maxConcurrent,
-	}
-}
-
-// Acquire tries to acquire a slot
-func (g *GlobalRateLimiter) Acquire() bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	if g.currentCount >= g.maxConcurrent {
-		return false
-	}
-	g.currentCount++
-	return true
-}
-
-// Release releases a slot
-func (g *GlobalRateLimiter) Release() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	if g.currentCount > 0 {
-		g.currentCount--
-	}
-}
-
-// CurrentCount returns the current concurrent request count
-func (g *GlobalRateLimiter) CurrentCount() int64 {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return g.currentCount
+	return pkgmiddleware.NewGlobalRateLimiter(maxConcurrent)
 }
 
 // ConcurrencyLimiter creates a middleware that limits concurrent requests
@@ -517,6 +562,39 @@ func DefaultPerEndpointConfig() PerEndpointRateLimitConfig {
 	}
 }
 
+// ParseTenantTiers parses tier limits of the form
+// "tenant-a=500:50,tenant-b=2000:200" (tenant=rps:burst) into a TierResolver.
+// Tenants not listed resolve to no tier.
+func ParseTenantTiers(spec string) (func(tenantID string) (rps, burst int), error) {
+	type tier struct{ rps, burst int }
+	tiers := make(map[string]tier)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, limits, ok := strings.Cut(entry, "=")
+		rpsStr, burstStr, ok2 := strings.Cut(limits, ":")
+		if !ok || !ok2 || tenantID == "" {
+			return nil, fmt.Errorf("invalid tenant tier %q: want tenant=rps:burst", entry)
+		}
+		rps, err := strconv.Atoi(rpsStr)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("invalid rps in tenant tier %q", entry)
+		}
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("invalid burst in tenant tier %q", entry)
+		}
+		tiers[tenantID] = tier{rps: rps, burst: burst}
+	}
+
+	return func(tenantID string) (int, int) {
+		t := tiers[tenantID]
+		return t.rps, t.burst
+	}, nil
+}
+
 // matchPath checks if a request path matches a pattern
 // Supports wildcards: * matches any segment, ** matches any number of segments
 func matchPath(pattern, path string) bool {
@@ -644,8 +722,14 @@ func PerEndpointRateLimiter(config PerEndpointRateLimitConfig) gin.HandlerFunc {
 		// Get client IP as rate limit key
 		clientIP := c.ClientIP()
 
-		// Get rate limit config for this endpoint
+		// Get rate limit config for this endpoint; tenants with a tier get their
+		// own limits and buckets
+		key := clientIP
 		rps, burst := config.findEndpointConfig(method, path)
+		if tierKey, tierRPS, tierBurst, tiered := tenantRateLimit(c, config.TierResolver); tiered {
+			key, rps, burst = tierKey, tierRPS, tierBurst
+			span.SetAttributes(attribute.String("tenant_id", c.GetString(pkgmiddleware.ContextKeyTenantID)))
+		}
 
 		span.SetAttributes(
 			attribute.String("client_ip", clientIP),
@@ -666,7 +750,7 @@ func PerEndpointRateLimiter(config PerEndpointRateLimitConfig) gin.HandlerFunc {
 
 		if redisLimiter != nil {
 			// For Redis, include the rate config in the key for per-endpoint limits
-			redisKey := fmt.Sprintf("%s:%d:%d", clientIP, rps, burst)
+			redisKey := fmt.Sprintf("%s:%d:%d", key, rps, burst)
 			var err error
 			allowed, remainingTokens, err = redisLimiter.AllowWithRemaining(ctx, redisKey, rps, burst)
 			if err != nil {
@@ -676,7 +760,7 @@ func PerEndpointRateLimiter(config PerEndpointRateLimitConfig) gin.HandlerFunc {
 			}
 		} else {
 			limiter := getLimiter(rps, burst)
-			allowed, remainingTokens = limiter.AllowWithRemaining(clientIP)
+			allowed, remainingTokens = limiter.AllowWithRemaining(key)
 		}
 
 		span.SetAttributes(attribute.Bool("allowed", allowed))
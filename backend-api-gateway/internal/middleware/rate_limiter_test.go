@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	pkgmiddleware "github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 )
 
 func TestLocalRateLimiter_Allow(t *testing.T) {
@@ -351,6 +353,236 @@ func TestRateLimiterMiddleware_RejectsExcessRequests(t *testing.T) {
 	}
 }
 
+// setupTieredRateLimiterRouter mounts RateLimiter behind a stand-in for the
+// JWT middleware that copies X-Tenant-ID and X-User-ID into the context
+func setupTieredRateLimiterRouter(config RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+
+	r.Use(func(c *gin.Context) {
+		if tenantID := c.GetHeader("X-Tenant-ID"); tenantID != "" {
+			c.Set(pkgmiddleware.ContextKeyTenantID, tenantID)
+		}
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			c.Set(pkgmiddleware.ContextKeyUserID, userID)
+		}
+		c.Next()
+	})
+	r.Use(RateLimiter(config))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+// countAllowed sends n requests and returns how many were not rate limited
+func countAllowed(r *gin.Engine, n int, remoteAddr, tenantID, userID string) (allowed int, limitHeader string) {
+	for i := 0; i < n; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		if tenantID != "" {
+			req.Header.Set("X-Tenant-ID", tenantID)
+		}
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		r.ServeHTTP(w, req)
+		if w.Code == http.StatusOK {
+			allowed++
+		}
+		limitHeader = w.Header().Get("X-RateLimit-Limit")
+	}
+	return allowed, limitHeader
+}
+
+func TestRateLimiterMiddleware_TenantTiers(t *testing.T) {
+	config := RateLimitConfig{
+		RequestsPerSecond: 1,
+		BurstSize:         2,
+		CleanupInterval:   time.Minute,
+		EntryTTL:          time.Minute,
+		TierResolver: func(tenantID string) (int, int) {
+			switch tenantID {
+			case "tenant-premium":
+				return 50, 5
+			case "tenant-free":
+				return 10, 1
+			}
+			return 0, 0
+		},
+	}
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		tenantID      string
+		userID        string
+		expectAllowed int
+		expectLimit   string
+	}{
+		{name: "premium tier", remoteAddr: "10.0.0.1:1", tenantID: "tenant-premium", userID: "user-1", expectAllowed: 5, expectLimit: "50"},
+		{name: "free tier", remoteAddr: "10.0.0.2:1", tenantID: "tenant-free", userID: "user-1", expectAllowed: 1, expectLimit: "10"},
+		{name: "tenant without tier falls back to defaults", remoteAddr: "10.0.0.3:1", tenantID: "tenant-unknown", userID: "user-1", expectAllowed: 2, expectLimit: "1"},
+		{name: "no tenant falls back to defaults", remoteAddr: "10.0.0.4:1", userID: "user-1", expectAllowed: 2, expectLimit: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupTieredRateLimiterRouter(config)
+
+			allowed, limit := countAllowed(r, 10, tt.remoteAddr, tt.tenantID, tt.userID)
+			if allowed != tt.expectAllowed {
+				t.Errorf("Expected %d allowed requests, got %d", tt.expectAllowed, allowed)
+			}
+			if limit != tt.expectLimit {
+				t.Errorf("Expected X-RateLimit-Limit %s, got %s", tt.expectLimit, limit)
+			}
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_TenantTierKeysPerUser(t *testing.T) {
+	config := RateLimitConfig{
+		RequestsPerSecond: 1,
+		BurstSize:         1,
+		CleanupInterval:   time.Minute,
+		EntryTTL:          time.Minute,
+		TierResolver: func(tenantID string) (int, int) {
+			return 10, 2
+		},
+	}
+	r := setupTieredRateLimiterRouter(config)
+
+	// Users behind the same IP and tenant have separate buckets
+	if allowed, _ := countAllowed(r, 5, "10.0.0.1:1", "tenant-a", "user-1"); allowed != 2 {
+		t.Errorf("Expected 2 allowed requests for user-1, got %d", allowed)
+	}
+	if allowed, _ := countAllowed(r, 5, "10.0.0.1:1", "tenant-a", "user-2"); allowed != 2 {
+		t.Errorf("Expected 2 allowed requests for user-2, got %d", allowed)
+	}
+	// The same user under another tenant has its own bucket too
+	if allowed, _ := countAllowed(r, 5, "10.0.0.1:1", "tenant-b", "user-1"); allowed != 2 {
+		t.Errorf("Expected 2 allowed requests for user-1 in tenant-b, got %d", allowed)
+	}
+}
+
+func TestTenantRateLimit_Key(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resolver := func(tenantID string) (int, int) { return 10, 2 }
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+	c.Request.RemoteAddr = "10.0.0.9:1"
+	c.Set(pkgmiddleware.ContextKeyTenantID, "tenant-a")
+
+	if key, _, _, ok := tenantRateLimit(c, resolver); !ok || key != "tenant:tenant-a:ip:10.0.0.9" {
+		t.Errorf("Expected IP-keyed tenant bucket, got %q (ok=%v)", key, ok)
+	}
+
+	c.Set(pkgmiddleware.ContextKeyUserID, "user-1")
+	if key, _, _, ok := tenantRateLimit(c, resolver); !ok || key != "tenant:tenant-a:user:user-1" {
+		t.Errorf("Expected user-keyed tenant bucket, got %q (ok=%v)", key, ok)
+	}
+
+	if _, _, _, ok := tenantRateLimit(c, nil); ok {
+		t.Error("Expected no tier without a resolver")
+	}
+}
+
+func TestPerEndpointRateLimiter_TenantTiers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const secret = "test-secret"
+	resolver, err := ParseTenantTiers("tenant-premium=50:5, tenant-free=10:1")
+	if err != nil {
+		t.Fatalf("ParseTenantTiers() error = %v", err)
+	}
+
+	r := gin.New()
+	r.Use(pkgmiddleware.Identify(&pkgmiddleware.JWTConfig{Secret: secret}))
+	r.Use(PerEndpointRateLimiter(PerEndpointRateLimitConfig{
+		Default:         RateLimitConfig{RequestsPerSecond: 1, BurstSize: 2},
+		CleanupInterval: time.Minute,
+		EntryTTL:        time.Minute,
+		TierResolver:    resolver,
+	}))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	countAllowedWithToken := func(remoteAddr, tenantID, tokenSecret string) (allowed int, limitHeader string) {
+		token := ""
+		if tenantID != "" {
+			token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"user_id":   "user-1",
+				"tenant_id": tenantID,
+				"exp":       time.Now().Add(time.Hour).Unix(),
+			}).SignedString([]byte(tokenSecret))
+		}
+		for i := 0; i < 10; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = remoteAddr
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code == http.StatusOK {
+				allowed++
+			}
+			limitHeader = w.Header().Get("X-RateLimit-Limit")
+		}
+		return allowed, limitHeader
+	}
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		tenantID      string
+		tokenSecret   string
+		expectAllowed int
+		expectLimit   string
+	}{
+		{name: "premium tier", remoteAddr: "10.0.0.1:1", tenantID: "tenant-premium", tokenSecret: secret, expectAllowed: 5, expectLimit: "50"},
+		{name: "free tier", remoteAddr: "10.0.0.2:1", tenantID: "tenant-free", tokenSecret: secret, expectAllowed: 1, expectLimit: "10"},
+		{name: "tenant without tier falls back to endpoint limits", remoteAddr: "10.0.0.3:1", tenantID: "tenant-unknown", tokenSecret: secret, expectAllowed: 2, expectLimit: "1"},
+		{name: "anonymous request falls back to endpoint limits", remoteAddr: "10.0.0.4:1", expectAllowed: 2, expectLimit: "1"},
+		{name: "forged token gets no tier", remoteAddr: "10.0.0.5:1", tenantID: "tenant-premium", tokenSecret: "wrong-secret", expectAllowed: 2, expectLimit: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, limit := countAllowedWithToken(tt.remoteAddr, tt.tenantID, tt.tokenSecret)
+			if allowed != tt.expectAllowed {
+				t.Errorf("Expected %d allowed requests, got %d", tt.expectAllowed, allowed)
+			}
+			if limit != tt.expectLimit {
+				t.Errorf("Expected X-RateLimit-Limit %s, got %s", tt.expectLimit, limit)
+			}
+		})
+	}
+}
+
+func TestParseTenantTiers(t *testing.T) {
+	resolver, err := ParseTenantTiers("tenant-a=500:50,tenant-b=2000:200")
+	if err != nil {
+		t.Fatalf("ParseTenantTiers() error = %v", err)
+	}
+	if rps, burst := resolver("tenant-b"); rps != 2000 || burst != 200 {
+		t.Errorf("resolver(tenant-b) = %d, %d, want 2000, 200", rps, burst)
+	}
+	if rps, burst := resolver("tenant-c"); rps != 0 || burst != 0 {
+		t.Errorf("resolver(tenant-c) = %d, %d, want no tier", rps, burst)
+	}
+
+	for _, spec := range []string{"tenant-a", "tenant-a=500", "=500:50", "tenant-a=x:50", "tenant-a=500:0"} {
+		if _, err := ParseTenantTiers(spec); err == nil {
+			t.Errorf("ParseTenantTiers(%q) expected error", spec)
+		}
+	}
+}
+
 func TestPerEndpointRateLimiterMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
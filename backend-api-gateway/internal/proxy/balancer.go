@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// backendInstance is one backend server of a service
+type backendInstance struct {
+	target    *url.URL
+	weight    int
+	current   int // smooth weighted round-robin counter
+	breaker   *circuitBreaker
+	transport http.RoundTripper
+}
+
+// loadBalancer spreads requests for one service across its instances using smooth
+// weighted round-robin, skipping instances whose circuit breaker would reject the
+// request: open ones, and half-open ones whose single probe is already in flight.
+// Instances of a service are expected to share the same base path.
+type loadBalancer struct {
+	mu        sync.Mutex
+	instances []*backendInstance
+}
+
+// next picks the instance for the next request, or nil if no breaker is ready
+func (lb *loadBalancer) next() *backendInstance {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var best *backendInstance
+	total := 0
+	for _, inst := range lb.instances {
+		if !inst.breaker.Ready() {
+			continue
+		}
+		inst.current += inst.weight
+		total += inst.weight
+		if best == nil || inst.current > best.current {
+			best = inst
+		}
+	}
+
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+// RoundTrip implements http.RoundTripper by forwarding to the selected instance
+func (lb *loadBalancer) RoundTrip(req *http.Request) (*http.Response, error) {
+	inst := lb.next()
+	if inst == nil {
+		return nil, errCircuitOpen
+	}
+
+	// RoundTrippers must not modify the caller's request
+	out := new(http.Request)
+	*out = *req
+	u := *req.URL
+	u.Scheme = inst.target.Scheme
+	u.Host = inst.target.Host
+	out.URL = &u
+	out.Host = inst.target.Host
+
+	return inst.transport.RoundTrip(out)
+}
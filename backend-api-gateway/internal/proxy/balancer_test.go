@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingBackend starts a backend that counts the requests it receives
+func countingBackend(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(backend.Close)
+	return backend, &hits
+}
+
+func TestReverseProxyWeightedRoundRobin(t *testing.T) {
+	heavy, heavyHits := countingBackend(t, http.StatusOK)
+	light, lightHits := countingBackend(t, http.StatusOK)
+
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:     "test-service",
+					BaseURLs: []string{heavy.URL, light.URL},
+					Weights:  []int{3, 1},
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	for i := 0; i < 400; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+	}
+
+	if heavyHits.Load() != 300 || lightHits.Load() != 100 {
+		t.Errorf("Expected 300/100 split, got %d/%d", heavyHits.Load(), lightHits.Load())
+	}
+}
+
+func TestReverseProxyBalancerSkipsOpenBreaker(t *testing.T) {
+	failing, failingHits := countingBackend(t, http.StatusServiceUnavailable)
+	healthy, healthyHits := countingBackend(t, http.StatusOK)
+
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:             "test-service",
+					BaseURLs:         []string{failing.URL, healthy.URL},
+					BreakerThreshold: 2,
+					BreakerCooldown:  time.Minute,
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	// Round-robin alternates until the failing instance trips its breaker
+	for i := 0; i < 4; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil))
+	}
+	if state := rp.BreakerStates()[failing.URL]; state != BreakerOpen {
+		t.Fatalf("Expected failing instance breaker open, got %s", state)
+	}
+
+	// All further traffic goes to the healthy instance
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	}
+	if failingHits.Load() != 2 {
+		t.Errorf("Expected failing instance to see 2 requests, got %d", failingHits.Load())
+	}
+	if healthyHits.Load() != 12 {
+		t.Errorf("Expected healthy instance to see 12 requests, got %d", healthyHits.Load())
+	}
+}
+
+func TestLoadBalancerSkipsHalfOpenBreakerWithProbeInFlight(t *testing.T) {
+	probing := newCircuitBreaker(1, time.Minute)
+	probing.RecordFailure()
+	probing.openedAt = time.Now().Add(-2 * time.Minute)
+	if !probing.Ready() || !probing.Allow() {
+		t.Fatal("Expected the cooled-down breaker to admit one probe")
+	}
+
+	lb := &loadBalancer{instances: []*backendInstance{
+		{weight: 1, breaker: probing},
+		{weight: 1, breaker: newCircuitBreaker(1, time.Minute)},
+	}}
+
+	for i := 0; i < 4; i++ {
+		if inst := lb.next(); inst == nil || inst.breaker == probing {
+			t.Fatal("Expected the instance with a probe in flight to be skipped")
+		}
+	}
+
+	// Once the probe succeeds the instance rejoins the rotation
+	probing.RecordSuccess()
+	picked := false
+	for i := 0; i < 4; i++ {
+		if lb.next().breaker == probing {
+			picked = true
+		}
+	}
+	if !picked {
+		t.Error("Expected the recovered instance to be picked again")
+	}
+}
@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a backend circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed forwards requests normally
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen short-circuits requests until the cooldown elapses
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single probe request through to test recovery
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// errCircuitOpen is returned by the transport when a backend's breaker is open
+var errCircuitOpen = errors.New("circuit breaker open")
+
+// circuitBreaker tracks consecutive backend failures for one service
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// newCircuitBreaker creates a closed breaker that opens after threshold consecutive failures
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a request may be sent to the backend
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Ready reports whether Allow would currently let a request through, without
+// claiming the half-open probe slot
+func (b *circuitBreaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return b.now().Sub(b.openedAt) >= b.cooldown
+	case BreakerHalfOpen:
+		return !b.probing
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the breaker and resets the failure count
+func (b *circuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// RecordFailure counts a failure, opening the breaker at the threshold or when a probe fails
+func (b *circuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// RecordIgnored releases a probe whose outcome says nothing about backend health
+func (b *circuitBreaker) RecordIgnored() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State returns the current breaker state
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// breakerTransport guards a backend with a circuit breaker. Transport errors and
+// 502/503/504 responses count as failures.
+type breakerTransport struct {
+	base    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.Allow() {
+		return nil, errCircuitOpen
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		// The client went away; the backend may be fine
+		t.breaker.RecordIgnored()
+	case err != nil && isBodyTooLargeError(err):
+		// The client exceeded the body limit; the backend never saw a complete request
+		t.breaker.RecordIgnored()
+	case err != nil:
+		t.breaker.RecordFailure()
+	case isBackendUnavailableStatus(resp.StatusCode):
+		t.breaker.RecordFailure()
+	default:
+		t.breaker.RecordSuccess()
+	}
+
+	return resp, err
+}
+
+// isBackendUnavailableStatus reports whether a response status signals an unhealthy backend
+func isBackendUnavailableStatus(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
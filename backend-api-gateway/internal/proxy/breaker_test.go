@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(3, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// Opens after threshold consecutive failures
+	for i := 0; i < 2; i++ {
+		breaker.RecordFailure()
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("Expected breaker closed below threshold, got %s", breaker.State())
+	}
+	breaker.RecordFailure()
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("Expected breaker open at threshold, got %s", breaker.State())
+	}
+	if breaker.Allow() {
+		t.Error("Expected open breaker to reject requests")
+	}
+
+	// Half-opens after cooldown and lets a single probe through
+	now = now.Add(time.Minute)
+	if !breaker.Allow() {
+		t.Fatal("Expected probe to be allowed after cooldown")
+	}
+	if breaker.Allow() {
+		t.Error("Expected only one probe while half-open")
+	}
+
+	// A failed probe reopens the breaker
+	breaker.RecordFailure()
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("Expected failed probe to reopen breaker, got %s", breaker.State())
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	breaker.Allow()
+	breaker.RecordSuccess()
+	if breaker.State() != BreakerClosed {
+		t.Errorf("Expected successful probe to close breaker, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker := newCircuitBreaker(2, time.Minute)
+
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+
+	if breaker.State() != BreakerClosed {
+		t.Errorf("Expected non-consecutive failures to keep breaker closed, got %s", breaker.State())
+	}
+}
+
+func TestReverseProxyCircuitBreakerFailsFast(t *testing.T) {
+	var hits atomic.Int32
+	var healthy atomic.Bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:             "test-service",
+					BaseURL:          backend.URL,
+					BreakerThreshold: 3,
+					BreakerCooldown:  100 * time.Millisecond,
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil))
+		return w
+	}
+
+	// Trip the breaker
+	for i := 0; i < 3; i++ {
+		get()
+	}
+	if state := rp.BreakerStates()[backend.URL]; state != BreakerOpen {
+		t.Fatalf("Expected breaker open, got %s", state)
+	}
+
+	// Requests fail fast without reaching the backend
+	w := get()
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 while open, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if code := body["error"].(map[string]interface{})["code"]; code != "SERVICE_UNAVAILABLE" {
+		t.Errorf("Expected SERVICE_UNAVAILABLE, got %v", code)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("Expected backend to see 3 requests, got %d", hits.Load())
+	}
+
+	// After the cooldown a probe reaches the recovered backend and closes the breaker
+	healthy.Store(true)
+	time.Sleep(150 * time.Millisecond)
+	if w := get(); w.Code != http.StatusOK {
+		t.Errorf("Expected probe to succeed, got %d", w.Code)
+	}
+	if state := rp.BreakerStates()[backend.URL]; state != BreakerClosed {
+		t.Errorf("Expected breaker closed after recovery, got %s", state)
+	}
+}
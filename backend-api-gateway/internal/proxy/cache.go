@@ -0,0 +1,155 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCachedBodyBytes is the largest response body the cache stores
+	maxCachedBodyBytes = 1 << 20
+	// maxCacheEntries bounds the number of responses held in memory
+	maxCacheEntries = 10000
+)
+
+// cacheKeyKey carries the response cache key on the proxied request context
+type cacheKeyKey struct{}
+
+// cachedResponse is a stored backend response
+type cachedResponse struct {
+	header    http.Header
+	body      []byte
+	expiresAt time.Time
+}
+
+// responseCache is an in-memory cache of 200 responses for routes with a CacheTTL
+type responseCache struct {
+	mu      sync.RWMutex
+	entries map[string]*cachedResponse
+}
+
+// newResponseCache creates an empty response cache
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]*cachedResponse)}
+}
+
+// responseCacheKey returns the cache key for a request: method, path and raw query
+func responseCacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery
+}
+
+// isCacheable reports whether a request may be served from or stored in the cache.
+// Only anonymous GETs on public routes are cached, since the key does not vary
+// by user: a public prefix can still hold per-user routes (/events/my) that the
+// backend authenticates from the Authorization header or a cookie.
+func isCacheable(route *RouteConfig, req *http.Request) bool {
+	return route.CacheTTL > 0 && !route.RequireAuth && req.Method == http.MethodGet &&
+		req.Header.Get("Authorization") == "" && req.Header.Get("Cookie") == ""
+}
+
+// clientBypassesCache reports whether the client asked for a fresh response
+func clientBypassesCache(req *http.Request) bool {
+	cacheControl := strings.ToLower(req.Header.Get("Cache-Control"))
+	return strings.Contains(cacheControl, "no-cache") ||
+		strings.Contains(cacheControl, "no-store") ||
+		strings.EqualFold(req.Header.Get("Pragma"), "no-cache")
+}
+
+// get returns the unexpired response stored under key
+func (rc *responseCache) get(key string) (*cachedResponse, bool) {
+	rc.mu.RLock()
+	entry, ok := rc.entries[key]
+	rc.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// set stores a response under key for ttl. When the cache is full, expired
+// entries are evicted first; if none have expired the response is not stored.
+func (rc *responseCache) set(key string, header http.Header, body []byte, ttl time.Duration) {
+	now := time.Now()
+	entry := &cachedResponse{header: header, body: body, expiresAt: now.Add(ttl)}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, exists := rc.entries[key]; !exists && len(rc.entries) >= maxCacheEntries {
+		for k, e := range rc.entries {
+			if now.After(e.expiresAt) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// withCacheKey returns a context that asks the proxy hooks to store the response under key
+func withCacheKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, cacheKeyKey{}, key)
+}
+
+// storeResponse caches a cacheable backend response, leaving its body readable
+// for the client. Responses that are not 200, stream, set cookies, forbid
+// shared caching or Vary on request headers the key ignores are passed through
+// untouched.
+func (rc *responseCache) storeResponse(resp *http.Response) error {
+	if resp.Request == nil {
+		return nil
+	}
+	key, _ := resp.Request.Context().Value(cacheKeyKey{}).(string)
+	route, _ := resp.Request.Context().Value(routeKey{}).(*RouteConfig)
+	if key == "" || route == nil {
+		return nil
+	}
+	resp.Header.Set("X-Cache", "MISS")
+
+	if resp.StatusCode != http.StatusOK || isEventStream(resp) || resp.Header.Get("Set-Cookie") != "" {
+		return nil
+	}
+	cacheControl := strings.ToLower(resp.Header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "private") {
+		return nil
+	}
+	if resp.Header.Get("Vary") != "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBodyBytes+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > maxCachedBodyBytes {
+		// Too large to cache: hand the client what was read plus the rest
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("X-Cache")
+	rc.set(key, header, body, route.CacheTTL)
+	return nil
+}
+
+// writeCached replays a cached response
+func writeCached(w http.ResponseWriter, entry *cachedResponse) {
+	for name, values := range entry.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("X-Cache", "HIT")
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.body)
+}
@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCachingProxy returns a router proxying to a backend that counts its calls
+// and answers /missing with 404
+func newCachingProxy(t *testing.T, route RouteConfig) (*gin.Engine, *int32) {
+	t.Helper()
+	var calls int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/api/v1/events/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/api/v1/events/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/api/v1/events/vary":
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `,"auth":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	t.Cleanup(backend.Close)
+
+	route.Service = ServiceConfig{Name: "ticket-service", BaseURL: backend.URL}
+	rp := NewReverseProxy(ProxyConfig{Routes: []RouteConfig{route}})
+
+	router := gin.New()
+	router.Any(route.PathPrefix+"/*path", rp.Handler())
+	return router, &calls
+}
+
+func serve(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReverseProxy_ResponseCache_HitWithinTTL(t *testing.T) {
+	router, calls := newCachingProxy(t, RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute})
+
+	first := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/events/list?page=1", nil))
+	second := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/events/list?page=1", nil))
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Fatalf("Expected 1 backend call, got %d", got)
+	}
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected X-Cache MISS then HIT, got %q then %q",
+			first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+	}
+	if second.Code != http.StatusOK || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected cached 200 %q, got %d %q", first.Body.String(), second.Code, second.Body.String())
+	}
+	if ct := second.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected cached Content-Type application/json, got %q", ct)
+	}
+
+	// A different query is a different key
+	serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/events/list?page=2", nil))
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("Expected 2 backend calls, got %d", got)
+	}
+}
+
+func TestReverseProxy_ResponseCache_Bypassed(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  RouteConfig
+		method string
+		path   string
+		header map[string]string
+	}{
+		{
+			name:   "POST is never cached",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodPost,
+			path:   "/api/v1/events/list",
+		},
+		{
+			name:   "client no-cache",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/list",
+			header: map[string]string{"Cache-Control": "no-cache"},
+		},
+		{
+			name:   "non-200 response",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/missing",
+		},
+		{
+			name:   "route without CacheTTL",
+			route:  RouteConfig{PathPrefix: "/api/v1/events"},
+			method: http.MethodGet,
+			path:   "/api/v1/events/list",
+		},
+		{
+			name:   "request with Authorization",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/my",
+			header: map[string]string{"Authorization": "Bearer organizer-a"},
+		},
+		{
+			name:   "request with Cookie",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/my",
+			header: map[string]string{"Cookie": "session=organizer-a"},
+		},
+		{
+			name:   "private response",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/private",
+		},
+		{
+			name:   "response with Vary",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute},
+			method: http.MethodGet,
+			path:   "/api/v1/events/vary",
+		},
+		{
+			name:   "route requiring auth",
+			route:  RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute, RequireAuth: true},
+			method: http.MethodGet,
+			path:   "/api/v1/events/list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, calls := newCachingProxy(t, tt.route)
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				for name, value := range tt.header {
+					req.Header.Set(name, value)
+				}
+				if w := serve(router, req); w.Header().Get("X-Cache") == "HIT" {
+					t.Errorf("Request %d served from cache", i+1)
+				}
+			}
+
+			if got := atomic.LoadInt32(calls); got != 2 {
+				t.Errorf("Expected 2 backend calls, got %d", got)
+			}
+		})
+	}
+}
+
+func TestReverseProxy_ResponseCache_Expires(t *testing.T) {
+	router, calls := newCachingProxy(t, RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: 20 * time.Millisecond})
+
+	serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/events/list", nil))
+	time.Sleep(40 * time.Millisecond)
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/events/list", nil))
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("Expected 2 backend calls after TTL, got %d", got)
+	}
+	if w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Expected X-Cache MISS after TTL, got %q", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestReverseProxy_ResponseCache_NotSharedBetweenUsers(t *testing.T) {
+	router, calls := newCachingProxy(t, RouteConfig{PathPrefix: "/api/v1/events", CacheTTL: time.Minute})
+
+	get := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/my", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return serve(router, req)
+	}
+
+	organizerA := get("Bearer organizer-a")
+	organizerB := get("Bearer organizer-b")
+	anonymous := get("")
+
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Fatalf("Expected 3 backend calls, got %d", got)
+	}
+	for name, w := range map[string]*httptest.ResponseRecorder{"organizer B": organizerB, "anonymous": anonymous} {
+		if strings.Contains(w.Body.String(), "organizer-a") {
+			t.Errorf("%s was served organizer A's response: %s", name, w.Body.String())
+		}
+	}
+	if !strings.Contains(organizerA.Body.String(), "organizer-a") || !strings.Contains(organizerB.Body.String(), "organizer-b") {
+		t.Errorf("Expected each organizer to get their own response, got %q and %q",
+			organizerA.Body.String(), organizerB.Body.String())
+	}
+}
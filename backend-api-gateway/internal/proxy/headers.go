@@ -0,0 +1,33 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+)
+
+// routeKey carries the matched route on the proxied request context
+type routeKey struct{}
+
+// withRoute returns a context that exposes the matched route to the proxy hooks
+func withRoute(ctx context.Context, route *RouteConfig) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// applyResponseHeaderRules strips and adds the response headers configured on the matched route.
+// Removal runs first so a route can replace a backend header with its own value.
+func applyResponseHeaderRules(resp *http.Response) {
+	if resp.Request == nil {
+		return
+	}
+	route, _ := resp.Request.Context().Value(routeKey{}).(*RouteConfig)
+	if route == nil {
+		return
+	}
+
+	for _, name := range route.RemoveResponseHeaders {
+		resp.Header.Del(name)
+	}
+	for name, value := range route.AddResponseHeaders {
+		resp.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReverseProxyResponseHeaderRules(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25")
+		w.Header().Set("X-Powered-By", "Go")
+		w.Header().Set("X-Content-Type-Options", "sniff")
+		w.Header().Set("X-Request-ID", "req-1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/secure",
+				Service:    ServiceConfig{Name: "test-service", BaseURL: backend.URL},
+				AddResponseHeaders: map[string]string{
+					"X-Content-Type-Options":    "nosniff",
+					"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				},
+				RemoveResponseHeaders: []string{"server", "X-Powered-By"},
+			},
+			{
+				PathPrefix: "/api/v1/plain",
+				Service:    ServiceConfig{Name: "test-service", BaseURL: backend.URL},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/secure/*path", rp.Handler())
+	router.Any("/api/v1/plain/*path", rp.Handler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/secure/resource", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	for _, name := range []string{"Server", "X-Powered-By"} {
+		if v := w.Header().Get(name); v != "" {
+			t.Errorf("Expected %s to be removed, got %q", name, v)
+		}
+	}
+	if v := w.Header().Get("X-Content-Type-Options"); v != "nosniff" {
+		t.Errorf("Expected X-Content-Type-Options nosniff, got %q", v)
+	}
+	if v := w.Header().Get("Strict-Transport-Security"); v != "max-age=31536000; includeSubDomains" {
+		t.Errorf("Expected Strict-Transport-Security to be added, got %q", v)
+	}
+	if v := w.Header().Get("X-Request-ID"); v != "req-1" {
+		t.Errorf("Expected unrelated headers to pass through, got %q", v)
+	}
+
+	// Rules are per route: the other route on the same service is untouched
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/plain/resource", nil))
+	if v := w.Header().Get("Server"); v != "nginx/1.25" {
+		t.Errorf("Expected Server header on plain route, got %q", v)
+	}
+	if v := w.Header().Get("Strict-Transport-Security"); v != "" {
+		t.Errorf("Expected no Strict-Transport-Security on plain route, got %q", v)
+	}
+}
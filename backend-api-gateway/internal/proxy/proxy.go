@@ -2,30 +2,49 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/httperr"
 	pkgmiddleware "github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// streamFlushInterval is how often streamed responses are flushed to the client
+const streamFlushInterval = 100 * time.Millisecond
+
 // ServiceConfig holds configuration for a backend service
 type ServiceConfig struct {
-	Name    string
+	Name string
+	// BaseURL is the shorthand for a service with a single instance
 	BaseURL string
+	// BaseURLs lists the service's instances for load balancing (takes precedence over BaseURL)
+	BaseURLs []string
+	// Weights sets the relative share of traffic for each entry in BaseURLs (missing or zero = 1)
+	Weights []int
 	Timeout time.Duration
+	// Connection pool tuning; zero values fall back to the ProxyConfig defaults
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
+	// Circuit breaker tuning; zero values fall back to the ProxyConfig defaults
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 // RouteConfig holds configuration for a route
@@ -40,21 +59,52 @@ type RouteConfig struct {
 	RequireAuth bool
 	// AllowedMethods restricts which HTTP methods are allowed (empty = all)
 	AllowedMethods []string
+	// MaxRetries is how many times GET/HEAD requests are retried on backend connection errors
+	MaxRetries int
+	// Timeout overrides the service and default timeouts for this route (0 = inherit)
+	Timeout time.Duration
+	// AddResponseHeaders are set on every response from this route (e.g., security headers)
+	AddResponseHeaders map[string]string
+	// RemoveResponseHeaders are stripped from backend responses (e.g., "Server", "X-Powered-By")
+	RemoveResponseHeaders []string
+	// CacheTTL caches 200 GET responses for this long, keyed on method, path and query (0 = off).
+	// Ignored on routes that require auth and for requests carrying credentials, since
+	// cached responses are shared between users.
+	CacheTTL time.Duration
+	// AllowedCIDRs restricts the route to clients in these ranges (empty = any source).
+	// Bare IPs are accepted; requests from elsewhere get 403 before JWT validation.
+	AllowedCIDRs []string
+
+	// allowedNets is AllowedCIDRs parsed once by NewReverseProxy
+	allowedNets []*net.IPNet
 }
 
 // ProxyConfig holds the overall proxy configuration
 type ProxyConfig struct {
-	Routes        []RouteConfig
+	Routes         []RouteConfig
 	DefaultTimeout time.Duration
-	JWTSecret     string
+	JWTSecret      string
+	// Default connection pool tuning for backend transports
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
+	// BreakerThreshold is the number of consecutive backend failures that opens a service's circuit breaker
+	BreakerThreshold int
+	// BreakerCooldown is how long an open breaker fails fast before probing the backend again
+	BreakerCooldown time.Duration
+	// MaxRequestBodyBytes caps the request body forwarded to backends (0 = unlimited)
+	MaxRequestBodyBytes int64
 }
 
 // ReverseProxy manages routing to backend services
 type ReverseProxy struct {
-	config   ProxyConfig
-	proxies  map[string]*httputil.ReverseProxy
-	mu       sync.RWMutex
-	client   *http.Client
+	config     ProxyConfig
+	proxies    map[string]*httputil.ReverseProxy
+	transports map[string]*http.Transport // keyed by backend base URL
+	breakers   map[string]*circuitBreaker // keyed by backend base URL
+	mu         sync.RWMutex
+	client     *http.Client
+	cache      *responseCache
 }
 
 // NewReverseProxy creates a new reverse proxy instance
@@ -62,28 +112,39 @@ func NewReverseProxy(config ProxyConfig) *ReverseProxy {
 	if config.DefaultTimeout == 0 {
 		config.DefaultTimeout = 30 * time.Second
 	}
+	// MaxIdleConnsPerHost defaults to 15000 to handle 10K+ SSE connections at scale
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 15000
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = 90 * time.Second
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = 30 * time.Second
+	}
+	if config.BreakerThreshold == 0 {
+		config.BreakerThreshold = 5
+	}
+	if config.BreakerCooldown == 0 {
+		config.BreakerCooldown = 10 * time.Second
+	}
 
-	// Create optimized HTTP transport for high performance
-	// MaxIdleConns/MaxIdleConnsPerHost set to 15000 to handle 10K+ SSE connections at scale
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          15000,
-		MaxIdleConnsPerHost:   15000,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   5 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		DisableCompression:    false,
-		ForceAttemptHTTP2:     true,
+	// Parse source ACLs once; copy the routes so the caller's config is untouched
+	routes := make([]RouteConfig, len(config.Routes))
+	copy(routes, config.Routes)
+	for i := range routes {
+		routes[i].allowedNets = parseCIDRs(routes[i].AllowedCIDRs)
 	}
+	config.Routes = routes
 
 	rp := &ReverseProxy{
-		config:  config,
-		proxies: make(map[string]*httputil.ReverseProxy),
+		config:     config,
+		proxies:    make(map[string]*httputil.ReverseProxy),
+		transports: make(map[string]*http.Transport),
+		breakers:   make(map[string]*circuitBreaker),
+		cache:      newResponseCache(),
 		client: &http.Client{
-			Transport: transport,
+			Transport: newTransport(config.MaxIdleConnsPerHost, config.IdleConnTimeout, config.KeepAlive),
 			Timeout:   config.DefaultTimeout,
 		},
 	}
@@ -98,35 +159,74 @@ func NewReverseProxy(config ProxyConfig) *ReverseProxy {
 	return rp
 }
 
+// instanceURLs returns the base URLs of all instances of the service
+func (s ServiceConfig) instanceURLs() []string {
+	if len(s.BaseURLs) > 0 {
+		return s.BaseURLs
+	}
+	return []string{s.BaseURL}
+}
+
+// instanceWeight returns the load-balancing weight of the i-th instance
+func (s ServiceConfig) instanceWeight(i int) int {
+	if i < len(s.Weights) && s.Weights[i] > 0 {
+		return s.Weights[i]
+	}
+	return 1
+}
+
 // initProxy initializes a reverse proxy for a service
 func (rp *ReverseProxy) initProxy(service ServiceConfig) {
-	targetURL, err := url.Parse(service.BaseURL)
-	if err != nil {
-		return
+	balancer := &loadBalancer{}
+	for i, baseURL := range service.instanceURLs() {
+		targetURL, err := url.Parse(baseURL)
+		if err != nil {
+			return
+		}
+
+		breaker := rp.breakerFor(service, baseURL)
+		balancer.instances = append(balancer.instances, &backendInstance{
+			target:  targetURL,
+			weight:  service.instanceWeight(i),
+			breaker: breaker,
+			transport: &breakerTransport{
+				base:    rp.transportFor(service, baseURL),
+				breaker: breaker,
+			},
+		})
 	}
 
+	// The director uses the first instance for path rewriting; the balancer picks the host
+	targetURL := balancer.instances[0].target
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
-	proxy.Transport = rp.client.Transport
+	// Retries sit above the balancer so each attempt picks an instance again and
+	// fails over, and every failed attempt counts against that instance's breaker
+	proxy.Transport = &retryTransport{base: balancer, backoff: defaultRetryBackoff}
+	// text/event-stream responses are flushed after every write; other streamed
+	// responses are flushed periodically so they are never held until completion
+	proxy.FlushInterval = streamFlushInterval
 
 	// Custom director to modify requests before forwarding
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
 		req.Host = targetURL.Host
+		// Propagate the gateway span so backend spans join the same trace
+		telemetry.InjectHTTPHeaders(req.Context(), req.Header)
 	}
 
 	// Custom error handler
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		w.Header().Set("Content-Type", "application/json")
-		if isTimeoutError(err) {
-			w.WriteHeader(http.StatusGatewayTimeout)
-			io.WriteString(w, `{"success":false,"error":{"code":"GATEWAY_TIMEOUT","message":"Backend service timed out"}}`)
+		if isBodyTooLargeError(err) {
+			httperr.WriteHTTP(w, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body too large")
+		} else if errors.Is(err, errCircuitOpen) {
+			httperr.WriteHTTP(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Backend service temporarily unavailable")
+		} else if isTimeoutError(err) {
+			httperr.WriteHTTP(w, http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", "Backend service timed out")
 		} else if isConnectionError(err) {
-			w.WriteHeader(http.StatusBadGateway)
-			io.WriteString(w, `{"success":false,"error":{"code":"BAD_GATEWAY","message":"Backend service unavailable"}}`)
+			httperr.WriteHTTP(w, http.StatusBadGateway, "BAD_GATEWAY", "Backend service unavailable")
 		} else {
-			w.WriteHeader(http.StatusBadGateway)
-			io.WriteString(w, `{"success":false,"error":{"code":"BAD_GATEWAY","message":"Backend service error"}}`)
+			httperr.WriteHTTP(w, http.StatusBadGateway, "BAD_GATEWAY", "Backend service error")
 		}
 	}
 
@@ -134,7 +234,12 @@ func (rp *ReverseProxy) initProxy(service ServiceConfig) {
 	proxy.ModifyResponse = func(resp *http.Response) error {
 		// Add gateway headers
 		resp.Header.Set("X-Proxied-By", "api-gateway")
-		return nil
+		applyResponseHeaderRules(resp)
+		if isEventStream(resp) {
+			// Tell nginx-style intermediaries in front of the gateway not to buffer the stream
+			resp.Header.Set("X-Accel-Buffering", "no")
+		}
+		return rp.cache.storeResponse(resp)
 	}
 
 	rp.mu.Lock()
@@ -142,33 +247,218 @@ func (rp *ReverseProxy) initProxy(service ServiceConfig) {
 	rp.mu.Unlock()
 }
 
-// findRoute finds the matching route for a request
+// transportFor returns the shared transport for a backend base URL,
+// creating it on first use so every route to the same backend shares one pool
+func (rp *ReverseProxy) transportFor(service ServiceConfig, baseURL string) *http.Transport {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if transport, exists := rp.transports[baseURL]; exists {
+		return transport
+	}
+
+	maxIdleConnsPerHost := service.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost == 0 {
+		maxIdleConnsPerHost = rp.config.MaxIdleConnsPerHost
+	}
+	idleConnTimeout := service.IdleConnTimeout
+	if idleConnTimeout == 0 {
+		idleConnTimeout = rp.config.IdleConnTimeout
+	}
+	keepAlive := service.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = rp.config.KeepAlive
+	}
+
+	transport := newTransport(maxIdleConnsPerHost, idleConnTimeout, keepAlive)
+	rp.transports[baseURL] = transport
+	return transport
+}
+
+// proxyBalancer returns the load balancer beneath a service proxy's retry layer
+func proxyBalancer(p *httputil.ReverseProxy) *loadBalancer {
+	if retry, ok := p.Transport.(*retryTransport); ok {
+		balancer, _ := retry.base.(*loadBalancer)
+		return balancer
+	}
+	return nil
+}
+
+// breakerFor returns the circuit breaker for a backend base URL, creating it on first use
+func (rp *ReverseProxy) breakerFor(service ServiceConfig, baseURL string) *circuitBreaker {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if breaker, exists := rp.breakers[baseURL]; exists {
+		return breaker
+	}
+
+	threshold := service.BreakerThreshold
+	if threshold == 0 {
+		threshold = rp.config.BreakerThreshold
+	}
+	cooldown := service.BreakerCooldown
+	if cooldown == 0 {
+		cooldown = rp.config.BreakerCooldown
+	}
+
+	breaker := newCircuitBreaker(threshold, cooldown)
+	rp.breakers[baseURL] = breaker
+	return breaker
+}
+
+// BreakerStates returns the circuit breaker state of each backend base URL, for metrics
+func (rp *ReverseProxy) BreakerStates() map[string]BreakerState {
+	rp.mu.RLock()
+	defer rp.mu.RUnlock()
+
+	states := make(map[string]BreakerState, len(rp.breakers))
+	for baseURL, breaker := range rp.breakers {
+		states[baseURL] = breaker.State()
+	}
+	return states
+}
+
+// newTransport creates an HTTP transport tuned for high-throughput proxying
+func newTransport(maxIdleConnsPerHost int, idleConnTimeout, keepAlive time.Duration) *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: keepAlive,
+		}).DialContext,
+		MaxIdleConns:          maxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableCompression:    false,
+		ForceAttemptHTTP2:     true,
+	}
+}
+
+// parseCIDRs parses CIDR ranges, treating bare IPs as single-host ranges.
+// Invalid entries are skipped, so a route whose ranges are all invalid
+// admits no one.
+func parseCIDRs(cidrs []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	return nets
+}
+
+// allowsSource reports whether a client IP may use the route
+func (r *RouteConfig) allowsSource(clientIP string) bool {
+	if len(r.AllowedCIDRs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range r.allowedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// abortIfSourceDenied rejects requests from outside the route's AllowedCIDRs
+// with 403 and reports whether it did. The source is the client IP as resolved
+// by the engine, so the engine must only trust X-Forwarded-For from known proxies
+// (see gin.Engine.SetTrustedProxies).
+func abortIfSourceDenied(c *gin.Context, route *RouteConfig) bool {
+	if route.allowsSource(c.ClientIP()) {
+		return false
+	}
+	httperr.Abort(c, http.StatusForbidden, "FORBIDDEN", "Source address not allowed")
+	return true
+}
+
+// findRoute finds the matching route for a request.
+// The longest matching PathPrefix wins regardless of declaration order;
+// among routes with the same prefix the first declared one wins.
 func (rp *ReverseProxy) findRoute(path, method string) *RouteConfig {
+	var best *RouteConfig
 	for _, route := range rp.config.Routes {
-		if strings.HasPrefix(path, route.PathPrefix) {
-			// Check method if restricted
-			if len(route.AllowedMethods) > 0 {
-				allowed := false
-				for _, m := range route.AllowedMethods {
-					if strings.EqualFold(m, method) {
-						allowed = true
-						break
-					}
-				}
-				if !allowed {
-					continue
+		if !strings.HasPrefix(path, route.PathPrefix) {
+			continue
+		}
+		if best != nil && len(route.PathPrefix) <= len(best.PathPrefix) {
+			continue
+		}
+		// Check method if restricted
+		if len(route.AllowedMethods) > 0 {
+			allowed := false
+			for _, m := range route.AllowedMethods {
+				if strings.EqualFold(m, method) {
+					allowed = true
+					break
 				}
 			}
-			return &route
+			if !allowed {
+				continue
+			}
 		}
+		best = &route
 	}
-	return nil
+	return best
+}
+
+// allowedMethods lists the methods accepted under the route prefixes matching
+// path, sorted and upper-cased. It is only meaningful when findRoute returned
+// nil: an empty result means the path is unknown, otherwise the path exists
+// and the method is not allowed.
+func (rp *ReverseProxy) allowedMethods(path string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, route := range rp.config.Routes {
+		if !strings.HasPrefix(path, route.PathPrefix) {
+			continue
+		}
+		for _, m := range route.AllowedMethods {
+			m = strings.ToUpper(m)
+			if !seen[m] {
+				seen[m] = true
+				methods = append(methods, m)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// abortNoRoute answers a request no route accepts: 405 with an Allow header
+// when the path is known under other methods, notFoundCode otherwise
+func (rp *ReverseProxy) abortNoRoute(c *gin.Context, notFoundCode, notFoundMsg string) {
+	if methods := rp.allowedMethods(c.Request.URL.Path); len(methods) > 0 {
+		c.Header("Allow", strings.Join(methods, ", "))
+		httperr.Abort(c, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method not allowed for this path")
+		return
+	}
+	httperr.Abort(c, http.StatusNotFound, notFoundCode, notFoundMsg)
 }
 
 // Handler returns a Gin handler for proxying requests
 func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, span := telemetry.StartSpan(c.Request.Context(), "gateway.proxy")
+		ctx, span := telemetry.StartSpan(c.Request.Context(), "gateway.proxy", trace.WithSpanKind(trace.SpanKindClient))
 		defer span.End()
 		c.Request = c.Request.WithContext(ctx)
 
@@ -179,20 +469,18 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 
 		route := rp.findRoute(c.Request.URL.Path, c.Request.Method)
 		if route == nil {
-			span.SetStatus(codes.Error, "No route configured for this path")
-			c.JSON(http.StatusNotFound, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "ROUTE_NOT_FOUND",
-					"message": "No route configured for this path",
-				},
-			})
-			c.Abort()
+			span.SetStatus(codes.Error, "No route configured for this path and method")
+			rp.abortNoRoute(c, "ROUTE_NOT_FOUND", "No route configured for this path")
 			return
 		}
 
 		span.SetAttributes(attribute.String("target.service", route.Service.Name))
 
+		if abortIfSourceDenied(c, route) {
+			span.SetStatus(codes.Error, "Source address not allowed")
+			return
+		}
+
 		// Get proxy for this service
 		rp.mu.RLock()
 		proxy, exists := rp.proxies[route.Service.Name]
@@ -200,17 +488,24 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 
 		if !exists {
 			span.SetStatus(codes.Error, "Backend service not configured")
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "SERVICE_NOT_CONFIGURED",
-					"message": "Backend service not configured",
-				},
-			})
-			c.Abort()
+			httperr.Abort(c, http.StatusInternalServerError, "SERVICE_NOT_CONFIGURED", "Backend service not configured")
 			return
 		}
 
+		// Serve cacheable reads from the response cache unless the client wants a fresh copy
+		var cacheKey string
+		if isCacheable(route, c.Request) {
+			cacheKey = responseCacheKey(c.Request)
+			if !clientBypassesCache(c.Request) {
+				if entry, ok := rp.cache.get(cacheKey); ok {
+					span.SetAttributes(attribute.Bool("cache.hit", true))
+					writeCached(c.Writer, entry)
+					c.Abort()
+					return
+				}
+			}
+		}
+
 		// Strip prefix if configured
 		if route.StripPrefix != "" {
 			c.Request.URL.Path = strings.TrimPrefix(c.Request.URL.Path, route.StripPrefix)
@@ -238,14 +533,32 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 			c.Request.Header.Set("X-Request-ID", requestID)
 		}
 
-		// Set timeout context
-		timeout := route.Service.Timeout
-		if timeout == 0 {
-			timeout = rp.config.DefaultTimeout
+		// WebSocket upgrades are tunnelled directly instead of going through httputil.ReverseProxy
+		if isWebSocketUpgrade(c.Request) {
+			if balancer := proxyBalancer(proxy); balancer != nil {
+				rp.serveWebSocket(c, route, balancer)
+				return
+			}
 		}
-		timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+
+		// Enforce the request body limit before forwarding
+		if limit := rp.config.MaxRequestBodyBytes; limit > 0 && c.Request.Body != nil {
+			if c.Request.ContentLength > limit {
+				span.SetStatus(codes.Error, "Request body too large")
+				httperr.Abort(c, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body too large")
+				return
+			}
+			// Chunked bodies of unknown length are cut off once they pass the limit
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+
+		// Set timeout context; the deadline cancels the upstream request and maps to 504
+		timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), rp.routeTimeout(route))
 		defer cancel()
-		c.Request = c.Request.WithContext(timeoutCtx)
+		c.Request = c.Request.WithContext(withRoute(withMaxRetries(timeoutCtx, route.MaxRetries), route))
+		if cacheKey != "" {
+			c.Request = c.Request.WithContext(withCacheKey(c.Request.Context(), cacheKey))
+		}
 
 		span.SetStatus(codes.Ok, "")
 
@@ -264,7 +577,7 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 					span.RecordError(fmt.Errorf("panic: %v", r))
 					// Write error response if possible
 					if !c.Writer.Written() {
-						c.JSON(500, gin.H{"error": "internal server error", "panic": fmt.Sprintf("%v", r)})
+						httperr.Write(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 					}
 				}
 			}()
@@ -273,20 +586,44 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 	}
 }
 
+// isEventStream reports whether the response is a Server-Sent Events stream
+func isEventStream(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// routeTimeout returns the effective timeout for a route: route, then service, then default
+func (rp *ReverseProxy) routeTimeout(route *RouteConfig) time.Duration {
+	if route.Timeout > 0 {
+		return route.Timeout
+	}
+	if route.Service.Timeout > 0 {
+		return route.Service.Timeout
+	}
+	return rp.config.DefaultTimeout
+}
+
 // isTimeoutError checks if error is a timeout
 func isTimeoutError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
 		return true
 	}
-	if err == context.DeadlineExceeded {
+	if errors.Is(err, context.DeadlineExceeded) {
 		return true
 	}
 	return strings.Contains(err.Error(), "timeout")
 }
 
+// isBodyTooLargeError checks if error comes from exceeding the request body limit
+func isBodyTooLargeError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
 // isConnectionError checks if error is a connection error
 func isConnectionError(err error) bool {
 	if err == nil {
@@ -299,6 +636,9 @@ func isConnectionError(err error) bool {
 		strings.Contains(err.Error(), "no such host")
 }
 
+// defaultAdminAllowedCIDRs limits admin routes to loopback and private networks
+const defaultAdminAllowedCIDRs = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"
+
 // getEnvOrDefault returns environment variable value or default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -417,6 +757,7 @@ func ConfigFromEnv(authURL, ticketURL, bookingURL, paymentURL, jwtSecret string)
 				},
 				RequireAuth:    false,
 				AllowedMethods: []string{"GET"},
+				CacheTTL:       2 * time.Second, // absorbs listing refreshes during a rush
 			},
 			// Events/Tickets - Write operations need auth
 			{
@@ -509,7 +850,8 @@ func ConfigFromEnv(authURL, ticketURL, bookingURL, paymentURL, jwtSecret string)
 					BaseURL: bookingURL,
 					Timeout: 60 * time.Second, // Sync may take longer
 				},
-				RequireAuth: true,
+				RequireAuth:  true,
+				AllowedCIDRs: strings.Split(getEnvOrDefault("ADMIN_ALLOWED_CIDRS", defaultAdminAllowedCIDRs), ","),
 			},
 			// Payments - all protected
 			{
@@ -588,7 +930,7 @@ func (rp *ReverseProxy) HealthCheck(ctx context.Context) map[string]bool {
 		go func(name string, service ServiceConfig) {
 			defer wg.Done()
 
-			healthURL := fmt.Sprintf("%s/health", service.BaseURL)
+			healthURL := fmt.Sprintf("%s/health", service.instanceURLs()[0])
 			req, err := http.NewRequestWithContext(ctx, "GET", healthURL, nil)
 			if err != nil {
 				mu.Lock()
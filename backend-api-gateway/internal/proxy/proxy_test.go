@@ -3,12 +3,20 @@ package proxy
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func init() {
@@ -32,6 +40,72 @@ func TestNewReverseProxy(t *testing.T) {
 	}
 }
 
+func TestReverseProxySharesTransportPerBaseURL(t *testing.T) {
+	config := ProxyConfig{
+		IdleConnTimeout: 45 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/bookings",
+				Service: ServiceConfig{
+					Name:                "booking-service",
+					BaseURL:             "http://localhost:8083",
+					MaxIdleConnsPerHost: 500,
+				},
+			},
+			{
+				PathPrefix: "/api/v1/queue",
+				Service: ServiceConfig{
+					Name:    "booking-queue",
+					BaseURL: "http://localhost:8083",
+				},
+			},
+			{
+				PathPrefix: "/api/v1/auth",
+				Service: ServiceConfig{
+					Name:      "auth-service",
+					BaseURL:   "http://localhost:8081",
+					KeepAlive: 10 * time.Second,
+				},
+			},
+		},
+	}
+
+	rp := NewReverseProxy(config)
+
+	bookingTransport := backendTransport(rp.proxies["booking-service"])
+	queueTransport := backendTransport(rp.proxies["booking-queue"])
+	authTransport := backendTransport(rp.proxies["auth-service"])
+
+	if bookingTransport != queueTransport {
+		t.Error("Expected routes to the same BaseURL to share a transport")
+	}
+	if bookingTransport == authTransport {
+		t.Error("Expected different BaseURLs to use different transports")
+	}
+	if len(rp.transports) != 2 {
+		t.Errorf("Expected 2 transports, got %d", len(rp.transports))
+	}
+
+	booking := rp.transports["http://localhost:8083"]
+	if booking.MaxIdleConnsPerHost != 500 {
+		t.Errorf("Expected MaxIdleConnsPerHost 500, got %d", booking.MaxIdleConnsPerHost)
+	}
+	if booking.IdleConnTimeout != 45*time.Second {
+		t.Errorf("Expected IdleConnTimeout 45s, got %v", booking.IdleConnTimeout)
+	}
+
+	auth := rp.transports["http://localhost:8081"]
+	if auth.MaxIdleConnsPerHost != 15000 {
+		t.Errorf("Expected default MaxIdleConnsPerHost 15000, got %d", auth.MaxIdleConnsPerHost)
+	}
+}
+
+// backendTransport unwraps the retry, balancer and breaker layers around a proxy's pooled transport
+func backendTransport(p *httputil.ReverseProxy) http.RoundTripper {
+	inst := proxyBalancer(p).instances[0]
+	return inst.transport.(*breakerTransport).base
+}
+
 func TestFindRoute(t *testing.T) {
 	config := ProxyConfig{
 		Routes: []RouteConfig{
@@ -145,6 +219,86 @@ func TestFindRoute(t *testing.T) {
 	}
 }
 
+func TestFindRouteLongestPrefix(t *testing.T) {
+	broad := RouteConfig{
+		PathPrefix: "/api/v1",
+		Service:    ServiceConfig{Name: "default-service", BaseURL: "http://localhost:8080"},
+	}
+	bookings := RouteConfig{
+		PathPrefix: "/api/v1/bookings",
+		Service:    ServiceConfig{Name: "booking-service", BaseURL: "http://localhost:8083"},
+	}
+	bookingsAdmin := RouteConfig{
+		PathPrefix:     "/api/v1/bookings/admin",
+		AllowedMethods: []string{"DELETE"},
+		Service:        ServiceConfig{Name: "admin-service", BaseURL: "http://localhost:8085"},
+	}
+
+	orders := map[string][]RouteConfig{
+		"broad first":    {broad, bookings, bookingsAdmin},
+		"specific first": {bookingsAdmin, bookings, broad},
+		"mixed":          {bookings, broad, bookingsAdmin},
+	}
+
+	tests := []struct {
+		path          string
+		method        string
+		expectService string
+	}{
+		{"/api/v1/bookings/123", "GET", "booking-service"},
+		{"/api/v1/bookings", "POST", "booking-service"},
+		{"/api/v1/bookings/admin/1", "DELETE", "admin-service"},
+		// Method mismatch falls back to the next most specific prefix
+		{"/api/v1/bookings/admin/1", "GET", "booking-service"},
+		{"/api/v1/events", "GET", "default-service"},
+	}
+
+	for name, routes := range orders {
+		rp := NewReverseProxy(ProxyConfig{Routes: routes})
+		for _, tt := range tests {
+			t.Run(name+" "+tt.method+" "+tt.path, func(t *testing.T) {
+				route := rp.findRoute(tt.path, tt.method)
+				if route == nil {
+					t.Fatal("Expected route to be found")
+				}
+				if route.Service.Name != tt.expectService {
+					t.Errorf("Expected %s, got %s", tt.expectService, route.Service.Name)
+				}
+			})
+		}
+	}
+}
+
+func TestReverseProxy_Handler_MethodNotAllowed(t *testing.T) {
+	config := ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix:     "/api/v1/webhooks",
+				AllowedMethods: []string{"POST"},
+				Service:        ServiceConfig{Name: "payment-service", BaseURL: "http://localhost:8084"},
+			},
+		},
+	}
+
+	rp := NewReverseProxy(config)
+	handler := rp.Handler()
+
+	w := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(w)
+
+	r.GET("/api/v1/webhooks/*path", handler)
+
+	req := httptest.NewRequest("GET", "/api/v1/webhooks/stripe", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+	if got := w.Header().Get("Allow"); got != "POST" {
+		t.Errorf("Expected Allow header 'POST', got '%s'", got)
+	}
+}
+
 func TestReverseProxy_Handler_NotFound(t *testing.T) {
 	config := ProxyConfig{
 		Routes: []RouteConfig{
@@ -299,6 +453,7 @@ func TestIsTimeoutError(t *testing.T) {
 	}{
 		{"nil error", nil, false},
 		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"wrapped deadline exceeded", fmt.Errorf("proxy: %w", context.DeadlineExceeded), true},
 	}
 
 	for _, tt := range tests {
@@ -487,3 +642,160 @@ func TestReverseProxyStripPrefix(t *testing.T) {
 		t.Errorf("Expected stripped path '/test/hello', got '%s'", receivedPath)
 	}
 }
+
+func TestReverseProxyMaxRequestBodyBytes(t *testing.T) {
+	var hits atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		MaxRequestBodyBytes: 1024,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:    "test-service",
+					BaseURL: backend.URL,
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	post := func(body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/test/upload", body))
+		return w
+	}
+
+	// Within the limit
+	if w := post(strings.NewReader(strings.Repeat("a", 1024))); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 within limit, got %d", w.Code)
+	}
+
+	// Declared length over the limit is rejected before reaching the backend
+	w := post(strings.NewReader(strings.Repeat("a", 2048)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if code := body["error"].(map[string]interface{})["code"]; code != "REQUEST_TOO_LARGE" {
+		t.Errorf("Expected REQUEST_TOO_LARGE, got %v", code)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("Expected backend to see 1 request, got %d", hits.Load())
+	}
+
+	// Bodies of unknown length are cut off while streaming
+	chunked := io.MultiReader(strings.NewReader(strings.Repeat("a", 2048)))
+	if w := post(chunked); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for streamed body, got %d", w.Code)
+	}
+	if state := rp.BreakerStates()[backend.URL]; state != BreakerClosed {
+		t.Errorf("Expected oversized bodies not to trip the breaker, got %s", state)
+	}
+}
+
+func TestReverseProxyPropagatesTraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	var traceparent string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:    "test-service",
+					BaseURL: backend.URL,
+				},
+			},
+		},
+	})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil).WithContext(ctx)
+
+	rp.Handler()(c)
+
+	if !strings.HasPrefix(traceparent, "00-"+traceID.String()+"-") {
+		t.Errorf("Expected traceparent for trace %s, got %q", traceID, traceparent)
+	}
+}
+
+func TestReverseProxyRouteTimeout(t *testing.T) {
+	backendDone := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(backendDone)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Timeout:    50 * time.Millisecond,
+				Service: ServiceConfig{
+					Name:    "test-service",
+					BaseURL: backend.URL,
+					Timeout: 5 * time.Second,
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/slow", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected status 504, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if code := body["error"].(map[string]interface{})["code"]; code != "GATEWAY_TIMEOUT" {
+		t.Errorf("Expected GATEWAY_TIMEOUT, got %v", code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected route timeout to apply, took %v", elapsed)
+	}
+
+	// The upstream request is cancelled rather than left running
+	select {
+	case <-backendDone:
+	case <-time.After(time.Second):
+		t.Error("Expected upstream request to be cancelled")
+	}
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultRetryBackoff is the base delay between retries; it grows linearly per attempt
+const defaultRetryBackoff = 50 * time.Millisecond
+
+// maxRetriesKey carries the matched route's MaxRetries on the proxied request context
+type maxRetriesKey struct{}
+
+// withMaxRetries returns a context that allows the proxy to retry the request up to maxRetries times
+func withMaxRetries(ctx context.Context, maxRetries int) context.Context {
+	return context.WithValue(ctx, maxRetriesKey{}, maxRetries)
+}
+
+// retryTransport retries idempotent requests whose connection to the backend
+// fails before any response is received. Once RoundTrip returns a response the
+// backend has started answering, so nothing is ever retried mid-stream.
+type retryTransport struct {
+	base    http.RoundTripper
+	backoff time.Duration
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries, _ := req.Context().Value(maxRetriesKey{}).(int)
+	if maxRetries <= 0 || !isIdempotentMethod(req.Method) || (req.Body != nil && req.Body != http.NoBody) {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil || attempt >= maxRetries || !isRetryableError(err) {
+			return resp, err
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, err
+		case <-time.After(time.Duration(attempt+1) * t.backoff):
+		}
+	}
+}
+
+// isIdempotentMethod reports whether a request with this method is safe to retry
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// isRetryableError reports whether the backend connection failed before a response
+func isRetryableError(err error) bool {
+	if isTimeoutError(err) {
+		return false
+	}
+	return isConnectionError(err) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		strings.Contains(err.Error(), "connection reset")
+}
@@ -0,0 +1,164 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// flakyListener drops the first n accepted connections before the backend can respond
+type flakyListener struct {
+	net.Listener
+	drop    int32
+	dropped atomic.Int32
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.dropped.Load() < l.drop {
+			l.dropped.Add(1)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// newFlakyBackend starts a backend whose first drop connections fail
+func newFlakyBackend(t *testing.T, drop int32, hits *atomic.Int32) (*httptest.Server, *flakyListener) {
+	t.Helper()
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	listener := &flakyListener{Listener: backend.Listener, drop: drop}
+	backend.Listener = listener
+	backend.Start()
+	return backend, listener
+}
+
+func newRetryTestProxy(baseURL string, maxRetries int) *gin.Engine {
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				MaxRetries: maxRetries,
+				Service: ServiceConfig{
+					Name:    "test-service",
+					BaseURL: baseURL,
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+	return router
+}
+
+func TestReverseProxyRetriesIdempotentRequest(t *testing.T) {
+	var hits atomic.Int32
+	backend, listener := newFlakyBackend(t, 1, &hits)
+	defer backend.Close()
+
+	router := newRetryTestProxy(backend.URL, 2)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 after retry, got %d", w.Code)
+	}
+	if listener.dropped.Load() != 1 {
+		t.Errorf("Expected the first connection to be dropped, dropped %d", listener.dropped.Load())
+	}
+	if hits.Load() != 1 {
+		t.Errorf("Expected backend to serve 1 request, got %d", hits.Load())
+	}
+}
+
+func TestReverseProxyDoesNotRetryNonIdempotentRequest(t *testing.T) {
+	var hits atomic.Int32
+	backend, _ := newFlakyBackend(t, 1, &hits)
+	defer backend.Close()
+
+	router := newRetryTestProxy(backend.URL, 2)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/test/resource", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502 without retry, got %d", w.Code)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("Expected POST not to be retried, backend served %d requests", hits.Load())
+	}
+}
+
+func TestReverseProxyWithoutRetriesFailsFast(t *testing.T) {
+	var hits atomic.Int32
+	backend, _ := newFlakyBackend(t, 1, &hits)
+	defer backend.Close()
+
+	router := newRetryTestProxy(backend.URL, 0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502 with retries disabled, got %d", w.Code)
+	}
+}
+
+func TestReverseProxyRetryFailsOverToAnotherInstance(t *testing.T) {
+	// A closed server's address refuses connections
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	var hits atomic.Int32
+	healthy, _ := newFlakyBackend(t, 0, &hits)
+	defer healthy.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		DefaultTimeout: 5 * time.Second,
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				MaxRetries: 1,
+				Service: ServiceConfig{
+					Name:     "test-service",
+					BaseURLs: []string{dead.URL, healthy.URL},
+				},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/test/*path", rp.Handler())
+
+	// Round-robin sends some of these to the dead instance first; the retry must fail over
+	for i := 0; i < 4; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/resource", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 after failing over, got %d", w.Code)
+		}
+	}
+	if hits.Load() != 4 {
+		t.Errorf("Expected the healthy instance to serve all 4 requests, got %d", hits.Load())
+	}
+}
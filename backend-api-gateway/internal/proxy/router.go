@@ -220,14 +220,12 @@ func (r *Router) MatchHandler() gin.HandlerFunc {
 		// Find matching route
 		route := r.proxy.findRoute(c.Request.URL.Path, c.Request.Method)
 		if route == nil {
-			c.JSON(404, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "NOT_FOUND",
-					"message": "Route not found",
-				},
-			})
-			c.Abort()
+			r.proxy.abortNoRoute(c, "NOT_FOUND", "Route not found")
+			return
+		}
+
+		// Reject untrusted sources before looking at credentials
+		if abortIfSourceDenied(c, route) {
 			return
 		}
 
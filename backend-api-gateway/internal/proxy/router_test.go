@@ -189,6 +189,61 @@ func TestRouter_MatchHandler_NotFound(t *testing.T) {
 	}
 }
 
+func TestRouter_MatchHandler_MethodNotAllowed(t *testing.T) {
+	config := ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix:     "/api/v1/webhooks",
+				AllowedMethods: []string{"post"},
+				Service:        ServiceConfig{Name: "payment-service", BaseURL: "http://localhost:8084"},
+			},
+			{
+				PathPrefix:     "/api/v1/zones",
+				AllowedMethods: []string{"PUT", "POST"},
+				Service:        ServiceConfig{Name: "ticket-service", BaseURL: "http://localhost:8082"},
+			},
+			{
+				PathPrefix:     "/api/v1/zones/bulk",
+				AllowedMethods: []string{"DELETE", "POST"},
+				Service:        ServiceConfig{Name: "ticket-service", BaseURL: "http://localhost:8082"},
+			},
+		},
+	}
+
+	rp := NewReverseProxy(config)
+	router := NewRouter(rp, "test-secret")
+	handler := router.MatchHandler()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+	}{
+		{"single allowed method", "GET", "/api/v1/webhooks/stripe", http.StatusMethodNotAllowed, "POST"},
+		{"methods of every matching prefix", "GET", "/api/v1/zones/bulk", http.StatusMethodNotAllowed, "DELETE, POST, PUT"},
+		{"unknown prefix", "GET", "/api/v1/unknown", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tt.method, tt.path, nil)
+
+			handler(c)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Expected Allow %q, got %q", tt.wantAllow, got)
+			}
+		})
+	}
+}
+
 func TestRouter_MatchHandler_ExpiredToken(t *testing.T) {
 	jwtSecret := "test-secret-key"
 
@@ -402,3 +457,143 @@ func TestRouteByMethod(t *testing.T) {
 		}
 	})
 }
+
+func TestRouter_MatchHandler_AdminRouteSourceACL(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	jwtSecret := "test-secret-key"
+
+	config := ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix:   "/api/v1/admin",
+				RequireAuth:  true,
+				AllowedCIDRs: []string{"10.0.0.0/8", "192.168.1.20", "not-a-cidr"},
+				Service: ServiceConfig{
+					Name:    "booking-service",
+					BaseURL: backend.URL,
+				},
+			},
+		},
+	}
+
+	rp := NewReverseProxy(config)
+	router := NewRouter(rp, jwtSecret)
+	handler := router.MatchHandler()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "admin-1",
+		"email":   "admin@example.com",
+		"role":    "admin",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte(jwtSecret))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		withToken  bool
+		wantStatus int
+	}{
+		{"internal source with token", "10.1.2.3:4567", true, http.StatusOK},
+		{"internal source without token", "10.1.2.3:4567", false, http.StatusUnauthorized},
+		{"single allowed host", "192.168.1.20:4567", true, http.StatusOK},
+		{"neighbour of allowed host", "192.168.1.21:4567", true, http.StatusForbidden},
+		{"external source with token", "203.0.113.5:4567", true, http.StatusForbidden},
+		{"external source without token", "203.0.113.5:4567", false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			req := httptest.NewRequest("POST", "/api/v1/admin/zones/init", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.withToken {
+				req.Header.Set("Authorization", "Bearer "+tokenString)
+			}
+			c.Request = req
+
+			handler(c)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRouter_MatchHandler_AdminRouteIgnoresSpoofedForwardedFor(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix:   "/api/v1/admin",
+				AllowedCIDRs: []string{"10.0.0.0/8"},
+				Service:      ServiceConfig{Name: "booking-service", BaseURL: backend.URL},
+			},
+		},
+	})
+	handler := NewRouter(rp, "test-secret-key").MatchHandler()
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		wantStatus     int
+	}{
+		{"spoofed header from an external client", nil, "203.0.113.5:4567", "10.0.0.1", http.StatusForbidden},
+		{"external client behind the load balancer", []string{"172.16.0.0/12"}, "172.16.0.2:4567", "203.0.113.5", http.StatusForbidden},
+		{"spoof appended to by the load balancer", []string{"172.16.0.0/12"}, "172.16.0.2:4567", "10.0.0.1, 203.0.113.5", http.StatusForbidden},
+		{"internal client behind the load balancer", []string{"172.16.0.0/12"}, "172.16.0.2:4567", "10.1.2.3", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			if err := engine.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatalf("SetTrustedProxies failed: %v", err)
+			}
+			engine.Any("/api/v1/admin/*path", handler)
+
+			req := httptest.NewRequest("POST", "/api/v1/admin/zones/init", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv_AdminRouteRestrictedToInternalNetworks(t *testing.T) {
+	rp := NewReverseProxy(ConfigFromEnv("", "", "", "", "secret"))
+
+	route := rp.findRoute("/api/v1/admin/maintenance", "POST")
+	if route == nil || route.PathPrefix != "/api/v1/admin" {
+		t.Fatalf("Expected admin route, got %+v", route)
+	}
+	for ip, want := range map[string]bool{
+		"127.0.0.1":   true,
+		"172.18.0.5":  true,
+		"10.20.30.40": true,
+		"8.8.8.8":     false,
+		"2001:db8::1": false,
+	} {
+		if got := route.allowsSource(ip); got != want {
+			t.Errorf("allowsSource(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReverseProxyStreamsServerSentEvents(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		fmt.Fprint(w, "data: position 2\n\n")
+		w.(http.Flusher).Flush()
+
+		// Hold the stream open until the client has seen the first event
+		<-release
+		fmt.Fprint(w, "data: position 1\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	defer backend.Close()
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/queue",
+				Service:    ServiceConfig{Name: "booking-service", BaseURL: backend.URL},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/queue/*path", rp.Handler())
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	resp, err := http.Get(gateway.URL + "/api/v1/queue/stream")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if v := resp.Header.Get("X-Accel-Buffering"); v != "no" {
+		t.Errorf("Expected X-Accel-Buffering no, got %q", v)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data:") {
+				lines <- line
+			}
+		}
+		close(lines)
+	}()
+
+	// The first event must arrive while the backend is still holding the stream open
+	select {
+	case line := <-lines:
+		if line != "data: position 2" {
+			t.Errorf("Expected first event, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected first event before the stream closed")
+	}
+
+	close(release)
+	select {
+	case line := <-lines:
+		if line != "data: position 1" {
+			t.Errorf("Expected second event, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected second event")
+	}
+}
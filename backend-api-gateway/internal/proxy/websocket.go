@@ -0,0 +1,159 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/httperr"
+	pkgmiddleware "github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+)
+
+// webSocketDialTimeout bounds connecting to the backend and completing the upgrade handshake
+const webSocketDialTimeout = 10 * time.Second
+
+// isWebSocketUpgrade reports whether the request asks to upgrade to WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// headerContainsToken reports whether a comma-separated header contains token (case-insensitive)
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveWebSocket completes the upgrade with a backend instance and then copies frames
+// in both directions over the hijacked connections. httputil.ReverseProxy is bypassed
+// so the long-lived connection is not subject to the route timeout.
+func (rp *ReverseProxy) serveWebSocket(c *gin.Context, route *RouteConfig, balancer *loadBalancer) {
+	// JWT middleware runs before the handler on protected routes; refuse to upgrade without it
+	if route.RequireAuth {
+		if _, exists := c.Get(pkgmiddleware.ContextKeyUserID); !exists {
+			writeWebSocketError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+			return
+		}
+	}
+
+	// Allow claims the half-open probe slot just as breakerTransport does for plain requests
+	inst := balancer.next()
+	if inst == nil || !inst.breaker.Allow() {
+		writeWebSocketError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Backend service temporarily unavailable")
+		return
+	}
+
+	backendConn, err := dialBackend(inst)
+	if err != nil {
+		inst.breaker.RecordFailure()
+		writeWebSocketError(c, http.StatusBadGateway, "BAD_GATEWAY", "Backend service unavailable")
+		return
+	}
+	defer backendConn.Close()
+
+	// Forward the handshake with the backend's host and base path
+	outReq := c.Request.Clone(c.Request.Context())
+	outReq.URL.Scheme = inst.target.Scheme
+	outReq.URL.Host = inst.target.Host
+	outReq.URL.Path = strings.TrimSuffix(inst.target.Path, "/") + c.Request.URL.Path
+	outReq.Host = inst.target.Host
+	outReq.RequestURI = ""
+
+	backendConn.SetDeadline(time.Now().Add(webSocketDialTimeout))
+	if err := outReq.Write(backendConn); err != nil {
+		inst.breaker.RecordFailure()
+		writeWebSocketError(c, http.StatusBadGateway, "BAD_GATEWAY", "Backend service unavailable")
+		return
+	}
+
+	backendReader := bufio.NewReader(backendConn)
+	resp, err := http.ReadResponse(backendReader, outReq)
+	if err != nil {
+		inst.breaker.RecordFailure()
+		writeWebSocketError(c, http.StatusBadGateway, "BAD_GATEWAY", "Backend service error")
+		return
+	}
+	backendConn.SetDeadline(time.Time{})
+
+	// The backend declined the upgrade; relay its answer as a normal response
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		if isBackendUnavailableStatus(resp.StatusCode) {
+			inst.breaker.RecordFailure()
+		} else {
+			inst.breaker.RecordSuccess()
+		}
+		for name, values := range resp.Header {
+			for _, value := range values {
+				c.Writer.Header().Add(name, value)
+			}
+		}
+		c.Status(resp.StatusCode)
+		io.Copy(c.Writer, resp.Body)
+		return
+	}
+	inst.breaker.RecordSuccess()
+
+	clientConn, clientBuf, err := c.Writer.Hijack()
+	if err != nil {
+		writeWebSocketError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "WebSocket upgrade not supported")
+		return
+	}
+	defer clientConn.Close()
+
+	// Relay the backend's 101 handshake to the client
+	fmt.Fprintf(clientBuf, "HTTP/1.1 %s\r\n", resp.Status)
+	resp.Header.Set("X-Proxied-By", "api-gateway")
+	resp.Header.Write(clientBuf)
+	clientBuf.WriteString("\r\n")
+	if err := clientBuf.Flush(); err != nil {
+		return
+	}
+
+	// Copy until either side closes; buffered readers hold any bytes read past the handshake
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backendConn, clientBuf.Reader)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(clientConn, backendReader)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// dialBackend opens a raw connection to the instance, using TLS for https backends
+func dialBackend(inst *backendInstance) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: webSocketDialTimeout}
+	host := inst.target.Host
+
+	if inst.target.Scheme == "https" {
+		if inst.target.Port() == "" {
+			host += ":443"
+		}
+		return tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: inst.target.Hostname()})
+	}
+
+	if inst.target.Port() == "" {
+		host += ":80"
+	}
+	return dialer.Dial("tcp", host)
+}
+
+// writeWebSocketError writes a JSON error before the connection has been hijacked
+func writeWebSocketError(c *gin.Context, status int, code, message string) {
+	httperr.Abort(c, status, code, message)
+}
@@ -0,0 +1,207 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webSocketAccept computes Sec-WebSocket-Accept for a handshake key (RFC 6455)
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newWebSocketEchoBackend completes the upgrade handshake and echoes every frame byte back
+func newWebSocketEchoBackend(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !isWebSocketUpgrade(r) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		buf.WriteString("Sec-WebSocket-Accept: " + webSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		buf.Flush()
+		io.Copy(conn, buf)
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+// dialWebSocket sends an upgrade request to the gateway and returns the connection and handshake response
+func dialWebSocket(t *testing.T, gatewayURL, path string) (net.Conn, *bufio.Reader, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(gatewayURL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to dial gateway: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, gatewayURL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("Failed to send handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatalf("Failed to read handshake response: %v", err)
+	}
+	return conn, reader, resp
+}
+
+func TestReverseProxyWebSocketPassthrough(t *testing.T) {
+	var hits atomic.Int32
+	backend := newWebSocketEchoBackend(t, &hits)
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/ws",
+				Timeout:    50 * time.Millisecond,
+				Service:    ServiceConfig{Name: "ws-service", BaseURL: backend.URL},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/ws/*path", rp.Handler())
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	conn, reader, resp := dialWebSocket(t, gateway.URL, "/api/v1/ws/queue")
+	defer conn.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != webSocketAccept("dGhlIHNhbXBsZSBub25jZQ==") {
+		t.Errorf("Expected backend Sec-WebSocket-Accept, got %q", accept)
+	}
+
+	// Masked text frames "hello" and "world" sent after the route timeout has elapsed
+	time.Sleep(100 * time.Millisecond)
+	for _, payload := range []string{"hello", "world"} {
+		mask := []byte{0x37, 0xfa, 0x21, 0x3d}
+		frame := []byte{0x81, 0x80 | byte(len(payload))}
+		frame = append(frame, mask...)
+		for i := 0; i < len(payload); i++ {
+			frame = append(frame, payload[i]^mask[i%4])
+		}
+
+		if _, err := conn.Write(frame); err != nil {
+			t.Fatalf("Failed to write frame: %v", err)
+		}
+		echoed := make([]byte, len(frame))
+		if _, err := io.ReadFull(reader, echoed); err != nil {
+			t.Fatalf("Failed to read echoed frame: %v", err)
+		}
+		if !bytes.Equal(echoed, frame) {
+			t.Errorf("Expected frame %x to pass through, got %x", frame, echoed)
+		}
+	}
+}
+
+func TestReverseProxyWebSocketRequiresAuth(t *testing.T) {
+	var hits atomic.Int32
+	backend := newWebSocketEchoBackend(t, &hits)
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix:  "/api/v1/ws",
+				RequireAuth: true,
+				Service:     ServiceConfig{Name: "ws-service", BaseURL: backend.URL},
+			},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/ws/*path", rp.Handler())
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	conn, _, resp := dialWebSocket(t, gateway.URL, "/api/v1/ws/queue")
+	defer conn.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("Expected backend not to be reached, got %d requests", hits.Load())
+	}
+}
+
+func TestReverseProxyWebSocketClaimsHalfOpenProbe(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(backend.Close)
+
+	rp := NewReverseProxy(ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/ws",
+				Service:    ServiceConfig{Name: "ws-service", BaseURL: backend.URL, BreakerThreshold: 1},
+			},
+		},
+	})
+
+	// Trip the breaker and let its cooldown elapse so the next request is the probe
+	breaker := rp.breakers[backend.URL]
+	breaker.RecordFailure()
+	breaker.openedAt = time.Now().Add(-time.Hour)
+
+	router := gin.New()
+	router.Any("/api/v1/ws/*path", rp.Handler())
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	done := make(chan *http.Response)
+	go func() {
+		conn, _, resp := dialWebSocket(t, gateway.URL, "/api/v1/ws/queue")
+		conn.Close()
+		done <- resp
+	}()
+
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if breaker.Ready() {
+		t.Error("Expected the upgrade to hold the half-open probe slot")
+	}
+
+	close(release)
+	if resp := <-done; resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected the backend's 400 to be relayed, got %d", resp.StatusCode)
+	}
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("Expected the successful probe to close the breaker, got %s", state)
+	}
+}
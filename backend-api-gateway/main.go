@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,7 +16,9 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-api-gateway/internal/middleware"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-api-gateway/internal/proxy"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lifecycle"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgmiddleware "github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 )
@@ -46,6 +49,9 @@ func main() {
 
 	ctx := context.Background()
 
+	// Components register closers as they start; they run in priority order on shutdown
+	shutdown := lifecycle.NewShutdownManager(30 * time.Second)
+
 	// Initialize OpenTelemetry
 	telemetryCfg := &telemetry.Config{
 		Enabled:        cfg.OTel.Enabled,
@@ -60,7 +66,7 @@ func main() {
 	} else if telemetryCfg.Enabled {
 		log.Info(fmt.Sprintf("Telemetry initialized (collector: %s)", telemetryCfg.CollectorAddr))
 	}
-	defer telemetry.Shutdown(ctx)
+	shutdown.Register("telemetry", lifecycle.PriorityFlush, telemetry.Shutdown)
 
 	// API Gateway does NOT connect to any database directly (Microservice pattern)
 	// Each service manages its own database connection
@@ -73,6 +79,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -83,8 +92,13 @@ func main() {
 	if err != nil {
 		log.Warn("Redis connection failed, /ready will report unhealthy")
 	} else {
-		defer redis.Close()
+		shutdown.RegisterFunc("redis", lifecycle.PriorityConnections, redis.Close)
 		log.Info("Redis connected")
+
+		// Pre-load Lua scripts so the first rate-limited request doesn't load them
+		if err := redis.PreloadScripts(ctx, middleware.RedisScripts()); err != nil {
+			log.Warn(fmt.Sprintf("Failed to pre-load Lua scripts: %v", err))
+		}
 	}
 
 	// Setup Gin
@@ -94,6 +108,17 @@ func main() {
 
 	router := gin.New()
 
+	// Only honour X-Forwarded-For from the load balancer (comma-separated CIDRs,
+	// e.g. the ingress pod network). Gin trusts every proxy by default, which lets
+	// clients spoof their address past route source ACLs and per-IP rate limits.
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(strings.ReplaceAll(proxies, " ", ""), ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal(fmt.Sprintf("Invalid TRUSTED_PROXIES: %v", err))
+	}
+
 	// Apply global middlewares
 	router.Use(gin.Recovery())
 
@@ -105,7 +130,15 @@ func main() {
 
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(log))
-	router.Use(middleware.CORS())
+
+	// CORS runs before routing so preflights are answered here, never proxied
+	corsConfig := middleware.DefaultCORSConfig()
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		// Comma-separated, e.g. "https://app.example.com,https://*.example.com"
+		corsConfig.AllowOrigins = strings.Split(strings.ReplaceAll(origins, " ", ""), ",")
+		log.Info(fmt.Sprintf("CORS allowed origins: %v", corsConfig.AllowOrigins))
+	}
+	router.Use(middleware.CORSWithConfig(corsConfig))
 
 	// Configure per-endpoint rate limiting (can be disabled via ENV for load testing)
 	if os.Getenv("RATE_LIMIT_ENABLED") != "false" {
@@ -117,6 +150,16 @@ func main() {
 		} else {
 			log.Info("Rate limiting enabled (local, non-distributed)")
 		}
+		// Tenant tiers need the caller's tenant, so identify callers first
+		if tiers := os.Getenv("RATE_LIMIT_TENANT_TIERS"); tiers != "" {
+			resolver, err := middleware.ParseTenantTiers(tiers)
+			if err != nil {
+				log.Fatal(fmt.Sprintf("Invalid RATE_LIMIT_TENANT_TIERS: %v", err))
+			}
+			rateLimitConfig.TierResolver = resolver
+			router.Use(pkgmiddleware.Identify(&pkgmiddleware.JWTConfig{Secret: cfg.JWT.Secret}))
+			log.Info("Per-tenant rate limit tiers enabled")
+		}
 		router.Use(middleware.PerEndpointRateLimiter(rateLimitConfig))
 	} else {
 		log.Warn("Rate limiting DISABLED (RATE_LIMIT_ENABLED=false)")
@@ -187,12 +230,11 @@ func main() {
 	<-quit
 	log.Info("Shutting down server...")
 
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	shutdown.Register("http-server", lifecycle.PriorityServer, srv.Shutdown)
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal(fmt.Sprintf("Server forced to shutdown: %v", err))
+	// Give outstanding requests and the remaining closers 30 seconds to complete
+	if err := shutdown.Shutdown(context.Background()); err != nil {
+		log.Error(fmt.Sprintf("Shutdown did not complete cleanly: %v", err))
 	}
 
 	log.Info("Server exited gracefully")
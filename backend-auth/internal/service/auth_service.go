@@ -452,6 +452,7 @@ func (s *authService) generateTokenPair(user *domain.User) (*domain.TokenPair, e
 		"email":     user.Email,
 		"role":      string(user.Role),
 		"tenant_id": user.TenantID,
+		"jti":       uuid.New().String(), // Lets the gateway blacklist the token on logout
 		"exp":       time.Now().Add(s.config.AccessTokenExpiry).Unix(),
 		"iat":       time.Now().Unix(),
 	})
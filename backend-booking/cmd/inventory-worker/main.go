@@ -67,6 +67,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -46,6 +46,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -70,16 +73,18 @@ func main() {
 	releaseInterval := getEnvDuration("QUEUE_RELEASE_INTERVAL", 1*time.Second)
 	defaultQueuePassTTL := getEnvDuration("QUEUE_DEFAULT_PASS_TTL", 5*time.Minute)
 	jwtSecret := getEnvString("QUEUE_JWT_SECRET", cfg.JWT.Secret)
+	issuanceSpread := getEnvDuration("QUEUE_ISSUANCE_SPREAD", 0) // e.g. 500ms to smooth reservation bursts
 
 	workerCfg := &worker.QueueReleaseWorkerConfig{
 		DefaultMaxConcurrent: defaultMaxConcurrent,
 		ReleaseInterval:      releaseInterval,
 		DefaultQueuePassTTL:  defaultQueuePassTTL,
 		JWTSecret:            jwtSecret,
+		IssuanceSpread:       issuanceSpread,
 	}
 
-	appLog.Info(fmt.Sprintf("Worker configuration: DefaultMaxConcurrent=%d, ReleaseInterval=%v, DefaultQueuePassTTL=%v",
-		workerCfg.DefaultMaxConcurrent, workerCfg.ReleaseInterval, workerCfg.DefaultQueuePassTTL))
+	appLog.Info(fmt.Sprintf("Worker configuration: DefaultMaxConcurrent=%d, ReleaseInterval=%v, DefaultQueuePassTTL=%v, IssuanceSpread=%v",
+		workerCfg.DefaultMaxConcurrent, workerCfg.ReleaseInterval, workerCfg.DefaultQueuePassTTL, workerCfg.IssuanceSpread))
 
 	// Create and start queue release worker (pass redis client for Pub/Sub publishing)
 	queueWorker := worker.NewQueueReleaseWorker(workerCfg, queueRepo, redis, appLog)
@@ -9,10 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 )
@@ -82,6 +85,31 @@ func main() {
 	store := pkgsaga.NewPostgresStore(db.Pool())
 	appLog.Info("Saga store initialized (PostgreSQL)")
 
+	// Initialize Redis connection for seat reservations
+	redis, err := pkgredis.NewClient(ctx, &pkgredis.Config{
+		Host:          cfg.Redis.Host,
+		Port:          cfg.Redis.Port,
+		Password:      cfg.Redis.Password,
+		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
+		PoolSize:      50,
+		MinIdleConns:  10,
+		MaxRetries:    3,
+		RetryInterval: 2 * time.Second,
+	})
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to connect to Redis: %v", err))
+	}
+	defer redis.Close()
+	appLog.Info("Redis connected")
+
+	reservationRepo := repository.NewRedisReservationRepository(redis)
+	if err := reservationRepo.LoadScripts(ctx); err != nil {
+		appLog.Warn(fmt.Sprintf("Failed to pre-load Lua scripts: %v", err))
+	}
+
 	// Initialize Kafka producer
 	producer, err := saga.NewKafkaSagaProducer(ctx, &saga.KafkaSagaProducerConfig{
 		Brokers:       cfg.Kafka.Brokers,
@@ -100,12 +128,30 @@ func main() {
 	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{
 		Store:  store,
 		Logger: &saga.ZapLogger{},
+		OnCompensationFailed: func(instance *pkgsaga.Instance, step *pkgsaga.Step, err error) {
+			appLog.Error(fmt.Sprintf("[ALERT] Compensation failed for saga %s step %s: %v", instance.ID, step.Name, err))
+		},
+		EventHandler: saga.NewLifecycleEventHandler(&saga.ZapLogger{}),
 	})
 
+	// Payments go through the payment service; without its URL the payment step fails
+	var paymentService saga.PaymentService
+	if cfg.Services.PaymentServiceURL != "" {
+		paymentService = saga.NewProviderPaymentService(saga.NewHTTPPaymentProvider(cfg.Services.PaymentServiceURL))
+	} else {
+		appLog.Warn("Payment service URL not configured; booking saga payments will fail")
+	}
+
 	// Register booking saga definition (legacy - for backward compatibility)
 	sagaBuilder := saga.NewBookingSagaBuilder(&saga.BookingSagaConfig{
-		StepTimeout: 30 * time.Second,
-		MaxRetries:  2,
+		ReservationService: service.NewRedisSeatReservationService(
+			reservationRepo,
+			cfg.Booking.MaxTicketsPerUser,
+			time.Duration(cfg.Booking.ReservationTTLMinutes)*time.Minute,
+		),
+		PaymentService: paymentService,
+		StepTimeout:    30 * time.Second,
+		MaxRetries:     2,
 	})
 	if err := orchestrator.RegisterDefinition(sagaBuilder.Build()); err != nil {
 		appLog.Fatal(fmt.Sprintf("Failed to register booking saga definition: %v", err))
@@ -70,6 +70,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      100,
 		MinIdleConns:  20,
 		MaxRetries:    3,
@@ -148,6 +151,8 @@ func main() {
 			WorkerCount:   5,
 			RetryAttempts: 3,
 			RetryDelay:    time.Second,
+			// Codes are checked against the ones already issued in PostgreSQL
+			ConfirmationCodes: saga.NewConfirmationCodeGenerator(bookingRepo),
 		},
 	)
 
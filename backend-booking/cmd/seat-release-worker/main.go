@@ -16,6 +16,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
 func main() {
@@ -68,6 +69,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      100,
 		MinIdleConns:  20,
 		MaxRetries:    3,
@@ -127,6 +131,18 @@ func main() {
 		}
 	}()
 
+	// Restore holds whose reservation key expires in Redis
+	holdExpiryListener := worker.NewHoldExpiryListener(redis, reservationRepo, appLog)
+	go holdExpiryListener.Start(ctx)
+
+	// Restore any expired hold the listener missed from the expiry index
+	holdExpirySweeper := worker.NewHoldExpirySweeper(reservationRepo, nil, appLog)
+	go holdExpirySweeper.Start(ctx)
+
+	// Release holds whose booking saga has already failed or been compensated
+	reservationReconciler := worker.NewReservationReconciler(reservationRepo, pkgsaga.NewPostgresStore(db.Pool()), nil, appLog)
+	go reservationReconciler.Start(ctx)
+
 	appLog.Info("Seat Release Worker started successfully")
 
 	// Wait for interrupt signal for graceful shutdown
@@ -52,6 +52,7 @@ type ContainerConfig struct {
 	SagaStore            pkgsaga.Store
 	SagaServiceConfig    *service.SagaServiceConfig
 	BookingHandlerConfig *handler.BookingHandlerConfig
+	QueueHandlerConfig   *handler.QueueHandlerConfig
 	// Note: Saga is now triggered asynchronously after payment success via webhook
 	// Booking handler always uses fast path (Redis Lua + PostgreSQL)
 }
@@ -102,8 +103,14 @@ func NewContainer(cfg *ContainerConfig) *Container {
 	// Saga is triggered asynchronously after payment success via webhook
 	c.BookingHandler = handler.NewBookingHandler(c.BookingService, c.QueueService, cfg.BookingHandlerConfig)
 
-	c.QueueHandler = handler.NewQueueHandler(c.QueueService, c.Redis)
-	c.AdminHandler = handler.NewAdminHandler(c.Redis)
+	c.QueueHandler = handler.NewQueueHandler(c.QueueService, c.Redis, cfg.QueueHandlerConfig)
+
+	// Event stats need the Redis reservation store's zone set and hold index
+	var statsService service.EventStatsService
+	if statsRepo, ok := cfg.ReservationRepo.(repository.EventStatsRepository); ok {
+		statsService = service.NewEventStatsService(statsRepo, c.QueueRepo)
+	}
+	c.AdminHandler = handler.NewAdminHandler(c.Redis, statsService)
 	c.SagaHandler = handler.NewSagaHandler(c.SagaService)
 
 	return c
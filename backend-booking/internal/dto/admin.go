@@ -0,0 +1,17 @@
+package dto
+
+// ZoneStatsResponse represents a zone's remaining seats in EventStatsResponse
+type ZoneStatsResponse struct {
+	ZoneID         string `json:"zone_id"`
+	SeatsRemaining int64  `json:"seats_remaining"`
+}
+
+// EventStatsResponse represents a live reservation snapshot for an event
+type EventStatsResponse struct {
+	EventID        string              `json:"event_id"`
+	SeatsRemaining int64               `json:"seats_remaining"`
+	TotalReserved  int64               `json:"total_reserved"`
+	ActiveHolds    int64               `json:"active_holds"`
+	QueueLength    int64               `json:"queue_length"`
+	Zones          []ZoneStatsResponse `json:"zones"`
+}
@@ -16,6 +16,12 @@ type ReserveSeatsRequest struct {
 	UnitPrice      float64 `json:"unit_price,omitempty"`
 	IdempotencyKey string  `json:"idempotency_key,omitempty"`
 	QueuePass      string  `json:"queue_pass,omitempty"` // JWT token from virtual queue
+	// SingleUsePass is set by the handler when the event's queue passes are
+	// single-use, so the reservation consumes QueuePass atomically
+	SingleUsePass bool `json:"-"`
+	// UserRole is set by the handler from the caller's identity and selects
+	// role-specific per-user limits
+	UserRole string `json:"-"`
 }
 
 // ReserveSeatsResponse represents response after reserving seats
@@ -61,6 +67,12 @@ type BookingResponse struct {
 	ExpiresAt   time.Time  `json:"expires_at"`
 }
 
+// ZoneAvailabilityResponse represents the seats still available in a zone
+type ZoneAvailabilityResponse struct {
+	ZoneID         string `json:"zone_id"`
+	AvailableSeats int64  `json:"available_seats"`
+}
+
 // UserBookingSummaryResponse represents user's booking summary for an event
 type UserBookingSummaryResponse struct {
 	UserID       string `json:"user_id"`
@@ -5,6 +5,9 @@ import "time"
 // JoinQueueRequest represents request to join the queue
 type JoinQueueRequest struct {
 	EventID string `json:"event_id" binding:"required"`
+	// Idempotent makes a repeated join return the existing entry with 200
+	// instead of failing with ALREADY_IN_QUEUE, so clients can safely retry
+	Idempotent bool `json:"idempotent,omitempty"`
 }
 
 // JoinQueueResponse represents response after joining the queue
@@ -15,6 +18,11 @@ type JoinQueueResponse struct {
 	JoinedAt      time.Time `json:"joined_at"`
 	ExpiresAt     time.Time `json:"expires_at"`
 	Message       string    `json:"message,omitempty"`
+	// QueueSizeAfter is the number of users waiting once the join completed,
+	// read atomically with Position
+	QueueSizeAfter int64 `json:"queue_size_after"`
+	// AlreadyInQueue is set when an idempotent join found an existing entry
+	AlreadyInQueue bool `json:"already_in_queue,omitempty"`
 }
 
 // QueuePositionResponse represents current queue position
@@ -26,7 +34,7 @@ type QueuePositionResponse struct {
 	ExpiresAt     time.Time `json:"expires_at,omitempty"`
 	// QueuePass is a JWT token generated when user is ready (position = 1)
 	// This token is required to proceed with booking
-	QueuePass     string    `json:"queue_pass,omitempty"`
+	QueuePass string `json:"queue_pass,omitempty"`
 	// QueuePassExpiresAt indicates when the queue pass expires (5 minutes validity)
 	QueuePassExpiresAt time.Time `json:"queue_pass_expires_at,omitempty"`
 }
@@ -48,4 +56,10 @@ type LeaveQueueRequest struct {
 type LeaveQueueResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	// WasInQueue reports that the user was still waiting and has been removed
+	WasInQueue bool `json:"was_in_queue"`
+	// PreviousPosition is the 1-indexed position the user held when leaving
+	PreviousPosition int64 `json:"previous_position"`
+	// QueueSizeAfter is the number of users still waiting after the user left
+	QueueSizeAfter int64 `json:"queue_size_after"`
 }
@@ -3,28 +3,37 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// syncInventoryLockKey guards SyncInventory so two admin nodes never rewrite zone availability at once
+const syncInventoryLockKey = "lock:admin:sync-inventory"
+
 // AdminHandler handles admin HTTP requests
 type AdminHandler struct {
 	redis            *pkgredis.Client
+	statsService     service.EventStatsService
 	ticketServiceURL string
 	httpClient       *http.Client
 }
 
-// NewAdminHandler creates a new admin handler
-func NewAdminHandler(redis *pkgredis.Client) *AdminHandler {
+// NewAdminHandler creates a new admin handler. statsService may be nil, in
+// which case the event stats endpoint reports it is unavailable.
+func NewAdminHandler(redis *pkgredis.Client, statsService service.EventStatsService) *AdminHandler {
 	ticketURL := os.Getenv("TICKET_SERVICE_URL")
 	if ticketURL == "" {
 		ticketURL = "http://localhost:8082"
@@ -32,6 +41,7 @@ func NewAdminHandler(redis *pkgredis.Client) *AdminHandler {
 
 	return &AdminHandler{
 		redis:            redis,
+		statsService:     statsService,
 		ticketServiceURL: ticketURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
@@ -69,6 +79,30 @@ func (h *AdminHandler) SyncInventory(c *gin.Context) {
 	defer span.End()
 	c.Request = c.Request.WithContext(ctx)
 
+	lock, err := h.redis.AcquireLock(ctx, syncInventoryLockKey, time.Minute)
+	if err != nil {
+		if errors.Is(err, pkgredis.ErrLockNotAcquired) {
+			span.SetStatus(codes.Error, "sync already in progress")
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "inventory sync already in progress",
+				Code:    "SYNC_IN_PROGRESS",
+				Message: "Another admin node is syncing inventory. Please retry shortly.",
+			})
+			return
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to sync inventory",
+			Code:    "SYNC_FAILED",
+			Message: err.Error(),
+		})
+		return
+	}
+	// Keep the lock for syncs that outlive its TTL
+	lock.AutoRenew(ctx)
+	defer lock.Release(context.WithoutCancel(ctx))
+
 	count, err := h.syncZoneAvailability(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -119,15 +153,25 @@ func (h *AdminHandler) syncZoneAvailability(ctx context.Context) (int, error) {
 	}
 
 	count := 0
+	showSeats := make(map[string]int)
 	for _, zone := range ticketResp.Data {
 		// Set zone availability in Redis
 		key := fmt.Sprintf("zone:availability:%s", zone.ID)
 		if err := h.redis.Set(ctx, key, zone.AvailableSeats, 0).Err(); err != nil {
 			continue
 		}
+		if zone.ShowID != "" {
+			h.redis.Set(ctx, repository.ZoneShowKey(zone.ID), zone.ShowID, 0)
+			showSeats[zone.ShowID] += zone.AvailableSeats
+		}
 		count++
 	}
 
+	// Seed the show-wide counters the reserve/release scripts keep in step
+	for showID, seats := range showSeats {
+		h.redis.Set(ctx, fmt.Sprintf("show:availability:%s", showID), seats, 0)
+	}
+
 	return count, nil
 }
 
@@ -227,3 +271,54 @@ func (h *AdminHandler) GetInventoryStatus(c *gin.Context) {
 		"count":   len(zones),
 	})
 }
+
+// GetEventStats handles GET /admin/events/:event_id/stats
+// Returns a live reservation snapshot for an event from Redis
+func (h *AdminHandler) GetEventStats(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.event_stats")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if h.statsService == nil {
+		span.SetStatus(codes.Error, "event stats unavailable")
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+			Error:   "event stats unavailable",
+			Code:    "STATS_UNAVAILABLE",
+			Message: "The reservation store does not support event stats",
+		})
+		return
+	}
+
+	stats, err := h.statsService.GetEventStats(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, domain.ErrInvalidEventID) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid event ID",
+				Code:    "INVALID_EVENT_ID",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to get event stats",
+			Code:    "STATS_FAILED",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("seats_remaining", stats.SeatsRemaining),
+		attribute.Int64("queue_length", stats.QueueLength),
+	)
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
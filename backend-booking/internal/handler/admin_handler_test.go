@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEventStatsService is a mock implementation of EventStatsService
+type MockEventStatsService struct {
+	mock.Mock
+}
+
+func (m *MockEventStatsService) GetEventStats(ctx context.Context, eventID string) (*dto.EventStatsResponse, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.EventStatsResponse), args.Error(1)
+}
+
+func setupAdminTestRouter(handler *AdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/events/:event_id/stats", handler.GetEventStats)
+	return router
+}
+
+func TestAdminHandler_GetEventStats_Success(t *testing.T) {
+	statsService := new(MockEventStatsService)
+	router := setupAdminTestRouter(NewAdminHandler(nil, statsService))
+
+	statsService.On("GetEventStats", mock.Anything, "event-123").Return(&dto.EventStatsResponse{
+		EventID:        "event-123",
+		SeatsRemaining: 64,
+		TotalReserved:  5,
+		ActiveHolds:    2,
+		QueueLength:    1200,
+		Zones: []dto.ZoneStatsResponse{
+			{ZoneID: "zone-a", SeatsRemaining: 45},
+			{ZoneID: "zone-b", SeatsRemaining: 19},
+		},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/events/event-123/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    dto.EventStatsResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, int64(64), response.Data.SeatsRemaining)
+	assert.Equal(t, int64(5), response.Data.TotalReserved)
+	assert.Equal(t, int64(2), response.Data.ActiveHolds)
+	assert.Equal(t, int64(1200), response.Data.QueueLength)
+	assert.Len(t, response.Data.Zones, 2)
+
+	statsService.AssertExpectations(t)
+}
+
+func TestAdminHandler_GetEventStats_Error(t *testing.T) {
+	statsService := new(MockEventStatsService)
+	router := setupAdminTestRouter(NewAdminHandler(nil, statsService))
+
+	statsService.On("GetEventStats", mock.Anything, "event-123").Return(nil, errors.New("redis unavailable"))
+
+	req, _ := http.NewRequest("GET", "/admin/events/event-123/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "STATS_FAILED", response.Code)
+}
+
+func TestAdminHandler_GetEventStats_Unavailable(t *testing.T) {
+	router := setupAdminTestRouter(NewAdminHandler(nil, nil))
+
+	req, _ := http.NewRequest("GET", "/admin/events/event-123/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -76,6 +78,7 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 	if req.TenantID == "" {
 		req.TenantID = c.GetString("tenant_id")
 	}
+	req.UserRole = c.GetString("role")
 
 	span.SetAttributes(
 		attribute.String("user_id", userID),
@@ -86,15 +89,31 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 		attribute.Bool("require_queue_pass", h.requireQueuePass),
 	)
 
-	// Validate queue pass if required
+	// Validate queue pass if required, unless RequireQueuePass already checked the
+	// X-Queue-Pass header against the event being reserved
 	if h.requireQueuePass {
-		if err := h.queueService.ValidateQueuePass(ctx, userID, req.EventID, req.QueuePass); err != nil {
+		if req.QueuePass == "" {
+			req.QueuePass = c.GetHeader(middleware.QueuePassHeader)
+		}
+		if !middleware.QueuePassValidFor(c, req.EventID) {
+			if err := h.queueService.ValidateQueuePass(ctx, userID, req.EventID, req.QueuePass); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				h.handleError(c, err)
+				return
+			}
+		}
+		span.SetAttributes(attribute.Bool("queue_pass_valid", true))
+
+		singleUse, err := h.queueService.IsSingleUsePass(ctx, req.EventID)
+		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			h.handleError(c, err)
 			return
 		}
-		span.SetAttributes(attribute.Bool("queue_pass_valid", true))
+		req.SingleUsePass = singleUse
+		span.SetAttributes(attribute.Bool("single_use_pass", singleUse))
 	}
 
 	// Fast path: Redis Lua (atomic) + PostgreSQL
@@ -106,8 +125,9 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 		return
 	}
 
-	// Delete queue pass after successful reservation (one-time use)
-	if h.requireQueuePass && h.queueService != nil {
+	// Delete queue pass after successful reservation (one-time use). A
+	// single-use pass was already consumed atomically by the reservation.
+	if h.requireQueuePass && h.queueService != nil && !req.SingleUsePass {
 		// Run in background - don't block the response
 		go func() {
 			_ = h.queueService.DeleteQueuePass(ctx, userID, req.EventID)
@@ -115,6 +135,7 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 	}
 
 	span.SetAttributes(attribute.String("booking_id", result.BookingID))
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
 	c.JSON(http.StatusCreated, result)
 }
@@ -166,6 +187,7 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 		return
 	}
 
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
 	c.JSON(http.StatusOK, result)
 }
@@ -209,6 +231,7 @@ func (h *BookingHandler) ReleaseBooking(c *gin.Context) {
 		return
 	}
 
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
 	c.JSON(http.StatusOK, result)
 }
@@ -252,6 +275,52 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 		return
 	}
 
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelReservation handles DELETE /bookings/:id/reservation
+// Releases the caller's unpaid hold back to inventory
+func (h *BookingHandler) CancelReservation(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.booking.cancel_reservation")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		span.SetStatus(codes.Error, "unauthorized")
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error: "unauthorized",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	bookingID := c.Param("id")
+	if bookingID == "" {
+		span.SetStatus(codes.Error, "booking id required")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "booking id required",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.String("user_id", userID),
+	)
+
+	result, err := h.bookingService.CancelReservation(ctx, bookingID, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.handleError(c, err)
+		return
+	}
+
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
 	c.JSON(http.StatusOK, result)
 }
@@ -391,6 +460,27 @@ func (h *BookingHandler) GetUserBookingSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// GetZoneAvailability handles GET /bookings/availability/:zone_id
+func (h *BookingHandler) GetZoneAvailability(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.booking.zone_availability")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	zoneID := c.Param("zone_id")
+	span.SetAttributes(attribute.String("zone_id", zoneID))
+
+	result, err := h.bookingService.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.handleError(c, err)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, result)
+}
+
 // GetPendingBookings handles GET /bookings/pending
 func (h *BookingHandler) GetPendingBookings(c *gin.Context) {
 	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.booking.pending")
@@ -424,84 +514,99 @@ func (h *BookingHandler) GetPendingBookings(c *gin.Context) {
 
 // handleError converts domain errors to HTTP responses
 func (h *BookingHandler) handleError(c *gin.Context, err error) {
+	var reserveErr *pkgredis.ReserveError
 	switch {
 	case errors.Is(err, domain.ErrBookingNotFound),
 		errors.Is(err, domain.ErrReservationNotFound):
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+		respondError(c, http.StatusNotFound, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "NOT_FOUND",
 		})
 	case errors.Is(err, domain.ErrZoneNotFound):
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+		respondError(c, http.StatusNotFound, dto.ErrorResponse{
 			Error:   err.Error(),
 			Code:    "ZONE_NOT_FOUND",
 			Message: "Zone inventory not synced to Redis. Please sync inventory first.",
 		})
 	case errors.Is(err, domain.ErrInvalidUserID):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		respondError(c, http.StatusForbidden, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "FORBIDDEN",
 		})
 	case errors.Is(err, domain.ErrInvalidShowID):
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		respondError(c, http.StatusBadRequest, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "INVALID_SHOW_ID",
 		})
 	case errors.Is(err, domain.ErrInsufficientSeats):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
+		respondError(c, http.StatusConflict, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "INSUFFICIENT_SEATS",
 		})
 	case errors.Is(err, domain.ErrMaxTicketsExceeded):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
+		respondError(c, http.StatusConflict, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "MAX_TICKETS_EXCEEDED",
 		})
 	case errors.Is(err, domain.ErrAlreadyConfirmed):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
+		respondError(c, http.StatusConflict, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "ALREADY_CONFIRMED",
 		})
 	case errors.Is(err, domain.ErrAlreadyReleased):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
+		respondError(c, http.StatusConflict, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "ALREADY_RELEASED",
 		})
 	case errors.Is(err, domain.ErrBookingExpired),
 		errors.Is(err, domain.ErrReservationExpired):
-		c.JSON(http.StatusGone, dto.ErrorResponse{
+		respondError(c, http.StatusGone, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "EXPIRED",
 		})
 	// Queue pass errors
 	case errors.Is(err, domain.ErrQueuePassRequired):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		respondError(c, http.StatusForbidden, dto.ErrorResponse{
 			Error:   err.Error(),
 			Code:    "QUEUE_PASS_REQUIRED",
 			Message: "Please join the queue and wait for your turn to book",
 		})
 	case errors.Is(err, domain.ErrInvalidQueuePass):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		respondError(c, http.StatusForbidden, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "INVALID_QUEUE_PASS",
 		})
 	case errors.Is(err, domain.ErrQueuePassExpired):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		respondError(c, http.StatusForbidden, dto.ErrorResponse{
 			Error:   err.Error(),
 			Code:    "QUEUE_PASS_EXPIRED",
 			Message: "Your queue pass has expired. Please rejoin the queue.",
 		})
 	case errors.Is(err, domain.ErrQueuePassUserMismatch),
 		errors.Is(err, domain.ErrQueuePassEventMismatch):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		respondError(c, http.StatusForbidden, dto.ErrorResponse{
 			Error: err.Error(),
 			Code:  "QUEUE_PASS_MISMATCH",
 		})
+	// Redis script failures without a dedicated domain error
+	case errors.As(err, &reserveErr):
+		respondError(c, reserveErr.HTTPStatus(), dto.ErrorResponse{
+			Error:   reserveErr.Code,
+			Code:    reserveErr.Code,
+			Message: reserveErr.Message,
+		})
 	default:
 		_ = c.Error(err) // Log the error with gin
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		respondError(c, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "internal server error",
 			Code:  "INTERNAL_ERROR",
 		})
 	}
 }
+
+// respondError writes an error response and tags the request span with its
+// code, so rejections can be told apart from successes in traces
+func respondError(c *gin.Context, status int, resp dto.ErrorResponse) {
+	telemetry.SetBusinessOutcome(telemetry.SpanFromContext(c.Request.Context()), resp.Code)
+	c.JSON(status, resp)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +13,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/stretchr/testify/mock"
 )
 
 // MockBookingService is a mock implementation of BookingService for testing
@@ -20,11 +24,13 @@ type MockBookingService struct {
 	ConfirmBookingFunc         func(ctx context.Context, bookingID, userID string, req *dto.ConfirmBookingRequest) (*dto.ConfirmBookingResponse, error)
 	CancelBookingFunc          func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
 	ReleaseBookingFunc         func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
+	CancelReservationFunc      func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
 	GetBookingFunc             func(ctx context.Context, bookingID, userID string) (*dto.BookingResponse, error)
 	GetUserBookingsFunc        func(ctx context.Context, userID string, page, pageSize int) (*dto.PaginatedResponse, error)
 	GetUserBookingSummaryFunc  func(ctx context.Context, userID, eventID string) (*dto.UserBookingSummaryResponse, error)
 	GetPendingBookingsFunc     func(ctx context.Context, limit int) ([]*dto.BookingResponse, error)
 	ExpireReservationsFunc     func(ctx context.Context, limit int) (int, error)
+	GetZoneAvailabilityFunc    func(ctx context.Context, zoneID string) (*dto.ZoneAvailabilityResponse, error)
 }
 
 func (m *MockBookingService) ReserveSeats(ctx context.Context, userID string, req *dto.ReserveSeatsRequest) (*dto.ReserveSeatsResponse, error) {
@@ -55,6 +61,13 @@ func (m *MockBookingService) ReleaseBooking(ctx context.Context, bookingID, user
 	return nil, nil
 }
 
+func (m *MockBookingService) CancelReservation(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+	if m.CancelReservationFunc != nil {
+		return m.CancelReservationFunc(ctx, bookingID, userID)
+	}
+	return nil, nil
+}
+
 func (m *MockBookingService) GetBooking(ctx context.Context, bookingID, userID string) (*dto.BookingResponse, error) {
 	if m.GetBookingFunc != nil {
 		return m.GetBookingFunc(ctx, bookingID, userID)
@@ -90,6 +103,13 @@ func (m *MockBookingService) ExpireReservations(ctx context.Context, limit int)
 	return 0, nil
 }
 
+func (m *MockBookingService) GetZoneAvailability(ctx context.Context, zoneID string) (*dto.ZoneAvailabilityResponse, error) {
+	if m.GetZoneAvailabilityFunc != nil {
+		return m.GetZoneAvailabilityFunc(ctx, zoneID)
+	}
+	return nil, nil
+}
+
 // newTestBookingHandler creates a BookingHandler for testing with mock services
 func newTestBookingHandler(bookingService *MockBookingService) *BookingHandler {
 	return &BookingHandler{
@@ -112,6 +132,7 @@ func setupTestRouter(handler *BookingHandler) *gin.Engine {
 		bookings.POST("/:id/confirm", handler.ConfirmBooking)
 		bookings.POST("/:id/cancel", handler.CancelBooking)
 		bookings.DELETE("/:id", handler.ReleaseBooking)
+		bookings.DELETE("/:id/reservation", handler.CancelReservation)
 	}
 
 	return router
@@ -136,6 +157,7 @@ func setupTestRouterWithAuth(handler *BookingHandler, userID string) *gin.Engine
 		bookings.POST("/:id/confirm", handler.ConfirmBooking)
 		bookings.POST("/:id/cancel", handler.CancelBooking)
 		bookings.DELETE("/:id", handler.ReleaseBooking)
+		bookings.DELETE("/:id/reservation", handler.CancelReservation)
 	}
 
 	return router
@@ -489,6 +511,105 @@ func TestBookingHandler_ReleaseBooking(t *testing.T) {
 	}
 }
 
+func TestBookingHandler_CancelReservation(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		bookingID      string
+		mockFunc       func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:      "successful cancellation",
+			userID:    "user-123",
+			bookingID: "booking-123",
+			mockFunc: func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+				if userID != "user-123" {
+					t.Errorf("expected user-123, got %s", userID)
+				}
+				return &dto.ReleaseBookingResponse{
+					BookingID: bookingID,
+					Status:    "cancelled",
+					Message:   "Reservation cancelled successfully",
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unauthorized - no user_id",
+			userID:         "",
+			bookingID:      "booking-123",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:      "reservation owned by another user",
+			userID:    "user-456",
+			bookingID: "booking-123",
+			mockFunc: func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+				return nil, domain.ErrInvalidUserID
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "FORBIDDEN",
+		},
+		{
+			name:      "reservation not found",
+			userID:    "user-123",
+			bookingID: "non-existent",
+			mockFunc: func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+				return nil, domain.ErrReservationNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NOT_FOUND",
+		},
+		{
+			name:      "already released",
+			userID:    "user-123",
+			bookingID: "booking-123",
+			mockFunc: func(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+				return nil, domain.ErrAlreadyReleased
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "ALREADY_RELEASED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBookingService{
+				CancelReservationFunc: tt.mockFunc,
+			}
+			handler := newTestBookingHandler(mockService)
+
+			var router *gin.Engine
+			if tt.userID != "" {
+				router = setupTestRouterWithAuth(handler, tt.userID)
+			} else {
+				router = setupTestRouter(handler)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/bookings/"+tt.bookingID+"/reservation", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedCode != "" {
+				var response dto.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err == nil {
+					if response.Code != tt.expectedCode {
+						t.Errorf("expected code %s, got %s", tt.expectedCode, response.Code)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestBookingHandler_GetBooking(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -778,6 +899,12 @@ func TestBookingHandler_HandleError(t *testing.T) {
 			expectedStatus: http.StatusGone,
 			expectedCode:   "EXPIRED",
 		},
+		{
+			name:           "script result without domain error",
+			err:            fmt.Errorf("%w: %w", domain.ErrInvalidBookingStatus, &pkgredis.ReserveError{Code: pkgredis.CodeInvalidBookingID, Message: "Booking ID does not match"}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   pkgredis.CodeInvalidBookingID,
+		},
 	}
 
 	for _, tt := range tests {
@@ -810,6 +937,47 @@ func TestBookingHandler_HandleError(t *testing.T) {
 	}
 }
 
+func TestBookingHandler_ReserveSeats_QueuePassForAnotherEvent(t *testing.T) {
+	queueService := new(MockQueueService)
+	queueService.On("ValidateQueuePass", mock.Anything, "user-123", "event-B", "pass-A").
+		Return(domain.ErrQueuePassEventMismatch)
+
+	mockService := &MockBookingService{
+		ReserveSeatsFunc: func(ctx context.Context, userID string, req *dto.ReserveSeatsRequest) (*dto.ReserveSeatsResponse, error) {
+			t.Fatal("expected no reservation without a pass for the requested event")
+			return nil, nil
+		},
+	}
+	handler := &BookingHandler{
+		bookingService:   mockService,
+		queueService:     queueService,
+		requireQueuePass: true,
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// RequireQueuePass accepted a pass for event-A, named in the query string
+	router.POST("/bookings/reserve", func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Set(middleware.ContextKeyQueuePassValid, true)
+		c.Set(middleware.ContextKeyQueuePassEventID, "event-A")
+		c.Next()
+	}, handler.ReserveSeats)
+
+	body, _ := json.Marshal(&dto.ReserveSeatsRequest{EventID: "event-B", ZoneID: "zone-123", Quantity: 2})
+	req := httptest.NewRequest(http.MethodPost, "/bookings/reserve?event_id=event-A", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.QueuePassHeader, "pass-A")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+	}
+	queueService.AssertExpectations(t)
+}
+
 func TestBookingHandler_InvalidRequestBody(t *testing.T) {
 	mockService := &MockBookingService{}
 	handler := newTestBookingHandler(mockService)
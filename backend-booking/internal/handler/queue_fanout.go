@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Queue pass subscription modes for SSE streams
+const (
+	// SubscriptionPerUser opens one Redis subscription per SSE stream on the
+	// user's own channel: precise delivery, one Redis connection per client
+	SubscriptionPerUser = "per_user"
+	// SubscriptionShared opens one pattern subscription per event and fans
+	// messages out in-process, so thousands of streams share one connection
+	SubscriptionShared = "shared"
+)
+
+// passSubscription delivers queue pass notifications to a single SSE stream
+type passSubscription struct {
+	messages <-chan *goredis.Message
+	// reconnected is signalled when the Redis subscription was re-established;
+	// a pass published while it was down was lost, so the stream should re-check
+	reconnected <-chan struct{}
+	close       func()
+}
+
+// patternSubscription is the subset of *redis.Subscription used by the fan-out
+type patternSubscription interface {
+	Messages() <-chan *goredis.Message
+	Reconnected() <-chan struct{}
+	Close() error
+}
+
+// subscribeFunc opens a pattern subscription
+type subscribeFunc func(ctx context.Context, pattern string) patternSubscription
+
+// passFanout shares one Redis pattern subscription per event across all SSE
+// streams waiting on that event, routing each message to the streams of the
+// user whose channel it was published on
+type passFanout struct {
+	subscribe subscribeFunc
+
+	mu     sync.Mutex
+	events map[string]*eventFanout
+}
+
+// eventFanout is the shared subscription for a single event
+type eventFanout struct {
+	sub   patternSubscription
+	users map[string]map[*fanoutStream]struct{} // a user may have several tabs open
+}
+
+// fanoutStream is the delivery side of one stream's passSubscription
+type fanoutStream struct {
+	messages    chan *goredis.Message
+	reconnected chan struct{}
+}
+
+// newPassFanout creates a fan-out that opens subscriptions with subscribe
+func newPassFanout(subscribe subscribeFunc) *passFanout {
+	return &passFanout{
+		subscribe: subscribe,
+		events:    make(map[string]*eventFanout),
+	}
+}
+
+// redisSubscribeFunc adapts a Redis client's resilient PSubscribe to subscribeFunc
+func redisSubscribeFunc(client *redis.Client) subscribeFunc {
+	return func(ctx context.Context, pattern string) patternSubscription {
+		return client.ResilientPSubscribe(ctx, pattern)
+	}
+}
+
+// add registers a stream for userID on eventID. The event's subscription is
+// opened by the first stream and closed when the last one leaves.
+func (f *passFanout) add(eventID, userID string) *passSubscription {
+	stream := &fanoutStream{
+		messages:    make(chan *goredis.Message, 1),
+		reconnected: make(chan struct{}, 1),
+	}
+
+	f.mu.Lock()
+	ev, ok := f.events[eventID]
+	if !ok {
+		// The subscription outlives any single request, so it isn't tied to one's context
+		sub := f.subscribe(context.Background(), worker.QueuePassChannelPattern(eventID))
+		ev = &eventFanout{sub: sub, users: make(map[string]map[*fanoutStream]struct{})}
+		f.events[eventID] = ev
+		go f.dispatch(eventID, ev)
+	}
+	if ev.users[userID] == nil {
+		ev.users[userID] = make(map[*fanoutStream]struct{})
+	}
+	ev.users[userID][stream] = struct{}{}
+	f.mu.Unlock()
+
+	var once sync.Once
+	return &passSubscription{
+		messages:    stream.messages,
+		reconnected: stream.reconnected,
+		close: func() {
+			once.Do(func() { f.remove(eventID, userID, stream) })
+		},
+	}
+}
+
+// remove unregisters a stream, closing the event subscription if it was the last one
+func (f *passFanout) remove(eventID, userID string, stream *fanoutStream) {
+	f.mu.Lock()
+	ev, ok := f.events[eventID]
+	if !ok {
+		f.mu.Unlock()
+		return
+	}
+	delete(ev.users[userID], stream)
+	if len(ev.users[userID]) == 0 {
+		delete(ev.users, userID)
+	}
+	last := len(ev.users) == 0
+	if last {
+		delete(f.events, eventID)
+	}
+	f.mu.Unlock()
+
+	// Closing waits for the connection to be released, so it must not stall
+	// other streams registering or leaving
+	if last {
+		ev.sub.Close()
+	}
+}
+
+// dispatch routes messages from an event subscription to the matching user's
+// streams and tells every stream on the event when the subscription reconnects.
+// It returns once the subscription is closed.
+func (f *passFanout) dispatch(eventID string, ev *eventFanout) {
+	prefix := worker.QueuePassChannelKey(eventID, "")
+	msgs := ev.sub.Messages()
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			userID := strings.TrimPrefix(msg.Channel, prefix)
+
+			f.mu.Lock()
+			for stream := range ev.users[userID] {
+				// A stream handles a single pass notification; never block the shared reader
+				select {
+				case stream.messages <- msg:
+				default:
+				}
+			}
+			f.mu.Unlock()
+
+		case <-ev.sub.Reconnected():
+			f.mu.Lock()
+			for _, streams := range ev.users {
+				for stream := range streams {
+					select {
+					case stream.reconnected <- struct{}{}:
+					default:
+					}
+				}
+			}
+			f.mu.Unlock()
+		}
+	}
+}
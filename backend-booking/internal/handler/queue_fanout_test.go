@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePatternSub is an in-memory patternSubscription
+type fakePatternSub struct {
+	messages    chan *goredis.Message
+	reconnected chan struct{}
+	onClose     func()
+}
+
+func (s *fakePatternSub) Messages() <-chan *goredis.Message { return s.messages }
+func (s *fakePatternSub) Reconnected() <-chan struct{}      { return s.reconnected }
+
+func (s *fakePatternSub) Close() error {
+	s.onClose()
+	close(s.messages)
+	return nil
+}
+
+// fakePatternSubscriber hands out in-memory pattern subscriptions
+type fakePatternSubscriber struct {
+	mu     sync.Mutex
+	subs   map[string]*fakePatternSub
+	opened int
+	closed int
+}
+
+func newFakePatternSubscriber() *fakePatternSubscriber {
+	return &fakePatternSubscriber{subs: make(map[string]*fakePatternSub)}
+}
+
+func (f *fakePatternSubscriber) subscribe(ctx context.Context, pattern string) patternSubscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sub := &fakePatternSub{
+		messages:    make(chan *goredis.Message, 10),
+		reconnected: make(chan struct{}, 1),
+		onClose: func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.closed++
+		},
+	}
+	f.subs[pattern] = sub
+	f.opened++
+	return sub
+}
+
+// reconnect signals that the event's subscription was re-established after a Redis blip
+func (f *fakePatternSubscriber) reconnect(eventID string) {
+	f.mu.Lock()
+	sub := f.subs[worker.QueuePassChannelPattern(eventID)]
+	f.mu.Unlock()
+	sub.reconnected <- struct{}{}
+}
+
+// publish delivers a queue pass message for userID as if published by the release worker
+func (f *fakePatternSubscriber) publish(t *testing.T, eventID, userID string) {
+	t.Helper()
+
+	data, err := json.Marshal(worker.QueuePassReadyMessage{
+		UserID:    userID,
+		EventID:   eventID,
+		QueuePass: "pass-for-" + userID,
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	f.mu.Lock()
+	sub := f.subs[worker.QueuePassChannelPattern(eventID)]
+	f.mu.Unlock()
+	sub.messages <- &goredis.Message{
+		Channel: worker.QueuePassChannelKey(eventID, userID),
+		Pattern: worker.QueuePassChannelPattern(eventID),
+		Payload: string(data),
+	}
+}
+
+func TestPassFanout_RoutesToMatchingUser(t *testing.T) {
+	sub := newFakePatternSubscriber()
+	fanout := newPassFanout(sub.subscribe)
+
+	alice := fanout.add("event-123", "alice")
+	bob := fanout.add("event-123", "bob")
+	assert.Equal(t, 1, sub.opened, "streams on the same event should share one subscription")
+
+	sub.publish(t, "event-123", "alice")
+
+	select {
+	case msg := <-alice.messages:
+		assert.Equal(t, worker.QueuePassChannelKey("event-123", "alice"), msg.Channel)
+	case <-time.After(time.Second):
+		t.Fatal("alice did not receive her queue pass")
+	}
+	select {
+	case msg := <-bob.messages:
+		t.Fatalf("bob received a message meant for %s", msg.Channel)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	alice.close()
+	assert.Equal(t, 0, sub.closed)
+	bob.close()
+	assert.Equal(t, 1, sub.closed, "the subscription should close when the last stream leaves")
+}
+
+func TestPassFanout_ReconnectNotifiesAllStreams(t *testing.T) {
+	sub := newFakePatternSubscriber()
+	fanout := newPassFanout(sub.subscribe)
+
+	alice := fanout.add("event-123", "alice")
+	defer alice.close()
+	bob := fanout.add("event-123", "bob")
+	defer bob.close()
+
+	sub.reconnect("event-123")
+
+	for name, s := range map[string]*passSubscription{"alice": alice, "bob": bob} {
+		select {
+		case <-s.reconnected:
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not told about the reconnection", name)
+		}
+	}
+}
+
+func TestQueueHandler_StreamPosition_RechecksAfterReconnect(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService) // default 15s keepalive
+	sub := newFakePatternSubscriber()
+	handler.fanout = newPassFanout(sub.subscribe)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	// The pass was issued while Redis was down, so its notification was never delivered
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").
+		Return(&dto.QueuePositionResponse{Position: 1, TotalInQueue: 100}, nil).Once()
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").
+		Return(&dto.QueuePositionResponse{IsReady: true, QueuePass: "missed-pass"}, nil)
+
+	resp := openQueueStream(t, server, "user-123")
+	require.Eventually(t, func() bool {
+		handler.fanout.mu.Lock()
+		defer handler.fanout.mu.Unlock()
+		return handler.fanout.events["event-123"] != nil
+	}, time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+	sub.reconnect("event-123")
+
+	frames := readSSEFrames(resp.Body)
+	positions := positionEvents(t, frames)
+	require.Len(t, positions, 2)
+	assert.Equal(t, "missed-pass", positions[1].QueuePass)
+	assert.Less(t, time.Since(start), 5*time.Second, "the pass should arrive without waiting for a keepalive")
+}
+
+func TestQueueHandler_StreamPosition_SharedSubscription(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService)
+	sub := newFakePatternSubscriber()
+	handler.fanout = newPassFanout(sub.subscribe)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	mockService.On("GetPosition", mock.Anything, mock.Anything, "event-123").Return(waiting, nil)
+
+	alice := openQueueStream(t, server, "alice")
+	bob := openQueueStream(t, server, "bob")
+	require.Eventually(t, func() bool { return handler.ActiveStreams() == 2 }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		handler.fanout.mu.Lock()
+		defer handler.fanout.mu.Unlock()
+		ev := handler.fanout.events["event-123"]
+		return ev != nil && len(ev.users) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	sub.publish(t, "event-123", "alice")
+
+	// Alice's stream ends with her pass; Bob's keeps waiting
+	frames := readSSEFrames(alice.Body)
+	require.NotEmpty(t, frames)
+	var final dto.QueuePositionResponse
+	require.NoError(t, json.Unmarshal([]byte(frames[len(frames)-1].Data), &final))
+	assert.True(t, final.IsReady)
+	assert.Equal(t, "pass-for-alice", final.QueuePass)
+	assert.Equal(t, int64(1), handler.ActiveStreams())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, handler.Shutdown(ctx))
+	bobFrames := readSSEFrames(bob.Body)
+	for _, f := range bobFrames {
+		assert.NotContains(t, f.Data, "pass-for-alice")
+	}
+	assert.Equal(t, http.StatusOK, bob.StatusCode)
+}
@@ -7,13 +7,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/httperr"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
@@ -24,14 +28,80 @@ import (
 type QueueHandler struct {
 	queueService service.QueueService
 	redisClient  *redis.Client // For Pub/Sub subscription in SSE
+
+	// SSE stream lifecycle (see queue_stream.go)
+	streamsMu     sync.Mutex
+	streams       sync.WaitGroup
+	shuttingDown  bool
+	shutdown      chan struct{}
+	maxStreams    int64
+	activeStreams atomic.Int64
+
+	fanout *passFanout // Shared per-event subscriptions (nil in per-user mode)
+
+	keepaliveInterval time.Duration
+	maxWait           time.Duration
+}
+
+// QueueHandlerConfig contains configuration for queue handler
+type QueueHandlerConfig struct {
+	// MaxStreams caps concurrent SSE position streams, each of which holds a
+	// Redis Pub/Sub subscription (0 = unlimited)
+	MaxStreams int
+	// SubscriptionMode is SubscriptionPerUser (default) or SubscriptionShared
+	SubscriptionMode string
+	// KeepaliveInterval is how often an idle stream re-sends the position (default: 15s)
+	KeepaliveInterval time.Duration
+	// MaxWait is how long a stream waits for a queue pass before timing out;
+	// it should match the queue pass TTL (default: 5m)
+	MaxWait time.Duration
+}
+
+// Validate checks that the stream timings are consistent once defaults are applied
+func (c *QueueHandlerConfig) Validate() error {
+	keepalive, maxWait := c.streamTimings()
+	if keepalive <= 0 || maxWait <= 0 {
+		return fmt.Errorf("queue stream keepalive (%v) and max wait (%v) must be positive", keepalive, maxWait)
+	}
+	if keepalive >= maxWait {
+		return fmt.Errorf("queue stream keepalive (%v) must be shorter than max wait (%v)", keepalive, maxWait)
+	}
+	return nil
+}
+
+// streamTimings returns the keepalive interval and max wait with defaults applied
+func (c *QueueHandlerConfig) streamTimings() (keepalive, maxWait time.Duration) {
+	keepalive, maxWait = defaultStreamKeepalive, defaultStreamMaxWait
+	if c.KeepaliveInterval != 0 {
+		keepalive = c.KeepaliveInterval
+	}
+	if c.MaxWait != 0 {
+		maxWait = c.MaxWait
+	}
+	return keepalive, maxWait
 }
 
-// NewQueueHandler creates a new queue handler
-func NewQueueHandler(queueService service.QueueService, redisClient *redis.Client) *QueueHandler {
-	return &QueueHandler{
+// NewQueueHandler creates a new queue handler. Stream timings that fail
+// Validate are ignored in favour of the defaults.
+func NewQueueHandler(queueService service.QueueService, redisClient *redis.Client, cfg *QueueHandlerConfig) *QueueHandler {
+	h := &QueueHandler{
 		queueService: queueService,
 		redisClient:  redisClient,
+		shutdown:     make(chan struct{}),
+
+		keepaliveInterval: defaultStreamKeepalive,
+		maxWait:           defaultStreamMaxWait,
+	}
+	if cfg != nil {
+		h.maxStreams = int64(cfg.MaxStreams)
+		if cfg.Validate() == nil {
+			h.keepaliveInterval, h.maxWait = cfg.streamTimings()
+		}
+		if cfg.SubscriptionMode == SubscriptionShared && redisClient != nil {
+			h.fanout = newPassFanout(redisSubscribeFunc(redisClient))
+		}
 	}
+	return h
 }
 
 // JoinQueue handles POST /queue/join
@@ -43,10 +113,7 @@ func (h *QueueHandler) JoinQueue(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
 		span.SetStatus(codes.Error, "unauthorized")
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "unauthorized",
-			Code:  "UNAUTHORIZED",
-		})
+		httperr.Write(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
 
@@ -54,11 +121,7 @@ func (h *QueueHandler) JoinQueue(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "invalid request")
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid request",
-			Code:    "INVALID_REQUEST",
-			Message: err.Error(),
-		})
+		httperr.Write(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
 
@@ -75,7 +138,13 @@ func (h *QueueHandler) JoinQueue(c *gin.Context) {
 		return
 	}
 
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
+	if result.AlreadyInQueue {
+		// Idempotent repeat: nothing new was created
+		c.JSON(http.StatusOK, result)
+		return
+	}
 	c.JSON(http.StatusCreated, result)
 }
 
@@ -88,20 +157,14 @@ func (h *QueueHandler) GetPosition(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
 		span.SetStatus(codes.Error, "unauthorized")
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "unauthorized",
-			Code:  "UNAUTHORIZED",
-		})
+		httperr.Write(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
 
 	eventID := c.Param("event_id")
 	if eventID == "" {
 		span.SetStatus(codes.Error, "event_id required")
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "event_id required",
-			Code:  "INVALID_REQUEST",
-		})
+		httperr.Write(c, http.StatusBadRequest, "INVALID_REQUEST", "event_id required")
 		return
 	}
 
@@ -131,10 +194,7 @@ func (h *QueueHandler) LeaveQueue(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
 		span.SetStatus(codes.Error, "unauthorized")
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "unauthorized",
-			Code:  "UNAUTHORIZED",
-		})
+		httperr.Write(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
 
@@ -142,11 +202,7 @@ func (h *QueueHandler) LeaveQueue(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "invalid request")
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid request",
-			Code:    "INVALID_REQUEST",
-			Message: err.Error(),
-		})
+		httperr.Write(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
 
@@ -163,6 +219,7 @@ func (h *QueueHandler) LeaveQueue(c *gin.Context) {
 		return
 	}
 
+	telemetry.SetBusinessOutcome(span, telemetry.OutcomeSuccess)
 	span.SetStatus(codes.Ok, "")
 	c.JSON(http.StatusOK, result)
 }
@@ -176,10 +233,7 @@ func (h *QueueHandler) GetQueueStatus(c *gin.Context) {
 	eventID := c.Param("event_id")
 	if eventID == "" {
 		span.SetStatus(codes.Error, "event_id required")
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "event_id required",
-			Code:  "INVALID_REQUEST",
-		})
+		httperr.Write(c, http.StatusBadRequest, "INVALID_REQUEST", "event_id required")
 		return
 	}
 
@@ -209,20 +263,14 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
 		span.SetStatus(codes.Error, "unauthorized")
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "unauthorized",
-			Code:  "UNAUTHORIZED",
-		})
+		httperr.Write(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
 		return
 	}
 
 	eventID := c.Param("event_id")
 	if eventID == "" {
 		span.SetStatus(codes.Error, "event_id required")
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "event_id required",
-			Code:  "INVALID_REQUEST",
-		})
+		httperr.Write(c, http.StatusBadRequest, "INVALID_REQUEST", "event_id required")
 		return
 	}
 
@@ -231,6 +279,20 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 		attribute.String("event_id", eventID),
 	)
 
+	if err := h.registerStream(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, errTooManyStreams) {
+			// 429 rather than 503: the gateway counts 503s toward its backend
+			// circuit breaker, so hitting the cap would cut off the whole service
+			c.Header("Retry-After", streamRetryAfter)
+			httperr.Write(c, http.StatusTooManyRequests, "TOO_MANY_STREAMS", err.Error())
+			return
+		}
+		httperr.Write(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", err.Error())
+		return
+	}
+	defer h.releaseStream()
+
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -253,10 +315,7 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 		// Other error - return error response
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "internal server error",
-			Code:  "INTERNAL_ERROR",
-		})
+		httperr.Write(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 		return
 	}
 
@@ -270,13 +329,15 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 	}
 
 	// Send initial position
-	data, _ := json.Marshal(result)
-	c.Writer.WriteString(fmt.Sprintf("event: position\ndata: %s\n\n", data))
-	c.Writer.Flush()
+	if err := writeSSEEvent(c, "position", result); err != nil {
+		metrics.RecordQueueStreamDeadClient(ctx)
+		span.SetStatus(codes.Error, "client_gone")
+		return
+	}
 
 	// Use Pub/Sub if Redis client is available, otherwise fallback to polling
-	if h.redisClient != nil {
-		h.streamWithPubSub(c, ctx, userID, eventID)
+	if h.redisClient != nil || h.fanout != nil {
+		h.streamWithPubSub(c, ctx, userID, eventID, result.Position)
 	} else {
 		h.streamWithPolling(c, ctx, userID, eventID)
 	}
@@ -285,23 +346,18 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 }
 
 // streamWithPubSub uses Redis Pub/Sub to wait for queue pass notification
-// Uses per-user channel for targeted delivery - no broadcast amplification
-func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, userID, eventID string) {
-	// Subscribe to queue pass channel for this USER (targeted delivery)
-	// Trade-off: More Redis connections but no broadcast storm
-	channel := worker.QueuePassChannelKey(eventID, userID)
-	pubsub := h.redisClient.Subscribe(ctx, channel)
-	defer pubsub.Close()
-
-	// Get the channel for receiving messages
-	msgChan := pubsub.Channel()
-
-	// Create keepalive ticker (send position every 15 seconds to prevent timeout)
-	keepalive := time.NewTicker(15 * time.Second)
+// Messages arrive only for this user, either from the per-user channel or via the shared fan-out
+// lastPosition is the position already sent to the client; keepalives only resend it when it changes
+func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, userID, eventID string, lastPosition int64) {
+	sub := h.subscribeQueuePass(ctx, eventID, userID)
+	defer sub.close()
+
+	// Create keepalive ticker (send position or heartbeat periodically to prevent timeout)
+	keepalive := time.NewTicker(h.keepaliveInterval)
 	defer keepalive.Stop()
 
-	// Maximum wait time (5 minutes - should match queue pass TTL)
-	maxWait := time.NewTimer(5 * time.Minute)
+	// Maximum wait time (should match queue pass TTL)
+	maxWait := time.NewTimer(h.maxWait)
 	defer maxWait.Stop()
 
 	for {
@@ -310,7 +366,15 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 			// Client disconnected
 			return
 
-		case msg := <-msgChan:
+		case <-h.shutdown:
+			writeShutdownEvent(c)
+			return
+
+		case msg, ok := <-sub.messages:
+			if !ok {
+				// Subscription ended with the request context
+				return
+			}
 			// Received queue pass notification - this is already for this user (per-user channel)
 			var queuePassMsg worker.QueuePassReadyMessage
 			if err := json.Unmarshal([]byte(msg.Payload), &queuePassMsg); err != nil {
@@ -333,38 +397,19 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 			c.Writer.Flush()
 			return // Done, close connection
 
-		case <-keepalive.C:
-			// Send keepalive with current position (low frequency)
-			result, err := h.queueService.GetPosition(ctx, userID, eventID)
-			if err != nil {
-				if errors.Is(err, domain.ErrNotInQueue) {
-					data, _ := json.Marshal(map[string]interface{}{
-						"event":   "not_in_queue",
-						"message": "User is not in queue",
-					})
-					c.Writer.WriteString(fmt.Sprintf("event: error\ndata: %s\n\n", data))
-					c.Writer.Flush()
-					return
-				}
-				// Send keepalive heartbeat
-				c.Writer.WriteString(":keepalive\n\n")
-				c.Writer.Flush()
-				continue
+		case <-sub.reconnected:
+			// A pass published while Redis was unreachable was lost; check now
+			// rather than waiting for the next keepalive
+			if !h.refreshPosition(c, ctx, userID, eventID, &lastPosition) {
+				return
 			}
 
-			// If got queue pass (race condition - might have been set between publishes)
-			if result.IsReady && result.QueuePass != "" {
-				data, _ := json.Marshal(result)
-				c.Writer.WriteString(fmt.Sprintf("event: position\ndata: %s\n\n", data))
-				c.Writer.Flush()
+		case <-keepalive.C:
+			// Send keepalive with current position (low frequency)
+			if !h.refreshPosition(c, ctx, userID, eventID, &lastPosition) {
 				return
 			}
 
-			// Send position update
-			data, _ := json.Marshal(result)
-			c.Writer.WriteString(fmt.Sprintf("event: position\ndata: %s\n\n", data))
-			c.Writer.Flush()
-
 		case <-maxWait.C:
 			// Timeout - close connection
 			data, _ := json.Marshal(map[string]interface{}{
@@ -378,6 +423,70 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 	}
 }
 
+// refreshPosition fetches the current position and sends it, a heartbeat when
+// it hasn't moved since lastPosition, or the queue pass if it was issued. It
+// returns false when the stream should end.
+func (h *QueueHandler) refreshPosition(c *gin.Context, ctx context.Context, userID, eventID string, lastPosition *int64) bool {
+	result, err := h.queueService.GetPosition(ctx, userID, eventID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotInQueue) {
+			data, _ := json.Marshal(map[string]interface{}{
+				"event":   "not_in_queue",
+				"message": "User is not in queue",
+			})
+			c.Writer.WriteString(fmt.Sprintf("event: error\ndata: %s\n\n", data))
+			c.Writer.Flush()
+			return false
+		}
+		// Send keepalive heartbeat; a failed write means the client is gone
+		if err := writeSSE(c, ":keepalive\n\n"); err != nil {
+			metrics.RecordQueueStreamDeadClient(ctx)
+			return false
+		}
+		return true
+	}
+
+	// If got queue pass (race condition - might have been set between publishes)
+	if result.IsReady && result.QueuePass != "" {
+		data, _ := json.Marshal(result)
+		c.Writer.WriteString(fmt.Sprintf("event: position\ndata: %s\n\n", data))
+		c.Writer.Flush()
+		return false
+	}
+
+	// Send a position update only when it moved, otherwise a lightweight heartbeat;
+	// a failed write means the client is gone
+	var writeErr error
+	if result.Position != *lastPosition {
+		writeErr = writeSSEEvent(c, "position", result)
+		*lastPosition = result.Position
+	} else {
+		writeErr = writeSSE(c, ":heartbeat\n\n")
+	}
+	if writeErr != nil {
+		metrics.RecordQueueStreamDeadClient(ctx)
+		return false
+	}
+	return true
+}
+
+// subscribeQueuePass subscribes to queue pass notifications for userID. Both
+// modes re-subscribe after a Redis connection loss and signal reconnected.
+func (h *QueueHandler) subscribeQueuePass(ctx context.Context, eventID, userID string) *passSubscription {
+	if h.fanout != nil {
+		return h.fanout.add(eventID, userID)
+	}
+
+	// Subscribe to queue pass channel for this USER (targeted delivery)
+	// Trade-off: More Redis connections but no broadcast storm
+	sub := h.redisClient.ResilientSubscribe(ctx, worker.QueuePassChannelKey(eventID, userID))
+	return &passSubscription{
+		messages:    sub.Messages(),
+		reconnected: sub.Reconnected(),
+		close:       func() { sub.Close() },
+	}
+}
+
 // streamWithPolling is the fallback method using polling (for when Redis Pub/Sub is unavailable)
 func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, userID, eventID string) {
 	ticker := time.NewTicker(500 * time.Millisecond)
@@ -387,6 +496,9 @@ func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, us
 		select {
 		case <-ctx.Done():
 			return false
+		case <-h.shutdown:
+			writeShutdownEvent(c)
+			return false
 		case <-ticker.C:
 			result, err := h.queueService.GetPosition(ctx, userID, eventID)
 			if err != nil {
@@ -403,7 +515,10 @@ func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, us
 			}
 
 			data, _ := json.Marshal(result)
-			fmt.Fprintf(w, "event: position\ndata: %s\n\n", data)
+			if _, err := fmt.Fprintf(w, "event: position\ndata: %s\n\n", data); err != nil {
+				metrics.RecordQueueStreamDeadClient(ctx)
+				return false
+			}
 			c.Writer.Flush()
 
 			if result.IsReady && result.QueuePass != "" {
@@ -414,48 +529,30 @@ func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, us
 	})
 }
 
+// writeError responds with the shared error envelope and tags the request span with code
+func (h *QueueHandler) writeError(c *gin.Context, status int, code, message string) {
+	telemetry.SetBusinessOutcome(telemetry.SpanFromContext(c.Request.Context()), code)
+	httperr.Write(c, status, code, message)
+}
+
 // handleError converts domain errors to HTTP responses
 func (h *QueueHandler) handleError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, domain.ErrNotInQueue):
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "NOT_IN_QUEUE",
-		})
+		h.writeError(c, http.StatusNotFound, "NOT_IN_QUEUE", err.Error())
 	case errors.Is(err, domain.ErrAlreadyInQueue):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "ALREADY_IN_QUEUE",
-		})
+		h.writeError(c, http.StatusConflict, "ALREADY_IN_QUEUE", err.Error())
 	case errors.Is(err, domain.ErrQueueFull):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "QUEUE_FULL",
-		})
+		h.writeError(c, http.StatusConflict, "QUEUE_FULL", err.Error())
 	case errors.Is(err, domain.ErrQueueNotOpen):
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "QUEUE_NOT_OPEN",
-		})
+		h.writeError(c, http.StatusConflict, "QUEUE_NOT_OPEN", err.Error())
 	case errors.Is(err, domain.ErrInvalidQueueToken):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "INVALID_TOKEN",
-		})
+		h.writeError(c, http.StatusForbidden, "INVALID_TOKEN", err.Error())
 	case errors.Is(err, domain.ErrInvalidUserID):
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "FORBIDDEN",
-		})
+		h.writeError(c, http.StatusForbidden, "FORBIDDEN", err.Error())
 	case errors.Is(err, domain.ErrInvalidEventID):
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "INVALID_EVENT_ID",
-		})
+		h.writeError(c, http.StatusBadRequest, "INVALID_EVENT_ID", err.Error())
 	default:
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "internal server error",
-			Code:  "INTERNAL_ERROR",
-		})
+		h.writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	pkgresponse "github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -63,12 +64,14 @@ func (m *MockQueueService) DeleteQueuePass(ctx context.Context, userID, eventID
 	return args.Error(0)
 }
 
+func (m *MockQueueService) IsSingleUsePass(ctx context.Context, eventID string) (bool, error) {
+	args := m.Called(ctx, eventID)
+	return args.Bool(0), args.Error(1)
+}
+
 // newTestQueueHandler creates a QueueHandler for testing
 func newTestQueueHandler(queueService *MockQueueService) *QueueHandler {
-	return &QueueHandler{
-		queueService: queueService,
-		redisClient:  nil, // redis.Client can be nil for tests
-	}
+	return NewQueueHandler(queueService, nil, nil) // redis.Client can be nil for tests
 }
 
 func setupQueueTestRouter(handler *QueueHandler) *gin.Engine {
@@ -88,6 +91,7 @@ func setupQueueTestRouter(handler *QueueHandler) *gin.Engine {
 	{
 		queue.POST("/join", handler.JoinQueue)
 		queue.GET("/position/:event_id", handler.GetPosition)
+		queue.GET("/position/:event_id/stream", handler.StreamPosition)
 		queue.DELETE("/leave", handler.LeaveQueue)
 		queue.GET("/status/:event_id", handler.GetQueueStatus)
 	}
@@ -102,12 +106,13 @@ func TestQueueHandler_JoinQueue_Success(t *testing.T) {
 
 	now := time.Now()
 	expectedResponse := &dto.JoinQueueResponse{
-		Position:      1,
-		Token:         "test-token-123",
-		EstimatedWait: 3,
-		JoinedAt:      now,
-		ExpiresAt:     now.Add(30 * time.Minute),
-		Message:       "Successfully joined the queue",
+		Position:       1,
+		Token:          "test-token-123",
+		EstimatedWait:  3,
+		JoinedAt:       now,
+		ExpiresAt:      now.Add(30 * time.Minute),
+		QueueSizeAfter: 12,
+		Message:        "Successfully joined the queue",
 	}
 
 	mockService.On("JoinQueue", mock.Anything, "user-123", mock.AnythingOfType("*dto.JoinQueueRequest")).Return(expectedResponse, nil)
@@ -131,6 +136,8 @@ func TestQueueHandler_JoinQueue_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), response.Position)
 	assert.Equal(t, "test-token-123", response.Token)
+	assert.Equal(t, int64(12), response.QueueSizeAfter)
+	assert.Contains(t, w.Body.String(), `"queue_size_after":12`)
 
 	mockService.AssertExpectations(t)
 }
@@ -195,14 +202,84 @@ func TestQueueHandler_JoinQueue_AlreadyInQueue(t *testing.T) {
 
 	assert.Equal(t, http.StatusConflict, w.Code)
 
-	var response dto.ErrorResponse
+	var response pkgresponse.Response
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "ALREADY_IN_QUEUE", response.Code)
+	assert.Equal(t, "ALREADY_IN_QUEUE", response.Error.Code)
 
 	mockService.AssertExpectations(t)
 }
 
+func TestQueueHandler_JoinQueue_Idempotent(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		userID         string
+		response       *dto.JoinQueueResponse
+		expectedStatus int
+	}{
+		{
+			name:   "first join",
+			userID: "user-123",
+			response: &dto.JoinQueueResponse{
+				Position: 1, Token: "token-123", JoinedAt: now, ExpiresAt: now.Add(30 * time.Minute),
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "idempotent repeat",
+			userID: "user-123",
+			response: &dto.JoinQueueResponse{
+				Position: 1, Token: "token-123", JoinedAt: now, ExpiresAt: now.Add(30 * time.Minute),
+				AlreadyInQueue: true,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "different user",
+			userID: "user-456",
+			response: &dto.JoinQueueResponse{
+				Position: 2, Token: "token-456", JoinedAt: now, ExpiresAt: now.Add(30 * time.Minute),
+			},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockQueueService)
+			handler := newTestQueueHandler(mockService)
+			router := setupQueueTestRouter(handler)
+
+			mockService.On("JoinQueue", mock.Anything, tt.userID, mock.MatchedBy(func(req *dto.JoinQueueRequest) bool {
+				return req.Idempotent
+			})).Return(tt.response, nil)
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"event_id":   "event-123",
+				"idempotent": true,
+			})
+
+			req, _ := http.NewRequest("POST", "/api/v1/queue/join", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User-ID", tt.userID)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response dto.JoinQueueResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.response.Position, response.Position)
+			assert.Equal(t, tt.response.Token, response.Token)
+			assert.Equal(t, tt.response.AlreadyInQueue, response.AlreadyInQueue)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
 func TestQueueHandler_GetPosition_Success(t *testing.T) {
 	mockService := new(MockQueueService)
 	handler := newTestQueueHandler(mockService)
@@ -250,10 +327,10 @@ func TestQueueHandler_GetPosition_NotInQueue(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	var response dto.ErrorResponse
+	var response pkgresponse.Response
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "NOT_IN_QUEUE", response.Code)
+	assert.Equal(t, "NOT_IN_QUEUE", response.Error.Code)
 
 	mockService.AssertExpectations(t)
 }
@@ -264,8 +341,11 @@ func TestQueueHandler_LeaveQueue_Success(t *testing.T) {
 	router := setupQueueTestRouter(handler)
 
 	expectedResponse := &dto.LeaveQueueResponse{
-		Success: true,
-		Message: "Successfully left the queue",
+		Success:          true,
+		Message:          "Successfully left the queue",
+		WasInQueue:       true,
+		PreviousPosition: 3,
+		QueueSizeAfter:   7,
 	}
 
 	mockService.On("LeaveQueue", mock.Anything, "user-123", mock.AnythingOfType("*dto.LeaveQueueRequest")).Return(expectedResponse, nil)
@@ -285,10 +365,13 @@ func TestQueueHandler_LeaveQueue_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response dto.LeaveQueueResponse
+	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.True(t, response.Success)
+	assert.Equal(t, true, response["success"])
+	assert.Equal(t, true, response["was_in_queue"])
+	assert.Equal(t, float64(3), response["previous_position"])
+	assert.Equal(t, float64(7), response["queue_size_after"])
 
 	mockService.AssertExpectations(t)
 }
@@ -315,10 +398,10 @@ func TestQueueHandler_LeaveQueue_InvalidToken(t *testing.T) {
 
 	assert.Equal(t, http.StatusForbidden, w.Code)
 
-	var response dto.ErrorResponse
+	var response pkgresponse.Response
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "INVALID_TOKEN", response.Code)
+	assert.Equal(t, "INVALID_TOKEN", response.Error.Code)
 
 	mockService.AssertExpectations(t)
 }
@@ -374,10 +457,10 @@ func TestQueueHandler_QueueFull(t *testing.T) {
 
 	assert.Equal(t, http.StatusConflict, w.Code)
 
-	var response dto.ErrorResponse
+	var response pkgresponse.Response
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "QUEUE_FULL", response.Code)
+	assert.Equal(t, "QUEUE_FULL", response.Error.Code)
 
 	mockService.AssertExpectations(t)
 }
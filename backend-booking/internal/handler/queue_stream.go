@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+)
+
+const (
+	// defaultStreamKeepalive is how often an idle SSE stream re-sends the position
+	defaultStreamKeepalive = 15 * time.Second
+	// defaultStreamMaxWait is how long an SSE stream waits for a queue pass
+	defaultStreamMaxWait = 5 * time.Minute
+)
+
+// streamRetryAfter is the Retry-After (seconds) sent when the stream cap is reached
+const streamRetryAfter = "5"
+
+var (
+	errShuttingDown   = errors.New("service is shutting down")
+	errTooManyStreams = errors.New("too many open queue streams")
+)
+
+// registerStream records a new SSE stream so Shutdown can wait for it and the
+// connection cap can be enforced. Callers must call releaseStream when a
+// successfully registered stream ends.
+func (h *QueueHandler) registerStream() error {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+
+	if h.shuttingDown {
+		return errShuttingDown
+	}
+	if h.maxStreams > 0 && h.activeStreams.Load() >= h.maxStreams {
+		return errTooManyStreams
+	}
+	h.activeStreams.Add(1)
+	h.streams.Add(1)
+	metrics.RecordQueueStreamOpened(context.Background())
+	return nil
+}
+
+// releaseStream marks a registered stream as finished
+func (h *QueueHandler) releaseStream() {
+	h.activeStreams.Add(-1)
+	h.streams.Done()
+	metrics.RecordQueueStreamClosed(context.Background())
+}
+
+// ActiveStreams returns the number of open SSE position streams, for metrics
+func (h *QueueHandler) ActiveStreams() int64 {
+	return h.activeStreams.Load()
+}
+
+// Shutdown tells every open SSE stream to send a final "shutdown" event and
+// close, then waits until they have all returned or ctx is done. New streams
+// are refused with 503 once Shutdown has been called. Call it before
+// http.Server.Shutdown, which would otherwise wait on the long-lived streams.
+func (h *QueueHandler) Shutdown(ctx context.Context) error {
+	h.streamsMu.Lock()
+	if !h.shuttingDown {
+		h.shuttingDown = true
+		close(h.shutdown)
+	}
+	h.streamsMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		h.streams.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeShutdownEvent tells the client the server is going away so it can reconnect elsewhere
+func writeShutdownEvent(c *gin.Context) {
+	data, _ := json.Marshal(map[string]interface{}{
+		"event":   "shutdown",
+		"message": "Server is shutting down, please reconnect",
+	})
+	c.Writer.WriteString(fmt.Sprintf("event: shutdown\ndata: %s\n\n", data))
+	c.Writer.Flush()
+}
+
+// writeSSE writes a raw SSE frame and flushes it to the client. An error means
+// the client has gone away (e.g. broken pipe) and the stream should end.
+func writeSSE(c *gin.Context, frame string) error {
+	if _, err := c.Writer.WriteString(frame); err != nil {
+		return err
+	}
+	return flushSSE(c)
+}
+
+// writeSSEEvent writes v as the JSON data of a named SSE event
+func writeSSEEvent(c *gin.Context, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeSSE(c, fmt.Sprintf("event: %s\ndata: %s\n\n", event, data))
+}
+
+// flushSSE flushes buffered frames. gin's Flush swallows errors, so flush the
+// underlying writer through http.ResponseController to see a dead connection
+// on this write rather than the next one.
+func flushSSE(c *gin.Context) error {
+	c.Writer.WriteHeaderNow()
+
+	var w http.ResponseWriter = c.Writer
+	if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		w = u.Unwrap()
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sseFrame is one message read from an SSE stream; comment frames have only Comment set
+type sseFrame struct {
+	Event   string
+	Data    string
+	Comment string
+}
+
+// readSSEFrames reads frames from r until EOF
+func readSSEFrames(r io.Reader) []sseFrame {
+	var frames []sseFrame
+	var cur sseFrame
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if cur != (sseFrame{}) {
+				frames = append(frames, cur)
+			}
+			cur = sseFrame{}
+		case strings.HasPrefix(line, ":"):
+			cur.Comment = strings.TrimPrefix(line, ":")
+		case strings.HasPrefix(line, "event: "):
+			cur.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return frames
+}
+
+// openQueueStream starts an SSE request against server for userID on event-123
+func openQueueStream(t *testing.T, server *httptest.Server, userID string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/queue/position/event-123/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-User-ID", userID)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestQueueHandler_StreamPosition_Shutdown(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	streaming := make(chan struct{})
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").
+		Return(waiting, nil).
+		Once().
+		Run(func(mock.Arguments) { close(streaming) })
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").Return(waiting, nil)
+
+	resp := openQueueStream(t, server, "user-123")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	<-streaming
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, handler.Shutdown(ctx))
+
+	frames := readSSEFrames(resp.Body)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "position", frames[0].Event)
+	assert.Equal(t, "shutdown", frames[len(frames)-1].Event)
+
+	// Streams opened after shutdown are refused
+	late := openQueueStream(t, server, "user-123")
+	assert.Equal(t, http.StatusServiceUnavailable, late.StatusCode)
+}
+
+func TestQueueHandler_Shutdown_NoStreams(t *testing.T) {
+	handler := newTestQueueHandler(new(MockQueueService))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, handler.Shutdown(ctx))
+	// Shutdown is idempotent
+	assert.NoError(t, handler.Shutdown(ctx))
+}
+
+func TestQueueHandler_StreamPosition_MaxStreams(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueHandler(mockService, nil, &QueueHandlerConfig{MaxStreams: 2})
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+	defer handler.Shutdown(context.Background())
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").Return(waiting, nil)
+
+	for i := 0; i < 2; i++ {
+		resp := openQueueStream(t, server, "user-123")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	require.Eventually(t, func() bool { return handler.ActiveStreams() == 2 }, time.Second, 10*time.Millisecond)
+
+	rejected := openQueueStream(t, server, "user-123")
+	assert.Equal(t, http.StatusTooManyRequests, rejected.StatusCode)
+	assert.Equal(t, streamRetryAfter, rejected.Header.Get("Retry-After"))
+	assert.Equal(t, int64(2), handler.ActiveStreams())
+}
+
+// brokenPipeWriter is a flushable ResponseWriter whose writes fail with EPIPE
+// after the first okWrites, like a client that disconnected mid-stream
+type brokenPipeWriter struct {
+	header   http.Header
+	mu       sync.Mutex
+	writes   int
+	okWrites int
+}
+
+func (w *brokenPipeWriter) Header() http.Header { return w.header }
+func (w *brokenPipeWriter) WriteHeader(int)     {}
+func (w *brokenPipeWriter) Flush()              {}
+
+func (w *brokenPipeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	if w.writes > w.okWrites {
+		return 0, syscall.EPIPE
+	}
+	return len(p), nil
+}
+
+func TestQueueHandler_StreamPosition_DeadClient(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueHandler(mockService, nil, &QueueHandlerConfig{KeepaliveInterval: 10 * time.Millisecond})
+	sub := newFakePatternSubscriber()
+	handler.fanout = newPassFanout(sub.subscribe)
+	router := setupQueueTestRouter(handler)
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").Return(waiting, nil)
+
+	// The initial position goes through; the first keepalive hits a broken pipe.
+	// The request context is never cancelled, so only the write error can end the stream.
+	w := &brokenPipeWriter{header: make(http.Header), okWrites: 1}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/queue/position/event-123/stream", nil)
+	req.Header.Set("X-User-ID", "user-123")
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not exit after a failed write")
+	}
+	assert.Equal(t, int64(0), handler.ActiveStreams())
+	assert.Equal(t, 1, sub.closed, "the Redis subscription should be released")
+}
+
+func TestQueueHandler_StreamPosition_CustomTimings(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueHandler(mockService, nil, &QueueHandlerConfig{
+		KeepaliveInterval: 20 * time.Millisecond,
+		MaxWait:           150 * time.Millisecond,
+	})
+	handler.fanout = newPassFanout(newFakePatternSubscriber().subscribe)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").Return(waiting, nil)
+
+	start := time.Now()
+	resp := openQueueStream(t, server, "user-123")
+	frames := readSSEFrames(resp.Body)
+	elapsed := time.Since(start)
+
+	// The initial position plus several fast keepalives, then the max-wait timeout
+	require.NotEmpty(t, frames)
+	assert.GreaterOrEqual(t, countHeartbeats(frames), 3, "expected a keepalive every 20ms")
+	last := frames[len(frames)-1]
+	assert.Equal(t, "error", last.Event)
+	assert.Contains(t, last.Data, "timeout")
+	assert.Less(t, elapsed, 5*time.Second, "custom max wait should end the stream")
+}
+
+func TestQueueHandlerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     QueueHandlerConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: QueueHandlerConfig{}},
+		{name: "custom", cfg: QueueHandlerConfig{KeepaliveInterval: 30 * time.Second, MaxWait: 10 * time.Minute}},
+		{name: "keepalive equals max wait", cfg: QueueHandlerConfig{KeepaliveInterval: time.Minute, MaxWait: time.Minute}, wantErr: true},
+		{name: "keepalive above default max wait", cfg: QueueHandlerConfig{KeepaliveInterval: 10 * time.Minute}, wantErr: true},
+		{name: "negative keepalive", cfg: QueueHandlerConfig{KeepaliveInterval: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewQueueHandler_InvalidTimingsUseDefaults(t *testing.T) {
+	handler := NewQueueHandler(new(MockQueueService), nil, &QueueHandlerConfig{
+		KeepaliveInterval: time.Hour,
+		MaxWait:           time.Minute,
+	})
+	assert.Equal(t, defaultStreamKeepalive, handler.keepaliveInterval)
+	assert.Equal(t, defaultStreamMaxWait, handler.maxWait)
+}
+
+// countHeartbeats returns how many ":heartbeat" comment frames were received
+func countHeartbeats(frames []sseFrame) int {
+	n := 0
+	for _, f := range frames {
+		if f.Comment == "heartbeat" {
+			n++
+		}
+	}
+	return n
+}
+
+// positionEvents returns the decoded data of every "position" event
+func positionEvents(t *testing.T, frames []sseFrame) []dto.QueuePositionResponse {
+	t.Helper()
+
+	var positions []dto.QueuePositionResponse
+	for _, f := range frames {
+		if f.Event != "position" {
+			continue
+		}
+		var p dto.QueuePositionResponse
+		require.NoError(t, json.Unmarshal([]byte(f.Data), &p))
+		positions = append(positions, p)
+	}
+	return positions
+}
+
+func TestQueueHandler_StreamPosition_StablePositionSendsHeartbeats(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueHandler(mockService, nil, &QueueHandlerConfig{
+		KeepaliveInterval: 20 * time.Millisecond,
+		MaxWait:           150 * time.Millisecond,
+	})
+	handler.fanout = newPassFanout(newFakePatternSubscriber().subscribe)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	waiting := &dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").Return(waiting, nil)
+
+	frames := readSSEFrames(openQueueStream(t, server, "user-123").Body)
+
+	// Only the initial snapshot is a position event; every later tick is a heartbeat
+	assert.Len(t, positionEvents(t, frames), 1)
+	assert.GreaterOrEqual(t, countHeartbeats(frames), 3)
+}
+
+func TestQueueHandler_StreamPosition_PositionChangeSendsEvent(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueHandler(mockService, nil, &QueueHandlerConfig{
+		KeepaliveInterval: 20 * time.Millisecond,
+		MaxWait:           150 * time.Millisecond,
+	})
+	handler.fanout = newPassFanout(newFakePatternSubscriber().subscribe)
+	server := httptest.NewServer(setupQueueTestRouter(handler))
+	defer server.Close()
+
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").
+		Return(&dto.QueuePositionResponse{Position: 5, TotalInQueue: 100}, nil).Twice()
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-123").
+		Return(&dto.QueuePositionResponse{Position: 3, TotalInQueue: 100}, nil)
+
+	frames := readSSEFrames(openQueueStream(t, server, "user-123").Body)
+
+	positions := positionEvents(t, frames)
+	require.Len(t, positions, 2)
+	assert.Equal(t, int64(5), positions[0].Position)
+	assert.Equal(t, int64(3), positions[1].Position)
+	assert.GreaterOrEqual(t, countHeartbeats(frames), 1, "the unchanged tick should be a heartbeat")
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
@@ -16,9 +17,13 @@ var (
 	BookingsFailed    *telemetry.Counter
 	BookingsCancelled *telemetry.Counter
 
+	// Reservation attempts by outcome, for flash-sale success/failure rates
+	ReservationsTotal *telemetry.Counter
+
 	// Queue counters
-	QueueJoined *telemetry.Counter
-	QueueLeft   *telemetry.Counter
+	QueueJoined            *telemetry.Counter
+	QueueLeft              *telemetry.Counter
+	QueueStreamDeadClients *telemetry.Counter
 
 	// Error tracking counters
 	ErrorsTotal      *telemetry.Counter
@@ -28,10 +33,15 @@ var (
 	ReservationDuration *telemetry.Histogram
 	QueueWaitTime       *telemetry.Histogram
 	RequestDuration     *telemetry.Histogram
+	ReservationLatency  *telemetry.Histogram
+
+	// Seats left per zone after the latest reservation
+	SeatsRemaining *telemetry.Gauge
 
 	// Gauges
 	ActiveReservations *telemetry.UpDownCounter
 	QueueDepth         *telemetry.UpDownCounter
+	QueueStreams       *telemetry.UpDownCounter
 
 	initOnce sync.Once
 	initErr  error
@@ -94,6 +104,15 @@ func initMetrics() error {
 		return err
 	}
 
+	ReservationsTotal, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "reservations_total",
+		Description: "Total number of reservation attempts by outcome",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
 	// Queue counters
 	QueueJoined, err = telemetry.NewCounter(telemetry.MetricOpts{
 		Name:        "queue_joins_total",
@@ -113,6 +132,15 @@ func initMetrics() error {
 		return err
 	}
 
+	QueueStreamDeadClients, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "queue_sse_dead_clients_total",
+		Description: "Total number of SSE queue streams closed after a failed write",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
 	// Histograms with custom buckets for latency
 	ReservationDuration, err = telemetry.NewHistogramWithBuckets(telemetry.MetricOpts{
 		Name:        "booking_reservation_duration_seconds",
@@ -142,6 +170,24 @@ func initMetrics() error {
 		return err
 	}
 
+	ReservationLatency, err = telemetry.NewHistogramWithBuckets(telemetry.MetricOpts{
+		Name:        "reservation_latency_ms",
+		Description: "Latency of reservation attempts in milliseconds",
+		Unit:        "ms",
+	}, []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500}) // 1ms to 2.5s
+	if err != nil {
+		return err
+	}
+
+	SeatsRemaining, err = telemetry.NewGauge(telemetry.MetricOpts{
+		Name:        "seats_remaining",
+		Description: "Seats available in a zone after the latest reservation",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
 	// Error tracking
 	ErrorsTotal, err = telemetry.NewCounter(telemetry.MetricOpts{
 		Name:        "booking_errors_total",
@@ -180,6 +226,15 @@ func initMetrics() error {
 		return err
 	}
 
+	QueueStreams, err = telemetry.NewUpDownCounter(telemetry.MetricOpts{
+		Name:        "queue_sse_streams",
+		Description: "Current number of open SSE queue position streams",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -197,6 +252,54 @@ func RecordReservation(ctx context.Context, eventID, userID, zoneID string, quan
 	}
 }
 
+// Reservation outcomes recorded by RecordReservationOutcome
+const (
+	OutcomeSuccess           = "success"
+	OutcomeInsufficientStock = "insufficient_stock"
+	OutcomeUserLimitExceeded = "user_limit_exceeded"
+	OutcomeZoneNotFound      = "zone_not_found"
+	OutcomeQueuePassRejected = "queue_pass_rejected"
+	OutcomeInvalidRequest    = "invalid_request"
+	OutcomeError             = "error"
+)
+
+// RecordReservationOutcome records a reservation attempt's outcome and latency.
+// zone_id comes from the request, so it is only recorded for outcomes that prove
+// the zone exists; otherwise callers could create unbounded label values.
+func RecordReservationOutcome(ctx context.Context, zoneID, outcome string, latency time.Duration) {
+	if ReservationsTotal != nil {
+		attrs := []attribute.KeyValue{attribute.String("outcome", outcome)}
+		if zoneValidated(outcome) {
+			attrs = append(attrs, attribute.String("zone_id", zoneID))
+		}
+		ReservationsTotal.Inc(ctx, attrs...)
+	}
+	if ReservationLatency != nil {
+		ReservationLatency.Record(ctx, float64(latency)/float64(time.Millisecond),
+			attribute.String("outcome", outcome),
+		)
+	}
+}
+
+// zoneValidated reports whether outcome is only reached after the reserve script found the zone
+func zoneValidated(outcome string) bool {
+	switch outcome {
+	case OutcomeSuccess, OutcomeInsufficientStock, OutcomeUserLimitExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// RecordSeatsRemaining records the seats left in a zone
+func RecordSeatsRemaining(ctx context.Context, zoneID string, remaining int64) {
+	if SeatsRemaining != nil {
+		SeatsRemaining.Record(ctx, remaining,
+			attribute.String("zone_id", zoneID),
+		)
+	}
+}
+
 // RecordConfirmation records a booking confirmation metric
 func RecordConfirmation(ctx context.Context, eventID, userID string, durationSeconds float64) {
 	if BookingsConfirmed != nil {
@@ -277,6 +380,27 @@ func RecordQueueLeave(ctx context.Context, eventID string, waitTimeSeconds float
 	}
 }
 
+// RecordQueueStreamOpened records an SSE queue position stream being opened
+func RecordQueueStreamOpened(ctx context.Context) {
+	if QueueStreams != nil {
+		QueueStreams.Inc(ctx)
+	}
+}
+
+// RecordQueueStreamClosed records an SSE queue position stream being closed
+func RecordQueueStreamClosed(ctx context.Context) {
+	if QueueStreams != nil {
+		QueueStreams.Dec(ctx)
+	}
+}
+
+// RecordQueueStreamDeadClient records an SSE queue stream cleaned up after its client went away
+func RecordQueueStreamDeadClient(ctx context.Context) {
+	if QueueStreamDeadClients != nil {
+		QueueStreamDeadClients.Inc(ctx)
+	}
+}
+
 // RecordError records an error by type and operation
 func RecordError(ctx context.Context, errorType, operation string) {
 	if ErrorsTotal != nil {
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// findMetric returns the named metric from collected resource metrics
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s not recorded", name)
+	return metricdata.Metrics{}
+}
+
+func TestReservationMetrics(t *testing.T) {
+	// Instruments are created through the global provider, so install an
+	// in-memory reader before Init
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	require.NoError(t, Init())
+
+	ctx := context.Background()
+	RecordReservationOutcome(ctx, "zone-a", OutcomeSuccess, 3*time.Millisecond)
+	RecordReservationOutcome(ctx, "zone-a", OutcomeSuccess, 7*time.Millisecond)
+	RecordReservationOutcome(ctx, "zone-a", OutcomeInsufficientStock, 2*time.Millisecond)
+	RecordReservationOutcome(ctx, "zone-b", OutcomeSuccess, 40*time.Millisecond)
+	// Unvalidated zone IDs must not become label values
+	RecordReservationOutcome(ctx, "no-such-zone-1", OutcomeZoneNotFound, time.Millisecond)
+	RecordReservationOutcome(ctx, "no-such-zone-2", OutcomeInvalidRequest, time.Millisecond)
+	RecordSeatsRemaining(ctx, "zone-a", 12)
+	RecordSeatsRemaining(ctx, "zone-a", 10)
+	RecordSeatsRemaining(ctx, "zone-b", 99)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	// reservations_total counts attempts per (outcome, zone)
+	counts := map[[2]string]int64{}
+	total := findMetric(t, rm, "reservations_total").Data.(metricdata.Sum[int64])
+	for _, dp := range total.DataPoints {
+		outcome, _ := dp.Attributes.Value("outcome")
+		zone, _ := dp.Attributes.Value("zone_id")
+		counts[[2]string{outcome.AsString(), zone.AsString()}] = dp.Value
+	}
+	assert.Equal(t, map[[2]string]int64{
+		{OutcomeSuccess, "zone-a"}:           2,
+		{OutcomeInsufficientStock, "zone-a"}: 1,
+		{OutcomeSuccess, "zone-b"}:           1,
+		{OutcomeZoneNotFound, ""}:            1,
+		{OutcomeInvalidRequest, ""}:          1,
+	}, counts)
+
+	// reservation_latency_ms records every attempt in milliseconds
+	latency := findMetric(t, rm, "reservation_latency_ms")
+	assert.Equal(t, "ms", latency.Unit)
+	var samples uint64
+	var sum float64
+	for _, dp := range latency.Data.(metricdata.Histogram[float64]).DataPoints {
+		samples += dp.Count
+		sum += dp.Sum
+	}
+	assert.Equal(t, uint64(6), samples)
+	assert.InDelta(t, 54, sum, 0.001)
+
+	// seats_remaining keeps the latest value per zone
+	remaining := map[string]int64{}
+	for _, dp := range findMetric(t, rm, "seats_remaining").Data.(metricdata.Gauge[int64]).DataPoints {
+		zone, _ := dp.Attributes.Value("zone_id")
+		remaining[zone.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"zone-a": 10, "zone-b": 99}, remaining)
+}
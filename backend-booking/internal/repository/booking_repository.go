@@ -26,8 +26,8 @@ type BookingRepository interface {
 	// Delete deletes a booking by its ID
 	Delete(ctx context.Context, id string) error
 
-	// Confirm confirms a booking with payment info
-	Confirm(ctx context.Context, id, paymentID string) error
+	// Confirm confirms a booking with payment info and its confirmation code
+	Confirm(ctx context.Context, id, paymentID, confirmationCode string) error
 
 	// Cancel cancels a booking
 	Cancel(ctx context.Context, id string) error
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultAvailabilityCacheTTL is how long a coalesced zone availability read is reused
+const DefaultAvailabilityCacheTTL = 250 * time.Millisecond
+
+// MaxAvailabilityCacheEntries bounds the number of zones whose reads are cached
+const MaxAvailabilityCacheEntries = 10000
+
+// cachedAvailability is a zone availability read and when it stops being reused
+type cachedAvailability struct {
+	seats     int64
+	expiresAt time.Time
+}
+
+// CoalescingReservationRepository wraps a ReservationRepository so that
+// concurrent GetZoneAvailability calls for the same zone share one read of the
+// underlying store, and the result is reused for a short TTL. During a rush
+// thousands of clients poll the same zones; this turns those polls into one
+// Redis GET per zone per TTL. Reservations still go straight to the wrapped
+// repository, so availability reads may lag by up to the TTL. Only use it for
+// reads that tolerate that lag, never to check whether a zone exists.
+type CoalescingReservationRepository struct {
+	ReservationRepository
+
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu    sync.RWMutex
+	cache map[string]cachedAvailability
+}
+
+// NewCoalescingReservationRepository wraps repo. A non-positive ttl uses
+// DefaultAvailabilityCacheTTL.
+func NewCoalescingReservationRepository(repo ReservationRepository, ttl time.Duration) *CoalescingReservationRepository {
+	if ttl <= 0 {
+		ttl = DefaultAvailabilityCacheTTL
+	}
+	return &CoalescingReservationRepository{
+		ReservationRepository: repo,
+		ttl:                   ttl,
+		cache:                 make(map[string]cachedAvailability),
+	}
+}
+
+// GetZoneAvailability returns the zone's cached availability if it is fresh,
+// otherwise reads it once on behalf of every concurrent caller
+func (r *CoalescingReservationRepository) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
+	r.mu.RLock()
+	entry, ok := r.cache[zoneID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.seats, nil
+	}
+
+	v, err, _ := r.group.Do(zoneID, func() (interface{}, error) {
+		// The read is shared, so one caller cancelling must not fail the others
+		seats, err := r.ReservationRepository.GetZoneAvailability(context.WithoutCancel(ctx), zoneID)
+		if err != nil {
+			return int64(0), err
+		}
+
+		r.store(zoneID, seats)
+		return seats, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// SetZoneAvailability sets the zone's availability and drops its cached read
+func (r *CoalescingReservationRepository) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
+	err := r.ReservationRepository.SetZoneAvailability(ctx, zoneID, seats)
+	r.invalidate(zoneID)
+	return err
+}
+
+// InitZone initializes the zone's availability and drops its cached read
+func (r *CoalescingReservationRepository) InitZone(ctx context.Context, zoneID, showID string, seats int64, ttl time.Duration) (bool, error) {
+	created, err := r.ReservationRepository.InitZone(ctx, zoneID, showID, seats, ttl)
+	r.invalidate(zoneID)
+	return created, err
+}
+
+// store caches the zone's availability. When the cache is full, expired
+// entries are dropped first; if it is still full the read is not cached.
+func (r *CoalescingReservationRepository) store(zoneID string, seats int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := r.cache[zoneID]; !ok && len(r.cache) >= MaxAvailabilityCacheEntries {
+		for id, entry := range r.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(r.cache, id)
+			}
+		}
+		if len(r.cache) >= MaxAvailabilityCacheEntries {
+			return
+		}
+	}
+	r.cache[zoneID] = cachedAvailability{seats: seats, expiresAt: now.Add(r.ttl)}
+}
+
+// invalidate drops the zone's cached availability
+func (r *CoalescingReservationRepository) invalidate(zoneID string) {
+	r.mu.Lock()
+	delete(r.cache, zoneID)
+	r.mu.Unlock()
+}
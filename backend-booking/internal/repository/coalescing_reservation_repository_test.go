@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingAvailabilityRepo counts zone availability reads; release unblocks them
+type countingAvailabilityRepo struct {
+	ReservationRepository
+	reads   atomic.Int32
+	release chan struct{}
+	seats   int64
+	err     error
+}
+
+func (r *countingAvailabilityRepo) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
+	r.reads.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.seats, r.err
+}
+
+func (r *countingAvailabilityRepo) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
+	r.seats = seats
+	return nil
+}
+
+func TestCoalescingReservationRepository_ConcurrentReadsShareOneGet(t *testing.T) {
+	inner := &countingAvailabilityRepo{release: make(chan struct{}), seats: 42}
+	repo := NewCoalescingReservationRepository(inner, time.Minute)
+
+	const readers = 500
+	var wg sync.WaitGroup
+	results := make(chan int64, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seats, err := repo.GetZoneAvailability(context.Background(), "zone-001")
+			if err != nil {
+				t.Errorf("GetZoneAvailability() error = %v", err)
+			}
+			results <- seats
+		}()
+	}
+
+	// Let the readers pile up behind the in-flight read before it returns
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+	close(results)
+
+	for seats := range results {
+		if seats != 42 {
+			t.Errorf("GetZoneAvailability() = %d, want 42", seats)
+		}
+	}
+	if n := inner.reads.Load(); n != 1 {
+		t.Errorf("Expected 1 read of the underlying store, got %d", n)
+	}
+
+	// Reads within the TTL are served from cache
+	if _, err := repo.GetZoneAvailability(context.Background(), "zone-001"); err != nil {
+		t.Fatalf("GetZoneAvailability() error = %v", err)
+	}
+	if n := inner.reads.Load(); n != 1 {
+		t.Errorf("Expected cached read, got %d reads of the underlying store", n)
+	}
+}
+
+func TestCoalescingReservationRepository_TTLExpiry(t *testing.T) {
+	inner := &countingAvailabilityRepo{seats: 10}
+	repo := NewCoalescingReservationRepository(inner, 20*time.Millisecond)
+	ctx := context.Background()
+
+	repo.GetZoneAvailability(ctx, "zone-001")
+	repo.GetZoneAvailability(ctx, "zone-002")
+	if n := inner.reads.Load(); n != 2 {
+		t.Errorf("Expected zones to be read separately, got %d reads", n)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	repo.GetZoneAvailability(ctx, "zone-001")
+	if n := inner.reads.Load(); n != 3 {
+		t.Errorf("Expected a fresh read after the TTL, got %d reads", n)
+	}
+}
+
+func TestCoalescingReservationRepository_SetInvalidates(t *testing.T) {
+	inner := &countingAvailabilityRepo{seats: 10}
+	repo := NewCoalescingReservationRepository(inner, time.Minute)
+	ctx := context.Background()
+
+	repo.GetZoneAvailability(ctx, "zone-001")
+	if err := repo.SetZoneAvailability(ctx, "zone-001", 99); err != nil {
+		t.Fatalf("SetZoneAvailability() error = %v", err)
+	}
+
+	seats, _ := repo.GetZoneAvailability(ctx, "zone-001")
+	if seats != 99 {
+		t.Errorf("GetZoneAvailability() = %d after set, want 99", seats)
+	}
+}
+
+func TestCoalescingReservationRepository_ErrorsAreNotCached(t *testing.T) {
+	inner := &countingAvailabilityRepo{err: errors.New("redis down")}
+	repo := NewCoalescingReservationRepository(inner, time.Minute)
+	ctx := context.Background()
+
+	if _, err := repo.GetZoneAvailability(ctx, "zone-001"); err == nil {
+		t.Fatal("Expected error from the underlying store")
+	}
+
+	inner.err = nil
+	inner.seats = 7
+	seats, err := repo.GetZoneAvailability(ctx, "zone-001")
+	if err != nil || seats != 7 {
+		t.Errorf("GetZoneAvailability() = %d, %v; want 7, nil", seats, err)
+	}
+}
+
+func TestCoalescingReservationRepository_CacheIsBounded(t *testing.T) {
+	inner := &countingAvailabilityRepo{seats: 5}
+	repo := NewCoalescingReservationRepository(inner, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < MaxAvailabilityCacheEntries+10; i++ {
+		repo.GetZoneAvailability(ctx, fmt.Sprintf("zone-%d", i))
+	}
+	if n := len(repo.cache); n != MaxAvailabilityCacheEntries {
+		t.Errorf("Expected the cache to hold %d zones, got %d", MaxAvailabilityCacheEntries, n)
+	}
+
+	// Zones past the bound are still read, just not cached
+	reads := inner.reads.Load()
+	repo.GetZoneAvailability(ctx, fmt.Sprintf("zone-%d", MaxAvailabilityCacheEntries+5))
+	if n := inner.reads.Load(); n != reads+1 {
+		t.Errorf("Expected an uncached read, got %d reads", n-reads)
+	}
+}
@@ -334,7 +334,7 @@ func (r *PostgresBookingRepository) Delete(ctx context.Context, id string) error
 }
 
 // Confirm confirms a booking with payment info
-func (r *PostgresBookingRepository) Confirm(ctx context.Context, id, paymentID string) error {
+func (r *PostgresBookingRepository) Confirm(ctx context.Context, id, paymentID, confirmationCode string) error {
 	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.booking.confirm")
 	defer span.End()
 
@@ -348,12 +348,13 @@ func (r *PostgresBookingRepository) Confirm(ctx context.Context, id, paymentID s
 			status = $2,
 			payment_id = $3,
 			confirmed_at = $4,
-			updated_at = $5
+			updated_at = $5,
+			confirmation_code = $6
 		WHERE id = $1 AND status = 'reserved'
 	`
 
 	now := time.Now()
-	result, err := r.pool.Exec(ctx, query, id, domain.BookingStatusConfirmed.String(), paymentID, now, now)
+	result, err := r.pool.Exec(ctx, query, id, domain.BookingStatusConfirmed.String(), paymentID, now, now, confirmationCode)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -648,6 +649,25 @@ func (r *PostgresBookingRepository) CountByUserAndEvent(ctx context.Context, use
 	return count, nil
 }
 
+// ConfirmationCodeExists reports whether any booking already carries the
+// confirmation code, so code generators can retry on a collision
+func (r *PostgresBookingRepository) ConfirmationCodeExists(ctx context.Context, code string) (bool, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.booking.confirmation_code_exists")
+	defer span.End()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM bookings WHERE confirmation_code = $1)", code).Scan(&exists)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, fmt.Errorf("failed to check confirmation code: %w", err)
+	}
+
+	span.SetAttributes(attribute.Bool("exists", exists))
+	span.SetStatus(codes.Ok, "")
+	return exists, nil
+}
+
 // scanBooking scans a row into a Booking struct
 func scanBooking(rows pgx.Rows) (*domain.Booking, error) {
 	booking := &domain.Booking{}
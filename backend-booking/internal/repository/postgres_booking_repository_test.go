@@ -220,7 +220,7 @@ func TestPostgresBookingRepository_Confirm(t *testing.T) {
 	bookingID := "existing-reserved-booking-id"
 	paymentID := "payment-" + uuid.New().String()
 
-	err := repo.Confirm(ctx, bookingID, paymentID)
+	err := repo.Confirm(ctx, bookingID, paymentID, "7KQ2M9XD")
 	if err != nil {
 		t.Fatalf("Confirm() error = %v", err)
 	}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 )
 
 // JoinQueueResult represents the result of joining a queue
@@ -14,13 +15,30 @@ type JoinQueueResult struct {
 	ErrorMessage string
 }
 
+// LeaveQueueResult represents the result of leaving a queue. Both fields are
+// read in the same atomic step that removes the user.
+type LeaveQueueResult struct {
+	PreviousPosition int64 // 1-indexed position the user held when leaving
+	QueueSizeAfter   int64 // Users still waiting after the user left
+}
+
 // QueuePositionResult represents the result of getting queue position
 type QueuePositionResult struct {
-	Position     int64
-	TotalInQueue int64
+	Position     int64 // 1-indexed rank among users still waiting (0 if not in queue)
+	TotalInQueue int64 // Users still waiting, reported whether or not the user is in queue
 	IsInQueue    bool
 }
 
+// IssuedPass is a queue pass issued to a user promoted from the queue
+type IssuedPass struct {
+	UserID    string
+	QueuePass string
+	ExpiresAt time.Time
+}
+
+// PassSigner creates the queue pass for a user about to be promoted
+type PassSigner func(userID string) (queuePass string, expiresAt time.Time, err error)
+
 // QueueRepository defines the interface for Redis-based queue operations
 type QueueRepository interface {
 	// JoinQueue adds a user to the queue using Sorted Set
@@ -29,8 +47,9 @@ type QueueRepository interface {
 	// GetPosition gets the user's current position in queue
 	GetPosition(ctx context.Context, eventID, userID string) (*QueuePositionResult, error)
 
-	// LeaveQueue removes a user from the queue
-	LeaveQueue(ctx context.Context, eventID, userID, token string) error
+	// LeaveQueue removes a user from the queue, reporting the position they
+	// held and the queue size after they left
+	LeaveQueue(ctx context.Context, eventID, userID, token string) (*LeaveQueueResult, error)
 
 	// GetQueueSize gets the total number of users in queue for an event
 	GetQueueSize(ctx context.Context, eventID string) (int64, error)
@@ -53,6 +72,10 @@ type QueueRepository interface {
 	// PopUsersFromQueue pops the first N users from the queue (for batch release)
 	PopUsersFromQueue(ctx context.Context, eventID string, count int64) ([]string, error)
 
+	// IssuePasses atomically promotes up to count users from the front of the
+	// queue, storing a queue pass created by sign for each with the given TTL
+	IssuePasses(ctx context.Context, eventID string, count int64, ttl time.Duration, sign PassSigner) ([]IssuedPass, error)
+
 	// GetAllQueueEventIDs returns all event IDs that have active queues
 	GetAllQueueEventIDs(ctx context.Context) ([]string, error)
 
@@ -73,6 +96,8 @@ type QueueRepository interface {
 type EventQueueConfig struct {
 	MaxConcurrentBookings int `json:"max_concurrent_bookings"`
 	QueuePassTTLMinutes   int `json:"queue_pass_ttl_minutes"`
+	// SingleUsePass makes each queue pass valid for one successful reservation
+	SingleUsePass bool `json:"single_use_pass,omitempty"`
 }
 
 // JoinQueueParams contains parameters for joining a queue
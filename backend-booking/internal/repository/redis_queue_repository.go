@@ -3,12 +3,14 @@ package repository
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -16,8 +18,22 @@ import (
 //go:embed scripts/join_queue.lua
 var joinQueueScript string
 
-// Script name for caching
-const scriptJoinQueue = "join_queue"
+//go:embed scripts/get_queue_position.lua
+var getQueuePositionScript string
+
+//go:embed scripts/issue_passes.lua
+var issuePassesScript string
+
+//go:embed scripts/leave_queue.lua
+var leaveQueueScript string
+
+// Script names for caching
+const (
+	scriptJoinQueue        = "join_queue"
+	scriptGetQueuePosition = "get_queue_position"
+	scriptIssuePasses      = "issue_passes"
+	scriptLeaveQueue       = "leave_queue"
+)
 
 // RedisQueueRepository implements QueueRepository using Redis
 type RedisQueueRepository struct {
@@ -29,19 +45,16 @@ func NewRedisQueueRepository(client *pkgredis.Client) *RedisQueueRepository {
 	return &RedisQueueRepository{client: client}
 }
 
-// LoadScripts loads all queue Lua scripts into Redis
+// LoadScripts loads all queue Lua scripts into Redis, reporting every script that failed to load
 func (r *RedisQueueRepository) LoadScripts(ctx context.Context) error {
 	scripts := map[string]string{
-		scriptJoinQueue: joinQueueScript,
-	}
-
-	for name, script := range scripts {
-		if _, err := r.client.LoadScript(ctx, name, script); err != nil {
-			return fmt.Errorf("failed to load script %s: %w", name, err)
-		}
+		scriptJoinQueue:        joinQueueScript,
+		scriptGetQueuePosition: getQueuePositionScript,
+		scriptIssuePasses:      issuePassesScript,
+		scriptLeaveQueue:       leaveQueueScript,
 	}
 
-	return nil
+	return r.client.PreloadScripts(ctx, scripts)
 }
 
 // JoinQueue adds a user to the queue using Sorted Set
@@ -57,8 +70,9 @@ func (r *RedisQueueRepository) JoinQueue(ctx context.Context, params JoinQueuePa
 	// Build Redis keys
 	queueKey := fmt.Sprintf("queue:%s", params.EventID)
 	userQueueKey := fmt.Sprintf("queue:user:%s:%s", params.EventID, params.UserID)
+	capacityKey := fmt.Sprintf("queue:capacity:%s", params.EventID)
 
-	keys := []string{queueKey, userQueueKey}
+	keys := []string{queueKey, userQueueKey, capacityKey}
 	args := []interface{}{
 		params.UserID,       // ARGV[1]: user_id
 		params.EventID,      // ARGV[2]: event_id
@@ -129,45 +143,56 @@ func (r *RedisQueueRepository) GetPosition(ctx context.Context, eventID, userID
 
 	queueKey := fmt.Sprintf("queue:%s", eventID)
 
-	// Get user's rank in sorted set (0-indexed)
-	rank, err := r.client.ZRank(ctx, queueKey, userID).Result()
-	if err != nil {
-		// User not in queue
-		if err.Error() == "redis: nil" {
-			span.SetStatus(codes.Ok, "not in queue")
-			return &QueuePositionResult{
-				Position:     0,
-				TotalInQueue: 0,
-				IsInQueue:    false,
-			}, nil
-		}
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to get queue position: %w", err)
+	// Rank and size are read atomically so the position always reflects
+	// users who left or were released before this call
+	result := r.client.EvalWithFallback(ctx, scriptGetQueuePosition, getQueuePositionScript, []string{queueKey}, userID)
+	if result.Err() != nil {
+		span.RecordError(result.Err())
+		span.SetStatus(codes.Error, result.Err().Error())
+		return nil, fmt.Errorf("failed to get queue position: %w", result.Err())
 	}
 
-	// Get total count
-	total, err := r.client.ZCard(ctx, queueKey).Result()
+	values, err := result.Slice()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to get queue size: %w", err)
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
+	}
+
+	if len(values) < 3 {
+		span.SetStatus(codes.Error, "unexpected result length")
+		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
 	}
 
+	inQueue, _ := toInt64(values[0])
+	position, _ := toInt64(values[1])
+	total, _ := toInt64(values[2])
+
 	span.SetAttributes(
-		attribute.Int64("position", rank+1),
+		attribute.Int64("position", position),
 		attribute.Int64("total_in_queue", total),
 	)
+	if inQueue != 1 {
+		span.SetStatus(codes.Ok, "not in queue")
+		return &QueuePositionResult{
+			Position:     0,
+			TotalInQueue: total,
+			IsInQueue:    false,
+		}, nil
+	}
+
 	span.SetStatus(codes.Ok, "")
 	return &QueuePositionResult{
-		Position:     rank + 1, // Convert to 1-indexed
+		Position:     position,
 		TotalInQueue: total,
 		IsInQueue:    true,
 	}, nil
 }
 
-// LeaveQueue removes a user from the queue
-func (r *RedisQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) error {
+// LeaveQueue removes a user from the queue. The token check, removal and the
+// reported position and queue size happen in one script, so a concurrent
+// join, leave or release cannot slip between them.
+func (r *RedisQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) (*LeaveQueueResult, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.queue.leave")
 	defer span.End()
 
@@ -176,43 +201,56 @@ func (r *RedisQueueRepository) LeaveQueue(ctx context.Context, eventID, userID,
 		attribute.String("user_id", userID),
 	)
 
-	// First verify the token
+	queueKey := fmt.Sprintf("queue:%s", eventID)
 	userQueueKey := fmt.Sprintf("queue:user:%s:%s", eventID, userID)
-	storedToken, err := r.client.HGet(ctx, userQueueKey, "token").Result()
+
+	result := r.client.EvalWithFallback(ctx, scriptLeaveQueue, leaveQueueScript, []string{queueKey, userQueueKey}, userID, token)
+	if result.Err() != nil {
+		span.RecordError(result.Err())
+		span.SetStatus(codes.Error, result.Err().Error())
+		return nil, fmt.Errorf("failed to execute leave_queue script: %w", result.Err())
+	}
+
+	parsed, err := parseScriptReply(result.Val())
 	if err != nil {
-		if err.Error() == "redis: nil" {
-			span.SetStatus(codes.Error, "not in queue")
-			return domain.ErrNotInQueue
-		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return fmt.Errorf("failed to get user queue info: %w", err)
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
 
-	if storedToken != token {
-		span.SetStatus(codes.Error, "invalid token")
-		return domain.ErrInvalidQueueToken
+	if !parsed.ok {
+		errorCode := parsed.errorCode()
+		span.SetStatus(codes.Error, errorCode)
+		switch errorCode {
+		case pkgredis.CodeInvalidQueueToken:
+			return nil, domain.ErrInvalidQueueToken
+		case pkgredis.CodeNotInQueue:
+			return nil, domain.ErrNotInQueue
+		default:
+			return nil, fmt.Errorf("leave_queue failed: %s: %s", errorCode, parsed.errorMessage())
+		}
 	}
 
-	// Remove from sorted set
-	queueKey := fmt.Sprintf("queue:%s", eventID)
-	removed, err := r.client.ZRem(ctx, queueKey, userID).Result()
+	previousPosition, err := parsed.int(1)
 	if err != nil {
-		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return fmt.Errorf("failed to remove from queue: %w", err)
+		return nil, err
 	}
-
-	if removed == 0 {
-		span.SetStatus(codes.Error, "not in queue")
-		return domain.ErrNotInQueue
+	queueSizeAfter, err := parsed.int(2)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	// Remove user queue info
-	r.client.Del(ctx, userQueueKey)
-
+	span.SetAttributes(
+		attribute.Int64("previous_position", previousPosition),
+		attribute.Int64("queue_size_after", queueSizeAfter),
+	)
 	span.SetStatus(codes.Ok, "")
-	return nil
+	return &LeaveQueueResult{
+		PreviousPosition: previousPosition,
+		QueueSizeAfter:   queueSizeAfter,
+	}, nil
 }
 
 // GetQueueSize gets the total number of users in queue for an event
@@ -245,6 +283,14 @@ func (r *RedisQueueRepository) GetUserQueueInfo(ctx context.Context, eventID, us
 	return result, nil
 }
 
+// boolToFlag encodes a bool as the "1"/"0" stored in Redis hashes
+func boolToFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
 // Helper function to convert interface{} to float64
 func toFloat64(v interface{}) (float64, bool) {
 	switch val := v.(type) {
@@ -343,6 +389,77 @@ func (r *RedisQueueRepository) PopUsersFromQueue(ctx context.Context, eventID st
 	return result, nil
 }
 
+// IssuePasses promotes up to count users from the front of an event queue,
+// earliest joined first. sign is called for each candidate to create its queue
+// pass; the promotion itself runs in a single script, so each promoted user is
+// removed from the queue and has its pass stored with ttl together, and a user
+// promoted concurrently by another worker or who left is skipped.
+func (r *RedisQueueRepository) IssuePasses(ctx context.Context, eventID string, count int64, ttl time.Duration, sign PassSigner) ([]IssuedPass, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.queue.issue_passes")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", eventID),
+		attribute.Int64("count", count),
+	)
+
+	if count <= 0 {
+		return []IssuedPass{}, nil
+	}
+	ttlSeconds := int64(ttl.Seconds())
+	if ttlSeconds <= 0 {
+		return nil, fmt.Errorf("invalid queue pass ttl: %v", ttl)
+	}
+
+	queueKey := fmt.Sprintf("queue:%s", eventID)
+	candidates, err := r.client.ZRange(ctx, queueKey, 0, count-1).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get users from queue: %w", err)
+	}
+	if len(candidates) == 0 {
+		span.SetStatus(codes.Ok, "queue empty")
+		return []IssuedPass{}, nil
+	}
+
+	signed := make(map[string]IssuedPass, len(candidates))
+	keys := make([]string, 0, 1+2*len(candidates))
+	args := make([]interface{}, 0, 1+2*len(candidates))
+	keys = append(keys, queueKey)
+	args = append(args, ttlSeconds)
+	for _, userID := range candidates {
+		queuePass, expiresAt, err := sign(userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to sign queue pass for user %s: %w", userID, err)
+		}
+		signed[userID] = IssuedPass{UserID: userID, QueuePass: queuePass, ExpiresAt: expiresAt}
+		keys = append(keys,
+			fmt.Sprintf("queue:user:%s:%s", eventID, userID),
+			fmt.Sprintf("queue:pass:%s:%s", eventID, userID),
+		)
+		args = append(args, userID, queuePass)
+	}
+
+	promoted, err := r.client.EvalWithFallback(ctx, scriptIssuePasses, issuePassesScript, keys, args...).StringSlice()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to issue queue passes: %w", err)
+	}
+
+	issued := make([]IssuedPass, 0, len(promoted))
+	for _, userID := range promoted {
+		issued = append(issued, signed[userID])
+	}
+
+	span.SetAttributes(attribute.Int("issued", len(issued)))
+	span.SetStatus(codes.Ok, "")
+	return issued, nil
+}
+
 // GetAllQueueEventIDs returns all event IDs that have active queues
 func (r *RedisQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	// Scan for all queue keys matching pattern "queue:*"
@@ -447,6 +564,7 @@ func (r *RedisQueueRepository) GetEventQueueConfig(ctx context.Context, eventID
 	if val, ok := result["queue_pass_ttl_minutes"]; ok {
 		fmt.Sscanf(val, "%d", &config.QueuePassTTLMinutes)
 	}
+	config.SingleUsePass = result["single_use_pass"] == "1"
 
 	return config, nil
 }
@@ -457,6 +575,7 @@ func (r *RedisQueueRepository) SetEventQueueConfig(ctx context.Context, eventID
 	err := r.client.HSet(ctx, key,
 		"max_concurrent_bookings", config.MaxConcurrentBookings,
 		"queue_pass_ttl_minutes", config.QueuePassTTLMinutes,
+		"single_use_pass", boolToFlag(config.SingleUsePass),
 	).Err()
 	if err != nil {
 		return fmt.Errorf("failed to set event queue config: %w", err)
@@ -464,5 +583,39 @@ func (r *RedisQueueRepository) SetEventQueueConfig(ctx context.Context, eventID
 	return nil
 }
 
+// SetQueueCapacity caps how many users may wait in an event's queue. JoinQueue
+// enforces it atomically in place of the default MaxQueueSize; a capacity of 0
+// removes the cap so the default applies again.
+func (r *RedisQueueRepository) SetQueueCapacity(ctx context.Context, eventID string, capacity int64) error {
+	if capacity < 0 {
+		return fmt.Errorf("invalid queue capacity for event %s: %d", eventID, capacity)
+	}
+
+	key := fmt.Sprintf("queue:capacity:%s", eventID)
+	var err error
+	if capacity == 0 {
+		err = r.client.Del(ctx, key).Err()
+	} else {
+		err = r.client.Set(ctx, key, capacity, 0).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set queue capacity: %w", err)
+	}
+	return nil
+}
+
+// GetQueueCapacity gets the per-event queue capacity (0 = not set)
+func (r *RedisQueueRepository) GetQueueCapacity(ctx context.Context, eventID string) (int64, error) {
+	key := fmt.Sprintf("queue:capacity:%s", eventID)
+	capacity, err := r.client.Get(ctx, key).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get queue capacity: %w", err)
+	}
+	return capacity, nil
+}
+
 // Ensure RedisQueueRepository implements QueueRepository
 var _ QueueRepository = (*RedisQueueRepository)(nil)
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRedisQueueRepository_JoinQueue_Capacity(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisQueueRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-capacity"
+	if err := repo.SetQueueCapacity(ctx, eventID, 3); err != nil {
+		t.Fatalf("SetQueueCapacity() error = %v", err)
+	}
+
+	join := func(userID string, maxQueueSize int64) *JoinQueueResult {
+		t.Helper()
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:       userID,
+			EventID:      eventID,
+			Token:        "token-" + userID,
+			TTLSeconds:   1800,
+			MaxQueueSize: maxQueueSize,
+		})
+		if err != nil {
+			t.Fatalf("JoinQueue() error = %v", err)
+		}
+		return result
+	}
+
+	// Users fill the queue in order; the per-event capacity overrides the larger default
+	for i := 1; i <= 3; i++ {
+		result := join(fmt.Sprintf("user-%d", i), 100)
+		if !result.Success {
+			t.Fatalf("JoinQueue() user-%d failed: %s - %s", i, result.ErrorCode, result.ErrorMessage)
+		}
+		if result.Position != int64(i) {
+			t.Errorf("JoinQueue() user-%d position = %d, want %d", i, result.Position, i)
+		}
+		if result.TotalInQueue != int64(i) {
+			t.Errorf("JoinQueue() user-%d totalInQueue = %d, want %d", i, result.TotalInQueue, i)
+		}
+	}
+
+	// At capacity the next user is rejected and not added
+	result := join("user-4", 100)
+	if result.Success {
+		t.Fatal("JoinQueue() should be rejected at capacity")
+	}
+	if result.ErrorCode != "QUEUE_FULL" {
+		t.Errorf("JoinQueue() errorCode = %v, want QUEUE_FULL", result.ErrorCode)
+	}
+	size, err := repo.GetQueueSize(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetQueueSize() error = %v", err)
+	}
+	if size != 3 {
+		t.Errorf("GetQueueSize() = %d, want 3", size)
+	}
+
+	// A user leaving frees a slot
+	if err := repo.RemoveUserFromQueue(ctx, eventID, "user-1"); err != nil {
+		t.Fatalf("RemoveUserFromQueue() error = %v", err)
+	}
+	result = join("user-4", 100)
+	if !result.Success {
+		t.Fatalf("JoinQueue() after a slot freed failed: %s", result.ErrorCode)
+	}
+	if result.Position != 3 {
+		t.Errorf("JoinQueue() user-4 position = %d, want 3", result.Position)
+	}
+
+	// Removing the capacity falls back to the default limit
+	if err := repo.SetQueueCapacity(ctx, eventID, 0); err != nil {
+		t.Fatalf("SetQueueCapacity() error = %v", err)
+	}
+	if capacity, _ := repo.GetQueueCapacity(ctx, eventID); capacity != 0 {
+		t.Errorf("GetQueueCapacity() = %d, want 0 after clearing", capacity)
+	}
+	if result := join("user-5", 0); !result.Success {
+		t.Errorf("JoinQueue() without a cap failed: %s", result.ErrorCode)
+	}
+	if result := join("user-6", 4); result.Success || result.ErrorCode != "QUEUE_FULL" {
+		t.Errorf("JoinQueue() with the default limit reached = %+v, want QUEUE_FULL", result)
+	}
+}
+
+func TestRedisQueueRepository_JoinQueue_ConcurrentAdmission(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisQueueRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-capacity-rush"
+	const capacity = 10
+	if err := repo.SetQueueCapacity(ctx, eventID, capacity); err != nil {
+		t.Fatalf("SetQueueCapacity() error = %v", err)
+	}
+
+	// 50 users rush the queue at once
+	type outcome struct {
+		position int64
+		admitted bool
+	}
+	results := make(chan outcome, 50)
+	for i := 0; i < 50; i++ {
+		go func(i int) {
+			result, err := repo.JoinQueue(ctx, JoinQueueParams{
+				UserID:     fmt.Sprintf("user-%d", i),
+				EventID:    eventID,
+				Token:      fmt.Sprintf("token-%d", i),
+				TTLSeconds: 1800,
+			})
+			if err != nil {
+				t.Errorf("JoinQueue() error = %v", err)
+				results <- outcome{}
+				return
+			}
+			results <- outcome{position: result.Position, admitted: result.Success}
+		}(i)
+	}
+
+	positions := make(map[int64]bool)
+	for i := 0; i < 50; i++ {
+		o := <-results
+		if !o.admitted {
+			continue
+		}
+		if positions[o.position] {
+			t.Errorf("position %d assigned twice", o.position)
+		}
+		positions[o.position] = true
+	}
+
+	if len(positions) != capacity {
+		t.Errorf("admitted %d users, want %d", len(positions), capacity)
+	}
+	for p := int64(1); p <= capacity; p++ {
+		if !positions[p] {
+			t.Errorf("position %d was never assigned", p)
+		}
+	}
+}
+
+func TestRedisQueueRepository_GetPosition_AfterLeave(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisQueueRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-positions"
+	users := []string{"user-1", "user-2", "user-3", "user-4", "user-5"}
+	for _, userID := range users {
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:     userID,
+			EventID:    eventID,
+			Token:      "token-" + userID,
+			TTLSeconds: 1800,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("JoinQueue(%s) failed: %v", userID, err)
+		}
+	}
+
+	assertPositions := func(want map[string]int64, wantTotal int64) {
+		t.Helper()
+		for userID, wantPosition := range want {
+			result, err := repo.GetPosition(ctx, eventID, userID)
+			if err != nil {
+				t.Fatalf("GetPosition(%s) error = %v", userID, err)
+			}
+			if !result.IsInQueue {
+				t.Errorf("GetPosition(%s) isInQueue = false, want true", userID)
+			}
+			if result.Position != wantPosition {
+				t.Errorf("GetPosition(%s) position = %d, want %d", userID, result.Position, wantPosition)
+			}
+			if result.TotalInQueue != wantTotal {
+				t.Errorf("GetPosition(%s) totalInQueue = %d, want %d", userID, result.TotalInQueue, wantTotal)
+			}
+		}
+	}
+
+	assertPositions(map[string]int64{"user-1": 1, "user-2": 2, "user-3": 3, "user-4": 4, "user-5": 5}, 5)
+
+	// Leaving from the middle moves everyone behind forward by one
+	left, err := repo.LeaveQueue(ctx, eventID, "user-3", "token-user-3")
+	if err != nil {
+		t.Fatalf("LeaveQueue() error = %v", err)
+	}
+	if left.PreviousPosition != 3 || left.QueueSizeAfter != 4 {
+		t.Errorf("LeaveQueue() = %+v, want previous position 3 and queue size 4", left)
+	}
+	assertPositions(map[string]int64{"user-1": 1, "user-2": 2, "user-4": 3, "user-5": 4}, 4)
+
+	// The leaver is out of the queue but still sees the total
+	result, err := repo.GetPosition(ctx, eventID, "user-3")
+	if err != nil {
+		t.Fatalf("GetPosition() error = %v", err)
+	}
+	if result.IsInQueue || result.Position != 0 {
+		t.Errorf("GetPosition(user-3) = %+v, want not in queue", result)
+	}
+	if result.TotalInQueue != 4 {
+		t.Errorf("GetPosition(user-3) totalInQueue = %d, want 4", result.TotalInQueue)
+	}
+
+	// Leaving from the head shifts the whole queue
+	if _, err := repo.LeaveQueue(ctx, eventID, "user-1", "token-user-1"); err != nil {
+		t.Fatalf("LeaveQueue() error = %v", err)
+	}
+	assertPositions(map[string]int64{"user-2": 1, "user-4": 2, "user-5": 3}, 3)
+}
+
+func TestRedisQueueRepository_IssuePasses(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisQueueRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-issue-passes"
+	for i := 1; i <= 5; i++ {
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:     fmt.Sprintf("user-%d", i),
+			EventID:    eventID,
+			Token:      fmt.Sprintf("token-%d", i),
+			TTLSeconds: 1800,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("JoinQueue() user-%d failed: %v %+v", i, err, result)
+		}
+	}
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+	sign := func(userID string) (string, time.Time, error) {
+		return "pass-" + userID, expiresAt, nil
+	}
+
+	// Exactly N users are promoted, earliest joined first
+	issued, err := repo.IssuePasses(ctx, eventID, 3, 5*time.Minute, sign)
+	if err != nil {
+		t.Fatalf("IssuePasses() error = %v", err)
+	}
+	if len(issued) != 3 {
+		t.Fatalf("IssuePasses() issued %d passes, want 3", len(issued))
+	}
+	for i, pass := range issued {
+		wantUser := fmt.Sprintf("user-%d", i+1)
+		if pass.UserID != wantUser {
+			t.Errorf("IssuePasses()[%d].UserID = %s, want %s", i, pass.UserID, wantUser)
+		}
+		if pass.QueuePass != "pass-"+wantUser {
+			t.Errorf("IssuePasses()[%d].QueuePass = %s, want pass-%s", i, pass.QueuePass, wantUser)
+		}
+		if !pass.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("IssuePasses()[%d].ExpiresAt = %v, want %v", i, pass.ExpiresAt, expiresAt)
+		}
+
+		stored, err := repo.GetQueuePass(ctx, eventID, wantUser)
+		if err != nil {
+			t.Fatalf("GetQueuePass() error = %v", err)
+		}
+		if stored != pass.QueuePass {
+			t.Errorf("GetQueuePass(%s) = %q, want %q", wantUser, stored, pass.QueuePass)
+		}
+		ttl, err := client.TTL(ctx, fmt.Sprintf("queue:pass:%s:%s", eventID, wantUser)).Result()
+		if err != nil {
+			t.Fatalf("TTL() error = %v", err)
+		}
+		if ttl <= 0 || ttl > 5*time.Minute {
+			t.Errorf("queue pass TTL for %s = %v, want (0, 5m]", wantUser, ttl)
+		}
+
+		position, err := repo.GetPosition(ctx, eventID, wantUser)
+		if err != nil {
+			t.Fatalf("GetPosition() error = %v", err)
+		}
+		if position.IsInQueue {
+			t.Errorf("%s should no longer be in the queue", wantUser)
+		}
+		info, err := repo.GetUserQueueInfo(ctx, eventID, wantUser)
+		if err != nil {
+			t.Fatalf("GetUserQueueInfo() error = %v", err)
+		}
+		if len(info) != 0 {
+			t.Errorf("queue info for %s should be deleted, got %v", wantUser, info)
+		}
+	}
+
+	// The rest of the queue keeps its order
+	position, err := repo.GetPosition(ctx, eventID, "user-4")
+	if err != nil {
+		t.Fatalf("GetPosition() error = %v", err)
+	}
+	if position.Position != 1 || position.TotalInQueue != 2 {
+		t.Errorf("GetPosition(user-4) = %+v, want position 1 of 2", position)
+	}
+	if pass, _ := repo.GetQueuePass(ctx, eventID, "user-4"); pass != "" {
+		t.Errorf("user-4 should not have a queue pass, got %q", pass)
+	}
+
+	// Asking for more than remain promotes only the users still waiting
+	issued, err = repo.IssuePasses(ctx, eventID, 10, 5*time.Minute, sign)
+	if err != nil {
+		t.Fatalf("IssuePasses() error = %v", err)
+	}
+	if len(issued) != 2 || issued[0].UserID != "user-4" || issued[1].UserID != "user-5" {
+		t.Errorf("IssuePasses() = %+v, want user-4 then user-5", issued)
+	}
+
+	issued, err = repo.IssuePasses(ctx, eventID, 3, 5*time.Minute, sign)
+	if err != nil {
+		t.Fatalf("IssuePasses() error = %v", err)
+	}
+	if len(issued) != 0 {
+		t.Errorf("IssuePasses() on an empty queue issued %d passes", len(issued))
+	}
+}
+
+func TestRedisQueueRepository_IssuePasses_SkipsUsersWhoLeft(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisQueueRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-issue-passes-left"
+	for i := 1; i <= 3; i++ {
+		if _, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:     fmt.Sprintf("user-%d", i),
+			EventID:    eventID,
+			Token:      fmt.Sprintf("token-%d", i),
+			TTLSeconds: 1800,
+		}); err != nil {
+			t.Fatalf("JoinQueue() error = %v", err)
+		}
+	}
+
+	// user-2 leaves after being picked as a candidate but before promotion
+	sign := func(userID string) (string, time.Time, error) {
+		if userID == "user-2" {
+			if err := repo.RemoveUserFromQueue(ctx, eventID, userID); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+		return "pass-" + userID, time.Now().Add(time.Minute), nil
+	}
+
+	issued, err := repo.IssuePasses(ctx, eventID, 3, time.Minute, sign)
+	if err != nil {
+		t.Fatalf("IssuePasses() error = %v", err)
+	}
+	if len(issued) != 2 || issued[0].UserID != "user-1" || issued[1].UserID != "user-3" {
+		t.Errorf("IssuePasses() = %+v, want user-1 then user-3", issued)
+	}
+	if pass, _ := repo.GetQueuePass(ctx, eventID, "user-2"); pass != "" {
+		t.Errorf("user-2 left the queue and should not have a queue pass, got %q", pass)
+	}
+}
@@ -3,12 +3,17 @@ package repository
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -22,13 +27,51 @@ var releaseSeatsScript string
 //go:embed scripts/confirm_booking.lua
 var confirmBookingScript string
 
+//go:embed scripts/init_zone.lua
+var initZoneScript string
+
+//go:embed scripts/reserve_specific_seats.lua
+var reserveSpecificSeatsScript string
+
+//go:embed scripts/release_specific_seats.lua
+var releaseSpecificSeatsScript string
+
+//go:embed scripts/restore_expired_hold.lua
+var restoreExpiredHoldScript string
+
+//go:embed scripts/set_zone_seats.lua
+var setZoneSeatsScript string
+
 // Script names for caching
 const (
 	scriptReserveSeats   = "reserve_seats"
 	scriptReleaseSeats   = "release_seats"
 	scriptConfirmBooking = "confirm_booking"
+	scriptInitZone       = "init_zone"
+
+	scriptReserveSpecificSeats = "reserve_specific_seats"
+	scriptReleaseSpecificSeats = "release_specific_seats"
+	scriptRestoreExpiredHold   = "restore_expired_hold"
+	scriptSetZoneSeats         = "set_zone_seats"
 )
 
+// expiryIndexKey is the sorted set of holds by expiry time, read by the expiry sweeper
+const expiryIndexKey = "reservations:expiry"
+
+// ZoneShowKey is the key recording which show a zone belongs to. Whoever seeds
+// a zone's availability seeds it too, so reserve can reject a show_id the zone
+// doesn't belong to.
+func ZoneShowKey(zoneID string) string {
+	return fmt.Sprintf("zone:show:%s", zoneID)
+}
+
+// showHeld reports whether a hold's seats were taken from its show's counter
+// and must be returned to it. Holds from before show_counted was recorded
+// were always counted.
+func showHeld(showID, showCounted string) bool {
+	return showID != "" && showCounted != "0"
+}
+
 // RedisReservationRepository implements ReservationRepository using Redis
 type RedisReservationRepository struct {
 	client *pkgredis.Client
@@ -39,21 +82,21 @@ func NewRedisReservationRepository(client *pkgredis.Client) *RedisReservationRep
 	return &RedisReservationRepository{client: client}
 }
 
-// LoadScripts loads all Lua scripts into Redis
+// LoadScripts loads all Lua scripts into Redis, reporting every script that failed to load
 func (r *RedisReservationRepository) LoadScripts(ctx context.Context) error {
 	scripts := map[string]string{
 		scriptReserveSeats:   reserveSeatsScript,
 		scriptReleaseSeats:   releaseSeatsScript,
 		scriptConfirmBooking: confirmBookingScript,
-	}
+		scriptInitZone:       initZoneScript,
 
-	for name, script := range scripts {
-		if _, err := r.client.LoadScript(ctx, name, script); err != nil {
-			return fmt.Errorf("failed to load script %s: %w", name, err)
-		}
+		scriptReserveSpecificSeats: reserveSpecificSeatsScript,
+		scriptReleaseSpecificSeats: releaseSpecificSeatsScript,
+		scriptRestoreExpiredHold:   restoreExpiredHoldScript,
+		scriptSetZoneSeats:         setZoneSeatsScript,
 	}
 
-	return nil
+	return r.client.PreloadScripts(ctx, scripts)
 }
 
 // ReserveSeats atomically reserves seats using Lua script
@@ -66,27 +109,54 @@ func (r *RedisReservationRepository) ReserveSeats(ctx context.Context, params Re
 		attribute.String("user_id", params.UserID),
 		attribute.String("event_id", params.EventID),
 		attribute.Int("quantity", params.Quantity),
+		attribute.Bool("check_only", params.CheckOnly),
+		attribute.Bool("single_use_pass", params.QueuePass != ""),
 	)
 
 	// Generate booking ID if not provided
-	bookingID := uuid.New().String()
+	bookingID := params.BookingID
+	if bookingID == "" {
+		bookingID = uuid.New().String()
+	}
 
 	// Build Redis keys
 	zoneAvailabilityKey := fmt.Sprintf("zone:availability:%s", params.ZoneID)
 	userReservationsKey := fmt.Sprintf("user:reservations:%s:%s", params.UserID, params.EventID)
 	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
 
-	keys := []string{zoneAvailabilityKey, userReservationsKey, reservationKey}
+	checkOnlyArg := "0"
+	if params.CheckOnly {
+		checkOnlyArg = "1"
+	}
+
+	keys := []string{
+		zoneAvailabilityKey,
+		userReservationsKey,
+		reservationKey,
+		fmt.Sprintf("event:zones:%s", params.EventID),
+		fmt.Sprintf("event:holds:%s", params.EventID),
+		fmt.Sprintf("hold:shadow:%s", bookingID),
+		expiryIndexKey,
+	}
+	if params.ShowID != "" {
+		keys = append(keys, fmt.Sprintf("show:availability:%s", params.ShowID), ZoneShowKey(params.ZoneID))
+	}
+	if params.QueuePass != "" {
+		keys = append(keys, fmt.Sprintf("queue:pass:%s:%s", params.EventID, params.UserID))
+	}
 	args := []interface{}{
-		params.Quantity,    // ARGV[1]: quantity
-		params.MaxPerUser,  // ARGV[2]: max_per_user
-		params.UserID,      // ARGV[3]: user_id
-		bookingID,          // ARGV[4]: booking_id
-		params.ZoneID,      // ARGV[5]: zone_id
-		params.EventID,     // ARGV[6]: event_id
-		"",                 // ARGV[7]: show_id (optional)
-		params.Price,       // ARGV[8]: unit_price
-		params.TTLSeconds,  // ARGV[9]: ttl_seconds
+		params.Quantity,          // ARGV[1]: quantity
+		params.MaxPerUser,        // ARGV[2]: max_per_user
+		params.UserID,            // ARGV[3]: user_id
+		bookingID,                // ARGV[4]: booking_id
+		params.ZoneID,            // ARGV[5]: zone_id
+		params.EventID,           // ARGV[6]: event_id
+		params.ShowID,            // ARGV[7]: show_id (optional)
+		params.Price,             // ARGV[8]: unit_price
+		params.TTLSeconds,        // ARGV[9]: ttl_seconds
+		params.OverbookAllowance, // ARGV[10]: overbook_allowance
+		checkOnlyArg,             // ARGV[11]: check_only
+		params.QueuePass,         // ARGV[12]: queue_pass (optional)
 	}
 
 	result := r.client.EvalWithFallback(ctx, scriptReserveSeats, reserveSeatsScript, keys, args...)
@@ -96,46 +166,207 @@ func (r *RedisReservationRepository) ReserveSeats(ctx context.Context, params Re
 		return nil, fmt.Errorf("failed to execute reserve_seats script: %w", result.Err())
 	}
 
-	// Parse result
-	values, err := result.Slice()
+	reserveResult, err := ParseReserveResult(result.Val())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
 
-	if len(values) < 3 {
-		span.SetStatus(codes.Error, "unexpected result length")
-		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
+	if !reserveResult.Success {
+		span.SetAttributes(attribute.String("error_code", reserveResult.ErrorCode))
+		span.SetStatus(codes.Error, reserveResult.ErrorCode)
+		return reserveResult, nil
 	}
 
-	success, _ := toInt64(values[0])
-	if success == 1 {
-		availableSeats, _ := toInt64(values[1])
-		userReserved, _ := toInt64(values[2])
-		span.SetAttributes(
-			attribute.String("booking_id", bookingID),
-			attribute.Int64("available_seats", availableSeats),
-		)
-		span.SetStatus(codes.Ok, "")
-		return &ReserveResult{
-			Success:        true,
-			BookingID:      bookingID,
-			AvailableSeats: availableSeats,
-			UserReserved:   userReserved,
+	if params.CheckOnly {
+		// Nothing was reserved, so there is no booking
+		bookingID = ""
+	}
+	reserveResult.BookingID = bookingID
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.Int64("available_seats", reserveResult.AvailableSeats),
+	)
+	span.SetStatus(codes.Ok, "")
+	return reserveResult, nil
+}
+
+// ReserveSpecificSeats atomically holds the named seats in the zone's seat
+// map. If any seat is taken nothing is held and the result lists every
+// conflicting seat.
+func (r *RedisReservationRepository) ReserveSpecificSeats(ctx context.Context, params SeatReserveParams) (*ReserveResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.reserve_specific_seats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", params.ZoneID),
+		attribute.String("user_id", params.UserID),
+		attribute.String("event_id", params.EventID),
+		attribute.Int("quantity", len(params.SeatIDs)),
+	)
+
+	bookingID := uuid.New().String()
+
+	keys := []string{
+		fmt.Sprintf("zone:seats:%s", params.ZoneID),
+		fmt.Sprintf("zone:availability:%s", params.ZoneID),
+		fmt.Sprintf("user:reservations:%s:%s", params.UserID, params.EventID),
+		fmt.Sprintf("reservation:%s", bookingID),
+		fmt.Sprintf("event:zones:%s", params.EventID),
+		fmt.Sprintf("event:holds:%s", params.EventID),
+		fmt.Sprintf("hold:shadow:%s", bookingID),
+		expiryIndexKey,
+	}
+	args := make([]interface{}, 0, 7+len(params.SeatIDs))
+	args = append(args,
+		params.MaxPerUser, // ARGV[1]: max_per_user
+		params.UserID,     // ARGV[2]: user_id
+		bookingID,         // ARGV[3]: booking_id
+		params.ZoneID,     // ARGV[4]: zone_id
+		params.EventID,    // ARGV[5]: event_id
+		params.Price,      // ARGV[6]: unit_price
+		params.TTLSeconds, // ARGV[7]: ttl_seconds
+	)
+	for _, seatID := range params.SeatIDs {
+		args = append(args, seatID) // ARGV[8..]: seat_ids
+	}
+
+	result := r.client.EvalWithFallback(ctx, scriptReserveSpecificSeats, reserveSpecificSeatsScript, keys, args...)
+	if result.Err() != nil {
+		span.RecordError(result.Err())
+		span.SetStatus(codes.Error, result.Err().Error())
+		return nil, fmt.Errorf("failed to execute reserve_specific_seats script: %w", result.Err())
+	}
+
+	reserveResult, err := ParseReserveResult(result.Val())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
+	}
+
+	if !reserveResult.Success {
+		span.SetAttributes(attribute.String("error_code", reserveResult.ErrorCode))
+		span.SetStatus(codes.Error, reserveResult.ErrorCode)
+		return reserveResult, nil
+	}
+
+	reserveResult.BookingID = bookingID
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.Int64("available_seats", reserveResult.AvailableSeats),
+	)
+	span.SetStatus(codes.Ok, "")
+	return reserveResult, nil
+}
+
+// ReleaseSpecificSeats frees seats held by a specific-seat reservation back to
+// the zone's seat map. An empty seatIDs releases every seat the booking holds;
+// otherwise the reservation keeps the seats not listed.
+func (r *RedisReservationRepository) ReleaseSpecificSeats(ctx context.Context, bookingID, userID string, seatIDs []string) (*ReleaseResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.release_specific_seats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.String("user_id", userID),
+		attribute.Int("quantity", len(seatIDs)),
+	)
+
+	// First, get the reservation to find the zone_id and event_id
+	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
+	reservationData, err := r.client.Client().HMGet(ctx, reservationKey, "zone_id", "event_id").Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	zoneID, _ := reservationData[0].(string)
+	eventID, _ := reservationData[1].(string)
+	if zoneID == "" {
+		span.SetStatus(codes.Error, pkgredis.CodeReservationNotFound)
+		return &ReleaseResult{
+			Success:      false,
+			ErrorCode:    pkgredis.CodeReservationNotFound,
+			ErrorMessage: "Reservation does not exist or has expired",
 		}, nil
 	}
 
-	// Error case
-	errorCode, _ := values[1].(string)
-	errorMessage, _ := values[2].(string)
-	span.SetAttributes(attribute.String("error_code", errorCode))
-	span.SetStatus(codes.Error, errorCode)
-	return &ReserveResult{
-		Success:      false,
-		ErrorCode:    errorCode,
-		ErrorMessage: errorMessage,
-	}, nil
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.String("event_id", eventID),
+	)
+
+	keys := []string{
+		reservationKey,
+		fmt.Sprintf("zone:seats:%s", zoneID),
+		fmt.Sprintf("zone:availability:%s", zoneID),
+		fmt.Sprintf("user:reservations:%s:%s", userID, eventID),
+		fmt.Sprintf("hold:shadow:%s", bookingID),
+		expiryIndexKey,
+	}
+	args := make([]interface{}, 0, 2+len(seatIDs))
+	args = append(args, bookingID, userID)
+	for _, seatID := range seatIDs {
+		args = append(args, seatID) // ARGV[3..]: seat_ids
+	}
+
+	result := r.client.EvalWithFallback(ctx, scriptReleaseSpecificSeats, releaseSpecificSeatsScript, keys, args...)
+	if result.Err() != nil {
+		span.RecordError(result.Err())
+		span.SetStatus(codes.Error, result.Err().Error())
+		return nil, fmt.Errorf("failed to execute release_specific_seats script: %w", result.Err())
+	}
+
+	releaseResult, err := ParseReleaseResult(result.Val())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
+	}
+
+	if !releaseResult.Success {
+		span.SetAttributes(attribute.String("error_code", releaseResult.ErrorCode))
+		span.SetStatus(codes.Error, releaseResult.ErrorCode)
+		return releaseResult, nil
+	}
+
+	span.SetAttributes(attribute.Int64("available_seats", releaseResult.AvailableSeats))
+	span.SetStatus(codes.Ok, "")
+	return releaseResult, nil
+}
+
+// SetZoneSeats adds seats to a zone's seat map as free (for initialization)
+// and adds them to the zone's availability counter, seeding the counter if the
+// zone has none. Seats already in the map keep their current holder.
+func (r *RedisReservationRepository) SetZoneSeats(ctx context.Context, zoneID string, seatIDs []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.set_zone_seats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.Int("seats", len(seatIDs)),
+	)
+
+	keys := []string{
+		fmt.Sprintf("zone:seats:%s", zoneID),
+		fmt.Sprintf("zone:availability:%s", zoneID),
+	}
+	args := make([]interface{}, len(seatIDs))
+	for i, seatID := range seatIDs {
+		args[i] = seatID
+	}
+
+	if err := r.client.EvalWithFallback(ctx, scriptSetZoneSeats, setZoneSeatsScript, keys, args...).Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to set zone seats: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
 }
 
 // ConfirmBooking confirms a reservation and makes it permanent
@@ -149,7 +380,7 @@ func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, booking
 	)
 
 	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
-	keys := []string{reservationKey}
+	keys := []string{reservationKey, fmt.Sprintf("hold:shadow:%s", bookingID), expiryIndexKey}
 	args := []interface{}{bookingID, userID, paymentID}
 
 	result := r.client.EvalWithFallback(ctx, scriptConfirmBooking, confirmBookingScript, keys, args...)
@@ -159,41 +390,21 @@ func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, booking
 		return nil, fmt.Errorf("failed to execute confirm_booking script: %w", result.Err())
 	}
 
-	// Parse result
-	values, err := result.Slice()
+	confirmResult, err := ParseConfirmResult(result.Val())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
 
-	if len(values) < 3 {
-		span.SetStatus(codes.Error, "unexpected result length")
-		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
-	}
-
-	success, _ := toInt64(values[0])
-	if success == 1 {
-		status, _ := values[1].(string)
-		confirmedAt, _ := values[2].(string)
-		span.SetStatus(codes.Ok, "")
-		return &ConfirmResult{
-			Success:     true,
-			Status:      status,
-			ConfirmedAt: confirmedAt,
-		}, nil
+	if !confirmResult.Success {
+		span.SetAttributes(attribute.String("error_code", confirmResult.ErrorCode))
+		span.SetStatus(codes.Error, confirmResult.ErrorCode)
+		return confirmResult, nil
 	}
 
-	// Error case
-	errorCode, _ := values[1].(string)
-	errorMessage, _ := values[2].(string)
-	span.SetAttributes(attribute.String("error_code", errorCode))
-	span.SetStatus(codes.Error, errorCode)
-	return &ConfirmResult{
-		Success:      false,
-		ErrorCode:    errorCode,
-		ErrorMessage: errorMessage,
-	}, nil
+	span.SetStatus(codes.Ok, "")
+	return confirmResult, nil
 }
 
 // ReleaseSeats releases reserved seats back to inventory
@@ -216,27 +427,20 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 	}
 
 	if len(reservationData) == 0 {
-		span.SetStatus(codes.Error, "RESERVATION_NOT_FOUND")
+		span.SetStatus(codes.Error, pkgredis.CodeReservationNotFound)
 		return &ReleaseResult{
 			Success:      false,
-			ErrorCode:    "RESERVATION_NOT_FOUND",
+			ErrorCode:    pkgredis.CodeReservationNotFound,
 			ErrorMessage: "Reservation does not exist or has expired",
 		}, nil
 	}
 
-	zoneID := reservationData["zone_id"]
-	eventID := reservationData["event_id"]
-
 	span.SetAttributes(
-		attribute.String("zone_id", zoneID),
-		attribute.String("event_id", eventID),
+		attribute.String("zone_id", reservationData["zone_id"]),
+		attribute.String("event_id", reservationData["event_id"]),
 	)
 
-	// Build Redis keys
-	zoneAvailabilityKey := fmt.Sprintf("zone:availability:%s", zoneID)
-	userReservationsKey := fmt.Sprintf("user:reservations:%s:%s", userID, eventID)
-
-	keys := []string{zoneAvailabilityKey, userReservationsKey, reservationKey}
+	keys := releaseSeatsKeys(bookingID, userID, reservationData)
 	args := []interface{}{bookingID, userID}
 
 	result := r.client.EvalWithFallback(ctx, scriptReleaseSeats, releaseSeatsScript, keys, args...)
@@ -246,45 +450,261 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 		return nil, fmt.Errorf("failed to execute release_seats script: %w", result.Err())
 	}
 
-	// Parse result
-	values, err := result.Slice()
+	releaseResult, err := ParseReleaseResult(result.Val())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
 
-	if len(values) < 3 {
-		span.SetStatus(codes.Error, "unexpected result length")
-		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
+	if !releaseResult.Success {
+		span.SetAttributes(attribute.String("error_code", releaseResult.ErrorCode))
+		span.SetStatus(codes.Error, releaseResult.ErrorCode)
+		return releaseResult, nil
 	}
 
-	success, _ := toInt64(values[0])
-	if success == 1 {
-		availableSeats, _ := toInt64(values[1])
-		userReserved, _ := toInt64(values[2])
-		span.SetAttributes(attribute.Int64("available_seats", availableSeats))
+	span.SetAttributes(attribute.Int64("available_seats", releaseResult.AvailableSeats))
+	span.SetStatus(codes.Ok, "")
+	return releaseResult, nil
+}
+
+// releaseSeatsKeys builds the release_seats script keys for a reservation hash
+func releaseSeatsKeys(bookingID, userID string, reservation map[string]string) []string {
+	showID := reservation["show_id"]
+	keys := []string{
+		fmt.Sprintf("zone:availability:%s", reservation["zone_id"]),
+		fmt.Sprintf("user:reservations:%s:%s", userID, reservation["event_id"]),
+		fmt.Sprintf("reservation:%s", bookingID),
+		fmt.Sprintf("hold:shadow:%s", bookingID),
+		expiryIndexKey,
+	}
+	if showHeld(showID, reservation["show_counted"]) {
+		keys = append(keys, fmt.Sprintf("show:availability:%s", showID))
+	}
+	return keys
+}
+
+// ReleaseSeatsBatch releases many holds in two round trips: one pipeline reads
+// every reservation hash and a second runs release_seats for each one found.
+// Results are returned in holds order. A script that fails on its own (e.g.
+// NOSCRIPT after a Redis restart) is retried through ReleaseSeats.
+func (r *RedisReservationRepository) ReleaseSeatsBatch(ctx context.Context, holds []HeldReservation) ([]*ReleaseResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.release_seats_batch")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("holds", len(holds)))
+
+	results := make([]*ReleaseResult, len(holds))
+	if len(holds) == 0 {
 		span.SetStatus(codes.Ok, "")
+		return results, nil
+	}
+
+	pipe := r.client.Pipeline()
+	reads := make([]*redis.MapStringStringCmd, len(holds))
+	for i, hold := range holds {
+		reads[i] = pipe.HGetAll(ctx, fmt.Sprintf("reservation:%s", hold.BookingID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get reservations: %w", err)
+	}
+
+	sha, ok := r.client.GetScriptSHA(scriptReleaseSeats)
+	if !ok {
+		info, err := r.client.LoadScript(ctx, scriptReleaseSeats, releaseSeatsScript)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		sha = info.SHA
+	}
+
+	pipe = r.client.Pipeline()
+	evals := make([]*redis.Cmd, len(holds))
+	for i, hold := range holds {
+		reservationData := reads[i].Val()
+		if len(reservationData) == 0 {
+			results[i] = &ReleaseResult{
+				Success:      false,
+				ErrorCode:    pkgredis.CodeReservationNotFound,
+				ErrorMessage: "Reservation does not exist or has expired",
+			}
+			continue
+		}
+		keys := releaseSeatsKeys(hold.BookingID, hold.UserID, reservationData)
+		evals[i] = pipe.EvalSha(ctx, sha, keys, hold.BookingID, hold.UserID)
+	}
+	// Per-command errors are handled below, one hold at a time
+	_, _ = pipe.Exec(ctx)
+
+	for i, cmd := range evals {
+		if cmd == nil {
+			continue
+		}
+		if cmd.Err() != nil {
+			result, err := r.ReleaseSeats(ctx, holds[i].BookingID, holds[i].UserID)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			results[i] = result
+			continue
+		}
+		result, err := ParseReleaseResult(cmd.Val())
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to parse script result: %w", err)
+		}
+		results[i] = result
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return results, nil
+}
+
+// RestoreExpiredHold returns the seats of a hold whose reservation expired to
+// inventory, reading the hold from the shadow copy the reserve scripts keep
+func (r *RedisReservationRepository) RestoreExpiredHold(ctx context.Context, bookingID string) (*ReleaseResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.restore_expired_hold")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("booking_id", bookingID))
+
+	// First, get the shadow to find the zone, user and event
+	shadowKey := fmt.Sprintf("hold:shadow:%s", bookingID)
+	shadow, err := r.client.HGetAll(ctx, shadowKey).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get hold shadow: %w", err)
+	}
+
+	if len(shadow) == 0 {
+		span.SetStatus(codes.Error, pkgredis.CodeReservationNotFound)
 		return &ReleaseResult{
-			Success:        true,
-			AvailableSeats: availableSeats,
-			UserReserved:   userReserved,
+			Success:      false,
+			ErrorCode:    pkgredis.CodeReservationNotFound,
+			ErrorMessage: "No expired hold to restore",
 		}, nil
 	}
 
-	// Error case
-	errorCode, _ := values[1].(string)
-	errorMessage, _ := values[2].(string)
-	span.SetAttributes(attribute.String("error_code", errorCode))
-	span.SetStatus(codes.Error, errorCode)
-	return &ReleaseResult{
-		Success:      false,
-		ErrorCode:    errorCode,
-		ErrorMessage: errorMessage,
-	}, nil
+	zoneID := shadow["zone_id"]
+	userID := shadow["user_id"]
+	eventID := shadow["event_id"]
+	showID := shadow["show_id"]
+
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.String("user_id", userID),
+		attribute.String("event_id", eventID),
+	)
+
+	keys := []string{
+		shadowKey,
+		fmt.Sprintf("reservation:%s", bookingID),
+		fmt.Sprintf("zone:availability:%s", zoneID),
+		fmt.Sprintf("user:reservations:%s:%s", userID, eventID),
+		fmt.Sprintf("zone:seats:%s", zoneID),
+		expiryIndexKey,
+	}
+	if showHeld(showID, shadow["show_counted"]) {
+		keys = append(keys, fmt.Sprintf("show:availability:%s", showID))
+	}
+
+	result := r.client.EvalWithFallback(ctx, scriptRestoreExpiredHold, restoreExpiredHoldScript, keys, bookingID)
+	if result.Err() != nil {
+		span.RecordError(result.Err())
+		span.SetStatus(codes.Error, result.Err().Error())
+		return nil, fmt.Errorf("failed to execute restore_expired_hold script: %w", result.Err())
+	}
+
+	releaseResult, err := ParseReleaseResult(result.Val())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
+	}
+
+	if !releaseResult.Success {
+		span.SetAttributes(attribute.String("error_code", releaseResult.ErrorCode))
+		span.SetStatus(codes.Error, releaseResult.ErrorCode)
+		return releaseResult, nil
+	}
+
+	span.SetAttributes(attribute.Int64("available_seats", releaseResult.AvailableSeats))
+	span.SetStatus(codes.Ok, "")
+	return releaseResult, nil
 }
 
-// GetZoneAvailability gets the current available seats for a zone
+// DueExpiredHolds returns up to limit entries of the expiry index whose hold
+// expired at or before before, oldest first. Entries that cannot be parsed
+// are returned with only Member set so the caller can remove them.
+func (r *RedisReservationRepository) DueExpiredHolds(ctx context.Context, before time.Time, limit int64) ([]ExpiryEntry, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.due_expired_holds")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("limit", limit))
+
+	members, err := r.client.Client().ZRangeByScoreWithScores(ctx, expiryIndexKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to read expiry index: %w", err)
+	}
+
+	entries := make([]ExpiryEntry, 0, len(members))
+	for _, z := range members {
+		member, _ := z.Member.(string)
+		entry, ok := ParseExpiryEntry(member)
+		if !ok {
+			entry = ExpiryEntry{Member: member}
+		}
+		entry.ExpiresAt = time.Unix(int64(z.Score), 0)
+		entries = append(entries, entry)
+	}
+
+	span.SetAttributes(attribute.Int("due", len(entries)))
+	span.SetStatus(codes.Ok, "")
+	return entries, nil
+}
+
+// RemoveExpiryEntries drops entries from the expiry index, for holds that no
+// longer have anything to restore
+func (r *RedisReservationRepository) RemoveExpiryEntries(ctx context.Context, entries ...ExpiryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.remove_expiry_entries")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("entries", len(entries)))
+
+	members := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		members[i] = entry.Member
+	}
+	if err := r.client.ZRem(ctx, expiryIndexKey, members...).Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to remove expiry entries: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// GetZoneAvailability gets the current available seats for a zone. An
+// overbooked zone reports 0, not its negative counter.
 func (r *RedisReservationRepository) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get_zone_availability")
 	defer span.End()
@@ -310,6 +730,32 @@ func (r *RedisReservationRepository) GetZoneAvailability(ctx context.Context, zo
 		return 0, fmt.Errorf("failed to parse availability: %w", err)
 	}
 
+	span.SetAttributes(attribute.Int64("available_seats", seats))
+	span.SetStatus(codes.Ok, "")
+	return max(seats, 0), nil
+}
+
+// GetShowAvailability gets the available seats across all zones of a show.
+// The counter is seeded when inventory is synced and kept in step with the
+// zone counters by the reserve and release scripts.
+func (r *RedisReservationRepository) GetShowAvailability(ctx context.Context, showID string) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get_show_availability")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("show_id", showID))
+
+	key := fmt.Sprintf("show:availability:%s", showID)
+	seats, err := r.client.Get(ctx, key).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			span.SetStatus(codes.Ok, "show not found")
+			return 0, nil // Show not found, return 0
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to get show availability: %w", err)
+	}
+
 	span.SetAttributes(attribute.Int64("available_seats", seats))
 	span.SetStatus(codes.Ok, "")
 	return seats, nil
@@ -337,6 +783,48 @@ func (r *RedisReservationRepository) SetZoneAvailability(ctx context.Context, zo
 	return nil
 }
 
+// InitZone sets the available seats for a zone only if it has not been
+// initialized yet. It reports whether this call initialized the zone; an
+// existing value is left unchanged. A ttl of 0 means the key never expires.
+// With a showID, initializing the zone also records which show it belongs to
+// and adds its seats to the show-wide counter.
+func (r *RedisReservationRepository) InitZone(ctx context.Context, zoneID, showID string, seats int64, ttl time.Duration) (bool, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.init_zone")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.String("show_id", showID),
+		attribute.Int64("seats", seats),
+	)
+
+	if seats < 0 {
+		span.SetStatus(codes.Error, "invalid seats")
+		return false, fmt.Errorf("invalid seats for zone %s: %d", zoneID, seats)
+	}
+
+	keys := []string{fmt.Sprintf("zone:availability:%s", zoneID)}
+	if showID != "" {
+		keys = append(keys, ZoneShowKey(zoneID), fmt.Sprintf("show:availability:%s", showID))
+	}
+	args := []interface{}{
+		seats,                    // ARGV[1]: seats
+		int64(ttl / time.Second), // ARGV[2]: ttl_seconds
+		showID,                   // ARGV[3]: show_id (optional)
+	}
+
+	initialized, err := r.client.EvalWithFallback(ctx, scriptInitZone, initZoneScript, keys, args...).Int64()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, fmt.Errorf("failed to execute init_zone script: %w", err)
+	}
+
+	span.SetAttributes(attribute.Bool("initialized", initialized == 1))
+	span.SetStatus(codes.Ok, "")
+	return initialized == 1, nil
+}
+
 // GetReservation gets a reservation by booking ID
 func (r *RedisReservationRepository) GetReservation(ctx context.Context, bookingID string) (map[string]string, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get")
@@ -356,6 +844,198 @@ func (r *RedisReservationRepository) GetReservation(ctx context.Context, booking
 	return result, nil
 }
 
+// HeldReservation identifies a reservation hash found by ScanReservations
+type HeldReservation struct {
+	BookingID string
+	UserID    string
+}
+
+// ScanReservations walks every reservation:* hash with SCAN (never KEYS, which
+// blocks Redis) and calls fn with each page of up to batchSize reservations.
+// Keys that expire mid-scan are skipped; SCAN may report a key more than once.
+func (r *RedisReservationRepository) ScanReservations(ctx context.Context, batchSize int64, fn func([]HeldReservation) error) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.scan")
+	defer span.End()
+
+	// Cancelling stops the key scan if fn returns early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys, scanErr, err := r.client.ScanKeys(ctx, "reservation:*", batchSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to scan reservations: %w", err)
+	}
+
+	var scanned int
+	flush := func(batch []string) error {
+		page, err := r.heldReservations(ctx, batch)
+		if err != nil {
+			return err
+		}
+		scanned += len(page)
+		if len(page) == 0 {
+			return nil
+		}
+		return fn(page)
+	}
+
+	batch := make([]string, 0, batchSize)
+	for key := range keys {
+		batch = append(batch, key)
+		if int64(len(batch)) < batchSize {
+			continue
+		}
+		if err := flush(batch); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		batch = batch[:0]
+	}
+	if err := scanErr(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to scan reservations: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := flush(batch); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	span.SetAttributes(attribute.Int("scanned", scanned))
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// heldReservations reads the owner of each reservation key in one pipeline
+func (r *RedisReservationRepository) heldReservations(ctx context.Context, keys []string) ([]HeldReservation, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGet(ctx, key, "user_id")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to read reservations: %w", err)
+	}
+
+	page := make([]HeldReservation, 0, len(keys))
+	for i, cmd := range cmds {
+		userID, err := cmd.Result()
+		if err != nil {
+			// Expired between SCAN and HGET
+			continue
+		}
+		page = append(page, HeldReservation{
+			BookingID: strings.TrimPrefix(keys[i], "reservation:"),
+			UserID:    userID,
+		})
+	}
+	return page, nil
+}
+
+// GetEventStats aggregates seats remaining per zone (one MGET over the event's
+// zone set) and active holds (from the event's hold index, reading each hold's
+// status and quantity in one pipeline). Released and confirmed holds found in
+// the index are pruned. Zones no reservation has touched yet are not listed.
+func (r *RedisReservationRepository) GetEventStats(ctx context.Context, eventID string) (*EventStats, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get_event_stats")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	zoneIDs, err := r.client.Client().SMembers(ctx, fmt.Sprintf("event:zones:%s", eventID)).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get event zones: %w", err)
+	}
+	sort.Strings(zoneIDs)
+
+	stats := &EventStats{Zones: make([]ZoneStats, 0, len(zoneIDs))}
+	if len(zoneIDs) > 0 {
+		keys := make([]string, len(zoneIDs))
+		for i, zoneID := range zoneIDs {
+			keys[i] = fmt.Sprintf("zone:availability:%s", zoneID)
+		}
+		values, err := r.client.Client().MGet(ctx, keys...).Result()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to get zone availability: %w", err)
+		}
+		for i, v := range values {
+			remaining, ok := toInt64(v)
+			if !ok {
+				// Zone key removed since it was reserved against
+				continue
+			}
+			stats.Zones = append(stats.Zones, ZoneStats{ZoneID: zoneIDs[i], SeatsRemaining: remaining})
+			stats.SeatsRemaining += remaining
+		}
+	}
+
+	if err := r.countActiveHolds(ctx, eventID, stats); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int64("seats_remaining", stats.SeatsRemaining),
+		attribute.Int64("active_holds", stats.ActiveHolds),
+	)
+	span.SetStatus(codes.Ok, "")
+	return stats, nil
+}
+
+// countActiveHolds adds the event's unexpired holds still in "reserved" status
+// to stats and prunes index entries for holds that were released or confirmed
+func (r *RedisReservationRepository) countActiveHolds(ctx context.Context, eventID string, stats *EventStats) error {
+	holdsKey := fmt.Sprintf("event:holds:%s", eventID)
+	bookingIDs, err := r.client.ZRangeByScore(ctx, holdsKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get event holds: %w", err)
+	}
+	if len(bookingIDs) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(bookingIDs))
+	for i, bookingID := range bookingIDs {
+		cmds[i] = pipe.HMGet(ctx, fmt.Sprintf("reservation:%s", bookingID), "status", "quantity")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read event holds: %w", err)
+	}
+
+	var stale []interface{}
+	for i, cmd := range cmds {
+		values, err := cmd.Result()
+		if err != nil || len(values) < 2 || values[0] != "reserved" {
+			stale = append(stale, bookingIDs[i])
+			continue
+		}
+		quantity, _ := toInt64(values[1])
+		stats.ActiveHolds++
+		stats.SeatsReserved += quantity
+	}
+
+	if len(stale) > 0 {
+		// Best effort; the next read retries
+		r.client.ZRem(ctx, holdsKey, stale...)
+	}
+	return nil
+}
+
 // GetUserReservedCount gets the total reserved count for a user on an event
 func (r *RedisReservationRepository) GetUserReservedCount(ctx context.Context, userID, eventID string) (int64, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get_user_count")
@@ -412,3 +1092,9 @@ func toInt64(v interface{}) (int64, bool) {
 
 // Ensure RedisReservationRepository implements ReservationRepository
 var _ ReservationRepository = (*RedisReservationRepository)(nil)
+
+// Ensure RedisReservationRepository implements EventStatsRepository
+var _ EventStatsRepository = (*RedisReservationRepository)(nil)
+
+// Ensure RedisReservationRepository implements SeatMapRepository
+var _ SeatMapRepository = (*RedisReservationRepository)(nil)
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// Benchmarks for the reserve path. They run against the same Redis as the
+// integration tests (TEST_REDIS_HOST, TEST_REDIS_PORT, TEST_REDIS_PASSWORD) and
+// are skipped unless INTEGRATION_TEST=true:
+//
+//	INTEGRATION_TEST=true go test -run '^$' -bench . ./internal/repository/
+
+// newBenchRepository returns a repository with its scripts loaded and zoneID
+// holding seats available seats
+func newBenchRepository(b *testing.B, zoneID string, seats int64) *RedisReservationRepository {
+	b.Helper()
+	skipIfNoIntegration(b)
+
+	ctx := context.Background()
+	client := getRedisClient(b)
+	b.Cleanup(func() { client.Close() })
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		b.Fatalf("Failed to load scripts: %v", err)
+	}
+	if err := repo.SetZoneAvailability(ctx, zoneID, seats); err != nil {
+		b.Fatalf("Failed to set zone availability: %v", err)
+	}
+	return repo
+}
+
+func BenchmarkReserveSeats(b *testing.B) {
+	zoneID := "zone-bench-reserve"
+	// One seat per iteration, so the zone never sells out mid-run
+	repo := newBenchRepository(b, zoneID, int64(b.N)+1)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     fmt.Sprintf("bench-user-%d", i),
+			EventID:    "event-bench",
+			Quantity:   1,
+			MaxPerUser: 4,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil {
+			b.Fatalf("ReserveSeats() error = %v", err)
+		}
+		if !result.Success {
+			b.Fatalf("ReserveSeats() failed: %s %s", result.ErrorCode, result.ErrorMessage)
+		}
+	}
+}
+
+func BenchmarkRejectSoldOut(b *testing.B) {
+	zoneID := "zone-bench-sold-out"
+	repo := newBenchRepository(b, zoneID, 0)
+	ctx := context.Background()
+
+	params := ReserveParams{
+		ZoneID:     zoneID,
+		UserID:     "bench-user",
+		EventID:    "event-bench",
+		Quantity:   1,
+		MaxPerUser: 4,
+		TTLSeconds: 600,
+		Price:      100.00,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := repo.ReserveSeats(ctx, params)
+		if err != nil {
+			b.Fatalf("ReserveSeats() error = %v", err)
+		}
+		if result.ErrorCode != "INSUFFICIENT_STOCK" {
+			b.Fatalf("ReserveSeats() errorCode = %q, want INSUFFICIENT_STOCK", result.ErrorCode)
+		}
+	}
+}
@@ -2,32 +2,42 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/redis/go-redis/v9"
 )
 
 // skipIfNoIntegration skips the test if INTEGRATION_TEST env var is not set
-func skipIfNoIntegration(t *testing.T) {
+func skipIfNoIntegration(t testing.TB) {
 	if os.Getenv("INTEGRATION_TEST") != "true" {
 		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
 	}
 }
 
 // getRedisClient creates a Redis client for testing
-func getRedisClient(t *testing.T) *pkgredis.Client {
+func getRedisClient(t testing.TB) *pkgredis.Client {
 	host := os.Getenv("TEST_REDIS_HOST")
 	if host == "" {
 		host = "localhost"
 	}
 
+	port := 6379
+	if p, err := strconv.Atoi(os.Getenv("TEST_REDIS_PORT")); err == nil {
+		port = p
+	}
+
 	password := os.Getenv("TEST_REDIS_PASSWORD")
 
 	cfg := &pkgredis.Config{
 		Host:          host,
-		Port:          6379,
+		Port:          port,
 		Password:      password,
 		DB:            15, // Use DB 15 for testing
 		PoolSize:      10,
@@ -203,7 +213,7 @@ func TestRedisReservationRepository_ReserveSeats_InsufficientStock(t *testing.T)
 	}
 }
 
-func TestRedisReservationRepository_ReleaseSeats(t *testing.T) {
+func TestRedisReservationRepository_ReserveSeats_OverbookAllowance(t *testing.T) {
 	skipIfNoIntegration(t)
 
 	ctx := context.Background()
@@ -216,58 +226,118 @@ func TestRedisReservationRepository_ReleaseSeats(t *testing.T) {
 		t.Fatalf("Failed to load scripts: %v", err)
 	}
 
-	// Initialize zone
-	zoneID := "zone-release-test"
-	initialSeats := int64(100)
-	if err := repo.SetZoneAvailability(ctx, zoneID, initialSeats); err != nil {
+	// 5 real seats, oversold by up to 3
+	zoneID := "zone-overbook"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 5); err != nil {
 		t.Fatalf("Failed to set zone availability: %v", err)
 	}
 
-	// Reserve seats first
-	reserveResult, err := repo.ReserveSeats(ctx, ReserveParams{
-		ZoneID:     zoneID,
-		UserID:     "user-release",
-		EventID:    "event-release",
-		Quantity:   3,
-		MaxPerUser: 10,
-		TTLSeconds: 600,
-		Price:      100.00,
-	})
+	reserve := func(userID string, quantity int) *ReserveResult {
+		t.Helper()
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:            zoneID,
+			UserID:            userID,
+			EventID:           "event-001",
+			Quantity:          quantity,
+			MaxPerUser:        20,
+			TTLSeconds:        600,
+			Price:             100.00,
+			OverbookAllowance: 3,
+		})
+		if err != nil {
+			t.Fatalf("ReserveSeats() error = %v", err)
+		}
+		return result
+	}
 
-	if err != nil || !reserveResult.Success {
-		t.Fatalf("Failed to reserve seats: %v, %+v", err, reserveResult)
+	// 7 seats fit within 5 + 3 and drive the real counter negative
+	result := reserve("user-001", 7)
+	if !result.Success {
+		t.Fatalf("ReserveSeats() failed within allowance: %s - %s", result.ErrorCode, result.ErrorMessage)
+	}
+	if result.AvailableSeats != 0 {
+		t.Errorf("ReserveSeats() availableSeats = %d, want 0 (never reported negative)", result.AvailableSeats)
+	}
+	if raw, _ := client.Client().Get(ctx, fmt.Sprintf("zone:availability:%s", zoneID)).Int64(); raw != -2 {
+		t.Errorf("Zone counter = %d, want -2", raw)
 	}
 
-	// Verify availability decreased
-	available, err := repo.GetZoneAvailability(ctx, zoneID)
-	if err != nil {
-		t.Fatalf("Failed to get availability: %v", err)
+	// 1 more seat uses the last of the allowance
+	result = reserve("user-002", 1)
+	if !result.Success {
+		t.Fatalf("ReserveSeats() failed within allowance: %s - %s", result.ErrorCode, result.ErrorMessage)
 	}
-	if available != initialSeats-3 {
-		t.Errorf("Available seats = %d, want %d", available, initialSeats-3)
+	if result.AvailableSeats != 0 {
+		t.Errorf("ReserveSeats() availableSeats = %d, want 0 (never reported negative)", result.AvailableSeats)
+	}
+	if available, _ := repo.GetZoneAvailability(ctx, zoneID); available != 0 {
+		t.Errorf("GetZoneAvailability() = %d for an overbooked zone, want 0", available)
 	}
 
-	// Release seats
-	releaseResult, err := repo.ReleaseSeats(ctx, reserveResult.BookingID, "user-release")
-	if err != nil {
-		t.Fatalf("ReleaseSeats() error = %v", err)
+	// The allowance is exhausted
+	result = reserve("user-003", 1)
+	if result.Success {
+		t.Error("ReserveSeats() should fail once the allowance is exhausted")
+	}
+	if result.ErrorCode != "INSUFFICIENT_STOCK" {
+		t.Errorf("ReserveSeats() errorCode = %v, want INSUFFICIENT_STOCK", result.ErrorCode)
 	}
+}
 
-	if !releaseResult.Success {
-		t.Errorf("ReleaseSeats() failed: %s - %s", releaseResult.ErrorCode, releaseResult.ErrorMessage)
+func TestRedisReservationRepository_ReserveSeats_NoAllowanceNeverNegative(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
 	}
 
-	// Verify seats restored
-	available, err = repo.GetZoneAvailability(ctx, zoneID)
+	zoneID := "zone-strict"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 5); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	// Reserve one seat at a time until the zone runs out
+	successCount := 0
+	for i := 0; i < 10; i++ {
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     fmt.Sprintf("user-%03d", i),
+			EventID:    "event-001",
+			Quantity:   1,
+			MaxPerUser: 20,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil {
+			t.Fatalf("ReserveSeats() error = %v", err)
+		}
+		if result.Success {
+			successCount++
+		} else if result.ErrorCode != "INSUFFICIENT_STOCK" {
+			t.Errorf("ReserveSeats() errorCode = %v, want INSUFFICIENT_STOCK", result.ErrorCode)
+		}
+	}
+
+	if successCount != 5 {
+		t.Errorf("successful reservations = %d, want 5", successCount)
+	}
+
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
 	if err != nil {
-		t.Fatalf("Failed to get availability: %v", err)
+		t.Fatalf("GetZoneAvailability() error = %v", err)
 	}
-	if available != initialSeats {
-		t.Errorf("Available seats after release = %d, want %d", available, initialSeats)
+	if available != 0 {
+		t.Errorf("GetZoneAvailability() = %d, want 0", available)
 	}
 }
 
-func TestRedisReservationRepository_ConfirmBooking(t *testing.T) {
+func TestRedisReservationRepository_ReserveSeats_CheckOnly(t *testing.T) {
 	skipIfNoIntegration(t)
 
 	ctx := context.Background()
@@ -280,57 +350,119 @@ func TestRedisReservationRepository_ConfirmBooking(t *testing.T) {
 		t.Fatalf("Failed to load scripts: %v", err)
 	}
 
-	// Initialize zone
-	zoneID := "zone-confirm-test"
-	if err := repo.SetZoneAvailability(ctx, zoneID, 100); err != nil {
+	zoneID := "zone-check-only"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 10); err != nil {
 		t.Fatalf("Failed to set zone availability: %v", err)
 	}
 
-	// Reserve seats first
-	reserveResult, err := repo.ReserveSeats(ctx, ReserveParams{
+	// user-001 already holds 2 seats
+	held, err := repo.ReserveSeats(ctx, ReserveParams{
 		ZoneID:     zoneID,
-		UserID:     "user-confirm",
-		EventID:    "event-confirm",
+		UserID:     "user-001",
+		EventID:    "event-001",
 		Quantity:   2,
-		MaxPerUser: 10,
+		MaxPerUser: 4,
 		TTLSeconds: 600,
 		Price:      100.00,
 	})
-
-	if err != nil || !reserveResult.Success {
-		t.Fatalf("Failed to reserve seats: %v, %+v", err, reserveResult)
+	if err != nil || !held.Success {
+		t.Fatalf("ReserveSeats() setup failed: %v", err)
 	}
 
-	// Confirm booking
-	confirmResult, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-123")
-	if err != nil {
-		t.Fatalf("ConfirmBooking() error = %v", err)
+	tests := []struct {
+		name         string
+		quantity     int
+		wantSuccess  bool
+		wantError    string
+		wantAvail    int64
+		wantReserved int64
+	}{
+		{
+			name:         "would succeed",
+			quantity:     2,
+			wantSuccess:  true,
+			wantAvail:    6,
+			wantReserved: 4,
+		},
+		{
+			name:      "user limit exceeded",
+			quantity:  3,
+			wantError: "USER_LIMIT_EXCEEDED",
+		},
+		{
+			name:      "insufficient stock",
+			quantity:  9,
+			wantError: "INSUFFICIENT_STOCK",
+		},
+		{
+			name:      "invalid quantity",
+			quantity:  0,
+			wantError: "INVALID_QUANTITY",
+		},
 	}
 
-	if !confirmResult.Success {
-		t.Errorf("ConfirmBooking() failed: %s - %s", confirmResult.ErrorCode, confirmResult.ErrorMessage)
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.ReserveSeats(ctx, ReserveParams{
+				ZoneID:     zoneID,
+				UserID:     "user-001",
+				EventID:    "event-001",
+				Quantity:   tt.quantity,
+				MaxPerUser: 4,
+				TTLSeconds: 600,
+				Price:      100.00,
+				CheckOnly:  true,
+			})
+			if err != nil {
+				t.Fatalf("ReserveSeats() error = %v", err)
+			}
 
-	if confirmResult.Status != "CONFIRMED" {
-		t.Errorf("ConfirmBooking() status = %v, want CONFIRMED", confirmResult.Status)
-	}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("ReserveSeats() success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.ErrorCode)
+			}
+			if tt.wantSuccess {
+				if result.BookingID != "" {
+					t.Errorf("ReserveSeats() bookingID = %q, want empty for check_only", result.BookingID)
+				}
+				if result.AvailableSeats != tt.wantAvail {
+					t.Errorf("ReserveSeats() availableSeats = %d, want %d", result.AvailableSeats, tt.wantAvail)
+				}
+				if result.UserReserved != tt.wantReserved {
+					t.Errorf("ReserveSeats() userReserved = %d, want %d", result.UserReserved, tt.wantReserved)
+				}
+			} else if result.ErrorCode != tt.wantError {
+				t.Errorf("ReserveSeats() errorCode = %v, want %v", result.ErrorCode, tt.wantError)
+			}
 
-	// Try to confirm again - should fail
-	confirmAgain, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-456")
-	if err != nil {
-		t.Fatalf("ConfirmBooking() error = %v", err)
+			// Nothing may have been consumed
+			available, err := repo.GetZoneAvailability(ctx, zoneID)
+			if err != nil {
+				t.Fatalf("GetZoneAvailability() error = %v", err)
+			}
+			if available != 8 {
+				t.Errorf("zone availability = %d, want unchanged 8", available)
+			}
+			reserved, err := repo.GetUserReservedCount(ctx, "user-001", "event-001")
+			if err != nil {
+				t.Fatalf("GetUserReservedCount() error = %v", err)
+			}
+			if reserved != 2 {
+				t.Errorf("user reserved = %d, want unchanged 2", reserved)
+			}
+		})
 	}
 
-	if confirmAgain.Success {
-		t.Error("ConfirmBooking() should fail when already confirmed")
+	// No reservation records beyond the real one
+	keys, err := client.Client().Keys(ctx, "reservation:*").Result()
+	if err != nil {
+		t.Fatalf("KEYS error = %v", err)
 	}
-
-	if confirmAgain.ErrorCode != "ALREADY_CONFIRMED" {
-		t.Errorf("ConfirmBooking() errorCode = %v, want ALREADY_CONFIRMED", confirmAgain.ErrorCode)
+	if len(keys) != 1 {
+		t.Errorf("reservation records = %d, want 1", len(keys))
 	}
 }
 
-func TestRedisReservationRepository_GetZoneAvailability(t *testing.T) {
+func TestRedisReservationRepository_ReserveSeats_SingleUseQueuePass(t *testing.T) {
 	skipIfNoIntegration(t)
 
 	ctx := context.Background()
@@ -339,35 +471,102 @@ func TestRedisReservationRepository_GetZoneAvailability(t *testing.T) {
 
 	repo := NewRedisReservationRepository(client)
 
-	// Set availability
-	zoneID := "zone-avail-test"
-	expectedSeats := int64(500)
-	if err := repo.SetZoneAvailability(ctx, zoneID, expectedSeats); err != nil {
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-single-use"
+	showID := "show-single-use"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 10); err != nil {
 		t.Fatalf("Failed to set zone availability: %v", err)
 	}
+	if err := client.Set(ctx, "show:availability:"+showID, 10, 0).Err(); err != nil {
+		t.Fatalf("Failed to set show availability: %v", err)
+	}
+	if err := client.Set(ctx, ZoneShowKey(zoneID), showID, 0).Err(); err != nil {
+		t.Fatalf("Failed to record zone show: %v", err)
+	}
+	passKey := "queue:pass:event-001:user-001"
+	if err := client.Set(ctx, passKey, "pass-001", time.Minute).Err(); err != nil {
+		t.Fatalf("Failed to store queue pass: %v", err)
+	}
 
-	// Get availability
-	available, err := repo.GetZoneAvailability(ctx, zoneID)
-	if err != nil {
-		t.Fatalf("GetZoneAvailability() error = %v", err)
+	params := ReserveParams{
+		ZoneID:     zoneID,
+		ShowID:     showID,
+		UserID:     "user-001",
+		EventID:    "event-001",
+		Quantity:   1,
+		MaxPerUser: 4,
+		TTLSeconds: 600,
+		Price:      100.00,
+		QueuePass:  "pass-001",
 	}
 
-	if available != expectedSeats {
-		t.Errorf("GetZoneAvailability() = %d, want %d", available, expectedSeats)
+	// A dry run validates the pass without consuming it
+	checkParams := params
+	checkParams.CheckOnly = true
+	result, err := repo.ReserveSeats(ctx, checkParams)
+	if err != nil || !result.Success {
+		t.Fatalf("ReserveSeats() check_only failed: %v %+v", err, result)
 	}
 
-	// Get non-existent zone
-	available, err = repo.GetZoneAvailability(ctx, "non-existent-zone")
+	result, err = repo.ReserveSeats(ctx, params)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("ReserveSeats() with a valid pass failed: %s - %s", result.ErrorCode, result.ErrorMessage)
+	}
+	if exists, _ := client.Exists(ctx, passKey).Result(); exists != 0 {
+		t.Error("queue pass should be consumed by the reservation")
+	}
+
+	// Replaying the same pass is rejected without touching inventory
+	result, err = repo.ReserveSeats(ctx, params)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if result.Success {
+		t.Fatal("ReserveSeats() second reservation with the same pass should be rejected")
+	}
+	if result.ErrorCode != "INVALID_QUEUE_PASS" {
+		t.Errorf("ReserveSeats() errorCode = %v, want INVALID_QUEUE_PASS", result.ErrorCode)
+	}
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
 	if err != nil {
 		t.Fatalf("GetZoneAvailability() error = %v", err)
 	}
+	if available != 9 {
+		t.Errorf("zone availability = %d, want 9", available)
+	}
+	showAvailable, err := repo.GetShowAvailability(ctx, showID)
+	if err != nil {
+		t.Fatalf("GetShowAvailability() error = %v", err)
+	}
+	if showAvailable != 9 {
+		t.Errorf("show availability = %d, want 9", showAvailable)
+	}
 
-	if available != 0 {
-		t.Errorf("GetZoneAvailability() for non-existent zone = %d, want 0", available)
+	// A failed reservation leaves the pass usable
+	if err := client.Set(ctx, passKey, "pass-002", time.Minute).Err(); err != nil {
+		t.Fatalf("Failed to store queue pass: %v", err)
+	}
+	params.QueuePass = "pass-002"
+	params.Quantity = 20
+	result, err = repo.ReserveSeats(ctx, params)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if result.ErrorCode != "INSUFFICIENT_STOCK" {
+		t.Errorf("ReserveSeats() errorCode = %v, want INSUFFICIENT_STOCK", result.ErrorCode)
+	}
+	if stored, _ := client.Get(ctx, passKey).Result(); stored != "pass-002" {
+		t.Errorf("queue pass after a failed reservation = %q, want pass-002", stored)
 	}
 }
 
-func TestRedisReservationRepository_ConcurrentReservations(t *testing.T) {
+func TestRedisReservationRepository_ReserveSeats_ShowValidation(t *testing.T) {
 	skipIfNoIntegration(t)
 
 	ctx := context.Background()
@@ -380,68 +579,1205 @@ func TestRedisReservationRepository_ConcurrentReservations(t *testing.T) {
 		t.Fatalf("Failed to load scripts: %v", err)
 	}
 
-	// Initialize zone with limited seats
-	zoneID := "zone-concurrent"
-	totalSeats := int64(10)
-	if err := repo.SetZoneAvailability(ctx, zoneID, totalSeats); err != nil {
-		t.Fatalf("Failed to set zone availability: %v", err)
+	// The zone is seeded under its own show, which seeds that show's counter
+	zoneID := "zone-show-validation"
+	if _, err := repo.InitZone(ctx, zoneID, "show-own", 10, 0); err != nil {
+		t.Fatalf("InitZone() error = %v", err)
 	}
-
-	// Run concurrent reservations
-	numReservations := 20
-	results := make(chan *ReserveResult, numReservations)
-
-	for i := 0; i < numReservations; i++ {
-		go func(userNum int) {
-			result, err := repo.ReserveSeats(ctx, ReserveParams{
-				ZoneID:     zoneID,
-				UserID:     "user-concurrent-" + string(rune('0'+userNum)),
-				EventID:    "event-concurrent",
-				Quantity:   1,
-				MaxPerUser: 1,
-				TTLSeconds: 600,
-				Price:      100.00,
-			})
-			if err != nil {
-				t.Logf("Reservation error for user %d: %v", userNum, err)
-				results <- nil
-				return
-			}
-			results <- result
-		}(i)
+	if err := client.Set(ctx, "show:availability:show-other", 50, 0).Err(); err != nil {
+		t.Fatalf("Failed to seed show availability: %v", err)
 	}
 
-	// Collect results
-	successCount := 0
-	insufficientCount := 0
-	for i := 0; i < numReservations; i++ {
-		result := <-results
-		if result == nil {
-			continue
-		}
-		if result.Success {
-			successCount++
-		} else if result.ErrorCode == "INSUFFICIENT_STOCK" {
-			insufficientCount++
-		}
+	params := ReserveParams{
+		ZoneID:     zoneID,
+		ShowID:     "show-other",
+		UserID:     "user-001",
+		EventID:    "event-001",
+		Quantity:   2,
+		MaxPerUser: 4,
+		TTLSeconds: 600,
+		Price:      100.00,
 	}
 
-	// Exactly 10 should succeed (since we have 10 seats)
-	if successCount != int(totalSeats) {
-		t.Errorf("Concurrent reservations: %d succeeded, want %d", successCount, totalSeats)
+	// Another show's ID can't drain that show's counter
+	result, err := repo.ReserveSeats(ctx, params)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
 	}
-
-	// The rest should fail with insufficient stock
-	if insufficientCount != numReservations-int(totalSeats) {
-		t.Errorf("Concurrent reservations: %d failed with insufficient, want %d", insufficientCount, numReservations-int(totalSeats))
+	if result.ErrorCode != pkgredis.CodeInvalidShowID {
+		t.Fatalf("ReserveSeats() errorCode = %v, want %s", result.ErrorCode, pkgredis.CodeInvalidShowID)
+	}
+	if available, _ := repo.GetShowAvailability(ctx, "show-other"); available != 50 {
+		t.Errorf("other show availability = %d, want 50", available)
 	}
 
-	// Verify final availability is 0
+	params.ShowID = "show-own"
+	result, err = repo.ReserveSeats(ctx, params)
+	if err != nil || !result.Success {
+		t.Fatalf("ReserveSeats() for the zone's own show failed: %v %+v", err, result)
+	}
+	if available, _ := repo.GetShowAvailability(ctx, "show-own"); available != 8 {
+		t.Errorf("show availability = %d, want 8", available)
+	}
+
+	// A show whose counter was never seeded is left alone rather than going negative
+	unseededZone := "zone-show-unseeded"
+	if err := repo.SetZoneAvailability(ctx, unseededZone, 10); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+	params.ZoneID = unseededZone
+	params.ShowID = "show-unseeded"
+	params.UserID = "user-002"
+	result, err = repo.ReserveSeats(ctx, params)
+	if err != nil || !result.Success {
+		t.Fatalf("ReserveSeats() for an unseeded show failed: %v %+v", err, result)
+	}
+	if exists, _ := client.Exists(ctx, "show:availability:show-unseeded").Result(); exists != 0 {
+		t.Error("unseeded show counter should not be created")
+	}
+	if release, err := repo.ReleaseSeats(ctx, result.BookingID, "user-002"); err != nil || !release.Success {
+		t.Fatalf("ReleaseSeats() failed: %v %+v", err, release)
+	}
+	if exists, _ := client.Exists(ctx, "show:availability:show-unseeded").Result(); exists != 0 {
+		t.Error("releasing an uncounted hold should not create the show counter")
+	}
+}
+
+func TestRedisReservationRepository_ReleaseSeats(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	// Initialize zone
+	zoneID := "zone-release-test"
+	initialSeats := int64(100)
+	if err := repo.SetZoneAvailability(ctx, zoneID, initialSeats); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	// Reserve seats first
+	reserveResult, err := repo.ReserveSeats(ctx, ReserveParams{
+		ZoneID:     zoneID,
+		UserID:     "user-release",
+		EventID:    "event-release",
+		Quantity:   3,
+		MaxPerUser: 10,
+		TTLSeconds: 600,
+		Price:      100.00,
+	})
+
+	if err != nil || !reserveResult.Success {
+		t.Fatalf("Failed to reserve seats: %v, %+v", err, reserveResult)
+	}
+
+	// Verify availability decreased
 	available, err := repo.GetZoneAvailability(ctx, zoneID)
 	if err != nil {
 		t.Fatalf("Failed to get availability: %v", err)
 	}
+	if available != initialSeats-3 {
+		t.Errorf("Available seats = %d, want %d", available, initialSeats-3)
+	}
+
+	// Release seats
+	releaseResult, err := repo.ReleaseSeats(ctx, reserveResult.BookingID, "user-release")
+	if err != nil {
+		t.Fatalf("ReleaseSeats() error = %v", err)
+	}
+
+	if !releaseResult.Success {
+		t.Errorf("ReleaseSeats() failed: %s - %s", releaseResult.ErrorCode, releaseResult.ErrorMessage)
+	}
+
+	// Verify seats restored
+	available, err = repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("Failed to get availability: %v", err)
+	}
+	if available != initialSeats {
+		t.Errorf("Available seats after release = %d, want %d", available, initialSeats)
+	}
+}
+
+func TestRedisReservationRepository_ScanReservations(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-scan-test"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 100); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	want := make(map[string]string)
+	for i := 0; i < 5; i++ {
+		userID := fmt.Sprintf("user-scan-%d", i)
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     userID,
+			EventID:    "event-scan",
+			Quantity:   1,
+			MaxPerUser: 10,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("Failed to reserve seats: %v, %+v", err, result)
+		}
+		want[result.BookingID] = userID
+	}
+
+	// A similarly named key outside the reservation:* pattern must not be returned
+	if err := client.Set(ctx, "reservations:unrelated", "x", time.Minute).Err(); err != nil {
+		t.Fatalf("Failed to set unrelated key: %v", err)
+	}
+
+	got := make(map[string]string)
+	err := repo.ScanReservations(ctx, 2, func(page []HeldReservation) error {
+		for _, hold := range page {
+			got[hold.BookingID] = hold.UserID
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanReservations() error = %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ScanReservations() found %d reservations, want %d", len(got), len(want))
+	}
+	for bookingID, userID := range want {
+		if got[bookingID] != userID {
+			t.Errorf("Reservation %s user = %q, want %q", bookingID, got[bookingID], userID)
+		}
+	}
+}
+
+func TestRedisReservationRepository_ConfirmBooking(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	// Initialize zone
+	zoneID := "zone-confirm-test"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 100); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	// Reserve seats first
+	reserveResult, err := repo.ReserveSeats(ctx, ReserveParams{
+		ZoneID:     zoneID,
+		UserID:     "user-confirm",
+		EventID:    "event-confirm",
+		Quantity:   2,
+		MaxPerUser: 10,
+		TTLSeconds: 600,
+		Price:      100.00,
+	})
+
+	if err != nil || !reserveResult.Success {
+		t.Fatalf("Failed to reserve seats: %v, %+v", err, reserveResult)
+	}
+
+	// Confirm booking
+	confirmResult, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-123")
+	if err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+
+	if !confirmResult.Success {
+		t.Errorf("ConfirmBooking() failed: %s - %s", confirmResult.ErrorCode, confirmResult.ErrorMessage)
+	}
+
+	if confirmResult.Status != "CONFIRMED" {
+		t.Errorf("ConfirmBooking() status = %v, want CONFIRMED", confirmResult.Status)
+	}
+
+	// Try to confirm again - should fail
+	confirmAgain, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-456")
+	if err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+
+	if confirmAgain.Success {
+		t.Error("ConfirmBooking() should fail when already confirmed")
+	}
+
+	if confirmAgain.ErrorCode != "ALREADY_CONFIRMED" {
+		t.Errorf("ConfirmBooking() errorCode = %v, want ALREADY_CONFIRMED", confirmAgain.ErrorCode)
+	}
+}
+
+func TestRedisReservationRepository_GetZoneAvailability(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	// Set availability
+	zoneID := "zone-avail-test"
+	expectedSeats := int64(500)
+	if err := repo.SetZoneAvailability(ctx, zoneID, expectedSeats); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	// Get availability
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("GetZoneAvailability() error = %v", err)
+	}
+
+	if available != expectedSeats {
+		t.Errorf("GetZoneAvailability() = %d, want %d", available, expectedSeats)
+	}
+
+	// Get non-existent zone
+	available, err = repo.GetZoneAvailability(ctx, "non-existent-zone")
+	if err != nil {
+		t.Fatalf("GetZoneAvailability() error = %v", err)
+	}
+
 	if available != 0 {
-		t.Errorf("Final availability = %d, want 0", available)
+		t.Errorf("GetZoneAvailability() for non-existent zone = %d, want 0", available)
+	}
+}
+
+func TestRedisReservationRepository_ShowAvailability(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	// Two zones of one show, with the show counter seeded as inventory sync does
+	showID := "show-avail-test"
+	zones := map[string]int64{"zone-show-a": 50, "zone-show-b": 30}
+	for zoneID, seats := range zones {
+		if err := repo.SetZoneAvailability(ctx, zoneID, seats); err != nil {
+			t.Fatalf("Failed to set zone availability: %v", err)
+		}
+		if err := client.Set(ctx, ZoneShowKey(zoneID), showID, 0).Err(); err != nil {
+			t.Fatalf("Failed to record zone show: %v", err)
+		}
+	}
+	if err := client.Set(ctx, "show:availability:"+showID, 80, 0).Err(); err != nil {
+		t.Fatalf("Failed to seed show availability: %v", err)
+	}
+
+	reserve := func(zoneID, userID string, quantity int) *ReserveResult {
+		t.Helper()
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			ShowID:     showID,
+			UserID:     userID,
+			EventID:    "event-001",
+			Quantity:   quantity,
+			MaxPerUser: 10,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil {
+			t.Fatalf("ReserveSeats() error = %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("ReserveSeats() failed: %s - %s", result.ErrorCode, result.ErrorMessage)
+		}
+		return result
+	}
+
+	r1 := reserve("zone-show-a", "user-001", 4)
+	reserve("zone-show-a", "user-002", 2)
+	r3 := reserve("zone-show-b", "user-003", 3)
+	reserve("zone-show-b", "user-004", 1)
+
+	for _, release := range []struct{ bookingID, userID string }{
+		{r1.BookingID, "user-001"},
+		{r3.BookingID, "user-003"},
+	} {
+		result, err := repo.ReleaseSeats(ctx, release.bookingID, release.userID)
+		if err != nil {
+			t.Fatalf("ReleaseSeats() error = %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("ReleaseSeats() failed: %s - %s", result.ErrorCode, result.ErrorMessage)
+		}
+	}
+
+	// A reservation without a show ID leaves the show counter alone
+	if result, err := repo.ReserveSeats(ctx, ReserveParams{
+		ZoneID:     "zone-show-a",
+		UserID:     "user-005",
+		EventID:    "event-002",
+		Quantity:   1,
+		MaxPerUser: 10,
+		TTLSeconds: 600,
+		Price:      100.00,
+	}); err != nil || !result.Success {
+		t.Fatalf("ReserveSeats() without show ID failed: %v", err)
+	}
+
+	var zoneDecrements int64
+	for zoneID, initial := range zones {
+		available, err := repo.GetZoneAvailability(ctx, zoneID)
+		if err != nil {
+			t.Fatalf("GetZoneAvailability() error = %v", err)
+		}
+		zoneDecrements += initial - available
+	}
+
+	showAvailable, err := repo.GetShowAvailability(ctx, showID)
+	if err != nil {
+		t.Fatalf("GetShowAvailability() error = %v", err)
+	}
+
+	// Net show reservations: 2 (user-002) + 1 (user-004); user-005 had no show ID
+	if showAvailable != 77 {
+		t.Errorf("GetShowAvailability() = %d, want 77", showAvailable)
+	}
+	if 80-showAvailable != zoneDecrements-1 {
+		t.Errorf("show decrements = %d, want zone decrements (excluding showless) = %d", 80-showAvailable, zoneDecrements-1)
+	}
+
+	// Unknown show
+	available, err := repo.GetShowAvailability(ctx, "non-existent-show")
+	if err != nil {
+		t.Fatalf("GetShowAvailability() error = %v", err)
+	}
+	if available != 0 {
+		t.Errorf("GetShowAvailability() for non-existent show = %d, want 0", available)
+	}
+}
+
+func TestRedisReservationRepository_InitZone(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	// Load scripts
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-init-test"
+
+	// First init sets availability
+	initialized, err := repo.InitZone(ctx, zoneID, "", 200, 0)
+	if err != nil {
+		t.Fatalf("InitZone() error = %v", err)
+	}
+	if !initialized {
+		t.Error("InitZone() = false on first init, want true")
+	}
+
+	// Simulate a reservation consuming stock before a second initializer runs
+	if err := client.Client().DecrBy(ctx, "zone:availability:"+zoneID, 5).Err(); err != nil {
+		t.Fatalf("Failed to decrement availability: %v", err)
+	}
+
+	// Second init is a no-op and must not restore the consumed seats
+	initialized, err = repo.InitZone(ctx, zoneID, "", 200, 0)
+	if err != nil {
+		t.Fatalf("InitZone() error = %v", err)
+	}
+	if initialized {
+		t.Error("InitZone() = true on second init, want false")
+	}
+
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("GetZoneAvailability() error = %v", err)
+	}
+	if available != 195 {
+		t.Errorf("GetZoneAvailability() after second init = %d, want 195", available)
+	}
+}
+
+func TestRedisReservationRepository_InitZone_SeedsShow(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	showID := "show-init-test"
+
+	for zoneID, seats := range map[string]int64{"zone-init-show-a": 40, "zone-init-show-b": 60} {
+		if _, err := repo.InitZone(ctx, zoneID, showID, seats, 0); err != nil {
+			t.Fatalf("InitZone() error = %v", err)
+		}
+		if got, _ := client.Get(ctx, ZoneShowKey(zoneID)).Result(); got != showID {
+			t.Errorf("zone %s show = %q, want %q", zoneID, got, showID)
+		}
+	}
+	// Re-initializing an existing zone doesn't count its seats twice
+	if _, err := repo.InitZone(ctx, "zone-init-show-a", showID, 40, 0); err != nil {
+		t.Fatalf("InitZone() error = %v", err)
+	}
+
+	available, err := repo.GetShowAvailability(ctx, showID)
+	if err != nil {
+		t.Fatalf("GetShowAvailability() error = %v", err)
+	}
+	if available != 100 {
+		t.Errorf("GetShowAvailability() = %d, want 100", available)
+	}
+}
+
+func TestRedisReservationRepository_InitZone_TTL(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	zoneID := "zone-init-ttl-test"
+	initialized, err := repo.InitZone(ctx, zoneID, "", 50, time.Minute)
+	if err != nil {
+		t.Fatalf("InitZone() error = %v", err)
+	}
+	if !initialized {
+		t.Error("InitZone() = false on first init, want true")
+	}
+
+	ttl, err := client.Client().TTL(ctx, "zone:availability:"+zoneID).Result()
+	if err != nil {
+		t.Fatalf("TTL error = %v", err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Errorf("zone TTL = %v, want within (0, 1m]", ttl)
+	}
+}
+
+func TestRedisReservationRepository_ConcurrentReservations(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	// Initialize zone with limited seats
+	zoneID := "zone-concurrent"
+	totalSeats := int64(10)
+	if err := repo.SetZoneAvailability(ctx, zoneID, totalSeats); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	// Run concurrent reservations
+	numReservations := 20
+	results := make(chan *ReserveResult, numReservations)
+
+	for i := 0; i < numReservations; i++ {
+		go func(userNum int) {
+			result, err := repo.ReserveSeats(ctx, ReserveParams{
+				ZoneID:     zoneID,
+				UserID:     "user-concurrent-" + string(rune('0'+userNum)),
+				EventID:    "event-concurrent",
+				Quantity:   1,
+				MaxPerUser: 1,
+				TTLSeconds: 600,
+				Price:      100.00,
+			})
+			if err != nil {
+				t.Logf("Reservation error for user %d: %v", userNum, err)
+				results <- nil
+				return
+			}
+			results <- result
+		}(i)
+	}
+
+	// Collect results
+	successCount := 0
+	insufficientCount := 0
+	for i := 0; i < numReservations; i++ {
+		result := <-results
+		if result == nil {
+			continue
+		}
+		if result.Success {
+			successCount++
+		} else if result.ErrorCode == "INSUFFICIENT_STOCK" {
+			insufficientCount++
+		}
+	}
+
+	// Exactly 10 should succeed (since we have 10 seats)
+	if successCount != int(totalSeats) {
+		t.Errorf("Concurrent reservations: %d succeeded, want %d", successCount, totalSeats)
+	}
+
+	// The rest should fail with insufficient stock
+	if insufficientCount != numReservations-int(totalSeats) {
+		t.Errorf("Concurrent reservations: %d failed with insufficient, want %d", insufficientCount, numReservations-int(totalSeats))
+	}
+
+	// Verify final availability is 0
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("Failed to get availability: %v", err)
+	}
+	if available != 0 {
+		t.Errorf("Final availability = %d, want 0", available)
+	}
+}
+
+func TestRedisReservationRepository_ReserveSpecificSeats(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-seatmap-001"
+	if err := repo.SetZoneSeats(ctx, zoneID, []string{"A1", "A2", "A3", "A4"}); err != nil {
+		t.Fatalf("SetZoneSeats() error = %v", err)
+	}
+	if err := repo.SetZoneAvailability(ctx, zoneID, 4); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	reserve := func(userID string, seatIDs ...string) *ReserveResult {
+		result, err := repo.ReserveSpecificSeats(ctx, SeatReserveParams{
+			ZoneID:     zoneID,
+			UserID:     userID,
+			EventID:    "event-seatmap-001",
+			SeatIDs:    seatIDs,
+			MaxPerUser: 4,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil {
+			t.Fatalf("ReserveSpecificSeats() error = %v", err)
+		}
+		return result
+	}
+
+	first := reserve("user-001", "A1", "A2")
+	if !first.Success {
+		t.Fatalf("ReserveSpecificSeats() failed: %s - %s", first.ErrorCode, first.ErrorMessage)
+	}
+	if first.AvailableSeats != 2 {
+		t.Errorf("AvailableSeats = %d, want 2", first.AvailableSeats)
+	}
+
+	seats, _ := client.Client().HGet(ctx, fmt.Sprintf("reservation:%s", first.BookingID), "seats").Result()
+	if seats != "A1,A2" {
+		t.Errorf("Reservation seats = %q, want A1,A2", seats)
+	}
+	holder, _ := client.Client().HGet(ctx, fmt.Sprintf("zone:seats:%s", zoneID), "A1").Result()
+	if holder != first.BookingID {
+		t.Errorf("Seat A1 holder = %q, want %q", holder, first.BookingID)
+	}
+
+	// Overlapping request holds nothing and names every conflict
+	conflict := reserve("user-002", "A2", "A3", "A1")
+	if conflict.Success || conflict.ErrorCode != pkgredis.CodeSeatTaken {
+		t.Fatalf("Expected SEAT_TAKEN, got %+v", conflict)
+	}
+	if len(conflict.ConflictingSeats) != 2 || conflict.ConflictingSeats[0] != "A2" || conflict.ConflictingSeats[1] != "A1" {
+		t.Errorf("ConflictingSeats = %v, want [A2 A1]", conflict.ConflictingSeats)
+	}
+	if holder, _ := client.Client().HGet(ctx, fmt.Sprintf("zone:seats:%s", zoneID), "A3").Result(); holder != "" {
+		t.Errorf("Seat A3 holder = %q after failed reservation, want free", holder)
+	}
+
+	tests := []struct {
+		name     string
+		seatIDs  []string
+		wantCode string
+	}{
+		{name: "unknown seat", seatIDs: []string{"A3", "Z9"}, wantCode: pkgredis.CodeSeatNotFound},
+		{name: "duplicate seat", seatIDs: []string{"A3", "A3"}, wantCode: pkgredis.CodeInvalidQuantity},
+		{name: "no seats", seatIDs: nil, wantCode: pkgredis.CodeInvalidQuantity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := reserve("user-003", tt.seatIDs...)
+			if result.Success || result.ErrorCode != tt.wantCode {
+				t.Errorf("Expected %s, got %+v", tt.wantCode, result)
+			}
+		})
+	}
+
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("Failed to get availability: %v", err)
+	}
+	if available != 2 {
+		t.Errorf("Availability = %d, want 2", available)
+	}
+}
+
+func TestRedisReservationRepository_ReserveSpecificSeats_Concurrent(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-seatmap-concurrent"
+	seatIDs := []string{"B1", "B2", "B3", "B4", "B5", "B6"}
+	if err := repo.SetZoneSeats(ctx, zoneID, seatIDs); err != nil {
+		t.Fatalf("SetZoneSeats() error = %v", err)
+	}
+
+	// Each attempt asks for an overlapping pair: B1+B2, B2+B3, ... B6+B1
+	numAttempts := 30
+	results := make(chan *ReserveResult, numAttempts)
+	for i := 0; i < numAttempts; i++ {
+		go func(n int) {
+			result, err := repo.ReserveSpecificSeats(ctx, SeatReserveParams{
+				ZoneID:     zoneID,
+				UserID:     fmt.Sprintf("user-seatmap-%d", n),
+				EventID:    "event-seatmap-concurrent",
+				SeatIDs:    []string{seatIDs[n%len(seatIDs)], seatIDs[(n+1)%len(seatIDs)]},
+				MaxPerUser: 2,
+				TTLSeconds: 600,
+				Price:      100.00,
+			})
+			if err != nil {
+				t.Logf("Reservation error for user %d: %v", n, err)
+				results <- nil
+				return
+			}
+			results <- result
+		}(i)
+	}
+
+	// Every seat is won by at most one booking
+	winners := make(map[string]string)
+	for i := 0; i < numAttempts; i++ {
+		result := <-results
+		if result == nil || !result.Success {
+			if result != nil && result.ErrorCode != pkgredis.CodeSeatTaken {
+				t.Errorf("Unexpected error code %s", result.ErrorCode)
+			}
+			continue
+		}
+		seats, _ := client.Client().HGet(ctx, fmt.Sprintf("reservation:%s", result.BookingID), "seats").Result()
+		for _, seatID := range strings.Split(seats, ",") {
+			if prev, ok := winners[seatID]; ok {
+				t.Errorf("Seat %s won by both %s and %s", seatID, prev, result.BookingID)
+			}
+			winners[seatID] = result.BookingID
+		}
+	}
+	if len(winners) == 0 {
+		t.Fatal("Expected at least one reservation to succeed")
+	}
+
+	// The seat map agrees with the winning reservations
+	seatMap, err := client.Client().HGetAll(ctx, fmt.Sprintf("zone:seats:%s", zoneID)).Result()
+	if err != nil {
+		t.Fatalf("Failed to read seat map: %v", err)
+	}
+	for seatID, holder := range seatMap {
+		if holder != winners[seatID] {
+			t.Errorf("Seat %s holder = %q, want %q", seatID, holder, winners[seatID])
+		}
+	}
+
+	// The counter SetZoneSeats seeded stays in step with the seat map
+	available, err := repo.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		t.Fatalf("Failed to get availability: %v", err)
+	}
+	if want := int64(len(seatIDs) - len(winners)); available != want {
+		t.Errorf("Availability = %d, want %d", available, want)
+	}
+}
+
+func TestRedisReservationRepository_ReleaseSpecificSeats(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-seatmap-release"
+	seatsKey := fmt.Sprintf("zone:seats:%s", zoneID)
+	if err := repo.SetZoneSeats(ctx, zoneID, []string{"C1", "C2", "C3", "C4"}); err != nil {
+		t.Fatalf("SetZoneSeats() error = %v", err)
+	}
+	if err := repo.SetZoneAvailability(ctx, zoneID, 4); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	reserved, err := repo.ReserveSpecificSeats(ctx, SeatReserveParams{
+		ZoneID:     zoneID,
+		UserID:     "user-001",
+		EventID:    "event-seatmap-release",
+		SeatIDs:    []string{"C1", "C2", "C3"},
+		MaxPerUser: 4,
+		TTLSeconds: 600,
+		Price:      100.00,
+	})
+	if err != nil || !reserved.Success {
+		t.Fatalf("ReserveSpecificSeats() failed: %v %+v", err, reserved)
+	}
+	bookingID := reserved.BookingID
+	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
+
+	// Ownership and seat checks change nothing
+	tests := []struct {
+		name      string
+		bookingID string
+		userID    string
+		seatIDs   []string
+		wantCode  string
+	}{
+		{name: "wrong user", bookingID: bookingID, userID: "user-002", wantCode: pkgredis.CodeInvalidUserID},
+		{name: "seat not held", bookingID: bookingID, userID: "user-001", seatIDs: []string{"C1", "C4"}, wantCode: pkgredis.CodeSeatNotHeld},
+		{name: "unknown booking", bookingID: "booking-missing", userID: "user-001", wantCode: pkgredis.CodeReservationNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.ReleaseSpecificSeats(ctx, tt.bookingID, tt.userID, tt.seatIDs)
+			if err != nil {
+				t.Fatalf("ReleaseSpecificSeats() error = %v", err)
+			}
+			if result.Success || result.ErrorCode != tt.wantCode {
+				t.Errorf("Expected %s, got %+v", tt.wantCode, result)
+			}
+		})
+	}
+	if holder, _ := client.Client().HGet(ctx, seatsKey, "C1").Result(); holder != bookingID {
+		t.Errorf("Seat C1 holder = %q after rejected release, want %q", holder, bookingID)
+	}
+
+	// Release a subset: C2 is freed, C1 and C3 stay held
+	result, err := repo.ReleaseSpecificSeats(ctx, bookingID, "user-001", []string{"C2"})
+	if err != nil || !result.Success {
+		t.Fatalf("ReleaseSpecificSeats() failed: %v %+v", err, result)
+	}
+	if result.AvailableSeats != 2 || result.UserReserved != 2 {
+		t.Errorf("Subset release = %+v, want AvailableSeats=2 UserReserved=2", result)
+	}
+	seatMap, _ := client.Client().HGetAll(ctx, seatsKey).Result()
+	if seatMap["C1"] != bookingID || seatMap["C2"] != "" || seatMap["C3"] != bookingID {
+		t.Errorf("Seat map after subset release = %v", seatMap)
+	}
+	reservation, _ := client.Client().HGetAll(ctx, reservationKey).Result()
+	if reservation["seats"] != "C1,C3" || reservation["quantity"] != "2" {
+		t.Errorf("Reservation after subset release: seats=%q quantity=%q, want C1,C3 and 2",
+			reservation["seats"], reservation["quantity"])
+	}
+
+	// Release the rest: every seat is free and the reservation is gone
+	result, err = repo.ReleaseSpecificSeats(ctx, bookingID, "user-001", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("ReleaseSpecificSeats() failed: %v %+v", err, result)
+	}
+	if result.AvailableSeats != 4 || result.UserReserved != 0 {
+		t.Errorf("Full release = %+v, want AvailableSeats=4 UserReserved=0", result)
+	}
+	seatMap, _ = client.Client().HGetAll(ctx, seatsKey).Result()
+	for seatID, holder := range seatMap {
+		if holder != "" {
+			t.Errorf("Seat %s holder = %q after full release, want free", seatID, holder)
+		}
+	}
+	if n, _ := client.Client().Exists(ctx, reservationKey).Result(); n != 0 {
+		t.Error("Expected reservation to be deleted after full release")
+	}
+}
+
+func TestRedisReservationRepository_ReleaseSpecificSeats_CountsOnlyFreedSeats(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-seatmap-freed"
+	seatsKey := fmt.Sprintf("zone:seats:%s", zoneID)
+	client.Client().Del(ctx, seatsKey, fmt.Sprintf("zone:availability:%s", zoneID))
+	if err := repo.SetZoneSeats(ctx, zoneID, []string{"D1", "D2", "D3"}); err != nil {
+		t.Fatalf("SetZoneSeats() error = %v", err)
+	}
+	if available, _ := repo.GetZoneAvailability(ctx, zoneID); available != 3 {
+		t.Fatalf("Seeded availability = %d, want 3", available)
+	}
+
+	reserved, err := repo.ReserveSpecificSeats(ctx, SeatReserveParams{
+		ZoneID:     zoneID,
+		UserID:     "user-001",
+		EventID:    "event-seatmap-freed",
+		SeatIDs:    []string{"D1", "D2"},
+		MaxPerUser: 4,
+		TTLSeconds: 600,
+		Price:      100.00,
+	})
+	if err != nil || !reserved.Success {
+		t.Fatalf("ReserveSpecificSeats() failed: %v %+v", err, reserved)
+	}
+
+	// D2 was reassigned behind the booking's back; releasing must not count it
+	client.Client().HSet(ctx, seatsKey, "D2", "booking-other")
+
+	result, err := repo.ReleaseSpecificSeats(ctx, reserved.BookingID, "user-001", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("ReleaseSpecificSeats() failed: %v %+v", err, result)
+	}
+	if result.AvailableSeats != 2 {
+		t.Errorf("AvailableSeats = %d after release, want 2", result.AvailableSeats)
+	}
+}
+
+func TestRedisReservationRepository_RestoreExpiredHold(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-expiry-restore"
+	eventID := "event-expiry-restore"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 10); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	reserve := func(userID string, ttlSeconds int) string {
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     userID,
+			EventID:    eventID,
+			Quantity:   3,
+			MaxPerUser: 10,
+			TTLSeconds: ttlSeconds,
+			Price:      100.00,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("ReserveSeats() failed: %v %+v", err, result)
+		}
+		return result.BookingID
+	}
+
+	// A live hold is left alone
+	liveID := reserve("user-live", 600)
+	result, err := repo.RestoreExpiredHold(ctx, liveID)
+	if err != nil {
+		t.Fatalf("RestoreExpiredHold() error = %v", err)
+	}
+	if result.Success || result.ErrorCode != pkgredis.CodeHoldActive {
+		t.Errorf("Expected %s for a live hold, got %+v", pkgredis.CodeHoldActive, result)
+	}
+
+	// A released hold is not restored a second time
+	if released, err := repo.ReleaseSeats(ctx, liveID, "user-live"); err != nil || !released.Success {
+		t.Fatalf("ReleaseSeats() failed: %v %+v", err, released)
+	}
+	result, err = repo.RestoreExpiredHold(ctx, liveID)
+	if err != nil {
+		t.Fatalf("RestoreExpiredHold() error = %v", err)
+	}
+	if result.Success || result.ErrorCode != pkgredis.CodeReservationNotFound {
+		t.Errorf("Expected %s for a released hold, got %+v", pkgredis.CodeReservationNotFound, result)
+	}
+
+	// Let a hold expire: its seats and the user's count come back once
+	expiringID := reserve("user-expiring", 1)
+	if available, _ := repo.GetZoneAvailability(ctx, zoneID); available != 7 {
+		t.Fatalf("Zone availability while held = %d, want 7", available)
+	}
+	time.Sleep(2 * time.Second)
+
+	result, err = repo.RestoreExpiredHold(ctx, expiringID)
+	if err != nil || !result.Success {
+		t.Fatalf("RestoreExpiredHold() failed: %v %+v", err, result)
+	}
+	if result.AvailableSeats != 10 || result.UserReserved != 0 {
+		t.Errorf("Restore = %+v, want AvailableSeats=10 UserReserved=0", result)
+	}
+
+	result, err = repo.RestoreExpiredHold(ctx, expiringID)
+	if err != nil {
+		t.Fatalf("RestoreExpiredHold() error = %v", err)
+	}
+	if result.Success {
+		t.Error("Expected a second restore of the same hold to be rejected")
+	}
+	if available, _ := repo.GetZoneAvailability(ctx, zoneID); available != 10 {
+		t.Errorf("Zone availability after restore = %d, want 10", available)
+	}
+}
+
+func TestRedisReservationRepository_ExpiryIndex(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	zoneID := "zone-expiry-index"
+	eventID := "event-expiry-index"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 10); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	reserve := func(userID string, ttlSeconds int) string {
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     userID,
+			EventID:    eventID,
+			Quantity:   2,
+			MaxPerUser: 10,
+			TTLSeconds: ttlSeconds,
+			Price:      100.00,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("ReserveSeats() failed: %v %+v", err, result)
+		}
+		return result.BookingID
+	}
+
+	// Reserving indexes the hold by its expiry
+	releasedID := reserve("user-released", 600)
+	member := fmt.Sprintf("%s:2:%s:user-released", releasedID, zoneID)
+	score, err := client.Client().ZScore(ctx, expiryIndexKey, member).Result()
+	if err != nil {
+		t.Fatalf("Expected expiry index entry %q: %v", member, err)
+	}
+	if wantMin := float64(time.Now().Unix() + 590); score < wantMin {
+		t.Errorf("Expiry index score = %v, want about now+600", score)
+	}
+
+	// Releasing removes it
+	if released, err := repo.ReleaseSeats(ctx, releasedID, "user-released"); err != nil || !released.Success {
+		t.Fatalf("ReleaseSeats() failed: %v %+v", err, released)
+	}
+	if n, _ := client.Client().ZCard(ctx, expiryIndexKey).Result(); n != 0 {
+		t.Errorf("Expiry index size after release = %d, want 0", n)
+	}
+
+	// Once due, the entry is returned, restored and dropped
+	expiringID := reserve("user-expiring", 1)
+	time.Sleep(2 * time.Second)
+
+	due, err := repo.DueExpiredHolds(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("DueExpiredHolds() error = %v", err)
+	}
+	if len(due) != 1 || due[0].BookingID != expiringID || due[0].Quantity != 2 || due[0].ZoneID != zoneID || due[0].UserID != "user-expiring" {
+		t.Fatalf("DueExpiredHolds() = %+v, want the expired hold", due)
+	}
+
+	result, err := repo.RestoreExpiredHold(ctx, due[0].BookingID)
+	if err != nil || !result.Success {
+		t.Fatalf("RestoreExpiredHold() failed: %v %+v", err, result)
+	}
+	if result.AvailableSeats != 10 {
+		t.Errorf("AvailableSeats after restore = %d, want 10", result.AvailableSeats)
+	}
+	if n, _ := client.Client().ZCard(ctx, expiryIndexKey).Result(); n != 0 {
+		t.Errorf("Expiry index size after restore = %d, want 0", n)
+	}
+}
+
+func TestParseExpiryEntry(t *testing.T) {
+	entry, ok := ParseExpiryEntry("booking-1:3:zone-a:user-1")
+	if !ok {
+		t.Fatal("ParseExpiryEntry() ok = false for a valid member")
+	}
+	if entry.BookingID != "booking-1" || entry.Quantity != 3 || entry.ZoneID != "zone-a" || entry.UserID != "user-1" {
+		t.Errorf("ParseExpiryEntry() = %+v", entry)
+	}
+
+	for _, member := range []string{"", "booking-1", "booking-1:x:zone-a:user-1", ":3:zone-a:user-1"} {
+		if _, ok := ParseExpiryEntry(member); ok {
+			t.Errorf("ParseExpiryEntry(%q) ok = true, want false", member)
+		}
+	}
+}
+
+// scriptResultCode matches the error code in a script's {0, code, message} return
+var scriptResultCode = regexp.MustCompile(`return \{0, "([A-Z_]+)"`)
+
+func TestRedisReservationRepository_GetEventStats(t *testing.T) {
+	skipIfNoIntegration(t)
+
+	ctx := context.Background()
+	client := getRedisClient(t)
+	defer client.Close()
+
+	repo := NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+
+	eventID := "event-stats-001"
+	for zoneID, seats := range map[string]int64{"zone-stats-a": 50, "zone-stats-b": 20} {
+		if err := repo.SetZoneAvailability(ctx, zoneID, seats); err != nil {
+			t.Fatalf("Failed to set zone availability: %v", err)
+		}
+	}
+
+	reserve := func(zoneID, userID string, quantity int) string {
+		result, err := repo.ReserveSeats(ctx, ReserveParams{
+			ZoneID:     zoneID,
+			UserID:     userID,
+			EventID:    eventID,
+			Quantity:   quantity,
+			MaxPerUser: 10,
+			TTLSeconds: 600,
+			Price:      100.00,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("ReserveSeats() failed: %v %+v", err, result)
+		}
+		return result.BookingID
+	}
+
+	reserve("zone-stats-a", "user-001", 2)
+	reserve("zone-stats-a", "user-002", 3)
+	released := reserve("zone-stats-b", "user-003", 4)
+	confirmed := reserve("zone-stats-b", "user-004", 1)
+
+	if _, err := repo.ReleaseSeats(ctx, released, "user-003"); err != nil {
+		t.Fatalf("ReleaseSeats() error = %v", err)
+	}
+	if _, err := repo.ConfirmBooking(ctx, confirmed, "user-004", "payment-001"); err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+
+	// A hold whose expiry has passed is not counted
+	client.Client().ZAdd(ctx, fmt.Sprintf("event:holds:%s", eventID), redis.Z{
+		Score:  float64(time.Now().Add(-time.Minute).Unix()),
+		Member: "expired-booking",
+	})
+
+	stats, err := repo.GetEventStats(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetEventStats() error = %v", err)
+	}
+
+	// zone-stats-a: 50-2-3, zone-stats-b: 20-1 (the released 4 are back)
+	if stats.SeatsRemaining != 64 {
+		t.Errorf("SeatsRemaining = %d, want 64", stats.SeatsRemaining)
+	}
+	if len(stats.Zones) != 2 || stats.Zones[0].ZoneID != "zone-stats-a" || stats.Zones[0].SeatsRemaining != 45 ||
+		stats.Zones[1].SeatsRemaining != 19 {
+		t.Errorf("Zones = %+v, want zone-stats-a=45 and zone-stats-b=19", stats.Zones)
+	}
+	if stats.ActiveHolds != 2 {
+		t.Errorf("ActiveHolds = %d, want 2", stats.ActiveHolds)
+	}
+	if stats.SeatsReserved != 5 {
+		t.Errorf("SeatsReserved = %d, want 5", stats.SeatsReserved)
+	}
+
+	// Released and confirmed holds were pruned from the index
+	if n, _ := client.Client().ZCard(ctx, fmt.Sprintf("event:holds:%s", eventID)).Result(); n != 3 {
+		t.Errorf("Hold index size = %d, want 3 (2 active + 1 expired)", n)
+	}
+
+	// Events with no reservations report empty stats
+	empty, err := repo.GetEventStats(ctx, "event-stats-none")
+	if err != nil {
+		t.Fatalf("GetEventStats() error = %v", err)
+	}
+	if empty.SeatsRemaining != 0 || empty.ActiveHolds != 0 || len(empty.Zones) != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
+
+func TestScripts_ResultCodesHaveHTTPMapping(t *testing.T) {
+	scripts := map[string]string{
+		scriptReserveSeats:   reserveSeatsScript,
+		scriptReleaseSeats:   releaseSeatsScript,
+		scriptConfirmBooking: confirmBookingScript,
+		scriptJoinQueue:      joinQueueScript,
+
+		scriptReserveSpecificSeats: reserveSpecificSeatsScript,
+		scriptReleaseSpecificSeats: releaseSpecificSeatsScript,
+		scriptRestoreExpiredHold:   restoreExpiredHoldScript,
+	}
+
+	for name, script := range scripts {
+		matches := scriptResultCode.FindAllStringSubmatch(script, -1)
+		if len(matches) == 0 {
+			t.Errorf("%s: found no result codes; has the return format changed?", name)
+		}
+		for _, m := range matches {
+			if _, ok := pkgredis.HTTPStatusForCode(m[1]); !ok {
+				t.Errorf("%s returns %s, which has no pkg/redis constant and HTTP mapping", name, m[1])
+			}
+		}
 	}
 }
@@ -2,6 +2,11 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
 
 // ReserveResult represents the result of a seat reservation
@@ -12,6 +17,9 @@ type ReserveResult struct {
 	UserReserved     int64
 	ErrorCode        string
 	ErrorMessage     string
+	// ConflictingSeats lists the requested seats already held by another
+	// booking when a specific-seat reservation fails with SEAT_TAKEN
+	ConflictingSeats []string
 }
 
 // ConfirmResult represents the result of confirming a booking
@@ -32,6 +40,29 @@ type ReleaseResult struct {
 	ErrorMessage    string
 }
 
+// Err returns the failure as a *pkgredis.ReserveError, or nil on success
+func (r *ReserveResult) Err() error {
+	return resultError(r.Success, r.ErrorCode, r.ErrorMessage)
+}
+
+// Err returns the failure as a *pkgredis.ReserveError, or nil on success
+func (r *ConfirmResult) Err() error {
+	return resultError(r.Success, r.ErrorCode, r.ErrorMessage)
+}
+
+// Err returns the failure as a *pkgredis.ReserveError, or nil on success
+func (r *ReleaseResult) Err() error {
+	return resultError(r.Success, r.ErrorCode, r.ErrorMessage)
+}
+
+// resultError wraps a failed script result in a *pkgredis.ReserveError
+func resultError(success bool, code, message string) error {
+	if success {
+		return nil
+	}
+	return &pkgredis.ReserveError{Code: code, Message: message}
+}
+
 // ReservationRepository defines the interface for Redis-based reservation operations
 type ReservationRepository interface {
 	// ReserveSeats atomically reserves seats using Lua script
@@ -46,17 +77,128 @@ type ReservationRepository interface {
 	// GetZoneAvailability gets the current available seats for a zone
 	GetZoneAvailability(ctx context.Context, zoneID string) (int64, error)
 
+	// GetShowAvailability gets the current available seats across all zones of a show
+	GetShowAvailability(ctx context.Context, showID string) (int64, error)
+
 	// SetZoneAvailability sets the available seats for a zone (for initialization)
 	SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error
+
+	// InitZone sets the available seats for a zone only if it is not already
+	// initialized, reporting whether it did. With a showID it also records the
+	// zone's show and adds the seats to the show-wide counter.
+	InitZone(ctx context.Context, zoneID, showID string, seats int64, ttl time.Duration) (bool, error)
+}
+
+// SeatMapRepository reserves named seats from a zone's seat map, for venues
+// with reserved seating
+type SeatMapRepository interface {
+	// SetZoneSeats adds seats to a zone's seat map as free and to the zone's
+	// availability counter; seats already in the map keep their holder
+	SetZoneSeats(ctx context.Context, zoneID string, seatIDs []string) error
+
+	// ReserveSpecificSeats atomically holds every requested seat or none of them
+	ReserveSpecificSeats(ctx context.Context, params SeatReserveParams) (*ReserveResult, error)
+
+	// ReleaseSpecificSeats frees the given seats of a specific-seat
+	// reservation, or all of its seats when seatIDs is empty
+	ReleaseSpecificSeats(ctx context.Context, bookingID, userID string, seatIDs []string) (*ReleaseResult, error)
+}
+
+// HoldExpiryRepository returns the seats of expired holds to inventory
+type HoldExpiryRepository interface {
+	// RestoreExpiredHold puts an expired hold's seats back and drops its
+	// shadow copy. Holds that were released, confirmed or already restored
+	// report RESERVATION_NOT_FOUND; holds that are still live report HOLD_ACTIVE.
+	RestoreExpiredHold(ctx context.Context, bookingID string) (*ReleaseResult, error)
+
+	// DueExpiredHolds returns up to limit expiry index entries whose hold
+	// expired at or before before, oldest first
+	DueExpiredHolds(ctx context.Context, before time.Time, limit int64) ([]ExpiryEntry, error)
+
+	// RemoveExpiryEntries drops entries from the expiry index
+	RemoveExpiryEntries(ctx context.Context, entries ...ExpiryEntry) error
+}
+
+// ExpiryEntry is a hold in the reservations:expiry index. The reserve scripts
+// store it as "booking_id:quantity:zone_id:user_id" scored by expires_at, so
+// the sweeper can see what a hold held after its reservation hash is gone.
+type ExpiryEntry struct {
+	Member    string // Raw sorted-set member
+	BookingID string
+	Quantity  int
+	ZoneID    string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// ParseExpiryEntry parses an expiry index member
+func ParseExpiryEntry(member string) (ExpiryEntry, bool) {
+	parts := strings.SplitN(member, ":", 4)
+	if len(parts) != 4 || parts[0] == "" {
+		return ExpiryEntry{}, false
+	}
+	quantity, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ExpiryEntry{}, false
+	}
+	return ExpiryEntry{
+		Member:    member,
+		BookingID: parts[0],
+		Quantity:  quantity,
+		ZoneID:    parts[2],
+		UserID:    parts[3],
+	}, true
+}
+
+// ZoneStats is a zone's remaining seats within EventStats
+type ZoneStats struct {
+	ZoneID         string
+	SeatsRemaining int64
+}
+
+// EventStats aggregates an event's reservation state from the zone set and
+// hold index the reserve script maintains
+type EventStats struct {
+	Zones          []ZoneStats
+	SeatsRemaining int64
+	SeatsReserved  int64 // Seats held by active (unexpired, unconfirmed) reservations
+	ActiveHolds    int64
+}
+
+// EventStatsRepository reads per-event reservation stats
+type EventStatsRepository interface {
+	GetEventStats(ctx context.Context, eventID string) (*EventStats, error)
 }
 
 // ReserveParams contains parameters for seat reservation
 type ReserveParams struct {
+	BookingID   string // Optional; generated when empty
 	ZoneID      string
+	ShowID      string // Optional; when set the show-wide availability counter is updated too
 	UserID      string
 	EventID     string
 	Quantity    int
 	MaxPerUser  int
 	TTLSeconds  int
 	Price       float64
+	// OverbookAllowance lets the zone be oversold by up to this many seats
+	// (0 = strict, availability never goes negative)
+	OverbookAllowance int
+	// CheckOnly runs every validation and returns the would-be result without
+	// consuming inventory; the result carries no booking ID
+	CheckOnly bool
+	// QueuePass, when set, is a single-use queue pass that must still be stored
+	// and is consumed by a successful reservation
+	QueuePass string
+}
+
+// SeatReserveParams contains parameters for a specific-seat reservation
+type SeatReserveParams struct {
+	ZoneID     string
+	UserID     string
+	EventID    string
+	SeatIDs    []string
+	MaxPerUser int
+	TTLSeconds int
+	Price      float64
 }
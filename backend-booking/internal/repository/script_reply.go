@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// scriptReply is the array every reservation script returns: {1, a, b} on
+// success and {0, error_code, error_message} on failure. Depending on the
+// client protocol and script, numbers arrive as int64 or as strings, so slots
+// are read through coercing accessors instead of type assertions.
+type scriptReply struct {
+	ok     bool
+	values []interface{}
+}
+
+// parseScriptReply validates the shape of a script reply and decodes its status
+func parseScriptReply(reply interface{}) (*scriptReply, error) {
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected script result type %T", reply)
+	}
+	if len(values) < 3 {
+		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
+	}
+
+	status, ok := toInt64(values[0])
+	if !ok || (status != 0 && status != 1) {
+		return nil, fmt.Errorf("unexpected script result status: %v", values[0])
+	}
+	return &scriptReply{ok: status == 1, values: values}, nil
+}
+
+// int returns slot i as an integer
+func (r *scriptReply) int(i int) (int64, error) {
+	n, ok := toInt64(r.values[i])
+	if !ok {
+		return 0, fmt.Errorf("unexpected value %v (%T) in script result slot %d", r.values[i], r.values[i], i)
+	}
+	return n, nil
+}
+
+// seats returns availability slot i. An overbooked zone's counter runs below
+// zero by design, but availability is never reported as negative.
+func (r *scriptReply) seats(i int) (int64, error) {
+	n, err := r.int(i)
+	if err != nil {
+		return 0, err
+	}
+	return max(n, 0), nil
+}
+
+// str returns slot i as a string
+func (r *scriptReply) str(i int) string {
+	return replyString(r.values[i])
+}
+
+// replyString converts a reply value to a string, formatting numbers
+func replyString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// errorCode and errorMessage read the {0, code, message} failure shape
+func (r *scriptReply) errorCode() string    { return r.str(1) }
+func (r *scriptReply) errorMessage() string { return r.str(2) }
+
+// ParseReserveResult decodes a reserve_seats or reserve_specific_seats reply.
+// BookingID is left for the caller, which generated it. A failed specific-seat
+// reservation may carry the conflicting seats in a fourth slot.
+func ParseReserveResult(reply interface{}) (*ReserveResult, error) {
+	parsed, err := parseScriptReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsed.ok {
+		result := &ReserveResult{
+			ErrorCode:    parsed.errorCode(),
+			ErrorMessage: parsed.errorMessage(),
+		}
+		if len(parsed.values) > 3 {
+			taken, _ := parsed.values[3].([]interface{})
+			for _, seat := range taken {
+				if seatID := replyString(seat); seatID != "" {
+					result.ConflictingSeats = append(result.ConflictingSeats, seatID)
+				}
+			}
+		}
+		return result, nil
+	}
+
+	availableSeats, err := parsed.seats(1)
+	if err != nil {
+		return nil, err
+	}
+	userReserved, err := parsed.int(2)
+	if err != nil {
+		return nil, err
+	}
+	return &ReserveResult{
+		Success:        true,
+		AvailableSeats: availableSeats,
+		UserReserved:   userReserved,
+	}, nil
+}
+
+// ParseReleaseResult decodes a release_seats, release_specific_seats or
+// restore_expired_hold reply
+func ParseReleaseResult(reply interface{}) (*ReleaseResult, error) {
+	parsed, err := parseScriptReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsed.ok {
+		return &ReleaseResult{
+			ErrorCode:    parsed.errorCode(),
+			ErrorMessage: parsed.errorMessage(),
+		}, nil
+	}
+
+	availableSeats, err := parsed.seats(1)
+	if err != nil {
+		return nil, err
+	}
+	userReserved, err := parsed.int(2)
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseResult{
+		Success:        true,
+		AvailableSeats: availableSeats,
+		UserReserved:   userReserved,
+	}, nil
+}
+
+// ParseConfirmResult decodes a confirm_booking reply
+func ParseConfirmResult(reply interface{}) (*ConfirmResult, error) {
+	parsed, err := parseScriptReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsed.ok {
+		return &ConfirmResult{
+			ErrorCode:    parsed.errorCode(),
+			ErrorMessage: parsed.errorMessage(),
+		}, nil
+	}
+	return &ConfirmResult{
+		Success:     true,
+		Status:      parsed.str(1),
+		ConfirmedAt: parsed.str(2),
+	}, nil
+}
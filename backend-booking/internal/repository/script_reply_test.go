@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReserveResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   interface{}
+		want    *ReserveResult
+		wantErr bool
+	}{
+		{
+			name:  "typed success",
+			reply: []interface{}{int64(1), int64(98), int64(2)},
+			want:  &ReserveResult{Success: true, AvailableSeats: 98, UserReserved: 2},
+		},
+		{
+			name:  "string-encoded success",
+			reply: []interface{}{"1", "98", "2"},
+			want:  &ReserveResult{Success: true, AvailableSeats: 98, UserReserved: 2},
+		},
+		{
+			name:  "overbooked zone reports no seats",
+			reply: []interface{}{int64(1), int64(-2), int64(7)},
+			want:  &ReserveResult{Success: true, AvailableSeats: 0, UserReserved: 7},
+		},
+		{
+			name:  "typed failure",
+			reply: []interface{}{int64(0), "INSUFFICIENT_STOCK", "Not enough seats available"},
+			want:  &ReserveResult{ErrorCode: "INSUFFICIENT_STOCK", ErrorMessage: "Not enough seats available"},
+		},
+		{
+			name:  "string-encoded failure",
+			reply: []interface{}{"0", []byte("USER_LIMIT_EXCEEDED"), "Limit reached"},
+			want:  &ReserveResult{ErrorCode: "USER_LIMIT_EXCEEDED", ErrorMessage: "Limit reached"},
+		},
+		{
+			name:  "failure with conflicting seats",
+			reply: []interface{}{int64(0), "SEAT_TAKEN", "Seats already held", []interface{}{"A1", "A2"}},
+			want: &ReserveResult{
+				ErrorCode:        "SEAT_TAKEN",
+				ErrorMessage:     "Seats already held",
+				ConflictingSeats: []string{"A1", "A2"},
+			},
+		},
+		{name: "not an array", reply: "OK", wantErr: true},
+		{name: "too short", reply: []interface{}{int64(1), int64(5)}, wantErr: true},
+		{name: "unknown status", reply: []interface{}{int64(2), int64(5), int64(1)}, wantErr: true},
+		{name: "non-numeric status", reply: []interface{}{"yes", int64(5), int64(1)}, wantErr: true},
+		{name: "non-numeric success slot", reply: []interface{}{int64(1), "many", int64(1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReserveResult(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseReserveResult() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReserveResult() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReserveResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReleaseResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   interface{}
+		want    *ReleaseResult
+		wantErr bool
+	}{
+		{
+			name:  "typed success",
+			reply: []interface{}{int64(1), int64(100), int64(0)},
+			want:  &ReleaseResult{Success: true, AvailableSeats: 100},
+		},
+		{
+			name:  "string-encoded success",
+			reply: []interface{}{"1", "100", "3"},
+			want:  &ReleaseResult{Success: true, AvailableSeats: 100, UserReserved: 3},
+		},
+		{
+			name:  "failure",
+			reply: []interface{}{int64(0), "ALREADY_RELEASED", "Reservation already released"},
+			want:  &ReleaseResult{ErrorCode: "ALREADY_RELEASED", ErrorMessage: "Reservation already released"},
+		},
+		{
+			name:  "string-encoded failure",
+			reply: []interface{}{"0", "RESERVATION_NOT_FOUND", "Reservation not found"},
+			want:  &ReleaseResult{ErrorCode: "RESERVATION_NOT_FOUND", ErrorMessage: "Reservation not found"},
+		},
+		{name: "nil reply", reply: nil, wantErr: true},
+		{name: "missing slots", reply: []interface{}{int64(0)}, wantErr: true},
+		{name: "non-numeric success slot", reply: []interface{}{int64(1), int64(100), nil}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReleaseResult(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseReleaseResult() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReleaseResult() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReleaseResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfirmResult(t *testing.T) {
+	got, err := ParseConfirmResult([]interface{}{"1", "CONFIRMED", int64(1760000000)})
+	if err != nil {
+		t.Fatalf("ParseConfirmResult() error = %v", err)
+	}
+	want := &ConfirmResult{Success: true, Status: "CONFIRMED", ConfirmedAt: "1760000000"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseConfirmResult() = %+v, want %+v", got, want)
+	}
+}
@@ -5,6 +5,8 @@
 
     Key Structure:
     - KEYS[1]: reservation:{booking_id}              - Reservation record (hash)
+    - KEYS[2]: hold:shadow:{booking_id}              - Copy of the hold kept for expiry (hash)
+    - KEYS[3]: reservations:expiry                   - Holds by expires_at (zset)
 
     Arguments:
     - ARGV[1]: booking_id        - Booking ID (for validation)
@@ -24,6 +26,8 @@
 --]]
 
 local reservation_key = KEYS[1]
+local hold_shadow_key = KEYS[2]
+local expiry_index_key = KEYS[3]
 
 local booking_id = ARGV[1]
 local user_id = ARGV[2]
@@ -77,5 +81,10 @@ redis.call("HSET", reservation_key,
 -- 2. Remove TTL - make reservation permanent
 redis.call("PERSIST", reservation_key)
 
+-- 3. A confirmed hold is never restored to inventory
+redis.call("DEL", hold_shadow_key)
+redis.call("ZREM", expiry_index_key,
+    booking_id .. ":" .. reservation_data["quantity"] .. ":" .. reservation_data["zone_id"] .. ":" .. user_id)
+
 -- Return success with confirmation timestamp
 return {1, "CONFIRMED", confirmed_at}
@@ -0,0 +1,29 @@
+--[[
+    Get Queue Position Lua Script
+    =============================
+    Reads a user's position and the queue size in one atomic step, so the
+    position is always the compacted rank among users still waiting (leavers
+    and released users no longer count) and never exceeds the reported total.
+
+    Key Structure:
+    - KEYS[1]: queue:{event_id}              - Sorted Set (score = timestamp, member = user_id)
+
+    Arguments:
+    - ARGV[1]: user_id           - User ID
+
+    Returns:
+    - In queue: {1, position, total_in_queue}  (position is 1-indexed)
+    - Not in queue: {0, 0, total_in_queue}
+--]]
+
+local queue_key = KEYS[1]
+local user_id = ARGV[1]
+
+local rank = redis.call("ZRANK", queue_key, user_id)
+local total = redis.call("ZCARD", queue_key)
+
+if not rank then
+    return {0, 0, total}
+end
+
+return {1, rank + 1, total}
@@ -0,0 +1,59 @@
+--[[
+    Init Zone Lua Script
+    ====================
+    Atomically initializes a zone's available seats only if the zone has not
+    been initialized yet, so concurrent initializers cannot overwrite stock
+    that is already being reserved against.
+
+    Key Structure:
+    - KEYS[1]: zone:availability:{zone_id}           - Available seats count (string/integer)
+    - KEYS[2]: zone:show:{zone_id}                   - Show the zone belongs to (optional)
+    - KEYS[3]: show:availability:{show_id}           - Available seats across the show's zones
+                                                       (optional, passed with KEYS[2])
+
+    Arguments:
+    - ARGV[1]: seats             - Initial available seats
+    - ARGV[2]: ttl_seconds       - Key TTL in seconds (0 = no expiry)
+    - ARGV[3]: show_id           - Show the zone belongs to (optional)
+
+    Show counter:
+    When show_id is set, initializing the zone also records its show and adds
+    its seats to the show-wide counter, so the counter always covers exactly
+    the zones whose show is recorded. An existing zone changes neither.
+
+    Returns:
+    - 1 if the zone was initialized
+    - 0 if the zone already existed (left unchanged)
+--]]
+
+local zone_availability_key = KEYS[1]
+local zone_show_key = KEYS[2]
+local show_availability_key = KEYS[3]
+
+local seats = tonumber(ARGV[1])
+local ttl_seconds = tonumber(ARGV[2]) or 0
+local show_id = ARGV[3] or ""
+
+if seats == nil or seats < 0 then
+    return redis.error_reply("INVALID_SEATS: seats must be a non-negative integer")
+end
+
+local ok
+if ttl_seconds > 0 then
+    ok = redis.call("SET", zone_availability_key, seats, "NX", "EX", ttl_seconds)
+else
+    ok = redis.call("SET", zone_availability_key, seats, "NX")
+end
+
+if ok then
+    if show_id ~= "" and zone_show_key and show_availability_key then
+        if ttl_seconds > 0 then
+            redis.call("SET", zone_show_key, show_id, "EX", ttl_seconds)
+        else
+            redis.call("SET", zone_show_key, show_id)
+        end
+        redis.call("INCRBY", show_availability_key, seats)
+    end
+    return 1
+end
+return 0
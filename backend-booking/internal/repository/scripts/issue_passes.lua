@@ -0,0 +1,46 @@
+--[[
+    Issue Queue Passes Lua Script
+    =============================
+    Promotes a batch of users from the front of an event queue in one atomic
+    step: each candidate still waiting is removed from the queue, has its
+    queue info deleted and receives its queue pass with a TTL.
+
+    Queue passes are signed JWTs, so the caller reads the lowest-score users,
+    signs a pass for each and passes them here in queue order. Candidates that
+    left or were promoted by another worker in the meantime are skipped, so a
+    user is never promoted twice.
+
+    Key Structure:
+    - KEYS[1]:     queue:{event_id}                - Sorted Set (score = timestamp, member = user_id)
+    - KEYS[2i]:    queue:user:{event_id}:{user_id} - Hash, queue info of candidate i
+    - KEYS[2i+1]:  queue:pass:{event_id}:{user_id} - String, queue pass of candidate i
+
+    Arguments:
+    - ARGV[1]:     ttl_seconds       - Queue pass TTL
+    - ARGV[2i]:    user_id           - Candidate i, in queue order
+    - ARGV[2i+1]:  queue_pass        - Signed queue pass for candidate i
+
+    Returns:
+    - {user_id, ...} - Promoted user IDs in queue order
+--]]
+
+local queue_key = KEYS[1]
+local ttl_seconds = tonumber(ARGV[1])
+
+if not ttl_seconds or ttl_seconds <= 0 then
+    return redis.error_reply("invalid ttl_seconds")
+end
+
+local promoted = {}
+local candidates = (#ARGV - 1) / 2
+
+for i = 1, candidates do
+    local user_id = ARGV[2 * i]
+    if redis.call("ZREM", queue_key, user_id) == 1 then
+        redis.call("DEL", KEYS[2 * i])
+        redis.call("SET", KEYS[2 * i + 1], ARGV[2 * i + 1], "EX", ttl_seconds)
+        promoted[#promoted + 1] = user_id
+    end
+end
+
+return promoted
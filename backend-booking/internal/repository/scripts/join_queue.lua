@@ -6,13 +6,15 @@
     Key Structure:
     - KEYS[1]: queue:{event_id}              - Sorted Set (score = timestamp, member = user_id)
     - KEYS[2]: queue:user:{event_id}:{user_id} - Hash with user queue info
+    - KEYS[3]: queue:capacity:{event_id}     - Per-event queue capacity (optional string/integer)
 
     Arguments:
     - ARGV[1]: user_id           - User ID
     - ARGV[2]: event_id          - Event ID
     - ARGV[3]: token             - Unique queue token
     - ARGV[4]: ttl_seconds       - TTL for queue entry (default 1800 = 30 min)
-    - ARGV[5]: max_queue_size    - Maximum queue size (0 = unlimited)
+    - ARGV[5]: max_queue_size    - Default maximum queue size (0 = unlimited),
+                                   overridden by a positive per-event capacity
 
     Returns:
     - Success: {1, position, total_in_queue, joined_at_timestamp}
@@ -25,6 +27,7 @@
 
 local queue_key = KEYS[1]
 local user_queue_key = KEYS[2]
+local capacity_key = KEYS[3]
 
 local user_id = ARGV[1]
 local event_id = ARGV[2]
@@ -41,6 +44,14 @@ if existing_score then
     return {0, "ALREADY_IN_QUEUE", "User is already in queue at position " .. (position + 1)}
 end
 
+-- A per-event capacity takes precedence over the default limit
+if capacity_key then
+    local capacity = tonumber(redis.call("GET", capacity_key))
+    if capacity and capacity > 0 then
+        max_queue_size = capacity
+    end
+end
+
 -- Check queue size limit
 if max_queue_size > 0 then
     local current_size = redis.call("ZCARD", queue_key)
@@ -0,0 +1,50 @@
+--[[
+    Leave Queue Lua Script
+    ======================
+    Verifies the user's queue token and removes them from the queue in one
+    atomic step, returning the position they held and the queue size after
+    they left so both describe the same queue state.
+
+    Key Structure:
+    - KEYS[1]: queue:{event_id}              - Sorted Set (score = timestamp, member = user_id)
+    - KEYS[2]: queue:user:{event_id}:{user_id} - Hash with user queue info
+
+    Arguments:
+    - ARGV[1]: user_id           - User ID
+    - ARGV[2]: token             - Queue token issued by join_queue
+
+    Returns:
+    - Success: {1, previous_position, queue_size_after}  (position is 1-indexed)
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - NOT_IN_QUEUE: User has no queue entry or is no longer waiting
+    - INVALID_TOKEN: Token does not match the user's queue entry
+--]]
+
+local queue_key = KEYS[1]
+local user_queue_key = KEYS[2]
+
+local user_id = ARGV[1]
+local token = ARGV[2]
+
+local stored_token = redis.call("HGET", user_queue_key, "token")
+if not stored_token then
+    return {0, "NOT_IN_QUEUE", "User is not in queue"}
+end
+
+if stored_token ~= token then
+    return {0, "INVALID_TOKEN", "Invalid queue token"}
+end
+
+local rank = redis.call("ZRANK", queue_key, user_id)
+if not rank then
+    return {0, "NOT_IN_QUEUE", "User is not in queue"}
+end
+
+redis.call("ZREM", queue_key, user_id)
+redis.call("DEL", user_queue_key)
+
+local total = redis.call("ZCARD", queue_key)
+
+return {1, rank + 1, total}
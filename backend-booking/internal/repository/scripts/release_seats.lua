@@ -7,6 +7,10 @@
     - KEYS[1]: zone:availability:{zone_id}           - Available seats count (string/integer)
     - KEYS[2]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
     - KEYS[3]: reservation:{booking_id}              - Reservation record (hash)
+    - KEYS[4]: hold:shadow:{booking_id}              - Copy of the hold kept for expiry (hash)
+    - KEYS[5]: reservations:expiry                   - Holds by expires_at (zset)
+    - KEYS[6]: show:availability:{show_id}           - Available seats across the show's zones
+                                                       (optional, passed when the hold was counted against the show)
 
     Arguments:
     - ARGV[1]: booking_id        - Booking ID (for validation)
@@ -26,6 +30,9 @@
 local zone_availability_key = KEYS[1]
 local user_reservations_key = KEYS[2]
 local reservation_key = KEYS[3]
+local hold_shadow_key = KEYS[4]
+local expiry_index_key = KEYS[5]
+local show_availability_key = KEYS[6]
 
 local booking_id = ARGV[1]
 local user_id = ARGV[2]
@@ -69,7 +76,12 @@ end
 -- 1. Increment seats back to availability (INCRBY)
 local new_available = redis.call("INCRBY", zone_availability_key, quantity)
 
--- 2. Decrement user's reserved count
+-- 2. Return the same seats to the show-wide counter
+if show_availability_key then
+    redis.call("INCRBY", show_availability_key, quantity)
+end
+
+-- 3. Decrement user's reserved count
 local current_user_reserved = redis.call("GET", user_reservations_key)
 current_user_reserved = tonumber(current_user_reserved) or 0
 
@@ -87,8 +99,10 @@ else
     redis.call("DEL", user_reservations_key)
 end
 
--- 3. Delete reservation record
-redis.call("DEL", reservation_key)
+-- 4. Delete reservation record, its shadow and its expiry entry, so expiry doesn't release it again
+redis.call("DEL", reservation_key, hold_shadow_key)
+redis.call("ZREM", expiry_index_key,
+    booking_id .. ":" .. quantity .. ":" .. reservation_data["zone_id"] .. ":" .. user_id)
 
 -- Return success with new available seats and user's new reserved count
 return {1, new_available, new_user_reserved}
@@ -0,0 +1,174 @@
+--[[
+    Release Specific Seats Lua Script
+    =================================
+    Atomically frees seats held by a specific-seat reservation back to the
+    zone's seat map. Either some of the booking's seats or all of them may be
+    released.
+
+    Key Structure:
+    - KEYS[1]: reservation:{booking_id}               - Reservation record (hash)
+    - KEYS[2]: zone:seats:{zone_id}                   - Seat map (hash of seat_id -> holding booking_id, "" when free)
+    - KEYS[3]: zone:availability:{zone_id}            - Available seats count (seeded by set_zone_seats)
+    - KEYS[4]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
+    - KEYS[5]: hold:shadow:{booking_id}               - Copy of the hold kept for expiry (hash)
+    - KEYS[6]: reservations:expiry                    - Holds by expires_at (zset)
+
+    Arguments:
+    - ARGV[1]: booking_id        - Booking ID (for validation)
+    - ARGV[2]: user_id           - User ID (for validation)
+    - ARGV[3..]: seat_ids        - Seats to release (optional; none means every held seat)
+
+    Returns:
+    - Success: {1, new_available_seats, new_user_reserved}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - RESERVATION_NOT_FOUND: Reservation record does not exist
+    - INVALID_BOOKING_ID: Booking ID does not match
+    - INVALID_USER_ID: User ID does not match
+    - ALREADY_RELEASED: Reservation already released or confirmed
+    - INVALID_QUANTITY: Reservation holds no specific seats, or a seat is listed twice
+    - SEAT_NOT_HELD: A requested seat is not held by this booking
+
+    Releasing a subset rewrites the reservation's "seats" and "quantity" fields
+    to the seats still held; releasing the last seat deletes the reservation,
+    as release_seats does. Every seat is validated before any is freed. Only
+    seats the map still ties to the booking go back to the zone counter.
+--]]
+
+local reservation_key = KEYS[1]
+local zone_seats_key = KEYS[2]
+local zone_availability_key = KEYS[3]
+local user_reservations_key = KEYS[4]
+local hold_shadow_key = KEYS[5]
+local expiry_index_key = KEYS[6]
+
+local booking_id = ARGV[1]
+local user_id = ARGV[2]
+
+-- Get reservation record
+local reservation = redis.call("HGETALL", reservation_key)
+if #reservation == 0 then
+    return {0, "RESERVATION_NOT_FOUND", "Reservation does not exist or has expired"}
+end
+
+-- Convert HGETALL result to table
+local reservation_data = {}
+for i = 1, #reservation, 2 do
+    reservation_data[reservation[i]] = reservation[i + 1]
+end
+
+-- Validate booking_id
+if reservation_data["booking_id"] ~= booking_id then
+    return {0, "INVALID_BOOKING_ID", "Booking ID does not match"}
+end
+
+-- Validate user_id
+if reservation_data["user_id"] ~= user_id then
+    return {0, "INVALID_USER_ID", "User ID does not match"}
+end
+
+-- Check if already released or confirmed
+local status = reservation_data["status"]
+if status ~= "reserved" then
+    return {0, "ALREADY_RELEASED", "Reservation status is '" .. (status or "unknown") .. "', cannot release"}
+end
+
+-- Seats the booking holds, in reservation order
+local held = {}
+local is_held = {}
+for seat_id in string.gmatch(reservation_data["seats"] or "", "[^,]+") do
+    held[#held + 1] = seat_id
+    is_held[seat_id] = true
+end
+if #held == 0 then
+    return {0, "INVALID_QUANTITY", "Reservation holds no specific seats"}
+end
+
+-- Seats to release; none requested means all of them
+local release = {}
+local releasing = {}
+for i = 3, #ARGV do
+    local seat_id = ARGV[i]
+    if releasing[seat_id] then
+        return {0, "INVALID_QUANTITY", "Seat " .. seat_id .. " is listed more than once"}
+    end
+    if not is_held[seat_id] then
+        return {0, "SEAT_NOT_HELD", "Seat " .. seat_id .. " is not held by this booking"}
+    end
+    release[#release + 1] = seat_id
+    releasing[seat_id] = true
+end
+if #release == 0 then
+    release = held
+    for _, seat_id in ipairs(held) do
+        releasing[seat_id] = true
+    end
+end
+local quantity = #release
+
+-- === ATOMIC RELEASE ===
+
+-- 1. Free the seats, leaving any seat the map no longer ties to this booking alone
+local holders = redis.call("HMGET", zone_seats_key, unpack(release))
+local freed = {}
+local freed_count = 0
+for i, holder in ipairs(holders) do
+    if holder == booking_id then
+        freed[#freed + 1] = release[i]
+        freed[#freed + 1] = ""
+        freed_count = freed_count + 1
+    end
+end
+if #freed > 0 then
+    redis.call("HSET", zone_seats_key, unpack(freed))
+end
+
+-- 2. Return the freed seats to the zone counter
+local new_available = 0
+if redis.call("EXISTS", zone_availability_key) == 1 then
+    new_available = redis.call("INCRBY", zone_availability_key, freed_count)
+end
+
+-- 3. Decrement user's reserved count
+local current_user_reserved = redis.call("GET", user_reservations_key)
+current_user_reserved = tonumber(current_user_reserved) or 0
+
+local new_user_reserved = current_user_reserved - quantity
+if new_user_reserved < 0 then
+    new_user_reserved = 0
+end
+
+if new_user_reserved > 0 then
+    redis.call("SET", user_reservations_key, new_user_reserved, "KEEPTTL")
+else
+    redis.call("DEL", user_reservations_key)
+end
+
+-- 4. Shrink the reservation, its shadow and its expiry entry to the seats still held, or delete them
+local zone_id = reservation_data["zone_id"]
+redis.call("ZREM", expiry_index_key, booking_id .. ":" .. #held .. ":" .. zone_id .. ":" .. user_id)
+local remaining = {}
+for _, seat_id in ipairs(held) do
+    if not releasing[seat_id] then
+        remaining[#remaining + 1] = seat_id
+    end
+end
+if #remaining > 0 then
+    redis.call("HSET", reservation_key,
+        "seats", table.concat(remaining, ","),
+        "quantity", #remaining
+    )
+    if redis.call("EXISTS", hold_shadow_key) == 1 then
+        redis.call("HSET", hold_shadow_key,
+            "seats", table.concat(remaining, ","),
+            "quantity", #remaining
+        )
+    end
+    redis.call("ZADD", expiry_index_key, reservation_data["expires_at"],
+        booking_id .. ":" .. #remaining .. ":" .. zone_id .. ":" .. user_id)
+else
+    redis.call("DEL", reservation_key, hold_shadow_key)
+end
+
+return {1, new_available, new_user_reserved}
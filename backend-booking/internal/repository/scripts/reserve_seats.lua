@@ -7,6 +7,14 @@
     - KEYS[1]: zone:availability:{zone_id}      - Available seats count (string/integer)
     - KEYS[2]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
     - KEYS[3]: reservation:{booking_id}         - Reservation record (hash)
+    - KEYS[4]: event:zones:{event_id}           - Zones reserved against for the event (set)
+    - KEYS[5]: event:holds:{event_id}           - Active holds for the event (zset of booking_id by expires_at)
+    - KEYS[6]: hold:shadow:{booking_id}         - Copy of the hold that outlives the reservation's TTL (hash)
+    - KEYS[7]: reservations:expiry              - Holds by expires_at (zset of booking_id:quantity:zone_id:user_id)
+    - KEYS[8]: show:availability:{show_id}      - Available seats across the show's zones (optional)
+    - KEYS[9]: zone:show:{zone_id}              - Show the zone belongs to (passed with the show key)
+    - KEYS[8] or KEYS[10]: queue:pass:{event_id}:{user_id} - Single-use queue pass (optional,
+      follows the show keys when both are passed)
     
     Arguments:
     - ARGV[1]: quantity           - Number of seats to reserve
@@ -15,12 +23,17 @@
     - ARGV[4]: booking_id         - Booking ID (for reservation record)
     - ARGV[5]: zone_id            - Zone ID
     - ARGV[6]: event_id           - Event ID
-    - ARGV[7]: show_id            - Show ID
+    - ARGV[7]: show_id            - Show ID (the show key is only passed when set)
     - ARGV[8]: unit_price         - Price per seat
     - ARGV[9]: ttl_seconds        - Reservation TTL (default 600 = 10 min)
+    - ARGV[10]: overbook_allowance - Seats the zone may be oversold by (optional, default 0)
+    - ARGV[11]: check_only        - "1" to validate without reserving (optional, default "0")
+    - ARGV[12]: queue_pass        - Single-use queue pass to consume (optional; the pass key is
+      only passed when set)
     
     Returns:
     - Success: {1, remaining_seats, total_user_reserved}
+      (with check_only, the values the reservation would produce; nothing is written)
     - Error: {0, error_code, error_message}
     
     Error Codes:
@@ -28,11 +41,60 @@
     - USER_LIMIT_EXCEEDED: User has reached max reservation limit
     - INVALID_QUANTITY: Quantity must be positive
     - ZONE_NOT_FOUND: Zone availability key not found
+    - INVALID_QUEUE_PASS: Queue pass is missing, expired or already used
+    - INVALID_USER_ID: booking_id already holds another user's reservation
+    - INVALID_SHOW_ID: The zone belongs to a different show than show_id
+
+    Retries:
+    A reservation for a booking_id that already has one (a retry whose first
+    attempt ran but whose reply was lost) returns success with the current
+    counters and changes nothing, so seats are never taken twice for one booking.
+
+    Show counter:
+    show_id comes from the client, so it is checked against the show the zone
+    was seeded under and a mismatch is rejected. The show-wide counter is only
+    decremented when both the zone's show and the counter were seeded; a zone
+    seeded without its show leaves the counter alone rather than driving an
+    unseeded key negative. The reservation records whether it was counted in
+    show_counted, so release and expiry return seats to the counter only then.
+
+    Overbooking:
+    With a positive overbook_allowance the check becomes
+    available + overbook_allowance >= quantity, and the real counter is still
+    decremented, so the counter may go negative by up to the allowance. The
+    reply carries the raw counter; the repository never reports it below
+    zero. With the default of 0 the counter never goes below zero.
+
+    Single-use queue passes:
+    When queue_pass is set it must match the stored pass, and a successful
+    reservation deletes it in the same step, so a pass can't be replayed for a
+    second reservation. A failed or check_only attempt leaves the pass intact.
+
+    Event stats:
+    A successful reservation adds its zone to the event's zone set and its
+    booking to the event's hold index, so dashboards can aggregate an event
+    without scanning reservation:* keys. Holds past their expiry are pruned
+    from the index here; released and confirmed holds are pruned by readers.
+
+    Expiry:
+    The reservation hash expires with the hold, taking its quantity with it.
+    A shadow copy of the hold is kept a day longer so the expiry worker can
+    put the seats back once Redis reports the reservation expired; release
+    and confirm delete the shadow, since those holds must not be restored.
+    The hold is also indexed in reservations:expiry by its expiry time, so a
+    sweeper can find due holds even when no expiry notification arrives.
 --]]
 
 local zone_availability_key = KEYS[1]
 local user_reservations_key = KEYS[2]
 local reservation_key = KEYS[3]
+local event_zones_key = KEYS[4]
+local event_holds_key = KEYS[5]
+local hold_shadow_key = KEYS[6]
+local expiry_index_key = KEYS[7]
+local show_availability_key = nil
+local zone_show_key = nil
+local queue_pass_key = nil
 
 local quantity = tonumber(ARGV[1])
 local max_per_user = tonumber(ARGV[2])
@@ -43,12 +105,60 @@ local event_id = ARGV[6]
 local show_id = ARGV[7]
 local unit_price = ARGV[8]
 local ttl_seconds = tonumber(ARGV[9]) or 600
+local overbook_allowance = tonumber(ARGV[10]) or 0
+local check_only = ARGV[11] == "1"
+local queue_pass = ARGV[12] or ""
+
+-- Optional keys follow the required ones in a fixed order
+local next_key = 8
+if show_id and show_id ~= "" then
+    show_availability_key = KEYS[next_key]
+    zone_show_key = KEYS[next_key + 1]
+    next_key = next_key + 2
+end
+if queue_pass ~= "" then
+    queue_pass_key = KEYS[next_key]
+end
 
 -- Validate quantity
 if not quantity or quantity <= 0 then
     return {0, "INVALID_QUANTITY", "Quantity must be a positive number"}
 end
 
+-- A retry of a reservation that already went through replays it; its queue
+-- pass was consumed by the first attempt, so this comes before the pass check
+local existing_user = redis.call("HGET", reservation_key, "user_id")
+if existing_user then
+    if existing_user ~= user_id then
+        return {0, "INVALID_USER_ID", "Booking ID belongs to another reservation"}
+    end
+    return {1, tonumber(redis.call("GET", zone_availability_key)) or 0,
+        tonumber(redis.call("GET", user_reservations_key)) or 0}
+end
+
+-- A single-use pass must still be stored; a consumed or expired pass is gone
+if queue_pass_key then
+    if redis.call("GET", queue_pass_key) ~= queue_pass then
+        return {0, "INVALID_QUEUE_PASS", "Queue pass is invalid, expired or already used"}
+    end
+end
+
+-- The zone must belong to the requested show; count against the show only
+-- when the zone's show and the show counter have both been seeded
+local show_counted = false
+if show_availability_key then
+    local zone_show = redis.call("GET", zone_show_key)
+    if zone_show and zone_show ~= show_id then
+        return {0, "INVALID_SHOW_ID", "Zone does not belong to the show"}
+    end
+    show_counted = zone_show ~= false and redis.call("EXISTS", show_availability_key) == 1
+end
+
+-- A negative allowance would make the check stricter than the stock; treat it as none
+if overbook_allowance < 0 then
+    overbook_allowance = 0
+end
+
 -- Get current available seats
 local available = redis.call("GET", zone_availability_key)
 if not available then
@@ -56,8 +166,8 @@ if not available then
 end
 available = tonumber(available)
 
--- Check seat availability
-if available < quantity then
+-- Check seat availability (including any overbooking allowance)
+if available + overbook_allowance < quantity then
     return {0, "INSUFFICIENT_STOCK", "Not enough seats available. Available: " .. available .. ", Requested: " .. quantity}
 end
 
@@ -72,18 +182,28 @@ if max_per_user and max_per_user > 0 then
     end
 end
 
+-- Dry run: every check passed, report the would-be result without writing
+if check_only then
+    return {1, available - quantity, user_reserved + quantity}
+end
+
 -- === ATOMIC RESERVATION ===
 
 -- 1. Deduct seats from availability
 local remaining = redis.call("DECRBY", zone_availability_key, quantity)
 
--- 2. Increment user's reserved count for this event
+-- 2. Deduct the same seats from the show-wide counter
+if show_counted then
+    redis.call("DECRBY", show_availability_key, quantity)
+end
+
+-- 3. Increment user's reserved count for this event
 local new_user_reserved = redis.call("INCRBY", user_reservations_key, quantity)
 
--- 3. Set expiry on user reservation key (same as booking TTL + buffer)
+-- 4. Set expiry on user reservation key (same as booking TTL + buffer)
 redis.call("EXPIRE", user_reservations_key, ttl_seconds + 60)
 
--- 4. Create reservation record
+-- 5. Create reservation record
 local timestamp = redis.call("TIME")
 local created_at = timestamp[1] .. "." .. timestamp[2]
 
@@ -93,6 +213,7 @@ redis.call("HSET", reservation_key,
     "zone_id", zone_id,
     "event_id", event_id,
     "show_id", show_id,
+    "show_counted", show_counted and "1" or "0",
     "quantity", quantity,
     "unit_price", unit_price,
     "status", "reserved",
@@ -100,8 +221,32 @@ redis.call("HSET", reservation_key,
     "expires_at", timestamp[1] + ttl_seconds
 )
 
--- 5. Set TTL on reservation
+-- 6. Set TTL on reservation, keeping a shadow copy for restoring it on expiry
 redis.call("EXPIRE", reservation_key, ttl_seconds)
+redis.call("HSET", hold_shadow_key,
+    "booking_id", booking_id,
+    "user_id", user_id,
+    "zone_id", zone_id,
+    "event_id", event_id,
+    "show_id", show_id,
+    "show_counted", show_counted and "1" or "0",
+    "quantity", quantity
+)
+redis.call("EXPIRE", hold_shadow_key, ttl_seconds + 86400)
+
+-- 7. Consume the single-use queue pass
+if queue_pass_key then
+    redis.call("DEL", queue_pass_key)
+end
+
+-- 8. Index the hold for event stats, dropping holds that have expired
+redis.call("SADD", event_zones_key, zone_id)
+redis.call("ZADD", event_holds_key, timestamp[1] + ttl_seconds, booking_id)
+redis.call("ZREMRANGEBYSCORE", event_holds_key, "-inf", "(" .. timestamp[1])
+
+-- 9. Index the hold for the expiry sweeper
+redis.call("ZADD", expiry_index_key, timestamp[1] + ttl_seconds,
+    booking_id .. ":" .. quantity .. ":" .. zone_id .. ":" .. user_id)
 
 -- Return success with remaining seats and user's total reserved
 return {1, remaining, new_user_reserved}
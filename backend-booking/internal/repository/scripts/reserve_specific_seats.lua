@@ -0,0 +1,167 @@
+--[[
+    Reserve Specific Seats Lua Script
+    =================================
+    Atomically reserves named seats from a zone's seat map, for venues with
+    reserved seating.
+
+    Key Structure:
+    - KEYS[1]: zone:seats:{zone_id}                   - Seat map (hash of seat_id -> holding booking_id, "" when free)
+    - KEYS[2]: zone:availability:{zone_id}            - Available seats count (seeded by set_zone_seats)
+    - KEYS[3]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
+    - KEYS[4]: reservation:{booking_id}               - Reservation record (hash)
+    - KEYS[5]: event:zones:{event_id}                 - Zones reserved against for the event (set)
+    - KEYS[6]: event:holds:{event_id}                 - Active holds for the event (zset of booking_id by expires_at)
+    - KEYS[7]: hold:shadow:{booking_id}               - Copy of the hold that outlives the reservation's TTL (hash)
+    - KEYS[8]: reservations:expiry                    - Holds by expires_at (zset of booking_id:quantity:zone_id:user_id)
+
+    Arguments:
+    - ARGV[1]: max_per_user       - Maximum seats allowed per user per event
+    - ARGV[2]: user_id            - User ID
+    - ARGV[3]: booking_id         - Booking ID (for reservation record)
+    - ARGV[4]: zone_id            - Zone ID
+    - ARGV[5]: event_id           - Event ID
+    - ARGV[6]: unit_price         - Price per seat
+    - ARGV[7]: ttl_seconds        - Reservation TTL (default 600 = 10 min)
+    - ARGV[8..]: seat_ids         - Seats to reserve (at least one, no duplicates)
+
+    Returns:
+    - Success: {1, remaining_seats, total_user_reserved}
+    - Error: {0, error_code, error_message}
+    - SEAT_TAKEN: {0, "SEAT_TAKEN", error_message, {conflicting_seat_ids...}}
+
+    Error Codes:
+    - INVALID_QUANTITY: No seats requested, or a seat is requested twice
+    - ZONE_NOT_FOUND: Zone seat map or availability counter not initialized
+    - SEAT_NOT_FOUND: A requested seat is not in the zone's seat map
+    - SEAT_TAKEN: One or more requested seats are held by another booking
+    - USER_LIMIT_EXCEEDED: User has reached max reservation limit
+
+    All seats are checked before any is marked, so a request either holds
+    every seat it names or none of them. The reservation record lists the held
+    seats in its "seats" field (comma separated) so they can be released. As
+    with reserve_seats, a shadow copy of the hold and its reservations:expiry
+    entry let the expiry workers free the seats once the reservation expires.
+--]]
+
+local zone_seats_key = KEYS[1]
+local zone_availability_key = KEYS[2]
+local user_reservations_key = KEYS[3]
+local reservation_key = KEYS[4]
+local event_zones_key = KEYS[5]
+local event_holds_key = KEYS[6]
+local hold_shadow_key = KEYS[7]
+local expiry_index_key = KEYS[8]
+
+local max_per_user = tonumber(ARGV[1])
+local user_id = ARGV[2]
+local booking_id = ARGV[3]
+local zone_id = ARGV[4]
+local event_id = ARGV[5]
+local unit_price = ARGV[6]
+local ttl_seconds = tonumber(ARGV[7]) or 600
+
+local seat_ids = {}
+for i = 8, #ARGV do
+    seat_ids[#seat_ids + 1] = ARGV[i]
+end
+local quantity = #seat_ids
+
+-- Validate requested seats
+if quantity == 0 then
+    return {0, "INVALID_QUANTITY", "At least one seat must be requested"}
+end
+local requested = {}
+for _, seat_id in ipairs(seat_ids) do
+    if requested[seat_id] then
+        return {0, "INVALID_QUANTITY", "Seat " .. seat_id .. " is requested more than once"}
+    end
+    requested[seat_id] = true
+end
+
+if redis.call("EXISTS", zone_seats_key) == 0 then
+    return {0, "ZONE_NOT_FOUND", "Zone seat map not initialized"}
+end
+if redis.call("EXISTS", zone_availability_key) == 0 then
+    return {0, "ZONE_NOT_FOUND", "Zone availability not initialized"}
+end
+
+-- Every seat must exist and be free; collect all conflicts, not just the first
+local holders = redis.call("HMGET", zone_seats_key, unpack(seat_ids))
+local taken = {}
+for i, holder in ipairs(holders) do
+    if not holder then
+        return {0, "SEAT_NOT_FOUND", "Seat " .. seat_ids[i] .. " does not exist in zone " .. zone_id}
+    end
+    if holder ~= "" then
+        taken[#taken + 1] = seat_ids[i]
+    end
+end
+if #taken > 0 then
+    return {0, "SEAT_TAKEN", "Seats already taken: " .. table.concat(taken, ","), taken}
+end
+
+-- Get user's current reservations for this event
+local user_reserved = redis.call("GET", user_reservations_key)
+user_reserved = tonumber(user_reserved) or 0
+
+-- Check user limit
+if max_per_user and max_per_user > 0 then
+    if (user_reserved + quantity) > max_per_user then
+        return {0, "USER_LIMIT_EXCEEDED", "User limit exceeded. Current: " .. user_reserved .. ", Requested: " .. quantity .. ", Max: " .. max_per_user}
+    end
+end
+
+-- === ATOMIC RESERVATION ===
+
+-- 1. Mark the seats held by this booking
+local held = {}
+for _, seat_id in ipairs(seat_ids) do
+    held[#held + 1] = seat_id
+    held[#held + 1] = booking_id
+end
+redis.call("HSET", zone_seats_key, unpack(held))
+
+-- 2. Keep the zone counter in step
+local remaining = redis.call("DECRBY", zone_availability_key, quantity)
+
+-- 3. Increment user's reserved count for this event
+local new_user_reserved = redis.call("INCRBY", user_reservations_key, quantity)
+redis.call("EXPIRE", user_reservations_key, ttl_seconds + 60)
+
+-- 4. Create reservation record
+local timestamp = redis.call("TIME")
+local created_at = timestamp[1] .. "." .. timestamp[2]
+
+redis.call("HSET", reservation_key,
+    "booking_id", booking_id,
+    "user_id", user_id,
+    "zone_id", zone_id,
+    "event_id", event_id,
+    "show_id", "",
+    "quantity", quantity,
+    "seats", table.concat(seat_ids, ","),
+    "unit_price", unit_price,
+    "status", "reserved",
+    "created_at", created_at,
+    "expires_at", timestamp[1] + ttl_seconds
+)
+redis.call("EXPIRE", reservation_key, ttl_seconds)
+redis.call("HSET", hold_shadow_key,
+    "booking_id", booking_id,
+    "user_id", user_id,
+    "zone_id", zone_id,
+    "event_id", event_id,
+    "show_id", "",
+    "quantity", quantity,
+    "seats", table.concat(seat_ids, ",")
+)
+redis.call("EXPIRE", hold_shadow_key, ttl_seconds + 86400)
+
+-- 5. Index the hold for event stats, dropping holds that have expired
+redis.call("SADD", event_zones_key, zone_id)
+redis.call("ZADD", event_holds_key, timestamp[1] + ttl_seconds, booking_id)
+redis.call("ZREMRANGEBYSCORE", event_holds_key, "-inf", "(" .. timestamp[1])
+redis.call("ZADD", expiry_index_key, timestamp[1] + ttl_seconds,
+    booking_id .. ":" .. quantity .. ":" .. zone_id .. ":" .. user_id)
+
+return {1, remaining, new_user_reserved}
@@ -0,0 +1,121 @@
+--[[
+    Restore Expired Hold Lua Script
+    ===============================
+    Atomically puts the seats of a hold whose reservation expired back into
+    inventory. The reservation hash is gone by then, so the hold is read from
+    the shadow copy reserve_seats and reserve_specific_seats keep beside it.
+
+    Key Structure:
+    - KEYS[1]: hold:shadow:{booking_id}               - Copy of the hold kept for expiry (hash)
+    - KEYS[2]: reservation:{booking_id}               - Reservation record (hash, expected to be gone)
+    - KEYS[3]: zone:availability:{zone_id}            - Available seats count
+    - KEYS[4]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
+    - KEYS[5]: zone:seats:{zone_id}                   - Seat map (hash, only touched for specific-seat holds)
+    - KEYS[6]: reservations:expiry                    - Holds by expires_at (zset)
+    - KEYS[7]: show:availability:{show_id}            - Available seats across the show's zones
+                                                        (optional, passed when the hold was counted against the show)
+
+    Arguments:
+    - ARGV[1]: booking_id        - Booking ID (for validation)
+
+    Returns:
+    - Success: {1, new_available_seats, new_user_reserved}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - RESERVATION_NOT_FOUND: No shadow; the hold was released, confirmed or already restored
+    - INVALID_BOOKING_ID: Booking ID does not match
+    - HOLD_ACTIVE: The reservation has not expired yet
+
+    Deleting the shadow is what makes a restore happen at most once, however
+    many times the expiry of the same reservation is reported, whether by a
+    keyspace notification or by the sweeper reading reservations:expiry.
+--]]
+
+local hold_shadow_key = KEYS[1]
+local reservation_key = KEYS[2]
+local zone_availability_key = KEYS[3]
+local user_reservations_key = KEYS[4]
+local zone_seats_key = KEYS[5]
+local expiry_index_key = KEYS[6]
+local show_availability_key = KEYS[7]
+
+local booking_id = ARGV[1]
+
+-- Get shadow record
+local shadow = redis.call("HGETALL", hold_shadow_key)
+if #shadow == 0 then
+    return {0, "RESERVATION_NOT_FOUND", "No expired hold to restore"}
+end
+
+local shadow_data = {}
+for i = 1, #shadow, 2 do
+    shadow_data[shadow[i]] = shadow[i + 1]
+end
+
+if shadow_data["booking_id"] ~= booking_id then
+    return {0, "INVALID_BOOKING_ID", "Booking ID does not match"}
+end
+
+-- The hold is still live; its owner may yet confirm or release it
+if redis.call("EXISTS", reservation_key) == 1 then
+    return {0, "HOLD_ACTIVE", "Reservation has not expired"}
+end
+
+local quantity = tonumber(shadow_data["quantity"]) or 0
+
+-- === ATOMIC RESTORE ===
+
+-- 1. Free specific seats still tied to this booking
+local seats = {}
+for seat_id in string.gmatch(shadow_data["seats"] or "", "[^,]+") do
+    seats[#seats + 1] = seat_id
+end
+local restored = quantity
+if #seats > 0 then
+    local holders = redis.call("HMGET", zone_seats_key, unpack(seats))
+    local freed = {}
+    restored = 0
+    for i, holder in ipairs(holders) do
+        if holder == booking_id then
+            freed[#freed + 1] = seats[i]
+            freed[#freed + 1] = ""
+            restored = restored + 1
+        end
+    end
+    if #freed > 0 then
+        redis.call("HSET", zone_seats_key, unpack(freed))
+    end
+end
+
+-- 2. Return the seats to the zone and show counters (for specific seats, only
+-- those the map still tied to this booking)
+local new_available = 0
+if #seats == 0 or redis.call("EXISTS", zone_availability_key) == 1 then
+    new_available = redis.call("INCRBY", zone_availability_key, restored)
+end
+if show_availability_key then
+    redis.call("INCRBY", show_availability_key, quantity)
+end
+
+-- 3. Decrement user's reserved count
+local current_user_reserved = redis.call("GET", user_reservations_key)
+current_user_reserved = tonumber(current_user_reserved) or 0
+
+local new_user_reserved = current_user_reserved - quantity
+if new_user_reserved < 0 then
+    new_user_reserved = 0
+end
+
+if new_user_reserved > 0 then
+    redis.call("SET", user_reservations_key, new_user_reserved, "KEEPTTL")
+else
+    redis.call("DEL", user_reservations_key)
+end
+
+-- 4. Drop the shadow and expiry entry so the hold is never restored twice
+redis.call("DEL", hold_shadow_key)
+redis.call("ZREM", expiry_index_key,
+    booking_id .. ":" .. shadow_data["quantity"] .. ":" .. shadow_data["zone_id"] .. ":" .. shadow_data["user_id"])
+
+return {1, new_available, new_user_reserved}
@@ -0,0 +1,41 @@
+--[[
+    Set Zone Seats Lua Script
+    =========================
+    Atomically adds seats to a zone's seat map as free and keeps the zone's
+    availability counter in step, so reserve_specific_seats and
+    release_specific_seats never have to count the seat map.
+
+    Key Structure:
+    - KEYS[1]: zone:seats:{zone_id}           - Seat map (hash of seat_id -> holding booking_id, "" when free)
+    - KEYS[2]: zone:availability:{zone_id}    - Available seats count (string/integer)
+
+    Arguments:
+    - ARGV[1..]: seat_ids        - Seats to add (seats already in the map keep their holder)
+
+    Returns:
+    - {added, available_seats}
+
+    When the zone has no counter yet it is seeded from the free seats in the
+    map. This is the only place the whole map is read, and only at setup.
+--]]
+
+local zone_seats_key = KEYS[1]
+local zone_availability_key = KEYS[2]
+
+local added = 0
+for i = 1, #ARGV do
+    added = added + redis.call("HSETNX", zone_seats_key, ARGV[i], "")
+end
+
+if redis.call("EXISTS", zone_availability_key) == 1 then
+    return {added, redis.call("INCRBY", zone_availability_key, added)}
+end
+
+local free = 0
+for _, holder in ipairs(redis.call("HVALS", zone_seats_key)) do
+    if holder == "" then
+        free = free + 1
+    end
+end
+redis.call("SET", zone_availability_key, free)
+return {added, free}
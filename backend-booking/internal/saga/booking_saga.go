@@ -153,13 +153,13 @@ func (d *BookingSagaData) FromMap(m map[string]interface{}) {
 
 // SeatReservationService defines the interface for seat reservation operations
 type SeatReservationService interface {
-	ReserveSeats(ctx context.Context, bookingID, userID, eventID, zoneID string, quantity int) (reservationID string, err error)
+	ReserveSeats(ctx context.Context, bookingID, userID, eventID, showID, zoneID string, quantity int) (reservationID string, err error)
 	ReleaseSeats(ctx context.Context, bookingID, userID string) error
 }
 
 // PaymentService defines the interface for payment operations
 type PaymentService interface {
-	ProcessPayment(ctx context.Context, bookingID, userID string, amount float64, currency, method string) (paymentID string, err error)
+	ProcessPayment(ctx context.Context, bookingID, userID, tenantID string, amount float64, currency, method string) (paymentID string, err error)
 	RefundPayment(ctx context.Context, paymentID, reason string) error
 }
 
@@ -203,6 +203,9 @@ func NewBookingSagaBuilder(config *BookingSagaConfig) *BookingSagaBuilder {
 func (b *BookingSagaBuilder) Build() *pkgsaga.Definition {
 	def := pkgsaga.NewDefinition(BookingSagaName, "Booking saga for ticket reservation")
 	def.WithTimeout(5 * time.Minute)
+	// Client retries with the same idempotency key replay the prior result instead of charging
+	// again; keys are per user, so one user's key never replays another user's booking
+	def.WithIdempotencyKey("idempotency_key").WithIdempotencyScope("user_id")
 
 	// Step 1: Reserve Seats
 	def.AddStep(&pkgsaga.Step{
@@ -261,6 +264,7 @@ func (b *BookingSagaBuilder) reserveSeatsExecute(ctx context.Context, data map[s
 		sagaData.BookingID,
 		sagaData.UserID,
 		sagaData.EventID,
+		sagaData.ShowID,
 		sagaData.ZoneID,
 		sagaData.Quantity,
 	)
@@ -302,6 +306,7 @@ func (b *BookingSagaBuilder) processPaymentExecute(ctx context.Context, data map
 		ctx,
 		sagaData.BookingID,
 		sagaData.UserID,
+		sagaData.TenantID,
 		sagaData.TotalPrice,
 		sagaData.Currency,
 		sagaData.PaymentMethod,
@@ -279,6 +279,67 @@ func TestBookingSaga_PaymentFailure_ReleasesSeats(t *testing.T) {
 	}
 }
 
+func TestBookingSaga_ReleaseFailure_CompensationFailed(t *testing.T) {
+	// Setup mock services: payment fails and the seat release compensation also fails
+	reservationSvc := NewMockSeatReservationService()
+	reservationSvc.ReleaseShouldFail = true
+	paymentSvc := NewMockPaymentService()
+	paymentSvc.ShouldFail = true
+	paymentSvc.FailureError = ErrPaymentDeclined
+
+	builder := NewBookingSagaBuilder(&BookingSagaConfig{
+		ReservationService:  reservationSvc,
+		PaymentService:      paymentSvc,
+		ConfirmationService: NewMockBookingConfirmationService(),
+		StepTimeout:         5 * time.Second,
+		MaxRetries:          0,
+	})
+
+	// Collect dead-lettered compensations
+	var deadLettered []string
+	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{
+		Store: pkgsaga.NewMemoryStore(),
+		OnCompensationFailed: func(instance *pkgsaga.Instance, step *pkgsaga.Step, err error) {
+			deadLettered = append(deadLettered, step.Name)
+		},
+	})
+
+	if err := orchestrator.RegisterDefinition(builder.Build()); err != nil {
+		t.Fatalf("failed to register saga definition: %v", err)
+	}
+
+	ctx := context.Background()
+	instance, err := orchestrator.Execute(ctx, BookingSagaName, map[string]interface{}{
+		"booking_id":     "booking-release-fail",
+		"user_id":        "user-release-fail",
+		"event_id":       "event-123",
+		"zone_id":        "zone-B",
+		"quantity":       1,
+		"total_price":    100.00,
+		"currency":       "THB",
+		"payment_method": "credit_card",
+	})
+	if err == nil {
+		t.Fatal("expected saga execution to fail")
+	}
+
+	if instance.Status != pkgsaga.StatusCompensationFailed {
+		t.Errorf("expected status %s, got %s", pkgsaga.StatusCompensationFailed, instance.Status)
+	}
+
+	if len(deadLettered) != 1 || deadLettered[0] != StepReserveSeats {
+		t.Errorf("expected %s to be dead-lettered, got %v", StepReserveSeats, deadLettered)
+	}
+
+	reservation, exists := reservationSvc.GetReservation("booking-release-fail")
+	if !exists {
+		t.Fatal("expected reservation to exist")
+	}
+	if reservation.Released {
+		t.Error("expected reservation to remain held after failed release")
+	}
+}
+
 func TestBookingSaga_ConfirmationFailure_RefundsPayment(t *testing.T) {
 	// Setup mock services with confirmation failure
 	reservationSvc := NewMockSeatReservationService()
@@ -399,6 +460,60 @@ func TestBookingSaga_WithoutNotificationService(t *testing.T) {
 	}
 }
 
+func TestBookingSaga_DuplicateIdempotencyKey_SinglePayment(t *testing.T) {
+	reservationSvc := NewMockSeatReservationService()
+	paymentSvc := NewMockPaymentService()
+	confirmationSvc := NewMockBookingConfirmationService()
+
+	builder := NewBookingSagaBuilder(&BookingSagaConfig{
+		ReservationService:  reservationSvc,
+		PaymentService:      paymentSvc,
+		ConfirmationService: confirmationSvc,
+		StepTimeout:         5 * time.Second,
+	})
+
+	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{
+		Store: pkgsaga.NewMemoryStore(),
+	})
+	if err := orchestrator.RegisterDefinition(builder.Build()); err != nil {
+		t.Fatalf("failed to register saga definition: %v", err)
+	}
+
+	ctx := context.Background()
+	initialData := map[string]interface{}{
+		"booking_id":      "booking-123",
+		"user_id":         "user-456",
+		"event_id":        "event-789",
+		"zone_id":         "zone-A",
+		"quantity":        2,
+		"total_price":     200.00,
+		"currency":        "THB",
+		"payment_method":  "credit_card",
+		"idempotency_key": "idem-key-123",
+	}
+
+	first, err := orchestrator.Execute(ctx, BookingSagaName, initialData)
+	if err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+
+	// Client retry with the same idempotency key
+	second, err := orchestrator.Execute(ctx, BookingSagaName, initialData)
+	if err != nil {
+		t.Fatalf("retried execution failed: %v", err)
+	}
+
+	if count := paymentSvc.PaymentCount(); count != 1 {
+		t.Errorf("expected 1 payment, got %d", count)
+	}
+	if second.ID != first.ID {
+		t.Errorf("expected retry to return saga %s, got %s", first.ID, second.ID)
+	}
+	if second.Data["payment_id"] != first.Data["payment_id"] {
+		t.Errorf("expected payment_id %v, got %v", first.Data["payment_id"], second.Data["payment_id"])
+	}
+}
+
 func TestBookingSagaData_ToMapAndFromMap(t *testing.T) {
 	original := &BookingSagaData{
 		BookingID:        "booking-123",
@@ -465,12 +580,68 @@ func TestBookingSagaData_ToMapAndFromMap(t *testing.T) {
 	}
 }
 
+func TestBookingSaga_StepOutputsVisibleToLaterSteps(t *testing.T) {
+	reservationSvc := NewMockSeatReservationService()
+
+	builder := NewBookingSagaBuilder(&BookingSagaConfig{
+		ReservationService:  reservationSvc,
+		PaymentService:      NewMockPaymentService(),
+		ConfirmationService: NewMockBookingConfirmationService(),
+		StepTimeout:         5 * time.Second,
+	})
+	def := builder.Build()
+
+	// Capture what the payment step sees through BookingSagaData.FromMap
+	var seenByPayment BookingSagaData
+	paymentStep := def.Steps[1]
+	execute := paymentStep.Execute
+	paymentStep.Execute = func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+		seenByPayment.FromMap(data)
+		return execute(ctx, data)
+	}
+
+	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{
+		Store: pkgsaga.NewMemoryStore(),
+	})
+	if err := orchestrator.RegisterDefinition(def); err != nil {
+		t.Fatalf("failed to register saga definition: %v", err)
+	}
+
+	instance, err := orchestrator.Execute(context.Background(), BookingSagaName, (&BookingSagaData{
+		BookingID:     "booking-merge",
+		UserID:        "user-merge",
+		EventID:       "event-merge",
+		ZoneID:        "zone-A",
+		Quantity:      1,
+		TotalPrice:    100.00,
+		Currency:      "THB",
+		PaymentMethod: "credit_card",
+	}).ToMap())
+	if err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+
+	reservation, exists := reservationSvc.GetReservation("booking-merge")
+	if !exists {
+		t.Fatal("expected reservation to exist")
+	}
+	if seenByPayment.ReservationID != reservation.ReservationID {
+		t.Errorf("expected payment step to see reservation %s, got %q", reservation.ReservationID, seenByPayment.ReservationID)
+	}
+
+	final := &BookingSagaData{}
+	final.FromMap(instance.GetData())
+	if final.ReservationID == "" || final.PaymentID == "" || final.ConfirmationCode == "" {
+		t.Errorf("expected all step outputs in final data, got %+v", final)
+	}
+}
+
 func TestMockSeatReservationService(t *testing.T) {
 	svc := NewMockSeatReservationService()
 	ctx := context.Background()
 
 	// Test successful reservation
-	reservationID, err := svc.ReserveSeats(ctx, "booking-1", "user-1", "event-1", "zone-1", 2)
+	reservationID, err := svc.ReserveSeats(ctx, "booking-1", "user-1", "event-1", "", "zone-1", 2)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -510,7 +681,7 @@ func TestMockPaymentService(t *testing.T) {
 	ctx := context.Background()
 
 	// Test successful payment
-	paymentID, err := svc.ProcessPayment(ctx, "booking-1", "user-1", 100.00, "THB", "credit_card")
+	paymentID, err := svc.ProcessPayment(ctx, "booking-1", "user-1", "tenant-1", 100.00, "THB", "credit_card")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,92 @@
+package saga
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// ConfirmationCodeAlphabet is Crockford's base32 alphabet. It leaves out I,
+	// L, O and U, so a code read aloud or copied by hand cannot confuse 0 with O
+	// or 1 with I or L.
+	ConfirmationCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// ConfirmationCodeLength is the number of characters in a confirmation code
+	ConfirmationCodeLength = 8
+	// DefaultConfirmationCodeAttempts bounds how many codes are tried before giving up
+	DefaultConfirmationCodeAttempts = 5
+)
+
+// ErrConfirmationCodeExhausted is returned when every attempt produced a code already in use
+var ErrConfirmationCodeExhausted = errors.New("no unused confirmation code found")
+
+// ConfirmationCodeStore reports whether a confirmation code is already taken
+type ConfirmationCodeStore interface {
+	ConfirmationCodeExists(ctx context.Context, code string) (bool, error)
+}
+
+// ConfirmationCodeStoreFunc adapts a function to ConfirmationCodeStore
+type ConfirmationCodeStoreFunc func(ctx context.Context, code string) (bool, error)
+
+// ConfirmationCodeExists implements ConfirmationCodeStore
+func (f ConfirmationCodeStoreFunc) ConfirmationCodeExists(ctx context.Context, code string) (bool, error) {
+	return f(ctx, code)
+}
+
+// ConfirmationCodeGenerator creates fixed-length confirmation codes that are
+// easy to read over the phone and not yet used by another booking
+type ConfirmationCodeGenerator struct {
+	store       ConfirmationCodeStore
+	maxAttempts int
+	random      io.Reader
+}
+
+// NewConfirmationCodeGenerator creates a generator that checks each candidate
+// code against store, trying up to DefaultConfirmationCodeAttempts codes
+func NewConfirmationCodeGenerator(store ConfirmationCodeStore) *ConfirmationCodeGenerator {
+	return &ConfirmationCodeGenerator{
+		store:       store,
+		maxAttempts: DefaultConfirmationCodeAttempts,
+		random:      rand.Reader,
+	}
+}
+
+// Generate returns a confirmation code not found in the store. A candidate
+// that collides is discarded and a new one drawn; after maxAttempts
+// collisions ErrConfirmationCodeExhausted is returned. The check is advisory,
+// so storage should still enforce uniqueness on write.
+func (g *ConfirmationCodeGenerator) Generate(ctx context.Context) (string, error) {
+	for attempt := 0; attempt < g.maxAttempts; attempt++ {
+		code, err := g.randomCode()
+		if err != nil {
+			return "", err
+		}
+
+		if g.store == nil {
+			return code, nil
+		}
+		exists, err := g.store.ConfirmationCodeExists(ctx, code)
+		if err != nil {
+			return "", fmt.Errorf("failed to check confirmation code: %w", err)
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("%w after %d attempts", ErrConfirmationCodeExhausted, g.maxAttempts)
+}
+
+// randomCode draws one candidate code. The alphabet has 32 symbols, so the
+// low five bits of each random byte pick a symbol without bias.
+func (g *ConfirmationCodeGenerator) randomCode() (string, error) {
+	buf := make([]byte, ConfirmationCodeLength)
+	if _, err := io.ReadFull(g.random, buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = ConfirmationCodeAlphabet[b&31]
+	}
+	return string(buf), nil
+}
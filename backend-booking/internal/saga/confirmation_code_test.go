@@ -0,0 +1,107 @@
+package saga
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// seededCodeStore is a confirmation code store holding a fixed set of codes
+type seededCodeStore struct {
+	codes   map[string]bool
+	checked []string
+	err     error
+}
+
+func (s *seededCodeStore) ConfirmationCodeExists(ctx context.Context, code string) (bool, error) {
+	s.checked = append(s.checked, code)
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.codes[code], nil
+}
+
+// newSeededGenerator returns a generator drawing its random bytes from random
+func newSeededGenerator(store ConfirmationCodeStore, random []byte) *ConfirmationCodeGenerator {
+	g := NewConfirmationCodeGenerator(store)
+	g.random = bytes.NewReader(random)
+	return g
+}
+
+func TestConfirmationCodeAlphabet(t *testing.T) {
+	if len(ConfirmationCodeAlphabet) != 32 {
+		t.Fatalf("alphabet has %d symbols, want 32", len(ConfirmationCodeAlphabet))
+	}
+	seen := make(map[rune]bool)
+	for _, r := range ConfirmationCodeAlphabet {
+		if seen[r] {
+			t.Errorf("alphabet repeats %q", r)
+		}
+		seen[r] = true
+	}
+	for _, ambiguous := range "ILOU" {
+		if seen[ambiguous] {
+			t.Errorf("alphabet contains ambiguous %q", ambiguous)
+		}
+	}
+}
+
+func TestConfirmationCodeGenerator_Generate(t *testing.T) {
+	g := NewConfirmationCodeGenerator(&seededCodeStore{})
+
+	for i := 0; i < 200; i++ {
+		code, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(code) != ConfirmationCodeLength {
+			t.Fatalf("Generate() = %q, want %d characters", code, ConfirmationCodeLength)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(ConfirmationCodeAlphabet, r) {
+				t.Fatalf("Generate() = %q, contains %q outside the alphabet", code, r)
+			}
+		}
+	}
+}
+
+func TestConfirmationCodeGenerator_RetriesOnCollision(t *testing.T) {
+	store := &seededCodeStore{codes: map[string]bool{"00000000": true}}
+	random := append(make([]byte, ConfirmationCodeLength), 1, 2, 3, 4, 5, 6, 7, 8)
+	g := newSeededGenerator(store, random)
+
+	code, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if code != "12345678" {
+		t.Errorf("Generate() = %q, want 12345678", code)
+	}
+	if want := []string{"00000000", "12345678"}; strings.Join(store.checked, ",") != strings.Join(want, ",") {
+		t.Errorf("checked codes = %v, want %v", store.checked, want)
+	}
+}
+
+func TestConfirmationCodeGenerator_GivesUpAfterMaxAttempts(t *testing.T) {
+	store := &seededCodeStore{codes: map[string]bool{"00000000": true}}
+	g := newSeededGenerator(store, make([]byte, ConfirmationCodeLength*(DefaultConfirmationCodeAttempts+1)))
+
+	_, err := g.Generate(context.Background())
+	if !errors.Is(err, ErrConfirmationCodeExhausted) {
+		t.Fatalf("Generate() error = %v, want ErrConfirmationCodeExhausted", err)
+	}
+	if len(store.checked) != DefaultConfirmationCodeAttempts {
+		t.Errorf("store checked %d codes, want %d", len(store.checked), DefaultConfirmationCodeAttempts)
+	}
+}
+
+func TestConfirmationCodeGenerator_StoreError(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	g := NewConfirmationCodeGenerator(&seededCodeStore{err: storeErr})
+
+	if _, err := g.Generate(context.Background()); !errors.Is(err, storeErr) {
+		t.Errorf("Generate() error = %v, want %v", err, storeErr)
+	}
+}
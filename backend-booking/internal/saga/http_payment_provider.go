@@ -0,0 +1,148 @@
+package saga
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPPaymentProvider is a PaymentProvider backed by the payment service's
+// REST API: a payment is created pending (authorize), processed (capture),
+// cancelled (void) or refunded. The payment service's idempotency middleware
+// replays requests carrying an Idempotency-Key it has already seen.
+type HTTPPaymentProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ PaymentProvider = (*HTTPPaymentProvider)(nil)
+
+// NewHTTPPaymentProvider creates a provider calling the payment service at paymentServiceURL
+func NewHTTPPaymentProvider(paymentServiceURL string) *HTTPPaymentProvider {
+	return &HTTPPaymentProvider{
+		baseURL: paymentServiceURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// paymentServiceResponse is the payment service's response envelope
+type paymentServiceResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		ID           string `json:"id"`
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Name returns the provider name
+func (p *HTTPPaymentProvider) Name() string {
+	return "payment-service"
+}
+
+// Authorize creates a pending payment for the booking; its ID is the authorization ID
+func (p *HTTPPaymentProvider) Authorize(ctx context.Context, req *AuthorizeRequest) (*Authorization, error) {
+	body := map[string]interface{}{
+		"booking_id": req.BookingID,
+		"amount":     req.Amount,
+		"currency":   req.Currency,
+		"method":     req.Method,
+	}
+	headers := map[string]string{
+		"X-User-ID":   req.UserID,
+		"X-Tenant-ID": req.TenantID,
+	}
+
+	resp, err := p.do(ctx, "/api/v1/payments", body, req.IdempotencyKey, headers)
+	if err != nil {
+		return nil, err
+	}
+	return &Authorization{AuthorizationID: resp.Data.ID}, nil
+}
+
+// Capture processes a pending payment, reporting a failed payment as declined
+func (p *HTTPPaymentProvider) Capture(ctx context.Context, req *CaptureRequest) (*CapturedPayment, error) {
+	body := map[string]interface{}{"payment_id": req.AuthorizationID}
+
+	resp, err := p.do(ctx, "/api/v1/payments/"+req.AuthorizationID+"/process", body, req.IdempotencyKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.Data.Status {
+	case "succeeded":
+		return &CapturedPayment{PaymentID: resp.Data.ID}, nil
+	case "failed":
+		return nil, fmt.Errorf("%w: %s", ErrPaymentDeclined, resp.Data.ErrorMessage)
+	default:
+		return nil, fmt.Errorf("payment %s is %s after processing", resp.Data.ID, resp.Data.Status)
+	}
+}
+
+// Void cancels a payment that was created but not processed
+func (p *HTTPPaymentProvider) Void(ctx context.Context, authorizationID string) error {
+	_, err := p.do(ctx, "/api/v1/payments/"+authorizationID+"/cancel", map[string]interface{}{}, paymentIdempotencyKey(authorizationID, "void"), nil)
+	return err
+}
+
+// Refund refunds a processed payment
+func (p *HTTPPaymentProvider) Refund(ctx context.Context, req *RefundRequest) error {
+	body := map[string]interface{}{
+		"payment_id": req.PaymentID,
+		"amount":     req.Amount,
+		"reason":     req.Reason,
+	}
+
+	_, err := p.do(ctx, "/api/v1/payments/"+req.PaymentID+"/refund", body, req.IdempotencyKey, nil)
+	return err
+}
+
+// do POSTs body to path and decodes the response envelope. A 404 is reported
+// as ErrPaymentNotFound.
+func (p *HTTPPaymentProvider) do(ctx context.Context, path string, body interface{}, idempotencyKey string, headers map[string]string) (*paymentServiceResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call payment service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPaymentNotFound
+	}
+
+	var result paymentServiceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	ok := resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated
+	if !ok || !result.Success {
+		if result.Error != nil {
+			return nil, fmt.Errorf("payment service returned %d: %s: %s", resp.StatusCode, result.Error.Code, result.Error.Message)
+		}
+		return nil, fmt.Errorf("payment service returned %d", resp.StatusCode)
+	}
+	return &result, nil
+}
@@ -0,0 +1,106 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newFakePaymentService serves the payment service's payment routes, failing
+// the processing of payments over declineAbove
+func newFakePaymentService(t *testing.T, declineAbove float64) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []string
+		amount   float64
+	)
+	respond := func(w http.ResponseWriter, status int, data map[string]interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": data})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/payments", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, "create:"+r.Header.Get("Idempotency-Key")+":"+r.Header.Get("X-User-ID")+":"+r.Header.Get("X-Tenant-ID"))
+		mu.Unlock()
+		var body struct {
+			Amount float64 `json:"amount"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		amount = body.Amount
+		mu.Unlock()
+		respond(w, http.StatusCreated, map[string]interface{}{"id": "pay-1", "status": "pending"})
+	})
+	mux.HandleFunc("POST /api/v1/payments/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.PathValue("action")+":"+r.PathValue("id"))
+		mu.Unlock()
+		if r.PathValue("id") != "pay-1" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false})
+			return
+		}
+		status := map[string]string{"process": "succeeded", "cancel": "cancelled", "refund": "refunded"}[r.PathValue("action")]
+		mu.Lock()
+		if r.PathValue("action") == "process" && amount > declineAbove {
+			status = "failed"
+		}
+		mu.Unlock()
+		respond(w, http.StatusOK, map[string]interface{}{"id": "pay-1", "status": status, "error_message": "insufficient funds"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHTTPPaymentProvider_ProcessAndRefund(t *testing.T) {
+	server, requests := newFakePaymentService(t, 1000)
+	svc := NewProviderPaymentService(NewHTTPPaymentProvider(server.URL))
+	ctx := context.Background()
+
+	paymentID, err := svc.ProcessPayment(ctx, "booking-123", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+	if paymentID != "pay-1" {
+		t.Errorf("ProcessPayment() = %q, want pay-1", paymentID)
+	}
+	if err := svc.RefundPayment(ctx, paymentID, "event cancelled"); err != nil {
+		t.Fatalf("RefundPayment() error = %v", err)
+	}
+
+	assertCalls(t, *requests, []string{
+		"create:booking-123:authorize:user-456:tenant-1",
+		"process:pay-1",
+		"refund:pay-1",
+	})
+
+	if err := svc.RefundPayment(ctx, "pay-unknown", "event cancelled"); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("RefundPayment(unknown) error = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestHTTPPaymentProvider_DeclinedPaymentIsVoided(t *testing.T) {
+	server, requests := newFakePaymentService(t, 100)
+	svc := NewProviderPaymentService(NewHTTPPaymentProvider(server.URL))
+
+	_, err := svc.ProcessPayment(context.Background(), "booking-123", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("ProcessPayment() error = %v, want ErrPaymentDeclined", err)
+	}
+
+	assertCalls(t, *requests, []string{
+		"create:booking-123:authorize:user-456:tenant-1",
+		"process:pay-1",
+		"cancel:pay-1",
+	})
+}
@@ -3,6 +3,8 @@ package saga
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // MessageType represents the type of saga message
@@ -276,7 +278,7 @@ func (tc *TimeoutCheck) IsTimedOut() bool {
 // helper functions
 
 func generateMessageID() string {
-	return generateConfirmationCode() + "-" + generateConfirmationCode()
+	return uuid.New().String()[:8] + "-" + uuid.New().String()[:8]
 }
 
 func generateIdempotencyKey(sagaID, stepName string) string {
@@ -0,0 +1,46 @@
+package saga
+
+import (
+	"context"
+
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NewLifecycleEventHandler returns a saga event handler that logs each lifecycle
+// event and records it on the active OpenTelemetry span, so operators can see
+// where sagas stall.
+func NewLifecycleEventHandler(logger Logger) pkgsaga.EventHandler {
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+
+	return func(ctx context.Context, event *pkgsaga.Event) {
+		fields := []interface{}{
+			"saga_id", event.Instance.ID,
+			"definition", event.Instance.DefinitionID,
+			"event", string(event.Type),
+		}
+		attrs := []attribute.KeyValue{
+			attribute.String("saga_id", event.Instance.ID),
+			attribute.String("saga.definition", event.Instance.DefinitionID),
+		}
+		if event.StepName != "" {
+			fields = append(fields, "step", event.StepName)
+			attrs = append(attrs, attribute.String("saga.step", event.StepName))
+		}
+
+		telemetry.AddSpanEvent(ctx, "saga."+string(event.Type), attrs...)
+
+		switch event.Type {
+		case pkgsaga.EventSagaFailed, pkgsaga.EventCompensationFailed:
+			telemetry.SetSpanError(ctx, event.Error)
+			logger.ErrorContext(ctx, "Saga lifecycle event", append(fields, "error", event.Error.Error())...)
+		case pkgsaga.EventStepFailed:
+			logger.WarnContext(ctx, "Saga lifecycle event", append(fields, "error", event.Error.Error())...)
+		default:
+			logger.InfoContext(ctx, "Saga lifecycle event", fields...)
+		}
+	}
+}
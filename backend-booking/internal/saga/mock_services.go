@@ -27,6 +27,8 @@ type MockSeatReservationService struct {
 	reservations map[string]*MockReservation
 	ShouldFail   bool
 	FailureError error
+	// ReleaseShouldFail makes ReleaseSeats fail (simulates a failing compensation)
+	ReleaseShouldFail bool
 }
 
 // MockReservation represents a mock reservation
@@ -48,7 +50,7 @@ func NewMockSeatReservationService() *MockSeatReservationService {
 }
 
 // ReserveSeats reserves seats in inventory
-func (s *MockSeatReservationService) ReserveSeats(ctx context.Context, bookingID, userID, eventID, zoneID string, quantity int) (string, error) {
+func (s *MockSeatReservationService) ReserveSeats(ctx context.Context, bookingID, userID, eventID, showID, zoneID string, quantity int) (string, error) {
 	if s.ShouldFail {
 		if s.FailureError != nil {
 			return "", s.FailureError
@@ -75,6 +77,10 @@ func (s *MockSeatReservationService) ReserveSeats(ctx context.Context, bookingID
 
 // ReleaseSeats releases reserved seats back to inventory
 func (s *MockSeatReservationService) ReleaseSeats(ctx context.Context, bookingID, userID string) error {
+	if s.ReleaseShouldFail {
+		return ErrMockServiceFailure
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -130,7 +136,7 @@ func NewMockPaymentService() *MockPaymentService {
 }
 
 // ProcessPayment processes a payment for booking
-func (s *MockPaymentService) ProcessPayment(ctx context.Context, bookingID, userID string, amount float64, currency, method string) (string, error) {
+func (s *MockPaymentService) ProcessPayment(ctx context.Context, bookingID, userID, tenantID string, amount float64, currency, method string) (string, error) {
 	if s.ShouldFail {
 		if s.FailureError != nil {
 			return "", s.FailureError
@@ -190,6 +196,13 @@ func (s *MockPaymentService) GetPaymentByBookingID(bookingID string) (*MockPayme
 	return nil, false
 }
 
+// PaymentCount returns the number of processed payments (for testing)
+func (s *MockPaymentService) PaymentCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.payments)
+}
+
 // Clear removes all payments (for testing)
 func (s *MockPaymentService) Clear() {
 	s.mu.Lock()
@@ -201,6 +214,7 @@ func (s *MockPaymentService) Clear() {
 type MockBookingConfirmationService struct {
 	mu            sync.RWMutex
 	confirmations map[string]*MockConfirmation
+	codes         *ConfirmationCodeGenerator
 	ShouldFail    bool
 	FailureError  error
 }
@@ -215,9 +229,19 @@ type MockConfirmation struct {
 
 // NewMockBookingConfirmationService creates a new mock booking confirmation service
 func NewMockBookingConfirmationService() *MockBookingConfirmationService {
-	return &MockBookingConfirmationService{
+	s := &MockBookingConfirmationService{
 		confirmations: make(map[string]*MockConfirmation),
 	}
+	// Called from ConfirmBooking with s.mu held
+	s.codes = NewConfirmationCodeGenerator(ConfirmationCodeStoreFunc(func(ctx context.Context, code string) (bool, error) {
+		for _, c := range s.confirmations {
+			if c.ConfirmationCode == code {
+				return true, nil
+			}
+		}
+		return false, nil
+	}))
+	return s
 }
 
 // ConfirmBooking confirms a booking after payment
@@ -232,7 +256,10 @@ func (s *MockBookingConfirmationService) ConfirmBooking(ctx context.Context, boo
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	confirmationCode := generateConfirmationCode()
+	confirmationCode, err := s.codes.Generate(ctx)
+	if err != nil {
+		return "", err
+	}
 	s.confirmations[bookingID] = &MockConfirmation{
 		BookingID:        bookingID,
 		UserID:           userID,
@@ -330,8 +357,3 @@ func (s *MockNotificationService) Clear() {
 	defer s.mu.Unlock()
 	s.notifications = make(map[string]*MockNotification)
 }
-
-// generateConfirmationCode generates a random confirmation code
-func generateConfirmationCode() string {
-	return uuid.New().String()[:8]
-}
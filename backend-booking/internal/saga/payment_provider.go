@@ -0,0 +1,163 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidRefundAmount is returned when a partial refund amount is not positive
+var ErrInvalidRefundAmount = errors.New("refund amount must be positive")
+
+// voidTimeout bounds voiding an authorization after a failed capture, which
+// runs even when the step's own context has already expired
+const voidTimeout = 10 * time.Second
+
+// PaymentProvider is a payment processor (Stripe, Omise, ...) plugged into
+// the booking saga through ProviderPaymentService. Providers should wrap
+// ErrPaymentDeclined when the payment is refused and ErrPaymentNotFound when
+// the referenced authorization or payment does not exist, and must treat a
+// repeated idempotency key as a replay of the original call.
+type PaymentProvider interface {
+	// Authorize places a hold on the payment method for the amount
+	Authorize(ctx context.Context, req *AuthorizeRequest) (*Authorization, error)
+	// Capture collects an authorized amount and returns the captured payment
+	Capture(ctx context.Context, req *CaptureRequest) (*CapturedPayment, error)
+	// Void releases an authorization that will not be captured
+	Void(ctx context.Context, authorizationID string) error
+	// Refund returns all (Amount 0) or part of a captured payment
+	Refund(ctx context.Context, req *RefundRequest) error
+	// Name returns the provider name
+	Name() string
+}
+
+// AuthorizeRequest asks a provider to authorize a booking's payment
+type AuthorizeRequest struct {
+	BookingID      string
+	UserID         string
+	TenantID       string
+	Amount         float64
+	Currency       string
+	Method         string
+	IdempotencyKey string
+}
+
+// Authorization is an authorized, not yet captured, payment
+type Authorization struct {
+	AuthorizationID string
+}
+
+// CaptureRequest asks a provider to capture an authorization
+type CaptureRequest struct {
+	AuthorizationID string
+	Amount          float64
+	IdempotencyKey  string
+}
+
+// CapturedPayment is a captured payment; its ID is the saga's payment ID
+type CapturedPayment struct {
+	PaymentID string
+}
+
+// RefundRequest asks a provider to refund a captured payment
+type RefundRequest struct {
+	PaymentID      string
+	Amount         float64 // 0 refunds whatever has not been refunded yet
+	Reason         string
+	IdempotencyKey string
+}
+
+// ProviderPaymentService implements PaymentService on top of a PaymentProvider:
+// the process-payment step authorizes then captures, voiding the authorization
+// if the capture fails, and its compensation refunds. Idempotency keys are derived from the booking and payment IDs, so
+// a retried step replays at the provider instead of charging twice.
+type ProviderPaymentService struct {
+	provider PaymentProvider
+}
+
+var _ PaymentService = (*ProviderPaymentService)(nil)
+
+// NewProviderPaymentService creates a payment service delegating to provider
+func NewProviderPaymentService(provider PaymentProvider) *ProviderPaymentService {
+	return &ProviderPaymentService{provider: provider}
+}
+
+// ProcessPayment authorizes and captures the booking's payment, returning the
+// captured payment ID. A failed step is not compensated by the orchestrator,
+// so if the capture fails the authorization is voided here rather than left
+// holding the customer's funds until it lapses.
+func (s *ProviderPaymentService) ProcessPayment(ctx context.Context, bookingID, userID, tenantID string, amount float64, currency, method string) (string, error) {
+	auth, err := s.provider.Authorize(ctx, &AuthorizeRequest{
+		BookingID:      bookingID,
+		UserID:         userID,
+		TenantID:       tenantID,
+		Amount:         amount,
+		Currency:       currency,
+		Method:         method,
+		IdempotencyKey: paymentIdempotencyKey(bookingID, "authorize"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s authorize failed: %w", s.provider.Name(), err)
+	}
+
+	captured, err := s.provider.Capture(ctx, &CaptureRequest{
+		AuthorizationID: auth.AuthorizationID,
+		Amount:          amount,
+		IdempotencyKey:  paymentIdempotencyKey(bookingID, "capture"),
+	})
+	if err != nil {
+		captureErr := fmt.Errorf("%s capture failed: %w", s.provider.Name(), err)
+		if voidErr := s.void(ctx, auth.AuthorizationID); voidErr != nil {
+			return "", errors.Join(captureErr, voidErr)
+		}
+		return "", captureErr
+	}
+	return captured.PaymentID, nil
+}
+
+// void releases an authorization, detached from ctx: a capture that failed
+// because the step timed out must still let go of the customer's funds
+func (s *ProviderPaymentService) void(ctx context.Context, authorizationID string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), voidTimeout)
+	defer cancel()
+
+	if err := s.provider.Void(ctx, authorizationID); err != nil {
+		return fmt.Errorf("%s void of authorization %s failed: %w", s.provider.Name(), authorizationID, err)
+	}
+	return nil
+}
+
+// RefundPayment refunds the remainder of a captured payment
+func (s *ProviderPaymentService) RefundPayment(ctx context.Context, paymentID, reason string) error {
+	if err := s.provider.Refund(ctx, &RefundRequest{
+		PaymentID:      paymentID,
+		Reason:         reason,
+		IdempotencyKey: paymentIdempotencyKey(paymentID, "refund"),
+	}); err != nil {
+		return fmt.Errorf("%s refund failed: %w", s.provider.Name(), err)
+	}
+	return nil
+}
+
+// RefundPartial refunds amount of a captured payment. Each distinct partial
+// refund needs its own idempotencyKey; retrying with the same key refunds once.
+func (s *ProviderPaymentService) RefundPartial(ctx context.Context, paymentID string, amount float64, reason, idempotencyKey string) error {
+	if amount <= 0 {
+		return ErrInvalidRefundAmount
+	}
+	if err := s.provider.Refund(ctx, &RefundRequest{
+		PaymentID:      paymentID,
+		Amount:         amount,
+		Reason:         reason,
+		IdempotencyKey: paymentIdempotencyKey(paymentID, "refund:"+idempotencyKey),
+	}); err != nil {
+		return fmt.Errorf("%s refund failed: %w", s.provider.Name(), err)
+	}
+	return nil
+}
+
+// paymentIdempotencyKey scopes a provider idempotency key to an ID and operation
+func paymentIdempotencyKey(id, operation string) string {
+	return id + ":" + operation
+}
@@ -0,0 +1,340 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// fakePaymentProvider is an in-memory PaymentProvider that replays calls
+// carrying an idempotency key it has already seen
+type fakePaymentProvider struct {
+	mu          sync.Mutex
+	Decline     bool
+	FailCapture bool
+	calls       []string
+	authorized  map[string]float64 // authorization ID -> amount
+	voided      map[string]bool    // authorization ID -> voided
+	captured    map[string]float64 // payment ID -> amount
+	refunded    map[string]float64 // payment ID -> amount refunded
+	seenResults map[string]string  // idempotency key -> returned ID
+	seq         int
+}
+
+func newFakePaymentProvider() *fakePaymentProvider {
+	return &fakePaymentProvider{
+		authorized:  make(map[string]float64),
+		voided:      make(map[string]bool),
+		captured:    make(map[string]float64),
+		refunded:    make(map[string]float64),
+		seenResults: make(map[string]string),
+	}
+}
+
+func (p *fakePaymentProvider) Name() string { return "fake" }
+
+func (p *fakePaymentProvider) Authorize(ctx context.Context, req *AuthorizeRequest) (*Authorization, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, "authorize:"+req.IdempotencyKey)
+
+	if id, ok := p.seenResults[req.IdempotencyKey]; ok {
+		return &Authorization{AuthorizationID: id}, nil
+	}
+	if p.Decline {
+		return nil, fmt.Errorf("card refused: %w", ErrPaymentDeclined)
+	}
+	p.seq++
+	id := fmt.Sprintf("auth-%d", p.seq)
+	p.authorized[id] = req.Amount
+	p.seenResults[req.IdempotencyKey] = id
+	return &Authorization{AuthorizationID: id}, nil
+}
+
+func (p *fakePaymentProvider) Capture(ctx context.Context, req *CaptureRequest) (*CapturedPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, "capture:"+req.IdempotencyKey)
+
+	if id, ok := p.seenResults[req.IdempotencyKey]; ok {
+		return &CapturedPayment{PaymentID: id}, nil
+	}
+	authorized, ok := p.authorized[req.AuthorizationID]
+	if !ok || p.voided[req.AuthorizationID] {
+		return nil, ErrPaymentNotFound
+	}
+	if p.FailCapture {
+		return nil, errors.New("capture timed out")
+	}
+	if req.Amount > authorized {
+		return nil, fmt.Errorf("capture %.2f exceeds authorized %.2f", req.Amount, authorized)
+	}
+	p.seq++
+	id := fmt.Sprintf("pay-%d", p.seq)
+	p.captured[id] = req.Amount
+	p.seenResults[req.IdempotencyKey] = id
+	return &CapturedPayment{PaymentID: id}, nil
+}
+
+func (p *fakePaymentProvider) Void(ctx context.Context, authorizationID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, "void:"+authorizationID)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := p.authorized[authorizationID]; !ok {
+		return ErrPaymentNotFound
+	}
+	p.voided[authorizationID] = true
+	return nil
+}
+
+func (p *fakePaymentProvider) Refund(ctx context.Context, req *RefundRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, "refund:"+req.IdempotencyKey)
+
+	if _, ok := p.seenResults[req.IdempotencyKey]; ok {
+		return nil
+	}
+	captured, ok := p.captured[req.PaymentID]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	remaining := captured - p.refunded[req.PaymentID]
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return fmt.Errorf("refund %.2f exceeds remaining %.2f", amount, remaining)
+	}
+	p.refunded[req.PaymentID] += amount
+	p.seenResults[req.IdempotencyKey] = req.PaymentID
+	return nil
+}
+
+func (p *fakePaymentProvider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
+
+// runBookingSaga executes the booking saga with payments going through provider
+func runBookingSaga(t *testing.T, provider PaymentProvider, confirmationSvc *MockBookingConfirmationService, bookingID string) (*MockSeatReservationService, error) {
+	t.Helper()
+	reservationSvc := NewMockSeatReservationService()
+	builder := NewBookingSagaBuilder(&BookingSagaConfig{
+		ReservationService:  reservationSvc,
+		PaymentService:      NewProviderPaymentService(provider),
+		ConfirmationService: confirmationSvc,
+		NotificationService: NewMockNotificationService(),
+		StepTimeout:         5 * time.Second,
+		MaxRetries:          0,
+	})
+
+	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{
+		Store: pkgsaga.NewMemoryStore(),
+	})
+	if err := orchestrator.RegisterDefinition(builder.Build()); err != nil {
+		t.Fatalf("failed to register saga definition: %v", err)
+	}
+
+	_, err := orchestrator.Execute(context.Background(), BookingSagaName, map[string]interface{}{
+		"booking_id":     bookingID,
+		"user_id":        "user-456",
+		"event_id":       "event-789",
+		"zone_id":        "zone-A",
+		"quantity":       2,
+		"total_price":    200.00,
+		"currency":       "THB",
+		"payment_method": "credit_card",
+	})
+	return reservationSvc, err
+}
+
+func assertCalls(t *testing.T, got, want []string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("provider calls = %v, want %v", got, want)
+	}
+}
+
+func TestProviderPaymentService_SagaAuthorizesThenCaptures(t *testing.T) {
+	provider := newFakePaymentProvider()
+	confirmationSvc := NewMockBookingConfirmationService()
+
+	if _, err := runBookingSaga(t, provider, confirmationSvc, "booking-123"); err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+
+	assertCalls(t, provider.Calls(), []string{
+		"authorize:booking-123:authorize",
+		"capture:booking-123:capture",
+	})
+	if provider.captured["pay-2"] != 200.00 {
+		t.Errorf("captured = %v, want 200.00 on pay-2", provider.captured)
+	}
+	confirmation, ok := confirmationSvc.GetConfirmation("booking-123")
+	if !ok || confirmation.PaymentID != "pay-2" {
+		t.Errorf("confirmation = %+v, want payment ID pay-2", confirmation)
+	}
+}
+
+func TestProviderPaymentService_ConfirmationFailureRefundsCapture(t *testing.T) {
+	provider := newFakePaymentProvider()
+	confirmationSvc := NewMockBookingConfirmationService()
+	confirmationSvc.ShouldFail = true
+
+	reservationSvc, err := runBookingSaga(t, provider, confirmationSvc, "booking-456")
+	if err == nil {
+		t.Fatal("expected saga execution to fail")
+	}
+
+	assertCalls(t, provider.Calls(), []string{
+		"authorize:booking-456:authorize",
+		"capture:booking-456:capture",
+		"refund:pay-2:refund",
+	})
+	if provider.refunded["pay-2"] != 200.00 {
+		t.Errorf("refunded = %v, want the full 200.00 on pay-2", provider.refunded)
+	}
+	if reservation, ok := reservationSvc.GetReservation("booking-456"); !ok || !reservation.Released {
+		t.Error("expected reservation to be released (compensated)")
+	}
+}
+
+func TestProviderPaymentService_DeclineReleasesSeatsWithoutCapture(t *testing.T) {
+	provider := newFakePaymentProvider()
+	provider.Decline = true
+
+	reservationSvc, err := runBookingSaga(t, provider, NewMockBookingConfirmationService(), "booking-789")
+	if err == nil {
+		t.Fatal("expected saga execution to fail")
+	}
+
+	// The step may be retried, but every attempt is the same authorization and
+	// nothing is captured
+	for _, call := range provider.Calls() {
+		if call != "authorize:booking-789:authorize" {
+			t.Errorf("unexpected provider call %q", call)
+		}
+	}
+	if reservation, ok := reservationSvc.GetReservation("booking-789"); !ok || !reservation.Released {
+		t.Error("expected reservation to be released (compensated)")
+	}
+
+	_, err = NewProviderPaymentService(provider).ProcessPayment(context.Background(), "booking-789", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Errorf("ProcessPayment() error = %v, want ErrPaymentDeclined", err)
+	}
+}
+
+func TestProviderPaymentService_CaptureFailureVoidsAuthorization(t *testing.T) {
+	provider := newFakePaymentProvider()
+	provider.FailCapture = true
+
+	reservationSvc, err := runBookingSaga(t, provider, NewMockBookingConfirmationService(), "booking-321")
+	if err == nil {
+		t.Fatal("expected saga execution to fail")
+	}
+
+	// Each retry replays the same authorization, fails to capture it and voids it again
+	calls := provider.Calls()
+	if len(calls) < 3 {
+		t.Fatalf("provider calls = %v, want authorize, capture and void", calls)
+	}
+	assertCalls(t, calls[:3], []string{
+		"authorize:booking-321:authorize",
+		"capture:booking-321:capture",
+		"void:auth-1",
+	})
+	if !provider.voided["auth-1"] {
+		t.Error("expected the authorization to be voided")
+	}
+	if reservation, ok := reservationSvc.GetReservation("booking-321"); !ok || !reservation.Released {
+		t.Error("expected reservation to be released (compensated)")
+	}
+}
+
+func TestProviderPaymentService_CaptureFailureVoidsAfterStepTimeout(t *testing.T) {
+	provider := newFakePaymentProvider()
+	provider.FailCapture = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewProviderPaymentService(provider).ProcessPayment(ctx, "booking-654", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if err == nil {
+		t.Fatal("expected ProcessPayment() to fail")
+	}
+	if !provider.voided["auth-1"] {
+		t.Error("expected the authorization to be voided with the step context cancelled")
+	}
+}
+
+func TestProviderPaymentService_RetriedPaymentChargesOnce(t *testing.T) {
+	provider := newFakePaymentProvider()
+	svc := NewProviderPaymentService(provider)
+	ctx := context.Background()
+
+	first, err := svc.ProcessPayment(ctx, "booking-123", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+	second, err := svc.ProcessPayment(ctx, "booking-123", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if err != nil {
+		t.Fatalf("retried ProcessPayment() error = %v", err)
+	}
+
+	if first != second {
+		t.Errorf("retried payment ID = %q, want %q", second, first)
+	}
+	if len(provider.captured) != 1 {
+		t.Errorf("captured %d payments, want 1", len(provider.captured))
+	}
+}
+
+func TestProviderPaymentService_RefundPartial(t *testing.T) {
+	provider := newFakePaymentProvider()
+	svc := NewProviderPaymentService(provider)
+	ctx := context.Background()
+
+	paymentID, err := svc.ProcessPayment(ctx, "booking-123", "user-456", "tenant-1", 200, "THB", "credit_card")
+	if err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+
+	if err := svc.RefundPartial(ctx, paymentID, 50, "one ticket returned", "ticket-1"); err != nil {
+		t.Fatalf("RefundPartial() error = %v", err)
+	}
+	// A retry with the same key refunds once
+	if err := svc.RefundPartial(ctx, paymentID, 50, "one ticket returned", "ticket-1"); err != nil {
+		t.Fatalf("retried RefundPartial() error = %v", err)
+	}
+	if provider.refunded[paymentID] != 50 {
+		t.Errorf("refunded = %.2f, want 50.00", provider.refunded[paymentID])
+	}
+
+	if err := svc.RefundPartial(ctx, paymentID, 0, "nothing", "ticket-2"); !errors.Is(err, ErrInvalidRefundAmount) {
+		t.Errorf("RefundPartial(0) error = %v, want ErrInvalidRefundAmount", err)
+	}
+
+	// A full refund afterwards returns only the remainder
+	if err := svc.RefundPayment(ctx, paymentID, "event cancelled"); err != nil {
+		t.Fatalf("RefundPayment() error = %v", err)
+	}
+	if provider.refunded[paymentID] != 200 {
+		t.Errorf("refunded = %.2f, want 200.00", provider.refunded[paymentID])
+	}
+
+	if err := svc.RefundPayment(ctx, "pay-unknown", "event cancelled"); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("RefundPayment(unknown) error = %v, want ErrPaymentNotFound", err)
+	}
+}
@@ -2,15 +2,16 @@ package service
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -31,6 +32,9 @@ type BookingService interface {
 	// ReleaseBooking releases a reservation (alias for CancelBooking)
 	ReleaseBooking(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
 
+	// CancelReservation releases an unpaid Redis hold and cancels its saga
+	CancelReservation(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error)
+
 	// GetBooking retrieves a booking by ID
 	GetBooking(ctx context.Context, bookingID, userID string) (*dto.BookingResponse, error)
 
@@ -43,6 +47,9 @@ type BookingService interface {
 	// GetPendingBookings retrieves pending reservations that are about to expire
 	GetPendingBookings(ctx context.Context, limit int) ([]*dto.BookingResponse, error)
 
+	// GetZoneAvailability retrieves the seats still available in a zone
+	GetZoneAvailability(ctx context.Context, zoneID string) (*dto.ZoneAvailabilityResponse, error)
+
 	// ExpireReservations marks expired reservations as expired
 	ExpireReservations(ctx context.Context, limit int) (int, error)
 }
@@ -51,11 +58,23 @@ type BookingService interface {
 type bookingService struct {
 	bookingRepo     repository.BookingRepository
 	reservationRepo repository.ReservationRepository
+	// availability serves polled availability reads, coalesced and briefly cached
+	availability    repository.ReservationRepository
 	eventPublisher  EventPublisher
 	zoneSyncer      ZoneSyncer
 	reservationTTL  time.Duration
 	maxPerUser      int
+	limitPolicy     LimitPolicy
 	defaultCurrency string
+	// overbookAllowances maps zone ID to the seats that zone may be oversold by
+	overbookAllowances map[string]int
+	confirmationCodes  ConfirmationCodeGenerator
+}
+
+// ConfirmationCodeGenerator issues the code a confirmed booking is looked up by
+// (satisfied by *saga.ConfirmationCodeGenerator)
+type ConfirmationCodeGenerator interface {
+	Generate(ctx context.Context) (string, error)
 }
 
 // BookingServiceConfig contains configuration for booking service
@@ -63,6 +82,18 @@ type BookingServiceConfig struct {
 	ReservationTTL  time.Duration
 	MaxPerUser      int
 	DefaultCurrency string
+	// LimitPolicy resolves the per-user limit for each reservation. Nil
+	// applies MaxPerUser to every event, role and tenant.
+	LimitPolicy LimitPolicy
+	// OverbookAllowances maps zone ID to the number of seats the zone may be
+	// oversold by to absorb no-shows. Zones not listed are never oversold.
+	OverbookAllowances map[string]int
+	// ConfirmationCodes issues confirmation codes. Nil checks codes against
+	// the booking repository when it can look them up.
+	ConfirmationCodes ConfirmationCodeGenerator
+	// AvailabilityCacheTTL is how long coalesced zone availability reads are
+	// reused (0 = repository.DefaultAvailabilityCacheTTL)
+	AvailabilityCacheTTL time.Duration
 }
 
 // NewBookingService creates a new booking service
@@ -76,6 +107,10 @@ func NewBookingService(
 	ttl := 10 * time.Minute
 	maxPerUser := 10
 	currency := "THB"
+	var overbookAllowances map[string]int
+	var limitPolicy LimitPolicy
+	var confirmationCodes ConfirmationCodeGenerator
+	var availabilityCacheTTL time.Duration
 	if cfg != nil {
 		if cfg.ReservationTTL > 0 {
 			ttl = cfg.ReservationTTL
@@ -86,6 +121,18 @@ func NewBookingService(
 		if cfg.DefaultCurrency != "" {
 			currency = cfg.DefaultCurrency
 		}
+		overbookAllowances = cfg.OverbookAllowances
+		limitPolicy = cfg.LimitPolicy
+		confirmationCodes = cfg.ConfirmationCodes
+		availabilityCacheTTL = cfg.AvailabilityCacheTTL
+	}
+	if limitPolicy == nil {
+		limitPolicy = &StaticLimitPolicy{Default: maxPerUser}
+	}
+	if confirmationCodes == nil {
+		// The Postgres repository looks codes up; other repositories get unchecked codes
+		store, _ := bookingRepo.(saga.ConfirmationCodeStore)
+		confirmationCodes = saga.NewConfirmationCodeGenerator(store)
 	}
 	// Use NoOpEventPublisher if none provided
 	if eventPublisher == nil {
@@ -94,19 +141,33 @@ func NewBookingService(
 	return &bookingService{
 		bookingRepo:     bookingRepo,
 		reservationRepo: reservationRepo,
+		availability:    repository.NewCoalescingReservationRepository(reservationRepo, availabilityCacheTTL),
 		eventPublisher:  eventPublisher,
 		zoneSyncer:      zoneSyncer,
 		reservationTTL:  ttl,
 		maxPerUser:      maxPerUser,
+		limitPolicy:     limitPolicy,
 		defaultCurrency: currency,
+
+		overbookAllowances: overbookAllowances,
+		confirmationCodes:  confirmationCodes,
 	}
 }
 
 // ReserveSeats reserves seats for a user with idempotency support
-func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *dto.ReserveSeatsRequest) (*dto.ReserveSeatsResponse, error) {
+func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *dto.ReserveSeatsRequest) (resp *dto.ReserveSeatsResponse, err error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.booking.reserve_seats")
 	defer span.End()
 
+	start := time.Now()
+	defer func() {
+		zoneID := ""
+		if req != nil {
+			zoneID = req.ZoneID
+		}
+		metrics.RecordReservationOutcome(ctx, zoneID, reservationOutcome(err), time.Since(start))
+	}()
+
 	// Validate request
 	if req == nil {
 		span.SetStatus(codes.Error, "invalid quantity")
@@ -176,15 +237,33 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 	}
 	totalPrice := unitPrice * float64(req.Quantity)
 
+	maxPerUser, err := s.limitPolicy.MaxPerUser(ctx, LimitKey{
+		EventID:  req.EventID,
+		UserRole: req.UserRole,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("max_per_user", maxPerUser))
+
 	// Reserve seats in Redis atomically
 	params := repository.ReserveParams{
 		ZoneID:     req.ZoneID,
+		ShowID:     req.ShowID,
 		UserID:     userID,
 		EventID:    req.EventID,
 		Quantity:   req.Quantity,
-		MaxPerUser: s.maxPerUser,
+		MaxPerUser: maxPerUser,
 		TTLSeconds: int(s.reservationTTL.Seconds()),
 		Price:      unitPrice,
+
+		OverbookAllowance: s.overbookAllowances[req.ZoneID],
+	}
+	if req.SingleUsePass {
+		params.QueuePass = req.QueuePass
 	}
 
 	result, err := s.reservationRepo.ReserveSeats(ctx, params)
@@ -194,11 +273,11 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 
 	if !result.Success {
 		switch result.ErrorCode {
-		case "INSUFFICIENT_STOCK":
+		case pkgredis.CodeInsufficientStock:
 			return nil, domain.ErrInsufficientSeats
-		case "USER_LIMIT_EXCEEDED":
+		case pkgredis.CodeUserLimitExceeded:
 			return nil, domain.ErrMaxTicketsExceeded
-		case "ZONE_NOT_FOUND":
+		case pkgredis.CodeZoneNotFound:
 			// Auto-sync zone from ticket service and retry once
 			if s.zoneSyncer != nil {
 				if syncErr := s.zoneSyncer.SyncZone(ctx, req.ZoneID); syncErr == nil {
@@ -214,20 +293,26 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 					}
 					// Retry failed, return the error
 					switch retryResult.ErrorCode {
-					case "INSUFFICIENT_STOCK":
+					case pkgredis.CodeInsufficientStock:
 						return nil, domain.ErrInsufficientSeats
-					case "USER_LIMIT_EXCEEDED":
+					case pkgredis.CodeUserLimitExceeded:
 						return nil, domain.ErrMaxTicketsExceeded
+					case pkgredis.CodeInvalidShowID:
+						return nil, domain.ErrInvalidShowID
 					default:
 						return nil, domain.ErrZoneNotFound
 					}
 				}
 			}
 			return nil, domain.ErrZoneNotFound
-		case "INVALID_QUANTITY":
+		case pkgredis.CodeInvalidQuantity:
 			return nil, domain.ErrInvalidQuantity
+		case pkgredis.CodeInvalidQueuePass:
+			return nil, domain.ErrQueuePassExpired
+		case pkgredis.CodeInvalidShowID:
+			return nil, domain.ErrInvalidShowID
 		default:
-			return nil, domain.ErrInvalidBookingStatus
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBookingStatus, result.Err())
 		}
 	}
 
@@ -267,6 +352,7 @@ createBooking:
 
 	// Record metrics
 	metrics.RecordReservation(ctx, booking.EventID, userID, booking.ZoneID, booking.Quantity)
+	metrics.RecordSeatsRemaining(ctx, booking.ZoneID, result.AvailableSeats)
 
 	// Add span event for reservation created
 	span.AddEvent("reservation_created", trace.WithAttributes(
@@ -288,6 +374,30 @@ createBooking:
 	}, nil
 }
 
+// reservationOutcome classifies a ReserveSeats result for the reservations_total metric
+func reservationOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.Is(err, domain.ErrInsufficientSeats):
+		return metrics.OutcomeInsufficientStock
+	case errors.Is(err, domain.ErrMaxTicketsExceeded):
+		return metrics.OutcomeUserLimitExceeded
+	case errors.Is(err, domain.ErrZoneNotFound):
+		return metrics.OutcomeZoneNotFound
+	case errors.Is(err, domain.ErrQueuePassExpired):
+		return metrics.OutcomeQueuePassRejected
+	case errors.Is(err, domain.ErrInvalidQuantity),
+		errors.Is(err, domain.ErrInvalidEventID),
+		errors.Is(err, domain.ErrInvalidShowID),
+		errors.Is(err, domain.ErrInvalidZoneID),
+		errors.Is(err, domain.ErrInvalidUserID):
+		return metrics.OutcomeInvalidRequest
+	default:
+		return metrics.OutcomeError
+	}
+}
+
 // ConfirmBooking confirms a reservation with payment
 func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID string, req *dto.ConfirmBookingRequest) (*dto.ConfirmBookingResponse, error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.booking.confirm")
@@ -341,6 +451,15 @@ func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID s
 		paymentID = req.PaymentID
 	}
 
+	// Draw the confirmation code before confirming anywhere, so failing to
+	// get one leaves the booking reserved
+	confirmationCode, err := s.confirmationCodes.Generate(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	// Confirm in Redis first
 	redisResult, err := s.reservationRepo.ConfirmBooking(ctx, bookingID, userID, paymentID)
 	if err != nil {
@@ -351,34 +470,28 @@ func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID s
 
 	if !redisResult.Success {
 		switch redisResult.ErrorCode {
-		case "RESERVATION_NOT_FOUND":
+		case pkgredis.CodeReservationNotFound:
 			span.SetStatus(codes.Error, "reservation not found")
 			return nil, domain.ErrReservationNotFound
-		case "INVALID_USER":
+		case pkgredis.CodeInvalidUserID:
 			span.SetStatus(codes.Error, "invalid user")
 			return nil, domain.ErrInvalidUserID
-		case "ALREADY_CONFIRMED":
+		case pkgredis.CodeAlreadyConfirmed:
 			span.SetStatus(codes.Error, "already confirmed")
 			return nil, domain.ErrAlreadyConfirmed
-		case "RESERVATION_EXPIRED":
-			span.SetStatus(codes.Error, "reservation expired")
-			return nil, domain.ErrReservationExpired
 		default:
 			span.SetStatus(codes.Error, "invalid booking status")
-			return nil, domain.ErrInvalidBookingStatus
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBookingStatus, redisResult.Err())
 		}
 	}
 
 	// Update booking in PostgreSQL
-	if err := s.bookingRepo.Confirm(ctx, bookingID, paymentID); err != nil {
+	if err := s.bookingRepo.Confirm(ctx, bookingID, paymentID, confirmationCode); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	// Generate confirmation code
-	confirmationCode := generateConfirmationCode()
-
 	// Update booking object for event publishing
 	booking.Status = domain.BookingStatusConfirmed
 	booking.PaymentID = paymentID
@@ -469,13 +582,13 @@ func (s *bookingService) CancelBooking(ctx context.Context, bookingID, userID st
 
 	if !releaseResult.Success {
 		switch releaseResult.ErrorCode {
-		case "RESERVATION_NOT_FOUND":
+		case pkgredis.CodeReservationNotFound:
 			// If not found in Redis, it might have expired
 			// Still proceed to cancel in PostgreSQL
-		case "INVALID_USER":
+		case pkgredis.CodeInvalidUserID:
 			span.SetStatus(codes.Error, "invalid user")
 			return nil, domain.ErrInvalidUserID
-		case "ALREADY_RELEASED":
+		case pkgredis.CodeAlreadyReleased:
 			span.SetStatus(codes.Error, "already released")
 			return nil, domain.ErrAlreadyReleased
 		}
@@ -524,6 +637,70 @@ func (s *bookingService) ReleaseBooking(ctx context.Context, bookingID, userID s
 	return s.CancelBooking(ctx, bookingID, userID)
 }
 
+// CancelReservation cancels a hold before payment. Redis is the source of
+// truth for the hold: release_seats checks ownership and returns the seats,
+// then the booking row (if written yet) is moved to cancelled. No saga runs
+// before payment, so there is none to cancel.
+func (s *bookingService) CancelReservation(ctx context.Context, bookingID, userID string) (*dto.ReleaseBookingResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.booking.cancel_reservation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.String("user_id", userID),
+	)
+
+	// Validate inputs
+	if bookingID == "" {
+		span.SetStatus(codes.Error, "invalid booking_id")
+		return nil, domain.ErrInvalidBookingID
+	}
+	if userID == "" {
+		span.SetStatus(codes.Error, "invalid user_id")
+		return nil, domain.ErrInvalidUserID
+	}
+
+	releaseResult, err := s.reservationRepo.ReleaseSeats(ctx, bookingID, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if !releaseResult.Success {
+		span.SetStatus(codes.Error, releaseResult.ErrorCode)
+		switch releaseResult.ErrorCode {
+		case pkgredis.CodeReservationNotFound:
+			return nil, domain.ErrReservationNotFound
+		case pkgredis.CodeInvalidUserID:
+			return nil, domain.ErrInvalidUserID
+		case pkgredis.CodeAlreadyReleased:
+			return nil, domain.ErrAlreadyReleased
+		default:
+			return nil, releaseResult.Err()
+		}
+	}
+
+	// The fast path may not have written the booking row yet
+	if err := s.bookingRepo.Cancel(ctx, bookingID); err != nil && !errors.Is(err, domain.ErrBookingNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.AddEvent("reservation_cancelled", trace.WithAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.Int64("available_seats", releaseResult.AvailableSeats),
+	))
+
+	span.SetStatus(codes.Ok, "")
+	return &dto.ReleaseBookingResponse{
+		BookingID: bookingID,
+		Status:    "cancelled",
+		Message:   "Reservation cancelled successfully",
+	}, nil
+}
+
 // GetBooking retrieves a booking by ID
 func (s *bookingService) GetBooking(ctx context.Context, bookingID, userID string) (*dto.BookingResponse, error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.booking.get")
@@ -641,7 +818,12 @@ func (s *bookingService) GetUserBookingSummary(ctx context.Context, userID, even
 	}
 
 	// Calculate remaining slots
-	maxAllowed := s.maxPerUser
+	maxAllowed, err := s.limitPolicy.MaxPerUser(ctx, LimitKey{EventID: eventID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 	remainingSlots := maxAllowed - bookedCount
 	if remainingSlots < 0 {
 		remainingSlots = 0
@@ -689,6 +871,32 @@ func (s *bookingService) GetPendingBookings(ctx context.Context, limit int) ([]*
 	return responses, nil
 }
 
+// GetZoneAvailability retrieves the seats still available in a zone. Concurrent
+// reads of the same zone share one Redis round trip and may lag writes by up
+// to the availability cache TTL.
+func (s *bookingService) GetZoneAvailability(ctx context.Context, zoneID string) (*dto.ZoneAvailabilityResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.booking.get_zone_availability")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("zone_id", zoneID))
+
+	if zoneID == "" {
+		span.SetStatus(codes.Error, "invalid zone_id")
+		return nil, domain.ErrInvalidZoneID
+	}
+
+	seats, err := s.availability.GetZoneAvailability(ctx, zoneID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("available_seats", seats))
+	span.SetStatus(codes.Ok, "")
+	return &dto.ZoneAvailabilityResponse{ZoneID: zoneID, AvailableSeats: seats}, nil
+}
+
 // ExpireReservations marks expired reservations as expired
 func (s *bookingService) ExpireReservations(ctx context.Context, limit int) (int, error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.booking.expire_reservations")
@@ -738,12 +946,3 @@ func (s *bookingService) ExpireReservations(ctx context.Context, limit int) (int
 	span.SetStatus(codes.Ok, "")
 	return expiredCount, nil
 }
-
-// generateConfirmationCode generates a random confirmation code
-func generateConfirmationCode() string {
-	bytes := make([]byte, 4)
-	if _, err := rand.Read(bytes); err != nil {
-		return uuid.New().String()[:8]
-	}
-	return hex.EncodeToString(bytes)
-}
@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
 )
 
 // MockBookingRepository is a mock implementation of BookingRepository
@@ -19,7 +21,7 @@ type MockBookingRepository struct {
 	UpdateFunc                 func(ctx context.Context, booking *domain.Booking) error
 	UpdateStatusFunc           func(ctx context.Context, id string, status domain.BookingStatus) error
 	DeleteFunc                 func(ctx context.Context, id string) error
-	ConfirmFunc                func(ctx context.Context, id, paymentID string) error
+	ConfirmFunc                func(ctx context.Context, id, paymentID, confirmationCode string) error
 	CancelFunc                 func(ctx context.Context, id string) error
 	GetExpiredReservationsFunc func(ctx context.Context, limit int) ([]*domain.Booking, error)
 	MarkAsExpiredFunc          func(ctx context.Context, id string) error
@@ -70,9 +72,9 @@ func (m *MockBookingRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *MockBookingRepository) Confirm(ctx context.Context, id, paymentID string) error {
+func (m *MockBookingRepository) Confirm(ctx context.Context, id, paymentID, confirmationCode string) error {
 	if m.ConfirmFunc != nil {
-		return m.ConfirmFunc(ctx, id, paymentID)
+		return m.ConfirmFunc(ctx, id, paymentID, confirmationCode)
 	}
 	return nil
 }
@@ -125,7 +127,9 @@ type MockReservationRepository struct {
 	ConfirmBookingFunc      func(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error)
 	ReleaseSeatsFunc        func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error)
 	GetZoneAvailabilityFunc func(ctx context.Context, zoneID string) (int64, error)
+	GetShowAvailabilityFunc func(ctx context.Context, showID string) (int64, error)
 	SetZoneAvailabilityFunc func(ctx context.Context, zoneID string, seats int64) error
+	InitZoneFunc            func(ctx context.Context, zoneID, showID string, seats int64, ttl time.Duration) (bool, error)
 }
 
 func (m *MockReservationRepository) ReserveSeats(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
@@ -164,6 +168,13 @@ func (m *MockReservationRepository) GetZoneAvailability(ctx context.Context, zon
 	return 100, nil
 }
 
+func (m *MockReservationRepository) GetShowAvailability(ctx context.Context, showID string) (int64, error) {
+	if m.GetShowAvailabilityFunc != nil {
+		return m.GetShowAvailabilityFunc(ctx, showID)
+	}
+	return 0, nil
+}
+
 func (m *MockReservationRepository) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
 	if m.SetZoneAvailabilityFunc != nil {
 		return m.SetZoneAvailabilityFunc(ctx, zoneID, seats)
@@ -171,6 +182,13 @@ func (m *MockReservationRepository) SetZoneAvailability(ctx context.Context, zon
 	return nil
 }
 
+func (m *MockReservationRepository) InitZone(ctx context.Context, zoneID, showID string, seats int64, ttl time.Duration) (bool, error) {
+	if m.InitZoneFunc != nil {
+		return m.InitZoneFunc(ctx, zoneID, showID, seats, ttl)
+	}
+	return true, nil
+}
+
 func TestBookingService_ReserveSeats(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -348,6 +366,175 @@ func TestBookingService_ReserveSeats(t *testing.T) {
 	}
 }
 
+func TestBookingService_ReserveSeats_OverbookAllowance(t *testing.T) {
+	gotAllowances := map[string]int{}
+	reservationRepo := &MockReservationRepository{
+		ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+			gotAllowances[params.ZoneID] = params.OverbookAllowance
+			return &repository.ReserveResult{Success: true, BookingID: "booking-" + params.ZoneID}, nil
+		},
+	}
+	bookingRepo := &MockBookingRepository{
+		CreateFunc: func(ctx context.Context, booking *domain.Booking) error {
+			return nil
+		},
+	}
+
+	svc := NewBookingService(bookingRepo, reservationRepo, nil, nil, &BookingServiceConfig{
+		OverbookAllowances: map[string]int{"zone-oversold": 3},
+	})
+
+	for _, zoneID := range []string{"zone-oversold", "zone-strict"} {
+		_, err := svc.ReserveSeats(context.Background(), "user-001", &dto.ReserveSeatsRequest{
+			EventID:  "event-001",
+			ZoneID:   zoneID,
+			ShowID:   "show-001",
+			Quantity: 1,
+		})
+		if err != nil {
+			t.Fatalf("ReserveSeats(%s) unexpected error = %v", zoneID, err)
+		}
+	}
+
+	if gotAllowances["zone-oversold"] != 3 {
+		t.Errorf("OverbookAllowance for configured zone = %d, want 3", gotAllowances["zone-oversold"])
+	}
+	if gotAllowances["zone-strict"] != 0 {
+		t.Errorf("OverbookAllowance for unconfigured zone = %d, want 0", gotAllowances["zone-strict"])
+	}
+}
+
+func TestBookingService_ReserveSeats_LimitPolicy(t *testing.T) {
+	var gotMaxPerUser int
+	reservationRepo := &MockReservationRepository{
+		ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+			gotMaxPerUser = params.MaxPerUser
+			return &repository.ReserveResult{Success: true, BookingID: "booking-001"}, nil
+		},
+	}
+	bookingRepo := &MockBookingRepository{
+		CreateFunc: func(ctx context.Context, booking *domain.Booking) error {
+			return nil
+		},
+	}
+
+	svc := NewBookingService(bookingRepo, reservationRepo, nil, nil, &BookingServiceConfig{
+		MaxPerUser: 4,
+		LimitPolicy: &StaticLimitPolicy{
+			Default: 4,
+			Events:  map[string]int{"event-resale": 2},
+			Roles:   map[string]int{"vip": 8},
+			Tenants: map[string]int{"tenant-partner": 6},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		eventID  string
+		role     string
+		tenantID string
+		want     int
+	}{
+		{name: "default", eventID: "event-001", role: "customer", tenantID: "tenant-001", want: 4},
+		{name: "vip role", eventID: "event-001", role: "vip", tenantID: "tenant-001", want: 8},
+		{name: "tenant", eventID: "event-001", role: "customer", tenantID: "tenant-partner", want: 6},
+		{name: "resale-protected event caps vip", eventID: "event-resale", role: "vip", tenantID: "tenant-001", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ReserveSeats(context.Background(), "user-001", &dto.ReserveSeatsRequest{
+				EventID:  tt.eventID,
+				ZoneID:   "zone-001",
+				ShowID:   "show-001",
+				TenantID: tt.tenantID,
+				Quantity: 1,
+				UserRole: tt.role,
+			})
+			if err != nil {
+				t.Fatalf("ReserveSeats() unexpected error = %v", err)
+			}
+			if gotMaxPerUser != tt.want {
+				t.Errorf("MaxPerUser passed to script = %d, want %d", gotMaxPerUser, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookingService_ReserveSeats_ZoneOfAnotherShow(t *testing.T) {
+	reservationRepo := &MockReservationRepository{
+		ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+			return &repository.ReserveResult{Success: false, ErrorCode: "INVALID_SHOW_ID"}, nil
+		},
+	}
+	svc := NewBookingService(&MockBookingRepository{}, reservationRepo, nil, nil, &BookingServiceConfig{})
+
+	_, err := svc.ReserveSeats(context.Background(), "user-001", &dto.ReserveSeatsRequest{
+		EventID:  "event-001",
+		ZoneID:   "zone-001",
+		ShowID:   "show-other",
+		Quantity: 1,
+	})
+	if !errors.Is(err, domain.ErrInvalidShowID) {
+		t.Errorf("ReserveSeats() error = %v, want %v", err, domain.ErrInvalidShowID)
+	}
+}
+
+func TestBookingService_ReserveSeats_SingleUsePass(t *testing.T) {
+	var gotPasses []string
+	consumed := false
+	reservationRepo := &MockReservationRepository{
+		ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+			gotPasses = append(gotPasses, params.QueuePass)
+			if params.QueuePass != "" {
+				if consumed {
+					return &repository.ReserveResult{Success: false, ErrorCode: "INVALID_QUEUE_PASS"}, nil
+				}
+				consumed = true
+			}
+			return &repository.ReserveResult{Success: true, BookingID: "booking-001"}, nil
+		},
+	}
+	bookingRepo := &MockBookingRepository{
+		CreateFunc: func(ctx context.Context, booking *domain.Booking) error {
+			return nil
+		},
+	}
+
+	svc := NewBookingService(bookingRepo, reservationRepo, nil, nil, &BookingServiceConfig{})
+
+	req := &dto.ReserveSeatsRequest{
+		EventID:       "event-001",
+		ZoneID:        "zone-001",
+		ShowID:        "show-001",
+		Quantity:      1,
+		QueuePass:     "pass-001",
+		SingleUsePass: true,
+	}
+	if _, err := svc.ReserveSeats(context.Background(), "user-001", req); err != nil {
+		t.Fatalf("ReserveSeats() first use unexpected error = %v", err)
+	}
+	if _, err := svc.ReserveSeats(context.Background(), "user-001", req); !errors.Is(err, domain.ErrQueuePassExpired) {
+		t.Errorf("ReserveSeats() replayed pass error = %v, want %v", err, domain.ErrQueuePassExpired)
+	}
+
+	// Without single-use the pass isn't sent to the reservation script
+	req.SingleUsePass = false
+	if _, err := svc.ReserveSeats(context.Background(), "user-001", req); err != nil {
+		t.Fatalf("ReserveSeats() multi-use unexpected error = %v", err)
+	}
+
+	want := []string{"pass-001", "pass-001", ""}
+	if len(gotPasses) != len(want) {
+		t.Fatalf("ReserveSeats() called repository %d times, want %d", len(gotPasses), len(want))
+	}
+	for i := range want {
+		if gotPasses[i] != want[i] {
+			t.Errorf("call %d QueuePass = %q, want %q", i, gotPasses[i], want[i])
+		}
+	}
+}
+
 func TestBookingService_ConfirmBooking(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -377,7 +564,7 @@ func TestBookingService_ConfirmBooking(t *testing.T) {
 						Status:  "CONFIRMED",
 					}, nil
 				}
-				br.ConfirmFunc = func(ctx context.Context, id, paymentID string) error {
+				br.ConfirmFunc = func(ctx context.Context, id, paymentID, confirmationCode string) error {
 					return nil
 				}
 			},
@@ -491,6 +678,96 @@ func TestBookingService_ConfirmBooking(t *testing.T) {
 	}
 }
 
+// stubConfirmationCodes returns a fixed code or error
+type stubConfirmationCodes struct {
+	code string
+	err  error
+}
+
+func (s stubConfirmationCodes) Generate(ctx context.Context) (string, error) {
+	return s.code, s.err
+}
+
+func TestBookingService_ConfirmBooking_ConfirmationCode(t *testing.T) {
+	newRepos := func(persisted *string) (*MockBookingRepository, *MockReservationRepository) {
+		br := &MockBookingRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*domain.Booking, error) {
+				return &domain.Booking{
+					ID:        id,
+					UserID:    "user-001",
+					Status:    domain.BookingStatusReserved,
+					ExpiresAt: time.Now().Add(10 * time.Minute),
+				}, nil
+			},
+			ConfirmFunc: func(ctx context.Context, id, paymentID, confirmationCode string) error {
+				*persisted = confirmationCode
+				return nil
+			},
+		}
+		rr := &MockReservationRepository{
+			ConfirmBookingFunc: func(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error) {
+				return &repository.ConfirmResult{Success: true, Status: "CONFIRMED"}, nil
+			},
+		}
+		return br, rr
+	}
+	req := &dto.ConfirmBookingRequest{PaymentID: "payment-123"}
+
+	t.Run("persists and returns the generated code", func(t *testing.T) {
+		var persisted string
+		br, rr := newRepos(&persisted)
+		svc := NewBookingService(br, rr, nil, nil, &BookingServiceConfig{
+			ConfirmationCodes: stubConfirmationCodes{code: "7KQ2M9XD"},
+		})
+
+		resp, err := svc.ConfirmBooking(context.Background(), "booking-123", "user-001", req)
+		if err != nil {
+			t.Fatalf("ConfirmBooking() unexpected error = %v", err)
+		}
+		if persisted != "7KQ2M9XD" || resp.ConfirmationCode != "7KQ2M9XD" {
+			t.Errorf("ConfirmBooking() persisted %q, returned %q, want 7KQ2M9XD", persisted, resp.ConfirmationCode)
+		}
+	})
+
+	t.Run("default generator issues Crockford codes", func(t *testing.T) {
+		var persisted string
+		br, rr := newRepos(&persisted)
+		svc := NewBookingService(br, rr, nil, nil, nil)
+
+		resp, err := svc.ConfirmBooking(context.Background(), "booking-123", "user-001", req)
+		if err != nil {
+			t.Fatalf("ConfirmBooking() unexpected error = %v", err)
+		}
+		if len(persisted) != saga.ConfirmationCodeLength || strings.Trim(persisted, saga.ConfirmationCodeAlphabet) != "" {
+			t.Errorf("ConfirmBooking() persisted code %q, want %d Crockford base32 characters", persisted, saga.ConfirmationCodeLength)
+		}
+		if resp.ConfirmationCode != persisted {
+			t.Errorf("ConfirmBooking() returned %q, persisted %q", resp.ConfirmationCode, persisted)
+		}
+	})
+
+	t.Run("generator failure leaves the booking reserved", func(t *testing.T) {
+		var persisted string
+		br, rr := newRepos(&persisted)
+		redisConfirmed := false
+		rr.ConfirmBookingFunc = func(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error) {
+			redisConfirmed = true
+			return &repository.ConfirmResult{Success: true, Status: "CONFIRMED"}, nil
+		}
+		svc := NewBookingService(br, rr, nil, nil, &BookingServiceConfig{
+			ConfirmationCodes: stubConfirmationCodes{err: saga.ErrConfirmationCodeExhausted},
+		})
+
+		_, err := svc.ConfirmBooking(context.Background(), "booking-123", "user-001", req)
+		if !errors.Is(err, saga.ErrConfirmationCodeExhausted) {
+			t.Fatalf("ConfirmBooking() error = %v, want ErrConfirmationCodeExhausted", err)
+		}
+		if redisConfirmed || persisted != "" {
+			t.Error("ConfirmBooking() confirmed the booking without a confirmation code")
+		}
+	})
+}
+
 func TestBookingService_CancelBooking(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -597,6 +874,89 @@ func TestBookingService_CancelBooking(t *testing.T) {
 	}
 }
 
+func TestBookingService_CancelReservation(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		setupMocks func(*MockBookingRepository, *MockReservationRepository)
+		wantErr    error
+	}{
+		{
+			name:   "successful cancellation",
+			userID: "user-001",
+			setupMocks: func(br *MockBookingRepository, rr *MockReservationRepository) {
+				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					return &repository.ReleaseResult{Success: true, AvailableSeats: 10}, nil
+				}
+			},
+		},
+		{
+			name:   "booking row not written yet",
+			userID: "user-001",
+			setupMocks: func(br *MockBookingRepository, rr *MockReservationRepository) {
+				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					return &repository.ReleaseResult{Success: true}, nil
+				}
+				br.CancelFunc = func(ctx context.Context, id string) error {
+					return domain.ErrBookingNotFound
+				}
+			},
+		},
+		{
+			name:   "reservation owned by another user",
+			userID: "user-002",
+			setupMocks: func(br *MockBookingRepository, rr *MockReservationRepository) {
+				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					return &repository.ReleaseResult{Success: false, ErrorCode: "INVALID_USER_ID"}, nil
+				}
+				br.CancelFunc = func(ctx context.Context, id string) error {
+					t.Error("Cancel() should not be called for another user's reservation")
+					return nil
+				}
+			},
+			wantErr: domain.ErrInvalidUserID,
+		},
+		{
+			name:   "reservation not found",
+			userID: "user-001",
+			setupMocks: func(br *MockBookingRepository, rr *MockReservationRepository) {
+				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					return &repository.ReleaseResult{Success: false, ErrorCode: "RESERVATION_NOT_FOUND"}, nil
+				}
+			},
+			wantErr: domain.ErrReservationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookingRepo := &MockBookingRepository{}
+			reservationRepo := &MockReservationRepository{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(bookingRepo, reservationRepo)
+			}
+
+			svc := NewBookingService(bookingRepo, reservationRepo, nil, nil, nil)
+
+			resp, err := svc.CancelReservation(context.Background(), "booking-123", tt.userID)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CancelReservation() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CancelReservation() unexpected error = %v", err)
+			}
+			if resp.Status != "cancelled" {
+				t.Errorf("CancelReservation() status = %v, want cancelled", resp.Status)
+			}
+		})
+	}
+}
+
 func TestBookingService_GetBooking(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -878,6 +1238,35 @@ func TestBookingService_ExpireReservations(t *testing.T) {
 	}
 }
 
+func TestBookingService_GetZoneAvailability(t *testing.T) {
+	reads := 0
+	reservationRepo := &MockReservationRepository{
+		GetZoneAvailabilityFunc: func(ctx context.Context, zoneID string) (int64, error) {
+			reads++
+			return 42, nil
+		},
+	}
+	svc := NewBookingService(nil, reservationRepo, nil, nil, &BookingServiceConfig{AvailabilityCacheTTL: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := svc.GetZoneAvailability(ctx, "zone-001")
+		if err != nil {
+			t.Fatalf("GetZoneAvailability() error = %v", err)
+		}
+		if result.ZoneID != "zone-001" || result.AvailableSeats != 42 {
+			t.Errorf("GetZoneAvailability() = %+v, want zone-001 with 42 seats", result)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("Expected repeated reads to be served from cache, got %d repository reads", reads)
+	}
+
+	if _, err := svc.GetZoneAvailability(ctx, ""); !errors.Is(err, domain.ErrInvalidZoneID) {
+		t.Errorf("GetZoneAvailability(\"\") error = %v, want %v", err, domain.ErrInvalidZoneID)
+	}
+}
+
 func TestBookingServiceConfig(t *testing.T) {
 	t.Run("default config", func(t *testing.T) {
 		svc := NewBookingService(nil, nil, nil, nil, nil)
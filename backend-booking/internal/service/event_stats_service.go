@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// EventStatsService defines the interface for the admin reservation snapshot
+type EventStatsService interface {
+	// GetEventStats gets seats remaining, active holds and queue length for an event
+	GetEventStats(ctx context.Context, eventID string) (*dto.EventStatsResponse, error)
+}
+
+// eventStatsService implements EventStatsService
+type eventStatsService struct {
+	statsRepo repository.EventStatsRepository
+	queueRepo repository.QueueRepository
+}
+
+// NewEventStatsService creates a new event stats service
+func NewEventStatsService(
+	statsRepo repository.EventStatsRepository,
+	queueRepo repository.QueueRepository,
+) EventStatsService {
+	return &eventStatsService{
+		statsRepo: statsRepo,
+		queueRepo: queueRepo,
+	}
+}
+
+// GetEventStats combines the event's reservation stats with its queue length
+func (s *eventStatsService) GetEventStats(ctx context.Context, eventID string) (*dto.EventStatsResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.event_stats.get")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if eventID == "" {
+		span.SetStatus(codes.Error, "invalid event ID")
+		return nil, domain.ErrInvalidEventID
+	}
+
+	stats, err := s.statsRepo.GetEventStats(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	queueLength, err := s.queueRepo.GetQueueSize(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	zones := make([]dto.ZoneStatsResponse, len(stats.Zones))
+	for i, zone := range stats.Zones {
+		zones[i] = dto.ZoneStatsResponse{ZoneID: zone.ZoneID, SeatsRemaining: zone.SeatsRemaining}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &dto.EventStatsResponse{
+		EventID:        eventID,
+		SeatsRemaining: stats.SeatsRemaining,
+		TotalReserved:  stats.SeatsReserved,
+		ActiveHolds:    stats.ActiveHolds,
+		QueueLength:    queueLength,
+		Zones:          zones,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// ReservationHoldReleaser releases a cancelled saga's seat hold through the
+// release_seats script. The saga step worker runs it for release-seats
+// compensations; plug it into the saga state machine with
+// pkgsaga.StateMachine.WithHoldReleaser.
+type ReservationHoldReleaser struct {
+	reservationRepo repository.ReservationRepository
+}
+
+var _ pkgsaga.HoldReleaser = (*ReservationHoldReleaser)(nil)
+
+// NewReservationHoldReleaser creates a hold releaser backed by Redis reservations
+func NewReservationHoldReleaser(reservationRepo repository.ReservationRepository) *ReservationHoldReleaser {
+	return &ReservationHoldReleaser{reservationRepo: reservationRepo}
+}
+
+// ReleaseHold returns the saga's held seats to inventory. A hold that already
+// expired or was released leaves nothing to do and counts as success.
+func (r *ReservationHoldReleaser) ReleaseHold(ctx context.Context, saga *pkgsaga.BookingSaga) error {
+	result, err := r.reservationRepo.ReleaseSeats(ctx, saga.BookingID, saga.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch result.ErrorCode {
+	case pkgredis.CodeReservationNotFound, pkgredis.CodeAlreadyReleased:
+		return nil
+	}
+	return result.Err()
+}
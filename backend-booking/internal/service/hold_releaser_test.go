@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+func TestReservationHoldReleaser_CancelRestoresAvailability(t *testing.T) {
+	ctx := context.Background()
+
+	// A zone with 8 of 10 seats left after a 2-seat hold
+	available := int64(8)
+	holds := map[string]int64{"booking-123": 2}
+	reservationRepo := &MockReservationRepository{
+		ReleaseSeatsFunc: func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+			quantity, ok := holds[bookingID]
+			if !ok {
+				return &repository.ReleaseResult{ErrorCode: pkgredis.CodeReservationNotFound}, nil
+			}
+			delete(holds, bookingID)
+			available += quantity
+			return &repository.ReleaseResult{Success: true, AvailableSeats: available}, nil
+		},
+		GetZoneAvailabilityFunc: func(ctx context.Context, zoneID string) (int64, error) {
+			return available, nil
+		},
+	}
+
+	sagas := pkgsaga.NewStateMachine(pkgsaga.NewMemoryStateStore()).
+		WithHoldReleaser(NewReservationHoldReleaser(reservationRepo))
+	bookingSaga, err := sagas.CreateSaga(ctx, "booking-123", "event-001", "user-001", nil)
+	if err != nil {
+		t.Fatalf("CreateSaga() error = %v", err)
+	}
+	if _, err := sagas.MarkReserved(ctx, bookingSaga.ID, "booking-123"); err != nil {
+		t.Fatalf("MarkReserved() error = %v", err)
+	}
+
+	cancelled, err := sagas.MarkCancelled(ctx, bookingSaga.ID, "user cancelled")
+	if err != nil {
+		t.Fatalf("MarkCancelled() error = %v", err)
+	}
+	if cancelled.State != pkgsaga.StateCancelled {
+		t.Errorf("saga state = %s, want CANCELLED", cancelled.State)
+	}
+
+	if got, _ := reservationRepo.GetZoneAvailability(ctx, "zone-001"); got != 10 {
+		t.Errorf("zone availability = %d, want 10 after cancellation", got)
+	}
+	if _, held := holds["booking-123"]; held {
+		t.Error("hold for booking-123 should be released")
+	}
+}
+
+func TestReservationHoldReleaser_ReleaseHold(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *repository.ReleaseResult
+		err     error
+		wantErr bool
+	}{
+		{name: "released", result: &repository.ReleaseResult{Success: true}},
+		{name: "already expired", result: &repository.ReleaseResult{ErrorCode: pkgredis.CodeReservationNotFound}},
+		{name: "already released", result: &repository.ReleaseResult{ErrorCode: pkgredis.CodeAlreadyReleased}},
+		{name: "owned by another user", result: &repository.ReleaseResult{ErrorCode: pkgredis.CodeInvalidUserID}, wantErr: true},
+		{name: "redis error", err: errors.New("connection refused"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBookingID, gotUserID string
+			releaser := NewReservationHoldReleaser(&MockReservationRepository{
+				ReleaseSeatsFunc: func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					gotBookingID, gotUserID = bookingID, userID
+					return tt.result, tt.err
+				},
+			})
+
+			err := releaser.ReleaseHold(context.Background(), &pkgsaga.BookingSaga{BookingID: "booking-123", UserID: "user-001"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReleaseHold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotBookingID != "booking-123" || gotUserID != "user-001" {
+				t.Errorf("ReleaseSeats called with (%s, %s), want (booking-123, user-001)", gotBookingID, gotUserID)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultLimitPolicyCacheTTL is how long a resolved per-user limit is reused
+const DefaultLimitPolicyCacheTTL = 30 * time.Second
+
+// LimitKey identifies the reservation a per-user limit is resolved for
+type LimitKey struct {
+	EventID  string
+	UserRole string
+	TenantID string
+}
+
+// LimitPolicy resolves the most seats a user may hold for an event, which is
+// passed to reserve_seats as max_per_user. 0 means no limit.
+type LimitPolicy interface {
+	MaxPerUser(ctx context.Context, key LimitKey) (int, error)
+}
+
+// StaticLimitPolicy resolves limits from fixed overrides. The most specific
+// override wins: the event's, then the role's, then the tenant's, then
+// Default. An event override therefore caps VIPs too, which is what
+// resale-protected events want.
+type StaticLimitPolicy struct {
+	Default int
+	Events  map[string]int // event ID -> limit
+	Roles   map[string]int // user role -> limit
+	Tenants map[string]int // tenant ID -> limit
+}
+
+// MaxPerUser returns the limit for key
+func (p *StaticLimitPolicy) MaxPerUser(ctx context.Context, key LimitKey) (int, error) {
+	if limit, ok := p.Events[key.EventID]; ok {
+		return limit, nil
+	}
+	if limit, ok := p.Roles[key.UserRole]; ok && key.UserRole != "" {
+		return limit, nil
+	}
+	if limit, ok := p.Tenants[key.TenantID]; ok && key.TenantID != "" {
+		return limit, nil
+	}
+	return p.Default, nil
+}
+
+// cachedLimit is a resolved limit and when it stops being reused
+type cachedLimit struct {
+	limit     int
+	expiresAt time.Time
+}
+
+// CachingLimitPolicy wraps a LimitPolicy backed by a slower store (a database
+// or a remote config service) so each key is resolved at most once per TTL,
+// with concurrent misses for the same key sharing one lookup. Policy changes
+// take up to the TTL to apply.
+type CachingLimitPolicy struct {
+	policy LimitPolicy
+	ttl    time.Duration
+	group  singleflight.Group
+
+	mu    sync.RWMutex
+	cache map[LimitKey]cachedLimit
+}
+
+// NewCachingLimitPolicy wraps policy. A non-positive ttl uses
+// DefaultLimitPolicyCacheTTL.
+func NewCachingLimitPolicy(policy LimitPolicy, ttl time.Duration) *CachingLimitPolicy {
+	if ttl <= 0 {
+		ttl = DefaultLimitPolicyCacheTTL
+	}
+	return &CachingLimitPolicy{
+		policy: policy,
+		ttl:    ttl,
+		cache:  make(map[LimitKey]cachedLimit),
+	}
+}
+
+// MaxPerUser returns the cached limit for key if it is fresh, otherwise
+// resolves it once on behalf of every concurrent caller. Errors are not cached.
+func (p *CachingLimitPolicy) MaxPerUser(ctx context.Context, key LimitKey) (int, error) {
+	p.mu.RLock()
+	entry, ok := p.cache[key]
+	p.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.limit, nil
+	}
+
+	v, err, _ := p.group.Do(key.EventID+"\x00"+key.UserRole+"\x00"+key.TenantID, func() (interface{}, error) {
+		// The lookup is shared, so one caller cancelling must not fail the others
+		limit, err := p.policy.MaxPerUser(context.WithoutCancel(ctx), key)
+		if err != nil {
+			return 0, err
+		}
+
+		p.mu.Lock()
+		p.cache[key] = cachedLimit{limit: limit, expiresAt: time.Now().Add(p.ttl)}
+		p.mu.Unlock()
+		return limit, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int), nil
+}
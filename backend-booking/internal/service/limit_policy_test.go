@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingLimitPolicy counts lookups and returns a fixed limit or error
+type countingLimitPolicy struct {
+	calls atomic.Int32
+	limit int
+	err   error
+	delay time.Duration
+}
+
+func (p *countingLimitPolicy) MaxPerUser(ctx context.Context, key LimitKey) (int, error) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+	return p.limit, p.err
+}
+
+func TestCachingLimitPolicy_ReusesResolvedLimit(t *testing.T) {
+	inner := &countingLimitPolicy{limit: 6, delay: 10 * time.Millisecond}
+	policy := NewCachingLimitPolicy(inner, time.Minute)
+	key := LimitKey{EventID: "event-001", UserRole: "vip"}
+
+	// Concurrent misses share one lookup
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limit, err := policy.MaxPerUser(context.Background(), key); err != nil || limit != 6 {
+				t.Errorf("MaxPerUser() = %d, %v; want 6", limit, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := policy.MaxPerUser(context.Background(), key); err != nil {
+		t.Fatalf("MaxPerUser() error = %v", err)
+	}
+	if calls := inner.calls.Load(); calls != 1 {
+		t.Errorf("Inner policy called %d times, want 1", calls)
+	}
+
+	// Other keys are resolved separately
+	if _, err := policy.MaxPerUser(context.Background(), LimitKey{EventID: "event-002"}); err != nil {
+		t.Fatalf("MaxPerUser() error = %v", err)
+	}
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Inner policy called %d times, want 2", calls)
+	}
+}
+
+func TestCachingLimitPolicy_Expiry(t *testing.T) {
+	inner := &countingLimitPolicy{limit: 4}
+	policy := NewCachingLimitPolicy(inner, 20*time.Millisecond)
+	key := LimitKey{EventID: "event-001"}
+
+	policy.MaxPerUser(context.Background(), key)
+	time.Sleep(30 * time.Millisecond)
+	policy.MaxPerUser(context.Background(), key)
+
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Inner policy called %d times, want 2 after TTL", calls)
+	}
+}
+
+func TestCachingLimitPolicy_ErrorsNotCached(t *testing.T) {
+	inner := &countingLimitPolicy{err: errors.New("policy store down")}
+	policy := NewCachingLimitPolicy(inner, time.Minute)
+	key := LimitKey{EventID: "event-001"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := policy.MaxPerUser(context.Background(), key); err == nil {
+			t.Fatal("MaxPerUser() expected error")
+		}
+	}
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Inner policy called %d times, want 2", calls)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -36,6 +37,9 @@ type QueueService interface {
 
 	// DeleteQueuePass removes the queue pass after successful booking
 	DeleteQueuePass(ctx context.Context, userID, eventID string) error
+
+	// IsSingleUsePass reports whether an event's queue passes are consumed by the first reservation
+	IsSingleUsePass(ctx context.Context, eventID string) (bool, error)
 }
 
 // queueService implements QueueService
@@ -143,10 +147,22 @@ func (s *queueService) JoinQueue(ctx context.Context, userID string, req *dto.Jo
 
 	if !result.Success {
 		switch result.ErrorCode {
-		case "ALREADY_IN_QUEUE":
+		case pkgredis.CodeAlreadyInQueue:
+			if req.Idempotent {
+				// A retried join returns the entry the first attempt created
+				resp, err := s.existingQueueEntry(ctx, userID, req.EventID)
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+					return nil, err
+				}
+				span.SetAttributes(attribute.Bool("already_in_queue", true))
+				span.SetStatus(codes.Ok, "")
+				return resp, nil
+			}
 			span.SetStatus(codes.Error, "already in queue")
 			return nil, domain.ErrAlreadyInQueue
-		case "QUEUE_FULL":
+		case pkgredis.CodeQueueFull:
 			span.SetStatus(codes.Error, "queue full")
 			return nil, domain.ErrQueueFull
 		default:
@@ -162,15 +178,51 @@ func (s *queueService) JoinQueue(ctx context.Context, userID string, req *dto.Jo
 	span.SetAttributes(attribute.Int64("position", result.Position))
 	span.SetStatus(codes.Ok, "")
 	return &dto.JoinQueueResponse{
-		Position:      result.Position,
-		Token:         token,
-		EstimatedWait: estimatedWait,
-		JoinedAt:      now,
-		ExpiresAt:     now.Add(s.queueTTL),
-		Message:       "Successfully joined the queue",
+		Position:       result.Position,
+		Token:          token,
+		EstimatedWait:  estimatedWait,
+		JoinedAt:       now,
+		ExpiresAt:      now.Add(s.queueTTL),
+		QueueSizeAfter: result.TotalInQueue,
+		Message:        "Successfully joined the queue",
 	}, nil
 }
 
+// existingQueueEntry builds a join response from the user's current queue
+// entry, keeping the token and timestamps issued by the original join
+func (s *queueService) existingQueueEntry(ctx context.Context, userID, eventID string) (*dto.JoinQueueResponse, error) {
+	position, err := s.queueRepo.GetPosition(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !position.IsInQueue {
+		// The entry was popped or expired between the join attempt and this lookup
+		return nil, domain.ErrAlreadyInQueue
+	}
+
+	info, err := s.queueRepo.GetUserQueueInfo(ctx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.JoinQueueResponse{
+		Position:       position.Position,
+		Token:          info["token"],
+		EstimatedWait:  position.Position * s.estimatedWaitPerUser,
+		QueueSizeAfter: position.TotalInQueue,
+		Message:        "Already in the queue",
+		AlreadyInQueue: true,
+	}
+	// joined_at is stored as fractional unix seconds
+	if joined, err := strconv.ParseFloat(info["joined_at"], 64); err == nil {
+		resp.JoinedAt = time.Unix(0, int64(joined*float64(time.Second)))
+	}
+	if ts, err := parseTimestamp(info["expires_at"]); err == nil {
+		resp.ExpiresAt = time.Unix(ts, 0)
+	}
+	return resp, nil
+}
+
 // GetPosition gets the user's current position in queue
 func (s *queueService) GetPosition(ctx context.Context, userID, eventID string) (*dto.QueuePositionResponse, error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.queue.get_position")
@@ -298,17 +350,24 @@ func (s *queueService) LeaveQueue(ctx context.Context, userID string, req *dto.L
 		attribute.String("event_id", req.EventID),
 	)
 
-	err := s.queueRepo.LeaveQueue(ctx, req.EventID, userID, req.Token)
+	result, err := s.queueRepo.LeaveQueue(ctx, req.EventID, userID, req.Token)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
+	span.SetAttributes(
+		attribute.Int64("previous_position", result.PreviousPosition),
+		attribute.Int64("queue_size_after", result.QueueSizeAfter),
+	)
 	span.SetStatus(codes.Ok, "")
 	return &dto.LeaveQueueResponse{
-		Success: true,
-		Message: "Successfully left the queue",
+		Success:          true,
+		Message:          "Successfully left the queue",
+		WasInQueue:       true,
+		PreviousPosition: result.PreviousPosition,
+		QueueSizeAfter:   result.QueueSizeAfter,
 	}, nil
 }
 
@@ -488,3 +547,13 @@ func (s *queueService) DeleteQueuePass(ctx context.Context, userID, eventID stri
 	span.SetStatus(codes.Ok, "")
 	return nil
 }
+
+// IsSingleUsePass reports whether an event's queue passes are single-use,
+// per the event's queue config (default: false)
+func (s *queueService) IsSingleUsePass(ctx context.Context, eventID string) (bool, error) {
+	config, err := s.queueRepo.GetEventQueueConfig(ctx, eventID)
+	if err != nil {
+		return false, err
+	}
+	return config != nil && config.SingleUsePass, nil
+}
@@ -33,9 +33,12 @@ func (m *MockQueueRepository) GetPosition(ctx context.Context, eventID, userID s
 	return args.Get(0).(*repository.QueuePositionResult), args.Error(1)
 }
 
-func (m *MockQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) error {
+func (m *MockQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) (*repository.LeaveQueueResult, error) {
 	args := m.Called(ctx, eventID, userID, token)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.LeaveQueueResult), args.Error(1)
 }
 
 func (m *MockQueueRepository) GetQueueSize(ctx context.Context, eventID string) (int64, error) {
@@ -74,6 +77,14 @@ func (m *MockQueueRepository) PopUsersFromQueue(ctx context.Context, eventID str
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockQueueRepository) IssuePasses(ctx context.Context, eventID string, count int64, ttl time.Duration, sign repository.PassSigner) ([]repository.IssuedPass, error) {
+	args := m.Called(ctx, eventID, count, ttl, sign)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.IssuedPass), args.Error(1)
+}
+
 func (m *MockQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -146,6 +157,7 @@ func TestQueueService_JoinQueue_Success(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, int64(1), result.Position)
 	assert.NotEmpty(t, result.Token)
+	assert.Equal(t, int64(1), result.QueueSizeAfter)
 	assert.Equal(t, "Successfully joined the queue", result.Message)
 
 	mockRepo.AssertExpectations(t)
@@ -175,6 +187,92 @@ func TestQueueService_JoinQueue_AlreadyInQueue(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestQueueService_JoinQueue_Idempotent(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{
+		EstimatedWaitPerUser: 3,
+		JWTSecret:            testJWTSecret,
+	})
+
+	// user-123 already holds position 4 from an earlier attempt
+	mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+		return params.UserID == "user-123"
+	})).Return(&repository.JoinQueueResult{
+		Success:      false,
+		ErrorCode:    "ALREADY_IN_QUEUE",
+		ErrorMessage: "User is already in queue",
+	}, nil)
+	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(&repository.QueuePositionResult{
+		Position:     4,
+		TotalInQueue: 10,
+		IsInQueue:    true,
+	}, nil)
+	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(map[string]string{
+		"token":      "original-token",
+		"joined_at":  "1700000000.5",
+		"expires_at": "1700001800",
+		"position":   "4",
+	}, nil)
+
+	// A different user still joins normally
+	mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+		return params.UserID == "user-456"
+	})).Return(&repository.JoinQueueResult{
+		Success:      true,
+		Position:     11,
+		TotalInQueue: 11,
+	}, nil)
+
+	req := &dto.JoinQueueRequest{
+		EventID:    "event-123",
+		Idempotent: true,
+	}
+
+	result, err := service.JoinQueue(context.Background(), "user-123", req)
+	assert.NoError(t, err)
+	assert.True(t, result.AlreadyInQueue)
+	assert.Equal(t, int64(4), result.Position)
+	assert.Equal(t, "original-token", result.Token)
+	assert.Equal(t, int64(12), result.EstimatedWait)
+	assert.Equal(t, time.Unix(1700000000, 500000000), result.JoinedAt)
+	assert.Equal(t, time.Unix(1700001800, 0), result.ExpiresAt)
+
+	result, err = service.JoinQueue(context.Background(), "user-456", req)
+	assert.NoError(t, err)
+	assert.False(t, result.AlreadyInQueue)
+	assert.Equal(t, int64(11), result.Position)
+	assert.NotEqual(t, "original-token", result.Token)
+	assert.Equal(t, "Successfully joined the queue", result.Message)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQueueService_JoinQueue_IdempotentEntryGone(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
+
+	mockRepo.On("JoinQueue", mock.Anything, mock.Anything).Return(&repository.JoinQueueResult{
+		Success:   false,
+		ErrorCode: "ALREADY_IN_QUEUE",
+	}, nil)
+	// The entry was popped before the lookup
+	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(&repository.QueuePositionResult{
+		IsInQueue: false,
+	}, nil)
+
+	req := &dto.JoinQueueRequest{
+		EventID:    "event-123",
+		Idempotent: true,
+	}
+
+	result, err := service.JoinQueue(context.Background(), "user-123", req)
+
+	assert.Nil(t, result)
+	assert.Equal(t, domain.ErrAlreadyInQueue, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestQueueService_JoinQueue_QueueFull(t *testing.T) {
 	mockRepo := new(MockQueueRepository)
 	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
@@ -316,7 +414,8 @@ func TestQueueService_LeaveQueue_Success(t *testing.T) {
 	mockRepo := new(MockQueueRepository)
 	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
 
-	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "token-123").Return(nil)
+	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "token-123").
+		Return(&repository.LeaveQueueResult{PreviousPosition: 3, QueueSizeAfter: 7}, nil)
 
 	req := &dto.LeaveQueueRequest{
 		EventID: "event-123",
@@ -329,6 +428,9 @@ func TestQueueService_LeaveQueue_Success(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.True(t, result.Success)
 	assert.Equal(t, "Successfully left the queue", result.Message)
+	assert.True(t, result.WasInQueue)
+	assert.Equal(t, int64(3), result.PreviousPosition)
+	assert.Equal(t, int64(7), result.QueueSizeAfter)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -337,7 +439,7 @@ func TestQueueService_LeaveQueue_InvalidToken(t *testing.T) {
 	mockRepo := new(MockQueueRepository)
 	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
 
-	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "wrong-token").Return(domain.ErrInvalidQueueToken)
+	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "wrong-token").Return(nil, domain.ErrInvalidQueueToken)
 
 	req := &dto.LeaveQueueRequest{
 		EventID: "event-123",
@@ -356,7 +458,7 @@ func TestQueueService_LeaveQueue_NotInQueue(t *testing.T) {
 	mockRepo := new(MockQueueRepository)
 	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
 
-	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "token-123").Return(domain.ErrNotInQueue)
+	mockRepo.On("LeaveQueue", mock.Anything, "event-123", "user-123", "token-123").Return(nil, domain.ErrNotInQueue)
 
 	req := &dto.LeaveQueueRequest{
 		EventID: "event-123",
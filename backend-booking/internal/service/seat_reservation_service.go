@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// RedisSeatReservationService reserves and releases saga seats through the
+// reserve_seats and release_seats scripts, so the booking saga works against
+// real inventory. Plug it into saga.BookingSagaConfig.ReservationService in
+// place of the mock.
+type RedisSeatReservationService struct {
+	reservationRepo repository.ReservationRepository
+	maxPerUser      int
+	reservationTTL  time.Duration
+}
+
+var _ saga.SeatReservationService = (*RedisSeatReservationService)(nil)
+
+// NewRedisSeatReservationService creates a seat reservation service backed by
+// Redis reservations. maxPerUser caps a user's seats per event (0 = unlimited)
+// and reservationTTL is how long a hold lives before it expires.
+func NewRedisSeatReservationService(reservationRepo repository.ReservationRepository, maxPerUser int, reservationTTL time.Duration) *RedisSeatReservationService {
+	if reservationTTL <= 0 {
+		reservationTTL = 10 * time.Minute
+	}
+	return &RedisSeatReservationService{
+		reservationRepo: reservationRepo,
+		maxPerUser:      maxPerUser,
+		reservationTTL:  reservationTTL,
+	}
+}
+
+// ReserveSeats holds quantity seats in the zone under the saga's booking ID,
+// which is also returned as the reservation ID. Retrying the step after a
+// timeout is safe: the script returns the booking's existing hold rather than
+// taking the seats again.
+func (s *RedisSeatReservationService) ReserveSeats(ctx context.Context, bookingID, userID, eventID, showID, zoneID string, quantity int) (string, error) {
+	result, err := s.reservationRepo.ReserveSeats(ctx, repository.ReserveParams{
+		BookingID:  bookingID,
+		ZoneID:     zoneID,
+		UserID:     userID,
+		EventID:    eventID,
+		ShowID:     showID,
+		Quantity:   quantity,
+		MaxPerUser: s.maxPerUser,
+		TTLSeconds: int(s.reservationTTL.Seconds()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !result.Success {
+		if result.ErrorCode == pkgredis.CodeInsufficientStock {
+			return "", saga.ErrInsufficientSeats
+		}
+		return "", result.Err()
+	}
+	return result.BookingID, nil
+}
+
+// ReleaseSeats returns the booking's held seats to inventory. A hold that no
+// longer exists (already released, or expired and restored by the sweeper) or
+// was already confirmed or released leaves nothing to do and counts as
+// success, so compensation never fails on it.
+func (s *RedisSeatReservationService) ReleaseSeats(ctx context.Context, bookingID, userID string) error {
+	result, err := s.reservationRepo.ReleaseSeats(ctx, bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	switch result.ErrorCode {
+	case pkgredis.CodeReservationNotFound, pkgredis.CodeAlreadyReleased:
+		return nil
+	}
+	return result.Err()
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+func TestRedisSeatReservationService_ReserveSeats(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *repository.ReserveResult
+		wantID  string
+		wantErr error
+	}{
+		{
+			name:   "reserved under the saga booking ID",
+			result: &repository.ReserveResult{Success: true, BookingID: "booking-123"},
+			wantID: "booking-123",
+		},
+		{
+			name:    "insufficient stock",
+			result:  &repository.ReserveResult{ErrorCode: pkgredis.CodeInsufficientStock},
+			wantErr: saga.ErrInsufficientSeats,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got repository.ReserveParams
+			svc := NewRedisSeatReservationService(&MockReservationRepository{
+				ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+					got = params
+					return tt.result, nil
+				},
+			}, 4, 5*time.Minute)
+
+			reservationID, err := svc.ReserveSeats(context.Background(), "booking-123", "user-001", "event-001", "show-001", "zone-001", 2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReserveSeats() error = %v, want %v", err, tt.wantErr)
+			}
+			if reservationID != tt.wantID {
+				t.Errorf("ReserveSeats() reservationID = %q, want %q", reservationID, tt.wantID)
+			}
+			want := repository.ReserveParams{
+				BookingID:  "booking-123",
+				ZoneID:     "zone-001",
+				UserID:     "user-001",
+				EventID:    "event-001",
+				ShowID:     "show-001",
+				Quantity:   2,
+				MaxPerUser: 4,
+				TTLSeconds: 300,
+			}
+			if got != want {
+				t.Errorf("ReserveSeats() params = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRedisSeatReservationService_ReserveSeats_OtherFailure(t *testing.T) {
+	svc := NewRedisSeatReservationService(&MockReservationRepository{
+		ReserveSeatsFunc: func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+			return &repository.ReserveResult{ErrorCode: pkgredis.CodeUserLimitExceeded, ErrorMessage: "limit reached"}, nil
+		},
+	}, 4, time.Minute)
+
+	_, err := svc.ReserveSeats(context.Background(), "booking-123", "user-001", "event-001", "", "zone-001", 2)
+	var reserveErr *pkgredis.ReserveError
+	if !errors.As(err, &reserveErr) || reserveErr.Code != pkgredis.CodeUserLimitExceeded {
+		t.Errorf("ReserveSeats() error = %v, want USER_LIMIT_EXCEEDED", err)
+	}
+}
+
+func TestRedisSeatReservationService_ReleaseSeats(t *testing.T) {
+	// Compensation must not fail on a hold that is already gone
+	tests := []struct {
+		name   string
+		result *repository.ReleaseResult
+	}{
+		{name: "released", result: &repository.ReleaseResult{Success: true}},
+		{name: "already released", result: &repository.ReleaseResult{ErrorCode: pkgredis.CodeAlreadyReleased}},
+		{name: "expired or already removed", result: &repository.ReleaseResult{ErrorCode: pkgredis.CodeReservationNotFound}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRedisSeatReservationService(&MockReservationRepository{
+				ReleaseSeatsFunc: func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+					return tt.result, nil
+				},
+			}, 4, time.Minute)
+
+			if err := svc.ReleaseSeats(context.Background(), "booking-123", "user-001"); err != nil {
+				t.Errorf("ReleaseSeats() error = %v", err)
+			}
+		})
+	}
+}
+
+// newIntegrationReservationRepository connects to the test Redis (DB 15, flushed)
+// and loads the reservation scripts. Skipped unless INTEGRATION_TEST=true.
+func newIntegrationReservationRepository(t *testing.T) *repository.RedisReservationRepository {
+	t.Helper()
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	host := os.Getenv("TEST_REDIS_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := 6379
+	if p, err := strconv.Atoi(os.Getenv("TEST_REDIS_PORT")); err == nil {
+		port = p
+	}
+
+	ctx := context.Background()
+	client, err := pkgredis.NewClient(ctx, &pkgredis.Config{
+		Host:          host,
+		Port:          port,
+		Password:      os.Getenv("TEST_REDIS_PASSWORD"),
+		DB:            15, // Use DB 15 for testing
+		PoolSize:      10,
+		DialTimeout:   5 * time.Second,
+		ReadTimeout:   3 * time.Second,
+		WriteTimeout:  3 * time.Second,
+		MaxRetries:    3,
+		RetryInterval: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Redis client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if err := client.Client().FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("Failed to flush test database: %v", err)
+	}
+
+	repo := repository.NewRedisReservationRepository(client)
+	if err := repo.LoadScripts(ctx); err != nil {
+		t.Fatalf("Failed to load scripts: %v", err)
+	}
+	return repo
+}
+
+func TestRedisSeatReservationService_Integration_ReserveAndRelease(t *testing.T) {
+	repo := newIntegrationReservationRepository(t)
+	ctx := context.Background()
+
+	zoneID := "zone-saga-001"
+	if err := repo.SetZoneAvailability(ctx, zoneID, 5); err != nil {
+		t.Fatalf("Failed to set zone availability: %v", err)
+	}
+
+	var svc saga.SeatReservationService = NewRedisSeatReservationService(repo, 10, time.Minute)
+
+	reservationID, err := svc.ReserveSeats(ctx, "booking-saga-1", "user-001", "event-001", "", zoneID, 3)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if reservationID != "booking-saga-1" {
+		t.Errorf("ReserveSeats() reservationID = %q, want booking-saga-1", reservationID)
+	}
+	assertZoneAvailability(t, repo, zoneID, 2)
+
+	// A retried step (the first attempt's reply was lost) takes no more seats
+	if _, err := svc.ReserveSeats(ctx, "booking-saga-1", "user-001", "event-001", "", zoneID, 3); err != nil {
+		t.Fatalf("retried ReserveSeats() error = %v", err)
+	}
+	assertZoneAvailability(t, repo, zoneID, 2)
+
+	if _, err := svc.ReserveSeats(ctx, "booking-saga-2", "user-002", "event-001", "", zoneID, 3); !errors.Is(err, saga.ErrInsufficientSeats) {
+		t.Errorf("ReserveSeats() beyond stock error = %v, want ErrInsufficientSeats", err)
+	}
+
+	if err := svc.ReleaseSeats(ctx, "booking-saga-1", "user-001"); err != nil {
+		t.Fatalf("ReleaseSeats() error = %v", err)
+	}
+	assertZoneAvailability(t, repo, zoneID, 5)
+
+	// The released hold is gone, so releasing it again succeeds without returning seats twice
+	if err := svc.ReleaseSeats(ctx, "booking-saga-1", "user-001"); err != nil {
+		t.Errorf("second ReleaseSeats() error = %v", err)
+	}
+	assertZoneAvailability(t, repo, zoneID, 5)
+}
+
+func assertZoneAvailability(t *testing.T, repo *repository.RedisReservationRepository, zoneID string, want int64) {
+	t.Helper()
+	got, err := repo.GetZoneAvailability(context.Background(), zoneID)
+	if err != nil {
+		t.Fatalf("GetZoneAvailability() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("zone availability = %d, want %d", got, want)
+	}
+}
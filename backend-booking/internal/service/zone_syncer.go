@@ -146,8 +146,9 @@ func (s *DefaultZoneSyncer) doSync(ctx context.Context, zoneID string) error {
 		return fmt.Errorf("zone %s is not active", zoneID)
 	}
 
-	// Sync to Redis
-	if err := s.reservationRepo.SetZoneAvailability(ctx, zoneID, zone.AvailableSeats); err != nil {
+	// Initialize in Redis only if absent: another instance may have synced the
+	// zone meanwhile and reservations may already be counting down from it
+	if _, err := s.reservationRepo.InitZone(ctx, zoneID, zone.ShowID, zone.AvailableSeats, 0); err != nil {
 		return fmt.Errorf("failed to sync zone %s to Redis: %w", zoneID, err)
 	}
 
@@ -9,6 +9,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
 
 // ExpiryWorkerConfig contains configuration for the expiry worker
@@ -162,7 +163,7 @@ func (w *ExpiryWorker) expireBooking(ctx context.Context, booking *domain.Bookin
 		w.totalReleased++
 		w.log.Info(fmt.Sprintf("Released %d seats for booking %s, new availability: %d",
 			booking.Quantity, booking.ID, releaseResult.AvailableSeats))
-	} else if releaseResult.ErrorCode == "RESERVATION_NOT_FOUND" {
+	} else if releaseResult.ErrorCode == pkgredis.CodeReservationNotFound {
 		// Redis reservation already expired via TTL - this is expected
 		w.log.Debug(fmt.Sprintf("Redis reservation for booking %s already expired (TTL)", booking.ID))
 	} else {
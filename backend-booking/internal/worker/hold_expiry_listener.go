@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+const (
+	// reservationKeyPrefix prefixes the reservation hashes whose expiry is restored
+	reservationKeyPrefix = "reservation:"
+	// defaultRestoreTimeout bounds a single restore
+	defaultRestoreTimeout = 5 * time.Second
+)
+
+// ExpiredEventsChannel returns the keyevent channel Redis publishes key
+// expirations to for a database, given notify-keyspace-events with E and x
+func ExpiredEventsChannel(db int) string {
+	return fmt.Sprintf("__keyevent@%d__:expired", db)
+}
+
+// ExpiredEventsEnabled reports whether a notify-keyspace-events setting
+// publishes the keyevent notifications for expired keys
+func ExpiredEventsEnabled(flags string) bool {
+	return strings.ContainsRune(flags, 'E') && strings.ContainsAny(flags, "xA")
+}
+
+// HoldExpiryListener returns the seats of expired holds to inventory as soon
+// as Redis expires their reservation, instead of waiting for the Postgres
+// scan in ExpiryWorker, which cannot release a hold whose key is already gone.
+//
+// It needs Redis configured with notify-keyspace-events Ex. Expirations
+// published while the listener is disconnected are lost; HoldExpirySweeper
+// restores those holds from the expiry index instead.
+type HoldExpiryListener struct {
+	client *pkgredis.Client
+	repo   repository.HoldExpiryRepository
+	log    *logger.Logger
+
+	// Metrics
+	mu            sync.Mutex
+	totalRestored int64
+	totalSkipped  int64
+	totalFailed   int64
+}
+
+// NewHoldExpiryListener creates a new hold expiry listener
+func NewHoldExpiryListener(client *pkgredis.Client, repo repository.HoldExpiryRepository, log *logger.Logger) *HoldExpiryListener {
+	return &HoldExpiryListener{
+		client: client,
+		repo:   repo,
+		log:    log,
+	}
+}
+
+// Start subscribes to expiry notifications and restores expired holds until
+// ctx is cancelled
+func (l *HoldExpiryListener) Start(ctx context.Context) {
+	l.checkNotifyConfig(ctx)
+
+	channel := ExpiredEventsChannel(l.client.Client().Options().DB)
+	sub := l.client.ResilientSubscribe(ctx, channel)
+	defer sub.Close()
+
+	l.log.Info(fmt.Sprintf("Hold expiry listener started (channel: %s)", channel))
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.log.Info("Hold expiry listener stopping...")
+			return
+		case <-sub.Reconnected():
+			l.log.Warn("Hold expiry listener reconnected; expirations published while disconnected are left to the sweeper")
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+			l.handleExpired(ctx, msg.Payload)
+		}
+	}
+}
+
+// checkNotifyConfig warns when Redis is not publishing expirations. Managed
+// Redis often forbids CONFIG, so a failed lookup is only logged.
+func (l *HoldExpiryListener) checkNotifyConfig(ctx context.Context) {
+	values, err := l.client.Client().ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		l.log.Warn(fmt.Sprintf("Could not read notify-keyspace-events, make sure it includes Ex: %v", err))
+		return
+	}
+	if flags := values["notify-keyspace-events"]; !ExpiredEventsEnabled(flags) {
+		l.log.Warn(fmt.Sprintf("notify-keyspace-events is %q; expired holds will not be restored until it includes Ex", flags))
+	}
+}
+
+// handleExpired restores the hold behind an expired key, ignoring keys that
+// are not reservations
+func (l *HoldExpiryListener) handleExpired(ctx context.Context, key string) {
+	if !strings.HasPrefix(key, reservationKeyPrefix) {
+		return
+	}
+	bookingID := strings.TrimPrefix(key, reservationKeyPrefix)
+
+	restoreCtx, cancel := context.WithTimeout(ctx, defaultRestoreTimeout)
+	defer cancel()
+
+	result, err := l.repo.RestoreExpiredHold(restoreCtx, bookingID)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case err != nil:
+		l.totalFailed++
+		l.log.Error(fmt.Sprintf("Failed to restore expired hold %s: %v", bookingID, err))
+	case result.Success:
+		l.totalRestored++
+		l.log.Debug(fmt.Sprintf("Restored expired hold %s (available: %d)", bookingID, result.AvailableSeats))
+	default:
+		// Released, confirmed or already restored by another listener
+		l.totalSkipped++
+	}
+}
+
+// GetMetrics returns current listener metrics
+func (l *HoldExpiryListener) GetMetrics() (totalRestored, totalSkipped, totalFailed int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.totalRestored, l.totalSkipped, l.totalFailed
+}
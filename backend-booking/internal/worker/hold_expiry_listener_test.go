@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldExpiryRepository is a mock implementation of HoldExpiryRepository
+type MockHoldExpiryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldExpiryRepository) RestoreExpiredHold(ctx context.Context, bookingID string) (*repository.ReleaseResult, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ReleaseResult), args.Error(1)
+}
+
+func (m *MockHoldExpiryRepository) DueExpiredHolds(ctx context.Context, before time.Time, limit int64) ([]repository.ExpiryEntry, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ExpiryEntry), args.Error(1)
+}
+
+func (m *MockHoldExpiryRepository) RemoveExpiryEntries(ctx context.Context, entries ...repository.ExpiryEntry) error {
+	args := m.Called(ctx, entries)
+	return args.Error(0)
+}
+
+func TestExpiredEventsChannel(t *testing.T) {
+	assert.Equal(t, "__keyevent@0__:expired", ExpiredEventsChannel(0))
+	assert.Equal(t, "__keyevent@15__:expired", ExpiredEventsChannel(15))
+}
+
+func TestExpiredEventsEnabled(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  bool
+	}{
+		{flags: "", want: false},
+		{flags: "Ex", want: true},
+		{flags: "xE", want: true},
+		{flags: "EA", want: true},
+		{flags: "Kx", want: false}, // keyspace channel only, not keyevent
+		{flags: "E", want: false},
+		{flags: "Eg$", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ExpiredEventsEnabled(tt.flags), "flags %q", tt.flags)
+	}
+}
+
+func TestHoldExpiryListener_HandleExpired(t *testing.T) {
+	log, err := logger.New(&logger.Config{Level: "error", ServiceName: "hold-expiry-listener-test"})
+	assert.NoError(t, err)
+
+	mockRepo := new(MockHoldExpiryRepository)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-1").
+		Return(&repository.ReleaseResult{Success: true, AvailableSeats: 10}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-2").
+		Return(&repository.ReleaseResult{Success: false, ErrorCode: pkgredis.CodeReservationNotFound}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-3").
+		Return(nil, errors.New("connection refused"))
+
+	listener := NewHoldExpiryListener(nil, mockRepo, log)
+	ctx := context.Background()
+
+	listener.handleExpired(ctx, "reservation:booking-1")
+	listener.handleExpired(ctx, "reservation:booking-2") // already released or confirmed
+	listener.handleExpired(ctx, "reservation:booking-3")
+	listener.handleExpired(ctx, "queue:pass:event-1:user-1") // not a reservation
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "RestoreExpiredHold", 3)
+
+	restored, skipped, failed := listener.GetMetrics()
+	assert.Equal(t, int64(1), restored)
+	assert.Equal(t, int64(1), skipped)
+	assert.Equal(t, int64(1), failed)
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// HoldExpirySweeperConfig holds configuration for the hold expiry sweeper
+type HoldExpirySweeperConfig struct {
+	// SweepInterval is the time between sweeps (default: 5 seconds)
+	SweepInterval time.Duration
+	// BatchSize is the most due holds restored per sweep (default: 100)
+	BatchSize int64
+}
+
+// DefaultHoldExpirySweeperConfig returns default configuration
+func DefaultHoldExpirySweeperConfig() *HoldExpirySweeperConfig {
+	return &HoldExpirySweeperConfig{
+		SweepInterval: 5 * time.Second,
+		BatchSize:     100,
+	}
+}
+
+// HoldExpirySweeper restores expired holds from the reservations:expiry index
+// the reserve scripts maintain. It does not depend on keyspace notifications,
+// so it catches every expiry HoldExpiryListener missed; running both is safe
+// because a hold is restored at most once.
+type HoldExpirySweeper struct {
+	repo   repository.HoldExpiryRepository
+	config *HoldExpirySweeperConfig
+	log    *logger.Logger
+
+	// Metrics
+	mu            sync.Mutex
+	totalRestored int64
+	lastSweepTime time.Time
+}
+
+// NewHoldExpirySweeper creates a new hold expiry sweeper
+func NewHoldExpirySweeper(repo repository.HoldExpiryRepository, cfg *HoldExpirySweeperConfig, log *logger.Logger) *HoldExpirySweeper {
+	if cfg == nil {
+		cfg = DefaultHoldExpirySweeperConfig()
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &HoldExpirySweeper{
+		repo:   repo,
+		config: cfg,
+		log:    log,
+	}
+}
+
+// Start sweeps due holds every SweepInterval until ctx is cancelled
+func (s *HoldExpirySweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	s.log.Info(fmt.Sprintf("Hold expiry sweeper started (interval: %v, batch: %d)",
+		s.config.SweepInterval, s.config.BatchSize))
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Hold expiry sweeper stopping...")
+			return
+		case <-ticker.C:
+			if _, err := s.SweepOnce(ctx); err != nil {
+				s.log.Error(fmt.Sprintf("Hold expiry sweep failed: %v", err))
+			}
+		}
+	}
+}
+
+// SweepOnce restores up to BatchSize holds that are due, returning how many
+// it restored. Entries whose hold was released, confirmed or already restored
+// are dropped from the index; holds Redis has not expired yet are left for a
+// later sweep.
+func (s *HoldExpirySweeper) SweepOnce(ctx context.Context) (int, error) {
+	entries, err := s.repo.DueExpiredHolds(ctx, time.Now(), s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	var stale []repository.ExpiryEntry
+	for _, entry := range entries {
+		if entry.BookingID == "" {
+			s.log.Warn(fmt.Sprintf("Dropping malformed expiry index entry %q", entry.Member))
+			stale = append(stale, entry)
+			continue
+		}
+
+		result, err := s.repo.RestoreExpiredHold(ctx, entry.BookingID)
+		switch {
+		case err != nil:
+			s.log.Error(fmt.Sprintf("Failed to restore expired hold %s: %v", entry.BookingID, err))
+		case result.Success:
+			restored++
+		case result.ErrorCode == pkgredis.CodeHoldActive:
+			// The reservation key outlives expires_at by under a second
+		default:
+			stale = append(stale, entry)
+		}
+	}
+
+	if err := s.repo.RemoveExpiryEntries(ctx, stale...); err != nil {
+		return restored, err
+	}
+
+	s.mu.Lock()
+	s.totalRestored += int64(restored)
+	s.lastSweepTime = time.Now()
+	s.mu.Unlock()
+
+	if restored > 0 {
+		s.log.Info(fmt.Sprintf("Restored %d expired holds", restored))
+	}
+	return restored, nil
+}
+
+// GetMetrics returns current sweeper metrics
+func (s *HoldExpirySweeper) GetMetrics() (totalRestored int64, lastSweepTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.totalRestored, s.lastSweepTime
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoldExpirySweeper_SweepOnce(t *testing.T) {
+	log, err := logger.New(&logger.Config{Level: "error", ServiceName: "hold-expiry-sweeper-test"})
+	assert.NoError(t, err)
+
+	expired := repository.ExpiryEntry{Member: "booking-1:2:zone-1:user-1", BookingID: "booking-1", Quantity: 2}
+	released := repository.ExpiryEntry{Member: "booking-2:1:zone-1:user-2", BookingID: "booking-2", Quantity: 1}
+	active := repository.ExpiryEntry{Member: "booking-3:1:zone-1:user-3", BookingID: "booking-3", Quantity: 1}
+	failing := repository.ExpiryEntry{Member: "booking-4:1:zone-1:user-4", BookingID: "booking-4", Quantity: 1}
+	malformed := repository.ExpiryEntry{Member: "garbage"}
+
+	mockRepo := new(MockHoldExpiryRepository)
+	mockRepo.On("DueExpiredHolds", mock.Anything, mock.Anything, int64(50)).
+		Return([]repository.ExpiryEntry{expired, released, active, failing, malformed}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-1").
+		Return(&repository.ReleaseResult{Success: true}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-2").
+		Return(&repository.ReleaseResult{ErrorCode: pkgredis.CodeReservationNotFound}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-3").
+		Return(&repository.ReleaseResult{ErrorCode: pkgredis.CodeHoldActive}, nil)
+	mockRepo.On("RestoreExpiredHold", mock.Anything, "booking-4").
+		Return(nil, errors.New("connection refused"))
+	// Only holds with nothing left to restore leave the index
+	mockRepo.On("RemoveExpiryEntries", mock.Anything, []repository.ExpiryEntry{released, malformed}).Return(nil)
+
+	sweeper := NewHoldExpirySweeper(mockRepo, &HoldExpirySweeperConfig{BatchSize: 50}, log)
+	restored, err := sweeper.SweepOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, restored)
+	mockRepo.AssertExpectations(t)
+
+	total, lastSweep := sweeper.GetMetrics()
+	assert.Equal(t, int64(1), total)
+	assert.False(t, lastSweep.IsZero())
+}
+
+func TestHoldExpirySweeper_SweepOnceIndexError(t *testing.T) {
+	log, err := logger.New(&logger.Config{Level: "error", ServiceName: "hold-expiry-sweeper-test"})
+	assert.NoError(t, err)
+
+	mockRepo := new(MockHoldExpiryRepository)
+	mockRepo.On("DueExpiredHolds", mock.Anything, mock.Anything, int64(100)).
+		Return(nil, errors.New("connection refused"))
+
+	sweeper := NewHoldExpirySweeper(mockRepo, nil, log)
+	restored, err := sweeper.SweepOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, restored)
+	mockRepo.AssertNotCalled(t, "RestoreExpiredHold", mock.Anything, mock.Anything)
+}
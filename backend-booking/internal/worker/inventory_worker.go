@@ -9,6 +9,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
@@ -242,6 +243,9 @@ func (w *InventoryWorker) updateZoneInventory(ctx context.Context, tx pgx.Tx, de
 	// available_seats change: -reserved + cancelled
 	// reserved_seats change: +reserved - confirmed - cancelled
 	// sold_seats change: +confirmed
+	//
+	// An overbooked zone reserves past its stock; available_seats stops at
+	// zero (the column is CHECKed non-negative) rather than failing the sync
 
 	availableChange := -delta.ReservedDelta + delta.CancelledDelta
 	reservedChange := delta.ReservedDelta - delta.ConfirmedDelta - delta.CancelledDelta
@@ -250,7 +254,7 @@ func (w *InventoryWorker) updateZoneInventory(ctx context.Context, tx pgx.Tx, de
 	query := `
 		UPDATE seat_zones
 		SET
-			available_seats = available_seats + $1,
+			available_seats = GREATEST(available_seats + $1, 0),
 			reserved_seats = reserved_seats + $2,
 			sold_seats = sold_seats + $3,
 			updated_at = NOW()
@@ -284,7 +288,7 @@ func (w *InventoryWorker) RebuildRedisFromDB(ctx context.Context) error {
 
 	// Query all active seat zones
 	query := `
-		SELECT id, available_seats
+		SELECT id, show_id, available_seats
 		FROM seat_zones
 		WHERE is_active = true AND deleted_at IS NULL
 	`
@@ -296,11 +300,12 @@ func (w *InventoryWorker) RebuildRedisFromDB(ctx context.Context) error {
 	defer rows.Close()
 
 	count := 0
+	showSeats := make(map[string]int64)
 	for rows.Next() {
-		var zoneID string
+		var zoneID, showID string
 		var availableSeats int64
 
-		if err := rows.Scan(&zoneID, &availableSeats); err != nil {
+		if err := rows.Scan(&zoneID, &showID, &availableSeats); err != nil {
 			w.log.Error(fmt.Sprintf("Failed to scan zone row: %v", err))
 			continue
 		}
@@ -312,6 +317,10 @@ func (w *InventoryWorker) RebuildRedisFromDB(ctx context.Context) error {
 			continue
 		}
 
+		if err := w.redis.Set(ctx, repository.ZoneShowKey(zoneID), showID, 0).Err(); err != nil {
+			w.log.Error(fmt.Sprintf("Failed to record show of zone %s: %v", zoneID, err))
+		}
+		showSeats[showID] += availableSeats
 		count++
 	}
 
@@ -319,6 +328,14 @@ func (w *InventoryWorker) RebuildRedisFromDB(ctx context.Context) error {
 		return fmt.Errorf("error iterating rows: %w", err)
 	}
 
+	// Set show-wide availability, which the reserve/release scripts keep in step
+	for showID, seats := range showSeats {
+		key := fmt.Sprintf("show:availability:%s", showID)
+		if err := w.redis.Set(ctx, key, seats, 0).Err(); err != nil {
+			w.log.Error(fmt.Sprintf("Failed to set Redis key %s: %v", key, err))
+		}
+	}
+
 	w.log.Info(fmt.Sprintf("Redis rebuild complete: %d zones synced", count))
 	return nil
 }
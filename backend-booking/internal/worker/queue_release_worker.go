@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	mathrand "math/rand/v2"
 	"sync"
 	"time"
 
@@ -26,6 +27,10 @@ type QueueReleaseWorkerConfig struct {
 	DefaultMaxConcurrent int
 	// DefaultQueuePassTTL is used when event config is not set (default: 5 minutes)
 	DefaultQueuePassTTL time.Duration
+	// IssuanceSpread is the window over which a batch's queue pass notifications
+	// are published at random offsets, so released users don't all hit
+	// reserve at once (default: 0, publish immediately; capped at ReleaseInterval)
+	IssuanceSpread time.Duration
 }
 
 // DefaultQueueReleaseWorkerConfig returns default configuration
@@ -62,6 +67,9 @@ type QueueReleaseWorker struct {
 	redisClient *redis.Client // For Pub/Sub publishing
 	log         *logger.Logger
 
+	// publishFn sends a queue pass notification (publishQueuePassReady; replaced in tests)
+	publishFn func(ctx context.Context, eventID, userID, queuePass string, expiresAt time.Time)
+
 	// Metrics
 	mu               sync.Mutex
 	totalReleased    int64
@@ -97,8 +105,12 @@ func NewQueueReleaseWorker(
 	if cfg.DefaultQueuePassTTL <= 0 {
 		cfg.DefaultQueuePassTTL = time.Duration(domain.DefaultQueuePassTTLMinutes) * time.Minute
 	}
+	if cfg.IssuanceSpread > cfg.ReleaseInterval {
+		// A wider window would overlap the next batch's notifications
+		cfg.IssuanceSpread = cfg.ReleaseInterval
+	}
 
-	return &QueueReleaseWorker{
+	w := &QueueReleaseWorker{
 		config:          cfg,
 		queueRepo:       queueRepo,
 		redisClient:     redisClient,
@@ -107,6 +119,8 @@ func NewQueueReleaseWorker(
 		configCacheTTL:  30 * time.Second, // Cache config for 30 seconds
 		configCacheTime: make(map[string]time.Time),
 	}
+	w.publishFn = w.publishQueuePassReady
+	return w
 }
 
 // Start begins the continuous queue release process
@@ -152,8 +166,30 @@ func (w *QueueReleaseWorker) processAllQueues(ctx context.Context) {
 	}
 }
 
-// releaseFromQueue releases users from a specific event queue using dynamic capacity
+// releaseFromQueue releases users from a specific event queue using dynamic
+// capacity and notifies each released user
 func (w *QueueReleaseWorker) releaseFromQueue(ctx context.Context, eventID string) {
+	passes, err := w.issuePasses(ctx, eventID)
+	if err != nil {
+		w.log.Error(fmt.Sprintf("Failed to release users from queue %s: %v", eventID, err))
+		return
+	}
+
+	for _, pass := range passes {
+		// Publish queue pass ready notification via Pub/Sub
+		// This allows SSE clients to receive real-time updates without polling
+		w.schedulePublish(ctx, eventID, pass.UserID, pass.QueuePass, pass.ExpiresAt)
+	}
+
+	if len(passes) > 0 {
+		w.log.Info(fmt.Sprintf("Released %d users from queue %s", len(passes), eventID))
+	}
+}
+
+// issuePasses promotes as many users from the front of the event queue as
+// the event has free capacity for. Promotion runs in one script, so each
+// promoted user leaves the queue and gets its pass together, in queue order.
+func (w *QueueReleaseWorker) issuePasses(ctx context.Context, eventID string) ([]repository.IssuedPass, error) {
 	// Get event queue config (cached)
 	config := w.getEventConfig(ctx, eventID)
 	maxConcurrent := config.MaxConcurrentBookings
@@ -162,67 +198,32 @@ func (w *QueueReleaseWorker) releaseFromQueue(ctx context.Context, eventID strin
 	// Count current active queue passes
 	activeCount, err := w.queueRepo.CountActiveQueuePasses(ctx, eventID)
 	if err != nil {
-		w.log.Error(fmt.Sprintf("Failed to count active queue passes for %s: %v", eventID, err))
-		return
+		return nil, fmt.Errorf("failed to count active queue passes: %w", err)
 	}
 
 	// Calculate how many users to release
 	releaseCount := int64(maxConcurrent) - activeCount
 	if releaseCount <= 0 {
 		// At capacity, no need to release
-		return
-	}
-
-	// Pop users from queue
-	userIDs, err := w.queueRepo.PopUsersFromQueue(ctx, eventID, releaseCount)
-	if err != nil {
-		w.log.Error(fmt.Sprintf("Failed to pop users from queue %s: %v", eventID, err))
-		return
+		return []repository.IssuedPass{}, nil
 	}
 
-	if len(userIDs) == 0 {
-		return
+	sign := func(userID string) (string, time.Time, error) {
+		return w.generateQueuePassWithTTL(userID, eventID, queuePassTTL)
 	}
-
-	w.log.Info(fmt.Sprintf("Releasing %d users from queue %s (active: %d, max: %d)",
-		len(userIDs), eventID, activeCount, maxConcurrent))
-
-	// Generate and store queue passes for each user
-	releasedCount := 0
-	ttlSeconds := int(queuePassTTL.Seconds())
-	for _, userID := range userIDs {
-		queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, queuePassTTL)
-		if err != nil {
-			w.log.Error(fmt.Sprintf("Failed to generate queue pass for user %s: %v", userID, err))
-			continue
-		}
-
-		// Store queue pass in Redis
-		if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, ttlSeconds); err != nil {
-			w.log.Error(fmt.Sprintf("Failed to store queue pass for user %s: %v", userID, err))
-			continue
-		}
-
-		// Publish queue pass ready notification via Pub/Sub
-		// This allows SSE clients to receive real-time updates without polling
-		w.publishQueuePassReady(ctx, eventID, userID, queuePass, expiresAt)
-
-		releasedCount++
-		w.log.Debug(fmt.Sprintf("Released user %s from queue %s with pass expiring at %v",
-			userID, eventID, expiresAt))
+	passes, err := w.queueRepo.IssuePasses(ctx, eventID, releaseCount, queuePassTTL, sign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue queue passes: %w", err)
 	}
 
 	// Update metrics
 	w.mu.Lock()
-	w.totalReleased += int64(releasedCount)
+	w.totalReleased += int64(len(passes))
 	w.lastReleaseTime = time.Now()
-	w.lastReleaseCount = releasedCount
+	w.lastReleaseCount = len(passes)
 	w.mu.Unlock()
 
-	if releasedCount > 0 {
-		w.log.Info(fmt.Sprintf("Successfully released %d/%d users from queue %s",
-			releasedCount, len(userIDs), eventID))
-	}
+	return passes, nil
 }
 
 // getEventConfig gets event queue config with caching
@@ -323,60 +324,20 @@ func (w *QueueReleaseWorker) GetMetrics() (totalReleased int64, lastReleaseTime
 
 // ReleaseFromQueueOnce releases users from a specific queue using dynamic capacity (for testing)
 func (w *QueueReleaseWorker) ReleaseFromQueueOnce(ctx context.Context, eventID string) ([]ReleasedUser, error) {
-	// Get event queue config (cached)
-	config := w.getEventConfig(ctx, eventID)
-	maxConcurrent := config.MaxConcurrentBookings
-	queuePassTTL := time.Duration(config.QueuePassTTLMinutes) * time.Minute
-
-	// Count current active queue passes
-	activeCount, err := w.queueRepo.CountActiveQueuePasses(ctx, eventID)
+	passes, err := w.issuePasses(ctx, eventID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to count active queue passes: %w", err)
+		return nil, err
 	}
 
-	// Calculate how many users to release
-	releaseCount := int64(maxConcurrent) - activeCount
-	if releaseCount <= 0 {
-		return []ReleasedUser{}, nil // At capacity
-	}
-
-	// Pop users from queue
-	userIDs, err := w.queueRepo.PopUsersFromQueue(ctx, eventID, releaseCount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to pop users from queue: %w", err)
-	}
-
-	if len(userIDs) == 0 {
-		return []ReleasedUser{}, nil
-	}
-
-	var releasedUsers []ReleasedUser
-	ttlSeconds := int(queuePassTTL.Seconds())
-	for _, userID := range userIDs {
-		queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, queuePassTTL)
-		if err != nil {
-			continue
-		}
-
-		if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, ttlSeconds); err != nil {
-			continue
-		}
-
+	releasedUsers := make([]ReleasedUser, 0, len(passes))
+	for _, pass := range passes {
 		releasedUsers = append(releasedUsers, ReleasedUser{
-			UserID:           userID,
+			UserID:           pass.UserID,
 			EventID:          eventID,
-			QueuePass:        queuePass,
-			QueuePassExpires: expiresAt,
+			QueuePass:        pass.QueuePass,
+			QueuePassExpires: pass.ExpiresAt,
 		})
 	}
-
-	// Update metrics
-	w.mu.Lock()
-	w.totalReleased += int64(len(releasedUsers))
-	w.lastReleaseTime = time.Now()
-	w.lastReleaseCount = len(releasedUsers)
-	w.mu.Unlock()
-
 	return releasedUsers, nil
 }
 
@@ -402,6 +363,28 @@ func QueuePassChannelKey(eventID, userID string) string {
 	return fmt.Sprintf("queue:pass:%s:%s", eventID, userID)
 }
 
+// QueuePassChannelPattern returns the Pub/Sub pattern matching every user's
+// queue pass channel for an event, for consumers sharing one subscription per event
+func QueuePassChannelPattern(eventID string) string {
+	return QueuePassChannelKey(eventID, "*")
+}
+
+// schedulePublish publishes a queue pass notification, delayed by a random
+// offset within IssuanceSpread to smooth the reservation load of a large batch.
+// The pass itself is already stored, so a delayed notification is still sent
+// if the worker is stopping.
+func (w *QueueReleaseWorker) schedulePublish(ctx context.Context, eventID, userID, queuePass string, expiresAt time.Time) {
+	if w.config.IssuanceSpread <= 0 {
+		w.publishFn(ctx, eventID, userID, queuePass, expiresAt)
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	time.AfterFunc(mathrand.N(w.config.IssuanceSpread), func() {
+		w.publishFn(ctx, eventID, userID, queuePass, expiresAt)
+	})
+}
+
 // publishQueuePassReady publishes a queue pass ready notification via Redis Pub/Sub
 func (w *QueueReleaseWorker) publishQueuePassReady(ctx context.Context, eventID, userID, queuePass string, expiresAt time.Time) {
 	if w.redisClient == nil {
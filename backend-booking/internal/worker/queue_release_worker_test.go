@@ -2,11 +2,14 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -32,9 +35,12 @@ func (m *MockQueueRepository) GetPosition(ctx context.Context, eventID, userID s
 	return args.Get(0).(*repository.QueuePositionResult), args.Error(1)
 }
 
-func (m *MockQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) error {
+func (m *MockQueueRepository) LeaveQueue(ctx context.Context, eventID, userID, token string) (*repository.LeaveQueueResult, error) {
 	args := m.Called(ctx, eventID, userID, token)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.LeaveQueueResult), args.Error(1)
 }
 
 func (m *MockQueueRepository) GetQueueSize(ctx context.Context, eventID string) (int64, error) {
@@ -78,6 +84,28 @@ func (m *MockQueueRepository) PopUsersFromQueue(ctx context.Context, eventID str
 	return args.Get(0).([]string), args.Error(1)
 }
 
+// IssuePasses returns the configured passes, or signs one for each user when
+// the configured result is a []string of promoted user IDs
+func (m *MockQueueRepository) IssuePasses(ctx context.Context, eventID string, count int64, ttl time.Duration, sign repository.PassSigner) ([]repository.IssuedPass, error) {
+	args := m.Called(ctx, eventID, count, ttl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	userIDs, ok := args.Get(0).([]string)
+	if !ok {
+		return args.Get(0).([]repository.IssuedPass), args.Error(1)
+	}
+	passes := make([]repository.IssuedPass, 0, len(userIDs))
+	for _, userID := range userIDs {
+		queuePass, expiresAt, err := sign(userID)
+		if err != nil {
+			return nil, err
+		}
+		passes = append(passes, repository.IssuedPass{UserID: userID, QueuePass: queuePass, ExpiresAt: expiresAt})
+	}
+	return passes, args.Error(1)
+}
+
 func (m *MockQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -170,8 +198,7 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		// 100 active, so release 400 (but only 3 in queue)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(100), nil)
-		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(400)).Return(userIDs, nil)
-		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 300).Return(nil)
+		mockRepo.On("IssuePasses", ctx, eventID, int64(400), 5*time.Minute).Return(userIDs, nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -226,7 +253,7 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
-		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(500)).Return([]string{}, nil)
+		mockRepo.On("IssuePasses", ctx, eventID, int64(500), 5*time.Minute).Return([]string{}, nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -279,9 +306,8 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(customConfig, nil)
 		// 50 active, so release 50
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(50), nil)
-		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(50)).Return([]string{"user-1"}, nil)
-		// TTL should be 10 min = 600 seconds
-		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 600).Return(nil)
+		// TTL should be 10 min
+		mockRepo.On("IssuePasses", ctx, eventID, int64(50), 10*time.Minute).Return([]string{"user-1"}, nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -362,8 +388,7 @@ func TestQueueReleaseWorker_GetMetrics(t *testing.T) {
 
 	mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 	mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
-	mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(500)).Return(userIDs, nil)
-	mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 300).Return(nil)
+	mockRepo.On("IssuePasses", ctx, eventID, int64(500), 5*time.Minute).Return(userIDs, nil)
 
 	_, _ = worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -392,3 +417,89 @@ func TestGenerateUniqueID(t *testing.T) {
 	assert.NotEqual(t, id1, id2) // Should be unique
 	assert.Len(t, id1, 32)       // 16 bytes = 32 hex chars
 }
+
+func TestQueueReleaseWorker_IssuanceSpread(t *testing.T) {
+	log, err := logger.New(&logger.Config{Level: "error", ServiceName: "queue-release-worker-test"})
+	assert.NoError(t, err)
+
+	// newSpreadWorker releases n users and records when each pass notification is published
+	newSpreadWorker := func(n int, spread time.Duration) (*QueueReleaseWorker, *sync.WaitGroup, *[]time.Time) {
+		mockRepo := new(MockQueueRepository)
+		userIDs := make([]string, n)
+		for i := range userIDs {
+			userIDs[i] = fmt.Sprintf("user-%d", i)
+		}
+		mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+		mockRepo.On("CountActiveQueuePasses", mock.Anything, "event-123").Return(int64(0), nil)
+		mockRepo.On("IssuePasses", mock.Anything, "event-123", mock.Anything, mock.Anything).Return(userIDs, nil)
+
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{
+			ReleaseInterval: time.Second,
+			JWTSecret:       testWorkerJWTSecret,
+			IssuanceSpread:  spread,
+		}, mockRepo, nil, log)
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		published := make([]time.Time, 0, n)
+		wg.Add(n)
+		worker.publishFn = func(ctx context.Context, eventID, userID, queuePass string, expiresAt time.Time) {
+			mu.Lock()
+			published = append(published, time.Now())
+			mu.Unlock()
+			wg.Done()
+		}
+		return worker, &wg, &published
+	}
+
+	waitPublished := func(t *testing.T, wg *sync.WaitGroup) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for queue pass notifications")
+		}
+	}
+
+	t.Run("spreads notifications across the window", func(t *testing.T) {
+		const n = 50
+		spread := 200 * time.Millisecond
+		worker, wg, published := newSpreadWorker(n, spread)
+
+		start := time.Now()
+		worker.releaseFromQueue(context.Background(), "event-123")
+		assert.Less(t, time.Since(start), spread/2, "releasing should not wait for the spread")
+
+		waitPublished(t, wg)
+		first, last := (*published)[0], (*published)[0]
+		for _, ts := range *published {
+			offset := ts.Sub(start)
+			assert.GreaterOrEqual(t, offset, time.Duration(0))
+			assert.Less(t, offset, spread+100*time.Millisecond, "notification published outside the window")
+			if ts.Before(first) {
+				first = ts
+			}
+			if ts.After(last) {
+				last = ts
+			}
+		}
+		assert.Greater(t, last.Sub(first), spread/2, "notifications should not all be published at once")
+	})
+
+	t.Run("publishes immediately without a spread", func(t *testing.T) {
+		worker, _, published := newSpreadWorker(10, 0)
+
+		worker.releaseFromQueue(context.Background(), "event-123")
+		assert.Len(t, *published, 10)
+	})
+
+	t.Run("caps the spread at the release interval", func(t *testing.T) {
+		worker, _, _ := newSpreadWorker(0, time.Minute)
+		assert.Equal(t, time.Second, worker.config.IssuanceSpread)
+	})
+}
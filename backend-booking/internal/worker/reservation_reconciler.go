@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// ReservationReconcilerConfig contains configuration for the reservation reconciler
+type ReservationReconcilerConfig struct {
+	// Interval is the time between reconciliation passes (default: 1 minute)
+	Interval time.Duration
+	// BatchSize is the SCAN page size and the number of holds released per batch (default: 100)
+	BatchSize int64
+}
+
+// DefaultReservationReconcilerConfig returns default configuration
+func DefaultReservationReconcilerConfig() *ReservationReconcilerConfig {
+	return &ReservationReconcilerConfig{
+		Interval:  1 * time.Minute,
+		BatchSize: 100,
+	}
+}
+
+// ReservationHoldStore lists and releases Redis reservation holds
+type ReservationHoldStore interface {
+	ScanReservations(ctx context.Context, batchSize int64, fn func([]repository.HeldReservation) error) error
+	ReleaseSeatsBatch(ctx context.Context, holds []repository.HeldReservation) ([]*repository.ReleaseResult, error)
+}
+
+// SagaStateLookup finds the status of the saga driving each booking
+// (satisfied by pkgsaga.PostgresStore)
+type SagaStateLookup interface {
+	GetStatusesByBookingID(ctx context.Context, bookingIDs []string) (map[string]pkgsaga.Status, error)
+}
+
+// ReservationReconciler releases Redis holds whose booking saga has already
+// failed or been compensated, so orphaned seats return to inventory before the
+// reservation TTL would expire them
+type ReservationReconciler struct {
+	holds  ReservationHoldStore
+	sagas  SagaStateLookup
+	config *ReservationReconcilerConfig
+	log    *logger.Logger
+
+	// Stats
+	mu            sync.Mutex
+	totalReleased int64
+	lastRunTime   time.Time
+}
+
+// NewReservationReconciler creates a new reservation reconciler
+func NewReservationReconciler(
+	holds ReservationHoldStore,
+	sagas SagaStateLookup,
+	config *ReservationReconcilerConfig,
+	log *logger.Logger,
+) *ReservationReconciler {
+	if config == nil {
+		config = DefaultReservationReconcilerConfig()
+	}
+	if config.Interval <= 0 {
+		config.Interval = 1 * time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if log == nil {
+		log = logger.Get()
+	}
+
+	return &ReservationReconciler{
+		holds:  holds,
+		sagas:  sagas,
+		config: config,
+		log:    log,
+	}
+}
+
+// Start runs ReconcileReservations every Interval until ctx is cancelled
+func (r *ReservationReconciler) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	r.log.Info(fmt.Sprintf("Reservation reconciler started (interval: %v, batch size: %d)",
+		r.config.Interval, r.config.BatchSize))
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Reservation reconciler stopping...")
+			return
+		case <-ticker.C:
+			if _, err := r.ReconcileReservations(ctx); err != nil {
+				r.log.Error(fmt.Sprintf("Reservation reconciliation failed: %v", err))
+			}
+		}
+	}
+}
+
+// ReconcileReservations scans every reservation hold, looks up its booking
+// saga and releases holds whose saga failed or was compensated. Holds with
+// no saga or a saga still in progress are left to the reservation TTL. It
+// returns the number of holds released.
+func (r *ReservationReconciler) ReconcileReservations(ctx context.Context) (int, error) {
+	released := 0
+	err := r.holds.ScanReservations(ctx, r.config.BatchSize, func(page []repository.HeldReservation) error {
+		orphans, err := r.findOrphans(ctx, page)
+		if err != nil {
+			return err
+		}
+		released += r.releaseBatch(ctx, orphans)
+		return ctx.Err()
+	})
+
+	r.mu.Lock()
+	r.totalReleased += int64(released)
+	r.lastRunTime = time.Now()
+	r.mu.Unlock()
+
+	if released > 0 {
+		r.log.Info(fmt.Sprintf("Reconciliation released %d orphaned reservations", released))
+	}
+	return released, err
+}
+
+// findOrphans returns the holds in page whose saga has failed or been compensated
+func (r *ReservationReconciler) findOrphans(ctx context.Context, page []repository.HeldReservation) ([]repository.HeldReservation, error) {
+	bookingIDs := make([]string, len(page))
+	for i, hold := range page {
+		bookingIDs[i] = hold.BookingID
+	}
+
+	statuses, err := r.sagas.GetStatusesByBookingID(ctx, bookingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sagas for reservations: %w", err)
+	}
+
+	var orphans []repository.HeldReservation
+	for _, hold := range page {
+		switch statuses[hold.BookingID] {
+		case pkgsaga.StatusFailed, pkgsaga.StatusCompensated, pkgsaga.StatusCompensationFailed:
+			orphans = append(orphans, hold)
+		}
+	}
+	return orphans, nil
+}
+
+// releaseBatch releases orphaned holds back to inventory and returns how many were released
+func (r *ReservationReconciler) releaseBatch(ctx context.Context, orphans []repository.HeldReservation) int {
+	if len(orphans) == 0 {
+		return 0
+	}
+
+	results, err := r.holds.ReleaseSeatsBatch(ctx, orphans)
+	if err != nil {
+		r.log.Warn(fmt.Sprintf("Failed to release %d orphaned reservations: %v", len(orphans), err))
+		return 0
+	}
+
+	released := 0
+	for i, hold := range orphans {
+		result := results[i]
+		if !result.Success {
+			// RESERVATION_NOT_FOUND means it expired or was released concurrently
+			if result.ErrorCode != pkgredis.CodeReservationNotFound {
+				r.log.Warn(fmt.Sprintf("Could not release orphaned reservation %s: %s - %s",
+					hold.BookingID, result.ErrorCode, result.ErrorMessage))
+			}
+			continue
+		}
+		released++
+		r.log.Debug(fmt.Sprintf("Released orphaned reservation %s (user: %s)", hold.BookingID, hold.UserID))
+	}
+	return released
+}
+
+// GetMetrics returns reconciler statistics
+func (r *ReservationReconciler) GetMetrics() (totalReleased int64, lastRunTime time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.totalReleased, r.lastRunTime
+}
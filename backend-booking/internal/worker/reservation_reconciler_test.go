@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHoldStore is an in-memory ReservationHoldStore keyed by booking ID
+type fakeHoldStore struct {
+	mu       sync.Mutex
+	holds    map[string]string // bookingID -> userID
+	released []string
+	pages    int
+	batches  int
+}
+
+func (f *fakeHoldStore) ScanReservations(ctx context.Context, batchSize int64, fn func([]repository.HeldReservation) error) error {
+	f.mu.Lock()
+	var all []repository.HeldReservation
+	for bookingID, userID := range f.holds {
+		all = append(all, repository.HeldReservation{BookingID: bookingID, UserID: userID})
+	}
+	f.mu.Unlock()
+
+	for start := 0; start < len(all); start += int(batchSize) {
+		end := min(start+int(batchSize), len(all))
+		f.pages++
+		if err := fn(all[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeHoldStore) ReleaseSeatsBatch(ctx context.Context, holds []repository.HeldReservation) ([]*repository.ReleaseResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches++
+	results := make([]*repository.ReleaseResult, len(holds))
+	for i, hold := range holds {
+		if owner, ok := f.holds[hold.BookingID]; !ok || owner != hold.UserID {
+			results[i] = &repository.ReleaseResult{Success: false, ErrorCode: "RESERVATION_NOT_FOUND"}
+			continue
+		}
+		delete(f.holds, hold.BookingID)
+		f.released = append(f.released, hold.BookingID)
+		results[i] = &repository.ReleaseResult{Success: true}
+	}
+	return results, nil
+}
+
+func TestReservationReconciler_ReleasesOrphanedReservations(t *testing.T) {
+	ctx := context.Background()
+	sagaStore := pkgsaga.NewMemoryStore()
+
+	// newSaga saves a booking saga for bookingID in status
+	newSaga := func(bookingID string, status pkgsaga.Status) {
+		instance := pkgsaga.NewInstance("booking-saga", map[string]interface{}{"booking_id": bookingID})
+		instance.Status = status
+		require.NoError(t, sagaStore.Save(ctx, instance))
+	}
+
+	newSaga("failed", pkgsaga.StatusFailed)
+	newSaga("compensated", pkgsaga.StatusCompensated)
+	newSaga("in-progress", pkgsaga.StatusRunning)
+
+	holds := &fakeHoldStore{holds: map[string]string{
+		"failed":      "user-failed",
+		"compensated": "user-compensated",
+		"in-progress": "user-in-progress",
+		"no-saga":     "user-no-saga", // saga not created yet; left to the TTL
+	}}
+
+	reconciler := NewReservationReconciler(holds, sagaStore, &ReservationReconcilerConfig{BatchSize: 2}, nil)
+
+	released, err := reconciler.ReconcileReservations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, released)
+
+	sort.Strings(holds.released)
+	assert.Equal(t, []string{"compensated", "failed"}, holds.released)
+	assert.Contains(t, holds.holds, "in-progress")
+	assert.Contains(t, holds.holds, "no-saga")
+	assert.Equal(t, 2, holds.pages, "holds should be processed in batches")
+	assert.LessOrEqual(t, holds.batches, holds.pages, "each page should release its orphans in one call")
+
+	total, lastRun := reconciler.GetMetrics()
+	assert.Equal(t, int64(2), total)
+	assert.False(t, lastRun.IsZero())
+
+	// A second pass finds nothing left to release
+	released, err = reconciler.ReconcileReservations(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, released)
+}
+
+func TestNewReservationReconciler_Defaults(t *testing.T) {
+	reconciler := NewReservationReconciler(&fakeHoldStore{}, pkgsaga.NewMemoryStore(), &ReservationReconcilerConfig{}, nil)
+
+	assert.Equal(t, DefaultReservationReconcilerConfig().Interval, reconciler.config.Interval)
+	assert.Equal(t, DefaultReservationReconcilerConfig().BatchSize, reconciler.config.BatchSize)
+}
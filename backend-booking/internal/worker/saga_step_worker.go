@@ -10,8 +10,10 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
 // SagaStepWorkerConfig contains configuration for the saga step worker
@@ -19,6 +21,12 @@ type SagaStepWorkerConfig struct {
 	WorkerCount   int
 	RetryAttempts int
 	RetryDelay    time.Duration
+	// ConfirmationCodes issues confirmation codes. Nil checks codes against
+	// the booking repository when it can look them up.
+	ConfirmationCodes *saga.ConfirmationCodeGenerator
+	// HoldReleaser returns a compensated booking's seats. Nil releases them
+	// through the reservation repository's release_seats script.
+	HoldReleaser pkgsaga.HoldReleaser
 }
 
 // SagaStepWorker consumes saga commands and executes steps
@@ -47,6 +55,13 @@ func NewSagaStepWorker(
 			RetryDelay:    time.Second,
 		}
 	}
+	if config.ConfirmationCodes == nil {
+		store, _ := bookingRepo.(saga.ConfirmationCodeStore)
+		config.ConfirmationCodes = saga.NewConfirmationCodeGenerator(store)
+	}
+	if config.HoldReleaser == nil {
+		config.HoldReleaser = service.NewReservationHoldReleaser(reservationRepo)
+	}
 	return &SagaStepWorker{
 		consumer:        consumer,
 		producer:        producer,
@@ -273,10 +288,16 @@ func (w *SagaStepWorker) handleReleaseSeats(ctx context.Context, record *kafka.R
 	data := &saga.BookingSagaData{}
 	data.FromMap(command.OriginalStepData)
 
-	// Execute release
-	_, err := w.reservationRepo.ReleaseSeats(ctx, data.BookingID, data.UserID)
-	if err != nil {
+	// Execute release; a hold that is already gone counts as released
+	if err := w.releaseHold(ctx, data); err != nil {
 		log.Error(fmt.Sprintf("Failed to release seats: %v", err))
+
+		// Keep the failed release so the seats are not lost until the hold expires
+		if w.dlqHandler != nil {
+			if dlqErr := w.dlqHandler.HandleFailedMessage(ctx, saga.TopicSagaReleaseSeatsCommand, command.SagaID, record.Value, err, 0); dlqErr != nil {
+				log.Error(fmt.Sprintf("Failed to send to DLQ: %v", dlqErr))
+			}
+		}
 	} else {
 		log.Info(fmt.Sprintf("Released seats: booking_id=%s", data.BookingID))
 	}
@@ -284,6 +305,15 @@ func (w *SagaStepWorker) handleReleaseSeats(ctx context.Context, record *kafka.R
 	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
 }
 
+// releaseHold returns the seats held for a compensated booking saga
+func (w *SagaStepWorker) releaseHold(ctx context.Context, data *saga.BookingSagaData) error {
+	return w.config.HoldReleaser.ReleaseHold(ctx, &pkgsaga.BookingSaga{
+		BookingID: data.BookingID,
+		EventID:   data.EventID,
+		UserID:    data.UserID,
+	})
+}
+
 // handleConfirmBooking handles the confirm-booking step
 // This is triggered by the post-payment saga after payment success
 func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka.Record) error {
@@ -332,6 +362,8 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 		execErr = fmt.Errorf("failed to get booking: %w", err)
 	} else if booking == nil {
 		execErr = fmt.Errorf("booking not found: %s", bookingID)
+	} else if confirmationCode, err := w.confirmationCode(ctx, booking); err != nil {
+		execErr = fmt.Errorf("failed to generate confirmation code: %w", err)
 	} else {
 		// Use userID from booking if not provided in command
 		if userID == "" {
@@ -358,14 +390,6 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 		booking.PaymentID = paymentID
 		booking.ConfirmedAt = &now
 		booking.UpdatedAt = now
-
-		// Generate confirmation code
-		confirmationCode := bookingID[:8]
-		if len(bookingID) >= 8 {
-			confirmationCode = bookingID[:8]
-		} else {
-			confirmationCode = bookingID
-		}
 		booking.ConfirmationCode = confirmationCode
 
 		if err := w.bookingRepo.Update(ctx, booking); err != nil {
@@ -527,3 +551,12 @@ func (w *SagaStepWorker) handleSendNotification(ctx context.Context, record *kaf
 
 	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
 }
+
+// confirmationCode returns the booking's confirmation code, issuing one unless
+// a redelivered command already did
+func (w *SagaStepWorker) confirmationCode(ctx context.Context, booking *domain.Booking) (string, error) {
+	if booking.ConfirmationCode != "" {
+		return booking.ConfirmationCode, nil
+	}
+	return w.config.ConfirmationCodes.Generate(ctx)
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHoldReleaser records the sagas whose holds it was asked to release
+type fakeHoldReleaser struct {
+	released []*pkgsaga.BookingSaga
+	err      error
+}
+
+func (f *fakeHoldReleaser) ReleaseHold(ctx context.Context, saga *pkgsaga.BookingSaga) error {
+	f.released = append(f.released, saga)
+	return f.err
+}
+
+func TestSagaStepWorker_ReleaseHold(t *testing.T) {
+	releaser := &fakeHoldReleaser{}
+	w := NewSagaStepWorker(nil, nil, nil, nil, nil, &SagaStepWorkerConfig{HoldReleaser: releaser})
+
+	data := &saga.BookingSagaData{BookingID: "booking-1", EventID: "event-1", UserID: "user-1"}
+	require.NoError(t, w.releaseHold(context.Background(), data))
+
+	require.Len(t, releaser.released, 1)
+	assert.Equal(t, "booking-1", releaser.released[0].BookingID)
+	assert.Equal(t, "user-1", releaser.released[0].UserID)
+
+	releaser.err = errors.New("redis unavailable")
+	assert.ErrorIs(t, w.releaseHold(context.Background(), data), releaser.err)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lifecycle"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
@@ -56,6 +57,9 @@ func main() {
 
 	ctx := context.Background()
 
+	// Components register closers as they start; they run in priority order on shutdown
+	shutdown := lifecycle.NewShutdownManager(30 * time.Second)
+
 	// Initialize OpenTelemetry
 	telemetryCfg := &telemetry.Config{
 		Enabled:        cfg.OTel.Enabled,
@@ -70,7 +74,7 @@ func main() {
 	} else if telemetryCfg.Enabled {
 		appLog.Info(fmt.Sprintf("Telemetry initialized (collector: %s)", telemetryCfg.CollectorAddr))
 	}
-	defer telemetry.Shutdown(ctx)
+	shutdown.Register("telemetry", lifecycle.PriorityFlush, telemetry.Shutdown)
 
 	// Initialize database connection with optimized settings for 10k RPS
 	// Uses BookingDatabase config (Microservice - each service has its own database)
@@ -95,7 +99,10 @@ func main() {
 	if err != nil {
 		appLog.Fatal(fmt.Sprintf("Database connection failed: %v", err))
 	}
-	defer db.Close()
+	shutdown.Register("postgres", lifecycle.PriorityConnections, func(context.Context) error {
+		db.Close()
+		return nil
+	})
 	appLog.Info(fmt.Sprintf("Database connected (pool: min=%d, max=%d)", dbCfg.MinConns, dbCfg.MaxConns))
 
 	// Initialize Redis connection with optimized settings for 10k RPS
@@ -105,6 +112,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      500, // Large pool for 10k RPS
 		MinIdleConns:  100, // Keep connections ready
 		MaxRetries:    3,
@@ -120,9 +130,16 @@ func main() {
 	if err != nil {
 		appLog.Fatal(fmt.Sprintf("Redis connection failed: %v", err))
 	}
-	defer redisClient.Close()
+	shutdown.RegisterFunc("redis", lifecycle.PriorityConnections, redisClient.Close)
 	appLog.Info(fmt.Sprintf("Redis connected (pool: %d, minIdle: %d)", redisCfg.PoolSize, redisCfg.MinIdleConns))
 
+	// Open the idle connections now rather than during the opening burst
+	if opened, err := redisClient.Warmup(ctx); err != nil {
+		appLog.Warn(fmt.Sprintf("Redis warmup incomplete: %v", err))
+	} else {
+		appLog.Info(fmt.Sprintf("Redis pool warmed up (%d connections)", opened))
+	}
+
 	// Initialize Kafka event publisher
 	var eventPublisher service.EventPublisher
 	eventPubCfg := &service.EventPublisherConfig{
@@ -199,6 +216,16 @@ func main() {
 	requireQueuePass := cfg.Booking.RequireQueuePass
 	appLog.Info(fmt.Sprintf("Virtual Queue: RequireQueuePass=%v", requireQueuePass))
 
+	queueHandlerCfg := &handler.QueueHandlerConfig{
+		MaxStreams:        10000,
+		SubscriptionMode:  handler.SubscriptionShared, // One Redis subscription per event, fanned out in-process
+		KeepaliveInterval: cfg.Booking.QueueStreamKeepalive,
+		MaxWait:           cfg.Booking.QueueStreamMaxWait,
+	}
+	if err := queueHandlerCfg.Validate(); err != nil {
+		appLog.Fatal(fmt.Sprintf("Invalid queue stream config: %v", err))
+	}
+
 	container := di.NewContainer(&di.ContainerConfig{
 		DB:              db,
 		Redis:           redisClient,
@@ -207,8 +234,15 @@ func main() {
 		QueueRepo:       queueRepo,
 		EventPublisher:  eventPublisher,
 		ServiceConfig: &service.BookingServiceConfig{
-			ReservationTTL: reservationTTL,
-			MaxPerUser:     maxPerUser,
+			ReservationTTL:     reservationTTL,
+			MaxPerUser:         maxPerUser,
+			OverbookAllowances: cfg.Booking.ZoneOverbookAllowances,
+			LimitPolicy: &service.StaticLimitPolicy{
+				Default: maxPerUser,
+				Events:  cfg.Booking.EventMaxTicketsPerUser,
+				Roles:   cfg.Booking.RoleMaxTicketsPerUser,
+			},
+			AvailabilityCacheTTL: cfg.Booking.AvailabilityCacheTTL,
 		},
 		QueueServiceConfig: &service.QueueServiceConfig{
 			QueueTTL:             30 * time.Minute,
@@ -226,6 +260,7 @@ func main() {
 		BookingHandlerConfig: &handler.BookingHandlerConfig{
 			RequireQueuePass: requireQueuePass,
 		},
+		QueueHandlerConfig: queueHandlerCfg,
 	})
 
 	// Setup Gin with optimized settings
@@ -243,6 +278,9 @@ func main() {
 		router.Use(telemetry.TraceHeaderMiddleware())
 	}
 
+	// Log, trace and answer panics from handlers with the standard envelope
+	router.Use(middleware.Recovery(appLog))
+
 	// Health check endpoints
 	router.GET("/health", container.HealthHandler.Health)
 	router.GET("/ready", container.HealthHandler.Ready)
@@ -283,15 +321,31 @@ func main() {
 
 		{
 			// Write operations with idempotency
-			bookings.POST("/reserve", middleware.IdempotencyMiddleware(idempotencyConfig), container.BookingHandler.ReserveSeats)
+			reserveHandlers := []gin.HandlerFunc{
+				// Sheds load with 429 before any Redis work when the gateway
+				// limit in front of the service is missing or too generous
+				middleware.Backpressure(cfg.Booking.MaxConcurrentReservations, middleware.DefaultBackpressureRetryAfter),
+				middleware.IdempotencyMiddleware(idempotencyConfig),
+				// Rejects replays of a captured request carrying X-Request-Nonce;
+				// after idempotency so retries with the same key replay instead
+				middleware.RequireNonce(redisClient.Client()),
+			}
+			if requireQueuePass {
+				// Runs after idempotency so a retried reservation replays its result
+				// even though the pass was consumed by the first attempt
+				reserveHandlers = append(reserveHandlers, middleware.RequireQueuePass(redisClient.Client()))
+			}
+			bookings.POST("/reserve", append(reserveHandlers, container.BookingHandler.ReserveSeats)...)
 			bookings.POST("/:id/confirm", middleware.IdempotencyMiddleware(idempotencyConfig), container.BookingHandler.ConfirmBooking)
 			bookings.POST("/:id/cancel", middleware.IdempotencyMiddleware(idempotencyConfig), container.BookingHandler.CancelBooking)
 			bookings.DELETE("/:id", middleware.IdempotencyMiddleware(idempotencyConfig), container.BookingHandler.ReleaseBooking)
+			bookings.DELETE("/:id/reservation", middleware.IdempotencyMiddleware(idempotencyConfig), container.BookingHandler.CancelReservation)
 
 			// Read operations without idempotency
 			bookings.GET("", container.BookingHandler.GetUserBookings)
 			bookings.GET("/summary", container.BookingHandler.GetUserBookingSummary) // Must be before /:id
 			bookings.GET("/pending", container.BookingHandler.GetPendingBookings)
+			bookings.GET("/availability/:zone_id", container.BookingHandler.GetZoneAvailability)
 			bookings.GET("/:id", container.BookingHandler.GetBooking)
 		}
 
@@ -323,6 +377,17 @@ func main() {
 
 			// Get inventory status (PostgreSQL vs Redis)
 			admin.GET("/inventory-status", container.AdminHandler.GetInventoryStatus)
+
+			// Live reservation snapshot for the ops dashboard
+			admin.GET("/events/:event_id/stats", container.AdminHandler.GetEventStats)
+
+			// Runtime log-level control for admins: PUT {"level":"debug"}
+			logLevel := admin.Group("/log-level",
+				middleware.JWTMiddleware(&middleware.JWTConfig{Secret: cfg.JWT.Secret}),
+				middleware.RequireRole("admin"),
+			)
+			logLevel.GET("", gin.WrapH(appLog.LevelHandler()))
+			logLevel.PUT("", gin.WrapH(appLog.LevelHandler()))
 		}
 
 		// Saga routes - async booking via saga pattern
@@ -373,18 +438,19 @@ func main() {
 	<-quit
 	appLog.Info("Shutting down server...")
 
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Close SSE queue streams first; srv.Shutdown would wait on them until the deadline
+	shutdown.Register("sse-streams", lifecycle.PriorityServer, container.QueueHandler.Shutdown)
+	shutdown.Register("http-server", lifecycle.PriorityServer, srv.Shutdown)
 
-	if err := srv.Shutdown(ctx); err != nil {
-		appLog.Fatal(fmt.Sprintf("Server forced to shutdown: %v", err))
+	// Give outstanding requests and the remaining closers 30 seconds to complete
+	if err := shutdown.Shutdown(context.Background()); err != nil {
+		appLog.Error(fmt.Sprintf("Shutdown did not complete cleanly: %v", err))
 	}
 
 	appLog.Info("Server exited gracefully")
 }
 
-// userIDMiddleware extracts user_id and tenant_id from headers
+// userIDMiddleware extracts user_id, tenant_id and role from headers
 func userIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.GetHeader("X-User-ID")
@@ -400,6 +466,11 @@ func userIDMiddleware() gin.HandlerFunc {
 			c.Set("tenant_id", tenantID)
 		}
 
+		// Role selects role-specific per-user limits
+		if role := c.GetHeader("X-User-Role"); role != "" {
+			c.Set("role", role)
+		}
+
 		c.Next()
 	}
 }
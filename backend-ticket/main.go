@@ -94,6 +94,9 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		TLSEnabled:    cfg.Redis.TLSEnabled,
+		TLSSkipVerify: cfg.Redis.TLSSkipVerify,
+		TLSCAFile:     cfg.Redis.TLSCAFile,
 		PoolSize:      cfg.Redis.PoolSize,
 		MinIdleConns:  cfg.Redis.MinIdleConns,
 		DialTimeout:   cfg.Redis.DialTimeout,
@@ -135,6 +138,9 @@ func main() {
 		router.Use(telemetry.TraceHeaderMiddleware())
 	}
 
+	// Log, trace and answer panics from handlers with the standard envelope
+	router.Use(middleware.Recovery(appLog))
+
 	// Health check endpoints
 	router.GET("/health", container.HealthHandler.Health)
 	router.GET("/ready", container.HealthHandler.Ready)
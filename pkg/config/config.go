@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,9 +29,20 @@ type Config struct {
 
 // BookingServiceConfig holds booking service specific settings
 type BookingServiceConfig struct {
-	MaxTicketsPerUser     int  `mapstructure:"max_tickets_per_user"`    // Maximum tickets per user per event (0 = unlimited)
-	ReservationTTLMinutes int  `mapstructure:"reservation_ttl_minutes"` // Reservation TTL in minutes
-	RequireQueuePass      bool `mapstructure:"require_queue_pass"`      // Require queue pass for booking (virtual queue enforcement)
+	MaxTicketsPerUser     int           `mapstructure:"max_tickets_per_user"`    // Maximum tickets per user per event (0 = unlimited)
+	ReservationTTLMinutes int           `mapstructure:"reservation_ttl_minutes"` // Reservation TTL in minutes
+	RequireQueuePass      bool          `mapstructure:"require_queue_pass"`      // Require queue pass for booking (virtual queue enforcement)
+	QueueStreamKeepalive  time.Duration `mapstructure:"queue_stream_keepalive"`  // How often an idle SSE queue stream re-sends the position
+	QueueStreamMaxWait    time.Duration `mapstructure:"queue_stream_max_wait"`   // How long an SSE queue stream waits for a queue pass
+	AvailabilityCacheTTL  time.Duration `mapstructure:"availability_cache_ttl"`  // How long coalesced zone availability reads are reused
+	// MaxConcurrentReservations caps in-flight reserve requests; the overflow gets 503 (0 = unlimited)
+	MaxConcurrentReservations int64 `mapstructure:"max_concurrent_reservations"`
+	// ZoneOverbookAllowances maps zone ID to the seats that zone may be oversold by (unlisted zones: 0)
+	ZoneOverbookAllowances map[string]int `mapstructure:"zone_overbook_allowances"`
+	// EventMaxTicketsPerUser overrides MaxTicketsPerUser for listed event IDs (e.g. resale-protected events)
+	EventMaxTicketsPerUser map[string]int `mapstructure:"event_max_tickets_per_user"`
+	// RoleMaxTicketsPerUser overrides MaxTicketsPerUser for listed user roles (e.g. vip)
+	RoleMaxTicketsPerUser map[string]int `mapstructure:"role_max_tickets_per_user"`
 }
 
 // ServicesConfig holds URLs of other microservices
@@ -89,6 +102,10 @@ type RedisConfig struct {
 	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// TLS settings for managed Redis
+	TLSEnabled    bool   `mapstructure:"tls_enabled"`
+	TLSSkipVerify bool   `mapstructure:"tls_skip_verify"` // Testing only
+	TLSCAFile     string `mapstructure:"tls_ca_file"`     // PEM CA bundle (default: system roots)
 }
 
 // Addr returns the Redis address
@@ -268,6 +285,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("REDIS_DIAL_TIMEOUT", "5s")
 	v.SetDefault("REDIS_READ_TIMEOUT", "3s")
 	v.SetDefault("REDIS_WRITE_TIMEOUT", "3s")
+	v.SetDefault("REDIS_TLS_ENABLED", false)
+	v.SetDefault("REDIS_TLS_SKIP_VERIFY", false)
+	v.SetDefault("REDIS_TLS_CA_FILE", "")
 
 	// Kafka defaults
 	v.SetDefault("KAFKA_BROKERS", "localhost:9092")
@@ -279,7 +299,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("MONGODB_DATABASE", "booking_rush")
 
 	// JWT defaults
-	v.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
+	v.SetDefault("JWT_SECRET", defaultJWTSecret)
 	v.SetDefault("JWT_ACCESS_TOKEN_TTL", "15m")
 	v.SetDefault("JWT_REFRESH_TOKEN_TTL", "168h") // 7 days
 	v.SetDefault("JWT_ISSUER", "booking-rush")
@@ -295,9 +315,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("MAX_TICKETS_PER_USER", 10)        // Default 10 tickets per user per event
 	v.SetDefault("RESERVATION_TTL_MINUTES", 10)    // Default 10 minutes reservation TTL
 	v.SetDefault("REQUIRE_QUEUE_PASS", false)      // Default: don't require queue pass (for backward compatibility)
+	v.SetDefault("QUEUE_STREAM_KEEPALIVE", "15s")
+	v.SetDefault("QUEUE_STREAM_MAX_WAIT", "5m") // Should match the queue pass TTL
+	v.SetDefault("AVAILABILITY_CACHE_TTL", "250ms")
+	v.SetDefault("MAX_CONCURRENT_RESERVATIONS", 5000)
 }
 
-func bindConfig(v *viper.Viper, cfg *Config) error {
+func bindConfig(v *viper.Viper, cfg *Config) (err error) {
 	// App
 	cfg.App.Name = v.GetString("APP_NAME")
 	cfg.App.Environment = v.GetString("APP_ENVIRONMENT")
@@ -319,7 +343,9 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.AuthDatabase.Host = v.GetString("AUTH_DATABASE_HOST")
 	cfg.AuthDatabase.Port = v.GetInt("AUTH_DATABASE_PORT")
 	cfg.AuthDatabase.User = v.GetString("AUTH_DATABASE_USER")
-	cfg.AuthDatabase.Password = v.GetString("AUTH_DATABASE_PASSWORD")
+	if cfg.AuthDatabase.Password, err = getSecret(v, "AUTH_DATABASE_PASSWORD"); err != nil {
+		return err
+	}
 	cfg.AuthDatabase.DBName = v.GetString("AUTH_DATABASE_DBNAME")
 	cfg.AuthDatabase.SSLMode = v.GetString("AUTH_DATABASE_SSLMODE")
 	cfg.AuthDatabase.MaxOpenConns = v.GetInt("AUTH_DATABASE_MAX_OPEN_CONNS")
@@ -331,7 +357,9 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.TicketDatabase.Host = v.GetString("TICKET_DATABASE_HOST")
 	cfg.TicketDatabase.Port = v.GetInt("TICKET_DATABASE_PORT")
 	cfg.TicketDatabase.User = v.GetString("TICKET_DATABASE_USER")
-	cfg.TicketDatabase.Password = v.GetString("TICKET_DATABASE_PASSWORD")
+	if cfg.TicketDatabase.Password, err = getSecret(v, "TICKET_DATABASE_PASSWORD"); err != nil {
+		return err
+	}
 	cfg.TicketDatabase.DBName = v.GetString("TICKET_DATABASE_DBNAME")
 	cfg.TicketDatabase.SSLMode = v.GetString("TICKET_DATABASE_SSLMODE")
 	cfg.TicketDatabase.MaxOpenConns = v.GetInt("TICKET_DATABASE_MAX_OPEN_CONNS")
@@ -343,7 +371,9 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.BookingDatabase.Host = v.GetString("BOOKING_DATABASE_HOST")
 	cfg.BookingDatabase.Port = v.GetInt("BOOKING_DATABASE_PORT")
 	cfg.BookingDatabase.User = v.GetString("BOOKING_DATABASE_USER")
-	cfg.BookingDatabase.Password = v.GetString("BOOKING_DATABASE_PASSWORD")
+	if cfg.BookingDatabase.Password, err = getSecret(v, "BOOKING_DATABASE_PASSWORD"); err != nil {
+		return err
+	}
 	cfg.BookingDatabase.DBName = v.GetString("BOOKING_DATABASE_DBNAME")
 	cfg.BookingDatabase.SSLMode = v.GetString("BOOKING_DATABASE_SSLMODE")
 	cfg.BookingDatabase.MaxOpenConns = v.GetInt("BOOKING_DATABASE_MAX_OPEN_CONNS")
@@ -355,7 +385,9 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.PaymentDatabase.Host = v.GetString("PAYMENT_DATABASE_HOST")
 	cfg.PaymentDatabase.Port = v.GetInt("PAYMENT_DATABASE_PORT")
 	cfg.PaymentDatabase.User = v.GetString("PAYMENT_DATABASE_USER")
-	cfg.PaymentDatabase.Password = v.GetString("PAYMENT_DATABASE_PASSWORD")
+	if cfg.PaymentDatabase.Password, err = getSecret(v, "PAYMENT_DATABASE_PASSWORD"); err != nil {
+		return err
+	}
 	cfg.PaymentDatabase.DBName = v.GetString("PAYMENT_DATABASE_DBNAME")
 	cfg.PaymentDatabase.SSLMode = v.GetString("PAYMENT_DATABASE_SSLMODE")
 	cfg.PaymentDatabase.MaxOpenConns = v.GetInt("PAYMENT_DATABASE_MAX_OPEN_CONNS")
@@ -366,13 +398,18 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	// Redis
 	cfg.Redis.Host = v.GetString("REDIS_HOST")
 	cfg.Redis.Port = v.GetInt("REDIS_PORT")
-	cfg.Redis.Password = v.GetString("REDIS_PASSWORD")
+	if cfg.Redis.Password, err = getSecret(v, "REDIS_PASSWORD"); err != nil {
+		return err
+	}
 	cfg.Redis.DB = v.GetInt("REDIS_DB")
 	cfg.Redis.PoolSize = v.GetInt("REDIS_POOL_SIZE")
 	cfg.Redis.MinIdleConns = v.GetInt("REDIS_MIN_IDLE_CONNS")
 	cfg.Redis.DialTimeout = v.GetDuration("REDIS_DIAL_TIMEOUT")
 	cfg.Redis.ReadTimeout = v.GetDuration("REDIS_READ_TIMEOUT")
 	cfg.Redis.WriteTimeout = v.GetDuration("REDIS_WRITE_TIMEOUT")
+	cfg.Redis.TLSEnabled = v.GetBool("REDIS_TLS_ENABLED")
+	cfg.Redis.TLSSkipVerify = v.GetBool("REDIS_TLS_SKIP_VERIFY")
+	cfg.Redis.TLSCAFile = v.GetString("REDIS_TLS_CA_FILE")
 
 	// Kafka
 	brokersStr := v.GetString("KAFKA_BROKERS")
@@ -385,7 +422,9 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.MongoDB.Database = v.GetString("MONGODB_DATABASE")
 
 	// JWT
-	cfg.JWT.Secret = v.GetString("JWT_SECRET")
+	if cfg.JWT.Secret, err = getSecret(v, "JWT_SECRET"); err != nil {
+		return err
+	}
 	cfg.JWT.AccessTokenTTL = v.GetDuration("JWT_ACCESS_TOKEN_TTL")
 	cfg.JWT.RefreshTokenTTL = v.GetDuration("JWT_REFRESH_TOKEN_TTL")
 	cfg.JWT.Issuer = v.GetString("JWT_ISSUER")
@@ -401,74 +440,62 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.Booking.MaxTicketsPerUser = v.GetInt("MAX_TICKETS_PER_USER")
 	cfg.Booking.ReservationTTLMinutes = v.GetInt("RESERVATION_TTL_MINUTES")
 	cfg.Booking.RequireQueuePass = v.GetBool("REQUIRE_QUEUE_PASS")
-
-	return nil
-}
-
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	if c.App.Name == "" {
-		return fmt.Errorf("app name is required")
+	cfg.Booking.QueueStreamKeepalive = v.GetDuration("QUEUE_STREAM_KEEPALIVE")
+	cfg.Booking.QueueStreamMaxWait = v.GetDuration("QUEUE_STREAM_MAX_WAIT")
+	cfg.Booking.AvailabilityCacheTTL = v.GetDuration("AVAILABILITY_CACHE_TTL")
+	cfg.Booking.MaxConcurrentReservations = v.GetInt64("MAX_CONCURRENT_RESERVATIONS")
+	if cfg.Booking.ZoneOverbookAllowances, err = parseIntMap("ZONE_OVERBOOK_ALLOWANCES", v.GetString("ZONE_OVERBOOK_ALLOWANCES")); err != nil {
+		return err
 	}
-
-	if c.Server.Port <= 0 || c.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", c.Server.Port)
+	if cfg.Booking.EventMaxTicketsPerUser, err = parseIntMap("EVENT_MAX_TICKETS_PER_USER", v.GetString("EVENT_MAX_TICKETS_PER_USER")); err != nil {
+		return err
 	}
-
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("JWT secret is required")
-	}
-
-	// Warn if using default JWT secret in production
-	if c.App.Environment == "production" && c.JWT.Secret == "your-secret-key-change-in-production" {
-		return fmt.Errorf("JWT secret must be changed in production")
+	if cfg.Booking.RoleMaxTicketsPerUser, err = parseIntMap("ROLE_MAX_TICKETS_PER_USER", v.GetString("ROLE_MAX_TICKETS_PER_USER")); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// ValidateAuthDatabase validates auth database configuration
-func (c *Config) ValidateAuthDatabase() error {
-	if c.AuthDatabase.Host == "" {
-		return fmt.Errorf("AUTH_DATABASE_HOST is required")
-	}
-	if c.AuthDatabase.DBName == "" {
-		return fmt.Errorf("AUTH_DATABASE_DBNAME is required")
-	}
-	return nil
-}
-
-// ValidateTicketDatabase validates ticket database configuration
-func (c *Config) ValidateTicketDatabase() error {
-	if c.TicketDatabase.Host == "" {
-		return fmt.Errorf("TICKET_DATABASE_HOST is required")
-	}
-	if c.TicketDatabase.DBName == "" {
-		return fmt.Errorf("TICKET_DATABASE_DBNAME is required")
+// parseIntMap parses the env var key's comma-separated list of id=value
+// pairs, e.g. "zone-a=5,zone-b=2". Values must be non-negative integers. An
+// empty string yields an empty map.
+func parseIntMap(key, s string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, valueStr, ok := strings.Cut(pair, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: want id=value", key, pair)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(valueStr))
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid %s entry %q: value must be a non-negative integer", key, pair)
+		}
+		values[id] = value
 	}
-	return nil
+	return values, nil
 }
 
-// ValidateBookingDatabase validates booking database configuration
-func (c *Config) ValidateBookingDatabase() error {
-	if c.BookingDatabase.Host == "" {
-		return fmt.Errorf("BOOKING_DATABASE_HOST is required")
+// getSecret returns the value of key, or the contents of the file named by
+// key+"_FILE" when that is set (e.g. JWT_SECRET_FILE=/run/secrets/jwt) so
+// secrets don't have to appear in the process environment. The file wins over
+// the plain variable; trailing newlines are trimmed.
+func getSecret(v *viper.Viper, key string) (string, error) {
+	path := v.GetString(key + "_FILE")
+	if path == "" {
+		return v.GetString(key), nil
 	}
-	if c.BookingDatabase.DBName == "" {
-		return fmt.Errorf("BOOKING_DATABASE_DBNAME is required")
-	}
-	return nil
-}
 
-// ValidatePaymentDatabase validates payment database configuration
-func (c *Config) ValidatePaymentDatabase() error {
-	if c.PaymentDatabase.Host == "" {
-		return fmt.Errorf("PAYMENT_DATABASE_HOST is required")
-	}
-	if c.PaymentDatabase.DBName == "" {
-		return fmt.Errorf("PAYMENT_DATABASE_DBNAME is required")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
 	}
-	return nil
+	return strings.TrimRight(string(data), "\r\n"), nil
 }
 
 // IsProduction returns true if running in production environment
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -123,63 +127,72 @@ func TestRedisConfig_Addr(t *testing.T) {
 	}
 }
 
+// validConfig returns a Config that passes Validate
+func validConfig() Config {
+	db := DatabaseConfig{Host: "localhost", Port: 5432, DBName: "app_db"}
+	return Config{
+		App:             AppConfig{Name: "test", Environment: "development"},
+		Server:          ServerConfig{Port: 8080},
+		AuthDatabase:    db,
+		TicketDatabase:  db,
+		BookingDatabase: db,
+		PaymentDatabase: db,
+		Redis:           RedisConfig{Host: "localhost", Port: 6379},
+		JWT:             JWTConfig{Secret: "secret"},
+	}
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
-		cfg     Config
+		modify  func(cfg *Config)
 		wantErr bool
 	}{
 		{
-			name: "valid config",
-			cfg: Config{
-				App:    AppConfig{Name: "test", Environment: "development"},
-				Server: ServerConfig{Port: 8080},
-				JWT:    JWTConfig{Secret: "secret"},
-			},
+			name:    "valid config",
+			modify:  func(cfg *Config) {},
 			wantErr: false,
 		},
 		{
-			name: "missing app name",
-			cfg: Config{
-				App:    AppConfig{Name: "", Environment: "development"},
-				Server: ServerConfig{Port: 8080},
-				JWT:    JWTConfig{Secret: "secret"},
-			},
+			name:    "missing app name",
+			modify:  func(cfg *Config) { cfg.App.Name = "" },
 			wantErr: true,
 		},
 		{
-			name: "invalid port",
-			cfg: Config{
-				App:    AppConfig{Name: "test", Environment: "development"},
-				Server: ServerConfig{Port: -1},
-				JWT:    JWTConfig{Secret: "secret"},
-			},
+			name:    "invalid port",
+			modify:  func(cfg *Config) { cfg.Server.Port = -1 },
 			wantErr: true,
 		},
 		{
-			name: "port too high",
-			cfg: Config{
-				App:    AppConfig{Name: "test", Environment: "development"},
-				Server: ServerConfig{Port: 70000},
-				JWT:    JWTConfig{Secret: "secret"},
-			},
+			name:    "port too high",
+			modify:  func(cfg *Config) { cfg.Server.Port = 70000 },
 			wantErr: true,
 		},
 		{
-			name: "missing JWT secret",
-			cfg: Config{
-				App:    AppConfig{Name: "test", Environment: "development"},
-				Server: ServerConfig{Port: 8080},
-				JWT:    JWTConfig{Secret: ""},
-			},
+			name:    "redis port too high",
+			modify:  func(cfg *Config) { cfg.Redis.Port = 70000 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid database port",
+			modify:  func(cfg *Config) { cfg.PaymentDatabase.Port = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "missing database host",
+			modify:  func(cfg *Config) { cfg.TicketDatabase.Host = "" },
+			wantErr: true,
+		},
+		{
+			name:    "missing JWT secret",
+			modify:  func(cfg *Config) { cfg.JWT.Secret = "" },
 			wantErr: true,
 		},
 		{
 			name: "default JWT secret in production",
-			cfg: Config{
-				App:    AppConfig{Name: "test", Environment: "production"},
-				Server: ServerConfig{Port: 8080},
-				JWT:    JWTConfig{Secret: "your-secret-key-change-in-production"},
+			modify: func(cfg *Config) {
+				cfg.App.Environment = "production"
+				cfg.JWT.Secret = "your-secret-key-change-in-production"
 			},
 			wantErr: true,
 		},
@@ -187,7 +200,9 @@ func TestConfig_Validate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.cfg.Validate()
+			cfg := validConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -204,21 +219,21 @@ func TestConfig_ValidateAuthDatabase(t *testing.T) {
 		{
 			name: "valid auth database",
 			cfg: Config{
-				AuthDatabase: DatabaseConfig{Host: "localhost", DBName: "auth_db"},
+				AuthDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: "auth_db"},
 			},
 			wantErr: false,
 		},
 		{
 			name: "missing auth database host",
 			cfg: Config{
-				AuthDatabase: DatabaseConfig{Host: "", DBName: "auth_db"},
+				AuthDatabase: DatabaseConfig{Host: "", Port: 5432, DBName: "auth_db"},
 			},
 			wantErr: true,
 		},
 		{
 			name: "missing auth database name",
 			cfg: Config{
-				AuthDatabase: DatabaseConfig{Host: "localhost", DBName: ""},
+				AuthDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: ""},
 			},
 			wantErr: true,
 		},
@@ -243,21 +258,21 @@ func TestConfig_ValidateTicketDatabase(t *testing.T) {
 		{
 			name: "valid ticket database",
 			cfg: Config{
-				TicketDatabase: DatabaseConfig{Host: "localhost", DBName: "ticket_db"},
+				TicketDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: "ticket_db"},
 			},
 			wantErr: false,
 		},
 		{
 			name: "missing ticket database host",
 			cfg: Config{
-				TicketDatabase: DatabaseConfig{Host: "", DBName: "ticket_db"},
+				TicketDatabase: DatabaseConfig{Host: "", Port: 5432, DBName: "ticket_db"},
 			},
 			wantErr: true,
 		},
 		{
 			name: "missing ticket database name",
 			cfg: Config{
-				TicketDatabase: DatabaseConfig{Host: "localhost", DBName: ""},
+				TicketDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: ""},
 			},
 			wantErr: true,
 		},
@@ -282,14 +297,14 @@ func TestConfig_ValidateBookingDatabase(t *testing.T) {
 		{
 			name: "valid booking database",
 			cfg: Config{
-				BookingDatabase: DatabaseConfig{Host: "localhost", DBName: "booking_db"},
+				BookingDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: "booking_db"},
 			},
 			wantErr: false,
 		},
 		{
 			name: "missing booking database host",
 			cfg: Config{
-				BookingDatabase: DatabaseConfig{Host: "", DBName: "booking_db"},
+				BookingDatabase: DatabaseConfig{Host: "", Port: 5432, DBName: "booking_db"},
 			},
 			wantErr: true,
 		},
@@ -314,14 +329,14 @@ func TestConfig_ValidatePaymentDatabase(t *testing.T) {
 		{
 			name: "valid payment database",
 			cfg: Config{
-				PaymentDatabase: DatabaseConfig{Host: "localhost", DBName: "payment_db"},
+				PaymentDatabase: DatabaseConfig{Host: "localhost", Port: 5432, DBName: "payment_db"},
 			},
 			wantErr: false,
 		},
 		{
 			name: "missing payment database host",
 			cfg: Config{
-				PaymentDatabase: DatabaseConfig{Host: "", DBName: "payment_db"},
+				PaymentDatabase: DatabaseConfig{Host: "", Port: 5432, DBName: "payment_db"},
 			},
 			wantErr: true,
 		},
@@ -364,3 +379,136 @@ func TestConfig_IsDevelopment(t *testing.T) {
 		t.Error("IsDevelopment() = true, want false")
 	}
 }
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	dir := t.TempDir()
+	jwtFile := filepath.Join(dir, "jwt")
+	if err := os.WriteFile(jwtFile, []byte("jwt-from-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+	dbFile := filepath.Join(dir, "db")
+	if err := os.WriteFile(dbFile, []byte("db-from-file"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", "jwt-from-env")
+	t.Setenv("JWT_SECRET_FILE", jwtFile)
+	t.Setenv("BOOKING_DATABASE_PASSWORD", "db-from-env")
+	t.Setenv("BOOKING_DATABASE_PASSWORD_FILE", dbFile)
+	t.Setenv("AUTH_DATABASE_PASSWORD", "auth-from-env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.JWT.Secret != "jwt-from-file" {
+		t.Errorf("JWT.Secret = %q, want %q", cfg.JWT.Secret, "jwt-from-file")
+	}
+	if cfg.BookingDatabase.Password != "db-from-file" {
+		t.Errorf("BookingDatabase.Password = %q, want %q", cfg.BookingDatabase.Password, "db-from-file")
+	}
+	if cfg.AuthDatabase.Password != "auth-from-env" {
+		t.Errorf("AuthDatabase.Password = %q, want %q", cfg.AuthDatabase.Password, "auth-from-env")
+	}
+}
+
+func TestLoad_SecretFileMissing(t *testing.T) {
+	t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail when a secret file cannot be read")
+	}
+}
+
+func TestLoad_ZoneOverbookAllowances(t *testing.T) {
+	t.Setenv("ZONE_OVERBOOK_ALLOWANCES", "zone-a=5, zone-b=0 ,")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	want := map[string]int{"zone-a": 5, "zone-b": 0}
+	if !reflect.DeepEqual(cfg.Booking.ZoneOverbookAllowances, want) {
+		t.Errorf("Booking.ZoneOverbookAllowances = %v, want %v", cfg.Booking.ZoneOverbookAllowances, want)
+	}
+}
+
+func TestLoad_ZoneOverbookAllowancesInvalid(t *testing.T) {
+	for _, value := range []string{"zone-a", "=5", "zone-a=many", "zone-a=-1"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("ZONE_OVERBOOK_ALLOWANCES", value)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail for ZONE_OVERBOOK_ALLOWANCES=%q", value)
+			}
+		})
+	}
+}
+
+func TestLoad_MaxTicketsPerUserOverrides(t *testing.T) {
+	t.Setenv("EVENT_MAX_TICKETS_PER_USER", "event-resale=2")
+	t.Setenv("ROLE_MAX_TICKETS_PER_USER", "vip=8, staff=0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if want := map[string]int{"event-resale": 2}; !reflect.DeepEqual(cfg.Booking.EventMaxTicketsPerUser, want) {
+		t.Errorf("Booking.EventMaxTicketsPerUser = %v, want %v", cfg.Booking.EventMaxTicketsPerUser, want)
+	}
+	if want := map[string]int{"vip": 8, "staff": 0}; !reflect.DeepEqual(cfg.Booking.RoleMaxTicketsPerUser, want) {
+		t.Errorf("Booking.RoleMaxTicketsPerUser = %v, want %v", cfg.Booking.RoleMaxTicketsPerUser, want)
+	}
+
+	t.Setenv("ROLE_MAX_TICKETS_PER_USER", "vip")
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail for ROLE_MAX_TICKETS_PER_USER=vip")
+	}
+}
+
+func TestConfig_ValidateReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.App = AppConfig{Name: "", Environment: "production"}
+	cfg.Server.Port = 0
+	cfg.JWT.Secret = defaultJWTSecret
+	cfg.BookingDatabase = DatabaseConfig{Host: "", Port: 99999, DBName: "booking_db"}
+	cfg.Redis.Port = -1
+
+	err := cfg.Validate()
+	var verr *ConfigValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want *ConfigValidationError", err)
+	}
+
+	wantFields := []string{"APP_NAME", "SERVER_PORT", "REDIS_PORT", "JWT_SECRET", "BOOKING_DATABASE_HOST", "BOOKING_DATABASE_PORT"}
+	if len(verr.Errors) != len(wantFields) {
+		t.Fatalf("got %d problems, want %d: %v", len(verr.Errors), len(wantFields), err)
+	}
+	for i, field := range wantFields {
+		if verr.Errors[i].Field != field {
+			t.Errorf("Errors[%d].Field = %q, want %q", i, verr.Errors[i].Field, field)
+		}
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error message %q does not mention %s", err.Error(), field)
+		}
+	}
+}
+
+func TestConfig_ValidateDatabaseReportsAllProblems(t *testing.T) {
+	cfg := Config{}
+
+	err := cfg.ValidateBookingDatabase()
+	var verr *ConfigValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateBookingDatabase() error = %v, want *ConfigValidationError", err)
+	}
+	if len(verr.Errors) != 3 {
+		t.Fatalf("got %d problems, want 3: %v", len(verr.Errors), err)
+	}
+	if verr.Errors[0].Field != "BOOKING_DATABASE_HOST" || verr.Errors[1].Field != "BOOKING_DATABASE_PORT" || verr.Errors[2].Field != "BOOKING_DATABASE_DBNAME" {
+		t.Errorf("unexpected fields: %v", err)
+	}
+}
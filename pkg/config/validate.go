@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultJWTSecret is the placeholder secret from setDefaults that must never reach production
+const defaultJWTSecret = "your-secret-key-change-in-production"
+
+// FieldError describes a single invalid configuration field
+type FieldError struct {
+	Field   string // Environment variable name, e.g. SERVER_PORT
+	Message string
+}
+
+// Error implements error
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ConfigValidationError lists every problem found while validating a Config, so
+// all misconfigurations can be fixed in one go instead of one per boot
+type ConfigValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements error
+func (e *ConfigValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add records a problem with field
+func (e *ConfigValidationError) add(field, format string, args ...any) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// port records a problem with field unless port is a valid TCP port
+func (e *ConfigValidationError) port(field string, port int) {
+	if port <= 0 || port > 65535 {
+		e.add(field, "must be between 1 and 65535, got %d", port)
+	}
+}
+
+// database records every problem with the database whose env vars start with prefix
+func (e *ConfigValidationError) database(prefix string, db DatabaseConfig) {
+	if db.Host == "" {
+		e.add(prefix+"_HOST", "is required")
+	}
+	e.port(prefix+"_PORT", db.Port)
+	if db.DBName == "" {
+		e.add(prefix+"_DBNAME", "is required")
+	}
+}
+
+// err returns e as an error, or nil if no problems were recorded
+func (e *ConfigValidationError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate validates the configuration, returning a *ConfigValidationError listing every problem
+func (c *Config) Validate() error {
+	errs := &ConfigValidationError{}
+
+	if c.App.Name == "" {
+		errs.add("APP_NAME", "is required")
+	}
+
+	errs.port("SERVER_PORT", c.Server.Port)
+	errs.port("REDIS_PORT", c.Redis.Port)
+
+	if c.JWT.Secret == "" {
+		errs.add("JWT_SECRET", "is required")
+	} else if c.App.Environment == "production" && c.JWT.Secret == defaultJWTSecret {
+		errs.add("JWT_SECRET", "must be changed from the default in production")
+	}
+
+	// Every service database has a default, so a bad value is a misconfiguration
+	// even in services that never connect to it
+	errs.database("AUTH_DATABASE", c.AuthDatabase)
+	errs.database("TICKET_DATABASE", c.TicketDatabase)
+	errs.database("BOOKING_DATABASE", c.BookingDatabase)
+	errs.database("PAYMENT_DATABASE", c.PaymentDatabase)
+
+	return errs.err()
+}
+
+// ValidateAuthDatabase validates auth database configuration
+func (c *Config) ValidateAuthDatabase() error {
+	return validateDatabase("AUTH_DATABASE", c.AuthDatabase)
+}
+
+// ValidateTicketDatabase validates ticket database configuration
+func (c *Config) ValidateTicketDatabase() error {
+	return validateDatabase("TICKET_DATABASE", c.TicketDatabase)
+}
+
+// ValidateBookingDatabase validates booking database configuration
+func (c *Config) ValidateBookingDatabase() error {
+	return validateDatabase("BOOKING_DATABASE", c.BookingDatabase)
+}
+
+// ValidatePaymentDatabase validates payment database configuration
+func (c *Config) ValidatePaymentDatabase() error {
+	return validateDatabase("PAYMENT_DATABASE", c.PaymentDatabase)
+}
+
+// validateDatabase checks the settings of the database whose env vars start with prefix
+func validateDatabase(prefix string, db DatabaseConfig) error {
+	errs := &ConfigValidationError{}
+	errs.database(prefix, db)
+	return errs.err()
+}
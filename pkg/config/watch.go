@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Watch reloads configuration from the .env file whenever it changes or the
+// process receives SIGHUP, calling onChange with the new Config. A reload that
+// fails to read or validate is discarded and reported to onError (which may be
+// nil), so callers keep running with their last good config.
+//
+// Only the following fields are safe to hot-reload; everything else (server
+// address and timeouts, database/Redis/Kafka/MongoDB connections, JWT secret,
+// service URLs, OTel exporter settings) is wired up at startup and needs a
+// restart to take effect:
+//   - App.Debug
+//   - OTel.SampleRatio
+//   - Booking.MaxTicketsPerUser, Booking.ReservationTTLMinutes, Booking.RequireQueuePass
+//
+// Watching stops and the file watcher is closed when ctx is cancelled, so
+// services should pass a ctx they cancel on shutdown.
+func Watch(ctx context.Context, onChange func(*Config), onError func(error)) error {
+	return WatchPath(ctx, ".env", onChange, onError)
+}
+
+// WatchPath is like Watch but watches the env file at path
+func WatchPath(ctx context.Context, path string, onChange func(*Config), onError func(error)) error {
+	if err := newWatchViper(path).ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	// Watch the directory: editors often replace the file instead of writing to it
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+	reload := func() {
+		v := newWatchViper(path)
+		if err := v.ReadInConfig(); err != nil {
+			report(fmt.Errorf("failed to read config file: %w", err))
+			return
+		}
+		cfg := &Config{}
+		if err := bindConfig(v, cfg); err != nil {
+			report(fmt.Errorf("failed to bind config: %w", err))
+			return
+		}
+		if err := cfg.Validate(); err != nil {
+			report(fmt.Errorf("config validation failed: %w", err))
+			return
+		}
+		if ctx.Err() == nil {
+			onChange(cfg)
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	// File events and SIGHUP are handled on one goroutine, so callbacks never overlap
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hup)
+
+		target := filepath.Clean(path)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == target && event.Has(fsnotify.Write|fsnotify.Create) {
+					reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				report(fmt.Errorf("config watcher error: %w", err))
+			case <-hup:
+				reload()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// newWatchViper creates a viper instance configured the same way as LoadWithPath
+func newWatchViper(path string) *viper.Viper {
+	v := viper.New()
+
+	v.SetConfigFile(path)
+	v.SetConfigType("env")
+
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	setDefaults(v)
+
+	return v
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchPath_ReloadsOnFileChange(t *testing.T) {
+	os.Unsetenv("MAX_TICKETS_PER_USER")
+	os.Unsetenv("SERVER_PORT")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("MAX_TICKETS_PER_USER=4\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan *Config, 16)
+	reloadErrs := make(chan error, 16)
+	if err := WatchPath(ctx, path, func(cfg *Config) { changes <- cfg }, func(err error) { reloadErrs <- err }); err != nil {
+		t.Fatalf("WatchPath() failed: %v", err)
+	}
+
+	// An invalid config must not reach the callback
+	if err := os.WriteFile(path, []byte("MAX_TICKETS_PER_USER=6\nSERVER_PORT=0\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	select {
+	case err := <-reloadErrs:
+		var verr *ConfigValidationError
+		if !errors.As(err, &verr) || !strings.Contains(err.Error(), "SERVER_PORT") {
+			t.Errorf("expected the SERVER_PORT validation error to be reported, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the invalid reload to be reported")
+	}
+
+	if err := os.WriteFile(path, []byte("MAX_TICKETS_PER_USER=8\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Editors and WriteFile may emit several events; wait for the final value
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case cfg := <-changes:
+			if cfg.Server.Port == 0 || cfg.Booking.MaxTicketsPerUser == 6 {
+				t.Fatalf("callback received invalid config: %+v", cfg.Server)
+			}
+			if cfg.Booking.MaxTicketsPerUser == 8 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for config reload")
+		}
+	}
+}
+
+func TestWatchPath_MissingFile(t *testing.T) {
+	err := WatchPath(context.Background(), filepath.Join(t.TempDir(), ".env"), func(*Config) {}, nil)
+	if err == nil {
+		t.Error("WatchPath() should fail when the config file does not exist")
+	}
+}
+
+func TestWatchPath_StopsWhenContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("MAX_TICKETS_PER_USER=4\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan *Config, 16)
+	if err := WatchPath(ctx, path, func(cfg *Config) { changes <- cfg }, nil); err != nil {
+		t.Fatalf("WatchPath() failed: %v", err)
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("MAX_TICKETS_PER_USER=8\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	select {
+	case cfg := <-changes:
+		t.Errorf("callback fired after cancellation: MaxTicketsPerUser=%d", cfg.Booking.MaxTicketsPerUser)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
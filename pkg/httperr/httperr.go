@@ -0,0 +1,47 @@
+// Package httperr writes the error envelope shared by every service:
+//
+//	{
+//	  "success": false,
+//	  "error": {
+//	    "code":    "QUEUE_FULL",
+//	    "message": "queue is full",
+//	    "details": {"field": "reason"}
+//	  }
+//	}
+//
+// code is a stable SCREAMING_SNAKE_CASE identifier clients branch on, message
+// is human-readable and may change, and details is omitted when empty. The
+// envelope is response.Response with Error set, so success and error responses
+// share one shape.
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+)
+
+// Write responds with the error envelope
+func Write(c *gin.Context, status int, code, message string) {
+	c.JSON(status, response.Error(code, message))
+}
+
+// WriteWithDetails responds with the error envelope including per-field details
+func WriteWithDetails(c *gin.Context, status int, code, message string, details map[string]string) {
+	c.JSON(status, response.ErrorWithDetails(code, message, details))
+}
+
+// Abort responds with the error envelope and stops the handler chain
+func Abort(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, response.Error(code, message))
+}
+
+// WriteHTTP responds with the error envelope outside gin, e.g. from an
+// httputil.ReverseProxy error handler
+func WriteHTTP(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response.Error(code, message))
+}
@@ -0,0 +1,79 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	return c, w
+}
+
+func TestWrite(t *testing.T) {
+	c, w := newTestContext()
+
+	Write(c, http.StatusConflict, "QUEUE_FULL", "queue is full")
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+	want := `{"success":false,"error":{"code":"QUEUE_FULL","message":"queue is full"}}`
+	if got := w.Body.String(); got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Expected JSON content type, got %s", ct)
+	}
+}
+
+func TestWriteWithDetails(t *testing.T) {
+	c, w := newTestContext()
+
+	WriteWithDetails(c, http.StatusBadRequest, "VALIDATION_FAILED", "Validation failed",
+		map[string]string{"quantity": "must be positive"})
+
+	want := `{"success":false,"error":{"code":"VALIDATION_FAILED","message":"Validation failed","details":{"quantity":"must be positive"}}}`
+	if got := w.Body.String(); got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+}
+
+func TestAbort(t *testing.T) {
+	c, w := newTestContext()
+
+	Abort(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+
+	if !c.IsAborted() {
+		t.Error("Expected the handler chain to be aborted")
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+	want := `{"success":false,"error":{"code":"UNAUTHORIZED","message":"User not authenticated"}}`
+	if got := w.Body.String(); got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteHTTP(w, http.StatusBadGateway, "BAD_GATEWAY", "Backend service unavailable")
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d", w.Code)
+	}
+	want := `{"success":false,"error":{"code":"BAD_GATEWAY","message":"Backend service unavailable"}}` + "\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", ct)
+	}
+}
@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Standard shutdown priorities. Closers run in ascending priority order, so
+// requests stop before buffers are flushed and pools close last.
+const (
+	// PriorityServer stops accepting new requests and waits for in-flight ones
+	PriorityServer = 0
+	// PriorityWorkers stops background workers and consumers
+	PriorityWorkers = 100
+	// PriorityFlush flushes buffered output (audit logger, OTLP exporters, logs)
+	PriorityFlush = 200
+	// PriorityConnections closes Redis and database pools
+	PriorityConnections = 300
+)
+
+// Closer releases a component. It should return once ctx is done.
+type Closer func(ctx context.Context) error
+
+type registeredCloser struct {
+	name     string
+	priority int
+	closer   Closer
+}
+
+// ShutdownManager runs registered closers in priority order within a deadline
+type ShutdownManager struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	closers []registeredCloser
+	done    bool
+}
+
+// NewShutdownManager creates a shutdown manager whose Shutdown gives all
+// closers together at most timeout to finish (default: 30 seconds)
+func NewShutdownManager(timeout time.Duration) *ShutdownManager {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &ShutdownManager{timeout: timeout}
+}
+
+// Register adds a closer. Closers with the same priority run in registration order.
+func (m *ShutdownManager) Register(name string, priority int, closer Closer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, registeredCloser{name: name, priority: priority, closer: closer})
+}
+
+// RegisterFunc adds a closer that takes no context, such as a pool's Close method
+func (m *ShutdownManager) RegisterFunc(name string, priority int, fn func() error) {
+	m.Register(name, priority, func(context.Context) error { return fn() })
+}
+
+// Shutdown runs every registered closer in ascending priority order. A closer
+// that fails does not stop the sequence. Once the deadline passes, the running
+// closer is abandoned and the remaining ones are skipped. It returns the
+// joined errors of all failed or skipped closers. Only the first call runs the
+// closers; later calls return nil.
+func (m *ShutdownManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	closers := make([]registeredCloser, len(m.closers))
+	copy(closers, m.closers)
+	m.mu.Unlock()
+
+	sort.SliceStable(closers, func(i, j int) bool {
+		return closers[i].priority < closers[j].priority
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	var errs []error
+	for _, c := range closers {
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("%s: skipped: %w", c.name, ctx.Err()))
+			continue
+		}
+		if err := runCloser(ctx, c.closer); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCloser runs closer and returns its error, or ctx's error if the deadline
+// passes first
+func runCloser(ctx context.Context, closer Closer) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- closer(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
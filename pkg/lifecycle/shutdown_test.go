@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the order closers ran in
+type recorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *recorder) closer(name string) Closer {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, name)
+		return nil
+	}
+}
+
+func (r *recorder) ran() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.order...)
+}
+
+func TestShutdownManager_RunsInPriorityOrder(t *testing.T) {
+	rec := &recorder{}
+	m := NewShutdownManager(time.Second)
+
+	// Registered out of order on purpose
+	m.Register("db", PriorityConnections, rec.closer("db"))
+	m.Register("audit", PriorityFlush, rec.closer("audit"))
+	m.Register("http", PriorityServer, rec.closer("http"))
+	m.Register("redis", PriorityConnections, rec.closer("redis"))
+	m.Register("otlp", PriorityFlush, rec.closer("otlp"))
+	m.Register("worker", PriorityWorkers, rec.closer("worker"))
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.Equal(t, []string{"http", "worker", "audit", "otlp", "db", "redis"}, rec.ran())
+
+	// Shutdown only runs once
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.Len(t, rec.ran(), 6)
+}
+
+func TestShutdownManager_ContinuesAfterError(t *testing.T) {
+	rec := &recorder{}
+	m := NewShutdownManager(time.Second)
+
+	m.RegisterFunc("audit", PriorityFlush, func() error { return errors.New("flush failed") })
+	m.Register("redis", PriorityConnections, rec.closer("redis"))
+
+	err := m.Shutdown(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit: flush failed")
+	assert.Equal(t, []string{"redis"}, rec.ran())
+}
+
+func TestShutdownManager_RespectsTimeout(t *testing.T) {
+	rec := &recorder{}
+	m := NewShutdownManager(50 * time.Millisecond)
+
+	m.Register("http", PriorityServer, rec.closer("http"))
+	// Ignores ctx and would block well past the deadline
+	m.Register("stuck", PriorityWorkers, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	m.Register("redis", PriorityConnections, rec.closer("redis"))
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "stuck")
+	assert.Contains(t, err.Error(), "redis: skipped")
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	assert.Equal(t, []string{"http"}, rec.ran())
+}
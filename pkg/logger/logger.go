@@ -2,6 +2,9 @@ package logger
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"os"
 	"sync"
 	"time"
@@ -29,6 +32,7 @@ const (
 type Logger struct {
 	*zap.Logger
 	serviceName string
+	level       zap.AtomicLevel // shared by every core and derived logger
 }
 
 var (
@@ -45,25 +49,37 @@ type Config struct {
 	// OTLP configuration for exporting logs to OTel Collector
 	OTLPEnabled   bool
 	OTLPEndpoint  string        // e.g., "otel-collector:4317"
+	OTLPProtocol  string        // "http" (default, JSON to :4318/v1/logs) or "grpc"
 	OTLPInsecure  bool          // Use insecure connection (no TLS)
 	OTLPTimeout   time.Duration // Timeout for OTLP export
 	BatchSize     int           // Batch size for log export
 	BatchInterval time.Duration // Interval for batch export
+	// OTLPSampleRate is the fraction (0-1] of debug/info logs exported via OTLP;
+	// warn and above are always exported. 0 exports everything.
+	OTLPSampleRate float64
+	// OTLPMaxRetries is how many times a failed export is retried (default: 3, negative disables)
+	OTLPMaxRetries int
+	// MaxBufferSize caps records held while the collector is unreachable (default: 10x BatchSize)
+	MaxBufferSize int
 }
 
 // DefaultConfig returns default logger configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Level:         "info",
-		ServiceName:   "booking-rush",
-		Development:   false,
-		OutputPath:    "stdout",
-		OTLPEnabled:   false,
-		OTLPEndpoint:  "localhost:4317",
-		OTLPInsecure:  true,
-		OTLPTimeout:   5 * time.Second,
-		BatchSize:     100,
-		BatchInterval: 1 * time.Second,
+		Level:          "info",
+		ServiceName:    "booking-rush",
+		Development:    false,
+		OutputPath:     "stdout",
+		OTLPEnabled:    false,
+		OTLPEndpoint:   "localhost:4317",
+		OTLPProtocol:   OTLPProtocolHTTP,
+		OTLPInsecure:   true,
+		OTLPTimeout:    5 * time.Second,
+		BatchSize:      100,
+		BatchInterval:  1 * time.Second,
+		OTLPSampleRate: 1.0,
+		OTLPMaxRetries: 3,
+		MaxBufferSize:  1000,
 	}
 }
 
@@ -89,7 +105,7 @@ func New(cfg *Config) (*Logger, error) {
 		cfg = DefaultConfig()
 	}
 
-	level := parseLevel(cfg.Level)
+	level := zap.NewAtomicLevelAt(parseLevel(cfg.Level))
 
 	// Configure encoder for JSON output (structured logging)
 	encoderConfig := zapcore.EncoderConfig{
@@ -159,6 +175,7 @@ func New(cfg *Config) (*Logger, error) {
 	return &Logger{
 		Logger:      zapLogger,
 		serviceName: cfg.ServiceName,
+		level:       level,
 	}, nil
 }
 
@@ -219,6 +236,7 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	return &Logger{
 		Logger:      l.Logger.With(fields...),
 		serviceName: l.serviceName,
+		level:       l.level,
 	}
 }
 
@@ -227,6 +245,7 @@ func (l *Logger) WithFields(fields ...zap.Field) *Logger {
 	return &Logger{
 		Logger:      l.Logger.With(fields...),
 		serviceName: l.serviceName,
+		level:       l.level,
 	}
 }
 
@@ -235,6 +254,7 @@ func (l *Logger) WithService(serviceName string) *Logger {
 	return &Logger{
 		Logger:      l.Logger.With(zap.String("service", serviceName)),
 		serviceName: serviceName,
+		level:       l.level,
 	}
 }
 
@@ -263,6 +283,55 @@ func (l *Logger) FatalContext(ctx context.Context, msg string, fields ...zap.Fie
 	l.WithContext(ctx).Fatal(msg, fields...)
 }
 
+// SetLevel changes the minimum enabled level (debug, info, warn, error) at runtime
+func (l *Logger) SetLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+		l.level.SetLevel(parseLevel(level))
+		return nil
+	default:
+		return fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// Level returns the current minimum enabled level
+func (l *Logger) Level() string {
+	return l.level.Level().String()
+}
+
+// LevelHandler returns an admin HTTP handler reporting the level on GET and
+// changing it on PUT with a JSON body like {"level":"debug"}. Only the levels
+// SetLevel accepts can be set, never panic or fatal. It does no
+// authentication of its own; mount it behind admin auth.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+				return
+			}
+			if err := l.SetLevel(req.Level); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{"error": "method not allowed"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"level": l.Level()})
+	})
+}
+
 // Sync flushes any buffered log entries
 func (l *Logger) Sync() error {
 	return l.Logger.Sync()
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -36,14 +39,16 @@ func newTestLogger(level zapcore.Level) (*Logger, *testBuffer) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
+	atomicLevel := zap.NewAtomicLevelAt(level)
 	encoder := zapcore.NewJSONEncoder(encoderConfig)
-	core := zapcore.NewCore(encoder, buf, level)
+	core := zapcore.NewCore(encoder, buf, atomicLevel)
 
 	zapLogger := zap.New(core).With(zap.String("service", "test-service"))
 
 	return &Logger{
 		Logger:      zapLogger,
 		serviceName: "test-service",
+		level:       atomicLevel,
 	}, buf
 }
 
@@ -257,3 +262,61 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected default output path 'stdout', got '%s'", cfg.OutputPath)
 	}
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	logger, buf := newTestLogger(zapcore.InfoLevel)
+	derived := logger.WithFields(zap.String("component", "test"))
+
+	derived.Debug("suppressed")
+	if buf.Len() != 0 {
+		t.Fatalf("Expected debug log to be suppressed, got %s", buf.String())
+	}
+
+	if err := logger.SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() failed: %v", err)
+	}
+	if logger.Level() != "debug" {
+		t.Errorf("Expected level 'debug', got '%s'", logger.Level())
+	}
+
+	derived.Debug("now visible")
+	if !strings.Contains(buf.String(), "now visible") {
+		t.Errorf("Expected debug log after SetLevel, got %q", buf.String())
+	}
+
+	if err := logger.SetLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestLogger_LevelHandler(t *testing.T) {
+	logger, _ := newTestLogger(zapcore.InfoLevel)
+	handler := logger.LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"warn"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if logger.Level() != "warn" {
+		t.Errorf("Expected level 'warn', got '%s'", logger.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+	if !strings.Contains(rec.Body.String(), `"level":"warn"`) {
+		t.Errorf("Expected GET to report warn level, got %s", rec.Body.String())
+	}
+
+	// Levels that would crash or silence the service are rejected
+	for _, level := range []string{"panic", "fatal", "dpanic"} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"`+level+`"}`)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for level %s, got %d", level, rec.Code)
+		}
+	}
+	if logger.Level() != "warn" {
+		t.Errorf("Expected level to stay 'warn', got '%s'", logger.Level())
+	}
+}
@@ -1,12 +1,12 @@
 package logger
 
 import (
-	"bytes"
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap/zapcore"
@@ -15,15 +15,26 @@ import (
 // OTLPCore implements zapcore.Core for sending logs to OTel Collector
 type OTLPCore struct {
 	zapcore.LevelEnabler
-	endpoint      string
-	serviceName   string
-	client        *http.Client
-	buffer        []LogRecord
-	bufferMu      sync.Mutex
-	batchSize     int
-	batchInterval time.Duration
-	stopChan      chan struct{}
-	wg            sync.WaitGroup
+	exporter        logExporter
+	serviceName     string
+	buffer          []LogRecord
+	bufferMu        sync.Mutex
+	batchSize       int
+	batchInterval   time.Duration
+	sampleRate      float64 // fraction of below-warn records exported
+	sampled         atomic.Uint64
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxBufferSize   int
+	flushMu         sync.Mutex  // serializes exports; never held across retry sleeps
+	flushing        atomic.Bool // set while a background flush is in flight
+	flushFailures   int         // consecutive failed flushes, guarded by flushMu
+	nextFlushAt     atomic.Int64
+	flushBackoff    time.Duration
+	flushBackoffMax time.Duration
+	dropped         atomic.Uint64
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
 }
 
 // LogRecord represents a log entry in OTLP format
@@ -72,21 +83,25 @@ type Scope struct {
 	Version string `json:"version"`
 }
 
+// defaultOTLPRetryBackoff is the base delay between export retries; it grows linearly per attempt
+const defaultOTLPRetryBackoff = 200 * time.Millisecond
+
+// Bounds of the exponential backoff between background flushes after a flush
+// fails, so an unreachable collector isn't hit on every tick or full batch
+const (
+	defaultOTLPFlushBackoff    = 1 * time.Second
+	defaultOTLPFlushBackoffMax = 30 * time.Second
+)
+
+// errOTLPRejected marks an export the collector refused outright; retrying won't help
+var errOTLPRejected = errors.New("OTLP export rejected")
+
 // NewOTLPCore creates a new OTLP core for sending logs to OTel Collector
 func NewOTLPCore(cfg *Config, level zapcore.LevelEnabler) *OTLPCore {
 	if cfg == nil || cfg.OTLPEndpoint == "" {
 		return nil
 	}
 
-	// Construct HTTP endpoint from gRPC endpoint
-	// OTel Collector typically exposes HTTP on :4318
-	httpEndpoint := fmt.Sprintf("http://%s/v1/logs", cfg.OTLPEndpoint)
-	// If endpoint already contains port 4317 (gRPC), change to 4318 (HTTP)
-	if cfg.OTLPEndpoint == "localhost:4317" || cfg.OTLPEndpoint == "otel-collector:4317" {
-		httpEndpoint = fmt.Sprintf("http://%s/v1/logs",
-			cfg.OTLPEndpoint[:len(cfg.OTLPEndpoint)-4]+"4318")
-	}
-
 	batchSize := cfg.BatchSize
 	if batchSize <= 0 {
 		batchSize = 100
@@ -102,15 +117,43 @@ func NewOTLPCore(cfg *Config, level zapcore.LevelEnabler) *OTLPCore {
 		timeout = 5 * time.Second
 	}
 
+	exporter, err := newLogExporter(cfg, timeout)
+	if err != nil {
+		fmt.Printf("logger: failed to create OTLP exporter: %v\n", err)
+		return nil
+	}
+
+	sampleRate := cfg.OTLPSampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+
+	maxRetries := cfg.OTLPMaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	maxBufferSize := cfg.MaxBufferSize
+	if maxBufferSize <= 0 {
+		maxBufferSize = 10 * batchSize
+	}
+
 	core := &OTLPCore{
-		LevelEnabler:  level,
-		endpoint:      httpEndpoint,
-		serviceName:   cfg.ServiceName,
-		client:        &http.Client{Timeout: timeout},
-		buffer:        make([]LogRecord, 0, batchSize),
-		batchSize:     batchSize,
-		batchInterval: batchInterval,
-		stopChan:      make(chan struct{}),
+		LevelEnabler:    level,
+		exporter:        exporter,
+		serviceName:     cfg.ServiceName,
+		buffer:          make([]LogRecord, 0, batchSize),
+		batchSize:       batchSize,
+		batchInterval:   batchInterval,
+		sampleRate:      sampleRate,
+		maxRetries:      maxRetries,
+		retryBackoff:    defaultOTLPRetryBackoff,
+		flushBackoff:    defaultOTLPFlushBackoff,
+		flushBackoffMax: defaultOTLPFlushBackoffMax,
+		maxBufferSize:   maxBufferSize,
+		stopChan:        make(chan struct{}),
 	}
 
 	// Start background flush goroutine
@@ -136,6 +179,10 @@ func (c *OTLPCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.C
 
 // Write serializes the Entry and any Fields to the OTLP buffer
 func (c *OTLPCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.sample(ent.Level) {
+		return nil
+	}
+
 	record := LogRecord{
 		Timestamp:         ent.Time.UnixNano(),
 		ObservedTimestamp: time.Now().UnixNano(),
@@ -182,20 +229,39 @@ func (c *OTLPCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
 
 	record.Attributes = attrs
 
-	// Add to buffer
+	// Add to buffer, dropping the record if the collector has fallen too far behind
 	c.bufferMu.Lock()
+	if len(c.buffer) >= c.maxBufferSize {
+		c.bufferMu.Unlock()
+		c.dropped.Add(1)
+		return nil
+	}
 	c.buffer = append(c.buffer, record)
 	shouldFlush := len(c.buffer) >= c.batchSize
 	c.bufferMu.Unlock()
 
-	if shouldFlush {
-		go c.flush()
+	// At most one background flush in flight, so a slow collector can't pile up goroutines
+	if shouldFlush && c.startFlush() {
+		go func() {
+			defer c.flushing.Store(false)
+			c.flush()
+		}()
 	}
 
 	return nil
 }
 
-// Sync flushes buffered logs
+// sample decides whether a record at level is exported. Warn and above always
+// pass; lower levels pass at sampleRate, spread evenly across records.
+func (c *OTLPCore) sample(level zapcore.Level) bool {
+	if level >= zapcore.WarnLevel || c.sampleRate >= 1 {
+		return true
+	}
+	n := c.sampled.Add(1)
+	return math.Floor(float64(n)*c.sampleRate) > math.Floor(float64(n-1)*c.sampleRate)
+}
+
+// Sync flushes buffered logs, ignoring any backoff from earlier failures
 func (c *OTLPCore) Sync() error {
 	c.flush()
 	return nil
@@ -206,7 +272,7 @@ func (c *OTLPCore) Close() error {
 	close(c.stopChan)
 	c.wg.Wait()
 	c.flush()
-	return nil
+	return c.exporter.close()
 }
 
 // flushLoop periodically flushes the buffer
@@ -218,14 +284,35 @@ func (c *OTLPCore) flushLoop() {
 	for {
 		select {
 		case <-ticker.C:
-			c.flush()
+			if c.startFlush() {
+				c.flush()
+				c.flushing.Store(false)
+			}
 		case <-c.stopChan:
 			return
 		}
 	}
 }
 
-// flush sends buffered logs to OTel Collector
+// startFlush claims the single background flush slot. It returns false if a
+// flush is already in flight or failed flushes are still backing off.
+func (c *OTLPCore) startFlush() bool {
+	if time.Now().UnixNano() < c.nextFlushAt.Load() {
+		return false
+	}
+	return c.flushing.CompareAndSwap(false, true)
+}
+
+// DroppedLogs returns how many records were discarded because the collector
+// could not keep up or stayed unreachable past the retry budget
+func (c *OTLPCore) DroppedLogs() uint64 {
+	return c.dropped.Load()
+}
+
+// flush sends buffered logs to OTel Collector. A batch that still fails after
+// retries is re-queued ahead of newer records, up to maxBufferSize.
+// flushMu is held per export attempt only, so a Sync or Close racing a failing
+// flush waits for at most one export, not the whole retry schedule.
 func (c *OTLPCore) flush() {
 	c.bufferMu.Lock()
 	if len(c.buffer) == 0 {
@@ -263,34 +350,72 @@ func (c *OTLPCore) flush() {
 	}
 
 	// Send to OTel Collector
-	data, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("logger: failed to marshal OTLP payload: %v\n", err)
-		return
+	var err error
+	for attempt := 0; ; attempt++ {
+		c.flushMu.Lock()
+		err = c.exporter.export(payload)
+		if err == nil {
+			c.resetFlushBackoff()
+		}
+		c.flushMu.Unlock()
+		if err == nil {
+			return
+		}
+		if errors.Is(err, errOTLPRejected) || attempt >= c.maxRetries {
+			break
+		}
+		time.Sleep(time.Duration(attempt+1) * c.retryBackoff)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(data))
-	if err != nil {
-		fmt.Printf("logger: failed to create OTLP request: %v\n", err)
+	if errors.Is(err, errOTLPRejected) {
+		// Log error but don't block
+		fmt.Printf("logger: %v\n", err)
+		c.dropped.Add(uint64(len(records)))
+		c.flushMu.Lock()
+		c.resetFlushBackoff()
+		c.flushMu.Unlock()
 		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	c.requeue(records)
+	c.flushMu.Lock()
+	c.backOffFlushes()
+	c.flushMu.Unlock()
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		// Silently fail - don't block the application
-		return
+// backOffFlushes delays the next background flush, doubling the delay with
+// each consecutive failure up to flushBackoffMax. Called with flushMu held.
+func (c *OTLPCore) backOffFlushes() {
+	c.flushFailures++
+	delay := c.flushBackoff
+	for i := 1; i < c.flushFailures && delay < c.flushBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > c.flushBackoffMax {
+		delay = c.flushBackoffMax
 	}
-	defer resp.Body.Close()
+	c.nextFlushAt.Store(time.Now().Add(delay).UnixNano())
+}
 
-	if resp.StatusCode >= 400 {
-		// Log error but don't block
-		fmt.Printf("logger: OTLP export failed with status %d\n", resp.StatusCode)
+// resetFlushBackoff clears the backoff once the collector accepts a flush.
+// Called with flushMu held.
+func (c *OTLPCore) resetFlushBackoff() {
+	c.flushFailures = 0
+	c.nextFlushAt.Store(0)
+}
+
+// requeue puts records that failed to export back in front of the buffer,
+// dropping the oldest once maxBufferSize is exceeded
+func (c *OTLPCore) requeue(records []LogRecord) {
+	c.bufferMu.Lock()
+	defer c.bufferMu.Unlock()
+
+	merged := append(records, c.buffer...)
+	if overflow := len(merged) - c.maxBufferSize; overflow > 0 {
+		c.dropped.Add(uint64(overflow))
+		merged = merged[overflow:]
 	}
+	c.buffer = merged
 }
 
 // zapLevelToOTLP converts zap log level to OTLP severity number
@@ -322,8 +447,11 @@ func fieldToKeyValue(f zapcore.Field) KeyValue {
 		return KeyValue{Key: f.Key, Value: map[string]int64{"intValue": f.Integer}}
 	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
 		return KeyValue{Key: f.Key, Value: map[string]uint64{"intValue": uint64(f.Integer)}}
-	case zapcore.Float64Type, zapcore.Float32Type:
-		return KeyValue{Key: f.Key, Value: map[string]float64{"doubleValue": float64(f.Integer)}}
+	case zapcore.Float64Type:
+		// zap stores the IEEE 754 bits in Integer
+		return KeyValue{Key: f.Key, Value: map[string]float64{"doubleValue": math.Float64frombits(uint64(f.Integer))}}
+	case zapcore.Float32Type:
+		return KeyValue{Key: f.Key, Value: map[string]float64{"doubleValue": float64(math.Float32frombits(uint32(f.Integer)))}}
 	case zapcore.BoolType:
 		return KeyValue{Key: f.Key, Value: map[string]bool{"boolValue": f.Integer == 1}}
 	case zapcore.DurationType:
@@ -0,0 +1,350 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newTestOTLPCore creates an OTLPCore exporting to a collector that accepts everything
+func newTestOTLPCore(t *testing.T, cfg *Config) *OTLPCore {
+	t.Helper()
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(collector.Close)
+
+	cfg.OTLPEndpoint = collector.Listener.Addr().String()
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 10000
+	}
+	if cfg.BatchInterval == 0 {
+		cfg.BatchInterval = time.Hour
+	}
+
+	core := NewOTLPCore(cfg, zapcore.DebugLevel)
+	t.Cleanup(func() { core.Close() })
+	return core
+}
+
+// bufferedRecords returns the number of records waiting to be exported
+func bufferedRecords(c *OTLPCore) int {
+	c.bufferMu.Lock()
+	defer c.bufferMu.Unlock()
+	return len(c.buffer)
+}
+
+func TestOTLPCore_SamplesLowSeverityLogs(t *testing.T) {
+	core := newTestOTLPCore(t, &Config{ServiceName: "test-service", OTLPSampleRate: 0.25})
+
+	for i := 0; i < 1000; i++ {
+		core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "info"}, nil)
+	}
+
+	if got := bufferedRecords(core); got != 250 {
+		t.Errorf("Expected 250 sampled info logs, got %d", got)
+	}
+}
+
+func TestOTLPCore_AlwaysExportsErrors(t *testing.T) {
+	core := newTestOTLPCore(t, &Config{ServiceName: "test-service", OTLPSampleRate: 0.01})
+
+	for i := 0; i < 100; i++ {
+		core.Write(zapcore.Entry{Level: zapcore.WarnLevel, Message: "warn"}, nil)
+		core.Write(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "error"}, nil)
+	}
+
+	if got := bufferedRecords(core); got != 200 {
+		t.Errorf("Expected all 200 warn/error logs to be buffered, got %d", got)
+	}
+}
+
+func TestOTLPCore_ZeroSampleRateExportsAll(t *testing.T) {
+	core := newTestOTLPCore(t, &Config{ServiceName: "test-service"})
+
+	for i := 0; i < 100; i++ {
+		core.Write(zapcore.Entry{Level: zapcore.DebugLevel, Message: "debug"}, nil)
+	}
+
+	if got := bufferedRecords(core); got != 100 {
+		t.Errorf("Expected 100 debug logs to be buffered, got %d", got)
+	}
+}
+
+func TestFieldToKeyValue_Floats(t *testing.T) {
+	tests := []struct {
+		field zapcore.Field
+		want  float64
+	}{
+		{zap.Float64("total_price", 1234.56), 1234.56},
+		{zap.Float64("negative", -0.125), -0.125},
+		{zap.Float32("ratio", 0.5), 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Key, func(t *testing.T) {
+			kv := fieldToKeyValue(tt.field)
+			value, ok := kv.Value.(map[string]float64)
+			if !ok {
+				t.Fatalf("Expected doubleValue, got %T", kv.Value)
+			}
+			if value["doubleValue"] != tt.want {
+				t.Errorf("Expected doubleValue %v, got %v", tt.want, value["doubleValue"])
+			}
+		})
+	}
+}
+
+func TestOTLPCore_ExportsFloatAttribute(t *testing.T) {
+	core := newTestOTLPCore(t, &Config{ServiceName: "test-service"})
+
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "booking created"}, []zapcore.Field{zap.Float64("total_price", 99.95)})
+
+	core.bufferMu.Lock()
+	defer core.bufferMu.Unlock()
+	if len(core.buffer) != 1 {
+		t.Fatalf("Expected 1 buffered record, got %d", len(core.buffer))
+	}
+
+	data, err := json.Marshal(core.buffer[0].Attributes)
+	if err != nil {
+		t.Fatalf("Failed to marshal attributes: %v", err)
+	}
+	want := `[{"key":"total_price","value":{"doubleValue":99.95}}]`
+	if string(data) != want {
+		t.Errorf("Expected attributes %s, got %s", want, data)
+	}
+}
+
+func TestOTLPCore_RetriesAndRequeuesOnFailure(t *testing.T) {
+	var attempts atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:    "test-service",
+		OTLPEndpoint:   collector.Listener.Addr().String(),
+		BatchSize:      100,
+		BatchInterval:  time.Hour,
+		OTLPMaxRetries: 2,
+		MaxBufferSize:  3,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+	core.retryBackoff = time.Millisecond
+
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "first"}, nil)
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "second"}, nil)
+	core.Sync()
+
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 export attempts (1 + 2 retries), got %d", got)
+	}
+	if got := bufferedRecords(core); got != 2 {
+		t.Errorf("Expected failed batch to be re-queued, got %d buffered records", got)
+	}
+	if got := core.DroppedLogs(); got != 0 {
+		t.Errorf("Expected no dropped logs yet, got %d", got)
+	}
+
+	// The buffer holds at most 3 records; further writes are dropped
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "third"}, nil)
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "fourth"}, nil)
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "fifth"}, nil)
+
+	if got := bufferedRecords(core); got != 3 {
+		t.Errorf("Expected buffer capped at 3 records, got %d", got)
+	}
+	if got := core.DroppedLogs(); got != 2 {
+		t.Errorf("Expected 2 dropped logs, got %d", got)
+	}
+}
+
+func TestOTLPCore_DropsRejectedBatch(t *testing.T) {
+	var attempts atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer collector.Close()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:   "test-service",
+		OTLPEndpoint:  collector.Listener.Addr().String(),
+		BatchSize:     100,
+		BatchInterval: time.Hour,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+	core.retryBackoff = time.Millisecond
+
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "malformed"}, nil)
+	core.Sync()
+
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected a rejected batch not to be retried, got %d attempts", got)
+	}
+	if got := core.DroppedLogs(); got != 1 {
+		t.Errorf("Expected 1 dropped log, got %d", got)
+	}
+}
+
+func TestOTLPCore_BoundsConcurrentFlushesAgainstSlowCollector(t *testing.T) {
+	var inFlight, maxInFlight, exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		exports.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:   "test-service",
+		OTLPEndpoint:  collector.Listener.Addr().String(),
+		BatchSize:     1,
+		BatchInterval: 5 * time.Millisecond,
+		MaxBufferSize: 100000,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+
+	goroutinesBefore := runtime.NumGoroutine()
+
+	// Every write fills a batch and asks for a flush
+	var wg sync.WaitGroup
+	for w := 0; w < 20; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "overflow"}, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if grown := runtime.NumGoroutine() - goroutinesBefore; grown > 10 {
+		t.Errorf("Expected flush goroutines to stay bounded, grew by %d", grown)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got := maxInFlight.Load(); got != 1 {
+		t.Errorf("Expected at most 1 export in flight, saw %d", got)
+	}
+	if got := exports.Load(); got >= 2000 {
+		t.Errorf("Expected overflow writes to be batched into few exports, got %d", got)
+	}
+}
+
+func TestOTLPCore_BacksOffAfterFailedFlush(t *testing.T) {
+	var attempts atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:    "test-service",
+		OTLPEndpoint:   collector.Listener.Addr().String(),
+		BatchSize:      1,
+		BatchInterval:  5 * time.Millisecond,
+		OTLPMaxRetries: -1,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+	core.flushBackoff = 100 * time.Millisecond
+	core.flushBackoffMax = 200 * time.Millisecond
+
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "first"}, nil)
+	time.Sleep(20 * time.Millisecond)
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("Expected 1 export attempt, got %d", got)
+	}
+
+	// Neither full batches nor ticks flush while backing off
+	for i := 0; i < 10; i++ {
+		core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "more"}, nil)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected no export attempts during backoff, got %d", got)
+	}
+
+	// The second failure doubles the delay to the 200ms cap
+	time.Sleep(110 * time.Millisecond)
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("Expected a retry once the backoff elapsed, got %d attempts", got)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected the backoff to double after a second failure, got %d attempts", got)
+	}
+
+	// Sync flushes regardless of the backoff
+	core.Sync()
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected Sync to flush during backoff, got %d attempts", got)
+	}
+}
+
+func TestOTLPCore_SyncDoesNotWaitForRetryBackoff(t *testing.T) {
+	var failing atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "unlucky") {
+			failing.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:    "test-service",
+		OTLPEndpoint:   collector.Listener.Addr().String(),
+		BatchSize:      100,
+		BatchInterval:  time.Hour,
+		OTLPMaxRetries: 3,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+	core.retryBackoff = 200 * time.Millisecond
+
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "unlucky"}, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		core.Sync()
+	}()
+	for failing.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The first flush is now sleeping before its retry
+	core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "lucky"}, nil)
+	start := time.Now()
+	core.Sync()
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected Sync not to wait out another flush's retry backoff, took %v", elapsed)
+	}
+	<-done
+}
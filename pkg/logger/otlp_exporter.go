@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// OTLP export protocols
+const (
+	OTLPProtocolHTTP = "http"
+	OTLPProtocolGRPC = "grpc"
+)
+
+// logExporter delivers a batch of log records to the collector
+type logExporter interface {
+	export(payload OTLPLogPayload) error
+	close() error
+}
+
+// newLogExporter creates the exporter for cfg.OTLPProtocol
+func newLogExporter(cfg *Config, timeout time.Duration) (logExporter, error) {
+	endpoint, err := otlpLogEndpoint(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.OTLPProtocol == OTLPProtocolGRPC {
+		creds := credentials.NewTLS(nil)
+		if cfg.OTLPInsecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC client: %w", err)
+		}
+		return &grpcLogExporter{
+			conn:    conn,
+			client:  collogspb.NewLogsServiceClient(conn),
+			timeout: timeout,
+		}, nil
+	}
+
+	return &httpLogExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// otlpLogEndpoint returns the export target for cfg: a URL for HTTP, a host:port for gRPC
+func otlpLogEndpoint(cfg *Config) (string, error) {
+	switch cfg.OTLPProtocol {
+	case OTLPProtocolGRPC:
+		return cfg.OTLPEndpoint, nil
+	case OTLPProtocolHTTP, "":
+		// OTel Collector exposes HTTP on :4318; map the conventional gRPC port over
+		endpoint := cfg.OTLPEndpoint
+		if strings.HasSuffix(endpoint, ":4317") {
+			endpoint = strings.TrimSuffix(endpoint, "4317") + "4318"
+		}
+		return fmt.Sprintf("http://%s/v1/logs", endpoint), nil
+	default:
+		return "", fmt.Errorf("unsupported OTLP protocol %q", cfg.OTLPProtocol)
+	}
+}
+
+// httpLogExporter POSTs OTLP/JSON to the collector's /v1/logs endpoint
+type httpLogExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// export implements logExporter
+func (e *httpLogExporter) export(payload OTLPLogPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: failed to marshal payload: %v", errOTLPRejected, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errOTLPRejected, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("OTLP export failed with status %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("%w with status %d", errOTLPRejected, resp.StatusCode)
+	}
+	return nil
+}
+
+// close implements logExporter
+func (e *httpLogExporter) close() error {
+	return nil
+}
+
+// grpcLogExporter sends logs with the OTLP LogsService gRPC client
+type grpcLogExporter struct {
+	conn    *grpc.ClientConn
+	client  collogspb.LogsServiceClient
+	timeout time.Duration
+}
+
+// export implements logExporter
+func (e *grpcLogExporter) export(payload OTLPLogPayload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	_, err := e.client.Export(ctx, payloadToProto(payload))
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.InvalidArgument, codes.Unimplemented, codes.PermissionDenied, codes.Unauthenticated:
+		return fmt.Errorf("%w: %v", errOTLPRejected, err)
+	default:
+		return fmt.Errorf("OTLP export failed: %w", err)
+	}
+}
+
+// close implements logExporter
+func (e *grpcLogExporter) close() error {
+	return e.conn.Close()
+}
+
+// payloadToProto converts the JSON-shaped payload into an OTLP protobuf request
+func payloadToProto(payload OTLPLogPayload) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	for _, rl := range payload.ResourceLogs {
+		resourceLogs := &logspb.ResourceLogs{
+			Resource: &resourcepb.Resource{Attributes: keyValuesToProto(rl.Resource.Attributes)},
+		}
+		for _, sl := range rl.ScopeLogs {
+			scopeLogs := &logspb.ScopeLogs{
+				Scope: &commonpb.InstrumentationScope{Name: sl.Scope.Name, Version: sl.Scope.Version},
+			}
+			for _, r := range sl.LogRecords {
+				scopeLogs.LogRecords = append(scopeLogs.LogRecords, logRecordToProto(r))
+			}
+			resourceLogs.ScopeLogs = append(resourceLogs.ScopeLogs, scopeLogs)
+		}
+		req.ResourceLogs = append(req.ResourceLogs, resourceLogs)
+	}
+	return req
+}
+
+// logRecordToProto converts a LogRecord into its protobuf form
+func logRecordToProto(r LogRecord) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(r.Timestamp),
+		ObservedTimeUnixNano: uint64(r.ObservedTimestamp),
+		SeverityNumber:       logspb.SeverityNumber(r.SeverityNumber),
+		SeverityText:         r.SeverityText,
+		Body:                 valueToProto(r.Body),
+		Attributes:           keyValuesToProto(r.Attributes),
+	}
+	// Invalid hex IDs are dropped rather than failing the batch
+	if traceID, err := hex.DecodeString(r.TraceID); err == nil && len(traceID) == 16 {
+		record.TraceId = traceID
+	}
+	if spanID, err := hex.DecodeString(r.SpanID); err == nil && len(spanID) == 8 {
+		record.SpanId = spanID
+	}
+	return record
+}
+
+// keyValuesToProto converts attributes into protobuf KeyValues
+func keyValuesToProto(kvs []KeyValue) []*commonpb.KeyValue {
+	out := make([]*commonpb.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		out = append(out, &commonpb.KeyValue{Key: kv.Key, Value: valueToProto(kv.Value)})
+	}
+	return out
+}
+
+// valueToProto converts a JSON-shaped value built by fieldToKeyValue into an AnyValue
+func valueToProto(v interface{}) *commonpb.AnyValue {
+	switch val := v.(type) {
+	case map[string]string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val["stringValue"]}}
+	case map[string]int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: val["intValue"]}}
+	case map[string]uint64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(val["intValue"])}}
+	case map[string]float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: val["doubleValue"]}}
+	case map[string]bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: val["boolValue"]}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
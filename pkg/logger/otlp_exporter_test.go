@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+)
+
+func TestOTLPLogEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{name: "http default maps gRPC port", protocol: "", endpoint: "otel-collector:4317", want: "http://otel-collector:4318/v1/logs"},
+		{name: "http maps gRPC port for any host", protocol: OTLPProtocolHTTP, endpoint: "10.0.0.5:4317", want: "http://10.0.0.5:4318/v1/logs"},
+		{name: "http keeps custom port", protocol: OTLPProtocolHTTP, endpoint: "collector:9999", want: "http://collector:9999/v1/logs"},
+		{name: "grpc uses endpoint as-is", protocol: OTLPProtocolGRPC, endpoint: "otel-collector:4317", want: "otel-collector:4317"},
+		{name: "unknown protocol", protocol: "udp", endpoint: "otel-collector:4317", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := otlpLogEndpoint(&Config{OTLPProtocol: tt.protocol, OTLPEndpoint: tt.endpoint})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("otlpLogEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("otlpLogEndpoint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testLogsServer records the requests received by a gRPC LogsService
+type testLogsServer struct {
+	collogspb.UnimplementedLogsServiceServer
+	requests chan *collogspb.ExportLogsServiceRequest
+}
+
+func (s *testLogsServer) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.requests <- req
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func TestOTLPCore_GRPCExport(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	logsServer := &testLogsServer{requests: make(chan *collogspb.ExportLogsServiceRequest, 1)}
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, logsServer)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	core := NewOTLPCore(&Config{
+		ServiceName:   "test-service",
+		OTLPEndpoint:  lis.Addr().String(),
+		OTLPProtocol:  OTLPProtocolGRPC,
+		OTLPInsecure:  true,
+		BatchSize:     100,
+		BatchInterval: time.Hour,
+	}, zapcore.DebugLevel)
+	defer core.Close()
+
+	core.Write(zapcore.Entry{Level: zapcore.WarnLevel, Message: "seat released"}, []zapcore.Field{
+		zap.String("trace_id", "0af7651916cd43dd8448eb211c80319c"),
+		zap.Int("quantity", 2),
+	})
+	core.Sync()
+
+	select {
+	case req := <-logsServer.requests:
+		records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+		if len(records) != 1 {
+			t.Fatalf("Expected 1 log record, got %d", len(records))
+		}
+		record := records[0]
+		if record.Body.GetStringValue() != "seat released" {
+			t.Errorf("Expected body 'seat released', got %q", record.Body.GetStringValue())
+		}
+		if record.SeverityText != "warn" {
+			t.Errorf("Expected severity 'warn', got %q", record.SeverityText)
+		}
+		if len(record.TraceId) != 16 {
+			t.Errorf("Expected 16-byte trace ID, got %d bytes", len(record.TraceId))
+		}
+		if len(record.Attributes) != 1 || record.Attributes[0].Value.GetIntValue() != 2 {
+			t.Errorf("Expected quantity=2 attribute, got %v", record.Attributes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for gRPC export")
+	}
+}
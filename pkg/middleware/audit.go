@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AuditAction represents the type of action being audited
@@ -39,6 +46,8 @@ const (
 	ContextKeyAuditOldValues    = "audit_old_values"
 	ContextKeyAuditNewValues    = "audit_new_values"
 	ContextKeyAuditMetadata     = "audit_metadata"
+	ContextKeyAuditSagaID       = "audit_saga_id"
+	ContextKeyAuditBookingID    = "audit_booking_id"
 )
 
 // AuditEntry represents a single audit log entry
@@ -51,6 +60,8 @@ type AuditEntry struct {
 	Action       AuditAction            `json:"action"`
 	ResourceType string                 `json:"resource_type"`
 	ResourceID   *string                `json:"resource_id,omitempty"`
+	SagaID       *string                `json:"saga_id,omitempty"`
+	BookingID    *string                `json:"booking_id,omitempty"`
 	IPAddress    string                 `json:"ip_address,omitempty"`
 	UserAgent    string                 `json:"user_agent,omitempty"`
 	RequestID    string                 `json:"request_id,omitempty"`
@@ -117,6 +128,17 @@ type AuditLogger struct {
 	cancel    context.CancelFunc
 	closeOnce sync.Once
 
+	// Stats, read by Stats() and the registered metrics
+	dropped           atomic.Int64
+	flushes           atomic.Int64
+	flushedEntries    atomic.Int64
+	lastFlushSize     atomic.Int64
+	lastFlushDuration atomic.Int64 // nanoseconds
+
+	// Set by RegisterMetrics
+	flushDuration  *telemetry.Histogram
+	flushBatchSize *telemetry.Histogram
+
 	// For testing: collect entries instead of writing to DB
 	testMode    bool
 	testEntries []*AuditEntry
@@ -158,7 +180,79 @@ func (al *AuditLogger) Log(entry *AuditEntry) {
 		// Entry added to buffer
 	default:
 		// Buffer full, drop entry (or could implement overflow handling)
+		al.dropped.Add(1)
+	}
+}
+
+// AuditStats is a snapshot of the audit buffer and flush activity
+type AuditStats struct {
+	BufferLength      int           // Entries waiting in the buffer
+	BufferCapacity    int           // Entries the buffer holds before Log drops
+	Dropped           int64         // Entries dropped because the buffer was full
+	Flushes           int64         // Batches flushed
+	FlushedEntries    int64         // Entries flushed across all batches
+	LastFlushSize     int           // Entries in the most recent batch
+	LastFlushDuration time.Duration // How long the most recent batch took to write
+}
+
+// Stats returns the current buffer utilization and flush counters. A buffer
+// staying near capacity or slow flushes precede dropped entries.
+func (al *AuditLogger) Stats() AuditStats {
+	return AuditStats{
+		BufferLength:      len(al.buffer),
+		BufferCapacity:    cap(al.buffer),
+		Dropped:           al.dropped.Load(),
+		Flushes:           al.flushes.Load(),
+		FlushedEntries:    al.flushedEntries.Load(),
+		LastFlushSize:     int(al.lastFlushSize.Load()),
+		LastFlushDuration: time.Duration(al.lastFlushDuration.Load()),
+	}
+}
+
+// RegisterMetrics exports the audit stats through the OTel meter: buffer
+// length and dropped entries are observed at collection time, and each flush
+// records its duration and batch size. Call it once per logger, after
+// telemetry is initialized and before entries are logged.
+func (al *AuditLogger) RegisterMetrics() error {
+	var err error
+	al.flushDuration, err = telemetry.NewHistogram(telemetry.MetricOpts{
+		Name:        "audit_flush_duration_seconds",
+		Description: "Time taken to write a batch of audit entries",
+		Unit:        "s",
+	})
+	if err != nil {
+		return err
 	}
+	al.flushBatchSize, err = telemetry.NewHistogram(telemetry.MetricOpts{
+		Name:        "audit_flush_batch_size",
+		Description: "Audit entries written per flush",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	meter := telemetry.GetMeter()
+	_, err = meter.Int64ObservableGauge("audit_buffer_length",
+		metric.WithDescription("Audit entries waiting in the buffer"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(len(al.buffer)))
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = meter.Int64ObservableCounter("audit_dropped_total",
+		metric.WithDescription("Audit entries dropped because the buffer was full"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(al.dropped.Load())
+			return nil
+		}),
+	)
+	return err
 }
 
 // Close gracefully shuts down the audit logger
@@ -236,12 +330,28 @@ func (al *AuditLogger) worker() {
 	}
 }
 
-// flush writes a batch of entries to the database
+// flush writes a batch of entries and records its size and duration
 func (al *AuditLogger) flush(entries []*AuditEntry) {
 	if len(entries) == 0 {
 		return
 	}
 
+	start := time.Now()
+	al.write(entries)
+	elapsed := time.Since(start)
+
+	al.flushes.Add(1)
+	al.flushedEntries.Add(int64(len(entries)))
+	al.lastFlushSize.Store(int64(len(entries)))
+	al.lastFlushDuration.Store(int64(elapsed))
+	if al.flushDuration != nil {
+		al.flushDuration.Record(context.Background(), elapsed.Seconds())
+		al.flushBatchSize.Record(context.Background(), float64(len(entries)))
+	}
+}
+
+// write writes a batch of entries to the database
+func (al *AuditLogger) write(entries []*AuditEntry) {
 	// In test mode, just collect entries
 	al.testMu.Lock()
 	if al.testMode {
@@ -262,14 +372,14 @@ func (al *AuditLogger) flush(entries []*AuditEntry) {
 	query := `
 		INSERT INTO audit_logs (
 			id, tenant_id, user_id, user_email, user_role,
-			action, resource_type, resource_id,
+			action, resource_type, resource_id, saga_id, booking_id,
 			ip_address, user_agent, request_id, trace_id,
 			old_values, new_values, changes, metadata, created_at
 		) VALUES (
 			$1, $2, $3, $4, $5,
-			$6, $7, $8,
-			$9, $10, $11, $12,
-			$13, $14, $15, $16, $17
+			$6, $7, $8, $9, $10,
+			$11, $12, $13, $14,
+			$15, $16, $17, $18, $19
 		)
 	`
 
@@ -296,7 +406,7 @@ func (al *AuditLogger) flush(entries []*AuditEntry) {
 
 		batch.Queue(query,
 			entry.ID, entry.TenantID, entry.UserID, entry.UserEmail, entry.UserRole,
-			string(entry.Action), entry.ResourceType, entry.ResourceID,
+			string(entry.Action), entry.ResourceType, entry.ResourceID, entry.SagaID, entry.BookingID,
 			entry.IPAddress, entry.UserAgent, entry.RequestID, entry.TraceID,
 			oldValuesJSON, newValuesJSON, changesJSON, metadataJSON, entry.CreatedAt,
 		)
@@ -312,6 +422,81 @@ func (al *AuditLogger) flush(entries []*AuditEntry) {
 	}
 }
 
+// EntriesForBooking returns the audit entries tied to a booking, oldest first,
+// so user actions can be read alongside the booking's saga transitions.
+// Entries still waiting in the buffer are not included.
+func (al *AuditLogger) EntriesForBooking(ctx context.Context, bookingID string) ([]*AuditEntry, error) {
+	al.testMu.Lock()
+	if al.testMode {
+		var entries []*AuditEntry
+		for _, entry := range al.testEntries {
+			if entry.BookingID != nil && *entry.BookingID == bookingID {
+				entries = append(entries, entry)
+			}
+		}
+		al.testMu.Unlock()
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		})
+		return entries, nil
+	}
+	al.testMu.Unlock()
+
+	if al.config.DB == nil {
+		return nil, errors.New("audit logger has no database")
+	}
+
+	rows, err := al.config.DB.Query(ctx, `
+		SELECT id::text, tenant_id::text, user_id::text, COALESCE(user_email, ''), COALESCE(user_role, ''),
+			action::text, resource_type, resource_id::text, saga_id, booking_id,
+			COALESCE(host(ip_address), ''), COALESCE(user_agent, ''), COALESCE(request_id, ''), COALESCE(trace_id, ''),
+			old_values, new_values, changes, metadata, created_at
+		FROM audit_logs
+		WHERE booking_id = $1
+		ORDER BY created_at, id
+	`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		var action string
+		var oldValues, newValues, changes, metadata []byte
+		if err := rows.Scan(
+			&entry.ID, &entry.TenantID, &entry.UserID, &entry.UserEmail, &entry.UserRole,
+			&action, &entry.ResourceType, &entry.ResourceID, &entry.SagaID, &entry.BookingID,
+			&entry.IPAddress, &entry.UserAgent, &entry.RequestID, &entry.TraceID,
+			&oldValues, &newValues, &changes, &metadata, &entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Action = AuditAction(action)
+		for _, field := range []struct {
+			raw []byte
+			dst *map[string]interface{}
+		}{
+			{oldValues, &entry.OldValues},
+			{newValues, &entry.NewValues},
+			{changes, &entry.Changes},
+			{metadata, &entry.Metadata},
+		} {
+			if len(field.raw) > 0 {
+				_ = json.Unmarshal(field.raw, field.dst)
+			}
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
 // pgxBatch is a simple batch helper
 type pgxBatch struct {
 	items []batchItem
@@ -326,7 +511,13 @@ func (b *pgxBatch) Queue(query string, args ...interface{}) {
 	b.items = append(b.items, batchItem{query: query, args: args})
 }
 
-// AuditMiddleware creates a new audit logging middleware
+// AuditMiddleware creates a new audit logging middleware.
+//
+// The entry is built in two steps. Request-derived fields (client IP, user
+// agent, request and trace IDs, action, resource and any user info already on
+// the context) are captured before the handlers run; handler-set audit values
+// and user info set by later middleware are added after. Mount it after the
+// auth and request ID middleware so their values are part of the snapshot.
 func AuditMiddleware(logger *AuditLogger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		config := logger.config
@@ -370,8 +561,11 @@ func AuditMiddleware(logger *AuditLogger) gin.HandlerFunc {
 			c.Writer = responseWriter
 		}
 
-		// Store start time
-		startTime := time.Now()
+		// Snapshot everything the request itself provides before running the
+		// handlers. By the time c.Next() returns the client may have gone away,
+		// cancelling the request context, and handlers may have swapped
+		// c.Request, so only values handlers set on purpose are read afterwards.
+		entry := snapshotAuditEntry(c, config)
 
 		// Process request
 		c.Next()
@@ -381,37 +575,25 @@ func AuditMiddleware(logger *AuditLogger) gin.HandlerFunc {
 			return
 		}
 
-		// Create audit entry
-		entry := &AuditEntry{
-			ID:        uuid.New().String(),
-			CreatedAt: startTime,
-		}
-
-		// Extract user info from context (set by JWT middleware)
-		if userID, ok := GetUserID(c); ok && userID != "" {
-			entry.UserID = &userID
-		}
-		if email, ok := GetEmail(c); ok {
-			entry.UserEmail = email
-		}
-		if role, ok := GetRole(c); ok {
-			entry.UserRole = role
+		// Fill in user info set by later middleware (e.g. per-route JWT)
+		if entry.UserID == nil {
+			if userID, ok := GetUserID(c); ok && userID != "" {
+				entry.UserID = &userID
+			}
 		}
-		if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
-			entry.TenantID = &tenantID
+		if entry.UserEmail == "" {
+			if email, ok := GetEmail(c); ok {
+				entry.UserEmail = email
+			}
 		}
-
-		// Extract action
-		if config.ActionMapper != nil {
-			entry.Action = config.ActionMapper(c.Request.Method, c.Request.URL.Path)
+		if entry.UserRole == "" {
+			if role, ok := GetRole(c); ok {
+				entry.UserRole = role
+			}
 		}
-
-		// Extract resource info
-		if config.ResourceExtractor != nil {
-			resourceType, resourceID := config.ResourceExtractor(c.Request.URL.Path)
-			entry.ResourceType = resourceType
-			if resourceID != "" {
-				entry.ResourceID = &resourceID
+		if entry.TenantID == nil {
+			if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
+				entry.TenantID = &tenantID
 			}
 		}
 
@@ -433,6 +615,12 @@ func AuditMiddleware(logger *AuditLogger) gin.HandlerFunc {
 		if meta, exists := c.Get(ContextKeyAuditMetadata); exists {
 			entry.Metadata = meta.(map[string]interface{})
 		}
+		if sagaID := c.GetString(ContextKeyAuditSagaID); sagaID != "" {
+			entry.SagaID = &sagaID
+		}
+		if bookingID := c.GetString(ContextKeyAuditBookingID); bookingID != "" {
+			entry.BookingID = &bookingID
+		}
 
 		// Compute changes if both old and new values exist
 		if entry.OldValues != nil && entry.NewValues != nil {
@@ -455,24 +643,67 @@ func AuditMiddleware(logger *AuditLogger) gin.HandlerFunc {
 			}
 		}
 
-		// Extract request context
-		entry.IPAddress = getClientIP(c)
-		entry.UserAgent = c.GetHeader("User-Agent")
-		entry.RequestID = c.GetHeader("X-Request-ID")
-		entry.TraceID = c.GetHeader("X-Trace-ID")
-
-		// If no request ID, generate one
+		// If no request ID header, use the one generated by the request ID middleware
 		if entry.RequestID == "" {
 			if reqID, exists := c.Get("request_id"); exists {
-				entry.RequestID = reqID.(string)
+				entry.RequestID, _ = reqID.(string)
 			}
 		}
 
-		// Log asynchronously
+		// Log asynchronously. Log only touches the buffer, never the request
+		// context, so an entry for a cancelled request is still written.
 		logger.Log(entry)
 	}
 }
 
+// snapshotAuditEntry builds an audit entry from the parts of the request that
+// are known before the handlers run
+func snapshotAuditEntry(c *gin.Context, config *AuditConfig) *AuditEntry {
+	entry := &AuditEntry{
+		ID:        uuid.New().String(),
+		IPAddress: getClientIP(c),
+		UserAgent: c.GetHeader("User-Agent"),
+		RequestID: c.GetHeader("X-Request-ID"),
+		TraceID:   c.GetHeader("X-Trace-ID"),
+		CreatedAt: time.Now(),
+	}
+	if entry.TraceID == "" {
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			entry.TraceID = sc.TraceID().String()
+		}
+	}
+
+	// Extract user info from context (set by JWT middleware)
+	if userID, ok := GetUserID(c); ok && userID != "" {
+		entry.UserID = &userID
+	}
+	if email, ok := GetEmail(c); ok {
+		entry.UserEmail = email
+	}
+	if role, ok := GetRole(c); ok {
+		entry.UserRole = role
+	}
+	if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
+		entry.TenantID = &tenantID
+	}
+
+	// Extract action
+	if config.ActionMapper != nil {
+		entry.Action = config.ActionMapper(c.Request.Method, c.Request.URL.Path)
+	}
+
+	// Extract resource info
+	if config.ResourceExtractor != nil {
+		resourceType, resourceID := config.ResourceExtractor(c.Request.URL.Path)
+		entry.ResourceType = resourceType
+		if resourceID != "" {
+			entry.ResourceID = &resourceID
+		}
+	}
+
+	return entry
+}
+
 // auditResponseWriter captures response body
 type auditResponseWriter struct {
 	gin.ResponseWriter
@@ -717,6 +948,16 @@ func SetAuditMetadata(c *gin.Context, metadata map[string]interface{}) {
 	c.Set(ContextKeyAuditMetadata, metadata)
 }
 
+// SetAuditSagaID ties the audit entry to the saga instance the request drives
+func SetAuditSagaID(c *gin.Context, sagaID string) {
+	c.Set(ContextKeyAuditSagaID, sagaID)
+}
+
+// SetAuditBookingID ties the audit entry to a booking, so EntriesForBooking can find it
+func SetAuditBookingID(c *gin.Context, bookingID string) {
+	c.Set(ContextKeyAuditBookingID, bookingID)
+}
+
 // SkipAudit marks the current request to skip audit logging
 func SkipAudit(c *gin.Context) {
 	c.Set("audit_skip", true)
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -277,6 +278,51 @@ func TestAuditLogger_BufferFull(t *testing.T) {
 	}
 }
 
+func TestAuditLogger_Stats(t *testing.T) {
+	// No worker drains this buffer, so queued and dropped entries are exact
+	queued := &AuditLogger{config: &AuditConfig{}, buffer: make(chan *AuditEntry, 3)}
+	for i := 0; i < 5; i++ {
+		queued.Log(&AuditEntry{ID: "queued"})
+	}
+	stats := queued.Stats()
+	assert.Equal(t, 3, stats.BufferLength)
+	assert.Equal(t, 3, stats.BufferCapacity)
+	assert.Equal(t, int64(2), stats.Dropped)
+	assert.Equal(t, int64(0), stats.Flushes)
+
+	logger := NewAuditLogger(&AuditConfig{
+		BufferSize:    10,
+		FlushInterval: time.Hour,
+		BatchSize:     2,
+	})
+	logger.SetTestMode(true)
+	defer logger.Close()
+
+	for i := 0; i < 4; i++ {
+		logger.Log(&AuditEntry{ID: "flushed"})
+	}
+
+	// BatchSize 2 flushes as soon as each pair arrives
+	require.Eventually(t, func() bool { return logger.Stats().FlushedEntries == 4 }, time.Second, 10*time.Millisecond)
+	stats = logger.Stats()
+	assert.Equal(t, int64(2), stats.Flushes)
+	assert.Equal(t, 2, stats.LastFlushSize)
+	assert.Equal(t, 0, stats.BufferLength)
+	assert.Equal(t, int64(0), stats.Dropped)
+	assert.Positive(t, stats.LastFlushDuration)
+}
+
+func TestAuditLogger_RegisterMetrics(t *testing.T) {
+	logger := NewAuditLogger(&AuditConfig{BufferSize: 10, FlushInterval: time.Hour, BatchSize: 1})
+	logger.SetTestMode(true)
+	defer logger.Close()
+
+	require.NoError(t, logger.RegisterMetrics())
+
+	logger.Log(&AuditEntry{ID: "metered"})
+	require.Eventually(t, func() bool { return logger.Stats().Flushes == 1 }, time.Second, 10*time.Millisecond)
+}
+
 func TestAuditMiddleware_SkipPaths(t *testing.T) {
 	config := &AuditConfig{
 		DB:            nil,
@@ -382,6 +428,64 @@ func TestAuditMiddleware_CapturesUserInfo(t *testing.T) {
 	assert.Equal(t, "TestAgent/1.0", entry.UserAgent)
 }
 
+func TestAuditMiddleware_CancelledRequestContext(t *testing.T) {
+	logger := NewAuditLogger(&AuditConfig{
+		BufferSize:        100,
+		FlushInterval:     20 * time.Millisecond,
+		BatchSize:         100,
+		ActionMapper:      defaultActionMapper,
+		ResourceExtractor: defaultResourceExtractor,
+	})
+	logger.SetTestMode(true)
+	defer logger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(ContextKeyUserID, "user-123")
+		c.Set(ContextKeyTenantID, "tenant-456")
+		c.Next()
+	})
+	router.Use(AuditMiddleware(logger))
+	router.POST("/api/v1/bookings/:id/cancel", func(c *gin.Context) {
+		// The client disconnects mid-request, and the handler replaces the
+		// request with one that no longer carries the original headers
+		cancel()
+		c.Request = c.Request.Clone(context.Background())
+		c.Request.Header = http.Header{}
+		c.Request.RemoteAddr = ""
+		c.Set(ContextKeyAuditMetadata, map[string]interface{}{"reason": "client_gone"})
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bookings/789/cancel", nil).WithContext(ctx)
+	req.Header.Set("User-Agent", "test-agent/1.0")
+	req.Header.Set("X-Request-ID", "req-001")
+	req.Header.Set("X-Trace-ID", "trace-001")
+	req.RemoteAddr = "203.0.113.7:4321"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Eventually(t, func() bool { return len(logger.GetTestEntries()) == 1 }, time.Second, 10*time.Millisecond)
+	entry := logger.GetTestEntries()[0]
+
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, AuditActionCancel, entry.Action)
+	assert.Equal(t, "booking", entry.ResourceType)
+	require.NotNil(t, entry.ResourceID)
+	assert.Equal(t, "789", *entry.ResourceID)
+	require.NotNil(t, entry.UserID)
+	assert.Equal(t, "user-123", *entry.UserID)
+	require.NotNil(t, entry.TenantID)
+	assert.Equal(t, "tenant-456", *entry.TenantID)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "test-agent/1.0", entry.UserAgent)
+	assert.Equal(t, "req-001", entry.RequestID)
+	assert.Equal(t, "trace-001", entry.TraceID)
+	assert.Equal(t, "client_gone", entry.Metadata["reason"])
+}
+
 func TestAuditMiddleware_SetContextValues(t *testing.T) {
 	config := &AuditConfig{
 		DB:                nil,
@@ -432,6 +536,71 @@ func TestAuditMiddleware_SetContextValues(t *testing.T) {
 	assert.Contains(t, entry.Changes, "name")
 }
 
+func TestAuditLogger_EntriesForBooking(t *testing.T) {
+	config := &AuditConfig{
+		DB:                nil,
+		BufferSize:        100,
+		FlushInterval:     50 * time.Millisecond,
+		BatchSize:         100,
+		SkipPaths:         []string{},
+		SkipMethods:       []string{},
+		ActionMapper:      defaultActionMapper,
+		ResourceExtractor: defaultResourceExtractor,
+	}
+
+	logger := NewAuditLogger(config)
+	logger.SetTestMode(true)
+	defer logger.Close()
+
+	router := gin.New()
+	router.Use(AuditMiddleware(logger))
+	router.POST("/api/v1/bookings/:id/:action", func(c *gin.Context) {
+		// Handler ties the entry to the booking and its saga
+		SetAuditBookingID(c, c.Param("id"))
+		SetAuditSagaID(c, "saga-"+c.Param("id"))
+		c.String(http.StatusOK, "OK")
+	})
+	router.POST("/api/v1/events", func(c *gin.Context) {
+		c.String(http.StatusCreated, "OK")
+	})
+
+	for _, path := range []string{
+		"/api/v1/bookings/booking-1/reserve",
+		"/api/v1/bookings/booking-2/reserve",
+		"/api/v1/events",
+		"/api/v1/bookings/booking-1/confirm",
+		"/api/v1/bookings/booking-1/cancel",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		router.ServeHTTP(w, req)
+		require.Less(t, w.Code, 300)
+	}
+
+	// Wait for flush
+	time.Sleep(150 * time.Millisecond)
+
+	entries, err := logger.EntriesForBooking(context.Background(), "booking-1")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	wantActions := []AuditAction{AuditActionReserve, AuditActionConfirm, AuditActionCancel}
+	for i, entry := range entries {
+		assert.Equal(t, wantActions[i], entry.Action)
+		require.NotNil(t, entry.BookingID)
+		assert.Equal(t, "booking-1", *entry.BookingID)
+		require.NotNil(t, entry.SagaID)
+		assert.Equal(t, "saga-booking-1", *entry.SagaID)
+		if i > 0 {
+			assert.False(t, entry.CreatedAt.Before(entries[i-1].CreatedAt), "entries must be in time order")
+		}
+	}
+
+	entries, err = logger.EntriesForBooking(context.Background(), "booking-unknown")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestAuditMiddleware_SkipAudit(t *testing.T) {
 	config := &AuditConfig{
 		DB:                nil,
@@ -705,6 +874,8 @@ func TestAuditContextKeys(t *testing.T) {
 		ContextKeyAuditOldValues,
 		ContextKeyAuditNewValues,
 		ContextKeyAuditMetadata,
+		ContextKeyAuditSagaID,
+		ContextKeyAuditBookingID,
 	}
 
 	for _, key := range keys {
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+)
+
+// DefaultBackpressureRetryAfter is the Retry-After sent to rejected clients
+const DefaultBackpressureRetryAfter = time.Second
+
+// GlobalRateLimiter implements global (non-per-IP) rate limiting for spike protection
+type GlobalRateLimiter struct {
+	maxConcurrent int64
+	currentCount  int64
+	mu            sync.Mutex
+}
+
+// NewGlobalRateLimiter creates a new global rate limiter
+func NewGlobalRateLimiter(maxConcurrent int64) *GlobalRateLimiter {
+	return &GlobalRateLimiter{
+		maxConcurrent: maxConcurrent,
+	}
+}
+
+// Acquire tries to acquire a slot
+func (g *GlobalRateLimiter) Acquire() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.currentCount >= g.maxConcurrent {
+		return false
+	}
+	g.currentCount++
+	return true
+}
+
+// Release releases a slot
+func (g *GlobalRateLimiter) Release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.currentCount > 0 {
+		g.currentCount--
+	}
+}
+
+// CurrentCount returns the current concurrent request count
+func (g *GlobalRateLimiter) CurrentCount() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.currentCount
+}
+
+// Backpressure creates a middleware that lets at most maxConcurrent requests
+// through at once and answers the overflow with 429 and Retry-After, so a
+// service shed load itself when the gateway limit in front of it is missing or
+// misconfigured. Shedding is deliberately not a 503: the gateway counts 503s
+// toward its per-backend circuit breaker, and a burst of shed requests would
+// open it for the whole backend. A maxConcurrent of 0 or less disables the limit. retryAfter
+// defaults to DefaultBackpressureRetryAfter and is rounded up to whole seconds.
+func Backpressure(maxConcurrent int64, retryAfter time.Duration) gin.HandlerFunc {
+	if maxConcurrent <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if retryAfter <= 0 {
+		retryAfter = DefaultBackpressureRetryAfter
+	}
+	retryAfterSeconds := strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10)
+	limiter := NewGlobalRateLimiter(maxConcurrent)
+
+	return func(c *gin.Context) {
+		if !limiter.Acquire() {
+			c.Header("Retry-After", retryAfterSeconds)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests,
+				response.Error(response.ErrCodeTooManyRequests, "Server is at capacity. Please retry in a moment."))
+			return
+		}
+
+		defer limiter.Release()
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+)
+
+func TestBackpressure_RejectsOverflowWhenSaturated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := gin.New()
+	router.POST("/bookings/reserve", Backpressure(2, 3*time.Second), func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"booking_id": "booking-1"})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bookings/reserve", nil))
+		return w
+	}
+
+	// Saturate the limiter with two requests held inside the handler
+	var wg sync.WaitGroup
+	held := make([]*httptest.ResponseRecorder, 2)
+	for i := range held {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			held[i] = send()
+		}(i)
+		<-entered
+	}
+
+	w := send()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Overflow request status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+	var body response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body.Error == nil || body.Error.Code != response.ErrCodeTooManyRequests {
+		t.Errorf("Error = %+v, want code %s", body.Error, response.ErrCodeTooManyRequests)
+	}
+
+	close(release)
+	wg.Wait()
+	for i, w := range held {
+		if w.Code != http.StatusCreated {
+			t.Errorf("Held request %d status = %d, want %d", i+1, w.Code, http.StatusCreated)
+		}
+	}
+
+	// Slots are released once the held requests finish
+	go func() { <-entered }()
+	if w := send(); w.Code != http.StatusCreated {
+		t.Errorf("Request after release status = %d, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestBackpressure_DisabledWithoutLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/test", Backpressure(0, 0), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -20,6 +20,8 @@ import (
 const (
 	// IdempotencyKeyHeader is the header name for idempotency key
 	IdempotencyKeyHeader = "X-Idempotency-Key"
+	// StandardIdempotencyKeyHeader is the IETF draft header, accepted when IdempotencyKeyHeader is absent
+	StandardIdempotencyKeyHeader = "Idempotency-Key"
 	// ContextKeyIdempotencyKey is the context key for idempotency key
 	ContextKeyIdempotencyKey = "idempotency_key"
 	// Default TTL for idempotency records (5 minutes - short-lived for network retries)
@@ -100,7 +102,27 @@ func DefaultIdempotencyConfig(redis RedisClient) *IdempotencyConfig {
 
 // defaultKeyExtractor extracts idempotency key from header
 func defaultKeyExtractor(c *gin.Context) string {
-	return c.GetHeader(IdempotencyKeyHeader)
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		return key
+	}
+	return c.GetHeader(StandardIdempotencyKeyHeader)
+}
+
+// Idempotency creates an idempotency middleware with the default configuration.
+// The first response for each (user_id, key) pair is stored and replayed for
+// duplicates; a duplicate arriving while the first is still running gets 409.
+func Idempotency(redis RedisClient) gin.HandlerFunc {
+	return IdempotencyMiddleware(DefaultIdempotencyConfig(redis))
+}
+
+// IdempotencyRecordKey scopes an idempotency key to the user that sent it, so
+// two users choosing the same key never see each other's responses. Requests
+// without a user keep the bare key.
+func IdempotencyRecordKey(userID, idempotencyKey string) string {
+	if userID == "" {
+		return idempotencyKey
+	}
+	return userID + ":" + idempotencyKey
 }
 
 // IdempotencyMiddleware creates a new idempotency middleware
@@ -128,7 +150,7 @@ func IdempotencyMiddleware(config *IdempotencyConfig) gin.HandlerFunc {
 		// Extract idempotency key
 		idempotencyKey := config.KeyExtractor(c)
 		if idempotencyKey == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, response.Error("MISSING_IDEMPOTENCY_KEY", "X-Idempotency-Key header is required"))
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Error("MISSING_IDEMPOTENCY_KEY", "Idempotency-Key header is required"))
 			return
 		}
 
@@ -146,8 +168,9 @@ func IdempotencyMiddleware(config *IdempotencyConfig) gin.HandlerFunc {
 		// Generate request hash
 		requestHash := generateRequestHash(c, bodyBytes, config)
 
-		// Build Redis key
-		redisKey := IdempotencyKeyPrefix + idempotencyKey
+		// Build Redis key, scoped to the caller
+		userID, _ := GetUserID(c)
+		redisKey := IdempotencyKeyPrefix + IdempotencyRecordKey(userID, idempotencyKey)
 
 		ctx := c.Request.Context()
 
@@ -187,7 +210,13 @@ func IdempotencyMiddleware(config *IdempotencyConfig) gin.HandlerFunc {
 		}
 
 		// Try to set record (atomic) with SHORT ProcessingTTL
-		if !trySetIdempotencyRecord(ctx, config.Redis, redisKey, record, config.ProcessingTTL) {
+		acquired, err := trySetIdempotencyRecord(ctx, config.Redis, redisKey, record, config.ProcessingTTL)
+		if err != nil {
+			// Redis error - continue without idempotency (fail open)
+			c.Next()
+			return
+		}
+		if !acquired {
 			// Another request beat us - retry get
 			existingRecord, _ = getIdempotencyRecord(ctx, config.Redis, redisKey)
 			if existingRecord != nil {
@@ -200,6 +229,9 @@ func IdempotencyMiddleware(config *IdempotencyConfig) gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			// The winner's record is already gone; don't run the request unguarded
+			c.AbortWithStatusJSON(http.StatusConflict, response.Error("REQUEST_IN_PROGRESS", "A request with this idempotency key is already being processed"))
+			return
 		}
 
 		// Create response writer to capture response
@@ -226,9 +258,9 @@ func IdempotencyMiddleware(config *IdempotencyConfig) gin.HandlerFunc {
 // RequireIdempotencyKey creates a middleware that enforces idempotency key presence
 func RequireIdempotencyKey() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := c.GetHeader(IdempotencyKeyHeader)
+		key := defaultKeyExtractor(c)
 		if key == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, response.Error("MISSING_IDEMPOTENCY_KEY", "X-Idempotency-Key header is required"))
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Error("MISSING_IDEMPOTENCY_KEY", "Idempotency-Key header is required"))
 			return
 		}
 		c.Set(ContextKeyIdempotencyKey, key)
@@ -317,18 +349,13 @@ func getIdempotencyRecord(ctx context.Context, redis RedisClient, key string) (*
 	return &record, nil
 }
 
-func trySetIdempotencyRecord(ctx context.Context, redis RedisClient, key string, record *IdempotencyRecord, ttl time.Duration) bool {
+func trySetIdempotencyRecord(ctx context.Context, redis RedisClient, key string, record *IdempotencyRecord, ttl time.Duration) (bool, error) {
 	data, err := json.Marshal(record)
 	if err != nil {
-		return false
-	}
-
-	result, err := redis.SetNX(ctx, key, string(data), ttl).Result()
-	if err != nil {
-		return false
+		return false, err
 	}
 
-	return result
+	return redis.SetNX(ctx, key, string(data), ttl).Result()
 }
 
 func saveIdempotencyRecord(ctx context.Context, redis RedisClient, key string, record *IdempotencyRecord, ttl time.Duration) error {
@@ -340,13 +367,15 @@ func saveIdempotencyRecord(ctx context.Context, redis RedisClient, key string, r
 	return redis.Set(ctx, key, string(data), ttl).Err()
 }
 
-// DeleteIdempotencyRecord deletes an idempotency record (for testing or cleanup)
+// DeleteIdempotencyRecord deletes an idempotency record (for testing or cleanup).
+// Records for authenticated requests are stored under IdempotencyRecordKey(userID, key).
 func DeleteIdempotencyRecord(ctx context.Context, redis RedisClient, idempotencyKey string) error {
 	redisKey := IdempotencyKeyPrefix + idempotencyKey
 	return redis.Del(ctx, redisKey).Err()
 }
 
-// CheckIdempotency checks if a request with the given key exists and returns its status
+// CheckIdempotency checks if a request with the given key exists and returns its status.
+// Records for authenticated requests are stored under IdempotencyRecordKey(userID, key).
 func CheckIdempotency(ctx context.Context, redis RedisClient, idempotencyKey string) (*IdempotencyRecord, error) {
 	redisKey := IdempotencyKeyPrefix + idempotencyKey
 	return getIdempotencyRecord(ctx, redis, redisKey)
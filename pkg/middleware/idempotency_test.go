@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,16 +16,41 @@ import (
 
 // MockRedisClient implements RedisClient interface for testing
 type MockRedisClient struct {
-	data map[string]string
+	mu        sync.Mutex
+	data      map[string]string
+	expiresAt map[string]time.Time
 }
 
 func NewMockRedisClient() *MockRedisClient {
 	return &MockRedisClient{
-		data: make(map[string]string),
+		data:      make(map[string]string),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+// expireLocked drops key if its TTL has passed; m.mu must be held
+func (m *MockRedisClient) expireLocked(key string) {
+	if at, ok := m.expiresAt[key]; ok && !time.Now().Before(at) {
+		delete(m.data, key)
+		delete(m.expiresAt, key)
+	}
+}
+
+// setLocked stores key with an optional TTL; m.mu must be held
+func (m *MockRedisClient) setLocked(key string, value interface{}, expiration time.Duration) {
+	m.data[key] = value.(string)
+	if expiration > 0 {
+		m.expiresAt[key] = time.Now().Add(expiration)
+	} else {
+		delete(m.expiresAt, key)
 	}
 }
 
 func (m *MockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked(key)
+
 	cmd := redis.NewStringCmd(ctx)
 	if val, ok := m.data[key]; ok {
 		cmd.SetVal(val)
@@ -35,29 +61,41 @@ func (m *MockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd
 }
 
 func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cmd := redis.NewStatusCmd(ctx)
-	m.data[key] = value.(string)
+	m.setLocked(key, value, expiration)
 	cmd.SetVal("OK")
 	return cmd
 }
 
 func (m *MockRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked(key)
+
 	cmd := redis.NewBoolCmd(ctx)
 	if _, ok := m.data[key]; ok {
 		cmd.SetVal(false)
 	} else {
-		m.data[key] = value.(string)
+		m.setLocked(key, value, expiration)
 		cmd.SetVal(true)
 	}
 	return cmd
 }
 
 func (m *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cmd := redis.NewIntCmd(ctx)
 	count := int64(0)
 	for _, key := range keys {
+		m.expireLocked(key)
 		if _, ok := m.data[key]; ok {
 			delete(m.data, key)
+			delete(m.expiresAt, key)
 			count++
 		}
 	}
@@ -66,7 +104,10 @@ func (m *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd
 }
 
 func (m *MockRedisClient) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.data = make(map[string]string)
+	m.expiresAt = make(map[string]time.Time)
 }
 
 func setupIdempotencyTestRouter() *gin.Engine {
@@ -623,3 +664,124 @@ func TestIdempotencyMiddleware_DifferentErrorResponses(t *testing.T) {
 		t.Errorf("Responses should be identical. First: %s, Second: %s", w1.Body.String(), w2.Body.String())
 	}
 }
+
+func TestIdempotency_InFlightConflict(t *testing.T) {
+	mockRedis := NewMockRedisClient()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int
+	router := setupIdempotencyTestRouter()
+	router.POST("/payments", Idempotency(mockRedis), func(c *gin.Context) {
+		calls++
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"payment_id": "pay-1"})
+	})
+
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequest("POST", "/payments", bytes.NewBuffer([]byte(`{"amount":100}`)))
+		req.Header.Set(StandardIdempotencyKeyHeader, "pay-key-1")
+		return req
+	}
+
+	w1 := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w1, newRequest())
+	}()
+	<-started
+
+	// A duplicate while the first is still running must not execute
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, newRequest())
+	if w2.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 while in flight, got %d", w2.Code)
+	}
+
+	close(release)
+	<-done
+	if w1.Code != http.StatusCreated {
+		t.Errorf("Expected first request status 201, got %d", w1.Code)
+	}
+
+	// Once completed, duplicates replay the stored response
+	w3 := httptest.NewRecorder()
+	router.ServeHTTP(w3, newRequest())
+	if w3.Code != http.StatusCreated || w3.Body.String() != w1.Body.String() {
+		t.Errorf("Expected replay of %d %s, got %d %s", w1.Code, w1.Body.String(), w3.Code, w3.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("Handler should be called only once, but was called %d times", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_TTLExpiry(t *testing.T) {
+	mockRedis := NewMockRedisClient()
+	config := DefaultIdempotencyConfig(mockRedis)
+	config.TTL = 20 * time.Millisecond
+
+	calls := 0
+	router := setupIdempotencyTestRouter()
+	router.POST("/test", IdempotencyMiddleware(config), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"call": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer([]byte(`{}`)))
+		req.Header.Set(IdempotencyKeyHeader, "ttl-key-123")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send()
+	if replay := send(); replay.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay within TTL, got %s", replay.Body.String())
+	}
+
+	// After the TTL the key is free and the request runs again
+	time.Sleep(40 * time.Millisecond)
+	if again := send(); again.Body.String() == first.Body.String() {
+		t.Error("Expected the request to run again after the TTL")
+	}
+	if calls != 2 {
+		t.Errorf("Expected handler to run twice, got %d", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_KeyScopedToUser(t *testing.T) {
+	mockRedis := NewMockRedisClient()
+
+	router := setupIdempotencyTestRouter()
+	router.POST("/test", func(c *gin.Context) {
+		c.Set(ContextKeyUserID, c.GetHeader("X-User-ID"))
+		c.Next()
+	}, Idempotency(mockRedis), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString(ContextKeyUserID)})
+	})
+
+	send := func(userID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer([]byte(`{}`)))
+		req.Header.Set(IdempotencyKeyHeader, "shared-key")
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The same key from two users runs once per user instead of colliding
+	w1 := send("user-1")
+	w2 := send("user-2")
+	if w1.Code != http.StatusOK || w2.Code != http.StatusOK {
+		t.Fatalf("Expected both users to get 200, got %d and %d", w1.Code, w2.Code)
+	}
+	if w1.Body.String() == w2.Body.String() {
+		t.Error("Expected each user to get their own response")
+	}
+	if _, err := CheckIdempotency(context.Background(), mockRedis, IdempotencyRecordKey("user-1", "shared-key")); err != nil {
+		t.Errorf("Expected a record under the user-scoped key: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultJWKSRefreshInterval is how long fetched JWKS keys are cached
+	DefaultJWKSRefreshInterval = 10 * time.Minute
+	// jwksMinRefetchInterval limits refetches triggered by unknown kids
+	jwksMinRefetchInterval = 30 * time.Second
+	// jwksFetchTimeout bounds a single JWKS request
+	jwksFetchTimeout = 5 * time.Second
+)
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// jwk is a single JSON Web Key (RFC 7517); only the RSA and EC fields are used
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksKeySet caches the public keys served at a JWKS URL, keyed by kid
+type jwksKeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	// group collapses concurrent refreshes into a single fetch
+	group singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time // last fetch, successful or not
+}
+
+// newJWKSKeySet creates a key set that refetches keys every refreshInterval
+func newJWKSKeySet(url string, refreshInterval time.Duration) *jwksKeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+	return &jwksKeySet{
+		url:             url,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]interface{}),
+	}
+}
+
+// key returns the public key for kid, refreshing the cache when it is stale
+// or when an unknown kid suggests the IdP has rotated its keys
+func (s *jwksKeySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.RLock()
+	key, found := s.keys[kid]
+	age := time.Since(s.fetchedAt)
+	sinceAttempt := time.Since(s.attemptedAt)
+	s.mu.RUnlock()
+
+	if found && age < s.refreshInterval {
+		return key, nil
+	}
+	// Unknown kids are attacker-controlled, so they may only trigger a fetch
+	// once per jwksMinRefetchInterval, even while the IdP is failing
+	if found || sinceAttempt >= jwksMinRefetchInterval {
+		if err := s.refresh(ctx); err != nil && !found {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, found := s.keys[kid]; found {
+		return key, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// refresh fetches the JWKS document once for all concurrent callers. The shared
+// fetch is detached from the caller that started it, so a cancelled request
+// doesn't fail the others; each caller still stops waiting when its ctx ends.
+func (s *jwksKeySet) refresh(ctx context.Context) error {
+	result := s.group.DoChan("jwks", func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+		defer cancel()
+		return nil, s.fetch(fetchCtx)
+	})
+
+	select {
+	case res := <-result:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch downloads the JWKS document and replaces the cached keys
+func (s *jwksKeySet) fetch(ctx context.Context) error {
+	// Recorded when the fetch ends so lookups arriving meanwhile join it
+	defer func() {
+		s.mu.Lock()
+		s.attemptedAt = time.Now()
+		s.mu.Unlock()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip keys we can't use rather than rejecting the whole set
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// publicKey decodes the JWK into an *rsa.PublicKey or *ecdsa.PublicKey
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeJWKInt decodes a base64url-encoded big-endian integer
+func decodeJWKInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK integer: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// newKeyFunc returns the key lookup for the configured verification mode:
+// JWKS public keys selected by kid, or the shared HMAC secret. JWKS fetches
+// made during a lookup are bound to the request ctx.
+func newKeyFunc(config *JWTConfig) func(ctx context.Context) jwt.Keyfunc {
+	if config.JWKSURL != "" {
+		keys := newJWKSKeySet(config.JWKSURL, config.JWKSRefreshInterval)
+		return func(ctx context.Context) jwt.Keyfunc {
+			return func(token *jwt.Token) (interface{}, error) {
+				kid, _ := token.Header["kid"].(string)
+				return keys.key(ctx, kid)
+			}
+		}
+	}
+
+	hmacKey := func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(config.Secret), nil
+	}
+	return func(ctx context.Context) jwt.Keyfunc {
+		return hmacKey
+	}
+}
+
+// parserOptions pins the accepted alg so a token cannot pick its own verification method,
+// and adds the configured claim checks
+func (config *JWTConfig) parserOptions() []jwt.ParserOption {
+	options := config.claimOptions()
+
+	algorithm := config.Algorithm
+	if algorithm == "" && config.JWKSURL != "" {
+		algorithm = "RS256"
+	}
+	if algorithm != "" {
+		options = append(options, jwt.WithValidMethods([]string{algorithm}))
+	}
+	return options
+}
+
+// claimOptions validates iss and aud when configured and tolerates
+// clock skew between services when checking exp and nbf
+func (config *JWTConfig) claimOptions() []jwt.ParserOption {
+	var options []jwt.ParserOption
+	if config.Leeway > 0 {
+		options = append(options, jwt.WithLeeway(config.Leeway))
+	}
+	if config.ExpectedIssuer != "" {
+		options = append(options, jwt.WithIssuer(config.ExpectedIssuer))
+	}
+	if config.ExpectedAudience != "" {
+		options = append(options, jwt.WithAudience(config.ExpectedAudience))
+	}
+	return options
+}
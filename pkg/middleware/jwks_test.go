@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newJWKSServer serves the given keys as a JWKS document and counts fetches
+func newJWKSServer(t *testing.T, keys []map[string]string) (*httptest.Server, *atomic.Int32) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func encodeJWKInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   encodeJWKInt(key.N),
+		"e":   encodeJWKInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func signWithKid(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func requestWithToken(config *JWTConfig, token string) int {
+	router := setupTestRouter(config)
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestJWTMiddlewareJWKS_RS256(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server, fetches := newJWKSServer(t, []map[string]string{rsaJWK("key-1", &rsaKey.PublicKey)})
+
+	config := &JWTConfig{JWKSURL: server.URL, Algorithm: "RS256"}
+	validClaims := jwt.MapClaims{"user_id": "user-123", "exp": time.Now().Add(time.Hour).Unix()}
+
+	t.Run("valid RS256 token", func(t *testing.T) {
+		token := signWithKid(t, jwt.SigningMethodRS256, "key-1", rsaKey, validClaims)
+		if code := requestWithToken(config, token); code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", code)
+		}
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		token := signWithKid(t, jwt.SigningMethodRS256, "key-2", rsaKey, validClaims)
+		if code := requestWithToken(config, token); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", code)
+		}
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		token := signWithKid(t, jwt.SigningMethodRS256, "key-1", otherKey, validClaims)
+		if code := requestWithToken(config, token); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", code)
+		}
+	})
+
+	t.Run("alg confusion with HS256", func(t *testing.T) {
+		// HMAC keyed with the public modulus must not verify against an RSA-configured middleware
+		token := signWithKid(t, jwt.SigningMethodHS256, "key-1", rsaKey.PublicKey.N.Bytes(), validClaims)
+		if code := requestWithToken(config, token); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", code)
+		}
+	})
+
+	t.Run("alg mismatch with RS384", func(t *testing.T) {
+		token := signWithKid(t, jwt.SigningMethodRS384, "key-1", rsaKey, validClaims)
+		if code := requestWithToken(config, token); code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", code)
+		}
+	})
+
+	if fetches.Load() == 0 {
+		t.Error("Expected JWKS to be fetched")
+	}
+}
+
+func TestJWTMiddlewareJWKS_ES256(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server, _ := newJWKSServer(t, []map[string]string{{
+		"kty": "EC",
+		"kid": "ec-1",
+		"crv": "P-256",
+		"x":   encodeJWKInt(ecKey.X),
+		"y":   encodeJWKInt(ecKey.Y),
+	}})
+
+	config := &JWTConfig{JWKSURL: server.URL, Algorithm: "ES256"}
+	token := signWithKid(t, jwt.SigningMethodES256, "ec-1", ecKey, jwt.MapClaims{
+		"user_id": "user-123",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+
+	if code := requestWithToken(config, token); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+}
+
+func TestJWKSKeySetCachesAndRefreshes(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server, fetches := newJWKSServer(t, []map[string]string{rsaJWK("key-1", &rsaKey.PublicKey)})
+
+	keys := newJWKSKeySet(server.URL, time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := keys.key(t.Context(), "key-1"); err != nil {
+			t.Fatalf("Expected key, got %v", err)
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("Expected keys to be cached after one fetch, got %d fetches", fetches.Load())
+	}
+
+	// Stale keys are refetched
+	keys.mu.Lock()
+	keys.fetchedAt = time.Now().Add(-2 * time.Hour)
+	keys.mu.Unlock()
+	if _, err := keys.key(t.Context(), "key-1"); err != nil {
+		t.Fatalf("Expected key after refresh, got %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("Expected stale keys to be refetched, got %d fetches", fetches.Load())
+	}
+
+	// Unknown kids don't trigger a refetch storm
+	keys.key(t.Context(), "missing")
+	keys.key(t.Context(), "missing")
+	if fetches.Load() != 2 {
+		t.Errorf("Expected unknown kids within the refetch interval to use the cache, got %d fetches", fetches.Load())
+	}
+}
+
+func TestJWKSKeySetCollapsesConcurrentRefreshes(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{rsaJWK("key-1", &rsaKey.PublicKey)}})
+	}))
+	t.Cleanup(server.Close)
+
+	keys := newJWKSKeySet(server.URL, time.Hour)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := keys.key(t.Context(), "key-1"); err != nil {
+				t.Errorf("Expected key, got %v", err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if fetches.Load() != 1 {
+		t.Errorf("Expected concurrent lookups to share one fetch, got %d fetches", fetches.Load())
+	}
+}
+
+func TestJWKSKeySetStopsWaitingWhenRequestIsCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	keys := newJWKSKeySet(server.URL, time.Hour)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := keys.key(ctx, "key-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the lookup to end with the request ctx, got %v", err)
+	}
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -31,10 +33,33 @@ type JWTConfig struct {
 	Secret string
 	// SkipPaths is a list of paths that should skip JWT validation
 	SkipPaths []string
+	// AccessTokenTTL is the lifetime of issued access tokens (default: 15 minutes)
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of issued refresh tokens (default: 7 days)
+	RefreshTokenTTL time.Duration
+	// RevocationList stores the jti of revoked refresh tokens (nil disables revocation)
+	RevocationList RevocationClient
+	// JWKSURL enables asymmetric verification with keys from an external IdP (selected by kid)
+	JWKSURL string
+	// JWKSRefreshInterval is how long fetched keys are cached (default: 10 minutes)
+	JWKSRefreshInterval time.Duration
+	// Algorithm is the only accepted signing alg, e.g. "RS256" or "ES256"
+	// (default: any HMAC with Secret, or RS256 when JWKSURL is set)
+	Algorithm string
+	// Blacklist rejects access tokens revoked before expiry; tokens without a jti are rejected when set
+	Blacklist *TokenBlacklist
+	// Leeway tolerates clock skew when validating exp and nbf (default: 0)
+	Leeway time.Duration
+	// ExpectedIssuer, when set, rejects tokens whose iss claim differs or is missing
+	ExpectedIssuer string
+	// ExpectedAudience, when set, rejects tokens whose aud claim doesn't include it
+	ExpectedAudience string
 }
 
 // JWTMiddleware creates a new JWT validation middleware
 func JWTMiddleware(config *JWTConfig) gin.HandlerFunc {
+	auth := newAuthenticator(config)
+
 	return func(c *gin.Context) {
 		// Check if path should skip JWT validation
 		for _, path := range config.SkipPaths {
@@ -44,75 +69,157 @@ func JWTMiddleware(config *JWTConfig) gin.HandlerFunc {
 			}
 		}
 
-		// Get Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("MISSING_TOKEN", "Authorization header is required"))
+		if failure := auth.authenticate(c); failure != nil {
+			c.AbortWithStatusJSON(failure.status, response.Error(failure.code, failure.message))
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		const bearerPrefix = "Bearer "
-		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Invalid authorization header format"))
-			return
-		}
-		tokenString := authHeader[len(bearerPrefix):]
+		c.Next()
+	}
+}
 
-		if tokenString == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Token is empty"))
+// OptionalAuth creates a middleware that populates the user context when a valid token
+// is supplied and otherwise continues anonymously. Expired tokens are still rejected so
+// a client with a stale session learns to refresh instead of silently losing identity.
+func OptionalAuth(config *JWTConfig) gin.HandlerFunc {
+	auth := newAuthenticator(config)
+
+	return func(c *gin.Context) {
+		if failure := auth.authenticate(c); failure != nil && failure.code == "TOKEN_EXPIRED" {
+			c.AbortWithStatusJSON(failure.status, response.Error(failure.code, failure.message))
 			return
 		}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, ErrInvalidToken
-			}
-			return []byte(config.Secret), nil
-		})
+		c.Next()
+	}
+}
 
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("TOKEN_EXPIRED", "Access token has expired"))
-				return
-			}
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Invalid access token"))
-			return
-		}
+// Identify creates a middleware that populates the user context when a valid token is
+// supplied and never rejects a request. It lets middleware that runs before routing, such
+// as rate limiting, see who is calling; routes still authenticate on their own.
+func Identify(config *JWTConfig) gin.HandlerFunc {
+	auth := newAuthenticator(config)
 
-		if !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Invalid access token"))
-			return
-		}
+	return func(c *gin.Context) {
+		auth.authenticate(c)
+		c.Next()
+	}
+}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Invalid token claims"))
-			return
+// authFailure describes why a request could not be authenticated
+type authFailure struct {
+	status  int
+	code    string
+	message string
+}
+
+// unauthorized creates a 401 authFailure
+func unauthorized(code, message string) *authFailure {
+	return &authFailure{status: http.StatusUnauthorized, code: code, message: message}
+}
+
+// authenticator validates bearer tokens against a JWTConfig
+type authenticator struct {
+	config        *JWTConfig
+	keyFunc       func(ctx context.Context) jwt.Keyfunc
+	parserOptions []jwt.ParserOption
+}
+
+// newAuthenticator prepares key lookup and parser options for config
+func newAuthenticator(config *JWTConfig) *authenticator {
+	return &authenticator{
+		config:        config,
+		keyFunc:       newKeyFunc(config),
+		parserOptions: config.parserOptions(),
+	}
+}
+
+// authenticate validates the request's bearer token and injects the user context.
+// It returns nil on success and leaves the context untouched on failure.
+func (a *authenticator) authenticate(c *gin.Context) *authFailure {
+	// Get Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return unauthorized("MISSING_TOKEN", "Authorization header is required")
+	}
+
+	// Extract token from "Bearer <token>"
+	const bearerPrefix = "Bearer "
+	if !strings.HasPrefix(authHeader, bearerPrefix) {
+		return unauthorized("INVALID_TOKEN", "Invalid authorization header format")
+	}
+	tokenString := authHeader[len(bearerPrefix):]
+
+	if tokenString == "" {
+		return unauthorized("INVALID_TOKEN", "Token is empty")
+	}
+
+	// Parse and validate token
+	token, err := jwt.Parse(tokenString, a.keyFunc(c.Request.Context()), a.parserOptions...)
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return unauthorized("TOKEN_EXPIRED", "Access token has expired")
 		}
+		return unauthorized("INVALID_TOKEN", "Invalid access token")
+	}
 
-		// Extract and validate required claims
-		userID, ok := claims["user_id"].(string)
-		if !ok || userID == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Missing user_id in token"))
-			return
+	if !token.Valid {
+		return unauthorized("INVALID_TOKEN", "Invalid access token")
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return unauthorized("INVALID_TOKEN", "Invalid token claims")
+	}
+
+	// Refresh tokens are only accepted by the refresh endpoint
+	if tokenType, _ := claims[ClaimTokenType].(string); tokenType == TokenTypeRefresh {
+		return unauthorized("INVALID_TOKEN", "Refresh token cannot be used as an access token")
+	}
+
+	// Extract and validate required claims
+	userID, ok := claims["user_id"].(string)
+	if !ok || userID == "" {
+		return unauthorized("INVALID_TOKEN", "Missing user_id in token")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if a.config.Blacklist != nil {
+		if jti == "" {
+			return unauthorized("INVALID_TOKEN", "Missing jti in token")
 		}
+		revoked, err := a.config.Blacklist.IsRevoked(c.Request.Context(), jti)
+		if err != nil {
+			return &authFailure{status: http.StatusServiceUnavailable, code: response.ErrCodeServiceUnavailable, message: "Unable to verify token"}
+		}
+		if revoked {
+			return unauthorized("TOKEN_REVOKED", "Access token has been revoked")
+		}
+	}
 
-		email, _ := claims["email"].(string)
-		role, _ := claims["role"].(string)
-		tenantID, _ := claims["tenant_id"].(string)
+	scopes, ok := parseScopes(claims["scopes"])
+	if !ok {
+		return unauthorized("INVALID_TOKEN", "Invalid scopes in token")
+	}
 
-		// Inject user context into request
-		c.Set(ContextKeyUserID, userID)
-		c.Set(ContextKeyEmail, email)
-		c.Set(ContextKeyRole, role)
-		c.Set(ContextKeyTenantID, tenantID)
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+	tenantID, _ := claims["tenant_id"].(string)
 
-		c.Next()
+	// Inject user context into request
+	c.Set(ContextKeyUserID, userID)
+	c.Set(ContextKeyEmail, email)
+	c.Set(ContextKeyRole, role)
+	c.Set(ContextKeyTenantID, tenantID)
+	c.Set(ContextKeyScopes, scopes)
+	c.Set(ContextKeyTokenID, jti)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		c.Set(ContextKeyTokenExpiresAt, exp.Time)
 	}
+
+	return nil
 }
 
 // RequireRole creates a middleware that checks if user has required role
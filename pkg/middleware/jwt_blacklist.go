@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenBlacklistKeyPrefix is the Redis key prefix for revoked access tokens
+const TokenBlacklistKeyPrefix = "jwt:blacklist:"
+
+// Context keys for the authenticated token, set by JWTMiddleware
+const (
+	ContextKeyTokenID        = "token_id"
+	ContextKeyTokenExpiresAt = "token_expires_at"
+)
+
+// TokenBlacklist invalidates access tokens before they expire (e.g., on logout).
+// Entries are keyed on the token's jti and expire together with the token.
+type TokenBlacklist struct {
+	redis RedisClient
+}
+
+// NewTokenBlacklist creates a Redis-backed token blacklist
+func NewTokenBlacklist(redis RedisClient) *TokenBlacklist {
+	return &TokenBlacklist{redis: redis}
+}
+
+// RevokeToken blacklists jti for the token's remaining lifetime
+func (b *TokenBlacklist) RevokeToken(ctx context.Context, jti string, exp time.Time) error {
+	if jti == "" {
+		return ErrInvalidToken
+	}
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		// Already expired; the signature check rejects it anyway
+		return nil
+	}
+	if err := b.redis.Set(ctx, TokenBlacklistKeyPrefix+jti, "1", ttl).Err(); err != nil {
+		return fmt.Errorf("failed to blacklist token: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether jti has been blacklisted
+func (b *TokenBlacklist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	err := b.redis.Get(ctx, TokenBlacklistKeyPrefix+jti).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check token blacklist: %w", err)
+	}
+	return true, nil
+}
+
+// LogoutHandler returns a handler that blacklists the caller's access token.
+// It must run after JWTMiddleware with a Blacklist configured.
+func LogoutHandler(config *JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jti, _ := GetTokenID(c)
+		exp, _ := c.Get(ContextKeyTokenExpiresAt)
+		expiresAt, _ := exp.(time.Time)
+
+		if config.Blacklist == nil || jti == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("UNAUTHORIZED", "User not authenticated"))
+			return
+		}
+
+		if err := config.Blacklist.RevokeToken(c.Request.Context(), jti, expiresAt); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.InternalError("Failed to revoke token"))
+			return
+		}
+
+		c.JSON(http.StatusOK, response.Success(gin.H{"message": "Logged out successfully"}))
+	}
+}
+
+// GetTokenID extracts the authenticated token's jti from gin context
+func GetTokenID(c *gin.Context) (string, bool) {
+	jti, exists := c.Get(ContextKeyTokenID)
+	if !exists {
+		return "", false
+	}
+	id, ok := jti.(string)
+	return id, ok
+}
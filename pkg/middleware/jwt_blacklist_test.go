@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// expiringRedisClient implements RedisClient with per-key expiry against a controllable clock
+type expiringRedisClient struct {
+	data      map[string]string
+	expiresAt map[string]time.Time
+	now       time.Time
+}
+
+func newExpiringRedisClient() *expiringRedisClient {
+	return &expiringRedisClient{
+		data:      make(map[string]string),
+		expiresAt: make(map[string]time.Time),
+		now:       time.Now(),
+	}
+}
+
+func (m *expiringRedisClient) live(key string) bool {
+	exp, ok := m.expiresAt[key]
+	return !ok || m.now.Before(exp)
+}
+
+func (m *expiringRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx)
+	if val, ok := m.data[key]; ok && m.live(key) {
+		cmd.SetVal(val)
+	} else {
+		cmd.SetErr(redis.Nil)
+	}
+	return cmd
+}
+
+func (m *expiringRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	cmd := redis.NewStatusCmd(ctx)
+	m.data[key] = value.(string)
+	if expiration > 0 {
+		m.expiresAt[key] = m.now.Add(expiration)
+	}
+	cmd.SetVal("OK")
+	return cmd
+}
+
+func (m *expiringRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(ctx)
+	if _, ok := m.data[key]; ok && m.live(key) {
+		cmd.SetVal(false)
+		return cmd
+	}
+	m.Set(ctx, key, value, expiration)
+	cmd.SetVal(true)
+	return cmd
+}
+
+func (m *expiringRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	for _, key := range keys {
+		delete(m.data, key)
+		delete(m.expiresAt, key)
+	}
+	cmd.SetVal(int64(len(keys)))
+	return cmd
+}
+
+func setupBlacklistTestRouter(config *JWTConfig) *gin.Engine {
+	router := setupTestRouter(config)
+	router.POST("/logout", JWTMiddleware(config), LogoutHandler(config))
+	return router
+}
+
+func serveWithToken(router *gin.Engine, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestJWTMiddlewareBlacklist_LogoutRevokesToken(t *testing.T) {
+	store := newExpiringRedisClient()
+	config := &JWTConfig{Secret: testSecret, Blacklist: NewTokenBlacklist(store)}
+	router := setupBlacklistTestRouter(config)
+
+	token := generateTestToken(jwt.MapClaims{
+		"user_id": "user-123",
+		"jti":     "token-1",
+		"exp":     time.Now().Add(10 * time.Minute).Unix(),
+	}, testSecret)
+
+	if code := serveWithToken(router, http.MethodGet, "/protected", token); code != http.StatusOK {
+		t.Fatalf("Expected status 200 before logout, got %d", code)
+	}
+	if code := serveWithToken(router, http.MethodPost, "/logout", token); code != http.StatusOK {
+		t.Fatalf("Expected logout to succeed, got %d", code)
+	}
+	if code := serveWithToken(router, http.MethodGet, "/protected", token); code != http.StatusUnauthorized {
+		t.Errorf("Expected blacklisted token to be rejected, got %d", code)
+	}
+
+	// Other tokens for the same user are unaffected
+	other := generateTestToken(jwt.MapClaims{
+		"user_id": "user-123",
+		"jti":     "token-2",
+		"exp":     time.Now().Add(10 * time.Minute).Unix(),
+	}, testSecret)
+	if code := serveWithToken(router, http.MethodGet, "/protected", other); code != http.StatusOK {
+		t.Errorf("Expected other token to be accepted, got %d", code)
+	}
+}
+
+func TestJWTMiddlewareBlacklist_RequiresJTI(t *testing.T) {
+	config := &JWTConfig{Secret: testSecret, Blacklist: NewTokenBlacklist(newExpiringRedisClient())}
+	router := setupTestRouter(config)
+
+	token := generateTestToken(jwt.MapClaims{
+		"user_id": "user-123",
+		"exp":     time.Now().Add(10 * time.Minute).Unix(),
+	}, testSecret)
+
+	if code := serveWithToken(router, http.MethodGet, "/protected", token); code != http.StatusUnauthorized {
+		t.Errorf("Expected token without jti to be rejected, got %d", code)
+	}
+}
+
+func TestTokenBlacklist_TTLMatchesTokenLifetime(t *testing.T) {
+	ctx := context.Background()
+	store := newExpiringRedisClient()
+	blacklist := NewTokenBlacklist(store)
+
+	if err := blacklist.RevokeToken(ctx, "token-1", time.Now().Add(5*time.Minute)); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	ttl := store.expiresAt[TokenBlacklistKeyPrefix+"token-1"].Sub(store.now)
+	if ttl <= 4*time.Minute || ttl > 5*time.Minute {
+		t.Errorf("Expected TTL close to 5m, got %v", ttl)
+	}
+
+	if revoked, _ := blacklist.IsRevoked(ctx, "token-1"); !revoked {
+		t.Error("Expected token to be revoked")
+	}
+
+	// The entry disappears once the token would have expired anyway
+	store.now = store.now.Add(6 * time.Minute)
+	if revoked, _ := blacklist.IsRevoked(ctx, "token-1"); revoked {
+		t.Error("Expected blacklist entry to expire with the token")
+	}
+
+	// Already-expired tokens are not stored
+	if err := blacklist.RevokeToken(ctx, "token-2", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if _, ok := store.data[TokenBlacklistKeyPrefix+"token-2"]; ok {
+		t.Error("Expected expired token not to be blacklisted")
+	}
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ClaimTokenType distinguishes refresh tokens from access tokens
+	ClaimTokenType = "token_type"
+	// TokenTypeRefresh is the token_type claim value of refresh tokens
+	TokenTypeRefresh = "refresh"
+	// RevokedRefreshTokenKeyPrefix prefixes the per-jti Redis key marking a refresh
+	// token as revoked (jwt:revoked_refresh_token:{jti}); each key expires with its token
+	RevokedRefreshTokenKeyPrefix = "jwt:revoked_refresh_token:"
+	// Default token lifetimes
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrNotRefreshToken = errors.New("not a refresh token")
+	ErrTokenRevoked    = errors.New("token revoked")
+)
+
+// RevocationClient is the subset of Redis operations used by the refresh token revocation list
+type RevocationClient interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Exists(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// UserClaims are the user attributes carried by access and refresh tokens
+type UserClaims struct {
+	UserID   string
+	Email    string
+	Role     string
+	TenantID string
+}
+
+// TokenPair is a newly issued access token and refresh token
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// IssueTokenPair signs a new access token and refresh token for the user
+func IssueTokenPair(config *JWTConfig, user UserClaims) (*TokenPair, error) {
+	now := time.Now()
+	accessTTL := config.accessTokenTTL()
+
+	accessToken, err := signToken(config, jwt.MapClaims{
+		"sub":       user.UserID,
+		"user_id":   user.UserID,
+		"email":     user.Email,
+		"role":      user.Role,
+		"tenant_id": user.TenantID,
+		"jti":       uuid.New().String(),
+		"exp":       now.Add(accessTTL).Unix(),
+		"iat":       now.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := signToken(config, jwt.MapClaims{
+		"sub":          user.UserID,
+		"user_id":      user.UserID,
+		"email":        user.Email,
+		"role":         user.Role,
+		"tenant_id":    user.TenantID,
+		"jti":          uuid.New().String(),
+		ClaimTokenType: TokenTypeRefresh,
+		"exp":          now.Add(config.refreshTokenTTL()).Unix(),
+		"iat":          now.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	}, nil
+}
+
+// ValidateRefreshToken parses a refresh token and checks it has not been revoked
+func ValidateRefreshToken(ctx context.Context, config *JWTConfig, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(config.Secret), nil
+	}, config.claimOptions()...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if tokenType, _ := claims[ClaimTokenType].(string); tokenType != TokenTypeRefresh {
+		return nil, ErrNotRefreshToken
+	}
+	jti, _ := claims["jti"].(string)
+	userID, _ := claims["user_id"].(string)
+	if jti == "" || userID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if config.RevocationList != nil {
+		revoked, err := config.RevocationList.Exists(ctx, RevokedRefreshTokenKeyPrefix+jti).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check revocation list: %w", err)
+		}
+		if revoked > 0 {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeRefreshToken revokes the refresh token with the given jti until it
+// expires at expiresAt. Revoking an already revoked token is a no-op.
+func RevokeRefreshToken(ctx context.Context, config *JWTConfig, jti string, expiresAt time.Time) error {
+	_, err := claimRefreshToken(ctx, config, jti, expiresAt)
+	return err
+}
+
+// claimRefreshToken revokes a refresh token and reports whether this call did
+// so, i.e. whether the caller is the token's first and only redeemer. The
+// revocation key lives as long as the token, after which it is rejected as expired anyway.
+func claimRefreshToken(ctx context.Context, config *JWTConfig, jti string, expiresAt time.Time) (bool, error) {
+	if config.RevocationList == nil {
+		return true, nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	claimed, err := config.RevocationList.SetNX(ctx, RevokedRefreshTokenKeyPrefix+jti, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return claimed, nil
+}
+
+// RotateRefreshToken exchanges a valid refresh token for a new token pair and revokes the old one,
+// so each refresh token can be used only once. Of concurrent rotations of the same token only
+// the first to revoke it succeeds.
+func RotateRefreshToken(ctx context.Context, config *JWTConfig, refreshToken string) (*TokenPair, error) {
+	claims, err := ValidateRefreshToken(ctx, config, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(config.refreshTokenTTL())
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+	claimed, err := claimRefreshToken(ctx, config, claims["jti"].(string), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrTokenRevoked
+	}
+
+	user := UserClaims{UserID: claims["user_id"].(string)}
+	user.Email, _ = claims["email"].(string)
+	user.Role, _ = claims["role"].(string)
+	user.TenantID, _ = claims["tenant_id"].(string)
+
+	return IssueTokenPair(config, user)
+}
+
+// RefreshTokenRequest is the body accepted by RefreshTokenHandler
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshTokenHandler returns a handler that rotates a refresh token into a new token pair
+func RefreshTokenHandler(config *JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.BadRequest("refresh_token is required"))
+			return
+		}
+
+		pair, err := RotateRefreshToken(c.Request.Context(), config, req.RefreshToken)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusOK, response.Success(pair))
+		case errors.Is(err, ErrTokenExpired):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("TOKEN_EXPIRED", "Refresh token has expired"))
+		case errors.Is(err, ErrTokenRevoked):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("TOKEN_REVOKED", "Refresh token has been revoked"))
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrNotRefreshToken):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("INVALID_TOKEN", "Invalid refresh token"))
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.InternalError("Failed to refresh token"))
+		}
+	}
+}
+
+// signToken signs claims with the configured HMAC secret, stamping the expected issuer and audience
+func signToken(config *JWTConfig, claims jwt.MapClaims) (string, error) {
+	if config.ExpectedIssuer != "" {
+		claims["iss"] = config.ExpectedIssuer
+	}
+	if config.ExpectedAudience != "" {
+		claims["aud"] = config.ExpectedAudience
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.Secret))
+}
+
+// accessTokenTTL returns the configured access token lifetime or the default
+func (config *JWTConfig) accessTokenTTL() time.Duration {
+	if config.AccessTokenTTL > 0 {
+		return config.AccessTokenTTL
+	}
+	return DefaultAccessTokenTTL
+}
+
+// refreshTokenTTL returns the configured refresh token lifetime or the default
+func (config *JWTConfig) refreshTokenTTL() time.Duration {
+	if config.RefreshTokenTTL > 0 {
+		return config.RefreshTokenTTL
+	}
+	return DefaultRefreshTokenTTL
+}
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// mockRevocationClient implements RevocationClient with in-memory keys
+type mockRevocationClient struct {
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+func newMockRevocationClient() *mockRevocationClient {
+	return &mockRevocationClient{ttls: make(map[string]time.Duration)}
+}
+
+func (m *mockRevocationClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cmd := redis.NewBoolCmd(ctx)
+	if _, exists := m.ttls[key]; exists {
+		cmd.SetVal(false)
+		return cmd
+	}
+	m.ttls[key] = expiration
+	cmd.SetVal(true)
+	return cmd
+}
+
+func (m *mockRevocationClient) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cmd := redis.NewIntCmd(ctx)
+	var n int64
+	for _, key := range keys {
+		if _, exists := m.ttls[key]; exists {
+			n++
+		}
+	}
+	cmd.SetVal(n)
+	return cmd
+}
+
+func (m *mockRevocationClient) ttl(key string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ttls[key]
+}
+
+func newRefreshTestConfig() *JWTConfig {
+	return &JWTConfig{
+		Secret:          testSecret,
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		RevocationList:  newMockRevocationClient(),
+	}
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	config := newRefreshTestConfig()
+
+	pair, err := IssueTokenPair(config, UserClaims{UserID: "user-123", Email: "test@example.com", Role: "customer", TenantID: "tenant-1"})
+	if err != nil {
+		t.Fatalf("IssueTokenPair failed: %v", err)
+	}
+	if pair.ExpiresIn != 60 {
+		t.Errorf("Expected ExpiresIn 60, got %d", pair.ExpiresIn)
+	}
+
+	rotated, err := RotateRefreshToken(ctx, config, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken failed: %v", err)
+	}
+	if rotated.RefreshToken == pair.RefreshToken {
+		t.Error("Expected a new refresh token")
+	}
+
+	// The new access token carries the user's claims and passes the middleware
+	router := setupTestRouter(config)
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+rotated.AccessToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected rotated access token to be accepted, got %d", w.Code)
+	}
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["user_id"] != "user-123" || body["tenant_id"] != "tenant-1" {
+		t.Errorf("Expected user claims to carry over, got %v", body)
+	}
+
+	// The old refresh token is single use
+	if _, err := RotateRefreshToken(ctx, config, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked on reuse, got %v", err)
+	}
+	// The revocation expires with the token rather than living on forever
+	oldClaims, _, _ := jwt.NewParser().ParseUnverified(pair.RefreshToken, jwt.MapClaims{})
+	jti := oldClaims.Claims.(jwt.MapClaims)["jti"].(string)
+	if ttl := config.RevocationList.(*mockRevocationClient).ttl(RevokedRefreshTokenKeyPrefix + jti); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("Expected revocation TTL of the token's remaining ~1h lifetime, got %v", ttl)
+	}
+
+	// The new refresh token still works
+	if _, err := ValidateRefreshToken(ctx, config, rotated.RefreshToken); err != nil {
+		t.Errorf("Expected rotated refresh token to be valid, got %v", err)
+	}
+}
+
+func TestValidateRefreshTokenRejectsRevoked(t *testing.T) {
+	ctx := context.Background()
+	config := newRefreshTestConfig()
+
+	pair, _ := IssueTokenPair(config, UserClaims{UserID: "user-123"})
+	claims, err := ValidateRefreshToken(ctx, config, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken failed: %v", err)
+	}
+
+	if err := RevokeRefreshToken(ctx, config, claims["jti"].(string), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeRefreshToken failed: %v", err)
+	}
+	if _, err := ValidateRefreshToken(ctx, config, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestRotateRefreshTokenConcurrentReuse(t *testing.T) {
+	ctx := context.Background()
+	config := newRefreshTestConfig()
+	pair, _ := IssueTokenPair(config, UserClaims{UserID: "user-123"})
+
+	const attempts = 20
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := RotateRefreshToken(ctx, config, pair.RefreshToken)
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrTokenRevoked) {
+				t.Errorf("Expected ErrTokenRevoked, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("Expected exactly 1 successful rotation, got %d", succeeded)
+	}
+}
+
+func TestValidateRefreshTokenRejectsExpired(t *testing.T) {
+	config := newRefreshTestConfig()
+
+	expired := generateTestToken(jwt.MapClaims{
+		"user_id":      "user-123",
+		"jti":          "jti-1",
+		ClaimTokenType: TokenTypeRefresh,
+		"exp":          time.Now().Add(-time.Minute).Unix(),
+	}, testSecret)
+
+	if _, err := ValidateRefreshToken(context.Background(), config, expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestRefreshAndAccessTokensAreNotInterchangeable(t *testing.T) {
+	config := newRefreshTestConfig()
+	pair, _ := IssueTokenPair(config, UserClaims{UserID: "user-123"})
+
+	if _, err := ValidateRefreshToken(context.Background(), config, pair.AccessToken); !errors.Is(err, ErrNotRefreshToken) {
+		t.Errorf("Expected ErrNotRefreshToken for access token, got %v", err)
+	}
+
+	router := setupTestRouter(config)
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.RefreshToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected refresh token to be rejected as access token, got %d", w.Code)
+	}
+}
+
+func TestRefreshTokenHandler(t *testing.T) {
+	config := newRefreshTestConfig()
+	pair, _ := IssueTokenPair(config, UserClaims{UserID: "user-123"})
+
+	router := gin.New()
+	router.POST("/auth/refresh", RefreshTokenHandler(config))
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: token})
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := refresh(pair.RefreshToken); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := refresh(pair.RefreshToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401 on reuse, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if code := body["error"].(map[string]interface{})["code"]; code != "TOKEN_REVOKED" {
+		t.Errorf("Expected TOKEN_REVOKED, got %v", code)
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+)
+
+// ContextKeyScopes holds the token's parsed scopes ([]string), set by JWTMiddleware
+const ContextKeyScopes = "scopes"
+
+// parseScopes reads a scopes claim given either as a space-delimited string
+// ("bookings:read bookings:write") or as an array of strings.
+// ok is false when the claim is present but has any other shape.
+func parseScopes(claim interface{}) (scopes []string, ok bool) {
+	switch v := claim.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return strings.Fields(v), true
+	case []interface{}:
+		scopes = make([]string, 0, len(v))
+		for _, item := range v {
+			scope, isString := item.(string)
+			if !isString {
+				return nil, false
+			}
+			scopes = append(scopes, scope)
+		}
+		return scopes, true
+	default:
+		return nil, false
+	}
+}
+
+// RequireScopes creates a middleware that checks the token grants all of the given scopes
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return requireScopes(scopes, true)
+}
+
+// RequireAnyScope creates a middleware that checks the token grants at least one of the given scopes
+func RequireAnyScope(scopes ...string) gin.HandlerFunc {
+	return requireScopes(scopes, false)
+}
+
+// requireScopes enforces that all (or, if requireAll is false, any) of the scopes are granted
+func requireScopes(required []string, requireAll bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get(ContextKeyUserID); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("UNAUTHORIZED", "User not authenticated"))
+			return
+		}
+
+		granted := make(map[string]bool)
+		userScopes, _ := GetScopes(c)
+		for _, scope := range userScopes {
+			granted[scope] = true
+		}
+
+		matched := 0
+		for _, scope := range required {
+			if granted[scope] {
+				matched++
+			}
+		}
+
+		if (requireAll && matched == len(required)) || (!requireAll && matched > 0) {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, response.Error("FORBIDDEN", "Insufficient scope"))
+	}
+}
+
+// GetScopes extracts the token's scopes from gin context
+func GetScopes(c *gin.Context) ([]string, bool) {
+	scopes, exists := c.Get(ContextKeyScopes)
+	if !exists {
+		return nil, false
+	}
+	s, ok := scopes.([]string)
+	return s, ok
+}
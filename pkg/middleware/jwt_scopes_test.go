@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		claim    interface{}
+		expected []string
+		ok       bool
+	}{
+		{"missing", nil, nil, true},
+		{"space delimited", "bookings:read  bookings:write", []string{"bookings:read", "bookings:write"}, true},
+		{"array", []interface{}{"bookings:read", "events:read"}, []string{"bookings:read", "events:read"}, true},
+		{"array with non-string", []interface{}{"bookings:read", 42.0}, nil, false},
+		{"number", 42.0, nil, false},
+		{"object", map[string]interface{}{"bookings": "write"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes, ok := parseScopes(tt.claim)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if len(tt.expected) > 0 && !reflect.DeepEqual(scopes, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, scopes)
+			}
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	config := &JWTConfig{Secret: testSecret}
+
+	router := gin.New()
+	router.Use(JWTMiddleware(config))
+	router.POST("/bookings", RequireScopes("bookings:read", "bookings:write"), func(c *gin.Context) {
+		scopes, _ := GetScopes(c)
+		c.JSON(http.StatusOK, gin.H{"scopes": scopes})
+	})
+	router.GET("/bookings", RequireAnyScope("bookings:read", "admin"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+
+	request := func(method string, scopes interface{}) int {
+		claims := jwt.MapClaims{
+			"user_id": "user-123",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}
+		if scopes != nil {
+			claims["scopes"] = scopes
+		}
+		req := httptest.NewRequest(method, "/bookings", nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(claims, testSecret))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		scopes   interface{}
+		expected int
+	}{
+		{"all scopes present (string)", http.MethodPost, "bookings:read bookings:write", http.StatusOK},
+		{"all scopes present (array)", http.MethodPost, []string{"bookings:write", "bookings:read", "events:read"}, http.StatusOK},
+		{"one scope missing", http.MethodPost, "bookings:read", http.StatusForbidden},
+		{"no scopes claim", http.MethodPost, nil, http.StatusForbidden},
+		{"any scope present", http.MethodGet, []string{"admin"}, http.StatusOK},
+		{"any scope missing", http.MethodGet, "events:read", http.StatusForbidden},
+		{"malformed scopes claim", http.MethodGet, 42, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := request(tt.method, tt.scopes); code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, code)
+			}
+		})
+	}
+
+	t.Run("no authentication", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/bookings", RequireScopes("bookings:read"), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "ok"})
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bookings", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -381,3 +382,224 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestOptionalAuth(t *testing.T) {
+	config := &JWTConfig{Secret: testSecret}
+
+	router := gin.New()
+	router.Use(OptionalAuth(config))
+	router.GET("/events", func(c *gin.Context) {
+		userID, authenticated := GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "authenticated": authenticated})
+	})
+
+	request := func(authHeader string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/events", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	t.Run("anonymous without token", func(t *testing.T) {
+		code, body := request("")
+		if code != http.StatusOK || body["authenticated"] != false {
+			t.Errorf("Expected anonymous pass-through, got %d %v", code, body)
+		}
+	})
+
+	t.Run("anonymous with invalid token", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id": "user-123",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}, "wrong-secret")
+		code, body := request("Bearer " + token)
+		if code != http.StatusOK || body["authenticated"] != false {
+			t.Errorf("Expected anonymous pass-through, got %d %v", code, body)
+		}
+	})
+
+	t.Run("anonymous with malformed header", func(t *testing.T) {
+		code, body := request("Basic dXNlcjpwYXNz")
+		if code != http.StatusOK || body["authenticated"] != false {
+			t.Errorf("Expected anonymous pass-through, got %d %v", code, body)
+		}
+	})
+
+	t.Run("valid token populates user", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id": "user-123",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}, testSecret)
+		code, body := request("Bearer " + token)
+		if code != http.StatusOK || body["user_id"] != "user-123" {
+			t.Errorf("Expected user context, got %d %v", code, body)
+		}
+	})
+
+	t.Run("expired token rejected", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id": "user-123",
+			"exp":     time.Now().Add(-time.Hour).Unix(),
+		}, testSecret)
+		code, _ := request("Bearer " + token)
+		if code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for expired token, got %d", code)
+		}
+	})
+}
+
+func TestIdentify(t *testing.T) {
+	config := &JWTConfig{Secret: testSecret}
+
+	router := gin.New()
+	router.Use(Identify(config))
+	router.GET("/events", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant_id": c.GetString(ContextKeyTenantID)})
+	})
+
+	request := func(authHeader string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/events", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	t.Run("valid token populates tenant", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id":   "user-123",
+			"tenant_id": "tenant-1",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		}, testSecret)
+		code, body := request("Bearer " + token)
+		if code != http.StatusOK || body["tenant_id"] != "tenant-1" {
+			t.Errorf("Expected tenant context, got %d %v", code, body)
+		}
+	})
+
+	t.Run("expired token passes through anonymously", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id":   "user-123",
+			"tenant_id": "tenant-1",
+			"exp":       time.Now().Add(-time.Hour).Unix(),
+		}, testSecret)
+		code, body := request("Bearer " + token)
+		if code != http.StatusOK || body["tenant_id"] != "" {
+			t.Errorf("Expected anonymous pass-through, got %d %v", code, body)
+		}
+	})
+
+	t.Run("forged token passes through anonymously", func(t *testing.T) {
+		token := generateTestToken(jwt.MapClaims{
+			"user_id":   "user-123",
+			"tenant_id": "tenant-1",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		}, "wrong-secret")
+		code, body := request("Bearer " + token)
+		if code != http.StatusOK || body["tenant_id"] != "" {
+			t.Errorf("Expected anonymous pass-through, got %d %v", code, body)
+		}
+	})
+}
+
+func TestJWTMiddlewareLeeway(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		leeway   time.Duration
+		claims   jwt.MapClaims
+		expected int
+	}{
+		{"expired without leeway", 0, jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}, http.StatusUnauthorized},
+		{"expired within leeway", 30 * time.Second, jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}, http.StatusOK},
+		{"expired beyond leeway", 30 * time.Second, jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}, http.StatusUnauthorized},
+		{"not yet valid without leeway", 0, jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()}, http.StatusUnauthorized},
+		{"not yet valid within leeway", 30 * time.Second, jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()}, http.StatusOK},
+		{"not yet valid beyond leeway", 30 * time.Second, jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(&JWTConfig{Secret: testSecret, Leeway: tt.leeway})
+
+			tt.claims["user_id"] = "user-123"
+			if _, ok := tt.claims["exp"]; !ok {
+				tt.claims["exp"] = now.Add(time.Hour).Unix()
+			}
+			token := generateTestToken(tt.claims, testSecret)
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestJWTMiddlewareIssuerAndAudience(t *testing.T) {
+	config := &JWTConfig{
+		Secret:           testSecret,
+		ExpectedIssuer:   "booking-rush-auth",
+		ExpectedAudience: "booking-rush-api",
+	}
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		expected int
+	}{
+		{"correct issuer and audience", jwt.MapClaims{"iss": "booking-rush-auth", "aud": "booking-rush-api"}, http.StatusOK},
+		{"audience list containing expected", jwt.MapClaims{"iss": "booking-rush-auth", "aud": []string{"other-api", "booking-rush-api"}}, http.StatusOK},
+		{"wrong issuer", jwt.MapClaims{"iss": "other-idp", "aud": "booking-rush-api"}, http.StatusUnauthorized},
+		{"wrong audience", jwt.MapClaims{"iss": "booking-rush-auth", "aud": "payment-api"}, http.StatusUnauthorized},
+		{"missing issuer", jwt.MapClaims{"aud": "booking-rush-api"}, http.StatusUnauthorized},
+		{"missing audience", jwt.MapClaims{"iss": "booking-rush-auth"}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(config)
+
+			tt.claims["user_id"] = "user-123"
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			token := generateTestToken(tt.claims, testSecret)
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+
+	t.Run("issued tokens carry issuer and audience", func(t *testing.T) {
+		pair, err := IssueTokenPair(config, UserClaims{UserID: "user-123"})
+		if err != nil {
+			t.Fatalf("IssueTokenPair failed: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		setupTestRouter(config).ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected issued token to be accepted, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+// failingNonceStore simulates Redis being unreachable
+type failingNonceStore struct{}
+
+func (failingNonceStore) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(ctx)
+	cmd.SetErr(errors.New("connection refused"))
+	return cmd
+}
+
+func setupNonceTestRouter(config *NonceConfig) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	handled := 0
+	router := gin.New()
+	router.POST("/bookings/reserve",
+		func(c *gin.Context) {
+			if userID := c.GetHeader("X-User-ID"); userID != "" {
+				c.Set(ContextKeyUserID, userID)
+			}
+			c.Next()
+		},
+		NonceMiddleware(config),
+		func(c *gin.Context) {
+			handled++
+			c.JSON(http.StatusCreated, gin.H{"booking_id": "booking-1"})
+		},
+	)
+	return router, &handled
+}
+
+func sendNonceRequest(router *gin.Engine, userID, nonce string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/bookings/reserve", nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if nonce != "" {
+		req.Header.Set(NonceHeader, nonce)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNonceMiddleware_FirstUseThenReplay(t *testing.T) {
+	router, handled := setupNonceTestRouter(&NonceConfig{Store: NewMockRedisClient(), TTL: time.Minute})
+	nonce := "3f6c1a9e-2b7d-4e8f-9a01-5c4d3b2a1f00"
+
+	if w := sendNonceRequest(router, "user-1", nonce); w.Code != http.StatusCreated {
+		t.Fatalf("First use status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	w := sendNonceRequest(router, "user-1", nonce)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Replay status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	var body response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body.Error == nil || body.Error.Code != ErrCodeNonceReplayed {
+		t.Errorf("Replay error = %+v, want %s", body.Error, ErrCodeNonceReplayed)
+	}
+
+	// Nonces are scoped per user
+	if w := sendNonceRequest(router, "user-2", nonce); w.Code != http.StatusCreated {
+		t.Errorf("Other user status = %d, want %d", w.Code, http.StatusCreated)
+	}
+
+	if *handled != 2 {
+		t.Errorf("Handler ran %d times, want 2", *handled)
+	}
+}
+
+func TestNonceMiddleware_ReplayWindow(t *testing.T) {
+	router, _ := setupNonceTestRouter(&NonceConfig{Store: NewMockRedisClient(), TTL: 50 * time.Millisecond})
+	nonce := "nonce-window-0000000001"
+
+	sendNonceRequest(router, "user-1", nonce)
+	if w := sendNonceRequest(router, "user-1", nonce); w.Code != http.StatusConflict {
+		t.Errorf("Replay within TTL status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if w := sendNonceRequest(router, "user-1", nonce); w.Code != http.StatusCreated {
+		t.Errorf("After TTL status = %d, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestNonceMiddleware_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *NonceConfig
+		userID     string
+		nonce      string
+		wantStatus int
+	}{
+		{name: "optional nonce absent", config: &NonceConfig{Store: NewMockRedisClient()}, userID: "user-1", wantStatus: http.StatusCreated},
+		{name: "required nonce absent", config: &NonceConfig{Store: NewMockRedisClient(), Required: true}, userID: "user-1", wantStatus: http.StatusBadRequest},
+		{name: "nonce too short", config: &NonceConfig{Store: NewMockRedisClient()}, userID: "user-1", nonce: "abc", wantStatus: http.StatusBadRequest},
+		{name: "no user", config: &NonceConfig{Store: NewMockRedisClient()}, nonce: "nonce-no-user-000001", wantStatus: http.StatusUnauthorized},
+		{name: "redis down", config: &NonceConfig{Store: failingNonceStore{}}, userID: "user-1", nonce: "nonce-redis-down-0001", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := setupNonceTestRouter(tt.config)
+			if w := sendNonceRequest(router, tt.userID, tt.nonce); w.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// QueuePassHeader is the header carrying the queue pass issued by the virtual queue
+	QueuePassHeader = "X-Queue-Pass"
+	// ContextKeyQueuePassValid is set to true once RequireQueuePass has accepted the request's pass
+	ContextKeyQueuePassValid = "queue_pass_valid"
+	// ContextKeyQueuePassEventID is the event RequireQueuePass validated the pass against
+	ContextKeyQueuePassEventID = "queue_pass_event_id"
+	// QueuePassKeyPrefix is the Redis key prefix for stored queue passes (queue:pass:{event_id}:{user_id})
+	QueuePassKeyPrefix = "queue:pass:"
+)
+
+// QueuePassStore reads stored queue passes (satisfied by *redis.Client)
+type QueuePassStore interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+}
+
+// RequireQueuePass creates a middleware that only lets a request through when
+// its X-Queue-Pass header matches the pass stored for the authenticated user
+// and the requested event. Passes are stored with a TTL, so an expired pass is
+// simply absent. The event is read from the event_id path parameter, query
+// parameter or JSON body field, in that order; the body is restored for the
+// handler. Handlers that act on an event named elsewhere must check it with
+// QueuePassValidFor. Must run after the middleware that sets user_id.
+func RequireQueuePass(store QueuePassStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString(ContextKeyUserID)
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("UNAUTHORIZED", "User not authenticated"))
+			return
+		}
+
+		queuePass := c.GetHeader(QueuePassHeader)
+		if queuePass == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Error("INVALID_TOKEN", "Queue pass is required"))
+			return
+		}
+
+		eventID := queuePassEventID(c)
+		if eventID == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Error("INVALID_TOKEN", "Queue pass does not match the requested event"))
+			return
+		}
+
+		stored, err := store.Get(c.Request.Context(), QueuePassKeyPrefix+eventID+":"+userID).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, response.Error(response.ErrCodeServiceUnavailable, "Unable to verify queue pass"))
+			return
+		}
+		// A pass issued to another user or event is stored under a different key
+		if stored == "" || stored != queuePass {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Error("INVALID_TOKEN", "Queue pass is invalid or expired"))
+			return
+		}
+
+		c.Set(ContextKeyQueuePassValid, true)
+		c.Set(ContextKeyQueuePassEventID, eventID)
+		c.Next()
+	}
+}
+
+// QueuePassValidFor reports whether RequireQueuePass accepted the request's
+// pass for eventID. A pass validated against another event (for example one
+// named in the query string while the body names a different one) does not count.
+func QueuePassValidFor(c *gin.Context, eventID string) bool {
+	return eventID != "" &&
+		c.GetBool(ContextKeyQueuePassValid) &&
+		c.GetString(ContextKeyQueuePassEventID) == eventID
+}
+
+// queuePassEventID returns the event the request targets, or "" if it names none
+func queuePassEventID(c *gin.Context) string {
+	if eventID := c.Param("event_id"); eventID != "" {
+		return eventID
+	}
+	if eventID := c.Query("event_id"); eventID != "" {
+		return eventID
+	}
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.EventID
+}
@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// failingQueuePassStore simulates Redis being unreachable
+type failingQueuePassStore struct{}
+
+func (failingQueuePassStore) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx)
+	cmd.SetErr(errors.New("connection refused"))
+	return cmd
+}
+
+func setupQueuePassTestRouter(store QueuePassStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/bookings/reserve",
+		func(c *gin.Context) {
+			if userID := c.GetHeader("X-User-ID"); userID != "" {
+				c.Set(ContextKeyUserID, userID)
+			}
+			c.Next()
+		},
+		RequireQueuePass(store),
+		func(c *gin.Context) {
+			// The handler still sees the full body
+			var req struct {
+				EventID string `json:"event_id"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{
+				"event_id":         req.EventID,
+				"queue_pass_valid": c.GetBool(ContextKeyQueuePassValid),
+			})
+		},
+	)
+	return router
+}
+
+func TestRequireQueuePass(t *testing.T) {
+	store := NewMockRedisClient()
+	store.Set(context.Background(), "queue:pass:event-1:user-1", "pass-user-1", 5*time.Minute)
+	store.Set(context.Background(), "queue:pass:event-1:user-2", "pass-user-2", 5*time.Minute)
+	store.Set(context.Background(), "queue:pass:event-1:user-3", "pass-user-3", 5*time.Minute)
+	// Redis drops the key once the pass TTL lapses
+	store.Del(context.Background(), "queue:pass:event-1:user-3")
+
+	tests := []struct {
+		name           string
+		userID         string
+		queuePass      string
+		eventID        string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "valid pass",
+			userID:         "user-1",
+			queuePass:      "pass-user-1",
+			eventID:        "event-1",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "expired pass",
+			userID:         "user-3",
+			queuePass:      "pass-user-3",
+			eventID:        "event-1",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "missing pass",
+			userID:         "user-1",
+			eventID:        "event-1",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "pass issued to another user",
+			userID:         "user-2",
+			queuePass:      "pass-user-1",
+			eventID:        "event-1",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "pass for another event",
+			userID:         "user-1",
+			queuePass:      "pass-user-1",
+			eventID:        "event-2",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "no event in request",
+			userID:         "user-1",
+			queuePass:      "pass-user-1",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "unauthenticated",
+			queuePass:      "pass-user-1",
+			eventID:        "event-1",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupQueuePassTestRouter(store)
+
+			body, _ := json.Marshal(map[string]interface{}{"event_id": tt.eventID, "quantity": 2})
+			req := httptest.NewRequest(http.MethodPost, "/bookings/reserve", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.userID != "" {
+				req.Header.Set("X-User-ID", tt.userID)
+			}
+			if tt.queuePass != "" {
+				req.Header.Set(QueuePassHeader, tt.queuePass)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.expectedCode != "" {
+				var resp struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %s", tt.expectedCode, resp.Error.Code)
+				}
+				return
+			}
+
+			var resp struct {
+				EventID        string `json:"event_id"`
+				QueuePassValid bool   `json:"queue_pass_valid"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.EventID != tt.eventID {
+				t.Errorf("Expected handler to read event_id %s, got %s", tt.eventID, resp.EventID)
+			}
+			if !resp.QueuePassValid {
+				t.Error("Expected queue_pass_valid to be set in the context")
+			}
+		})
+	}
+}
+
+func TestRequireQueuePass_EventFromQuery(t *testing.T) {
+	store := NewMockRedisClient()
+	store.Set(context.Background(), "queue:pass:event-1:user-1", "pass-user-1", 5*time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/availability", func(c *gin.Context) {
+		c.Set(ContextKeyUserID, "user-1")
+		c.Next()
+	}, RequireQueuePass(store), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/availability?event_id=event-1", nil)
+	req.Header.Set(QueuePassHeader, "pass-user-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestQueuePassValidFor(t *testing.T) {
+	store := NewMockRedisClient()
+	store.Set(context.Background(), "queue:pass:event-A:user-1", "pass-A", 5*time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/bookings/reserve", func(c *gin.Context) {
+		c.Set(ContextKeyUserID, "user-1")
+		c.Next()
+	}, RequireQueuePass(store), func(c *gin.Context) {
+		var req struct {
+			EventID string `json:"event_id"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"query_event": QueuePassValidFor(c, "event-A"),
+			"body_event":  QueuePassValidFor(c, req.EventID),
+		})
+	})
+
+	// A pass for the event in the query string says nothing about the body's event
+	body, _ := json.Marshal(map[string]string{"event_id": "event-B"})
+	req := httptest.NewRequest(http.MethodPost, "/bookings/reserve?event_id=event-A", bytes.NewReader(body))
+	req.Header.Set(QueuePassHeader, "pass-A")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		QueryEvent bool `json:"query_event"`
+		BodyEvent  bool `json:"body_event"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !resp.QueryEvent {
+		t.Error("Expected the pass to be valid for the validated event")
+	}
+	if resp.BodyEvent {
+		t.Error("Expected the pass not to be valid for a different event in the body")
+	}
+}
+
+func TestRequireQueuePass_StoreUnavailable(t *testing.T) {
+	router := setupQueuePassTestRouter(failingQueuePassStore{})
+
+	body, _ := json.Marshal(map[string]string{"event_id": "event-1"})
+	req := httptest.NewRequest(http.MethodPost, "/bookings/reserve", bytes.NewReader(body))
+	req.Header.Set("X-User-ID", "user-1")
+	req.Header.Set(QueuePassHeader, "pass-user-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
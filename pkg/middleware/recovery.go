@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Recovery creates a middleware that recovers panics in later handlers. The
+// panic and its stack are logged with the request's trace_id, recorded on the
+// active OTel span, and the client gets a 500 INTERNAL_ERROR envelope (unless
+// the handler already started writing). Mount it after the tracing middleware
+// so the request span is still open when the panic is recorded.
+func Recovery(log *logger.Logger) gin.HandlerFunc {
+	return RecoveryWithAudit(log, nil)
+}
+
+// RecoveryWithAudit is Recovery that also writes an audit entry marking the
+// request failed. audit may be nil.
+func RecoveryWithAudit(log *logger.Logger, audit *AuditLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http uses ErrAbortHandler to abort a response on purpose
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			ctx := c.Request.Context()
+			stack := debug.Stack()
+			panicErr := fmt.Errorf("panic: %v", rec)
+
+			span := trace.SpanFromContext(ctx)
+			span.RecordError(panicErr, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, panicErr.Error())
+
+			log.WithContext(ctx).Error("Panic recovered",
+				zap.String("panic", fmt.Sprint(rec)),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("stack", string(stack)),
+			)
+
+			if audit != nil {
+				audit.Log(panicAuditEntry(c, audit.config, span, rec))
+			}
+
+			if c.Writer.Written() {
+				// Headers are already out; all we can do is stop the chain
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Error("INTERNAL_ERROR", "internal server error"))
+		}()
+
+		c.Next()
+	}
+}
+
+// panicAuditEntry builds the audit entry for a request that panicked
+func panicAuditEntry(c *gin.Context, config *AuditConfig, span trace.Span, rec interface{}) *AuditEntry {
+	entry := &AuditEntry{
+		ID:        uuid.New().String(),
+		IPAddress: getClientIP(c),
+		UserAgent: c.GetHeader("User-Agent"),
+		RequestID: c.GetHeader("X-Request-ID"),
+		Metadata: map[string]interface{}{
+			"failed":          true,
+			"panic":           fmt.Sprint(rec),
+			"response_status": http.StatusInternalServerError,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	if userID, ok := GetUserID(c); ok && userID != "" {
+		entry.UserID = &userID
+	}
+	if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
+		entry.TenantID = &tenantID
+	}
+	if span.SpanContext().HasTraceID() {
+		entry.TraceID = span.SpanContext().TraceID().String()
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = c.GetString("request_id")
+	}
+
+	if config != nil && config.ActionMapper != nil {
+		entry.Action = config.ActionMapper(c.Request.Method, c.Request.URL.Path)
+	}
+	if config != nil && config.ResourceExtractor != nil {
+		resourceType, resourceID := config.ResourceExtractor(c.Request.URL.Path)
+		entry.ResourceType = resourceType
+		if resourceID != "" {
+			entry.ResourceID = &resourceID
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecovery_PanicReturnsEnvelopeAndLogsStack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.New(&logger.Config{Level: "info", ServiceName: "test-service", OutputPath: logPath})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	auditLogger := NewAuditLogger(&AuditConfig{
+		BufferSize:        10,
+		FlushInterval:     20 * time.Millisecond,
+		ActionMapper:      defaultActionMapper,
+		ResourceExtractor: defaultResourceExtractor,
+	})
+	auditLogger.SetTestMode(true)
+	defer auditLogger.Close()
+
+	router := gin.New()
+	// Stands in for the tracing middleware
+	router.Use(func(c *gin.Context) {
+		ctx, span := tracer.Start(c.Request.Context(), "request")
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(ContextKeyUserID, "user-123")
+		c.Next()
+	})
+	router.Use(RecoveryWithAudit(log, auditLogger))
+	router.POST("/api/v1/bookings/:id/confirm", func(c *gin.Context) {
+		var m map[string]int
+		m["boom"]++ // nil map write panics
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/bookings/b-42/confirm", nil))
+	log.Sync()
+
+	// Standard error envelope
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Success)
+	require.NotNil(t, body.Error)
+	assert.Equal(t, "INTERNAL_ERROR", body.Error.Code)
+
+	// Logged with stack and trace_id
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	lines := readLogLines(t, logPath)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "Panic recovered", lines[0]["message"])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), lines[0]["trace_id"])
+	assert.Contains(t, lines[0]["panic"], "nil map")
+	stack, _ := lines[0]["stack"].(string)
+	assert.True(t, strings.Contains(stack, "recovery_test.go"), "Expected the stack to include the panicking handler")
+
+	// Recorded on the span
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.NotEmpty(t, spans[0].Events())
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+
+	// Audited as a failed request
+	require.Eventually(t, func() bool { return len(auditLogger.GetTestEntries()) == 1 }, time.Second, 10*time.Millisecond)
+	entry := auditLogger.GetTestEntries()[0]
+	assert.Equal(t, AuditActionConfirm, entry.Action)
+	assert.Equal(t, true, entry.Metadata["failed"])
+	require.NotNil(t, entry.UserID)
+	assert.Equal(t, "user-123", *entry.UserID)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), entry.TraceID)
+}
+
+func TestRecovery_NoPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log, err := logger.New(&logger.Config{Level: "info", ServiceName: "test-service", OutputPath: filepath.Join(t.TempDir(), "app.log")})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Recovery(log))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
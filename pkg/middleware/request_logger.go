@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultRequestLogSkipPaths are the probe endpoints RequestLogger ignores when no skip paths are given
+var DefaultRequestLogSkipPaths = []string{"/health", "/ready", "/metrics"}
+
+// RequestLogger creates an access-log middleware that writes one structured line
+// per request with method, path, status, latency, client IP, user_id (when
+// authenticated) and the trace_id/span_id of the request's OTel span.
+// Requests to skipPaths (default: DefaultRequestLogSkipPaths) are not logged.
+func RequestLogger(log *logger.Logger, skipPaths ...string) gin.HandlerFunc {
+	if len(skipPaths) == 0 {
+		skipPaths = DefaultRequestLogSkipPaths
+	}
+	skip := make(map[string]struct{}, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if _, ok := skip[path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		// Process request
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if userID, ok := GetUserID(c); ok && userID != "" {
+			fields = append(fields, zap.String("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		reqLog := log.WithContext(c.Request.Context())
+		switch {
+		case status >= 500:
+			reqLog.Error("Server error", fields...)
+		case status >= 400:
+			reqLog.Warn("Client error", fields...)
+		default:
+			reqLog.Info("Request completed", fields...)
+		}
+	}
+}
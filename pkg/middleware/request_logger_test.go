@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// readLogLines decodes each JSON line written to path
+func readLogLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse log line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logPath := filepath.Join(t.TempDir(), "access.log")
+	log, err := logger.New(&logger.Config{Level: "info", ServiceName: "test-service", OutputPath: logPath})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(ContextKeyUserID, "user-123")
+		c.Next()
+	})
+	router.Use(RequestLogger(log))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/bookings/:id", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+
+	req := httptest.NewRequest(http.MethodGet, "/bookings/42", nil)
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), spanCtx))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	log.Sync()
+
+	lines := readLogLines(t, logPath)
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 access log line (health skipped), got %d", len(lines))
+	}
+
+	entry := lines[0]
+	expected := map[string]interface{}{
+		"message":  "Request completed",
+		"method":   http.MethodGet,
+		"path":     "/bookings/42",
+		"status":   float64(http.StatusCreated),
+		"user_id":  "user-123",
+		"trace_id": "0af7651916cd43dd8448eb211c80319c",
+		"span_id":  "b7ad6b7169203331",
+	}
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, entry[key])
+		}
+	}
+	for _, key := range []string{"latency", "client_ip"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("Expected %s field to be present", key)
+		}
+	}
+}
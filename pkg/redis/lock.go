@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotAcquired is returned by AcquireLock when another owner holds the lock
+	ErrLockNotAcquired = errors.New("redis: lock is held by another owner")
+	// ErrLockNotHeld is returned when a lock expired or was taken over by another owner
+	ErrLockNotHeld = errors.New("redis: lock is no longer held")
+)
+
+// releaseLockScript deletes the lock only if it still holds the caller's token
+const releaseLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`
+
+// refreshLockScript extends the lock's TTL only if it still holds the caller's token
+const refreshLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// lockConn is the part of *redis.Client used by a Lock
+type lockConn interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+}
+
+// Lock is a distributed lock held in a single Redis key. The key stores a
+// random token so that only the owner can refresh or release it.
+type Lock struct {
+	conn  lockConn
+	key   string
+	token string
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	stopRenew chan struct{}
+}
+
+// AcquireLock takes the lock at key for ttl using SET NX PX. It returns
+// ErrLockNotAcquired if another owner holds it. The lock expires after ttl
+// unless it is refreshed, auto-renewed or released first.
+func (c *Client) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return acquireLock(ctx, c.client, key, ttl)
+}
+
+// acquireLock implements AcquireLock on top of conn
+func acquireLock(ctx context.Context, conn lockConn, key string, ttl time.Duration) (*Lock, error) {
+	if key == "" {
+		return nil, fmt.Errorf("lock key is required")
+	}
+	if ttl < time.Millisecond {
+		return nil, fmt.Errorf("lock ttl must be at least 1ms, got %v", ttl)
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := conn.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	return &Lock{conn: conn, key: key, token: token, ttl: ttl}, nil
+}
+
+// newLockToken returns a random token identifying a lock owner
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Key returns the Redis key holding the lock
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Refresh resets the lock's TTL to ttl. It returns ErrLockNotHeld if the lock
+// expired or another owner holds it.
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := l.conn.Eval(ctx, refreshLockScript, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to refresh lock %s: %w", l.key, err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release stops auto-renewal and deletes the lock if the caller still owns it.
+// It returns ErrLockNotHeld, leaving the key untouched, if the lock expired or
+// was re-acquired by another owner.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopAutoRenew()
+
+	n, err := l.conn.Eval(ctx, releaseLockScript, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// AutoRenew refreshes the lock to its original TTL every third of the TTL
+// until the lock is released or ctx is cancelled, so long-running operations
+// keep the lock. The returned channel is closed when renewal stops; if the lock
+// was lost or a refresh failed, the error is sent on it first. Calling
+// AutoRenew again while renewal is running returns a closed channel.
+func (l *Lock) AutoRenew(ctx context.Context) <-chan error {
+	errCh := make(chan error, 1)
+
+	l.mu.Lock()
+	if l.stopRenew != nil {
+		l.mu.Unlock()
+		close(errCh)
+		return errCh
+	}
+	stop := make(chan struct{})
+	l.stopRenew = stop
+	l.mu.Unlock()
+
+	go func() {
+		defer close(errCh)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := l.Refresh(ctx, l.ttl); err != nil {
+					// Release may have deleted the key while this refresh was in flight
+					select {
+					case <-stop:
+					default:
+						errCh <- err
+					}
+					return
+				}
+			}
+		}
+	}()
+	return errCh
+}
+
+// stopAutoRenew stops a running AutoRenew loop
+func (l *Lock) stopAutoRenew() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopRenew != nil {
+		select {
+		case <-l.stopRenew:
+		default:
+			close(l.stopRenew)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeLockConn is an in-memory lockConn that evaluates the lock scripts
+type fakeLockConn struct {
+	mu        sync.Mutex
+	values    map[string]string
+	refreshes int
+}
+
+func newFakeLockConn() *fakeLockConn {
+	return &fakeLockConn{values: make(map[string]string)}
+}
+
+func (f *fakeLockConn) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cmd := redis.NewBoolCmd(ctx)
+	if _, exists := f.values[key]; exists {
+		cmd.SetVal(false)
+		return cmd
+	}
+	f.values[key] = value.(string)
+	cmd.SetVal(true)
+	return cmd
+}
+
+func (f *fakeLockConn) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cmd := redis.NewCmd(ctx)
+	if f.values[keys[0]] != args[0].(string) {
+		cmd.SetVal(int64(0))
+		return cmd
+	}
+	switch script {
+	case releaseLockScript:
+		delete(f.values, keys[0])
+	case refreshLockScript:
+		f.refreshes++
+	}
+	cmd.SetVal(int64(1))
+	return cmd
+}
+
+// expire simulates the lock's TTL lapsing
+func (f *fakeLockConn) expire(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.values, key)
+}
+
+func (f *fakeLockConn) value(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.values[key]
+	return v, ok
+}
+
+func (f *fakeLockConn) refreshCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.refreshes
+}
+
+func TestAcquireLock_Contention(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	first, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute)
+	if err != nil {
+		t.Fatalf("First acquire failed: %v", err)
+	}
+
+	if _, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("Expected ErrLockNotAcquired for second caller, got %v", err)
+	}
+
+	// Released locks can be taken again
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute); err != nil {
+		t.Errorf("Expected acquire after release to succeed, got %v", err)
+	}
+}
+
+func TestAcquireLock_InvalidArguments(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	if _, err := acquireLock(ctx, conn, "", time.Minute); err == nil {
+		t.Error("Expected error for empty key")
+	}
+	if _, err := acquireLock(ctx, conn, "lock:zone:init", 0); err == nil {
+		t.Error("Expected error for zero ttl")
+	}
+}
+
+func TestLock_Release(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	lock, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, ok := conn.value("lock:zone:init"); ok {
+		t.Error("Expected lock key to be deleted")
+	}
+
+	// Releasing twice reports the lock is gone
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld on second release, got %v", err)
+	}
+}
+
+func TestLock_ReleaseDoesNotDeleteReacquiredLock(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	stale, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// The first owner's lock expires and another node takes it over
+	conn.expire("lock:zone:init")
+	current, err := acquireLock(ctx, conn, "lock:zone:init", time.Minute)
+	if err != nil {
+		t.Fatalf("Re-acquire failed: %v", err)
+	}
+
+	if err := stale.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld for stale owner, got %v", err)
+	}
+	if err := stale.Refresh(ctx, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld refreshing stale lock, got %v", err)
+	}
+
+	if v, ok := conn.value("lock:zone:init"); !ok || v != current.token {
+		t.Error("Expected the new owner's lock to remain")
+	}
+}
+
+func TestLock_AutoRenew(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	lock, err := acquireLock(ctx, conn, "lock:zone:init", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	errCh := lock.AutoRenew(ctx)
+	time.Sleep(100 * time.Millisecond)
+	if n := conn.refreshCount(); n < 2 {
+		t.Errorf("Expected at least 2 refreshes, got %d", n)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	select {
+	case err, ok := <-errCh:
+		if ok {
+			t.Errorf("Expected renewal to stop without error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected renewal to stop after release")
+	}
+}
+
+func TestLock_AutoRenewReportsLostLock(t *testing.T) {
+	conn := newFakeLockConn()
+	ctx := context.Background()
+
+	lock, err := acquireLock(ctx, conn, "lock:zone:init", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	errCh := lock.AutoRenew(ctx)
+	conn.expire("lock:zone:init")
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrLockNotHeld) {
+			t.Errorf("Expected ErrLockNotHeld, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected renewal to report the lost lock")
+	}
+}
+
+func TestClient_AcquireLock_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	cfg := getTestConfig()
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	defer client.Close()
+
+	key := "test:lock:integration"
+	client.Del(ctx, key)
+	defer client.Del(ctx, key)
+
+	stale, err := client.AcquireLock(ctx, key, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if _, err := client.AcquireLock(ctx, key, time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("Expected ErrLockNotAcquired, got %v", err)
+	}
+
+	// Let the first lock expire, then take it over
+	time.Sleep(150 * time.Millisecond)
+	current, err := client.AcquireLock(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("Re-acquire failed: %v", err)
+	}
+
+	if err := stale.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld for stale owner, got %v", err)
+	}
+	if n, _ := client.Exists(ctx, key).Result(); n != 1 {
+		t.Error("Expected the new owner's lock to remain")
+	}
+	if err := current.Release(ctx); err != nil {
+		t.Errorf("Release failed: %v", err)
+	}
+}
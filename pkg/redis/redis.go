@@ -3,8 +3,13 @@ package redis
 import (
 	"context"
 	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,6 +29,14 @@ type Config struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	PoolTimeout  time.Duration
+	// WarmupConns is how many connections Warmup opens (default:
+	// MinIdleConns, capped at PoolSize)
+	WarmupConns int
+
+	// TLS configuration (required by most managed Redis offerings)
+	TLSEnabled    bool
+	TLSSkipVerify bool   // Skip server certificate verification (testing only)
+	TLSCAFile     string // PEM CA bundle to trust instead of the system roots
 
 	// Retry configuration
 	MaxRetries    int
@@ -56,6 +69,54 @@ func (c *Config) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// TLSConfig builds the TLS configuration for the connection, or nil when TLS is disabled
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	if !c.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.Host,
+		InsecureSkipVerify: c.TLSSkipVerify,
+	}
+
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in redis CA file %s", c.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	return tlsCfg, nil
+}
+
+// options converts the config into go-redis client options
+func (c *Config) options() (*redis.Options, error) {
+	tlsCfg, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &redis.Options{
+		Addr:         c.Addr(),
+		Password:     c.Password,
+		DB:           c.DB,
+		PoolSize:     c.PoolSize,
+		MinIdleConns: c.MinIdleConns,
+		DialTimeout:  c.DialTimeout,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		PoolTimeout:  c.PoolTimeout,
+		TLSConfig:    tlsCfg,
+	}, nil
+}
+
 // Client wraps redis.Client with additional functionality
 type Client struct {
 	client  *redis.Client
@@ -69,16 +130,9 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		cfg = DefaultConfig()
 	}
 
-	opts := &redis.Options{
-		Addr:         cfg.Addr(),
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MinIdleConns: cfg.MinIdleConns,
-		DialTimeout:  cfg.DialTimeout,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		PoolTimeout:  cfg.PoolTimeout,
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, err
 	}
 
 	client := redis.NewClient(opts)
@@ -161,6 +215,54 @@ func (c *Client) IsConnected(ctx context.Context) bool {
 	return c.Ping(ctx) == nil
 }
 
+// Warmup opens connections up front so the first burst after startup, such
+// as a flash sale opening, doesn't wait on dialing them. MinIdleConns alone
+// fills the pool in the background, so a service can report ready before
+// the connections exist. Warmup holds WarmupConns connections at once, pings
+// each in parallel and returns them to the pool idle. Call it before the
+// service reports ready. It returns how many connections answered.
+func (c *Client) Warmup(ctx context.Context) (int, error) {
+	n := c.config.WarmupConns
+	if n <= 0 {
+		n = c.config.MinIdleConns
+	}
+	if c.config.PoolSize > 0 && n > c.config.PoolSize {
+		n = c.config.PoolSize
+	}
+	if n <= 0 {
+		return 0, nil
+	}
+
+	// Each Conn keeps its connection until closed, so n of them can't share one
+	conns := make([]*redis.Conn, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conns[i] = c.client.Conn()
+			errs[i] = conns[i].Ping(ctx).Err()
+		}(i)
+	}
+	wg.Wait()
+
+	opened := 0
+	var firstErr error
+	for i, conn := range conns {
+		conn.Close()
+		if errs[i] == nil {
+			opened++
+		} else if firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	if firstErr != nil {
+		return opened, fmt.Errorf("redis warmup opened %d of %d connections: %w", opened, n, firstErr)
+	}
+	return opened, nil
+}
+
 // --- Lua Script Support ---
 
 // ScriptInfo holds information about a loaded script
@@ -194,6 +296,58 @@ func (c *Client) LoadScript(ctx context.Context, name, script string) (*ScriptIn
 	return info, nil
 }
 
+// PreloadScripts registers scripts by name at startup, so the first request
+// runs each one by SHA instead of paying to load it or hitting NOSCRIPT.
+// Scripts the server already has are only cached locally; the rest are
+// loaded. Every script is attempted and the failures are returned together,
+// so one bad script doesn't leave the others unregistered.
+func (c *Client) PreloadScripts(ctx context.Context, scripts map[string]string) error {
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shas := make([]string, len(names))
+	for i, name := range names {
+		shas[i] = computeSHA1(scripts[name])
+	}
+
+	// If the check fails every script is loaded, which reports the real error
+	exists, err := c.client.ScriptExists(ctx, shas...).Result()
+	if err != nil || len(exists) != len(names) {
+		exists = make([]bool, len(names))
+	}
+
+	var errs []error
+	for i, name := range names {
+		if exists[i] {
+			c.scripts.Store(name, &ScriptInfo{Name: name, SHA: shas[i], Script: scripts[name]})
+			continue
+		}
+		if _, err := c.LoadScript(ctx, name, scripts[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ScriptExists reports whether a script registered by name is still cached
+// by the server, which forgets scripts on restart or SCRIPT FLUSH. Scripts
+// never registered report false.
+func (c *Client) ScriptExists(ctx context.Context, name string) (bool, error) {
+	sha, ok := c.GetScriptSHA(name)
+	if !ok {
+		return false, nil
+	}
+
+	exists, err := c.client.ScriptExists(ctx, sha).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check script %s: %w", name, err)
+	}
+	return len(exists) == 1 && exists[0], nil
+}
+
 // GetScriptSHA returns the cached SHA for a script name
 func (c *Client) GetScriptSHA(name string) (string, bool) {
 	if info, ok := c.scripts.Load(name); ok {
@@ -355,6 +509,80 @@ func (c *Client) LRange(ctx context.Context, key string, start, stop int64) *red
 	return c.client.LRange(ctx, key, start, stop)
 }
 
+// --- Set Operations ---
+
+// SAdd adds members to a set
+func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	return c.client.SAdd(ctx, key, members...)
+}
+
+// SIsMember checks if a member is in a set
+func (c *Client) SIsMember(ctx context.Context, key string, member interface{}) *redis.BoolCmd {
+	return c.client.SIsMember(ctx, key, member)
+}
+
+// --- Key Iteration ---
+
+// scanFunc performs one SCAN call, returning a page of keys and the next cursor
+type scanFunc func(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+
+// ScanKeys streams the keys matching matchPattern over the returned channel
+// using a SCAN cursor loop with batchSize as the COUNT hint. It never uses
+// KEYS, which blocks Redis while it walks the whole keyspace. An error from
+// the first SCAN call is returned; the channel is closed when the scan
+// completes, ctx is cancelled or a later SCAN call fails. Once the channel is
+// closed, scanErr reports why: nil when every key was yielded, otherwise the
+// SCAN error or ctx's error, so a partial scan is never mistaken for a full
+// one. As with SCAN itself, a key modified during the scan may be yielded
+// more than once.
+func (c *Client) ScanKeys(ctx context.Context, matchPattern string, batchSize int64) (keys <-chan string, scanErr func() error, err error) {
+	return scanKeys(ctx, func(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+		return c.client.Scan(ctx, cursor, match, count).Result()
+	}, matchPattern, batchSize)
+}
+
+// scanKeys implements ScanKeys on top of scan
+func scanKeys(ctx context.Context, scan scanFunc, matchPattern string, batchSize int64) (<-chan string, func() error, error) {
+	if matchPattern == "" {
+		return nil, nil, fmt.Errorf("scan match pattern is required")
+	}
+	if batchSize <= 0 {
+		return nil, nil, fmt.Errorf("scan batch size must be positive, got %d", batchSize)
+	}
+
+	// Fetch the first page up front so connection errors reach the caller
+	keys, cursor, err := scan(ctx, 0, matchPattern, batchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan keys: %w", err)
+	}
+
+	out := make(chan string, batchSize)
+	// scanErr is written before out is closed and read after, so the close
+	// orders the two
+	var scanErr error
+	go func() {
+		defer close(out)
+		for {
+			for _, key := range keys {
+				select {
+				case out <- key:
+				case <-ctx.Done():
+					scanErr = ctx.Err()
+					return
+				}
+			}
+			if cursor == 0 {
+				return
+			}
+			if keys, cursor, err = scan(ctx, cursor, matchPattern, batchSize); err != nil {
+				scanErr = fmt.Errorf("failed to scan keys: %w", err)
+				return
+			}
+		}
+	}()
+	return out, func() error { return scanErr }, nil
+}
+
 // --- Pipeline ---
 
 // Pipeline returns a pipeline for batch operations
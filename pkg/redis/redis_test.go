@@ -1,11 +1,27 @@
 package redis
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // getTestConfig returns config for testing
@@ -51,6 +67,65 @@ func TestConfig_Addr(t *testing.T) {
 	}
 }
 
+func TestConfig_OptionsTLS(t *testing.T) {
+	cfg := &Config{Host: "redis.example.com", Port: 6380}
+
+	opts, err := cfg.options()
+	if err != nil {
+		t.Fatalf("options() failed: %v", err)
+	}
+	if opts.TLSConfig != nil {
+		t.Error("Expected no TLS config when TLS is disabled")
+	}
+
+	cfg.TLSEnabled = true
+	cfg.TLSSkipVerify = true
+	opts, err = cfg.options()
+	if err != nil {
+		t.Fatalf("options() failed: %v", err)
+	}
+	if opts.TLSConfig == nil {
+		t.Fatal("Expected TLS config when TLS is enabled")
+	}
+	if opts.TLSConfig.ServerName != "redis.example.com" {
+		t.Errorf("Expected server name 'redis.example.com', got '%s'", opts.TLSConfig.ServerName)
+	}
+	if !opts.TLSConfig.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be set")
+	}
+	if opts.TLSConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected min version TLS 1.2, got %x", opts.TLSConfig.MinVersion)
+	}
+}
+
+func TestConfig_TLSConfigCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	cfg := &Config{Host: "localhost", TLSEnabled: true, TLSCAFile: caFile}
+	tlsCfg, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig() failed: %v", err)
+	}
+	if tlsCfg.RootCAs == nil {
+		t.Fatal("Expected RootCAs to be loaded from CA file")
+	}
+	if _, err := srv.Certificate().Verify(x509.VerifyOptions{Roots: tlsCfg.RootCAs}); err != nil {
+		t.Errorf("Expected CA pool to trust server certificate: %v", err)
+	}
+
+	cfg.TLSCAFile = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := cfg.TLSConfig(); err == nil {
+		t.Error("Expected error for missing CA file")
+	}
+}
+
 func TestNewClient_InvalidConfig(t *testing.T) {
 	cfg := &Config{
 		Host:          "invalid-host-that-does-not-exist",
@@ -276,6 +351,218 @@ func TestClient_LuaScript_Integration(t *testing.T) {
 	}
 }
 
+func TestClient_PreloadScripts_ReportsEveryFailure(t *testing.T) {
+	// Nothing listens on port 1, so every load fails
+	client := &Client{client: redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})}
+	defer client.Close()
+
+	err := client.PreloadScripts(context.Background(), map[string]string{
+		"script_a": `return 1`,
+		"script_b": `return 2`,
+	})
+	if err == nil {
+		t.Fatal("PreloadScripts() error = nil, want an error")
+	}
+	for _, name := range []string{"script_a", "script_b"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("PreloadScripts() error %q does not mention %s", err, name)
+		}
+		if _, ok := client.GetScriptSHA(name); ok {
+			t.Errorf("Script %s cached after failing to load", name)
+		}
+	}
+
+	exists, err := client.ScriptExists(context.Background(), "never_registered")
+	if err != nil || exists {
+		t.Errorf("ScriptExists() for an unregistered script = %v, %v; want false, nil", exists, err)
+	}
+}
+
+// pingServer is a minimal RESP server that answers PING and rejects every
+// other command, counting connections and pings
+type pingServer struct {
+	ln    net.Listener
+	conns atomic.Int64
+	pings atomic.Int64
+}
+
+func newPingServer(t *testing.T) *pingServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := &pingServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			srv.conns.Add(1)
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+// serve answers RESP array commands until the connection closes
+func (s *pingServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "*")))
+		args := make([]string, n)
+		for i := range args {
+			if _, err := r.ReadString('\n'); err != nil { // $len
+				return
+			}
+			arg, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			args[i] = strings.TrimSpace(arg)
+		}
+		if n > 0 && strings.EqualFold(args[0], "PING") {
+			s.pings.Add(1)
+			conn.Write([]byte("+PONG\r\n"))
+		} else {
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+	}
+}
+
+func TestClient_Warmup(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		wantConns int
+	}{
+		{name: "defaults to min idle", config: Config{PoolSize: 10, MinIdleConns: 4}, wantConns: 4},
+		{name: "explicit warmup", config: Config{PoolSize: 10, MinIdleConns: 4, WarmupConns: 6}, wantConns: 6},
+		{name: "capped at pool size", config: Config{PoolSize: 5, WarmupConns: 20}, wantConns: 5},
+		{name: "disabled", config: Config{PoolSize: 10}, wantConns: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newPingServer(t)
+			cfg := tt.config
+			// MinIdleConns is left out of the pool options so only Warmup dials
+			client := &Client{
+				client: redis.NewClient(&redis.Options{
+					Addr:            srv.ln.Addr().String(),
+					PoolSize:        cfg.PoolSize,
+					DisableIdentity: true,
+				}),
+				config: &cfg,
+			}
+			defer client.Close()
+
+			opened, err := client.Warmup(context.Background())
+			if err != nil {
+				t.Fatalf("Warmup() error = %v", err)
+			}
+			if opened != tt.wantConns {
+				t.Errorf("Warmup() opened %d, want %d", opened, tt.wantConns)
+			}
+			if got := srv.conns.Load(); got != int64(tt.wantConns) {
+				t.Errorf("Server accepted %d connections, want %d", got, tt.wantConns)
+			}
+			if got := srv.pings.Load(); got != int64(tt.wantConns) {
+				t.Errorf("Server answered %d pings, want %d", got, tt.wantConns)
+			}
+			if idle := client.Client().PoolStats().IdleConns; idle != uint32(tt.wantConns) {
+				t.Errorf("Idle connections after warmup = %d, want %d", idle, tt.wantConns)
+			}
+		})
+	}
+}
+
+func TestClient_Warmup_Unreachable(t *testing.T) {
+	client := &Client{
+		client: redis.NewClient(&redis.Options{
+			Addr:        "127.0.0.1:1",
+			DialTimeout: 100 * time.Millisecond,
+		}),
+		config: &Config{PoolSize: 10, MinIdleConns: 3},
+	}
+	defer client.Close()
+
+	opened, err := client.Warmup(context.Background())
+	if err == nil {
+		t.Fatal("Warmup() error = nil, want an error")
+	}
+	if opened != 0 {
+		t.Errorf("Warmup() opened %d, want 0", opened)
+	}
+}
+
+func TestClient_PreloadScripts_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	cfg := getTestConfig()
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	defer client.Close()
+
+	scripts := map[string]string{
+		"test_preload_add":    `return tonumber(ARGV[1]) + tonumber(ARGV[2])`,
+		"test_preload_mul":    `return tonumber(ARGV[1]) * tonumber(ARGV[2])`,
+		"test_preload_concat": `return ARGV[1] .. ARGV[2]`,
+	}
+
+	// Load one script ahead so preload finds it already on the server
+	if _, err := client.Client().ScriptLoad(ctx, scripts["test_preload_mul"]).Result(); err != nil {
+		t.Fatalf("ScriptLoad failed: %v", err)
+	}
+
+	if err := client.PreloadScripts(ctx, scripts); err != nil {
+		t.Fatalf("PreloadScripts failed: %v", err)
+	}
+
+	for name := range scripts {
+		exists, err := client.ScriptExists(ctx, name)
+		if err != nil || !exists {
+			t.Errorf("ScriptExists(%s) = %v, %v; want true", name, exists, err)
+		}
+	}
+
+	// Every script runs by name with no per-call load
+	if result, err := client.EvalShaByName(ctx, "test_preload_add", nil, 2, 3).Int(); err != nil || result != 5 {
+		t.Errorf("test_preload_add = %d, %v; want 5", result, err)
+	}
+	if result, err := client.EvalShaByName(ctx, "test_preload_mul", nil, 2, 3).Int(); err != nil || result != 6 {
+		t.Errorf("test_preload_mul = %d, %v; want 6", result, err)
+	}
+	if result, err := client.EvalShaByName(ctx, "test_preload_concat", nil, "a", "b").Text(); err != nil || result != "ab" {
+		t.Errorf("test_preload_concat = %q, %v; want ab", result, err)
+	}
+
+	// A flushed script cache is reported
+	if err := client.Client().ScriptFlush(ctx).Err(); err != nil {
+		t.Fatalf("ScriptFlush failed: %v", err)
+	}
+	if exists, err := client.ScriptExists(ctx, "test_preload_add"); err != nil || exists {
+		t.Errorf("ScriptExists after flush = %v, %v; want false", exists, err)
+	}
+}
+
 func TestClient_EvalWithFallback_Integration(t *testing.T) {
 	if os.Getenv("INTEGRATION_TEST") != "true" {
 		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
@@ -353,3 +640,172 @@ func TestClient_HashOperations_Integration(t *testing.T) {
 		t.Errorf("Expected 2 fields, got %d", len(all))
 	}
 }
+
+// pagedKeyspace serves SCAN pages over a fixed keyspace, counting calls
+type pagedKeyspace struct {
+	keys  []string
+	calls int
+	err   error
+}
+
+func (p *pagedKeyspace) scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	p.calls++
+	if p.err != nil && cursor != 0 {
+		return nil, 0, p.err
+	}
+
+	var page []string
+	end := min(int(cursor)+int(count), len(p.keys))
+	for _, key := range p.keys[cursor:end] {
+		if ok, _ := filepath.Match(match, key); ok {
+			page = append(page, key)
+		}
+	}
+	next := uint64(end)
+	if end == len(p.keys) {
+		next = 0
+	}
+	return page, next, nil
+}
+
+func collectKeys(ch <-chan string) []string {
+	var keys []string
+	for key := range ch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestScanKeys_YieldsMatchingKeysAcrossPages(t *testing.T) {
+	keyspace := &pagedKeyspace{keys: []string{
+		"reservation:1", "zone:availability:a", "reservation:2", "reservation:3",
+		"queue:pass:e1:u1", "reservation:4", "reservations:other",
+	}}
+
+	ch, scanErr, err := scanKeys(context.Background(), keyspace.scan, "reservation:*", 2)
+	if err != nil {
+		t.Fatalf("scanKeys failed: %v", err)
+	}
+
+	got := collectKeys(ch)
+	want := []string{"reservation:1", "reservation:2", "reservation:3", "reservation:4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if err := scanErr(); err != nil {
+		t.Errorf("Expected a complete scan, got %v", err)
+	}
+	if keyspace.calls != 4 {
+		t.Errorf("Expected 4 SCAN calls for 7 keys in pages of 2, got %d", keyspace.calls)
+	}
+}
+
+func TestScanKeys_InvalidArguments(t *testing.T) {
+	keyspace := &pagedKeyspace{}
+
+	if _, _, err := scanKeys(context.Background(), keyspace.scan, "", 10); err == nil {
+		t.Error("Expected error for empty pattern")
+	}
+	if _, _, err := scanKeys(context.Background(), keyspace.scan, "*", 0); err == nil {
+		t.Error("Expected error for non-positive batch size")
+	}
+	if keyspace.calls != 0 {
+		t.Errorf("Expected no SCAN calls, got %d", keyspace.calls)
+	}
+}
+
+func TestScanKeys_StopsOnCancel(t *testing.T) {
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key:%d", i)
+	}
+	keyspace := &pagedKeyspace{keys: keys}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, scanErr, err := scanKeys(ctx, keyspace.scan, "key:*", 1)
+	if err != nil {
+		t.Fatalf("scanKeys failed: %v", err)
+	}
+
+	<-ch
+	cancel()
+
+	// The channel must close without the whole keyspace being scanned
+	collectKeys(ch)
+	if keyspace.calls >= len(keys) {
+		t.Errorf("Expected scan to stop early, got %d SCAN calls", keyspace.calls)
+	}
+	if err := scanErr(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestScanKeys_LaterScanErrorIsReported(t *testing.T) {
+	keyspace := &pagedKeyspace{
+		keys: []string{"key:1", "key:2", "key:3"},
+		err:  errors.New("connection reset"),
+	}
+
+	ch, scanErr, err := scanKeys(context.Background(), keyspace.scan, "key:*", 1)
+	if err != nil {
+		t.Fatalf("Expected the first page to succeed, got %v", err)
+	}
+	if got := collectKeys(ch); !reflect.DeepEqual(got, []string{"key:1"}) {
+		t.Errorf("Expected only the first page, got %v", got)
+	}
+	if err := scanErr(); !errors.Is(err, keyspace.err) {
+		t.Errorf("Expected the SCAN error after a partial scan, got %v", err)
+	}
+}
+
+func TestClient_ScanKeys_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	cfg := getTestConfig()
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	defer client.Close()
+
+	prefix := "test:scan:" + time.Now().Format("20060102150405") + ":"
+	var want []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("%s%d", prefix, i)
+		want = append(want, key)
+		if err := client.Set(ctx, key, i, time.Minute).Err(); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if err := client.Set(ctx, "test:scanother:"+prefix, "x", time.Minute).Err(); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	sort.Strings(want)
+
+	ch, scanErr, err := client.ScanKeys(ctx, prefix+"*", 10)
+	if err != nil {
+		t.Fatalf("ScanKeys failed: %v", err)
+	}
+
+	// SCAN may repeat keys; the set of keys must match exactly
+	seen := make(map[string]bool)
+	for key := range ch {
+		seen[key] = true
+	}
+	if err := scanErr(); err != nil {
+		t.Fatalf("ScanKeys stopped early: %v", err)
+	}
+	var got []string
+	for key := range seen {
+		got = append(got, key)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %d keys %v, got %d keys %v", len(want), want, len(got), got)
+	}
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"net/http"
+)
+
+// Result codes returned by the booking Lua scripts as {0, code, message}.
+// Scripts and Go code must use the same spelling; handlers translate them to
+// HTTP statuses with HTTPStatusForCode.
+const (
+	// reserve_seats
+	CodeInsufficientStock = "INSUFFICIENT_STOCK"
+	CodeUserLimitExceeded = "USER_LIMIT_EXCEEDED"
+	CodeInvalidQuantity   = "INVALID_QUANTITY"
+	CodeZoneNotFound      = "ZONE_NOT_FOUND"
+	CodeInvalidQueuePass  = "INVALID_QUEUE_PASS"
+	CodeInvalidShowID     = "INVALID_SHOW_ID"
+
+	// reserve_specific_seats
+	CodeSeatTaken    = "SEAT_TAKEN"
+	CodeSeatNotFound = "SEAT_NOT_FOUND"
+
+	// release_specific_seats
+	CodeSeatNotHeld = "SEAT_NOT_HELD"
+
+	// release_seats / confirm_booking
+	CodeReservationNotFound = "RESERVATION_NOT_FOUND"
+	CodeInvalidBookingID    = "INVALID_BOOKING_ID"
+	CodeInvalidUserID       = "INVALID_USER_ID"
+	CodeAlreadyReleased     = "ALREADY_RELEASED"
+	CodeAlreadyConfirmed    = "ALREADY_CONFIRMED"
+	CodeInvalidStatus       = "INVALID_STATUS"
+
+	// restore_expired_hold
+	CodeHoldActive = "HOLD_ACTIVE"
+
+	// join_queue
+	CodeAlreadyInQueue = "ALREADY_IN_QUEUE"
+	CodeQueueFull      = "QUEUE_FULL"
+
+	// leave_queue
+	CodeNotInQueue        = "NOT_IN_QUEUE"
+	CodeInvalidQueueToken = "INVALID_TOKEN"
+)
+
+// codeHTTPStatus maps each result code to the HTTP status a handler responds with
+var codeHTTPStatus = map[string]int{
+	CodeInsufficientStock:   http.StatusConflict,
+	CodeUserLimitExceeded:   http.StatusConflict,
+	CodeInvalidQuantity:     http.StatusBadRequest,
+	CodeZoneNotFound:        http.StatusNotFound,
+	CodeInvalidQueuePass:    http.StatusForbidden,
+	CodeInvalidShowID:       http.StatusBadRequest,
+	CodeSeatTaken:           http.StatusConflict,
+	CodeSeatNotFound:        http.StatusNotFound,
+	CodeSeatNotHeld:         http.StatusBadRequest,
+	CodeReservationNotFound: http.StatusNotFound,
+	CodeInvalidBookingID:    http.StatusBadRequest,
+	CodeInvalidUserID:       http.StatusForbidden,
+	CodeAlreadyReleased:     http.StatusConflict,
+	CodeAlreadyConfirmed:    http.StatusConflict,
+	CodeInvalidStatus:       http.StatusConflict,
+	CodeHoldActive:          http.StatusConflict,
+	CodeAlreadyInQueue:      http.StatusConflict,
+	CodeQueueFull:           http.StatusConflict,
+	CodeNotInQueue:          http.StatusNotFound,
+	CodeInvalidQueueToken:   http.StatusForbidden,
+}
+
+// HTTPStatusForCode returns the HTTP status for a script result code. Unknown
+// codes map to 500 with ok set to false.
+func HTTPStatusForCode(code string) (status int, ok bool) {
+	status, ok = codeHTTPStatus[code]
+	if !ok {
+		return http.StatusInternalServerError, false
+	}
+	return status, true
+}
+
+// ReserveError is a failed script result, carrying the script's code and message
+type ReserveError struct {
+	Code    string
+	Message string
+}
+
+// Error implements error
+func (e *ReserveError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Message
+}
+
+// HTTPStatus returns the HTTP status handlers should respond with for e
+func (e *ReserveError) HTTPStatus() int {
+	status, _ := HTTPStatusForCode(e.Code)
+	return status
+}
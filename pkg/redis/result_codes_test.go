@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPStatusForCode(t *testing.T) {
+	codes := []string{
+		CodeInsufficientStock,
+		CodeUserLimitExceeded,
+		CodeInvalidQuantity,
+		CodeZoneNotFound,
+		CodeInvalidQueuePass,
+		CodeInvalidShowID,
+		CodeSeatTaken,
+		CodeSeatNotFound,
+		CodeSeatNotHeld,
+		CodeReservationNotFound,
+		CodeInvalidBookingID,
+		CodeInvalidUserID,
+		CodeAlreadyReleased,
+		CodeAlreadyConfirmed,
+		CodeInvalidStatus,
+		CodeHoldActive,
+		CodeAlreadyInQueue,
+		CodeQueueFull,
+		CodeNotInQueue,
+		CodeInvalidQueueToken,
+	}
+
+	for _, code := range codes {
+		status, ok := HTTPStatusForCode(code)
+		if !ok {
+			t.Errorf("HTTPStatusForCode(%s) has no mapping", code)
+			continue
+		}
+		if status < 400 || status >= 500 {
+			t.Errorf("HTTPStatusForCode(%s) = %d, want a 4xx status", code, status)
+		}
+	}
+
+	if len(codeHTTPStatus) != len(codes) {
+		t.Errorf("codeHTTPStatus has %d entries, want %d; add new codes to this test", len(codeHTTPStatus), len(codes))
+	}
+}
+
+func TestHTTPStatusForCode_Unknown(t *testing.T) {
+	status, ok := HTTPStatusForCode("SOMETHING_NEW")
+	if ok {
+		t.Error("HTTPStatusForCode() ok = true for unknown code")
+	}
+	if status != http.StatusInternalServerError {
+		t.Errorf("HTTPStatusForCode() = %d, want %d", status, http.StatusInternalServerError)
+	}
+}
+
+func TestReserveError(t *testing.T) {
+	err := fmt.Errorf("reserve failed: %w", &ReserveError{Code: CodeInsufficientStock, Message: "Not enough seats available"})
+
+	var reserveErr *ReserveError
+	if !errors.As(err, &reserveErr) {
+		t.Fatal("errors.As() should find the ReserveError")
+	}
+	if reserveErr.Code != CodeInsufficientStock {
+		t.Errorf("Code = %s, want %s", reserveErr.Code, CodeInsufficientStock)
+	}
+	if reserveErr.HTTPStatus() != http.StatusConflict {
+		t.Errorf("HTTPStatus() = %d, want %d", reserveErr.HTTPStatus(), http.StatusConflict)
+	}
+	if got, want := reserveErr.Error(), "INSUFFICIENT_STOCK: Not enough seats available"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
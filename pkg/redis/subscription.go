@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// subscriptionBufferSize matches the buffer go-redis uses for PubSub.Channel
+	subscriptionBufferSize = 100
+	// Backoff bounds between re-subscribe attempts while Redis is unreachable
+	subscriptionRetryMin = 100 * time.Millisecond
+	subscriptionRetryMax = 5 * time.Second
+)
+
+// Subscription is a Pub/Sub subscription that survives connection loss. When
+// the connection drops it re-subscribes with backoff and signals Reconnected.
+// Messages published while disconnected are lost, so callers waiting on a
+// notification should re-check the underlying state when that happens.
+type Subscription struct {
+	messages    chan *redis.Message
+	reconnected chan struct{}
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// pubSubConn is the part of *redis.PubSub used by a Subscription
+type pubSubConn interface {
+	ReceiveMessage(ctx context.Context) (*redis.Message, error)
+	Close() error
+}
+
+// subscribeConnFunc opens a subscription and waits for Redis to confirm it
+type subscribeConnFunc func(ctx context.Context) (pubSubConn, error)
+
+// ResilientSubscribe subscribes to channels, re-subscribing after connection
+// loss until ctx is cancelled or the Subscription is closed
+func (c *Client) ResilientSubscribe(ctx context.Context, channels ...string) *Subscription {
+	return newSubscription(ctx, func(ctx context.Context) (pubSubConn, error) {
+		return confirmSubscription(ctx, c.client.Subscribe(ctx, channels...))
+	})
+}
+
+// ResilientPSubscribe is like ResilientSubscribe for channel patterns
+func (c *Client) ResilientPSubscribe(ctx context.Context, patterns ...string) *Subscription {
+	return newSubscription(ctx, func(ctx context.Context) (pubSubConn, error) {
+		return confirmSubscription(ctx, c.client.PSubscribe(ctx, patterns...))
+	})
+}
+
+// confirmSubscription waits for the subscribe reply so a dead connection is
+// detected before the subscription is handed to the receive loop
+func confirmSubscription(ctx context.Context, pubsub *redis.PubSub) (pubSubConn, error) {
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	return pubsub, nil
+}
+
+// newSubscription starts the receive loop for subscribe
+func newSubscription(ctx context.Context, subscribe subscribeConnFunc) *Subscription {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Subscription{
+		messages:    make(chan *redis.Message, subscriptionBufferSize),
+		reconnected: make(chan struct{}, 1),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	go s.run(ctx, subscribe)
+	return s
+}
+
+// Messages returns the channel of received messages. It is closed when the
+// subscription ends.
+func (s *Subscription) Messages() <-chan *redis.Message {
+	return s.messages
+}
+
+// Reconnected receives a value each time the subscription is re-established
+// after a connection loss. Signals are coalesced if not consumed.
+func (s *Subscription) Reconnected() <-chan struct{} {
+	return s.reconnected
+}
+
+// Close ends the subscription and waits for its connection to be released
+func (s *Subscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// run subscribes and forwards messages, re-subscribing whenever the connection fails
+func (s *Subscription) run(ctx context.Context, subscribe subscribeConnFunc) {
+	defer close(s.done)
+	defer close(s.messages)
+
+	backoff := subscriptionRetryMin
+	subscribed := false
+	for {
+		conn, err := subscribe(ctx)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, subscriptionRetryMax)
+			continue
+		}
+		backoff = subscriptionRetryMin
+
+		if subscribed {
+			select {
+			case s.reconnected <- struct{}{}:
+			default:
+			}
+		}
+		subscribed = true
+
+		s.receive(ctx, conn)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// receive forwards messages from conn until it fails or ctx is done, then
+// closes conn. go-redis does not abort a blocked ReceiveMessage when its
+// context is cancelled, so the connection is closed to unblock the read.
+func (s *Subscription) receive(ctx context.Context, conn pubSubConn) {
+	stop := make(chan struct{})
+	defer func() {
+		close(stop)
+		conn.Close()
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return
+		}
+		select {
+		case s.messages <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
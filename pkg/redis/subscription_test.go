@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakePubSub is an in-memory pubSubConn; sending an error on errs simulates a dropped connection
+type fakePubSub struct {
+	msgs   chan *redis.Message
+	errs   chan error
+	closed chan struct{}
+	once   sync.Once
+	// ignoreCtx makes ReceiveMessage block through cancellation, as go-redis does
+	ignoreCtx bool
+}
+
+func newFakePubSub() *fakePubSub {
+	return &fakePubSub{
+		msgs:   make(chan *redis.Message),
+		errs:   make(chan error),
+		closed: make(chan struct{}),
+	}
+}
+
+func (f *fakePubSub) ReceiveMessage(ctx context.Context) (*redis.Message, error) {
+	if f.ignoreCtx {
+		ctx = context.Background()
+	}
+	select {
+	case msg := <-f.msgs:
+		return msg, nil
+	case err := <-f.errs:
+		return nil, err
+	case <-f.closed:
+		return nil, redis.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakePubSub) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+// fakeSubscriber hands out connections from conns; a nil entry fails that attempt, as if Redis were down
+type fakeSubscriber struct {
+	mu       sync.Mutex
+	conns    []*fakePubSub
+	attempts int
+}
+
+func (f *fakeSubscriber) subscribe(ctx context.Context) (pubSubConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.attempts >= len(f.conns) {
+		return nil, errors.New("no more connections")
+	}
+	conn := f.conns[f.attempts]
+	f.attempts++
+	if conn == nil {
+		return nil, errors.New("connection refused")
+	}
+	return conn, nil
+}
+
+func receiveMessage(t *testing.T, sub *Subscription) *redis.Message {
+	t.Helper()
+
+	select {
+	case msg, ok := <-sub.Messages():
+		if !ok {
+			t.Fatal("Messages() closed unexpectedly")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestSubscription_ResubscribesAfterConnectionLoss(t *testing.T) {
+	first, second := newFakePubSub(), newFakePubSub()
+	// Drop the first connection, fail one attempt while Redis is down, then restore
+	subscriber := &fakeSubscriber{conns: []*fakePubSub{first, nil, second}}
+
+	sub := newSubscription(context.Background(), subscriber.subscribe)
+	defer sub.Close()
+
+	first.msgs <- &redis.Message{Channel: "orders", Payload: "before"}
+	if msg := receiveMessage(t, sub); msg.Payload != "before" {
+		t.Errorf("Expected payload 'before', got '%s'", msg.Payload)
+	}
+
+	first.errs <- errors.New("connection reset by peer")
+
+	select {
+	case <-sub.Reconnected():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reconnection event")
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Error("Expected the dropped connection to be closed")
+	}
+
+	second.msgs <- &redis.Message{Channel: "orders", Payload: "after"}
+	if msg := receiveMessage(t, sub); msg.Payload != "after" {
+		t.Errorf("Expected payload 'after', got '%s'", msg.Payload)
+	}
+}
+
+func TestSubscription_NoReconnectEventOnFirstSubscribe(t *testing.T) {
+	// The initial attempt fails, which is not a reconnection
+	subscriber := &fakeSubscriber{conns: []*fakePubSub{nil, newFakePubSub()}}
+
+	sub := newSubscription(context.Background(), subscriber.subscribe)
+	defer sub.Close()
+
+	select {
+	case <-sub.Reconnected():
+		t.Error("Expected no reconnection event for the first successful subscribe")
+	case <-time.After(3 * subscriptionRetryMin):
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	conn := newFakePubSub()
+	subscriber := &fakeSubscriber{conns: []*fakePubSub{conn}}
+
+	sub := newSubscription(context.Background(), subscriber.subscribe)
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+
+	if _, ok := <-sub.Messages(); ok {
+		t.Error("Expected Messages() to be closed")
+	}
+	select {
+	case <-conn.closed:
+	default:
+		t.Error("Expected the connection to be closed")
+	}
+}
+
+func TestSubscription_CloseUnblocksReceiveIgnoringContext(t *testing.T) {
+	conn := newFakePubSub()
+	conn.ignoreCtx = true
+	subscriber := &fakeSubscriber{conns: []*fakePubSub{conn}}
+
+	sub := newSubscription(context.Background(), subscriber.subscribe)
+
+	closed := make(chan error, 1)
+	go func() { closed <- sub.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked on a receive that ignores context cancellation")
+	}
+}
+
+func TestClient_ResilientSubscribe_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	cfg := getTestConfig()
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	defer client.Close()
+
+	channel := "test:resilient_subscribe"
+	sub := client.ResilientSubscribe(ctx, channel)
+	defer sub.Close()
+
+	// Wait until the subscription is live before publishing
+	publishUntilReceived := func(payload string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			client.Publish(ctx, channel, payload)
+			select {
+			case msg := <-sub.Messages():
+				if msg.Payload == payload {
+					return
+				}
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		t.Fatalf("timed out waiting for %q", payload)
+	}
+
+	publishUntilReceived("before")
+
+	// Drop every Pub/Sub connection server-side
+	if err := client.Client().ClientKillByFilter(ctx, "TYPE", "pubsub").Err(); err != nil {
+		t.Fatalf("CLIENT KILL failed: %v", err)
+	}
+
+	select {
+	case <-sub.Reconnected():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnection event")
+	}
+
+	publishUntilReceived("after")
+}
@@ -0,0 +1,49 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies a saga lifecycle event
+type EventType string
+
+const (
+	EventStepStarted           EventType = "step_started"
+	EventStepCompleted         EventType = "step_completed"
+	EventStepFailed            EventType = "step_failed"
+	EventStepSkipped           EventType = "step_skipped"
+	EventCompensationStarted   EventType = "compensation_started"
+	EventCompensationCompleted EventType = "compensation_completed"
+	EventCompensationFailed    EventType = "compensation_failed"
+	EventSagaCompleted         EventType = "saga_completed"
+	EventSagaFailed            EventType = "saga_failed"
+)
+
+// Event describes a saga lifecycle event emitted by the orchestrator
+type Event struct {
+	Type      EventType
+	Instance  *Instance
+	StepName  string // Empty for saga-level events
+	Error     error  // Set for failure events
+	Timestamp time.Time
+}
+
+// EventHandler receives saga lifecycle events. It is called synchronously from
+// the orchestrator (concurrently for steps in a parallel group), so it must be
+// safe for concurrent use and should not block.
+type EventHandler func(ctx context.Context, event *Event)
+
+// emit sends a lifecycle event to the configured handler, if any
+func (o *Orchestrator) emit(ctx context.Context, eventType EventType, instance *Instance, stepName string, err error) {
+	if o.eventHandler == nil {
+		return
+	}
+	o.eventHandler(ctx, &Event{
+		Type:      eventType,
+		Instance:  instance,
+		StepName:  stepName,
+		Error:     err,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,117 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// eventRecorder collects lifecycle events emitted by the orchestrator
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) handle(ctx context.Context, event *Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := string(event.Type)
+	if event.StepName != "" {
+		entry += ":" + event.StepName
+	}
+	r.events = append(r.events, entry)
+}
+
+func assertEvents(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events %v, got %d %v", len(want), want, len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: expected '%s', got '%s'", i, want[i], got[i])
+		}
+	}
+}
+
+func TestOrchestratorEmitsLifecycleEvents(t *testing.T) {
+	ctx := context.Background()
+	recorder := &eventRecorder{}
+	orch := NewOrchestrator(&OrchestratorConfig{EventHandler: recorder.handle})
+
+	def := NewDefinition("booking-saga", "Booking saga").
+		AddStep(&Step{
+			Name: "reserve-seats",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+		}).
+		AddStep(&Step{
+			Name:      "send-notification",
+			ShouldRun: func(data map[string]interface{}) bool { return false },
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "process-payment",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	if _, err := orch.Execute(ctx, "booking-saga", nil); err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+
+	assertEvents(t, recorder.events, []string{
+		"step_started:reserve-seats",
+		"step_completed:reserve-seats",
+		"step_skipped:send-notification",
+		"step_started:process-payment",
+		"step_completed:process-payment",
+		"saga_completed",
+	})
+}
+
+func TestOrchestratorEmitsCompensationEvents(t *testing.T) {
+	ctx := context.Background()
+	recorder := &eventRecorder{}
+	orch := NewOrchestrator(&OrchestratorConfig{EventHandler: recorder.handle})
+
+	def := NewDefinition("booking-saga", "Booking saga").
+		AddStep(&Step{
+			Name: "reserve-seats",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				return nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "process-payment",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("payment failed")
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	if _, err := orch.Execute(ctx, "booking-saga", nil); err == nil {
+		t.Fatal("expected saga to fail")
+	}
+
+	assertEvents(t, recorder.events, []string{
+		"step_started:reserve-seats",
+		"step_completed:reserve-seats",
+		"step_started:process-payment",
+		"step_failed:process-payment",
+		"compensation_started:reserve-seats",
+		"compensation_completed:reserve-seats",
+		"saga_failed",
+	})
+}
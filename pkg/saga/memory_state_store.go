@@ -5,7 +5,9 @@ import (
 	"sync"
 )
 
-// MemoryStateStore is an in-memory implementation of StateStore for testing
+// MemoryStateStore is an in-memory implementation of StateStore for testing.
+// It is safe for concurrent use: every map access holds mu, and sagas are
+// deep-copied on the way in and out so callers never share stored state.
 type MemoryStateStore struct {
 	mu          sync.RWMutex
 	sagas       map[string]*BookingSaga
@@ -76,10 +78,16 @@ func (s *MemoryStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga) er
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.sagas[saga.ID]; !exists {
+	stored, exists := s.sagas[saga.ID]
+	if !exists {
 		return ErrStateNotFound
 	}
 
+	if stored.Version != saga.Version {
+		return ErrConcurrentModification
+	}
+
+	saga.Version++
 	s.sagas[saga.ID] = s.copySaga(saga)
 	return nil
 }
@@ -109,7 +117,7 @@ func (s *MemoryStateStore) GetTransitions(ctx context.Context, sagaID string) ([
 	return result, nil
 }
 
-// GetSagasByState retrieves sagas by state
+// GetSagasByState retrieves sagas by state, returning copies
 func (s *MemoryStateStore) GetSagasByState(ctx context.Context, state BookingState, limit int) ([]*BookingSaga, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -127,6 +135,18 @@ func (s *MemoryStateStore) GetSagasByState(ctx context.Context, state BookingSta
 	return result, nil
 }
 
+// CountSagasByState returns the number of stored sagas in each state
+func (s *MemoryStateStore) CountSagasByState(ctx context.Context) (map[BookingState]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[BookingState]int)
+	for _, saga := range s.sagas {
+		counts[saga.State]++
+	}
+	return counts, nil
+}
+
 // copySaga creates a deep copy of a saga
 func (s *MemoryStateStore) copySaga(saga *BookingSaga) *BookingSaga {
 	if saga == nil {
@@ -143,14 +163,21 @@ func (s *MemoryStateStore) copySaga(saga *BookingSaga) *BookingSaga {
 		ReservationID:  saga.ReservationID,
 		PaymentID:      saga.PaymentID,
 		ConfirmationID: saga.ConfirmationID,
+		RefundID:       saga.RefundID,
 		ErrorMessage:   saga.ErrorMessage,
 		RetryCount:     saga.RetryCount,
+		Version:        saga.Version,
 		CreatedAt:      saga.CreatedAt,
 		UpdatedAt:      saga.UpdatedAt,
-		CompletedAt:    saga.CompletedAt,
 	}
 
-	// Copy data map
+	// Copy the completion time so callers can't mutate the stored one
+	if saga.CompletedAt != nil {
+		completedAt := *saga.CompletedAt
+		copied.CompletedAt = &completedAt
+	}
+
+	// Copy data map (values themselves are shared)
 	if saga.Data != nil {
 		copied.Data = make(map[string]interface{})
 		for k, v := range saga.Data {
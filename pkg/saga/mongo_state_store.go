@@ -0,0 +1,328 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	mongoSagaCollection       = "saga_states"
+	mongoTransitionCollection = "saga_transitions"
+)
+
+// MongoStateStore implements StateStore using MongoDB.
+// It suits append-heavy saga and transition history kept for archival.
+type MongoStateStore struct {
+	sagas       *mongo.Collection
+	transitions *mongo.Collection
+}
+
+// mongoSagaDocument is the MongoDB representation of a BookingSaga
+type mongoSagaDocument struct {
+	ID             string                 `bson:"_id"`
+	BookingID      string                 `bson:"booking_id"`
+	EventID        string                 `bson:"event_id"`
+	UserID         string                 `bson:"user_id"`
+	State          string                 `bson:"state"`
+	PreviousState  string                 `bson:"previous_state,omitempty"`
+	Data           map[string]interface{} `bson:"data"`
+	ReservationID  string                 `bson:"reservation_id,omitempty"`
+	PaymentID      string                 `bson:"payment_id,omitempty"`
+	ConfirmationID string                 `bson:"confirmation_id,omitempty"`
+	RefundID       string                 `bson:"refund_id,omitempty"`
+	ErrorMessage   string                 `bson:"error_message,omitempty"`
+	RetryCount     int                    `bson:"retry_count"`
+	Version        int                    `bson:"version"`
+	CreatedAt      time.Time              `bson:"created_at"`
+	UpdatedAt      time.Time              `bson:"updated_at"`
+	CompletedAt    *time.Time             `bson:"completed_at,omitempty"`
+}
+
+// mongoTransitionDocument is the MongoDB representation of a StateTransition
+type mongoTransitionDocument struct {
+	ID        string    `bson:"_id"`
+	SagaID    string    `bson:"saga_id"`
+	FromState string    `bson:"from_state"`
+	ToState   string    `bson:"to_state"`
+	Reason    string    `bson:"reason,omitempty"`
+	Timestamp time.Time `bson:"timestamp"`
+}
+
+// NewMongoStateStore creates a new MongoDB-based state store
+func NewMongoStateStore(db *mongo.Database) *MongoStateStore {
+	return &MongoStateStore{
+		sagas:       db.Collection(mongoSagaCollection),
+		transitions: db.Collection(mongoTransitionCollection),
+	}
+}
+
+// EnsureIndexes creates the indexes used by the store's queries
+func (s *MongoStateStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.sagas.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "booking_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "state", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create saga indexes: %w", err)
+	}
+
+	_, err = s.transitions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "saga_id", Value: 1}, {Key: "timestamp", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create transition indexes: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSaga persists a new saga instance
+func (s *MongoStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) error {
+	if _, err := s.sagas.InsertOne(ctx, toMongoSagaDocument(saga)); err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+	return nil
+}
+
+// GetSaga retrieves a saga by ID
+func (s *MongoStateStore) GetSaga(ctx context.Context, id string) (*BookingSaga, error) {
+	return s.findSaga(ctx, bson.M{"_id": id})
+}
+
+// GetSagaByBookingID retrieves a saga by booking ID
+func (s *MongoStateStore) GetSagaByBookingID(ctx context.Context, bookingID string) (*BookingSaga, error) {
+	return s.findSaga(ctx, bson.M{"booking_id": bookingID})
+}
+
+// findSaga returns the single saga matching filter
+func (s *MongoStateStore) findSaga(ctx context.Context, filter bson.M) (*BookingSaga, error) {
+	var doc mongoSagaDocument
+	if err := s.sagas.FindOne(ctx, filter).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrStateNotFound
+		}
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+	return doc.toBookingSaga(), nil
+}
+
+// UpdateSaga updates an existing saga instance
+func (s *MongoStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga) error {
+	doc := toMongoSagaDocument(saga)
+	doc.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"state":           doc.State,
+			"previous_state":  doc.PreviousState,
+			"data":            doc.Data,
+			"reservation_id":  doc.ReservationID,
+			"payment_id":      doc.PaymentID,
+			"confirmation_id": doc.ConfirmationID,
+			"refund_id":       doc.RefundID,
+			"error_message":   doc.ErrorMessage,
+			"retry_count":     doc.RetryCount,
+			"updated_at":      doc.UpdatedAt,
+			"completed_at":    doc.CompletedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := s.sagas.UpdateOne(ctx, bson.M{"_id": saga.ID, "version": saga.Version}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := s.sagas.CountDocuments(ctx, bson.M{"_id": saga.ID})
+		if err != nil {
+			return fmt.Errorf("failed to check saga existence: %w", err)
+		}
+		if count == 0 {
+			return ErrStateNotFound
+		}
+		return ErrConcurrentModification
+	}
+
+	saga.Version++
+	return nil
+}
+
+// SaveTransition persists a state transition
+func (s *MongoStateStore) SaveTransition(ctx context.Context, transition *StateTransition) error {
+	doc := mongoTransitionDocument{
+		ID:        transition.ID,
+		SagaID:    transition.SagaID,
+		FromState: string(transition.FromState),
+		ToState:   string(transition.ToState),
+		Reason:    transition.Reason,
+		Timestamp: transition.Timestamp,
+	}
+
+	if _, err := s.transitions.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save transition: %w", err)
+	}
+
+	return nil
+}
+
+// GetTransitions retrieves all transitions for a saga
+func (s *MongoStateStore) GetTransitions(ctx context.Context, sagaID string) ([]StateTransition, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	cursor, err := s.transitions.Find(ctx, bson.M{"saga_id": sagaID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transitions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transitions []StateTransition
+	for cursor.Next(ctx) {
+		var doc mongoTransitionDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode transition: %w", err)
+		}
+
+		transitions = append(transitions, StateTransition{
+			ID:        doc.ID,
+			SagaID:    doc.SagaID,
+			FromState: BookingState(doc.FromState),
+			ToState:   BookingState(doc.ToState),
+			Reason:    doc.Reason,
+			Timestamp: doc.Timestamp,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transitions: %w", err)
+	}
+
+	return transitions, nil
+}
+
+// GetSagasByState retrieves sagas by state
+func (s *MongoStateStore) GetSagasByState(ctx context.Context, state BookingState, limit int) ([]*BookingSaga, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := s.sagas.Find(ctx, bson.M{"state": string(state)}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sagas by state: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sagas []*BookingSaga
+	for cursor.Next(ctx) {
+		var doc mongoSagaDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode saga: %w", err)
+		}
+		sagas = append(sagas, doc.toBookingSaga())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sagas: %w", err)
+	}
+
+	return sagas, nil
+}
+
+// CountSagasByState returns the number of sagas in each state
+func (s *MongoStateStore) CountSagasByState(ctx context.Context) (map[BookingState]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$state"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+
+	cursor, err := s.sagas.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sagas by state: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[BookingState]int)
+	for cursor.Next(ctx) {
+		var row struct {
+			State string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode state count: %w", err)
+		}
+		counts[BookingState(row.State)] = row.Count
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating state counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// toMongoSagaDocument converts a BookingSaga to its MongoDB representation
+func toMongoSagaDocument(saga *BookingSaga) *mongoSagaDocument {
+	data := saga.Data
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	return &mongoSagaDocument{
+		ID:             saga.ID,
+		BookingID:      saga.BookingID,
+		EventID:        saga.EventID,
+		UserID:         saga.UserID,
+		State:          string(saga.State),
+		PreviousState:  string(saga.PreviousState),
+		Data:           data,
+		ReservationID:  saga.ReservationID,
+		PaymentID:      saga.PaymentID,
+		ConfirmationID: saga.ConfirmationID,
+		RefundID:       saga.RefundID,
+		ErrorMessage:   saga.ErrorMessage,
+		RetryCount:     saga.RetryCount,
+		Version:        saga.Version,
+		CreatedAt:      saga.CreatedAt,
+		UpdatedAt:      saga.UpdatedAt,
+		CompletedAt:    saga.CompletedAt,
+	}
+}
+
+// toBookingSaga converts a MongoDB document back to a BookingSaga
+func (d *mongoSagaDocument) toBookingSaga() *BookingSaga {
+	data := d.Data
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	return &BookingSaga{
+		ID:             d.ID,
+		BookingID:      d.BookingID,
+		EventID:        d.EventID,
+		UserID:         d.UserID,
+		State:          BookingState(d.State),
+		PreviousState:  BookingState(d.PreviousState),
+		Data:           data,
+		ReservationID:  d.ReservationID,
+		PaymentID:      d.PaymentID,
+		ConfirmationID: d.ConfirmationID,
+		RefundID:       d.RefundID,
+		ErrorMessage:   d.ErrorMessage,
+		RetryCount:     d.RetryCount,
+		Version:        d.Version,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+		CompletedAt:    d.CompletedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestMongoSagaDocumentRoundTrip(t *testing.T) {
+	completedAt := time.Now()
+	original := &BookingSaga{
+		ID:            "saga-1",
+		BookingID:     "booking-1",
+		EventID:       "event-1",
+		UserID:        "user-1",
+		State:         StateConfirmed,
+		PreviousState: StatePaid,
+		Data:          map[string]interface{}{"amount": 100.0},
+		PaymentID:     "pay-1",
+		RetryCount:    1,
+		CreatedAt:     completedAt.Add(-time.Minute),
+		UpdatedAt:     completedAt,
+		CompletedAt:   &completedAt,
+	}
+
+	restored := toMongoSagaDocument(original).toBookingSaga()
+
+	if restored.ID != original.ID || restored.BookingID != original.BookingID {
+		t.Errorf("expected IDs %s/%s, got %s/%s", original.ID, original.BookingID, restored.ID, restored.BookingID)
+	}
+	if restored.State != StateConfirmed || restored.PreviousState != StatePaid {
+		t.Errorf("expected states CONFIRMED/PAID, got %s/%s", restored.State, restored.PreviousState)
+	}
+	if restored.Data["amount"] != 100.0 {
+		t.Errorf("expected amount 100, got %v", restored.Data["amount"])
+	}
+	if restored.CompletedAt == nil || !restored.CompletedAt.Equal(completedAt) {
+		t.Errorf("expected CompletedAt %v, got %v", completedAt, restored.CompletedAt)
+	}
+
+	// nil data is normalised to an empty map
+	if doc := toMongoSagaDocument(&BookingSaga{ID: "saga-2"}); doc.Data == nil {
+		t.Error("expected data to be an empty map")
+	}
+}
+
+func TestMongoStateStore_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	uri := "mongodb://localhost:27017"
+	if v := os.Getenv("TEST_MONGODB_URI"); v != "" {
+		uri = v
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	db := client.Database("saga_state_store_test")
+	defer db.Drop(ctx)
+
+	store := NewMongoStateStore(db)
+	if err := store.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("failed to create indexes: %v", err)
+	}
+
+	sm := NewStateMachine(store)
+
+	// SaveSaga
+	saga, err := sm.CreateSaga(ctx, "booking-mongo-1", "event-1", "user-1", map[string]interface{}{"quantity": 2.0})
+	if err != nil {
+		t.Fatalf("failed to create saga: %v", err)
+	}
+
+	// GetSaga
+	retrieved, err := store.GetSaga(ctx, saga.ID)
+	if err != nil {
+		t.Fatalf("failed to get saga: %v", err)
+	}
+	if retrieved.State != StateCreated {
+		t.Errorf("expected state CREATED, got %s", retrieved.State)
+	}
+	if retrieved.Data["quantity"] != 2.0 {
+		t.Errorf("expected quantity 2, got %v", retrieved.Data["quantity"])
+	}
+
+	// GetSagaByBookingID
+	byBooking, err := store.GetSagaByBookingID(ctx, "booking-mongo-1")
+	if err != nil {
+		t.Fatalf("failed to get saga by booking ID: %v", err)
+	}
+	if byBooking.ID != saga.ID {
+		t.Errorf("expected saga %s, got %s", saga.ID, byBooking.ID)
+	}
+
+	// Duplicate booking IDs are rejected by the unique index
+	if err := store.SaveSaga(ctx, &BookingSaga{ID: "dup", BookingID: "booking-mongo-1", State: StateCreated}); err == nil {
+		t.Error("expected duplicate booking ID to be rejected")
+	}
+
+	// UpdateSaga + SaveTransition
+	if _, err := sm.MarkReserved(ctx, saga.ID, "res-1"); err != nil {
+		t.Fatalf("failed to mark reserved: %v", err)
+	}
+	retrieved, _ = store.GetSaga(ctx, saga.ID)
+	if retrieved.State != StateReserved || retrieved.ReservationID != "res-1" {
+		t.Errorf("expected RESERVED with res-1, got %s with %s", retrieved.State, retrieved.ReservationID)
+	}
+
+	if err := store.UpdateSaga(ctx, &BookingSaga{ID: "nonexistent"}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("expected ErrStateNotFound, got %v", err)
+	}
+
+	// Stale versions are rejected
+	stale := *retrieved
+	stale.Version--
+	if err := store.UpdateSaga(ctx, &stale); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	// GetTransitions
+	transitions, err := store.GetTransitions(ctx, saga.ID)
+	if err != nil {
+		t.Fatalf("failed to get transitions: %v", err)
+	}
+	if len(transitions) != 1 {
+		t.Fatalf("expected 1 transition, got %d", len(transitions))
+	}
+	if transitions[0].FromState != StateCreated || transitions[0].ToState != StateReserved {
+		t.Errorf("unexpected transition %s -> %s", transitions[0].FromState, transitions[0].ToState)
+	}
+
+	// GetSagasByState
+	reserved, err := store.GetSagasByState(ctx, StateReserved, 10)
+	if err != nil {
+		t.Fatalf("failed to get sagas by state: %v", err)
+	}
+	if len(reserved) != 1 || reserved[0].ID != saga.ID {
+		t.Errorf("expected saga %s in RESERVED state, got %d sagas", saga.ID, len(reserved))
+	}
+
+	// GetSaga not found
+	if _, err := store.GetSaga(ctx, "nonexistent"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("expected ErrStateNotFound, got %v", err)
+	}
+}
@@ -2,17 +2,28 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	// ErrSagaTimeout is returned when a saga exceeds its definition-level timeout
+	ErrSagaTimeout = errors.New("saga timed out")
+	// ErrSagaInProgress is returned when a saga with the same idempotency key is still running
+	ErrSagaInProgress = errors.New("saga with this idempotency key is already in progress")
+)
+
 // Orchestrator manages saga execution and compensation
 type Orchestrator struct {
-	definitions map[string]*Definition
-	store       Store
-	mu          sync.RWMutex
-	logger      Logger
+	definitions          map[string]*Definition
+	store                Store
+	mu                   sync.RWMutex
+	logger               Logger
+	onCompensationFailed CompensationFailedHandler
+	eventHandler         EventHandler
 }
 
 // Logger interface for saga logging
@@ -36,10 +47,21 @@ func (l *NoOpLogger) InfoContext(ctx context.Context, msg string, fields ...inte
 func (l *NoOpLogger) WarnContext(ctx context.Context, msg string, fields ...interface{}) {}
 func (l *NoOpLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {}
 
+// CompensationFailedHandler is called when a step's compensation returns an error.
+// Implementations typically push the saga to a dead letter queue or raise an alert.
+// Compensations of a parallel group run concurrently, so the handler may be called
+// concurrently for the same instance and must be safe for concurrent use.
+type CompensationFailedHandler func(instance *Instance, step *Step, err error)
+
 // OrchestratorConfig holds configuration for the orchestrator
 type OrchestratorConfig struct {
 	Store  Store
 	Logger Logger
+	// OnCompensationFailed is invoked for every compensation that fails (optional).
+	// It is called concurrently for steps of a parallel group.
+	OnCompensationFailed CompensationFailedHandler
+	// EventHandler receives saga lifecycle events for observability (optional)
+	EventHandler EventHandler
 }
 
 // NewOrchestrator creates a new saga orchestrator
@@ -55,9 +77,11 @@ func NewOrchestrator(cfg *OrchestratorConfig) *Orchestrator {
 	}
 
 	return &Orchestrator{
-		definitions: make(map[string]*Definition),
-		store:       store,
-		logger:      logger,
+		definitions:          make(map[string]*Definition),
+		store:                store,
+		logger:               logger,
+		onCompensationFailed: cfg.OnCompensationFailed,
+		eventHandler:         cfg.EventHandler,
 	}
 }
 
@@ -88,7 +112,13 @@ func (o *Orchestrator) GetDefinition(name string) (*Definition, error) {
 	return def, nil
 }
 
-// Execute starts a new saga instance and runs it to completion
+// Execute starts a new saga instance and runs it to completion.
+// The output of each completed step is merged into the saga data before the
+// next step runs (see Instance.MergeStepOutput). If the saga runs longer than
+// the definition timeout, the running step's context is cancelled and the
+// completed steps are compensated; the returned error wraps ErrSagaTimeout.
+// When the definition has an idempotency key field, a repeated key returns the
+// prior instance instead of running the steps again.
 func (o *Orchestrator) Execute(ctx context.Context, definitionName string, initialData map[string]interface{}) (*Instance, error) {
 	def, err := o.GetDefinition(definitionName)
 	if err != nil {
@@ -97,10 +127,26 @@ func (o *Orchestrator) Execute(ctx context.Context, definitionName string, initi
 
 	// Create a new saga instance
 	instance := NewInstance(def.Name, initialData)
+
+	// Replay the prior outcome for a repeated idempotency key
+	if key := def.idempotencyKey(initialData); key != "" {
+		instance.ID = idempotentInstanceID(def.Name, key)
+		prior, err := o.store.Get(ctx, instance.ID)
+		if err == nil {
+			return o.replay(prior)
+		}
+		if !errors.Is(err, ErrSagaNotFound) {
+			return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+		}
+	}
+
 	o.logger.Info("Starting saga execution", "saga_id", instance.ID, "definition", def.Name)
 
 	// Save initial state
 	if err := o.store.Save(ctx, instance); err != nil {
+		if errors.Is(err, ErrSagaAlreadyExists) {
+			return nil, ErrSagaInProgress
+		}
 		return nil, fmt.Errorf("failed to save saga instance: %w", err)
 	}
 
@@ -112,6 +158,21 @@ func (o *Orchestrator) Execute(ctx context.Context, definitionName string, initi
 	return o.executeSaga(sagaCtx, def, instance)
 }
 
+// replay returns the outcome of a saga that was already started with the same
+// idempotency key
+func (o *Orchestrator) replay(prior *Instance) (*Instance, error) {
+	o.logger.Info("Saga already executed for idempotency key", "saga_id", prior.ID, "status", prior.Status)
+
+	switch prior.Status {
+	case StatusCompleted:
+		return prior, nil
+	case StatusCompensated, StatusCompensationFailed, StatusFailed:
+		return prior, fmt.Errorf("saga previously failed: %s", prior.Error)
+	default:
+		return prior, ErrSagaInProgress
+	}
+}
+
 // executeSaga runs through all saga steps
 func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instance *Instance) (*Instance, error) {
 	instance.SetStatus(StatusRunning)
@@ -121,13 +182,14 @@ func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instanc
 
 	var lastError error
 
-	for i, step := range def.Steps {
+	for i := 0; i < len(def.Steps); i++ {
+		step := def.Steps[i]
 		instance.CurrentStep = i
 
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
-			lastError = ctx.Err()
+			lastError = sagaError(ctx, ctx.Err())
 			o.logger.Warn("Saga execution cancelled", "saga_id", instance.ID, "step", step.Name)
 			break
 		default:
@@ -137,6 +199,23 @@ func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instanc
 			break
 		}
 
+		// Run parallel groups as a unit
+		if step.ParallelGroup != 0 {
+			group := def.stepGroup(i)
+			i += len(group) - 1
+			if err := o.executeParallelGroup(ctx, group, instance); err != nil {
+				lastError = sagaError(ctx, err)
+				break
+			}
+			continue
+		}
+
+		// Skip steps whose condition is not met
+		if !step.shouldRun(instance.GetData()) {
+			o.skipStep(ctx, step, instance)
+			continue
+		}
+
 		// Execute step
 		result, err := o.executeStep(ctx, step, instance)
 		instance.AddStepResult(result)
@@ -146,15 +225,13 @@ func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instanc
 		}
 
 		if err != nil {
-			lastError = err
+			lastError = sagaError(ctx, err)
 			o.logger.Error("Step execution failed", "saga_id", instance.ID, "step", step.Name, "error", err)
 			break
 		}
 
 		// Merge step result data into saga data
-		if result.Data != nil {
-			instance.UpdateData(result.Data)
-		}
+		instance.MergeStepOutput(result)
 
 		o.logger.Info("Step completed successfully", "saga_id", instance.ID, "step", step.Name)
 	}
@@ -162,7 +239,7 @@ func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instanc
 	// If there was an error, run compensation
 	if lastError != nil {
 		instance.SetError(lastError)
-		return o.compensate(ctx, def, instance)
+		return o.compensate(ctx, def, instance, lastError)
 	}
 
 	// All steps completed successfully
@@ -170,11 +247,87 @@ func (o *Orchestrator) executeSaga(ctx context.Context, def *Definition, instanc
 	if err := o.store.Update(ctx, instance); err != nil {
 		o.logger.Error("Failed to update completed saga", "saga_id", instance.ID, "error", err)
 	}
+	o.emit(ctx, EventSagaCompleted, instance, "", nil)
 
 	o.logger.Info("Saga completed successfully", "saga_id", instance.ID)
 	return instance, nil
 }
 
+// sagaError wraps err with ErrSagaTimeout if the saga deadline has passed
+func sagaError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrSagaTimeout, err)
+	}
+	return err
+}
+
+// executeParallelGroup runs the given steps concurrently and waits for all of them.
+// Outputs of successful steps are merged even when another step in the group fails,
+// so that compensation sees them.
+func (o *Orchestrator) executeParallelGroup(ctx context.Context, steps []*Step, instance *Instance) error {
+	data := instance.GetData()
+	results := make([]*StepResult, len(steps))
+	errs := make([]error, len(steps))
+
+	var wg sync.WaitGroup
+	for idx, step := range steps {
+		if !step.shouldRun(data) {
+			o.skipStep(ctx, step, instance)
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, step *Step) {
+			defer wg.Done()
+			results[idx], errs[idx] = o.executeStep(ctx, step, instance)
+		}(idx, step)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for idx, result := range results {
+		if result == nil {
+			continue
+		}
+		instance.AddStepResult(result)
+
+		if errs[idx] != nil {
+			o.logger.Error("Step execution failed", "saga_id", instance.ID, "step", steps[idx].Name, "error", errs[idx])
+			if firstErr == nil {
+				firstErr = errs[idx]
+			}
+			continue
+		}
+
+		instance.MergeStepOutput(result)
+		o.logger.Info("Step completed successfully", "saga_id", instance.ID, "step", steps[idx].Name)
+	}
+
+	if err := o.store.Update(ctx, instance); err != nil {
+		o.logger.Error("Failed to update saga after parallel group", "saga_id", instance.ID, "error", err)
+	}
+
+	return firstErr
+}
+
+// skipStep records a step as skipped without executing it
+func (o *Orchestrator) skipStep(ctx context.Context, step *Step, instance *Instance) {
+	now := time.Now()
+	instance.AddStepResult(&StepResult{
+		StepName:   step.Name,
+		Status:     StepStatusSkipped,
+		StartedAt:  now,
+		FinishedAt: now,
+	})
+
+	if err := o.store.Update(ctx, instance); err != nil {
+		o.logger.Error("Failed to update saga after step", "saga_id", instance.ID, "step", step.Name, "error", err)
+	}
+
+	o.logger.Info("Step skipped", "saga_id", instance.ID, "step", step.Name)
+	o.emit(ctx, EventStepSkipped, instance, step.Name, nil)
+}
+
 // executeStep executes a single step with timeout and retry logic
 func (o *Orchestrator) executeStep(ctx context.Context, step *Step, instance *Instance) (*StepResult, error) {
 	result := &StepResult{
@@ -183,6 +336,8 @@ func (o *Orchestrator) executeStep(ctx context.Context, step *Step, instance *In
 		StartedAt: time.Now(),
 	}
 
+	o.emit(ctx, EventStepStarted, instance, step.Name, nil)
+
 	// Create step context with timeout
 	stepCtx, cancel := context.WithTimeout(ctx, step.Timeout)
 	defer cancel()
@@ -210,10 +365,16 @@ func (o *Orchestrator) executeStep(ctx context.Context, step *Step, instance *In
 			result.Data = resultData
 			result.FinishedAt = time.Now()
 			result.Duration = result.FinishedAt.Sub(result.StartedAt)
+			o.emit(ctx, EventStepCompleted, instance, step.Name, nil)
 			return result, nil
 		}
 
 		lastError = err
+
+		// Retrying is pointless once the step or saga deadline has passed
+		if stepCtx.Err() != nil {
+			break
+		}
 	}
 
 	// All retries failed
@@ -221,12 +382,18 @@ func (o *Orchestrator) executeStep(ctx context.Context, step *Step, instance *In
 	result.Error = lastError.Error()
 	result.FinishedAt = time.Now()
 	result.Duration = result.FinishedAt.Sub(result.StartedAt)
+	o.emit(ctx, EventStepFailed, instance, step.Name, lastError)
 
 	return result, lastError
 }
 
-// compensate runs compensation for all completed steps in reverse order
-func (o *Orchestrator) compensate(ctx context.Context, def *Definition, instance *Instance) (*Instance, error) {
+// compensate runs compensation for all completed steps in reverse order.
+// cause is the error that failed the saga; if nil, the recorded instance error is used.
+func (o *Orchestrator) compensate(ctx context.Context, def *Definition, instance *Instance, cause error) (*Instance, error) {
+	// Compensation must run even if the saga was cancelled or timed out;
+	// each compensation step is still bounded by its own step timeout.
+	ctx = context.WithoutCancel(ctx)
+
 	instance.SetStatus(StatusCompensating)
 	if err := o.store.Update(ctx, instance); err != nil {
 		o.logger.Error("Failed to update saga compensation status", "saga_id", instance.ID, "error", err)
@@ -234,41 +401,59 @@ func (o *Orchestrator) compensate(ctx context.Context, def *Definition, instance
 
 	o.logger.Info("Starting saga compensation", "saga_id", instance.ID, "completed_steps", len(instance.StepResults))
 
+	compensationFailed := false
+
 	// Find completed steps that need compensation (in reverse order)
 	for i := len(instance.StepResults) - 1; i >= 0; i-- {
 		stepResult := instance.StepResults[i]
 
-		// Skip steps that weren't completed
-		if stepResult.Status != StepStatusCompleted {
+		group := 0
+		if step := def.findStep(stepResult.StepName); step != nil {
+			group = step.ParallelGroup
+		}
+
+		if group == 0 {
+			if !o.compensateResult(ctx, def, instance, stepResult) {
+				compensationFailed = true
+			}
 			continue
 		}
 
-		// Find the step definition
-		var step *Step
-		for _, s := range def.Steps {
-			if s.Name == stepResult.StepName {
-				step = s
+		// Fan out compensation for the whole parallel group
+		start := i
+		for start > 0 {
+			prev := def.findStep(instance.StepResults[start-1].StepName)
+			if prev == nil || prev.ParallelGroup != group {
 				break
 			}
+			start--
 		}
 
-		if step == nil || step.Compensate == nil {
-			o.logger.Warn("No compensation function for step", "saga_id", instance.ID, "step", stepResult.StepName)
-			continue
+		var wg sync.WaitGroup
+		var groupFailed atomic.Bool
+		for _, result := range instance.StepResults[start : i+1] {
+			wg.Add(1)
+			go func(result *StepResult) {
+				defer wg.Done()
+				if !o.compensateResult(ctx, def, instance, result) {
+					groupFailed.Store(true)
+				}
+			}(result)
 		}
+		wg.Wait()
 
-		// Execute compensation
-		compensationResult := o.compensateStep(ctx, step, instance)
-		stepResult.Status = compensationResult.Status
-
-		if compensationResult.Status != StepStatusCompensated {
-			o.logger.Error("Compensation failed", "saga_id", instance.ID, "step", step.Name, "error", compensationResult.Error)
-		} else {
-			o.logger.Info("Step compensated", "saga_id", instance.ID, "step", step.Name)
+		if groupFailed.Load() {
+			compensationFailed = true
 		}
+		i = start
 	}
 
-	instance.SetStatus(StatusCompensated)
+	finalStatus := StatusCompensated
+	if compensationFailed {
+		finalStatus = StatusCompensationFailed
+	}
+
+	instance.SetStatus(finalStatus)
 	now := time.Now()
 	instance.CompletedAt = &now
 	instance.UpdatedAt = now
@@ -277,19 +462,63 @@ func (o *Orchestrator) compensate(ctx context.Context, def *Definition, instance
 		o.logger.Error("Failed to update compensated saga", "saga_id", instance.ID, "error", err)
 	}
 
-	o.logger.Info("Saga compensation completed", "saga_id", instance.ID)
+	if cause == nil {
+		cause = errors.New(instance.Error)
+	}
+	sagaErr := fmt.Errorf("saga failed and was compensated: %w", cause)
+	if compensationFailed {
+		o.logger.Error("Saga compensation incomplete", "saga_id", instance.ID)
+		sagaErr = fmt.Errorf("saga failed and compensation did not complete: %w", cause)
+	} else {
+		o.logger.Info("Saga compensation completed", "saga_id", instance.ID)
+	}
 
-	return instance, fmt.Errorf("saga failed and was compensated: %s", instance.Error)
+	o.emit(ctx, EventSagaFailed, instance, "", sagaErr)
+	return instance, sagaErr
+}
+
+// compensateResult compensates a single completed step result. It returns false
+// if the compensation failed.
+func (o *Orchestrator) compensateResult(ctx context.Context, def *Definition, instance *Instance, stepResult *StepResult) bool {
+	// Skip steps that weren't completed
+	if stepResult.Status != StepStatusCompleted {
+		return true
+	}
+
+	step := def.findStep(stepResult.StepName)
+	if step == nil || step.Compensate == nil {
+		o.logger.Warn("No compensation function for step", "saga_id", instance.ID, "step", stepResult.StepName)
+		return true
+	}
+
+	// Execute compensation
+	compensationResult, err := o.compensateStep(ctx, step, instance)
+	stepResult.Status = compensationResult.Status
+
+	if err != nil {
+		// Keep compensating the remaining steps; the failed one is escalated
+		stepResult.Error = compensationResult.Error
+		o.logger.Error("Compensation failed", "saga_id", instance.ID, "step", step.Name, "error", err)
+		if o.onCompensationFailed != nil {
+			o.onCompensationFailed(instance, step, err)
+		}
+		return false
+	}
+
+	o.logger.Info("Step compensated", "saga_id", instance.ID, "step", step.Name)
+	return true
 }
 
 // compensateStep executes compensation for a single step
-func (o *Orchestrator) compensateStep(ctx context.Context, step *Step, instance *Instance) *StepResult {
+func (o *Orchestrator) compensateStep(ctx context.Context, step *Step, instance *Instance) (*StepResult, error) {
 	result := &StepResult{
 		StepName:  step.Name,
 		Status:    StepStatusCompensating,
 		StartedAt: time.Now(),
 	}
 
+	o.emit(ctx, EventCompensationStarted, instance, step.Name, nil)
+
 	// Create step context with timeout
 	stepCtx, cancel := context.WithTimeout(ctx, step.Timeout)
 	defer cancel()
@@ -303,13 +532,15 @@ func (o *Orchestrator) compensateStep(ctx context.Context, step *Step, instance
 	result.Duration = result.FinishedAt.Sub(result.StartedAt)
 
 	if err != nil {
-		result.Status = StepStatusFailed
+		result.Status = StepStatusCompensationFailed
 		result.Error = err.Error()
-	} else {
-		result.Status = StepStatusCompensated
+		o.emit(ctx, EventCompensationFailed, instance, step.Name, err)
+		return result, err
 	}
 
-	return result
+	result.Status = StepStatusCompensated
+	o.emit(ctx, EventCompensationCompleted, instance, step.Name, nil)
+	return result, nil
 }
 
 // GetInstance retrieves a saga instance by ID
@@ -335,9 +566,9 @@ func (o *Orchestrator) Resume(ctx context.Context, instanceID string) (*Instance
 		return o.resumeExecution(ctx, def, instance)
 	case StatusFailed, StatusCompensating:
 		// Resume compensation
-		return o.compensate(ctx, def, instance)
-	case StatusCompleted, StatusCompensated:
-		// Already finished
+		return o.compensate(ctx, def, instance, nil)
+	case StatusCompleted, StatusCompensated, StatusCompensationFailed:
+		// Already finished (compensation failures need manual intervention)
 		return instance, nil
 	default:
 		return nil, fmt.Errorf("unknown saga status: %s", instance.Status)
@@ -362,7 +593,7 @@ func (o *Orchestrator) resumeExecution(ctx context.Context, def *Definition, ins
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
-			lastError = ctx.Err()
+			lastError = sagaError(ctx, ctx.Err())
 			break
 		default:
 		}
@@ -371,16 +602,31 @@ func (o *Orchestrator) resumeExecution(ctx context.Context, def *Definition, ins
 			break
 		}
 
-		// Check if this step was already completed
-		alreadyCompleted := false
-		for _, result := range instance.StepResults {
-			if result.StepName == step.Name && result.Status == StepStatusCompleted {
-				alreadyCompleted = true
+		// Run the unfinished part of a parallel group as a unit
+		if step.ParallelGroup != 0 {
+			group := def.stepGroup(i)
+			i += len(group) - 1
+
+			var pending []*Step
+			for _, s := range group {
+				if !instance.stepDone(s.Name) {
+					pending = append(pending, s)
+				}
+			}
+			if err := o.executeParallelGroup(ctx, pending, instance); err != nil {
+				lastError = sagaError(ctx, err)
 				break
 			}
+			continue
 		}
 
-		if alreadyCompleted {
+		// Check if this step was already completed or skipped
+		if instance.stepDone(step.Name) {
+			continue
+		}
+
+		if !step.shouldRun(instance.GetData()) {
+			o.skipStep(ctx, step, instance)
 			continue
 		}
 
@@ -393,20 +639,18 @@ func (o *Orchestrator) resumeExecution(ctx context.Context, def *Definition, ins
 		}
 
 		if err != nil {
-			lastError = err
+			lastError = sagaError(ctx, err)
 			break
 		}
 
 		// Merge step result data into saga data
-		if result.Data != nil {
-			instance.UpdateData(result.Data)
-		}
+		instance.MergeStepOutput(result)
 	}
 
 	// If there was an error, run compensation
 	if lastError != nil {
 		instance.SetError(lastError)
-		return o.compensate(ctx, def, instance)
+		return o.compensate(ctx, def, instance, lastError)
 	}
 
 	// All steps completed successfully
@@ -414,6 +658,7 @@ func (o *Orchestrator) resumeExecution(ctx context.Context, def *Definition, ins
 	if err := o.store.Update(ctx, instance); err != nil {
 		o.logger.Error("Failed to update completed saga", "saga_id", instance.ID, "error", err)
 	}
+	o.emit(ctx, EventSagaCompleted, instance, "", nil)
 
 	return instance, nil
 }
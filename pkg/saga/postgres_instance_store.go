@@ -187,6 +187,42 @@ func (s *PostgresStore) GetPendingCompensations(ctx context.Context, limit int)
 	return s.scanInstances(rows)
 }
 
+// GetStatusesByBookingID returns the status of the most recent saga for each
+// booking, matched on the booking_id in the saga data. Bookings with no saga
+// are absent from the result.
+func (s *PostgresStore) GetStatusesByBookingID(ctx context.Context, bookingIDs []string) (map[string]Status, error) {
+	statuses := make(map[string]Status, len(bookingIDs))
+	if len(bookingIDs) == 0 {
+		return statuses, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (data->>'booking_id') data->>'booking_id', status
+		FROM saga_instances
+		WHERE data->>'booking_id' = ANY($1)
+		ORDER BY data->>'booking_id', created_at DESC
+	`
+
+	rows, err := s.pool.Query(ctx, query, bookingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga statuses by booking: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookingID, status string
+		if err := rows.Scan(&bookingID, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan saga status: %w", err)
+		}
+		statuses[bookingID] = Status(status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate saga statuses: %w", err)
+	}
+
+	return statuses, nil
+}
+
 // GetByDefinitionID retrieves saga instances by definition ID
 func (s *PostgresStore) GetByDefinitionID(ctx context.Context, definitionID string, limit int) ([]*Instance, error) {
 	query := `
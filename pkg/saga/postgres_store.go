@@ -30,9 +30,9 @@ func (s *PostgresStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) er
 	query := `
 		INSERT INTO saga_instances (
 			id, booking_id, event_id, user_id, state, previous_state,
-			data, reservation_id, payment_id, confirmation_id,
-			error_message, retry_count, created_at, updated_at, completed_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			data, reservation_id, payment_id, confirmation_id, refund_id,
+			error_message, retry_count, version, created_at, updated_at, completed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
 	var previousState *string
@@ -41,7 +41,7 @@ func (s *PostgresStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) er
 		previousState = &ps
 	}
 
-	var reservationID, paymentID, confirmationID, errorMessage *string
+	var reservationID, paymentID, confirmationID, refundID, errorMessage *string
 	if saga.ReservationID != "" {
 		reservationID = &saga.ReservationID
 	}
@@ -51,6 +51,9 @@ func (s *PostgresStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) er
 	if saga.ConfirmationID != "" {
 		confirmationID = &saga.ConfirmationID
 	}
+	if saga.RefundID != "" {
+		refundID = &saga.RefundID
+	}
 	if saga.ErrorMessage != "" {
 		errorMessage = &saga.ErrorMessage
 	}
@@ -66,8 +69,10 @@ func (s *PostgresStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) er
 		reservationID,
 		paymentID,
 		confirmationID,
+		refundID,
 		errorMessage,
 		saga.RetryCount,
+		saga.Version,
 		saga.CreatedAt,
 		saga.UpdatedAt,
 		saga.CompletedAt,
@@ -83,8 +88,8 @@ func (s *PostgresStateStore) SaveSaga(ctx context.Context, saga *BookingSaga) er
 func (s *PostgresStateStore) GetSaga(ctx context.Context, id string) (*BookingSaga, error) {
 	query := `
 		SELECT id, booking_id, event_id, user_id, state, previous_state,
-			   data, reservation_id, payment_id, confirmation_id,
-			   error_message, retry_count, created_at, updated_at, completed_at
+			   data, reservation_id, payment_id, confirmation_id, refund_id,
+			   error_message, retry_count, version, created_at, updated_at, completed_at
 		FROM saga_instances
 		WHERE id = $1
 	`
@@ -96,8 +101,8 @@ func (s *PostgresStateStore) GetSaga(ctx context.Context, id string) (*BookingSa
 func (s *PostgresStateStore) GetSagaByBookingID(ctx context.Context, bookingID string) (*BookingSaga, error) {
 	query := `
 		SELECT id, booking_id, event_id, user_id, state, previous_state,
-			   data, reservation_id, payment_id, confirmation_id,
-			   error_message, retry_count, created_at, updated_at, completed_at
+			   data, reservation_id, payment_id, confirmation_id, refund_id,
+			   error_message, retry_count, version, created_at, updated_at, completed_at
 		FROM saga_instances
 		WHERE booking_id = $1
 	`
@@ -110,7 +115,7 @@ func (s *PostgresStateStore) scanSaga(ctx context.Context, row pgx.Row) (*Bookin
 	var saga BookingSaga
 	var state, previousState *string
 	var dataJSON []byte
-	var reservationID, paymentID, confirmationID, errorMessage *string
+	var reservationID, paymentID, confirmationID, refundID, errorMessage *string
 
 	err := row.Scan(
 		&saga.ID,
@@ -123,8 +128,10 @@ func (s *PostgresStateStore) scanSaga(ctx context.Context, row pgx.Row) (*Bookin
 		&reservationID,
 		&paymentID,
 		&confirmationID,
+		&refundID,
 		&errorMessage,
 		&saga.RetryCount,
+		&saga.Version,
 		&saga.CreatedAt,
 		&saga.UpdatedAt,
 		&saga.CompletedAt,
@@ -151,6 +158,9 @@ func (s *PostgresStateStore) scanSaga(ctx context.Context, row pgx.Row) (*Bookin
 	if confirmationID != nil {
 		saga.ConfirmationID = *confirmationID
 	}
+	if refundID != nil {
+		saga.RefundID = *refundID
+	}
 	if errorMessage != nil {
 		saga.ErrorMessage = *errorMessage
 	}
@@ -181,11 +191,13 @@ func (s *PostgresStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga)
 			reservation_id = $5,
 			payment_id = $6,
 			confirmation_id = $7,
-			error_message = $8,
-			retry_count = $9,
-			updated_at = $10,
-			completed_at = $11
-		WHERE id = $1
+			refund_id = $8,
+			error_message = $9,
+			retry_count = $10,
+			updated_at = $11,
+			completed_at = $12,
+			version = version + 1
+		WHERE id = $1 AND version = $13
 	`
 
 	var previousState *string
@@ -194,7 +206,7 @@ func (s *PostgresStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga)
 		previousState = &ps
 	}
 
-	var reservationID, paymentID, confirmationID, errorMessage *string
+	var reservationID, paymentID, confirmationID, refundID, errorMessage *string
 	if saga.ReservationID != "" {
 		reservationID = &saga.ReservationID
 	}
@@ -204,6 +216,9 @@ func (s *PostgresStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga)
 	if saga.ConfirmationID != "" {
 		confirmationID = &saga.ConfirmationID
 	}
+	if saga.RefundID != "" {
+		refundID = &saga.RefundID
+	}
 	if saga.ErrorMessage != "" {
 		errorMessage = &saga.ErrorMessage
 	}
@@ -216,19 +231,30 @@ func (s *PostgresStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga)
 		reservationID,
 		paymentID,
 		confirmationID,
+		refundID,
 		errorMessage,
 		saga.RetryCount,
 		time.Now(),
 		saga.CompletedAt,
+		saga.Version,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update saga: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return ErrStateNotFound
+		// Distinguish a missing saga from a stale version
+		var exists bool
+		if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM saga_instances WHERE id = $1)`, saga.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check saga existence: %w", err)
+		}
+		if !exists {
+			return ErrStateNotFound
+		}
+		return ErrConcurrentModification
 	}
 
+	saga.Version++
 	return nil
 }
 
@@ -304,8 +330,8 @@ func (s *PostgresStateStore) GetTransitions(ctx context.Context, sagaID string)
 func (s *PostgresStateStore) GetSagasByState(ctx context.Context, state BookingState, limit int) ([]*BookingSaga, error) {
 	query := `
 		SELECT id, booking_id, event_id, user_id, state, previous_state,
-			   data, reservation_id, payment_id, confirmation_id,
-			   error_message, retry_count, created_at, updated_at, completed_at
+			   data, reservation_id, payment_id, confirmation_id, refund_id,
+			   error_message, retry_count, version, created_at, updated_at, completed_at
 		FROM saga_instances
 		WHERE state = $1
 		ORDER BY created_at ASC
@@ -326,7 +352,7 @@ func (s *PostgresStateStore) GetSagasByState(ctx context.Context, state BookingS
 		var saga BookingSaga
 		var stateStr, previousState *string
 		var dataJSON []byte
-		var reservationID, paymentID, confirmationID, errorMessage *string
+		var reservationID, paymentID, confirmationID, refundID, errorMessage *string
 
 		err := rows.Scan(
 			&saga.ID,
@@ -339,8 +365,10 @@ func (s *PostgresStateStore) GetSagasByState(ctx context.Context, state BookingS
 			&reservationID,
 			&paymentID,
 			&confirmationID,
+			&refundID,
 			&errorMessage,
 			&saga.RetryCount,
+			&saga.Version,
 			&saga.CreatedAt,
 			&saga.UpdatedAt,
 			&saga.CompletedAt,
@@ -364,6 +392,9 @@ func (s *PostgresStateStore) GetSagasByState(ctx context.Context, state BookingS
 		if confirmationID != nil {
 			saga.ConfirmationID = *confirmationID
 		}
+		if refundID != nil {
+			saga.RefundID = *refundID
+		}
 		if errorMessage != nil {
 			saga.ErrorMessage = *errorMessage
 		}
@@ -385,3 +416,98 @@ func (s *PostgresStateStore) GetSagasByState(ctx context.Context, state BookingS
 
 	return sagas, nil
 }
+
+// CountSagasByState returns the number of sagas in each state
+func (s *PostgresStateStore) CountSagasByState(ctx context.Context) (map[BookingState]int, error) {
+	rows, err := s.pool.Query(ctx, `SELECT state, COUNT(*) FROM saga_instances GROUP BY state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sagas by state: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[BookingState]int)
+	for rows.Next() {
+		var state string
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan state count: %w", err)
+		}
+		counts[BookingState(state)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating state counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ArchiveCompletedSagas moves sagas that reached a terminal state before olderThan,
+// together with their transitions, into saga_instances_archive and
+// saga_transitions_archive. The archive tables mirror the columns of the hot tables.
+// It returns the number of sagas archived.
+func (s *PostgresStateStore) ArchiveCompletedSagas(ctx context.Context, olderThan time.Time) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin archive transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	terminalStates := []string{string(StateConfirmed), string(StateFailed), string(StateCancelled)}
+
+	// Lock the sagas being archived so their transitions and rows move together
+	rows, err := tx.Query(ctx, `
+		SELECT id
+		FROM saga_instances
+		WHERE state = ANY($1)
+		  AND COALESCE(completed_at, updated_at) < $2
+		FOR UPDATE
+	`, terminalStates, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select sagas to archive: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan saga ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating sagas to archive: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM saga_transitions WHERE saga_id = ANY($1) RETURNING *
+		)
+		INSERT INTO saga_transitions_archive SELECT * FROM moved
+	`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive transitions: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM saga_instances WHERE id = ANY($1) RETURNING *
+		)
+		INSERT INTO saga_instances_archive SELECT * FROM moved
+	`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive sagas: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit archive transaction: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
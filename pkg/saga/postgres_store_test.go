@@ -0,0 +1,179 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stateStoreTestSchema isolates the state store tables from the orchestrator's saga_instances
+const stateStoreTestSchema = "saga_state_store_test"
+
+// stateStoreTables is the layout PostgresStateStore expects, plus its archive tables
+const stateStoreTables = `
+	CREATE TABLE IF NOT EXISTS saga_instances (
+		id TEXT PRIMARY KEY,
+		booking_id TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		state VARCHAR(20) NOT NULL,
+		previous_state VARCHAR(20),
+		data JSONB NOT NULL DEFAULT '{}',
+		reservation_id TEXT,
+		payment_id TEXT,
+		confirmation_id TEXT,
+		refund_id TEXT,
+		error_message TEXT,
+		retry_count INTEGER NOT NULL DEFAULT 0,
+		version INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		completed_at TIMESTAMPTZ
+	);
+	CREATE TABLE IF NOT EXISTS saga_transitions (
+		id TEXT PRIMARY KEY,
+		saga_id TEXT NOT NULL REFERENCES saga_instances(id),
+		from_state VARCHAR(20) NOT NULL,
+		to_state VARCHAR(20) NOT NULL,
+		reason TEXT,
+		timestamp TIMESTAMPTZ NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS saga_instances_archive (LIKE saga_instances);
+	CREATE TABLE IF NOT EXISTS saga_transitions_archive (LIKE saga_transitions);
+`
+
+// getStateStorePool connects to the test database with the state store tables in a dedicated schema
+func getStateStorePool(t *testing.T) *pgxpool.Pool {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	host := os.Getenv("TEST_POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	user := os.Getenv("TEST_POSTGRES_USER")
+	if user == "" {
+		user = "postgres"
+	}
+	password := os.Getenv("TEST_POSTGRES_PASSWORD")
+	if password == "" {
+		password = "postgres"
+	}
+	dbname := os.Getenv("TEST_POSTGRES_DB")
+	if dbname == "" {
+		dbname = "booking_rush_test"
+	}
+
+	ctx := context.Background()
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable", user, password, host, dbname)
+
+	setup, err := pgxpool.New(ctx, connStr)
+	if err != nil {
+		t.Fatalf("failed to connect to PostgreSQL: %v", err)
+	}
+	defer setup.Close()
+	if _, err := setup.Exec(ctx, "DROP SCHEMA IF EXISTS "+stateStoreTestSchema+" CASCADE"); err != nil {
+		t.Fatalf("failed to reset schema: %v", err)
+	}
+	if _, err := setup.Exec(ctx, "CREATE SCHEMA "+stateStoreTestSchema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	pool, err := pgxpool.New(ctx, connStr+"&search_path="+stateStoreTestSchema)
+	if err != nil {
+		t.Fatalf("failed to connect to PostgreSQL: %v", err)
+	}
+	if _, err := pool.Exec(ctx, stateStoreTables); err != nil {
+		pool.Close()
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	return pool
+}
+
+func TestPostgresStateStoreArchiveCompletedSagas(t *testing.T) {
+	pool := getStateStorePool(t)
+	defer pool.Close()
+
+	ctx := context.Background()
+	store := NewPostgresStateStore(pool)
+	sm := NewStateMachine(store)
+
+	// Old terminal sagas (should be archived)
+	oldConfirmed, _ := sm.CreateSaga(ctx, "booking-old-confirmed", "event-1", "user-1", nil)
+	sm.MarkReserved(ctx, oldConfirmed.ID, "res-1")
+	sm.MarkPaid(ctx, oldConfirmed.ID, "pay-1")
+	sm.MarkConfirmed(ctx, oldConfirmed.ID, "conf-1")
+
+	oldFailed, _ := sm.CreateSaga(ctx, "booking-old-failed", "event-1", "user-2", nil)
+	sm.MarkFailed(ctx, oldFailed.ID, "reservation failed")
+
+	// Old non-terminal saga (must stay for recovery)
+	oldPending, _ := sm.CreateSaga(ctx, "booking-old-pending", "event-1", "user-3", nil)
+	sm.MarkReserved(ctx, oldPending.ID, "res-3")
+
+	// Backdate everything seeded so far
+	if _, err := pool.Exec(ctx, `UPDATE saga_instances SET completed_at = completed_at - INTERVAL '30 days', updated_at = updated_at - INTERVAL '30 days'`); err != nil {
+		t.Fatalf("failed to backdate sagas: %v", err)
+	}
+
+	// Recent terminal saga (too new to archive)
+	recent, _ := sm.CreateSaga(ctx, "booking-recent", "event-1", "user-4", nil)
+	sm.MarkCancelled(ctx, recent.ID, "user cancelled")
+
+	archived, err := store.ArchiveCompletedSagas(ctx, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("ArchiveCompletedSagas failed: %v", err)
+	}
+	if archived != 2 {
+		t.Errorf("expected 2 archived sagas, got %d", archived)
+	}
+
+	for _, id := range []string{oldConfirmed.ID, oldFailed.ID} {
+		if _, err := store.GetSaga(ctx, id); !errors.Is(err, ErrStateNotFound) {
+			t.Errorf("expected saga %s to be archived, got %v", id, err)
+		}
+		transitions, _ := store.GetTransitions(ctx, id)
+		if len(transitions) != 0 {
+			t.Errorf("expected transitions of %s to be archived, got %d", id, len(transitions))
+		}
+	}
+
+	for _, id := range []string{oldPending.ID, recent.ID} {
+		if _, err := store.GetSaga(ctx, id); err != nil {
+			t.Errorf("expected saga %s to remain, got %v", id, err)
+		}
+	}
+
+	var archivedTransitions int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM saga_transitions_archive`).Scan(&archivedTransitions); err != nil {
+		t.Fatalf("failed to count archived transitions: %v", err)
+	}
+	if archivedTransitions != 4 {
+		t.Errorf("expected 4 archived transitions, got %d", archivedTransitions)
+	}
+}
+
+func TestPostgresStateStoreCountSagasByState(t *testing.T) {
+	pool := getStateStorePool(t)
+	defer pool.Close()
+
+	sm := NewStateMachine(NewPostgresStateStore(pool))
+	want := seedSagasAcrossStates(t, sm)
+
+	got, err := sm.Metrics(context.Background())
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	for state, n := range want {
+		if got[state] != n {
+			t.Errorf("expected %d sagas in %s, got %d", n, state, got[state])
+		}
+	}
+}
@@ -20,6 +20,9 @@ const (
 	StatusFailed       Status = "failed"
 	StatusCompensating Status = "compensating"
 	StatusCompensated  Status = "compensated"
+	// StatusCompensationFailed means at least one compensation could not be
+	// applied and the saga needs manual intervention
+	StatusCompensationFailed Status = "compensation_failed"
 )
 
 // StepStatus represents the status of a saga step
@@ -33,6 +36,8 @@ const (
 	StepStatusCompensating StepStatus = "compensating"
 	StepStatusCompensated  StepStatus = "compensated"
 	StepStatusSkipped      StepStatus = "skipped"
+	// StepStatusCompensationFailed means the step's compensation returned an error
+	StepStatusCompensationFailed StepStatus = "compensation_failed"
 )
 
 // ExecuteFunc is the function signature for step execution
@@ -41,14 +46,30 @@ type ExecuteFunc func(ctx context.Context, data map[string]interface{}) (map[str
 // CompensateFunc is the function signature for step compensation
 type CompensateFunc func(ctx context.Context, data map[string]interface{}) error
 
+// ConditionFunc decides whether a step should run based on the current saga data
+type ConditionFunc func(data map[string]interface{}) bool
+
 // Step represents a single step in a saga
 type Step struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description"`
 	Execute     ExecuteFunc    `json:"-"`
 	Compensate  CompensateFunc `json:"-"`
-	Timeout     time.Duration  `json:"timeout"`
-	Retries     int            `json:"retries"`
+	// ShouldRun is optional; when it returns false the step is recorded as skipped
+	ShouldRun ConditionFunc `json:"-"`
+	Timeout   time.Duration `json:"timeout"`
+	Retries   int           `json:"retries"`
+	// ParallelGroup is non-zero for steps added via AddParallelGroup; consecutive
+	// steps sharing the same group run concurrently
+	ParallelGroup int `json:"parallel_group,omitempty"`
+}
+
+// shouldRun reports whether the step should execute for the given saga data
+func (s *Step) shouldRun(data map[string]interface{}) bool {
+	if s.ShouldRun == nil {
+		return true
+	}
+	return s.ShouldRun(data)
 }
 
 // StepResult represents the result of executing a step
@@ -68,6 +89,11 @@ type Definition struct {
 	Description string        `json:"description"`
 	Steps       []*Step       `json:"steps"`
 	Timeout     time.Duration `json:"timeout"`
+	// IdempotencyKeyField names the saga data field holding the client's idempotency key (optional)
+	IdempotencyKeyField string `json:"idempotency_key_field,omitempty"`
+	// IdempotencyScopeField names the saga data field (e.g. user_id) that scopes idempotency
+	// keys, so the same key sent by different users starts different sagas (optional)
+	IdempotencyScopeField string `json:"idempotency_scope_field,omitempty"`
 }
 
 // NewDefinition creates a new saga definition
@@ -89,12 +115,91 @@ func (d *Definition) AddStep(step *Step) *Definition {
 	return d
 }
 
+// AddParallelGroup adds steps that are executed concurrently. The saga waits
+// for every step in the group before proceeding, and compensates the group
+// concurrently as well. The orchestrator's Logger, EventHandler and
+// OnCompensationFailed hooks are therefore called concurrently for the steps
+// of a group and must be safe for concurrent use.
+func (d *Definition) AddParallelGroup(steps ...*Step) *Definition {
+	group := 1
+	for _, s := range d.Steps {
+		if s.ParallelGroup >= group {
+			group = s.ParallelGroup + 1
+		}
+	}
+
+	for _, step := range steps {
+		step.ParallelGroup = group
+		d.AddStep(step)
+	}
+	return d
+}
+
+// stepGroup returns the step at index i together with the consecutive steps
+// that belong to the same parallel group
+func (d *Definition) stepGroup(i int) []*Step {
+	step := d.Steps[i]
+	if step.ParallelGroup == 0 {
+		return d.Steps[i : i+1]
+	}
+
+	end := i + 1
+	for end < len(d.Steps) && d.Steps[end].ParallelGroup == step.ParallelGroup {
+		end++
+	}
+	return d.Steps[i:end]
+}
+
+// findStep returns the step with the given name, or nil if not found
+func (d *Definition) findStep(name string) *Step {
+	for _, s := range d.Steps {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
 // WithTimeout sets the overall saga timeout
 func (d *Definition) WithTimeout(timeout time.Duration) *Definition {
 	d.Timeout = timeout
 	return d
 }
 
+// WithIdempotencyKey makes executions with the same value in the given data field
+// share one saga instance, so client retries replay the prior outcome instead of
+// re-running the steps
+func (d *Definition) WithIdempotencyKey(field string) *Definition {
+	d.IdempotencyKeyField = field
+	return d
+}
+
+// WithIdempotencyScope prefixes idempotency keys with the value of the given data
+// field, so keys chosen by different callers (users) never collide
+func (d *Definition) WithIdempotencyScope(field string) *Definition {
+	d.IdempotencyScopeField = field
+	return d
+}
+
+// idempotencyKey returns the idempotency key in data, prefixed with its scope
+// when one is configured, or "" if no key is configured or set
+func (d *Definition) idempotencyKey(data map[string]interface{}) string {
+	if d.IdempotencyKeyField == "" {
+		return ""
+	}
+	key, _ := data[d.IdempotencyKeyField].(string)
+	if key == "" || d.IdempotencyScopeField == "" {
+		return key
+	}
+	scope, _ := data[d.IdempotencyScopeField].(string)
+	return scope + ":" + key
+}
+
+// idempotentInstanceID derives a stable instance ID from a definition and idempotency key
+func idempotentInstanceID(definitionName, key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(definitionName+":"+key)).String()
+}
+
 // Instance represents a running or completed saga instance
 type Instance struct {
 	ID           string                 `json:"id"`
@@ -152,6 +257,18 @@ func (i *Instance) AddStepResult(result *StepResult) {
 	i.UpdatedAt = time.Now()
 }
 
+// stepDone reports whether the named step already completed or was skipped
+func (i *Instance) stepDone(stepName string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, result := range i.StepResults {
+		if result.StepName == stepName && (result.Status == StepStatusCompleted || result.Status == StepStatusSkipped) {
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateData merges new data into the saga data
 func (i *Instance) UpdateData(data map[string]interface{}) {
 	i.mu.Lock()
@@ -162,6 +279,19 @@ func (i *Instance) UpdateData(data map[string]interface{}) {
 	i.UpdatedAt = time.Now()
 }
 
+// MergeStepOutput merges the output of a completed step into the saga data.
+//
+// This is the contract between steps: every key returned by a completed step
+// is visible to all later steps, and to compensations, through the data map
+// they receive. Keys returned by later steps overwrite earlier ones. Output of
+// steps that did not complete is ignored.
+func (i *Instance) MergeStepOutput(result *StepResult) {
+	if result == nil || result.Status != StepStatusCompleted || len(result.Data) == 0 {
+		return
+	}
+	i.UpdateData(result.Data)
+}
+
 // GetData returns a copy of the saga data
 func (i *Instance) GetData() map[string]interface{} {
 	i.mu.RLock()
@@ -3,6 +3,8 @@ package saga
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -230,6 +232,43 @@ func TestMemoryStoreGetByStatus(t *testing.T) {
 	}
 }
 
+func TestMemoryStoreGetStatusesByBookingID(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	failed := NewInstance("booking-saga", map[string]interface{}{"booking_id": "booking-1"})
+	failed.Status = StatusFailed
+	store.Save(ctx, failed)
+
+	// A retried saga for the same booking supersedes the failed one
+	retried := NewInstance("booking-saga", map[string]interface{}{"booking_id": "booking-1"})
+	retried.Status = StatusRunning
+	retried.CreatedAt = failed.CreatedAt.Add(time.Second)
+	store.Save(ctx, retried)
+
+	compensated := NewInstance("booking-saga", map[string]interface{}{"booking_id": "booking-2"})
+	compensated.Status = StatusCompensated
+	store.Save(ctx, compensated)
+
+	other := NewInstance("booking-saga", map[string]interface{}{"booking_id": "booking-3"})
+	other.Status = StatusFailed
+	store.Save(ctx, other)
+
+	statuses, err := store.GetStatusesByBookingID(ctx, []string{"booking-1", "booking-2", "booking-missing"})
+	if err != nil {
+		t.Fatalf("failed to get statuses: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %v", statuses)
+	}
+	if statuses["booking-1"] != StatusRunning {
+		t.Errorf("expected latest saga status running for booking-1, got %s", statuses["booking-1"])
+	}
+	if statuses["booking-2"] != StatusCompensated {
+		t.Errorf("expected compensated for booking-2, got %s", statuses["booking-2"])
+	}
+}
+
 func TestOrchestratorRegisterDefinition(t *testing.T) {
 	orch := NewOrchestrator(&OrchestratorConfig{})
 
@@ -435,6 +474,206 @@ func TestOrchestratorExecuteWithTimeout(t *testing.T) {
 	}
 }
 
+func TestOrchestratorSagaTimeoutCompensatesCompletedSteps(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	compensated := false
+	def := NewDefinition("saga-timeout", "Saga exceeding its overall timeout").
+		WithTimeout(100 * time.Millisecond).
+		AddStep(&Step{
+			Name: "reserve",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"reserved": true}, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				compensated = true
+				return nil
+			},
+		}).
+		AddStep(&Step{
+			Name:    "slow-payment",
+			Timeout: time.Second, // longer than the saga timeout
+			Retries: 2,
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Second):
+					return nil, nil
+				}
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	start := time.Now()
+	instance, err := orch.Execute(ctx, "saga-timeout", nil)
+
+	if !errors.Is(err, ErrSagaTimeout) {
+		t.Errorf("expected ErrSagaTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected slow step to be cancelled at the saga timeout, took %v", elapsed)
+	}
+	if instance.Status != StatusCompensated {
+		t.Errorf("expected status 'compensated', got '%s'", instance.Status)
+	}
+	if !compensated {
+		t.Error("expected completed step to be compensated")
+	}
+	if instance.Error == "" {
+		t.Error("expected instance error to be recorded")
+	}
+	if instance.StepResults[1].Status != StepStatusFailed {
+		t.Errorf("expected slow step to be failed, got '%s'", instance.StepResults[1].Status)
+	}
+}
+
+func TestOrchestratorIdempotencyKeyReplaysCompletedSaga(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	executions := 0
+	def := NewDefinition("idempotent-saga", "Saga with idempotency key").
+		WithIdempotencyKey("idempotency_key").
+		AddStep(&Step{
+			Name: "charge",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				executions++
+				return map[string]interface{}{"charge_id": fmt.Sprintf("charge-%d", executions)}, nil
+			},
+		})
+	orch.RegisterDefinition(def)
+
+	data := map[string]interface{}{"idempotency_key": "key-1"}
+	first, err := orch.Execute(ctx, "idempotent-saga", data)
+	if err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+
+	second, err := orch.Execute(ctx, "idempotent-saga", data)
+	if err != nil {
+		t.Fatalf("second execution failed: %v", err)
+	}
+
+	if executions != 1 {
+		t.Errorf("expected step to run once, ran %d times", executions)
+	}
+	if second.ID != first.ID {
+		t.Errorf("expected prior instance %s, got %s", first.ID, second.ID)
+	}
+	if second.Data["charge_id"] != "charge-1" {
+		t.Errorf("expected prior result 'charge-1', got '%v'", second.Data["charge_id"])
+	}
+
+	// A different key runs the saga again
+	if _, err := orch.Execute(ctx, "idempotent-saga", map[string]interface{}{"idempotency_key": "key-2"}); err != nil {
+		t.Fatalf("execution with new key failed: %v", err)
+	}
+	if executions != 2 {
+		t.Errorf("expected step to run for new key, ran %d times", executions)
+	}
+}
+
+func TestOrchestratorIdempotencyKeyRejectsInProgressSaga(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	orch := NewOrchestrator(&OrchestratorConfig{Store: store})
+
+	def := NewDefinition("idempotent-saga", "Saga with idempotency key").
+		WithIdempotencyKey("idempotency_key").
+		AddStep(&Step{
+			Name: "charge",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+		})
+	orch.RegisterDefinition(def)
+
+	running := NewInstance("idempotent-saga", nil)
+	running.ID = idempotentInstanceID("idempotent-saga", "key-1")
+	running.Status = StatusRunning
+	store.Save(ctx, running)
+
+	_, err := orch.Execute(ctx, "idempotent-saga", map[string]interface{}{"idempotency_key": "key-1"})
+	if !errors.Is(err, ErrSagaInProgress) {
+		t.Errorf("expected ErrSagaInProgress, got %v", err)
+	}
+}
+
+func TestOrchestratorIdempotencyKeyScopedPerUser(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var mu sync.Mutex
+	charged := make(map[string]int)
+	def := NewDefinition("idempotent-saga", "Saga with per-user idempotency keys").
+		WithIdempotencyKey("idempotency_key").
+		WithIdempotencyScope("user_id").
+		AddStep(&Step{
+			Name: "charge",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				mu.Lock()
+				charged[data["user_id"].(string)]++
+				mu.Unlock()
+				return nil, nil
+			},
+		})
+	orch.RegisterDefinition(def)
+
+	for _, userID := range []string{"user-1", "user-2", "user-1"} {
+		data := map[string]interface{}{"idempotency_key": "key-1", "user_id": userID}
+		if _, err := orch.Execute(ctx, "idempotent-saga", data); err != nil {
+			t.Fatalf("execution for %s failed: %v", userID, err)
+		}
+	}
+
+	if charged["user-1"] != 1 || charged["user-2"] != 1 {
+		t.Errorf("expected one charge per user for the shared key, got %v", charged)
+	}
+}
+
+func TestOrchestratorIdempotencyKeyConcurrentExecutionsRunOnce(t *testing.T) {
+	ctx := context.Background()
+	store := NewRedisStore(newFakeRedisClient(), "", 0)
+	orch := NewOrchestrator(&OrchestratorConfig{Store: store})
+
+	var executions int32
+	release := make(chan struct{})
+	def := NewDefinition("idempotent-saga", "Saga with idempotency key").
+		WithIdempotencyKey("idempotency_key").
+		AddStep(&Step{
+			Name: "charge",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				atomic.AddInt32(&executions, 1)
+				<-release
+				return nil, nil
+			},
+		})
+	orch.RegisterDefinition(def)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orch.Execute(ctx, "idempotent-saga", map[string]interface{}{"idempotency_key": "key-1"})
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&executions); n != 1 {
+		t.Errorf("expected the step to run once, ran %d times", n)
+	}
+}
+
 func TestOrchestratorGetInstance(t *testing.T) {
 	ctx := context.Background()
 	orch := NewOrchestrator(&OrchestratorConfig{})
@@ -504,3 +743,466 @@ func TestStepResultFields(t *testing.T) {
 		t.Errorf("expected duration >= 10ms, got %v", result.Duration)
 	}
 }
+
+func TestOrchestratorCompensationFailure(t *testing.T) {
+	ctx := context.Background()
+
+	var failedStep string
+	var failedErr error
+	orch := NewOrchestrator(&OrchestratorConfig{
+		OnCompensationFailed: func(instance *Instance, step *Step, err error) {
+			failedStep = step.Name
+			failedErr = err
+		},
+	})
+
+	var step2Compensated bool
+
+	def := NewDefinition("compensation-failure-saga", "Saga whose compensation fails").
+		AddStep(&Step{
+			Name: "reserve-seats",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				return errors.New("release failed")
+			},
+		}).
+		AddStep(&Step{
+			Name: "process-payment",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				step2Compensated = true
+				return nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "confirm-booking",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("confirm failed")
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	instance, err := orch.Execute(ctx, "compensation-failure-saga", nil)
+	if err == nil {
+		t.Fatal("expected error due to step failure")
+	}
+
+	if instance.Status != StatusCompensationFailed {
+		t.Errorf("expected status 'compensation_failed', got '%s'", instance.Status)
+	}
+
+	// Remaining compensations still run after one fails
+	if !step2Compensated {
+		t.Error("process-payment should still have been compensated")
+	}
+
+	if failedStep != "reserve-seats" {
+		t.Errorf("expected hook for 'reserve-seats', got '%s'", failedStep)
+	}
+	if failedErr == nil {
+		t.Error("expected hook to receive the compensation error")
+	}
+
+	statuses := make(map[string]StepStatus)
+	for _, r := range instance.StepResults {
+		statuses[r.StepName] = r.Status
+	}
+	if statuses["reserve-seats"] != StepStatusCompensationFailed {
+		t.Errorf("expected reserve-seats 'compensation_failed', got '%s'", statuses["reserve-seats"])
+	}
+	if statuses["process-payment"] != StepStatusCompensated {
+		t.Errorf("expected process-payment 'compensated', got '%s'", statuses["process-payment"])
+	}
+
+	// Resume must not retry a dead-lettered saga
+	resumed, err := orch.Resume(ctx, instance.ID)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if resumed.Status != StatusCompensationFailed {
+		t.Errorf("expected resumed status 'compensation_failed', got '%s'", resumed.Status)
+	}
+}
+
+func TestOrchestratorSkipsStepWhenConditionFalse(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var notifyExecuted, auditExecuted bool
+
+	def := NewDefinition("conditional-saga", "Saga with a conditional step").
+		AddStep(&Step{
+			Name: "confirm-booking",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"confirmation_code": "CONF-1"}, nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "send-notification",
+			ShouldRun: func(data map[string]interface{}) bool {
+				notify, ok := data["notify"].(bool)
+				return !ok || notify
+			},
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				notifyExecuted = true
+				return nil, nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "write-audit",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				auditExecuted = true
+				return nil, nil
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	instance, err := orch.Execute(ctx, "conditional-saga", map[string]interface{}{"notify": false})
+	if err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+
+	if notifyExecuted {
+		t.Error("send-notification should have been skipped")
+	}
+	if !auditExecuted {
+		t.Error("write-audit should still run after a skipped step")
+	}
+	if instance.Status != StatusCompleted {
+		t.Errorf("expected status 'completed', got '%s'", instance.Status)
+	}
+
+	if len(instance.StepResults) != 3 {
+		t.Fatalf("expected 3 step results, got %d", len(instance.StepResults))
+	}
+	if instance.StepResults[1].Status != StepStatusSkipped {
+		t.Errorf("expected send-notification 'skipped', got '%s'", instance.StepResults[1].Status)
+	}
+	if instance.StepResults[2].Status != StepStatusCompleted {
+		t.Errorf("expected write-audit 'completed', got '%s'", instance.StepResults[2].Status)
+	}
+}
+
+func TestOrchestratorRunsStepWhenConditionTrue(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var notifyExecuted bool
+
+	def := NewDefinition("conditional-saga", "Saga with a conditional step").
+		AddStep(&Step{
+			Name: "send-notification",
+			ShouldRun: func(data map[string]interface{}) bool {
+				notify, _ := data["notify"].(bool)
+				return notify
+			},
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				notifyExecuted = true
+				return nil, nil
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	if _, err := orch.Execute(ctx, "conditional-saga", map[string]interface{}{"notify": true}); err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+
+	if !notifyExecuted {
+		t.Error("send-notification should have run when notify=true")
+	}
+}
+
+func TestAddParallelGroup(t *testing.T) {
+	noop := func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	def := NewDefinition("parallel-saga", "Saga with parallel groups").
+		AddStep(&Step{Name: "confirm", Execute: noop}).
+		AddParallelGroup(
+			&Step{Name: "notify", Execute: noop},
+			&Step{Name: "analytics", Execute: noop},
+		).
+		AddParallelGroup(
+			&Step{Name: "cleanup", Execute: noop},
+		)
+
+	if def.Steps[0].ParallelGroup != 0 {
+		t.Errorf("expected sequential step to have no group, got %d", def.Steps[0].ParallelGroup)
+	}
+	if def.Steps[1].ParallelGroup != 1 || def.Steps[2].ParallelGroup != 1 {
+		t.Errorf("expected first group to be 1, got %d and %d", def.Steps[1].ParallelGroup, def.Steps[2].ParallelGroup)
+	}
+	if def.Steps[3].ParallelGroup != 2 {
+		t.Errorf("expected second group to be 2, got %d", def.Steps[3].ParallelGroup)
+	}
+	if len(def.stepGroup(1)) != 2 {
+		t.Errorf("expected group of 2 steps, got %d", len(def.stepGroup(1)))
+	}
+}
+
+func TestOrchestratorParallelGroupRunsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var running, maxRunning int32
+	parallelStep := func(name string) *Step {
+		return &Step{
+			Name: name,
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return map[string]interface{}{name + "_done": true}, nil
+			},
+		}
+	}
+
+	var afterGroupSawData bool
+	def := NewDefinition("parallel-saga", "Saga with parallel side-effects").
+		AddParallelGroup(
+			parallelStep("send-notification"),
+			parallelStep("write-analytics"),
+			parallelStep("warm-cache"),
+		).
+		AddStep(&Step{
+			Name: "finish",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				afterGroupSawData = data["send-notification_done"] == true &&
+					data["write-analytics_done"] == true &&
+					data["warm-cache_done"] == true
+				return nil, nil
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	start := time.Now()
+	instance, err := orch.Execute(ctx, "parallel-saga", nil)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("saga execution failed: %v", err)
+	}
+	if instance.Status != StatusCompleted {
+		t.Errorf("expected status 'completed', got '%s'", instance.Status)
+	}
+	if atomic.LoadInt32(&maxRunning) != 3 {
+		t.Errorf("expected 3 steps running concurrently, got %d", atomic.LoadInt32(&maxRunning))
+	}
+	if elapsed >= 250*time.Millisecond {
+		t.Errorf("expected parallel group to take ~100ms, took %v", elapsed)
+	}
+	if !afterGroupSawData {
+		t.Error("step after the group should see all group outputs")
+	}
+	if len(instance.StepResults) != 4 {
+		t.Errorf("expected 4 step results, got %d", len(instance.StepResults))
+	}
+}
+
+func TestOrchestratorParallelGroupFailureCompensatesGroup(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var reserveCompensated, notifyCompensated, analyticsCompensated atomic.Bool
+
+	def := NewDefinition("parallel-saga", "Saga with a failing parallel group").
+		AddStep(&Step{
+			Name: "reserve-seats",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				// The group must be compensated before earlier steps
+				if !notifyCompensated.Load() {
+					return errors.New("group not compensated first")
+				}
+				reserveCompensated.Store(true)
+				return nil
+			},
+		}).
+		AddParallelGroup(
+			&Step{
+				Name: "send-notification",
+				Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+					return nil, nil
+				},
+				Compensate: func(ctx context.Context, data map[string]interface{}) error {
+					notifyCompensated.Store(true)
+					return nil
+				},
+			},
+			&Step{
+				Name: "write-analytics",
+				Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+					return nil, errors.New("analytics unavailable")
+				},
+				Compensate: func(ctx context.Context, data map[string]interface{}) error {
+					analyticsCompensated.Store(true)
+					return nil
+				},
+			},
+		)
+
+	orch.RegisterDefinition(def)
+
+	instance, err := orch.Execute(ctx, "parallel-saga", nil)
+	if err == nil {
+		t.Fatal("expected error due to parallel step failure")
+	}
+
+	if instance.Status != StatusCompensated {
+		t.Errorf("expected status 'compensated', got '%s'", instance.Status)
+	}
+	if !notifyCompensated.Load() {
+		t.Error("successful parallel step should have been compensated")
+	}
+	if analyticsCompensated.Load() {
+		t.Error("failed parallel step should not be compensated")
+	}
+	if !reserveCompensated.Load() {
+		t.Error("step before the group should have been compensated after it")
+	}
+}
+
+func TestOrchestratorParallelGroupCallsHooksConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	// Each hook call waits for the other, so sequential calls would time out
+	var inFlight atomic.Int32
+	var concurrent atomic.Bool
+	var failedSteps sync.Map
+	orch := NewOrchestrator(&OrchestratorConfig{
+		OnCompensationFailed: func(instance *Instance, step *Step, err error) {
+			failedSteps.Store(step.Name, true)
+			inFlight.Add(1)
+			defer inFlight.Add(-1)
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) {
+				if inFlight.Load() == 2 {
+					concurrent.Store(true)
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		},
+	})
+
+	failingStep := func(name string) *Step {
+		return &Step{
+			Name: name,
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				return errors.New("compensation unavailable")
+			},
+		}
+	}
+
+	def := NewDefinition("parallel-hooks-saga", "Saga whose group compensations fail").
+		AddParallelGroup(failingStep("send-notification"), failingStep("write-analytics")).
+		AddStep(&Step{
+			Name: "confirm-booking",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("confirmation failed")
+			},
+		})
+	orch.RegisterDefinition(def)
+
+	instance, err := orch.Execute(ctx, "parallel-hooks-saga", nil)
+	if err == nil {
+		t.Fatal("expected error due to failing step")
+	}
+	if instance.Status != StatusCompensationFailed {
+		t.Errorf("expected status 'compensation_failed', got '%s'", instance.Status)
+	}
+	for _, name := range []string{"send-notification", "write-analytics"} {
+		if _, ok := failedSteps.Load(name); !ok {
+			t.Errorf("expected OnCompensationFailed for %s", name)
+		}
+	}
+	if !concurrent.Load() {
+		t.Error("expected OnCompensationFailed to be called concurrently for the group")
+	}
+}
+
+func TestInstanceMergeStepOutput(t *testing.T) {
+	instance := NewInstance("test-saga", map[string]interface{}{
+		"booking_id": "book-1",
+	})
+
+	instance.MergeStepOutput(&StepResult{
+		StepName: "reserve-seats",
+		Status:   StepStatusCompleted,
+		Data:     map[string]interface{}{"reservation_id": "res-1"},
+	})
+	instance.MergeStepOutput(&StepResult{
+		StepName: "process-payment",
+		Status:   StepStatusFailed,
+		Data:     map[string]interface{}{"payment_id": "pay-1"},
+	})
+	instance.MergeStepOutput(nil)
+
+	data := instance.GetData()
+	if data["booking_id"] != "book-1" {
+		t.Errorf("expected booking_id to be preserved, got '%v'", data["booking_id"])
+	}
+	if data["reservation_id"] != "res-1" {
+		t.Errorf("expected reservation_id 'res-1', got '%v'", data["reservation_id"])
+	}
+	if _, exists := data["payment_id"]; exists {
+		t.Error("output of a failed step must not be merged")
+	}
+}
+
+func TestOrchestratorStepSeesPreviousStepOutput(t *testing.T) {
+	ctx := context.Background()
+	orch := NewOrchestrator(&OrchestratorConfig{})
+
+	var seenByPayment, seenByCompensation interface{}
+
+	def := NewDefinition("booking-saga", "Booking saga").
+		AddStep(&Step{
+			Name: "reserve-seats",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"reservation_id": "res-123"}, nil
+			},
+			Compensate: func(ctx context.Context, data map[string]interface{}) error {
+				seenByCompensation = data["reservation_id"]
+				return nil
+			},
+		}).
+		AddStep(&Step{
+			Name: "process-payment",
+			Execute: func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+				seenByPayment = data["reservation_id"]
+				return nil, errors.New("payment failed")
+			},
+		})
+
+	orch.RegisterDefinition(def)
+
+	orch.Execute(ctx, "booking-saga", map[string]interface{}{"booking_id": "book-1"})
+
+	if seenByPayment != "res-123" {
+		t.Errorf("expected process-payment to see reservation_id 'res-123', got '%v'", seenByPayment)
+	}
+	if seenByCompensation != "res-123" {
+		t.Errorf("expected compensation to see reservation_id 'res-123', got '%v'", seenByCompensation)
+	}
+}
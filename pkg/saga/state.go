@@ -26,14 +26,22 @@ var (
 	ErrInvalidStateTransition = errors.New("invalid state transition")
 	// ErrStateNotFound is returned when a saga state is not found
 	ErrStateNotFound = errors.New("saga state not found")
+	// ErrConcurrentModification is returned when a saga was updated by someone else since it was read
+	ErrConcurrentModification = errors.New("saga was modified concurrently")
+	// ErrMaxRetriesExceeded is returned when a failed saga has used up its retries
+	ErrMaxRetriesExceeded = errors.New("saga retry limit reached")
 )
 
+// DefaultMaxRetries is the number of times a failed saga may be retried by default
+const DefaultMaxRetries = 3
+
 // validTransitions defines allowed state transitions
-// Key is current state, value is list of allowed next states
+// Key is current state, value is list of allowed next states.
+// PAID -> CANCELLED is only allowed through MarkCancelledWithRefund.
 var validTransitions = map[BookingState][]BookingState{
 	StateCreated:   {StateReserved, StateFailed, StateCancelled},
 	StateReserved:  {StatePaid, StateFailed, StateCancelled},
-	StatePaid:      {StateConfirmed, StateFailed},
+	StatePaid:      {StateConfirmed, StateFailed, StateCancelled},
 	StateConfirmed: {}, // Terminal state
 	StateFailed:    {}, // Terminal state
 	StateCancelled: {}, // Terminal state
@@ -76,8 +84,10 @@ type BookingSaga struct {
 	ReservationID  string                 `json:"reservation_id,omitempty"`
 	PaymentID      string                 `json:"payment_id,omitempty"`
 	ConfirmationID string                 `json:"confirmation_id,omitempty"`
+	RefundID       string                 `json:"refund_id,omitempty"`
 	ErrorMessage   string                 `json:"error_message,omitempty"`
 	RetryCount     int                    `json:"retry_count"`
+	Version        int                    `json:"version"`
 	CreatedAt      time.Time              `json:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at"`
 	CompletedAt    *time.Time             `json:"completed_at,omitempty"`
@@ -93,10 +103,19 @@ type StateTransition struct {
 	Timestamp time.Time    `json:"timestamp"`
 }
 
+// HoldReleaser returns the seats held for a booking to inventory. It is the
+// compensation run when a RESERVED saga is cancelled, and must treat a hold
+// that has already expired or been released as success.
+type HoldReleaser interface {
+	ReleaseHold(ctx context.Context, saga *BookingSaga) error
+}
+
 // StateMachine manages state transitions for booking sagas
 type StateMachine struct {
-	store       StateStore
-	transitions []StateTransition
+	store        StateStore
+	transitions  []StateTransition
+	maxRetries   int
+	holdReleaser HoldReleaser
 }
 
 // StateStore interface for persisting saga states
@@ -107,7 +126,8 @@ type StateStore interface {
 	GetSaga(ctx context.Context, id string) (*BookingSaga, error)
 	// GetSagaByBookingID retrieves a saga by booking ID
 	GetSagaByBookingID(ctx context.Context, bookingID string) (*BookingSaga, error)
-	// UpdateSaga updates an existing saga
+	// UpdateSaga updates an existing saga if its version still matches the stored one,
+	// incrementing the version on success; otherwise it returns ErrConcurrentModification
 	UpdateSaga(ctx context.Context, saga *BookingSaga) error
 	// SaveTransition persists a state transition
 	SaveTransition(ctx context.Context, transition *StateTransition) error
@@ -122,9 +142,24 @@ func NewStateMachine(store StateStore) *StateMachine {
 	return &StateMachine{
 		store:       store,
 		transitions: make([]StateTransition, 0),
+		maxRetries:  DefaultMaxRetries,
 	}
 }
 
+// WithMaxRetries sets how many times a failed saga may be retried
+func (sm *StateMachine) WithMaxRetries(maxRetries int) *StateMachine {
+	sm.maxRetries = maxRetries
+	return sm
+}
+
+// WithHoldReleaser sets the compensation MarkCancelled runs before cancelling
+// a RESERVED saga, so cancelled bookings do not keep their seats until the
+// hold expires
+func (sm *StateMachine) WithHoldReleaser(releaser HoldReleaser) *StateMachine {
+	sm.holdReleaser = releaser
+	return sm
+}
+
 // CreateSaga creates a new booking saga in CREATED state
 func (sm *StateMachine) CreateSaga(ctx context.Context, bookingID, eventID, userID string, data map[string]interface{}) (*BookingSaga, error) {
 	now := time.Now()
@@ -157,25 +192,30 @@ func (sm *StateMachine) TransitionTo(ctx context.Context, sagaID string, newStat
 		return nil, fmt.Errorf("failed to get saga: %w", err)
 	}
 
+	// A paid booking can only be cancelled together with a refund
+	if saga.State == StatePaid && newState == StateCancelled {
+		return nil, fmt.Errorf("%w: cancelling a PAID booking requires a refund", ErrInvalidStateTransition)
+	}
+
+	return sm.transition(ctx, saga, newState, reason)
+}
+
+// transition validates and applies a state change to a loaded saga
+func (sm *StateMachine) transition(ctx context.Context, saga *BookingSaga, newState BookingState, reason string) (*BookingSaga, error) {
 	// Validate transition
 	if !saga.State.CanTransitionTo(newState) {
 		return nil, fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStateTransition, saga.State, newState)
 	}
 
-	// Record transition
 	transition := &StateTransition{
 		ID:        generateID(),
-		SagaID:    sagaID,
+		SagaID:    saga.ID,
 		FromState: saga.State,
 		ToState:   newState,
 		Reason:    reason,
 		Timestamp: time.Now(),
 	}
 
-	if err := sm.store.SaveTransition(ctx, transition); err != nil {
-		return nil, fmt.Errorf("failed to save transition: %w", err)
-	}
-
 	// Update saga state
 	saga.PreviousState = saga.State
 	saga.State = newState
@@ -191,6 +231,12 @@ func (sm *StateMachine) TransitionTo(ctx context.Context, sagaID string, newStat
 		return nil, fmt.Errorf("failed to update saga: %w", err)
 	}
 
+	// Record the transition only once the versioned update has won, so a
+	// writer that lost the race leaves no transition that never happened
+	if err := sm.store.SaveTransition(ctx, transition); err != nil {
+		return nil, fmt.Errorf("failed to save transition: %w", err)
+	}
+
 	return saga, nil
 }
 
@@ -277,7 +323,87 @@ func (sm *StateMachine) MarkCancelled(ctx context.Context, sagaID, reason string
 		return nil, fmt.Errorf("%w: can only cancel from CREATED or RESERVED state", ErrInvalidStateTransition)
 	}
 
-	return sm.TransitionTo(ctx, sagaID, StateCancelled, reason)
+	// Release the hold first: if that fails the saga stays RESERVED and the
+	// cancellation can be retried
+	if saga.State == StateReserved && sm.holdReleaser != nil {
+		if err := sm.holdReleaser.ReleaseHold(ctx, saga); err != nil {
+			return nil, fmt.Errorf("failed to release hold for booking %s: %w", saga.BookingID, err)
+		}
+	}
+
+	return sm.transition(ctx, saga, StateCancelled, reason)
+}
+
+// MarkCancelledWithRefund cancels a PAID booking that has not been confirmed yet,
+// recording the refund issued for its payment
+func (sm *StateMachine) MarkCancelledWithRefund(ctx context.Context, sagaID, reason, refundID string) (*BookingSaga, error) {
+	if refundID == "" {
+		return nil, fmt.Errorf("%w: refund ID is required", ErrInvalidStateTransition)
+	}
+
+	saga, err := sm.store.GetSaga(ctx, sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+
+	if saga.State != StatePaid {
+		return nil, fmt.Errorf("%w: can only cancel with refund from PAID state", ErrInvalidStateTransition)
+	}
+
+	// Written in the same versioned update as the state, so a cancelled saga
+	// never lacks its refund
+	saga.RefundID = refundID
+	return sm.transition(ctx, saga, StateCancelled, reason)
+}
+
+// RetryFailed moves a FAILED saga back to the state it failed from (or CREATED)
+// so it can be reprocessed. It returns ErrMaxRetriesExceeded once the saga has
+// failed maxRetries times.
+func (sm *StateMachine) RetryFailed(ctx context.Context, sagaID string) (*BookingSaga, error) {
+	saga, err := sm.store.GetSaga(ctx, sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+
+	if saga.State != StateFailed {
+		return nil, fmt.Errorf("%w: can only retry from FAILED state", ErrInvalidStateTransition)
+	}
+
+	if saga.RetryCount >= sm.maxRetries {
+		return nil, fmt.Errorf("%w: saga %s failed %d times", ErrMaxRetriesExceeded, sagaID, saga.RetryCount)
+	}
+
+	// Resume from the state the saga failed in
+	target := saga.PreviousState
+	if target == "" || target.IsTerminal() {
+		target = StateCreated
+	}
+
+	transition := &StateTransition{
+		ID:        generateID(),
+		SagaID:    sagaID,
+		FromState: saga.State,
+		ToState:   target,
+		Reason:    fmt.Sprintf("Retry %d of %d", saga.RetryCount, sm.maxRetries),
+		Timestamp: time.Now(),
+	}
+
+	saga.PreviousState = saga.State
+	saga.State = target
+	saga.ErrorMessage = ""
+	saga.CompletedAt = nil
+	saga.UpdatedAt = time.Now()
+
+	if err := sm.store.UpdateSaga(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to update saga: %w", err)
+	}
+
+	// As in transition, only a retry whose versioned update won is recorded
+	if err := sm.store.SaveTransition(ctx, transition); err != nil {
+		return nil, fmt.Errorf("failed to save transition: %w", err)
+	}
+
+	return saga, nil
 }
 
 // GetSaga retrieves a saga by ID
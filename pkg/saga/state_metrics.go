@@ -0,0 +1,121 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// allStates lists every booking state, in lifecycle order
+var allStates = []BookingState{
+	StateCreated, StateReserved, StatePaid, StateConfirmed, StateFailed, StateCancelled,
+}
+
+// StateCounter is implemented by stores that can count sagas per state in a
+// single query. StateMachine.Metrics falls back to GetSagasByState without it.
+type StateCounter interface {
+	// CountSagasByState returns the number of sagas in each state; states with
+	// no sagas may be omitted
+	CountSagasByState(ctx context.Context) (map[BookingState]int, error)
+}
+
+// Metrics returns how many sagas are in each state. Every state is present in
+// the result, with zero for states that have no sagas.
+func (sm *StateMachine) Metrics(ctx context.Context) (map[BookingState]int, error) {
+	counts := make(map[BookingState]int, len(allStates))
+	for _, state := range allStates {
+		counts[state] = 0
+	}
+
+	if counter, ok := sm.store.(StateCounter); ok {
+		stored, err := counter.CountSagasByState(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count sagas by state: %w", err)
+		}
+		for state, n := range stored {
+			counts[state] = n
+		}
+		return counts, nil
+	}
+
+	for _, state := range allStates {
+		sagas, err := sm.store.GetSagasByState(ctx, state, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sagas in state %s: %w", state, err)
+		}
+		counts[state] = len(sagas)
+	}
+	return counts, nil
+}
+
+// StateMetricsExporter publishes StateMachine.Metrics as the saga_state_count
+// gauge, one series per state. Counts are refreshed on an interval rather than
+// on every collection so a slow store never stalls the metrics pipeline.
+type StateMetricsExporter struct {
+	sm       *StateMachine
+	interval time.Duration
+
+	mu     sync.RWMutex
+	counts map[BookingState]int
+}
+
+// NewStateMetricsExporter registers the saga_state_count gauge on meter.
+// Call Start to begin refreshing the counts.
+func NewStateMetricsExporter(sm *StateMachine, meter metric.Meter, interval time.Duration) (*StateMetricsExporter, error) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	e := &StateMetricsExporter{sm: sm, interval: interval}
+
+	_, err := meter.Int64ObservableGauge("saga_state_count",
+		metric.WithDescription("Booking sagas currently in each state"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			e.mu.RLock()
+			defer e.mu.RUnlock()
+			for state, n := range e.counts {
+				o.Observe(int64(n), metric.WithAttributes(attribute.String("state", string(state))))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register saga_state_count gauge: %w", err)
+	}
+	return e, nil
+}
+
+// Refresh reloads the counts the gauge reports. On error the previous counts
+// are kept.
+func (e *StateMetricsExporter) Refresh(ctx context.Context) error {
+	counts, err := e.sm.Metrics(ctx)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.counts = counts
+	e.mu.Unlock()
+	return nil
+}
+
+// Start refreshes the counts immediately and then every interval until ctx is
+// cancelled. onError, if set, receives refresh failures.
+func (e *StateMetricsExporter) Start(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Refresh(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
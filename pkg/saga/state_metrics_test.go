@@ -0,0 +1,144 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// listOnlyStore hides CountSagasByState so Metrics takes the GetSagasByState path
+type listOnlyStore struct {
+	StateStore
+}
+
+// seedSagasAcrossStates creates 3 CREATED, 2 RESERVED, 1 PAID and 1 CANCELLED saga
+func seedSagasAcrossStates(t *testing.T, sm *StateMachine) map[BookingState]int {
+	t.Helper()
+	ctx := context.Background()
+
+	create := func(bookingID string) *BookingSaga {
+		saga, err := sm.CreateSaga(ctx, bookingID, "event-1", "user-"+bookingID, nil)
+		if err != nil {
+			t.Fatalf("CreateSaga failed: %v", err)
+		}
+		return saga
+	}
+	must := func(_ *BookingSaga, err error) {
+		if err != nil {
+			t.Fatalf("transition failed: %v", err)
+		}
+	}
+
+	create("booking-c1")
+	create("booking-c2")
+	create("booking-c3")
+	must(sm.MarkReserved(ctx, create("booking-r1").ID, "res-1"))
+	must(sm.MarkReserved(ctx, create("booking-r2").ID, "res-2"))
+	paid := create("booking-p1")
+	must(sm.MarkReserved(ctx, paid.ID, "res-3"))
+	must(sm.MarkPaid(ctx, paid.ID, "pay-1"))
+	must(sm.MarkCancelled(ctx, create("booking-x1").ID, "user cancelled"))
+
+	return map[BookingState]int{
+		StateCreated:   3,
+		StateReserved:  2,
+		StatePaid:      1,
+		StateConfirmed: 0,
+		StateFailed:    0,
+		StateCancelled: 1,
+	}
+}
+
+func TestStateMachineMetrics(t *testing.T) {
+	stores := map[string]func() StateStore{
+		"state counter": func() StateStore { return NewMemoryStateStore() },
+		"list fallback": func() StateStore { return listOnlyStore{NewMemoryStateStore()} },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			sm := NewStateMachine(newStore())
+			want := seedSagasAcrossStates(t, sm)
+
+			got, err := sm.Metrics(context.Background())
+			if err != nil {
+				t.Fatalf("Metrics failed: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Errorf("expected %d states, got %d: %v", len(want), len(got), got)
+			}
+			for state, n := range want {
+				if got[state] != n {
+					t.Errorf("expected %d sagas in %s, got %d", n, state, got[state])
+				}
+			}
+		})
+	}
+}
+
+// failingCountStore fails every count
+type failingCountStore struct {
+	*MemoryStateStore
+}
+
+func (failingCountStore) CountSagasByState(ctx context.Context) (map[BookingState]int, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestStateMachineMetricsError(t *testing.T) {
+	sm := NewStateMachine(failingCountStore{NewMemoryStateStore()})
+	if _, err := sm.Metrics(context.Background()); err == nil {
+		t.Fatal("expected Metrics to fail")
+	}
+}
+
+func TestStateMetricsExporter(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(ctx)
+
+	sm := NewStateMachine(NewMemoryStateStore())
+	want := seedSagasAcrossStates(t, sm)
+
+	exporter, err := NewStateMetricsExporter(sm, provider.Meter("saga-test"), time.Minute)
+	if err != nil {
+		t.Fatalf("NewStateMetricsExporter failed: %v", err)
+	}
+	if err := exporter.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	got := make(map[BookingState]int)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "saga_state_count" {
+				continue
+			}
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok {
+				t.Fatalf("expected int64 gauge, got %T", m.Data)
+			}
+			for _, dp := range gauge.DataPoints {
+				state, _ := dp.Attributes.Value(attribute.Key("state"))
+				got[BookingState(state.AsString())] = int(dp.Value)
+			}
+		}
+	}
+
+	for state, n := range want {
+		if got[state] != n {
+			t.Errorf("expected gauge %d for %s, got %d", n, state, got[state])
+		}
+	}
+}
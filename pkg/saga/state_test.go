@@ -2,6 +2,10 @@ package saga
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
 	"testing"
 )
 
@@ -74,7 +78,7 @@ func TestBookingStateCanTransitionTo(t *testing.T) {
 		// From PAID
 		{"PAID -> CONFIRMED", StatePaid, StateConfirmed, true},
 		{"PAID -> FAILED", StatePaid, StateFailed, true},
-		{"PAID -> CANCELLED", StatePaid, StateCancelled, false},
+		{"PAID -> CANCELLED", StatePaid, StateCancelled, true},
 		{"PAID -> RESERVED", StatePaid, StateReserved, false},
 
 		// Terminal states
@@ -260,6 +264,60 @@ func TestStateMachineMarkCancelled(t *testing.T) {
 	}
 }
 
+// recordingHoldReleaser records the bookings whose holds it released
+type recordingHoldReleaser struct {
+	released []string
+	err      error
+}
+
+func (r *recordingHoldReleaser) ReleaseHold(ctx context.Context, saga *BookingSaga) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.released = append(r.released, saga.BookingID)
+	return nil
+}
+
+func TestStateMachineMarkCancelledReleasesHold(t *testing.T) {
+	ctx := context.Background()
+	releaser := &recordingHoldReleaser{}
+	sm := NewStateMachine(NewMemoryStateStore()).WithHoldReleaser(releaser)
+
+	reserved, _ := sm.CreateSaga(ctx, "booking-reserved", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, reserved.ID, "res-abc123")
+	created, _ := sm.CreateSaga(ctx, "booking-created", "event-456", "user-789", nil)
+
+	if _, err := sm.MarkCancelled(ctx, reserved.ID, "User requested cancellation"); err != nil {
+		t.Fatalf("MarkCancelled failed: %v", err)
+	}
+	if _, err := sm.MarkCancelled(ctx, created.ID, "User requested cancellation"); err != nil {
+		t.Fatalf("MarkCancelled failed: %v", err)
+	}
+
+	// Only the RESERVED saga held seats
+	if len(releaser.released) != 1 || releaser.released[0] != "booking-reserved" {
+		t.Errorf("expected only booking-reserved to be released, got %v", releaser.released)
+	}
+}
+
+func TestStateMachineMarkCancelledHoldReleaseFails(t *testing.T) {
+	ctx := context.Background()
+	releaseErr := errors.New("redis unavailable")
+	sm := NewStateMachine(NewMemoryStateStore()).WithHoldReleaser(&recordingHoldReleaser{err: releaseErr})
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, saga.ID, "res-abc123")
+
+	if _, err := sm.MarkCancelled(ctx, saga.ID, "User requested cancellation"); !errors.Is(err, releaseErr) {
+		t.Fatalf("expected release error, got %v", err)
+	}
+
+	got, _ := sm.GetSaga(ctx, saga.ID)
+	if got.State != StateReserved {
+		t.Errorf("expected saga to stay RESERVED for retry, got %s", got.State)
+	}
+}
+
 func TestStateMachineCannotCancelAfterPaid(t *testing.T) {
 	ctx := context.Background()
 	store := NewMemoryStateStore()
@@ -275,6 +333,86 @@ func TestStateMachineCannotCancelAfterPaid(t *testing.T) {
 	}
 }
 
+func TestStateMachineMarkCancelledWithRefund(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, saga.ID, "res-abc123")
+	sm.MarkPaid(ctx, saga.ID, "pay-xyz789")
+	paid, _ := sm.GetSaga(ctx, saga.ID)
+
+	updated, err := sm.MarkCancelledWithRefund(ctx, saga.ID, "Cancelled by support", "refund-001")
+	if err != nil {
+		t.Fatalf("MarkCancelledWithRefund failed: %v", err)
+	}
+
+	if updated.State != StateCancelled {
+		t.Errorf("expected state 'CANCELLED', got '%s'", updated.State)
+	}
+	if updated.PreviousState != StatePaid {
+		t.Errorf("expected previous state 'PAID', got '%s'", updated.PreviousState)
+	}
+	if updated.CompletedAt == nil {
+		t.Error("expected CompletedAt to be set")
+	}
+
+	stored, _ := sm.GetSaga(ctx, saga.ID)
+	if stored.RefundID != "refund-001" {
+		t.Errorf("expected refund ID 'refund-001', got '%s'", stored.RefundID)
+	}
+	// The state and refund ID are written in one versioned update
+	if stored.Version != paid.Version+1 {
+		t.Errorf("expected one update (version %d), got version %d", paid.Version+1, stored.Version)
+	}
+
+	history, _ := sm.GetTransitionHistory(ctx, saga.ID)
+	last := history[len(history)-1]
+	if last.FromState != StatePaid || last.ToState != StateCancelled || last.Reason != "Cancelled by support" {
+		t.Errorf("unexpected last transition %s -> %s (%s)", last.FromState, last.ToState, last.Reason)
+	}
+}
+
+func TestStateMachineCannotCancelPaidWithoutRefund(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, saga.ID, "res-abc123")
+	sm.MarkPaid(ctx, saga.ID, "pay-xyz789")
+
+	if _, err := sm.TransitionTo(ctx, saga.ID, StateCancelled, "no refund"); !errors.Is(err, ErrInvalidStateTransition) {
+		t.Errorf("expected ErrInvalidStateTransition, got %v", err)
+	}
+
+	if _, err := sm.MarkCancelledWithRefund(ctx, saga.ID, "no refund", ""); !errors.Is(err, ErrInvalidStateTransition) {
+		t.Errorf("expected ErrInvalidStateTransition for empty refund ID, got %v", err)
+	}
+}
+
+func TestStateMachineCannotCancelWithRefundAfterConfirmed(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, saga.ID, "res-abc123")
+	sm.MarkPaid(ctx, saga.ID, "pay-xyz789")
+	sm.MarkConfirmed(ctx, saga.ID, "conf-final")
+
+	_, err := sm.MarkCancelledWithRefund(ctx, saga.ID, "Too late", "refund-001")
+	if !errors.Is(err, ErrInvalidStateTransition) {
+		t.Errorf("expected ErrInvalidStateTransition, got %v", err)
+	}
+
+	stored, _ := sm.GetSaga(ctx, saga.ID)
+	if stored.State != StateConfirmed {
+		t.Errorf("expected state to remain 'CONFIRMED', got '%s'", stored.State)
+	}
+}
+
 func TestStateMachineCannotFailFromTerminalState(t *testing.T) {
 	ctx := context.Background()
 	store := NewMemoryStateStore()
@@ -460,3 +598,332 @@ func TestStateMachineFullBookingFlow(t *testing.T) {
 		t.Errorf("expected 3 transitions, got %d", len(history))
 	}
 }
+
+func TestMemoryStateStoreConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+
+	// Two workers read the same version of the saga
+	first, _ := store.GetSaga(ctx, saga.ID)
+	second, _ := store.GetSaga(ctx, saga.ID)
+	first.ReservationID = "res-worker-1"
+	second.ReservationID = "res-worker-2"
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, s := range []*BookingSaga{first, second} {
+		wg.Add(1)
+		go func(i int, s *BookingSaga) {
+			defer wg.Done()
+			errs[i] = store.UpdateSaga(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var succeeded, conflicted int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrConcurrentModification):
+			conflicted++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 || conflicted != 1 {
+		t.Fatalf("expected one success and one conflict, got %d and %d", succeeded, conflicted)
+	}
+
+	stored, _ := store.GetSaga(ctx, saga.ID)
+	if stored.Version != saga.Version+1 {
+		t.Errorf("expected version %d, got %d", saga.Version+1, stored.Version)
+	}
+
+	// Missing sagas are still reported as not found
+	if err := store.UpdateSaga(ctx, &BookingSaga{ID: "nonexistent"}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("expected ErrStateNotFound, got %v", err)
+	}
+}
+
+func TestMemoryStateStoreConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	const numSagas = 50
+	done := make(chan struct{})
+
+	// Readers scribble on everything they get back; with shared pointers this
+	// would corrupt stored sagas and -race would flag it
+	var readers sync.WaitGroup
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for _, state := range []BookingState{StateCreated, StateReserved, StatePaid, StateConfirmed} {
+					sagas, err := store.GetSagasByState(ctx, state, 0)
+					if err != nil {
+						t.Errorf("GetSagasByState failed: %v", err)
+						return
+					}
+					for _, saga := range sagas {
+						saga.State = StateFailed
+						saga.Data["scribbled"] = true
+						if saga.CompletedAt != nil {
+							*saga.CompletedAt = saga.CompletedAt.AddDate(-1, 0, 0)
+						}
+						transitions, _ := store.GetTransitions(ctx, saga.ID)
+						for i := range transitions {
+							transitions[i].ToState = StateFailed
+						}
+					}
+				}
+				store.Count()
+				runtime.Gosched() // let writers make progress on small machines
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	ids := make([]string, numSagas)
+	for i := 0; i < numSagas; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			saga, err := sm.CreateSaga(ctx, fmt.Sprintf("booking-%d", i), "event-1", "user-1", nil)
+			if err != nil {
+				t.Errorf("CreateSaga failed: %v", err)
+				return
+			}
+			ids[i] = saga.ID
+			if _, err := sm.MarkReserved(ctx, saga.ID, "res"); err != nil {
+				t.Errorf("MarkReserved failed: %v", err)
+				return
+			}
+			if _, err := sm.MarkPaid(ctx, saga.ID, "pay"); err != nil {
+				t.Errorf("MarkPaid failed: %v", err)
+				return
+			}
+			if _, err := sm.MarkConfirmed(ctx, saga.ID, "conf"); err != nil {
+				t.Errorf("MarkConfirmed failed: %v", err)
+			}
+		}(i)
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	if store.Count() != numSagas {
+		t.Fatalf("expected %d sagas, got %d", numSagas, store.Count())
+	}
+
+	confirmed, _ := store.GetSagasByState(ctx, StateConfirmed, 0)
+	if len(confirmed) != numSagas {
+		t.Errorf("expected %d confirmed sagas, got %d", numSagas, len(confirmed))
+	}
+
+	for i, id := range ids {
+		saga, err := store.GetSagaByBookingID(ctx, fmt.Sprintf("booking-%d", i))
+		if err != nil {
+			t.Fatalf("GetSagaByBookingID failed: %v", err)
+		}
+		if saga.ID != id || saga.State != StateConfirmed || saga.Data["scribbled"] != nil {
+			t.Errorf("saga %s was corrupted: %+v", id, saga)
+		}
+		if saga.CompletedAt == nil || saga.CompletedAt.Before(saga.CreatedAt) {
+			t.Errorf("saga %s has a corrupted CompletedAt: %v", id, saga.CompletedAt)
+		}
+		history, _ := store.GetTransitions(ctx, id)
+		if len(history) != 3 || history[2].ToState != StateConfirmed {
+			t.Errorf("saga %s has unexpected transitions: %+v", id, history)
+		}
+	}
+}
+
+func TestMemoryStateStoreConcurrentTransition(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+
+	// Many workers race the same CREATED -> RESERVED transition
+	const workers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sm.TransitionTo(ctx, saga.ID, StateReserved, "race")
+			switch {
+			case err == nil:
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			case errors.Is(err, ErrConcurrentModification), errors.Is(err, ErrInvalidStateTransition):
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("expected exactly one successful transition, got %d", succeeded)
+	}
+
+	stored, _ := store.GetSaga(ctx, saga.ID)
+	if stored.State != StateReserved || stored.Version != 1 {
+		t.Errorf("expected RESERVED at version 1, got %s at version %d", stored.State, stored.Version)
+	}
+
+	// Only the winning writer's transition is recorded
+	history, _ := store.GetTransitions(ctx, saga.ID)
+	if len(history) != 1 {
+		t.Errorf("expected 1 recorded transition, got %d: %+v", len(history), history)
+	}
+}
+
+// staleStateStore fails every update as if another writer got there first
+type staleStateStore struct {
+	*MemoryStateStore
+}
+
+func (s *staleStateStore) UpdateSaga(ctx context.Context, saga *BookingSaga) error {
+	return ErrConcurrentModification
+}
+
+func TestStateMachineLostUpdateRecordsNoTransition(t *testing.T) {
+	ctx := context.Background()
+	store := &staleStateStore{NewMemoryStateStore()}
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+
+	if _, err := sm.TransitionTo(ctx, saga.ID, StateReserved, "stale"); !errors.Is(err, ErrConcurrentModification) {
+		t.Fatalf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	history, _ := store.GetTransitions(ctx, saga.ID)
+	if len(history) != 0 {
+		t.Errorf("expected no transition for a lost update, got %+v", history)
+	}
+}
+
+func TestStateMachineRetryFailed(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkReserved(ctx, saga.ID, "res-abc123")
+	sm.MarkFailed(ctx, saga.ID, "Payment gateway timeout")
+
+	retried, err := sm.RetryFailed(ctx, saga.ID)
+	if err != nil {
+		t.Fatalf("RetryFailed failed: %v", err)
+	}
+
+	if retried.State != StateReserved {
+		t.Errorf("expected state 'RESERVED', got '%s'", retried.State)
+	}
+	if retried.ErrorMessage != "" {
+		t.Errorf("expected error message to be cleared, got '%s'", retried.ErrorMessage)
+	}
+	if retried.CompletedAt != nil {
+		t.Error("expected CompletedAt to be cleared")
+	}
+	if retried.RetryCount != 1 {
+		t.Errorf("expected retry count 1, got %d", retried.RetryCount)
+	}
+
+	// The saga can continue through the pipeline
+	if _, err := sm.MarkPaid(ctx, saga.ID, "pay-xyz789"); err != nil {
+		t.Errorf("expected retried saga to continue, got %v", err)
+	}
+}
+
+func TestStateMachineRetryFailedLostUpdateRecordsNoTransition(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	sm := NewStateMachine(store)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkFailed(ctx, saga.ID, "Reservation failed")
+	before, _ := store.GetTransitions(ctx, saga.ID)
+
+	// Another retry won the race, so this one's versioned update is stale
+	stale := NewStateMachine(&staleStateStore{store})
+	if _, err := stale.RetryFailed(ctx, saga.ID); !errors.Is(err, ErrConcurrentModification) {
+		t.Fatalf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	after, _ := store.GetTransitions(ctx, saga.ID)
+	if len(after) != len(before) {
+		t.Errorf("expected no transition for a lost retry, got %+v", after[len(before):])
+	}
+}
+
+func TestStateMachineRetryFailedFromCreated(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateMachine(NewMemoryStateStore())
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+	sm.MarkFailed(ctx, saga.ID, "Reservation failed")
+
+	retried, err := sm.RetryFailed(ctx, saga.ID)
+	if err != nil {
+		t.Fatalf("RetryFailed failed: %v", err)
+	}
+	if retried.State != StateCreated {
+		t.Errorf("expected state 'CREATED', got '%s'", retried.State)
+	}
+}
+
+func TestStateMachineRetryFailedAtCap(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateMachine(NewMemoryStateStore()).WithMaxRetries(2)
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+
+	// First failure: retry allowed
+	sm.MarkFailed(ctx, saga.ID, "first failure")
+	if _, err := sm.RetryFailed(ctx, saga.ID); err != nil {
+		t.Fatalf("expected first retry to succeed, got %v", err)
+	}
+
+	// Second failure: cap reached
+	sm.MarkFailed(ctx, saga.ID, "second failure")
+	_, err := sm.RetryFailed(ctx, saga.ID)
+	if !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("expected ErrMaxRetriesExceeded, got %v", err)
+	}
+
+	stored, _ := sm.GetSaga(ctx, saga.ID)
+	if stored.State != StateFailed {
+		t.Errorf("expected state to remain 'FAILED', got '%s'", stored.State)
+	}
+}
+
+func TestStateMachineRetryFailedRequiresFailedState(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateMachine(NewMemoryStateStore())
+
+	saga, _ := sm.CreateSaga(ctx, "booking-123", "event-456", "user-789", nil)
+
+	if _, err := sm.RetryFailed(ctx, saga.ID); !errors.Is(err, ErrInvalidStateTransition) {
+		t.Errorf("expected ErrInvalidStateTransition, got %v", err)
+	}
+}
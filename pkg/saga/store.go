@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -134,6 +135,34 @@ func (s *MemoryStore) GetByStatus(ctx context.Context, status Status, limit int)
 	return result, nil
 }
 
+// GetStatusesByBookingID returns the status of the most recent saga for each
+// booking, matched on the booking_id in the saga data
+func (s *MemoryStore) GetStatusesByBookingID(ctx context.Context, bookingIDs []string) (map[string]Status, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(bookingIDs))
+	for _, id := range bookingIDs {
+		wanted[id] = true
+	}
+
+	statuses := make(map[string]Status, len(bookingIDs))
+	latest := make(map[string]time.Time, len(bookingIDs))
+	for _, instance := range s.instances {
+		bookingID, _ := instance.Data["booking_id"].(string)
+		if !wanted[bookingID] {
+			continue
+		}
+		if seen, ok := latest[bookingID]; ok && !instance.CreatedAt.After(seen) {
+			continue
+		}
+		latest[bookingID] = instance.CreatedAt
+		statuses[bookingID] = instance.Status
+	}
+
+	return statuses, nil
+}
+
 // GetPendingCompensations returns sagas that need compensation
 func (s *MemoryStore) GetPendingCompensations(ctx context.Context, limit int) ([]*Instance, error) {
 	s.mu.RLock()
@@ -185,7 +214,8 @@ func (s *MemoryStore) Count() int {
 	return len(s.instances)
 }
 
-// RedisStore is a Redis-based implementation of Store
+// RedisStore is a Redis-based implementation of Store.
+// Each saga instance is stored as a hash; data and step results are JSON-encoded fields.
 type RedisStore struct {
 	client     RedisClient
 	keyPrefix  string
@@ -194,12 +224,31 @@ type RedisStore struct {
 
 // RedisClient defines the interface for Redis operations needed by the saga store
 type RedisClient interface {
-	Get(ctx context.Context, key string) (string, error)
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// HSet writes the hash fields and (re)applies the key expiration
+	HSet(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) error
+	// HCreate is HSet that only writes when the key does not exist yet, atomically;
+	// it reports whether the hash was created
+	HCreate(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) (bool, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Exists(ctx context.Context, key string) (bool, error)
 	Del(ctx context.Context, keys ...string) error
 	Keys(ctx context.Context, pattern string) ([]string, error)
 }
 
+// Hash field names used by RedisStore
+const (
+	redisFieldID           = "id"
+	redisFieldDefinitionID = "definition_id"
+	redisFieldStatus       = "status"
+	redisFieldData         = "data"
+	redisFieldStepResults  = "step_results"
+	redisFieldCurrentStep  = "current_step"
+	redisFieldError        = "error"
+	redisFieldCreatedAt    = "created_at"
+	redisFieldUpdatedAt    = "updated_at"
+	redisFieldCompletedAt  = "completed_at"
+)
+
 // NewRedisStore creates a new Redis-based saga store
 func NewRedisStore(client RedisClient, keyPrefix string, expiration time.Duration) *RedisStore {
 	if keyPrefix == "" {
@@ -220,46 +269,41 @@ func (s *RedisStore) key(id string) string {
 	return s.keyPrefix + id
 }
 
-// Save persists a saga instance
+// Save persists a new saga instance. The instance key is claimed atomically, so
+// of concurrent saves with the same ID (e.g. a repeated idempotency key) only
+// one succeeds and the rest get ErrSagaAlreadyExists.
 func (s *RedisStore) Save(ctx context.Context, instance *Instance) error {
-	// Check if exists
-	_, err := s.client.Get(ctx, s.key(instance.ID))
-	if err == nil {
-		return ErrSagaAlreadyExists
+	values, err := redisValues(instance)
+	if err != nil {
+		return err
 	}
 
-	data, err := instance.ToJSON()
+	created, err := s.client.HCreate(ctx, s.key(instance.ID), values, s.expiration)
 	if err != nil {
-		return fmt.Errorf("failed to serialize saga instance: %w", err)
+		return fmt.Errorf("failed to save saga instance: %w", err)
 	}
-
-	return s.client.Set(ctx, s.key(instance.ID), data, s.expiration)
+	if !created {
+		return ErrSagaAlreadyExists
+	}
+	return nil
 }
 
 // Get retrieves a saga instance by ID
 func (s *RedisStore) Get(ctx context.Context, id string) (*Instance, error) {
-	data, err := s.client.Get(ctx, s.key(id))
-	if err != nil {
-		return nil, ErrSagaNotFound
-	}
-
-	return FromJSON([]byte(data))
+	return s.read(ctx, s.key(id))
 }
 
 // Update updates an existing saga instance
 func (s *RedisStore) Update(ctx context.Context, instance *Instance) error {
-	// Check if exists
-	_, err := s.client.Get(ctx, s.key(instance.ID))
+	exists, err := s.client.Exists(ctx, s.key(instance.ID))
 	if err != nil {
-		return ErrSagaNotFound
+		return fmt.Errorf("failed to check saga instance: %w", err)
 	}
-
-	data, err := instance.ToJSON()
-	if err != nil {
-		return fmt.Errorf("failed to serialize saga instance: %w", err)
+	if !exists {
+		return ErrSagaNotFound
 	}
 
-	return s.client.Set(ctx, s.key(instance.ID), data, s.expiration)
+	return s.write(ctx, instance)
 }
 
 // Delete removes a saga instance
@@ -269,36 +313,126 @@ func (s *RedisStore) Delete(ctx context.Context, id string) error {
 
 // GetByStatus retrieves saga instances by status
 func (s *RedisStore) GetByStatus(ctx context.Context, status Status, limit int) ([]*Instance, error) {
-	keys, err := s.client.Keys(ctx, s.keyPrefix+"*")
+	return s.scan(ctx, limit, func(instance *Instance) bool {
+		return instance.Status == status
+	})
+}
+
+// GetPendingCompensations returns sagas that need compensation
+func (s *RedisStore) GetPendingCompensations(ctx context.Context, limit int) ([]*Instance, error) {
+	return s.scan(ctx, limit, func(instance *Instance) bool {
+		return instance.Status == StatusFailed || instance.Status == StatusCompensating
+	})
+}
+
+// write serializes a saga instance into a hash and refreshes its TTL
+func (s *RedisStore) write(ctx context.Context, instance *Instance) error {
+	values, err := redisValues(instance)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get keys: %w", err)
+		return err
 	}
 
-	var result []*Instance
-	for _, key := range keys {
-		data, err := s.client.Get(ctx, key)
-		if err != nil {
-			continue
+	if err := s.client.HSet(ctx, s.key(instance.ID), values, s.expiration); err != nil {
+		return fmt.Errorf("failed to write saga instance: %w", err)
+	}
+
+	return nil
+}
+
+// redisValues serializes a saga instance into hash fields
+func redisValues(instance *Instance) (map[string]interface{}, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	dataJSON, err := json.Marshal(instance.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	stepResultsJSON, err := json.Marshal(instance.StepResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal step results: %w", err)
+	}
+
+	completedAt := ""
+	if instance.CompletedAt != nil {
+		completedAt = instance.CompletedAt.Format(time.RFC3339Nano)
+	}
+
+	return map[string]interface{}{
+		redisFieldID:           instance.ID,
+		redisFieldDefinitionID: instance.DefinitionID,
+		redisFieldStatus:       string(instance.Status),
+		redisFieldData:         string(dataJSON),
+		redisFieldStepResults:  string(stepResultsJSON),
+		redisFieldCurrentStep:  strconv.Itoa(instance.CurrentStep),
+		redisFieldError:        instance.Error,
+		redisFieldCreatedAt:    instance.CreatedAt.Format(time.RFC3339Nano),
+		redisFieldUpdatedAt:    instance.UpdatedAt.Format(time.RFC3339Nano),
+		redisFieldCompletedAt:  completedAt,
+	}, nil
+}
+
+// read loads a saga instance from its hash
+func (s *RedisStore) read(ctx context.Context, key string) (*Instance, error) {
+	fields, err := s.client.HGetAll(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saga instance: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrSagaNotFound
+	}
+
+	instance := &Instance{
+		ID:           fields[redisFieldID],
+		DefinitionID: fields[redisFieldDefinitionID],
+		Status:       Status(fields[redisFieldStatus]),
+		Error:        fields[redisFieldError],
+	}
+
+	if v := fields[redisFieldCurrentStep]; v != "" {
+		if instance.CurrentStep, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("failed to parse current step: %w", err)
 		}
+	}
 
-		instance, err := FromJSON([]byte(data))
-		if err != nil {
-			continue
+	if v := fields[redisFieldData]; v != "" {
+		if err := json.Unmarshal([]byte(v), &instance.Data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data: %w", err)
 		}
+	}
+	if instance.Data == nil {
+		instance.Data = make(map[string]interface{})
+	}
 
-		if instance.Status == status {
-			result = append(result, instance)
-			if limit > 0 && len(result) >= limit {
-				break
-			}
+	if v := fields[redisFieldStepResults]; v != "" {
+		if err := json.Unmarshal([]byte(v), &instance.StepResults); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal step results: %w", err)
 		}
 	}
+	if instance.StepResults == nil {
+		instance.StepResults = make([]*StepResult, 0)
+	}
 
-	return result, nil
+	if instance.CreatedAt, err = time.Parse(time.RFC3339Nano, fields[redisFieldCreatedAt]); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if instance.UpdatedAt, err = time.Parse(time.RFC3339Nano, fields[redisFieldUpdatedAt]); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	if v := fields[redisFieldCompletedAt]; v != "" {
+		completedAt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse completed_at: %w", err)
+		}
+		instance.CompletedAt = &completedAt
+	}
+
+	return instance, nil
 }
 
-// GetPendingCompensations returns sagas that need compensation
-func (s *RedisStore) GetPendingCompensations(ctx context.Context, limit int) ([]*Instance, error) {
+// scan loads all saga instances under the key prefix that match the filter
+func (s *RedisStore) scan(ctx context.Context, limit int, match func(*Instance) bool) ([]*Instance, error) {
 	keys, err := s.client.Keys(ctx, s.keyPrefix+"*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get keys: %w", err)
@@ -306,17 +440,12 @@ func (s *RedisStore) GetPendingCompensations(ctx context.Context, limit int) ([]
 
 	var result []*Instance
 	for _, key := range keys {
-		data, err := s.client.Get(ctx, key)
-		if err != nil {
-			continue
-		}
-
-		instance, err := FromJSON([]byte(data))
+		instance, err := s.read(ctx, key)
 		if err != nil {
 			continue
 		}
 
-		if instance.Status == StatusFailed || instance.Status == StatusCompensating {
+		if match(instance) {
 			result = append(result, instance)
 			if limit > 0 && len(result) >= limit {
 				break
@@ -337,12 +466,47 @@ func NewRedisClientAdapter(client *redis.Client) *RedisClientAdapter {
 	return &RedisClientAdapter{client: client}
 }
 
-func (a *RedisClientAdapter) Get(ctx context.Context, key string) (string, error) {
-	return a.client.Get(ctx, key).Result()
+func (a *RedisClientAdapter) HSet(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) error {
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, values)
+		if expiration > 0 {
+			pipe.Expire(ctx, key, expiration)
+		}
+		return nil
+	})
+	return err
+}
+
+// hcreateScript writes the hash fields (ARGV[2..]) and expiration in ms (ARGV[1])
+// only if the key does not exist
+var hcreateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+if tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+func (a *RedisClientAdapter) HCreate(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) (bool, error) {
+	args := make([]interface{}, 0, 1+2*len(values))
+	args = append(args, expiration.Milliseconds())
+	for field, value := range values {
+		args = append(args, field, value)
+	}
+	created, err := hcreateScript.Run(ctx, a.client, []string{key}, args...).Int()
+	return created == 1, err
+}
+
+func (a *RedisClientAdapter) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return a.client.HGetAll(ctx, key).Result()
 }
 
-func (a *RedisClientAdapter) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return a.client.Set(ctx, key, value, expiration).Err()
+func (a *RedisClientAdapter) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := a.client.Exists(ctx, key).Result()
+	return n > 0, err
 }
 
 func (a *RedisClientAdapter) Del(ctx context.Context, keys ...string) error {
@@ -0,0 +1,280 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedisClient is an in-memory RedisClient for unit tests
+type fakeRedisClient struct {
+	mu          sync.Mutex
+	hashes      map[string]map[string]string
+	expirations map[string]time.Duration
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{
+		hashes:      make(map[string]map[string]string),
+		expirations: make(map[string]time.Duration),
+	}
+}
+
+func (c *fakeRedisClient) HSet(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash, ok := c.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		c.hashes[key] = hash
+	}
+	for field, value := range values {
+		hash[field] = fmt.Sprint(value)
+	}
+	c.expirations[key] = expiration
+	return nil
+}
+
+func (c *fakeRedisClient) HCreate(ctx context.Context, key string, values map[string]interface{}, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	if _, exists := c.hashes[key]; exists {
+		c.mu.Unlock()
+		return false, nil
+	}
+	c.hashes[key] = make(map[string]string)
+	c.mu.Unlock()
+	return true, c.HSet(ctx, key, values, expiration)
+}
+
+func (c *fakeRedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string]string)
+	for field, value := range c.hashes[key] {
+		result[field] = value
+	}
+	return result, nil
+}
+
+func (c *fakeRedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.hashes[key]
+	return ok, nil
+}
+
+func (c *fakeRedisClient) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.hashes, key)
+		delete(c.expirations, key)
+	}
+	return nil
+}
+
+func (c *fakeRedisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keys []string
+	for key := range c.hashes {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func newTestInstance() *Instance {
+	instance := NewInstance("booking-saga", map[string]interface{}{
+		"booking_id": "book-1",
+		"quantity":   2,
+	})
+	instance.Status = StatusRunning
+	instance.CurrentStep = 1
+	instance.StepResults = append(instance.StepResults, &StepResult{
+		StepName:   "reserve-seats",
+		Status:     StepStatusCompleted,
+		Data:       map[string]interface{}{"reservation_id": "res-1"},
+		StartedAt:  time.Now().Add(-time.Second),
+		FinishedAt: time.Now(),
+		Duration:   time.Second,
+	})
+	return instance
+}
+
+func assertInstanceRoundTrip(t *testing.T, want, got *Instance) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("expected ID '%s', got '%s'", want.ID, got.ID)
+	}
+	if got.DefinitionID != want.DefinitionID {
+		t.Errorf("expected definition ID '%s', got '%s'", want.DefinitionID, got.DefinitionID)
+	}
+	if got.Status != want.Status {
+		t.Errorf("expected status '%s', got '%s'", want.Status, got.Status)
+	}
+	if got.CurrentStep != want.CurrentStep {
+		t.Errorf("expected current step %d, got %d", want.CurrentStep, got.CurrentStep)
+	}
+	if got.Data["booking_id"] != "book-1" {
+		t.Errorf("expected booking_id 'book-1', got '%v'", got.Data["booking_id"])
+	}
+	if got.Data["quantity"] != float64(2) {
+		t.Errorf("expected quantity 2, got '%v'", got.Data["quantity"])
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("expected created_at %v, got %v", want.CreatedAt, got.CreatedAt)
+	}
+	if len(got.StepResults) != 1 {
+		t.Fatalf("expected 1 step result, got %d", len(got.StepResults))
+	}
+	result := got.StepResults[0]
+	if result.StepName != "reserve-seats" || result.Status != StepStatusCompleted {
+		t.Errorf("unexpected step result %s/%s", result.StepName, result.Status)
+	}
+	if result.Data["reservation_id"] != "res-1" {
+		t.Errorf("expected reservation_id 'res-1', got '%v'", result.Data["reservation_id"])
+	}
+	if result.Duration != time.Second {
+		t.Errorf("expected duration 1s, got %v", result.Duration)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedisClient()
+	store := NewRedisStore(client, "test-saga:", time.Hour)
+
+	instance := newTestInstance()
+
+	// Test Save
+	if err := store.Save(ctx, instance); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if client.expirations["test-saga:"+instance.ID] != time.Hour {
+		t.Errorf("expected TTL of 1 hour, got %v", client.expirations["test-saga:"+instance.ID])
+	}
+
+	// Test duplicate save
+	if err := store.Save(ctx, instance); !errors.Is(err, ErrSagaAlreadyExists) {
+		t.Errorf("expected ErrSagaAlreadyExists, got %v", err)
+	}
+
+	// Test Get round-trip
+	retrieved, err := store.Get(ctx, instance.ID)
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	assertInstanceRoundTrip(t, instance, retrieved)
+	if retrieved.CompletedAt != nil {
+		t.Error("expected CompletedAt to be nil")
+	}
+
+	// Test Get not found
+	if _, err := store.Get(ctx, "nonexistent"); !errors.Is(err, ErrSagaNotFound) {
+		t.Errorf("expected ErrSagaNotFound, got %v", err)
+	}
+
+	// Test Update
+	instance.Complete()
+	if err := store.Update(ctx, instance); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	retrieved, _ = store.Get(ctx, instance.ID)
+	if retrieved.Status != StatusCompleted {
+		t.Errorf("expected status 'completed', got '%s'", retrieved.Status)
+	}
+	if retrieved.CompletedAt == nil || !retrieved.CompletedAt.Equal(*instance.CompletedAt) {
+		t.Errorf("expected CompletedAt %v, got %v", instance.CompletedAt, retrieved.CompletedAt)
+	}
+
+	// Test Update not found
+	if err := store.Update(ctx, NewInstance("test", nil)); !errors.Is(err, ErrSagaNotFound) {
+		t.Errorf("expected ErrSagaNotFound, got %v", err)
+	}
+
+	// Test Delete
+	if err := store.Delete(ctx, instance.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := store.Get(ctx, instance.ID); !errors.Is(err, ErrSagaNotFound) {
+		t.Errorf("expected ErrSagaNotFound after delete, got %v", err)
+	}
+}
+
+func TestRedisStoreGetByStatus(t *testing.T) {
+	ctx := context.Background()
+	store := NewRedisStore(newFakeRedisClient(), "", 0)
+
+	for _, status := range []Status{StatusRunning, StatusRunning, StatusFailed, StatusCompensating, StatusCompleted} {
+		instance := NewInstance("test-saga", nil)
+		instance.Status = status
+		store.Save(ctx, instance)
+	}
+
+	running, err := store.GetByStatus(ctx, StatusRunning, 0)
+	if err != nil {
+		t.Fatalf("failed to get by status: %v", err)
+	}
+	if len(running) != 2 {
+		t.Errorf("expected 2 running instances, got %d", len(running))
+	}
+
+	pending, err := store.GetPendingCompensations(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed to get pending compensations: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("expected 2 pending compensations, got %d", len(pending))
+	}
+
+	limited, _ := store.GetByStatus(ctx, StatusRunning, 1)
+	if len(limited) != 1 {
+		t.Errorf("expected 1 instance with limit, got %d", len(limited))
+	}
+}
+
+func TestRedisStore_Integration(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	}
+
+	addr := "localhost:6379"
+	if host := os.Getenv("TEST_REDIS_HOST"); host != "" {
+		addr = host + ":6379"
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("TEST_REDIS_PASSWORD")})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	store := NewRedisStore(NewRedisClientAdapter(rdb), "saga-test:", time.Minute)
+
+	instance := newTestInstance()
+	if err := store.Save(ctx, instance); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	defer store.Delete(ctx, instance.ID)
+
+	retrieved, err := store.Get(ctx, instance.ID)
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	assertInstanceRoundTrip(t, instance, retrieved)
+
+	ttl, err := rdb.TTL(ctx, "saga-test:"+instance.ID).Result()
+	if err != nil {
+		t.Fatalf("failed to get TTL: %v", err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected TTL within 1 minute, got %v", ttl)
+	}
+}
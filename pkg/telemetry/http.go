@@ -1,10 +1,13 @@
 package telemetry
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // HTTPClientConfig holds configuration for instrumented HTTP client
@@ -65,3 +68,8 @@ func WrapHTTPClient(client *http.Client) *http.Client {
 	client.Transport = otelhttp.NewTransport(transport)
 	return client
 }
+
+// InjectHTTPHeaders injects the trace context from ctx (e.g. traceparent) into outgoing HTTP headers
+func InjectHTTPHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
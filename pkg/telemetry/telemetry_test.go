@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestInit_Disabled(t *testing.T) {
@@ -223,6 +225,43 @@ func TestSetSpanAttributes_NoSpan(t *testing.T) {
 	SetSpanAttributes(ctx, attribute.String("key", "value"), attribute.Int("number", 42))
 }
 
+func TestSetBusinessOutcome(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+	tracer := provider.Tracer("test")
+
+	tests := []struct {
+		name            string
+		code            string
+		expectedOutcome string
+		expectedSuccess bool
+	}{
+		{name: "rejection", code: "INSUFFICIENT_STOCK", expectedOutcome: "INSUFFICIENT_STOCK", expectedSuccess: false},
+		{name: "success", code: OutcomeSuccess, expectedOutcome: OutcomeSuccess, expectedSuccess: true},
+		{name: "empty code", code: "", expectedOutcome: OutcomeSuccess, expectedSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, span := tracer.Start(context.Background(), tt.name)
+			SetBusinessOutcome(span, tt.code)
+			span.End()
+
+			ended := recorder.Ended()
+			attrs := attribute.NewSet(ended[len(ended)-1].Attributes()...)
+
+			outcome, ok := attrs.Value(AttrBookingOutcome)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedOutcome, outcome.AsString())
+
+			success, ok := attrs.Value(AttrBookingSuccess)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedSuccess, success.AsBool())
+		})
+	}
+}
+
 func TestGetMeter_Disabled(t *testing.T) {
 	ctx := context.Background()
 
@@ -288,3 +288,26 @@ func SetSpanAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(attrs...)
 }
+
+// Business outcome span attributes, for slicing traces by rejection reason
+const (
+	AttrBookingOutcome = "booking.outcome"
+	AttrBookingSuccess = "booking.success"
+
+	// OutcomeSuccess is the outcome recorded for requests that were not rejected
+	OutcomeSuccess = "SUCCESS"
+)
+
+// SetBusinessOutcome tags span with a request's business outcome: code is a
+// rejection code such as INSUFFICIENT_STOCK, or OutcomeSuccess (or "") when the
+// request succeeded. Rejections are expected results rather than errors, so the
+// span status is left to the caller.
+func SetBusinessOutcome(span trace.Span, code string) {
+	if code == "" {
+		code = OutcomeSuccess
+	}
+	span.SetAttributes(
+		attribute.String(AttrBookingOutcome, code),
+		attribute.Bool(AttrBookingSuccess, code == OutcomeSuccess),
+	)
+}
@@ -0,0 +1,7 @@
+-- 000016_add_audit_saga_correlation.down.sql
+-- Remove booking and saga correlation from audit_logs
+
+DROP INDEX IF EXISTS idx_audit_logs_saga_id;
+DROP INDEX IF EXISTS idx_audit_logs_booking_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS booking_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS saga_id;
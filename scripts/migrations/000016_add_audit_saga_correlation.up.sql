@@ -0,0 +1,9 @@
+-- 000016_add_audit_saga_correlation.up.sql
+-- Tie audit entries to the booking and saga instance they acted on
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS saga_id VARCHAR(255);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS booking_id VARCHAR(255);
+
+-- Booking timelines are read in time order
+CREATE INDEX IF NOT EXISTS idx_audit_logs_booking_id ON audit_logs(booking_id, created_at) WHERE booking_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_logs_saga_id ON audit_logs(saga_id) WHERE saga_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Index for looking up sagas by booking (reservation reconciler)
+CREATE INDEX IF NOT EXISTS idx_saga_instances_booking_id
+    ON saga_instances ((data->>'booking_id'), created_at DESC);
@@ -0,0 +1,4 @@
+-- 000005_add_saga_refund_id.down.sql
+-- Remove refund_id column from saga_instances table
+
+ALTER TABLE saga_instances DROP COLUMN IF EXISTS refund_id;
@@ -0,0 +1,4 @@
+-- 000005_add_saga_refund_id.up.sql
+-- Record the refund issued when a PAID booking's saga is cancelled
+
+ALTER TABLE saga_instances ADD COLUMN IF NOT EXISTS refund_id VARCHAR(255);
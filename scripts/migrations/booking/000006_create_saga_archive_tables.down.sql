@@ -0,0 +1,5 @@
+-- 000006_create_saga_archive_tables.down.sql
+-- Drop saga archive tables
+
+DROP TABLE IF EXISTS saga_transitions_archive;
+DROP TABLE IF EXISTS saga_instances_archive;
@@ -0,0 +1,17 @@
+-- 000006_create_saga_archive_tables.up.sql
+-- Archive tables for completed sagas and their transitions.
+-- Rows are moved with INSERT ... SELECT *, so the columns must mirror the hot
+-- tables exactly; add any new saga_instances/saga_transitions column here too.
+
+CREATE TABLE IF NOT EXISTS saga_instances_archive (
+    LIKE saga_instances INCLUDING DEFAULTS,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS saga_transitions_archive (
+    LIKE saga_transitions INCLUDING DEFAULTS,
+    PRIMARY KEY (id)
+);
+
+-- Index for looking up the archived history of a saga
+CREATE INDEX IF NOT EXISTS idx_saga_transitions_archive_saga_id ON saga_transitions_archive(saga_id);
@@ -0,0 +1,5 @@
+-- 000007_add_saga_version.down.sql
+-- Remove version column from saga_instances and its archive
+
+ALTER TABLE saga_instances_archive DROP COLUMN IF EXISTS version;
+ALTER TABLE saga_instances DROP COLUMN IF EXISTS version;
@@ -0,0 +1,7 @@
+-- 000007_add_saga_version.up.sql
+-- Optimistic concurrency version for saga state updates.
+-- The archive table mirrors saga_instances (rows move with INSERT ... SELECT *),
+-- so it gets the column too.
+
+ALTER TABLE saga_instances ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE saga_instances_archive ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
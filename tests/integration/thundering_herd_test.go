@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -796,10 +797,35 @@ func TestThunderingHerd_ClearErrorMessages(t *testing.T) {
 
 // containsIgnoreCase checks if s contains substr (case-insensitive)
 func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr ||
-			len(s) > 0 && len(substr) > 0 &&
-				(s[0] == substr[0] || s[0]+32 == substr[0] || s[0]-32 == substr[0]) &&
-				containsIgnoreCase(s[1:], substr[1:]) ||
-			containsIgnoreCase(s[1:], substr))
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func TestContainsIgnoreCase(t *testing.T) {
+	tests := []struct {
+		s      string
+		substr string
+		want   bool
+	}{
+		{"Sold out", "sold out", true},
+		{"SOLD OUT", "Sold Out", true},
+		{"Zone is sold out", "SOLD", true},
+		{"Exceeds max tickets per user", "max tickets", true},
+		{"anything", "", true},
+		{"", "", true},
+		{"", "x", false},
+		{"Insufficient seats", "sold", false},
+		// Letters must be contiguous, not merely appear in order
+		{"s-o-l-d", "sold", false},
+		{"abc", "abcd", false},
+		// '@' and '`' are 32 apart from letters but aren't case variants
+		{"@", "`", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q in %q", tt.substr, tt.s), func(t *testing.T) {
+			if got := containsIgnoreCase(tt.s, tt.substr); got != tt.want {
+				t.Errorf("containsIgnoreCase(%q, %q) = %v, want %v", tt.s, tt.substr, got, tt.want)
+			}
+		})
+	}
 }